@@ -0,0 +1,89 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+)
+
+// AdaptiveTimeoutPolicy determines how the socket timeout of a command is derived from the
+// recent latencies of the target node. See BasePolicy.AdaptiveTimeout.
+type AdaptiveTimeoutPolicy struct {
+	// Percentile is the percentile of the recent latencies of the node the timeout is based on.
+	//
+	// Default: 99
+	Percentile float64 //= 99
+
+	// Multiplier is applied to the latency percentile to get the socket timeout, leaving a margin
+	// for the normal variance of the latencies.
+	//
+	// Default: 2
+	Multiplier float64 //= 2
+
+	// MinTimeout is the floor of the adaptive socket timeout.
+	//
+	// Default: 10ms
+	MinTimeout time.Duration //= 10ms
+
+	// MaxTimeout is the ceiling of the adaptive socket timeout.
+	// A value of 0 uses the static socket timeout of the policy as the ceiling.
+	//
+	// Default: 0
+	MaxTimeout time.Duration //= 0
+
+	// MinSamples is the number of latencies that must be observed for the node before the
+	// adaptive timeout is used. Until then, the static socket timeout of the policy is used.
+	//
+	// Default: 100
+	MinSamples int //= 100
+}
+
+// NewAdaptiveTimeoutPolicy returns a policy with the default values.
+func NewAdaptiveTimeoutPolicy() *AdaptiveTimeoutPolicy {
+	return &AdaptiveTimeoutPolicy{
+		Percentile: 99,
+		Multiplier: 2,
+		MinTimeout: 10 * time.Millisecond,
+		MinSamples: 100,
+	}
+}
+
+// socketTimeout returns the socket timeout derived from the latencies, bounded by the floor and
+// the ceiling of the policy. The static timeout is returned if there are not enough samples.
+func (p *AdaptiveTimeoutPolicy) socketTimeout(latencies *latencyWindow, static time.Duration) time.Duration {
+	ceiling := p.MaxTimeout
+	if ceiling <= 0 || (static > 0 && static < ceiling) {
+		ceiling = static
+	}
+
+	latency, ok := latencies.percentile(p.Percentile, p.MinSamples)
+	if !ok {
+		return static
+	}
+
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 1
+	}
+
+	timeout := time.Duration(float64(latency) * multiplier)
+	if timeout < p.MinTimeout {
+		timeout = p.MinTimeout
+	}
+	if ceiling > 0 && timeout > ceiling {
+		timeout = ceiling
+	}
+	return timeout
+}
@@ -0,0 +1,94 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Adaptive timeouts", func() {
+
+	gg.It("Must compute the percentiles of the published latencies", func() {
+		lw := new(latencyWindow)
+		_, ok := lw.percentile(99, 0)
+		gm.Expect(ok).To(gm.BeFalse())
+
+		for i := 100; i >= 1; i-- {
+			lw.add(time.Duration(i) * time.Millisecond)
+		}
+
+		// not published yet
+		_, ok = lw.percentile(99, 0)
+		gm.Expect(ok).To(gm.BeFalse())
+
+		lw.publish()
+		p, ok := lw.percentile(99, 100)
+		gm.Expect(ok).To(gm.BeTrue())
+		gm.Expect(p).To(gm.Equal(99 * time.Millisecond))
+
+		p, _ = lw.percentile(50, 0)
+		gm.Expect(p).To(gm.Equal(50 * time.Millisecond))
+
+		p, _ = lw.percentile(100, 0)
+		gm.Expect(p).To(gm.Equal(100 * time.Millisecond))
+
+		_, ok = lw.percentile(99, 101)
+		gm.Expect(ok).To(gm.BeFalse())
+	})
+
+	gg.It("Must only keep the most recent latencies", func() {
+		lw := new(latencyWindow)
+		for i := 0; i < latencyWindowSize; i++ {
+			lw.add(time.Second)
+		}
+		for i := 0; i < latencyWindowSize; i++ {
+			lw.add(time.Millisecond)
+		}
+		lw.publish()
+
+		p, ok := lw.percentile(100, 0)
+		gm.Expect(ok).To(gm.BeTrue())
+		gm.Expect(p).To(gm.Equal(time.Millisecond))
+	})
+
+	gg.It("Must bound the socket timeout by the floor and the ceiling", func() {
+		lw := new(latencyWindow)
+		policy := NewAdaptiveTimeoutPolicy()
+
+		// not enough samples
+		gm.Expect(policy.socketTimeout(lw, time.Second)).To(gm.Equal(time.Second))
+
+		for i := 0; i < policy.MinSamples; i++ {
+			lw.add(20 * time.Millisecond)
+		}
+		lw.publish()
+		gm.Expect(policy.socketTimeout(lw, time.Second)).To(gm.Equal(40 * time.Millisecond))
+
+		// the static timeout is the default ceiling
+		gm.Expect(policy.socketTimeout(lw, 30*time.Millisecond)).To(gm.Equal(30 * time.Millisecond))
+
+		policy.MaxTimeout = 35 * time.Millisecond
+		gm.Expect(policy.socketTimeout(lw, 0)).To(gm.Equal(35 * time.Millisecond))
+		gm.Expect(policy.socketTimeout(lw, 30*time.Millisecond)).To(gm.Equal(30 * time.Millisecond))
+
+		policy.MaxTimeout = 0
+		policy.MinTimeout = 100 * time.Millisecond
+		gm.Expect(policy.socketTimeout(lw, time.Second)).To(gm.Equal(100 * time.Millisecond))
+	})
+
+})
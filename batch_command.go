@@ -15,6 +15,7 @@
 package aerospike
 
 import (
+	"context"
 	"time"
 )
 
@@ -27,6 +28,7 @@ type batcher interface {
 	retryBatch(ifc batcher, cluster *Cluster, deadline time.Time, iteration int) (bool, Error)
 	generateBatchNodes(*Cluster) ([]*batchNode, Error)
 	setSequence(int, int)
+	setAbortContext(context.Context)
 
 	executeSingle(clientIfc) Error
 }
@@ -50,10 +52,14 @@ type batchCommand struct {
 	splitRetry bool
 
 	filteredOutCnt int
+
+	// if set, the command will be interrupted when the context is cancelled.
+	// Used to implement BatchPolicy.AbortOnFirstError.
+	abortCtx context.Context
 }
 
 func (cmd *batchCommand) prepareRetry(ifc command, isTimeout bool) bool {
-	if !(cmd.policy.ReplicaPolicy == SEQUENCE || cmd.policy.ReplicaPolicy == PREFER_RACK) {
+	if !(cmd.policy.ReplicaPolicy == SEQUENCE || cmd.policy.ReplicaPolicy == PREFER_RACK || cmd.policy.ReplicaPolicy == LEAST_LOADED) {
 		// Perform regular retry to same node.
 		return true
 	}
@@ -99,6 +105,41 @@ func (cmd *batchCommand) setSequence(ap, sc int) {
 	cmd.sequenceAP, cmd.sequenceSC = ap, sc
 }
 
+func (cmd *batchCommand) setAbortContext(ctx context.Context) {
+	cmd.abortCtx = ctx
+}
+
+func (cmd *batchCommand) parseResult(ifc command, conn *Connection) Error {
+	if cmd.abortCtx == nil {
+		return cmd.baseMultiCommand.parseResult(ifc, conn)
+	}
+
+	if cmd.abortCtx.Err() != nil {
+		return ErrBatchAborted.err()
+	}
+
+	// interrupt the blocking read on the connection if the batch is aborted
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-cmd.abortCtx.Done():
+			conn.interrupt()
+		case <-stop:
+		}
+	}()
+
+	err := cmd.baseMultiCommand.parseResult(ifc, conn)
+	close(stop)
+	<-stopped
+
+	if err != nil && cmd.abortCtx.Err() != nil {
+		return ErrBatchAborted.err()
+	}
+	return err
+}
+
 func (cmd *batchCommand) getPolicy(ifc command) Policy {
 	return cmd.policy
 }
@@ -129,19 +129,14 @@ func (cmd *batchCommandOperate) parseRecordResults(ifc command, receiveSize int)
 					return false, err
 				}
 
-				// for UDF failures
-				var msg interface{}
-				if rec != nil {
-					msg = rec.Bins["FAILURE"]
+				var packageName, functionName string
+				if bu, ok := cmd.records[batchIndex].(*BatchUDF); ok {
+					packageName, functionName = bu.PackageName, bu.FunctionName
 				}
 
 				// Need to store record because failure bin contains an error message.
 				cmd.records[batchIndex].setRecord(rec)
-				if msg, ok := msg.(string); ok && len(msg) > 0 {
-					cmd.records[batchIndex].setErrorWithMsg(cmd.node, resultCode, msg, cmd.batchInDoubt(cmd.attr.hasWrite, cmd.commandSentCounter))
-				} else {
-					cmd.records[batchIndex].setError(cmd.node, resultCode, cmd.batchInDoubt(cmd.attr.hasWrite, cmd.commandSentCounter))
-				}
+				cmd.records[batchIndex].BatchRec().setUDFError(cmd.node, resultCode, packageName, functionName, cmd.batchInDoubt(cmd.attr.hasWrite, cmd.commandSentCounter))
 
 				// If cmd is the end marker of the response, do not proceed further
 				// if (info3 & _INFO3_LAST) == _INFO3_LAST {
@@ -71,15 +71,17 @@ func batchParseObject(
 			err = newNodeError(cmd.node, err)
 			return err
 		}
-		value, err := bytesToParticle(particleType, cmd.dataBuffer, 0, particleBytesSize)
-		if err != nil {
-			err = newNodeError(cmd.node, err)
-			return err
-		}
+		if cmd.projection.allows(name) {
+			value, err := bytesToParticle(particleType, cmd.dataBuffer, 0, particleBytesSize)
+			if err != nil {
+				err = newNodeError(cmd.node, err)
+				return err
+			}
 
-		iobj := indirect(obj)
-		if err := setObjectField(cmd.resObjMappings, iobj, name, value); err != nil {
-			return err
+			iobj := indirect(obj)
+			if err := setObjectField(cmd.resObjMappings, iobj, name, value); err != nil {
+				return err
+			}
 		}
 
 		if err := setObjectMetaFields(obj, expiration, generation); err != nil {
@@ -90,8 +90,8 @@ func (cmd *batchCommandUDF) parseRecordResults(ifc command, receiveSize int) (bo
 		}
 		resultCode := types.ResultCode(cmd.dataBuffer[5] & 0xFF)
 
-		// The only valid server return codes are "ok" and "not found" and "filtered out".
-		// If other return codes are received, then abort the batch.
+		// The only valid server return codes are "ok", "not found", "filtered out" and
+		// the UDF failures of the record. If other return codes are received, then abort the batch.
 		if resultCode != 0 {
 			if resultCode != types.KEY_NOT_FOUND_ERROR {
 				if resultCode == types.FILTERED_OUT {
@@ -99,7 +99,7 @@ func (cmd *batchCommandUDF) parseRecordResults(ifc command, receiveSize int) (bo
 				}
 			}
 
-			if resultCode != types.KEY_NOT_FOUND_ERROR && resultCode != types.FILTERED_OUT {
+			if resultCode != types.KEY_NOT_FOUND_ERROR && resultCode != types.FILTERED_OUT && resultCode != types.UDF_BAD_RESPONSE {
 				return false, newCustomNodeError(cmd.node, resultCode)
 			}
 		}
@@ -125,6 +125,12 @@ func (cmd *batchCommandUDF) parseRecordResults(ifc command, receiveSize int) (bo
 			if err = cmd.parseRecord(cmd.records[batchIndex], cmd.keys[batchIndex], opCount, generation, expiration); err != nil {
 				return false, err
 			}
+		} else if resultCode == types.UDF_BAD_RESPONSE {
+			// Need to store record because failure bin contains an error message.
+			if err = cmd.parseRecord(cmd.records[batchIndex], cmd.keys[batchIndex], opCount, generation, expiration); err != nil {
+				return false, err
+			}
+			cmd.records[batchIndex].setUDFError(cmd.node, resultCode, cmd.packageName, cmd.functionName, cmd.batchInDoubt(cmd.attr.hasWrite, cmd.commandSentCounter))
 		} else {
 			cmd.records[batchIndex].Err = chainErrors(newCustomNodeError(cmd.node, resultCode), cmd.records[batchIndex].Err)
 			cmd.records[batchIndex].setError(cmd.node, resultCode, cmd.batchInDoubt(cmd.attr.hasWrite, cmd.commandSentCounter))
@@ -69,7 +69,7 @@ func (bdp *BatchDeletePolicy) toWritePolicy(bp *BatchPolicy) *WritePolicy {
 		wp.GenerationPolicy = bdp.GenerationPolicy
 		wp.Generation = bdp.Generation
 		wp.DurableDelete = bdp.DurableDelete
-		wp.SendKey = bdp.SendKey
+		wp.SendKey = wp.SendKey || bdp.SendKey
 	}
 	return wp
 }
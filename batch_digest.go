@@ -0,0 +1,53 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+// existingDigests returns the digests of the keys marked as existing, in key order.
+func existingDigests(keys []*Key, existsArray []bool) [][]byte {
+	res := make([][]byte, 0, len(keys))
+	for i := range existsArray {
+		if existsArray[i] {
+			res = append(res, keys[i].Digest())
+		}
+	}
+	return res
+}
+
+// BatchGetDigest returns the digests of the records that exist and pass the filter expression
+// of the policy, in the positional order of the keys.
+// Only the record headers are read on the server, and no bin data is sent back.
+// Records rejected by the filter expression are left out of the result, and are not reported
+// with ErrFilteredOut.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) BatchGetDigest(policy *BatchPolicy, keys []*Key) ([][]byte, Error) {
+	policy = clnt.getUsableBatchPolicyFor(policy, keys)
+
+	// same array can be used without synchronization;
+	// when a key exists, the corresponding index will be marked true
+	existsArray := make([]bool, len(keys))
+
+	batchNodes, err := newBatchNodeList(clnt.cluster, policy, keys, nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// pass nil to make sure it will be cloned and prepared
+	cmd := newBatchCommandExists(clnt, nil, policy, keys, existsArray)
+	if _, err = clnt.batchExecute(policy, batchNodes, cmd); err != nil && !policy.AllowPartialResults {
+		return nil, err
+	}
+
+	return existingDigests(keys, existsArray), err
+}
@@ -15,7 +15,8 @@
 package aerospike
 
 // batchExecute Uses werrGroup to run commands using multiple goroutines,
-// and waits for their return
+// and waits for their return.
+// If the sub-batches of more than one node fail, the returned error wraps a BatchError.
 func (clnt *Client) batchExecute(policy *BatchPolicy, batchNodes []*batchNode, cmd batcher) (int, Error) {
 	maxConcurrentNodes := policy.ConcurrentNodes
 	if maxConcurrentNodes <= 0 {
@@ -25,14 +26,18 @@ func (clnt *Client) batchExecute(policy *BatchPolicy, batchNodes []*batchNode, c
 	// we need this list to count the number of filtered out records
 	list := make([]batcher, 0, len(batchNodes))
 
-	weg := newWeightedErrGroup(maxConcurrentNodes)
+	weg := newWeightedErrGroup(maxConcurrentNodes).withPool(clnt.batchPool)
+	if policy.AbortOnFirstError && len(batchNodes) > 1 {
+		cmd.setAbortContext(weg.withAbortOnError())
+	}
+
 	for _, batchNode := range batchNodes {
 		newCmd := cmd.cloneBatchCommand(batchNode)
 		list = append(list, newCmd)
 		weg.execute(newCmd)
 	}
 
-	errs := weg.wait()
+	weg.wait()
 
 	// count the filtered out records
 	filteredOut := 0
@@ -40,5 +45,5 @@ func (clnt *Client) batchExecute(policy *BatchPolicy, batchNodes []*batchNode, c
 		filteredOut += list[i].filteredOut()
 	}
 
-	return filteredOut, errs
+	return filteredOut, newBatchError(weg.errors())
 }
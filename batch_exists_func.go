@@ -0,0 +1,92 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import "github.com/aerospike/aerospike-client-go/v7/types"
+
+// defaultBatchExistsChunkSize is the number of keys sent per batch by BatchExistsFunc
+// when the chunk size is not set.
+const defaultBatchExistsChunkSize = 5000
+
+// batchExistsChunks splits the keys into chunks of chunkSize keys, checks the existence of each
+// chunk with exists, and reports the results to fn in key order.
+// Only a single chunk of results is kept in memory; the slice passed to exists is reused.
+// If a record is filtered out, the remaining chunks are still processed and ErrFilteredOut is returned.
+func batchExistsChunks(keys []*Key, chunkSize int, exists func(keys []*Key, existsArray []bool) Error, fn func(index int, exists bool) bool) Error {
+	if chunkSize <= 0 {
+		chunkSize = defaultBatchExistsChunkSize
+	}
+	if chunkSize > len(keys) {
+		chunkSize = len(keys)
+	}
+
+	var filtered Error
+	existsArray := make([]bool, chunkSize)
+	for offset := 0; offset < len(keys); offset += chunkSize {
+		end := offset + chunkSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		res := existsArray[:end-offset]
+		for i := range res {
+			res[i] = false
+		}
+
+		if err := exists(keys[offset:end], res); err != nil {
+			if err.resultCode() != types.FILTERED_OUT || err.Unwrap() != nil {
+				return err
+			}
+			filtered = err
+		}
+
+		for i := range res {
+			if !fn(offset+i, res[i]) {
+				return filtered
+			}
+		}
+	}
+
+	return filtered
+}
+
+// BatchExistsFunc determines if multiple record keys exist, and reports the result of each key
+// to fn in the positional order of the keys, without allocating the result for all keys at once.
+// The keys are sent in consecutive batches of chunkSize keys; each batch is executed
+// according to the policy, including its ConcurrentNodes setting.
+// If chunkSize is zero or negative, a default of 5000 keys per batch is used.
+// Return false from fn to stop the iteration. The remaining keys will not be checked.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) BatchExistsFunc(policy *BatchPolicy, keys []*Key, chunkSize int, fn func(index int, exists bool) bool) Error {
+	return batchExistsChunks(keys, chunkSize, func(keys []*Key, existsArray []bool) Error {
+		policy := clnt.getUsableBatchPolicyFor(policy, keys)
+
+		batchNodes, err := newBatchNodeList(clnt.cluster, policy, keys, nil, false)
+		if err != nil {
+			return err
+		}
+
+		// pass nil to make sure it will be cloned and prepared
+		cmd := newBatchCommandExists(clnt, nil, policy, keys, existsArray)
+		filteredOut, err := clnt.batchExecute(policy, batchNodes, cmd)
+		if err != nil {
+			return err
+		}
+		if filteredOut > 0 {
+			return ErrFilteredOut.err()
+		}
+		return nil
+	}, fn)
+}
@@ -36,8 +36,21 @@ type BatchPolicy struct {
 	// completes, a new request will be issued until all goroutines are complete.  This mode
 	// prevents too many concurrent goroutines being created for large cluster implementations.
 	// The downside is extra goroutines will still need to be created (or taken from a goroutine pool).
+	//
+	// The concurrent batch requests are also bounded by ClientPolicy.MaxConcurrentBatchNodes,
+	// which is shared between all batch commands issued by the client.
 	ConcurrentNodes int // = 1
 
+	// AbortOnFirstError determines if the batch should be aborted as soon as the request
+	// to one of the nodes fails, instead of waiting for all the sub-batches to complete.
+	// The sub-batches that have not started yet will not be issued, and the in-flight ones
+	// will be interrupted by closing their connections. Only the original error is returned.
+	//
+	// Only relevant when the batch is issued to more than one node concurrently.
+	//
+	// Default: false
+	AbortOnFirstError bool // = false
+
 	// Allow batch to be processed immediately in the server's receiving thread when the server
 	// deems it to be appropriate.  If false, the batch will always be processed in separate
 	// transaction goroutines.  This field is only relevant for the new batch index protocol.
@@ -68,6 +68,7 @@ func newBatchRead(policy *BatchReadPolicy, key *Key, binNames []string) (*BatchR
 }
 
 // NewBatchReadOps defines a key and bins to retrieve in a batch operation, including expressions.
+// The results of ExpReadOp operations are returned in the record under the name of the operation.
 func NewBatchReadOps(policy *BatchReadPolicy, key *Key, ops ...*Operation) *BatchRead {
 	res := &BatchRead{
 		BatchRecord: *newSimpleBatchRecord(key, false),
@@ -152,6 +152,29 @@ func (br *BatchRecord) setErrorWithMsg(node *Node, resultCode types.ResultCode,
 	br.Err = newError(br.ResultCode, msg).setNode(node).markInDoubtIf(inDoubt)
 }
 
+// Set the error of a failed UDF, keeping the record returned by the server,
+// since its FAILURE bin holds the error message of the UDF. For internal use only.
+func (br *BatchRecord) setUDFError(node *Node, resultCode types.ResultCode, packageName, functionName string, inDoubt bool) {
+	var msg string
+	if br.Record != nil {
+		msg, _ = br.Record.Bins["FAILURE"].(string)
+	}
+
+	br.ResultCode = resultCode
+	br.InDoubt = inDoubt
+	br.Err = newUDFError(resultCode, packageName, functionName, msg).setNode(node).markInDoubtIf(inDoubt)
+}
+
+// UDFResult returns the value returned by the UDF of a BatchUDF or BatchExecute record.
+// It returns nil if the UDF did not return a value, or failed. The error message of
+// a failed UDF is available in the UDFError wrapped in Err.
+func (br *BatchRecord) UDFResult() interface{} {
+	if br.Record == nil {
+		return nil
+	}
+	return br.Record.Bins["SUCCESS"]
+}
+
 // String implements the Stringer interface.
 func (br *BatchRecord) String() string {
 	return fmt.Sprintf("Key: %s, Record: %s, ResultCode: %s, InDoubt: %t, Err: %v", br.Key, br.Record, br.ResultCode.String(), br.InDoubt, br.Err)
@@ -17,6 +17,7 @@
 package aerospike_test
 
 import (
+	"errors"
 	"math"
 	"math/rand"
 	"strings"
@@ -62,6 +63,26 @@ var _ = gg.Describe("Aerospike", func() {
 			}
 		}) // it
 
+		gg.It("must return the same results with AbortOnFirstError and concurrent nodes", func() {
+			var keys []*as.Key
+			for i := 0; i < 256; i++ {
+				key, _ := as.NewKey(ns, set, i)
+				client.PutBins(nil, key, as.NewBin("i", i))
+
+				keys = append(keys, key)
+			}
+
+			bp := as.NewBatchPolicy()
+			bp.ConcurrentNodes = 0
+			bp.AbortOnFirstError = true
+
+			recs, err := client.BatchGetOperate(bp, keys, as.GetBinOp("i"))
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			for i, rec := range recs {
+				gm.Expect(rec.Bins["i"]).To(gm.Equal(i))
+			}
+		}) // it
+
 	}) // describe
 
 	gg.Describe("Batch Write operations", func() {
@@ -146,6 +167,35 @@ var _ = gg.Describe("Aerospike", func() {
 				}
 			})
 
+			gg.It("must stream the existence of the keys in chunks", func() {
+				_, err := client.BatchDelete(bpolicy, bdpolicy, dkeys)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				keys := make([]*as.Key, 0, keyCount)
+				for i := range ekeys {
+					keys = append(keys, ekeys[i], dkeys[i])
+				}
+
+				count := 0
+				err = client.BatchExistsFunc(bpolicy, keys, 64, func(index int, exists bool) bool {
+					gm.Expect(index).To(gm.Equal(count))
+					gm.Expect(exists).To(gm.Equal(index%2 == 0))
+					count++
+					return true
+				})
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(count).To(gm.Equal(len(keys)))
+
+				// stop early
+				count = 0
+				err = client.BatchExistsFunc(bpolicy, keys, 64, func(index int, exists bool) bool {
+					count++
+					return count < 100
+				})
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(count).To(gm.Equal(100))
+			})
+
 			gg.It("must return the result with same ordering for s single key", func() {
 				keys := []*as.Key{ekeys[0]}
 				res, err := client.BatchDelete(bpolicy, bdpolicy, keys)
@@ -384,6 +434,47 @@ var _ = gg.Describe("Aerospike", func() {
 				}
 			})
 
+			gg.It("must successfully execute expression operations", func() {
+				key1, _ := as.NewKey(ns, set, randString(50))
+				key2, _ := as.NewKey(ns, set, randString(50))
+				key3, _ := as.NewKey(ns, set, randString(50))
+				for _, key := range []*as.Key{key1, key2, key3} {
+					err := client.Put(nil, key, as.BinMap{"bin1": 10})
+					gm.Expect(err).ToNot(gm.HaveOccurred())
+				}
+
+				double := as.ExpNumMul(as.ExpIntBin("bin1"), as.ExpIntVal(2))
+				read := as.NewBatchReadOps(nil, key1, as.ExpReadOp("doubled", double, as.ExpReadFlagDefault))
+				write := as.NewBatchWrite(nil, key2,
+					as.ExpWriteOp("bin2", double, as.ExpWriteFlagCreateOnly),
+					as.ExpReadOp("doubled", double, as.ExpReadFlagDefault),
+				)
+				createOnly := as.NewBatchWrite(nil, key3, as.ExpWriteOp("bin1", double, as.ExpWriteFlagCreateOnly))
+				noFail := as.NewBatchWrite(nil, key1, as.ExpWriteOp("bin1", double, as.ExpWriteFlagCreateOnly|as.ExpWriteFlagPolicyNoFail))
+
+				err := client.BatchOperate(nil, []as.BatchRecordIfc{read, write, createOnly, noFail})
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				gm.Expect(read.ResultCode).To(gm.Equal(types.OK))
+				gm.Expect(read.Record.Bins).To(gm.Equal(as.BinMap{"doubled": 20}))
+
+				gm.Expect(write.ResultCode).To(gm.Equal(types.OK))
+				gm.Expect(write.Record.Bins).To(gm.Equal(as.BinMap{"bin2": nil, "doubled": 20}))
+
+				gm.Expect(createOnly.ResultCode).To(gm.Equal(types.BIN_EXISTS_ERROR))
+				gm.Expect(noFail.ResultCode).To(gm.Equal(types.OK))
+
+				rec, err := client.Get(nil, key2)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"bin1": 10, "bin2": 20}))
+
+				for _, key := range []*as.Key{key1, key3} {
+					rec, err := client.Get(nil, key)
+					gm.Expect(err).ToNot(gm.HaveOccurred())
+					gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"bin1": 10}))
+				}
+			})
+
 			gg.It("must successfully execute ops with policies", func() {
 				key1, _ := as.NewKey(ns, set, randString(50))
 				err := client.Put(nil, key1, as.BinMap{"bin1": 1, "bin2": 2})
@@ -420,6 +511,45 @@ var _ = gg.Describe("Aerospike", func() {
 				gm.Expect(err).ToNot(gm.HaveOccurred())
 				gm.Expect(len(rec.Bins)).To(gm.Equal(4))
 			})
+
+			gg.It("must check the generation of each record and return the new generations", func() {
+				keys := make([]*as.Key, 3)
+				for i := range keys {
+					keys[i], _ = as.NewKey(ns, set, randString(50))
+					err := client.Put(nil, keys[i], as.BinMap{"bin1": i})
+					gm.Expect(err).ToNot(gm.HaveOccurred())
+				}
+
+				headers, err := client.BatchGetHeader(nil, keys)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				policy := as.NewBatchWritePolicy()
+				policy.Expiration = 1000
+				records := []as.BatchRecordIfc{
+					as.NewBatchWriteGeneration(policy, keys[0], headers[0].Generation, as.AddOp(as.NewBin("bin1", 1))),
+					as.NewBatchWriteGeneration(policy, keys[1], headers[1].Generation+1, as.AddOp(as.NewBin("bin1", 1))),
+					as.NewBatchWriteGeneration(nil, keys[2], headers[2].Generation, as.AddOp(as.NewBin("bin1", 1))),
+				}
+				gm.Expect(policy.GenerationPolicy).To(gm.Equal(as.NONE))
+
+				err = client.BatchOperate(nil, records)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				gm.Expect(records[0].BatchRec().ResultCode).To(gm.Equal(types.OK))
+				gm.Expect(records[0].BatchRec().Record.Generation).To(gm.Equal(headers[0].Generation + 1))
+				gm.Expect(records[0].BatchRec().Record.Expiration).To(gm.BeNumerically("~", 1000, 10))
+
+				gm.Expect(records[1].BatchRec().ResultCode).To(gm.Equal(types.GENERATION_ERROR))
+				gm.Expect(records[1].BatchRec().Record).To(gm.BeNil())
+
+				gm.Expect(records[2].BatchRec().ResultCode).To(gm.Equal(types.OK))
+				gm.Expect(records[2].BatchRec().Record.Generation).To(gm.Equal(headers[2].Generation + 1))
+
+				rec, err := client.Get(nil, keys[1])
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(rec.Generation).To(gm.Equal(headers[1].Generation))
+				gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"bin1": 1}))
+			})
 		})
 
 		gg.Context("BatchOperate operations", func() {
@@ -580,6 +710,34 @@ var _ = gg.Describe("Aerospike", func() {
 				gm.Expect(err.Matches(types.KEY_NOT_FOUND_ERROR)).To(gm.BeTrue())
 			})
 
+			gg.It("BatchRead TTL inherited from the batch policy", func() {
+				if nsupPeriod(ns) == 0 {
+					gg.Skip("Not supported with nsup-period == 0")
+				}
+
+				key1, _ := as.NewKey(ns, set, 88890)
+				key2, _ := as.NewKey(ns, set, 88891)
+
+				writePolicy := as.NewWritePolicy(0, 2)
+				gm.Expect(client.PutBins(writePolicy, key1, as.NewBin("a", 1))).ToNot(gm.HaveOccurred())
+				gm.Expect(client.PutBins(writePolicy, key2, as.NewBin("a", 1))).ToNot(gm.HaveOccurred())
+
+				// Read the records before they expire; the records without a policy reset their TTL.
+				time.Sleep(1 * time.Second)
+				bp := as.NewBatchPolicy()
+				bp.ReadTouchTTLPercent = 80
+				list := []as.BatchRecordIfc{as.NewBatchRead(nil, key1, []string{"a"}), as.NewBatchRead(nil, key2, []string{"a"})}
+				gm.Expect(client.BatchOperate(bp, list)).ToNot(gm.HaveOccurred())
+				gm.Expect(list[0].BatchRec().ResultCode).To(gm.Equal(types.OK))
+				gm.Expect(list[1].BatchRec().ResultCode).To(gm.Equal(types.OK))
+
+				// The records would have expired without the reset.
+				time.Sleep(1500 * time.Millisecond)
+				exists, err := client.BatchExists(nil, []*as.Key{key1, key2})
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(exists).To(gm.Equal([]bool{true, true}))
+			})
+
 			gg.It("BatchRead TTL", func() {
 				// WARNING: This test takes a long time to run due to sleeps.
 				// Define keys
@@ -684,6 +842,52 @@ var _ = gg.Describe("Aerospike", func() {
 				}
 			})
 
+			gg.It("must return the UDF error and result detail per record", func() {
+				luaCode := `-- Fail on odd values
+				function echo_even(rec, v)
+				    if v % 2 == 1 then
+				        error("odd value")
+				    end
+				    return v
+				end`
+
+				removeUDF("test_udf_errors.lua")
+				registerUDF(luaCode, "test_udf_errors.lua")
+
+				key1, _ := as.NewKey(ns, set, randString(50))
+				key2, _ := as.NewKey(ns, set, randString(50))
+				for _, key := range []*as.Key{key1, key2} {
+					err := client.Put(nil, key, as.BinMap{"bin": 1})
+					gm.Expect(err).ToNot(gm.HaveOccurred())
+				}
+
+				even := as.NewBatchUDF(nil, key1, "test_udf_errors", "echo_even", as.NewValue(2))
+				odd := as.NewBatchUDF(nil, key2, "test_udf_errors", "echo_even", as.NewValue(3))
+				err := client.BatchOperate(nil, []as.BatchRecordIfc{even, odd})
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				gm.Expect(even.ResultCode).To(gm.Equal(types.OK))
+				gm.Expect(even.UDFResult()).To(gm.Equal(2))
+
+				gm.Expect(odd.ResultCode).To(gm.Equal(types.UDF_BAD_RESPONSE))
+				gm.Expect(odd.UDFResult()).To(gm.BeNil())
+				udfErr := &as.UDFError{}
+				gm.Expect(errors.As(odd.Err, &udfErr)).To(gm.BeTrue())
+				gm.Expect(udfErr.PackageName).To(gm.Equal("test_udf_errors"))
+				gm.Expect(udfErr.FunctionName).To(gm.Equal("echo_even"))
+				gm.Expect(udfErr.Message).To(gm.ContainSubstring("odd value"))
+
+				records, err := client.BatchExecute(nil, nil, []*as.Key{key1, key2}, "test_udf_errors", "echo_even", as.NewValue(3))
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				for _, rec := range records {
+					gm.Expect(rec.ResultCode).To(gm.Equal(types.UDF_BAD_RESPONSE))
+					udfErr := &as.UDFError{}
+					gm.Expect(errors.As(rec.Err, &udfErr)).To(gm.BeTrue())
+					gm.Expect(udfErr.FunctionName).To(gm.Equal("echo_even"))
+					gm.Expect(udfErr.Message).To(gm.ContainSubstring("odd value"))
+				}
+			})
+
 			gg.It("must return the results when one operation is against an invalid namespace", func() {
 				luaCode := `-- Create a record
 				function rec_create(rec, bins)
@@ -66,7 +66,7 @@ func (bup *BatchUDFPolicy) toWritePolicy(bp *BatchPolicy) *WritePolicy {
 		wp.CommitLevel = bup.CommitLevel
 		wp.Expiration = bup.Expiration
 		wp.DurableDelete = bup.DurableDelete
-		wp.SendKey = bup.SendKey
+		wp.SendKey = wp.SendKey || bup.SendKey
 	}
 	return wp
 }
@@ -33,6 +33,8 @@ type BatchWrite struct {
 // ANy GetOp() is not allowed because it returns a variable number of bins and
 // makes it difficult (sometimes impossible) to lineup operations with results. Instead,
 // use GetBinOp(string) for each bin name.
+// Expression operations are supported; ExpWriteOp flags like ExpWriteFlagCreateOnly
+// fail only the record they are applied to, unless ExpWriteFlagPolicyNoFail is set.
 func NewBatchWrite(policy *BatchWritePolicy, key *Key, ops ...*Operation) *BatchWrite {
 	return &BatchWrite{
 		BatchRecord: *newSimpleBatchRecord(key, true),
@@ -41,6 +43,25 @@ func NewBatchWrite(policy *BatchWritePolicy, key *Key, ops ...*Operation) *Batch
 	}
 }
 
+// NewBatchWriteGeneration initializes a batch write that is only applied if the record
+// still has the expected generation, for optimistic concurrency on a per-record basis.
+// The policy is copied, with GenerationPolicy set to EXPECT_GEN_EQUAL and Generation to the
+// given generation; if it is nil, the values of NewBatchWritePolicy are used.
+// If the generation does not match, the ResultCode of the record is types.GENERATION_ERROR.
+// On success, the Record of the result holds the new generation and expiration of the record.
+func NewBatchWriteGeneration(policy *BatchWritePolicy, key *Key, generation uint32, ops ...*Operation) *BatchWrite {
+	var p BatchWritePolicy
+	if policy != nil {
+		p = *policy
+	} else {
+		p = *NewBatchWritePolicy()
+	}
+	p.GenerationPolicy = EXPECT_GEN_EQUAL
+	p.Generation = generation
+
+	return NewBatchWrite(&p, key, ops...)
+}
+
 func (bw *BatchWrite) isWrite() bool {
 	return bw.hasWrite
 }
@@ -96,7 +96,7 @@ func (bwp *BatchWritePolicy) toWritePolicy(bp *BatchPolicy) *WritePolicy {
 		wp.Generation = bwp.Generation
 		wp.Expiration = bwp.Expiration
 		wp.DurableDelete = bwp.DurableDelete
-		wp.SendKey = bwp.SendKey
+		wp.SendKey = wp.SendKey || bwp.SendKey
 	}
 
 	return wp
@@ -0,0 +1,81 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+// compressionPayload generates a message resembling a serialized record:
+// bin names and small integers interleaved with random string values.
+func compressionPayload(size int) []byte {
+	r := rand.New(rand.NewSource(int64(size)))
+	buf := bytes.NewBuffer(make([]byte, 0, size))
+	for i := 0; buf.Len() < size; i++ {
+		fmt.Fprintf(buf, "bin%d%08d", i%16, r.Intn(1000))
+		for j := 0; j < 8; j++ {
+			buf.WriteByte(byte('a' + r.Intn(26)))
+		}
+	}
+	return buf.Bytes()[:size]
+}
+
+// Benchmark_Compression reports the cpu cost and the resulting size ratio of compressing
+// messages of different sizes. Below ~128 bytes the framing overhead of zlib results in
+// a ratio close to or above 1, which is why it is the default MinCompressSize.
+func Benchmark_Compression(b *testing.B) {
+	for _, level := range []int{zlib.BestSpeed, zlib.DefaultCompression, zlib.BestCompression} {
+		for _, size := range []int{64, 128, 256, 1024, 16 * 1024, 128 * 1024} {
+			payload := compressionPayload(size)
+			b.Run(fmt.Sprintf("zlib_level_%d_size_%d", level, size), func(b *testing.B) {
+				var out bytes.Buffer
+				b.SetBytes(int64(size))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					out.Reset()
+					w, _ := zlibCompressor{}.NewWriter(&out, level)
+					w.Write(payload)
+					w.Close()
+				}
+				b.ReportMetric(float64(out.Len())/float64(size), "ratio")
+			})
+		}
+	}
+}
+
+func Benchmark_Decompression(b *testing.B) {
+	for _, size := range []int{128, 1024, 16 * 1024, 128 * 1024} {
+		var compressed bytes.Buffer
+		w, _ := zlibCompressor{}.NewWriter(&compressed, 0)
+		w.Write(compressionPayload(size))
+		w.Close()
+
+		b.Run(fmt.Sprintf("zlib_size_%d", size), func(b *testing.B) {
+			out := make([]byte, size)
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r, _ := decompressorFor(bytes.NewReader(compressed.Bytes()))
+				io.ReadFull(r, out)
+				r.Close()
+			}
+		})
+	}
+}
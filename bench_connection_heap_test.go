@@ -0,0 +1,67 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"testing"
+)
+
+func benchmarkConnectionHeap(b *testing.B, lockFree bool) {
+	h := newConnectionHeap(0, 256, 0, lockFree)
+	for i := 0; i < h.Cap(); i++ {
+		h.Offer(new(Connection), byte(i))
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			hint := h.shard()
+			if conn := h.Poll(hint); conn != nil {
+				h.Offer(conn, hint)
+			}
+		}
+	})
+}
+
+func Benchmark_ConnectionHeap_Mutex(b *testing.B) {
+	benchmarkConnectionHeap(b, false)
+}
+
+func Benchmark_ConnectionHeap_LockFree(b *testing.B) {
+	benchmarkConnectionHeap(b, true)
+}
+
+func benchmarkConnectionHeapShard(b *testing.B, q connectionQueue) {
+	for i := 0; i < q.Cap(); i++ {
+		q.Offer(new(Connection))
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if conn := q.Poll(); conn != nil {
+				q.Offer(conn)
+			}
+		}
+	})
+}
+
+func Benchmark_ConnectionHeap_SingleShard_Mutex(b *testing.B) {
+	benchmarkConnectionHeapShard(b, newSingleConnectionHeap(256))
+}
+
+func Benchmark_ConnectionHeap_SingleShard_LockFree(b *testing.B) {
+	benchmarkConnectionHeapShard(b, newConnectionRing(256))
+}
@@ -0,0 +1,50 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"testing"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+var __err Error
+
+func benchmarkNewError(b *testing.B, mode StackTraceMode, code types.ResultCode) {
+	SetStackTraceMode(mode)
+	defer SetStackTraceMode(StackTraceAlways)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		__err = newError(code)
+	}
+}
+
+func Benchmark_NewError_StackTraceAlways(b *testing.B) {
+	benchmarkNewError(b, StackTraceAlways, types.KEY_NOT_FOUND_ERROR)
+}
+
+func Benchmark_NewError_StackTraceUnexpected_Expected(b *testing.B) {
+	benchmarkNewError(b, StackTraceUnexpected, types.KEY_NOT_FOUND_ERROR)
+}
+
+func Benchmark_NewError_StackTraceUnexpected_Unexpected(b *testing.B) {
+	benchmarkNewError(b, StackTraceUnexpected, types.TIMEOUT)
+}
+
+func Benchmark_NewError_StackTraceNever(b *testing.B) {
+	benchmarkNewError(b, StackTraceNever, types.KEY_NOT_FOUND_ERROR)
+}
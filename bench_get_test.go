@@ -41,6 +41,17 @@ func doGet(set string, b *testing.B) {
 	}
 }
 
+func doGetBin(set string, b *testing.B) {
+	var err error
+	key, _ := as.NewKey(*namespace, set, 0)
+	for i := 0; i < b.N; i++ {
+		_, err = nativeClient.GetBin(nil, key, "b")
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
 func Benchmark_Get_________Int64(b *testing.B) {
 	set := "get_bench_integer"
 	bins := []*as.Bin{as.NewBin("b", rand.Int63())}
@@ -59,6 +70,15 @@ func Benchmark_Get_________Int32(b *testing.B) {
 	doGet(set, b)
 }
 
+func Benchmark_GetBin______Int64(b *testing.B) {
+	set := "get_bench_integer"
+	bins := []*as.Bin{as.NewBin("b", rand.Int63())}
+	makeDataForGetBench(set, bins)
+	runtime.GC()
+	b.ResetTimer()
+	doGetBin(set, b)
+}
+
 func Benchmark_Get_String______1(b *testing.B) {
 	set := "get_bench_str_1"
 	bins := []*as.Bin{as.NewBin("b", strings.Repeat("s", 1))}
@@ -104,6 +124,15 @@ func Benchmark_Get_String__10000(b *testing.B) {
 	doGet(set, b)
 }
 
+func Benchmark_GetBin_String1000(b *testing.B) {
+	set := "get_bench_str_1000"
+	bins := []*as.Bin{as.NewBin("b", strings.Repeat("s", 1000))}
+	makeDataForGetBench(set, bins)
+	runtime.GC()
+	b.ResetTimer()
+	doGetBin(set, b)
+}
+
 func Benchmark_Get_String_100000(b *testing.B) {
 	set := "get_bench_str_10000"
 	bins := []*as.Bin{as.NewBin("b", strings.Repeat("s", 10000))}
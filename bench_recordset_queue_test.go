@@ -0,0 +1,82 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"testing"
+	"time"
+)
+
+var _result *Result
+
+const benchRecordQueueSize = 5000
+
+// chanFanIn is the fan-in of the node streams before the result queue:
+// every stream sends to the channel of the consumer directly.
+type chanFanIn struct {
+	objectset
+	records chan *Result
+}
+
+func (rcs *chanFanIn) send(res *Result) bool {
+	select {
+	case rcs.records <- res:
+		return true
+	default:
+	}
+
+	defer rcs.blocked(time.Now())
+
+	select {
+	case rcs.records <- res:
+		return true
+	case <-rcs.cancelled:
+		return false
+	}
+}
+
+func benchmarkFanIn(b *testing.B, send func(*Result) bool, results <-chan *Result, end func()) {
+	consumed := make(chan struct{})
+	go func() {
+		defer close(consumed)
+		for res := range results {
+			_result = res
+		}
+	}()
+
+	res := &Result{}
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			send(res)
+		}
+	})
+	end()
+	<-consumed
+}
+
+func Benchmark_Recordset_Chan_FanIn(b *testing.B) {
+	rcs := &chanFanIn{
+		objectset: objectset{cancelled: make(chan struct{})},
+		records:   make(chan *Result, benchRecordQueueSize),
+	}
+	benchmarkFanIn(b, rcs.send, rcs.records, func() { close(rcs.records) })
+}
+
+func Benchmark_Recordset_Queue_FanIn(b *testing.B) {
+	rs := newRecordset(benchRecordQueueSize, 1)
+	benchmarkFanIn(b, rs.send, rs.Results(), rs.signalEnd)
+}
@@ -0,0 +1,304 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"sync"
+	"time"
+
+	iatomic "github.com/aerospike/aerospike-client-go/v7/internal/atomic"
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// BulkFailureHandler is called by the BulkLoader for each record that could not be written,
+// either because of a permanent error, or because the retries for a transient error were exhausted.
+// It can be used to compensate for the failure, e.g. by logging the record or writing it to a dead-letter queue.
+// The handler is called from the loader goroutines, and must be safe for concurrent use.
+type BulkFailureHandler func(key *Key, ops []*Operation, err Error)
+
+// BulkLoader is a streaming ingestion primitive on top of BatchOperate.
+// Records are buffered per node, and batches are flushed when they reach the size caps
+// set in the BulkLoaderPolicy. Batches to the same node are written in order, so writes
+// to the same key are applied in the order they were added.
+//
+// Records failing with transient errors are retried, and the ones that finally fail
+// are passed to the BulkFailureHandler.
+//
+// BulkLoader is safe for concurrent use. Close must be called after the last record is added.
+type BulkLoader struct {
+	clnt        *Client
+	policy      BulkLoaderPolicy
+	batchPolicy *BatchPolicy
+	writePolicy *BatchWritePolicy
+	onFailure   BulkFailureHandler
+
+	// guards pending, workers and closed.
+	// Batches are dispatched while holding it to preserve their order.
+	m       sync.Mutex
+	pending map[string]*bulkBatch
+	workers map[string]chan *bulkBatch
+	closed  bool
+
+	sem       chan struct{}
+	inFlight  sync.WaitGroup
+	workersWg sync.WaitGroup
+	stop      chan struct{}
+
+	written iatomic.Int
+	failed  iatomic.Int
+
+	errsLock sync.Mutex
+	errs     Error
+}
+
+type bulkBatch struct {
+	records []BatchRecordIfc
+	bytes   int
+}
+
+// NewBulkLoader creates a new BulkLoader using the client.
+// If the policy is nil, the default BulkLoaderPolicy will be used.
+// If onFailure is nil, the errors of the failed records are chained and returned from Flush and Close.
+func (clnt *Client) NewBulkLoader(policy *BulkLoaderPolicy, onFailure BulkFailureHandler) *BulkLoader {
+	if policy == nil {
+		policy = NewBulkLoaderPolicy()
+	}
+
+	concurrency := policy.ConcurrentNodes
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	bl := &BulkLoader{
+		clnt:        clnt,
+		policy:      *policy,
+		batchPolicy: clnt.getUsableBatchPolicy(policy.BatchPolicy),
+		writePolicy: clnt.getUsableBatchWritePolicy(policy.WritePolicy),
+		onFailure:   onFailure,
+		pending:     map[string]*bulkBatch{},
+		workers:     map[string]chan *bulkBatch{},
+		sem:         make(chan struct{}, concurrency),
+		stop:        make(chan struct{}),
+	}
+
+	if policy.FlushInterval > 0 {
+		go bl.flushPeriodically(policy.FlushInterval)
+	}
+
+	return bl
+}
+
+// Add buffers a record to be written with the bins.
+// The call blocks if the batches are filled faster than they can be flushed.
+func (bl *BulkLoader) Add(key *Key, bins ...*Bin) Error {
+	ops := make([]*Operation, len(bins))
+	for i := range bins {
+		ops[i] = PutOp(bins[i])
+	}
+	return bl.AddOperations(key, ops...)
+}
+
+// AddOperations buffers a record to be written with the operations.
+// The call blocks if the batches are filled faster than they can be flushed.
+func (bl *BulkLoader) AddOperations(key *Key, ops ...*Operation) Error {
+	rec := NewBatchWrite(bl.writePolicy, key, ops...)
+	size, err := rec.size(&bl.batchPolicy.BasePolicy)
+	if err != nil {
+		return err
+	}
+
+	// records that cannot be mapped to a node are grouped together,
+	// and will fail with the proper error in the batch command
+	var nodeName string
+	if partition, err := PartitionForWrite(bl.clnt.cluster, &bl.batchPolicy.BasePolicy, key); err == nil {
+		if node, err := partition.GetNodeWrite(bl.clnt.cluster); err == nil {
+			nodeName = node.GetName()
+		}
+	}
+
+	bl.m.Lock()
+	defer bl.m.Unlock()
+
+	if bl.closed {
+		return newError(types.COMMON_ERROR, "BulkLoader is closed")
+	}
+
+	b := bl.pending[nodeName]
+	if b == nil {
+		b = &bulkBatch{records: make([]BatchRecordIfc, 0, bl.policy.MaxBatchRecords)}
+		bl.pending[nodeName] = b
+	}
+
+	b.records = append(b.records, rec)
+	b.bytes += size
+
+	if (bl.policy.MaxBatchRecords > 0 && len(b.records) >= bl.policy.MaxBatchRecords) ||
+		(bl.policy.MaxBatchBytes > 0 && b.bytes >= bl.policy.MaxBatchBytes) {
+		delete(bl.pending, nodeName)
+		bl.dispatch(nodeName, b)
+	}
+
+	return nil
+}
+
+// Flush sends all the buffered records and waits until all the records added
+// before the call have been written or have failed.
+func (bl *BulkLoader) Flush() Error {
+	bl.m.Lock()
+	bl.dispatchPending()
+	bl.m.Unlock()
+
+	bl.inFlight.Wait()
+
+	bl.errsLock.Lock()
+	defer bl.errsLock.Unlock()
+	errs := bl.errs
+	bl.errs = nil
+	return errs
+}
+
+// Close flushes the buffered records and stops the loader.
+// Records cannot be added after the loader is closed.
+func (bl *BulkLoader) Close() Error {
+	bl.m.Lock()
+	if bl.closed {
+		bl.m.Unlock()
+		return nil
+	}
+	bl.closed = true
+	close(bl.stop)
+	bl.m.Unlock()
+
+	err := bl.Flush()
+
+	bl.m.Lock()
+	for _, ch := range bl.workers {
+		close(ch)
+	}
+	bl.m.Unlock()
+
+	bl.workersWg.Wait()
+	return err
+}
+
+// Written returns the number of records successfully written.
+func (bl *BulkLoader) Written() int {
+	return bl.written.Get()
+}
+
+// Failed returns the number of records that could not be written.
+func (bl *BulkLoader) Failed() int {
+	return bl.failed.Get()
+}
+
+func (bl *BulkLoader) flushPeriodically(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			bl.m.Lock()
+			bl.dispatchPending()
+			bl.m.Unlock()
+		case <-bl.stop:
+			return
+		}
+	}
+}
+
+// dispatchPending dispatches all the buffered batches. Must be called while holding bl.m.
+func (bl *BulkLoader) dispatchPending() {
+	for nodeName, b := range bl.pending {
+		delete(bl.pending, nodeName)
+		bl.dispatch(nodeName, b)
+	}
+}
+
+// dispatch sends the batch to the worker of the node. Must be called while holding bl.m.
+func (bl *BulkLoader) dispatch(nodeName string, b *bulkBatch) {
+	ch := bl.workers[nodeName]
+	if ch == nil {
+		ch = make(chan *bulkBatch, 1)
+		bl.workers[nodeName] = ch
+		bl.workersWg.Add(1)
+		go bl.work(ch)
+	}
+
+	bl.inFlight.Add(1)
+	ch <- b
+}
+
+func (bl *BulkLoader) work(ch chan *bulkBatch) {
+	defer bl.workersWg.Done()
+
+	for b := range ch {
+		bl.sem <- struct{}{}
+		bl.write(b.records)
+		<-bl.sem
+		bl.inFlight.Done()
+	}
+}
+
+// write sends the records to the server, retrying the transient failures.
+func (bl *BulkLoader) write(records []BatchRecordIfc) {
+	for attempt := 0; ; attempt++ {
+		err := bl.clnt.BatchOperate(bl.batchPolicy, records)
+
+		var retry []BatchRecordIfc
+		for _, rec := range records {
+			br := rec.BatchRec()
+			if br.ResultCode == types.OK {
+				bl.written.IncrementAndGet()
+				continue
+			}
+
+			if attempt < bl.policy.MaxRetries && br.ResultCode.IsRetryable() {
+				rec.prepare()
+				retry = append(retry, rec)
+				continue
+			}
+
+			rerr := br.Err
+			if rerr == nil {
+				rerr = newError(br.ResultCode).markInDoubt(br.InDoubt)
+			}
+			if br.ResultCode == types.NO_RESPONSE && err != nil {
+				rerr = chainErrors(rerr, err)
+			}
+			bl.fail(rec, rerr)
+		}
+
+		if len(retry) == 0 {
+			return
+		}
+
+		records = retry
+		time.Sleep(bl.policy.SleepBetweenRetries * time.Duration(attempt+1))
+	}
+}
+
+func (bl *BulkLoader) fail(rec BatchRecordIfc, err Error) {
+	bl.failed.IncrementAndGet()
+
+	if bl.onFailure != nil {
+		bl.onFailure(rec.key(), rec.(*BatchWrite).Ops, err)
+		return
+	}
+
+	bl.errsLock.Lock()
+	bl.errs = chainErrors(err, bl.errs)
+	bl.errsLock.Unlock()
+}
@@ -0,0 +1,80 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+)
+
+// BulkLoaderPolicy contains attributes used by the BulkLoader.
+type BulkLoaderPolicy struct {
+	// BatchPolicy is the policy used for the batch commands sent to the nodes.
+	// If nil, the client DefaultBatchPolicy is used.
+	BatchPolicy *BatchPolicy
+
+	// WritePolicy is the policy used for each record in the batch.
+	// If nil, the client DefaultBatchWritePolicy is used.
+	WritePolicy *BatchWritePolicy
+
+	// MaxBatchRecords is the maximum number of records buffered for a node before
+	// the batch is flushed.
+	//
+	// Default: 1000
+	MaxBatchRecords int // = 1000
+
+	// MaxBatchBytes is the maximum estimated size of the records buffered for a node
+	// before the batch is flushed.
+	//
+	// Default: 1 MiB
+	MaxBatchBytes int // = 1 MiB
+
+	// ConcurrentNodes is the maximum number of batches being flushed in parallel.
+	// Batches to the same node are always flushed in the order they were filled, so that
+	// writes to the same key are applied in the order they were added.
+	//
+	// Default: 4
+	ConcurrentNodes int // = 4
+
+	// MaxRetries is the maximum number of times the records that failed with a transient
+	// error (timeouts, overloaded devices, busy keys, unavailable nodes, etc.) are retried.
+	// Records still failing after the retries are passed to the failure handler.
+	//
+	// Default: 3
+	MaxRetries int // = 3
+
+	// SleepBetweenRetries is the time to wait before retrying the failed records of a batch.
+	// The wait is multiplied by the number of the attempt.
+	//
+	// Default: 100ms
+	SleepBetweenRetries time.Duration // = 100 * time.Millisecond
+
+	// FlushInterval determines the maximum time records are buffered before being flushed,
+	// regardless of the batch size. 0 means records are only flushed when the batch is full,
+	// or on Flush and Close.
+	//
+	// Default: 0
+	FlushInterval time.Duration // = 0
+}
+
+// NewBulkLoaderPolicy initializes a new BulkLoaderPolicy instance with default parameters.
+func NewBulkLoaderPolicy() *BulkLoaderPolicy {
+	return &BulkLoaderPolicy{
+		MaxBatchRecords:     1000,
+		MaxBatchBytes:       1024 * 1024,
+		ConcurrentNodes:     4,
+		MaxRetries:          3,
+		SleepBetweenRetries: 100 * time.Millisecond,
+	}
+}
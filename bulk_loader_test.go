@@ -0,0 +1,124 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	"sync"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+// ALL tests are isolated by SetName and Key, which are 50 random characters
+var _ = gg.Describe("BulkLoader", func() {
+
+	var ns = *namespace
+	var set string
+
+	gg.BeforeEach(func() {
+		if *proxy {
+			gg.Skip("Not supported in Proxy Client")
+		}
+
+		set = randString(50)
+	})
+
+	gg.It("must write all the records and keep the order of writes to the same key", func() {
+		policy := as.NewBulkLoaderPolicy()
+		policy.MaxBatchRecords = 7
+
+		bl := nativeClient.NewBulkLoader(policy, nil)
+		for round := 0; round < 3; round++ {
+			for i := 0; i < 100; i++ {
+				key, _ := as.NewKey(ns, set, i)
+				err := bl.Add(key, as.NewBin("i", i), as.NewBin("round", round))
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+			}
+		}
+
+		gm.Expect(bl.Close()).ToNot(gm.HaveOccurred())
+		gm.Expect(bl.Written()).To(gm.Equal(300))
+		gm.Expect(bl.Failed()).To(gm.Equal(0))
+
+		for i := 0; i < 100; i++ {
+			key, _ := as.NewKey(ns, set, i)
+			rec, err := nativeClient.Get(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Bins["i"]).To(gm.Equal(i))
+			gm.Expect(rec.Bins["round"]).To(gm.Equal(2))
+		}
+	})
+
+	gg.It("must pass the permanent failures to the failure handler", func() {
+		for i := 0; i < 10; i += 2 {
+			key, _ := as.NewKey(ns, set, i)
+			err := nativeClient.PutBins(nil, key, as.NewBin("i", i))
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+		}
+
+		var m sync.Mutex
+		var failed []as.Error
+		handler := func(key *as.Key, ops []*as.Operation, err as.Error) {
+			defer gg.GinkgoRecover()
+			gm.Expect(len(ops)).To(gm.Equal(1))
+
+			m.Lock()
+			failed = append(failed, err)
+			m.Unlock()
+		}
+
+		policy := as.NewBulkLoaderPolicy()
+		policy.WritePolicy = as.NewBatchWritePolicy()
+		policy.WritePolicy.RecordExistsAction = as.CREATE_ONLY
+
+		bl := nativeClient.NewBulkLoader(policy, handler)
+		for i := 0; i < 10; i++ {
+			key, _ := as.NewKey(ns, set, i)
+			err := bl.Add(key, as.NewBin("i", i))
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+		}
+
+		gm.Expect(bl.Close()).ToNot(gm.HaveOccurred())
+		gm.Expect(bl.Written()).To(gm.Equal(5))
+		gm.Expect(bl.Failed()).To(gm.Equal(5))
+		gm.Expect(len(failed)).To(gm.Equal(5))
+		for _, err := range failed {
+			gm.Expect(err.Matches(types.KEY_EXISTS_ERROR)).To(gm.BeTrue())
+		}
+	})
+
+	gg.It("must return the failures from Flush when there is no failure handler", func() {
+		key, _ := as.NewKey(ns, set, 1)
+		err := nativeClient.PutBins(nil, key, as.NewBin("i", 1))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		policy := as.NewBulkLoaderPolicy()
+		policy.WritePolicy = as.NewBatchWritePolicy()
+		policy.WritePolicy.RecordExistsAction = as.CREATE_ONLY
+
+		bl := nativeClient.NewBulkLoader(policy, nil)
+		gm.Expect(bl.Add(key, as.NewBin("i", 2))).ToNot(gm.HaveOccurred())
+
+		err = bl.Flush()
+		gm.Expect(err).To(gm.HaveOccurred())
+		gm.Expect(err.Matches(types.KEY_EXISTS_ERROR)).To(gm.BeTrue())
+
+		gm.Expect(bl.Close()).ToNot(gm.HaveOccurred())
+		gm.Expect(bl.Add(key, as.NewBin("i", 3))).To(gm.HaveOccurred())
+	})
+})
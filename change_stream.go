@@ -0,0 +1,208 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"sync"
+	"time"
+
+	iatomic "github.com/aerospike/aerospike-client-go/v7/internal/atomic"
+)
+
+// ChangeEvent is a record change delivered by a ChangeStream.
+type ChangeEvent struct {
+	// Key is the key of the changed record.
+	// Only the digest is set, unless the user key is stored on the server.
+	Key *Key
+
+	// Generation is the generation of the record when the change was detected.
+	Generation uint32
+
+	// Err is set if a scan failed. The stream keeps polling after errors,
+	// and the changes missed by the failed scan are found by the next one.
+	Err Error
+}
+
+// ChangeStream delivers the keys of the records of a set that changed since a watermark.
+// The server does not publish change notifications to clients, so the changes are detected
+// by periodic scans filtered by the last update time of the records.
+// Deleted and expired records are not reported.
+//
+// Use it for cache invalidation and similar use cases where the key of the changed records
+// is sufficient, and the latest version can be read when needed. Consecutive updates to a record
+// between two polls are reported as a single change.
+type ChangeStream struct {
+	clnt      *Client
+	policy    ChangeStreamPolicy
+	namespace string
+	set       string
+
+	events    chan *ChangeEvent
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+
+	watermark iatomic.TypedVal[time.Time]
+
+	// changes delivered within the overlap of the watermark.
+	// Only accessed by the polling goroutine.
+	seen map[string]seenChange
+}
+
+type seenChange struct {
+	generation uint32
+	seenAt     time.Time
+}
+
+// WatchChanges starts a ChangeStream for the records of the namespace and set
+// updated after the since time. A zero since time reports all the records in the set first.
+// To resume a stream, pass the Watermark of the previous stream.
+// If the policy is nil, the default ChangeStreamPolicy will be used.
+func (clnt *Client) WatchChanges(policy *ChangeStreamPolicy, namespace, set string, since time.Time) (*ChangeStream, Error) {
+	if policy == nil {
+		policy = NewChangeStreamPolicy()
+	}
+
+	cs := &ChangeStream{
+		clnt:      clnt,
+		policy:    *policy,
+		namespace: namespace,
+		set:       set,
+		events:    make(chan *ChangeEvent, policy.EventQueueSize),
+		done:      make(chan struct{}),
+		seen:      map[string]seenChange{},
+	}
+	cs.watermark.Set(since)
+
+	if cs.policy.PollInterval <= 0 {
+		cs.policy.PollInterval = time.Second
+	}
+
+	cs.wg.Add(1)
+	go cs.run()
+
+	return cs, nil
+}
+
+// Events returns the channel of the changes. The channel is closed after the stream is closed.
+func (cs *ChangeStream) Events() <-chan *ChangeEvent {
+	return cs.events
+}
+
+// Watermark returns the time before which all the changes have been delivered,
+// as measured by the client clock.
+func (cs *ChangeStream) Watermark() time.Time {
+	return cs.watermark.Get()
+}
+
+// Close stops the stream and waits for the polling goroutine to finish.
+func (cs *ChangeStream) Close() {
+	cs.closeOnce.Do(func() {
+		close(cs.done)
+	})
+	cs.wg.Wait()
+}
+
+func (cs *ChangeStream) run() {
+	defer cs.wg.Done()
+	defer close(cs.events)
+
+	for cs.poll() {
+		select {
+		case <-cs.done:
+			return
+		case <-time.After(cs.policy.PollInterval):
+		}
+	}
+}
+
+// poll scans for the records changed after the watermark minus the overlap.
+// Returns false if the stream was closed.
+func (cs *ChangeStream) poll() bool {
+	scanStart := time.Now()
+
+	var cutoff int64
+	if since := cs.watermark.Get().Add(-cs.policy.Overlap); since.After(time.Unix(0, 0)) {
+		cutoff = since.UnixNano()
+	}
+
+	policy := cs.policy.ScanPolicy
+	policy.IncludeBinData = false
+	policy.FilterExpression = ExpGreater(ExpLastUpdate(), ExpIntVal(cutoff))
+	if cs.policy.FilterExpression != nil {
+		policy.FilterExpression = ExpAnd(cs.policy.FilterExpression, policy.FilterExpression)
+	}
+
+	rs, err := cs.clnt.ScanAll(&policy, cs.namespace, cs.set)
+	if err != nil {
+		return cs.send(&ChangeEvent{Err: err})
+	}
+
+	failed := false
+	for res := range rs.Results() {
+		select {
+		case <-cs.done:
+			rs.Close()
+			return false
+		default:
+		}
+
+		if res.Err != nil {
+			failed = true
+			if !cs.send(&ChangeEvent{Err: res.Err}) {
+				rs.Close()
+				return false
+			}
+			continue
+		}
+
+		digest := string(res.Record.Key.Digest())
+		if s, exists := cs.seen[digest]; exists && s.generation == res.Record.Generation {
+			continue
+		}
+		cs.seen[digest] = seenChange{generation: res.Record.Generation, seenAt: scanStart}
+
+		if !cs.send(&ChangeEvent{Key: res.Record.Key, Generation: res.Record.Generation}) {
+			rs.Close()
+			return false
+		}
+	}
+
+	if failed {
+		return true
+	}
+
+	cs.watermark.Set(scanStart)
+
+	// changes seen before the start of the overlap cannot be found by the next scans
+	// unless they are updated again, in which case their generation changes
+	pruneBefore := scanStart.Add(-2 * cs.policy.Overlap)
+	for digest, s := range cs.seen {
+		if s.seenAt.Before(pruneBefore) {
+			delete(cs.seen, digest)
+		}
+	}
+
+	return true
+}
+
+func (cs *ChangeStream) send(e *ChangeEvent) bool {
+	select {
+	case cs.events <- e:
+		return true
+	case <-cs.done:
+		return false
+	}
+}
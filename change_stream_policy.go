@@ -0,0 +1,56 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+)
+
+// ChangeStreamPolicy contains attributes used by Client.WatchChanges.
+type ChangeStreamPolicy struct {
+	// ScanPolicy is used for the scans that detect the changed records.
+	// Its FilterExpression, if set, is combined with the last update time filter.
+	// Bin data is never requested.
+	ScanPolicy
+
+	// PollInterval is the time between the scans.
+	//
+	// Default: 1 second
+	PollInterval time.Duration // = 1 * time.Second
+
+	// Overlap is the period before the watermark that is scanned again on each poll.
+	// The watermark is set from the client clock, while the last update times are set
+	// by the server clocks, so Overlap must be larger than the clock skew between the
+	// client and the cluster nodes, plus the duration of the in-flight writes.
+	// Changes found again in the overlap are not delivered twice.
+	//
+	// Default: 5 seconds
+	Overlap time.Duration // = 5 * time.Second
+
+	// EventQueueSize is the size of the buffered event channel.
+	//
+	// Default: 1024
+	EventQueueSize int // = 1024
+}
+
+// NewChangeStreamPolicy creates a new ChangeStreamPolicy with default values.
+func NewChangeStreamPolicy() *ChangeStreamPolicy {
+	return &ChangeStreamPolicy{
+		ScanPolicy:     *NewScanPolicy(),
+		PollInterval:   time.Second,
+		Overlap:        5 * time.Second,
+		EventQueueSize: 1024,
+	}
+}
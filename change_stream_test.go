@@ -0,0 +1,92 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	"time"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+// ALL tests are isolated by SetName and Key, which are 50 random characters
+var _ = gg.Describe("ChangeStream", func() {
+
+	var ns = *namespace
+	var set string
+
+	gg.BeforeEach(func() {
+		if *proxy {
+			gg.Skip("Not supported in Proxy Client")
+		}
+
+		set = randString(50)
+	})
+
+	// collects the events until no new events are received for a while
+	collect := func(cs *as.ChangeStream) map[string]uint32 {
+		res := map[string]uint32{}
+		for {
+			select {
+			case e := <-cs.Events():
+				gm.Expect(e.Err).ToNot(gm.HaveOccurred())
+				_, exists := res[string(e.Key.Digest())]
+				gm.Expect(exists).To(gm.BeFalse())
+				res[string(e.Key.Digest())] = e.Generation
+			case <-time.After(time.Second):
+				return res
+			}
+		}
+	}
+
+	gg.It("must deliver each change once", func() {
+		policy := as.NewChangeStreamPolicy()
+		policy.PollInterval = 100 * time.Millisecond
+		policy.Overlap = 2 * time.Second
+
+		cs, err := nativeClient.WatchChanges(policy, ns, set, time.Now())
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		defer cs.Close()
+
+		var keys []*as.Key
+		for i := 0; i < 10; i++ {
+			key, _ := as.NewKey(ns, set, i)
+			gm.Expect(nativeClient.PutBins(nil, key, as.NewBin("i", i))).ToNot(gm.HaveOccurred())
+			keys = append(keys, key)
+		}
+
+		changes := collect(cs)
+		gm.Expect(len(changes)).To(gm.Equal(10))
+		for _, key := range keys {
+			gm.Expect(changes[string(key.Digest())]).To(gm.Equal(uint32(1)))
+		}
+
+		gm.Expect(nativeClient.PutBins(nil, keys[0], as.NewBin("i", 100))).ToNot(gm.HaveOccurred())
+		changes = collect(cs)
+		gm.Expect(changes).To(gm.Equal(map[string]uint32{string(keys[0].Digest()): 2}))
+
+		gm.Expect(cs.Watermark()).To(gm.BeTemporally("~", time.Now(), 2*time.Second))
+	})
+
+	gg.It("must close the events channel after Close", func() {
+		cs, err := nativeClient.WatchChanges(nil, ns, set, time.Time{})
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		cs.Close()
+
+		gm.Eventually(cs.Events()).Should(gm.BeClosed())
+	})
+})
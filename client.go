@@ -16,6 +16,7 @@ package aerospike
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -24,8 +25,12 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/sync/singleflight"
+
 	"github.com/aerospike/aerospike-client-go/v7/logger"
 	"github.com/aerospike/aerospike-client-go/v7/types"
 )
@@ -61,6 +66,27 @@ type Client struct {
 	DefaultAdminPolicy *AdminPolicy
 	// DefaultInfoPolicy is used for all info commands without a specific policy.
 	DefaultInfoPolicy *InfoPolicy
+
+	// journal records write intents if set in the ClientPolicy.
+	journal WriteJournal
+
+	// batchPool is the worker pool shared between all batch commands.
+	// nil if ClientPolicy.MaxConcurrentBatchNodes is not set.
+	batchPool *semaphore.Weighted
+
+	// cache serves the reads if set in the ClientPolicy.
+	cache RecordCache
+
+	// readFlights collapses identical concurrent reads. See BasePolicy.CollapseReads.
+	readFlights singleflight.Group
+
+	// policies holds the default policies per namespace and set.
+	policies PolicyRegistry
+
+	// luaPool holds the Lua instances of the aggregations of the client.
+	// It is created on the first aggregation.
+	luaPool     *types.Pool
+	luaPoolOnce sync.Once
 }
 
 func clientFinalizer(f *Client) {
@@ -124,6 +150,20 @@ func NewClientWithPolicyAndHost(policy *ClientPolicy, hosts ...*Host) (*Client,
 		DefaultQueryPolicy:       NewQueryPolicy(),
 		DefaultAdminPolicy:       NewAdminPolicy(),
 		DefaultInfoPolicy:        NewInfoPolicy(),
+		journal:                  policy.WriteJournal,
+		cache:                    policy.RecordCache,
+	}
+
+	if policy.DefaultSendKey {
+		client.DefaultWritePolicy.SendKey = true
+		client.DefaultBatchPolicy.SendKey = true
+		client.DefaultBatchWritePolicy.SendKey = true
+		client.DefaultBatchDeletePolicy.SendKey = true
+		client.DefaultBatchUDFPolicy.SendKey = true
+	}
+
+	if policy.MaxConcurrentBatchNodes > 0 {
+		client.batchPool = semaphore.NewWeighted(int64(policy.MaxConcurrentBatchNodes))
 	}
 
 	runtime.SetFinalizer(client, clientFinalizer)
@@ -253,6 +293,14 @@ func (clnt *Client) Close() {
 	clnt.cluster.Close()
 }
 
+// CloseGracefully stops accepting new commands, waits for the commands in flight to complete
+// until the context is done, and then closes all cached connections to the cluster nodes.
+// It returns the number of commands which were still in flight, and were aborted when the connections
+// were closed. The new commands fail with ErrClientClosing.
+func (clnt *Client) CloseGracefully(ctx context.Context) (int, Error) {
+	return clnt.cluster.CloseGracefully(ctx)
+}
+
 // IsConnected determines if the client is ready to talk to the database server cluster.
 func (clnt *Client) IsConnected() bool {
 	return clnt.cluster.IsConnected()
@@ -283,13 +331,17 @@ func (clnt *Client) GetNodeNames() []string {
 // handled when the record already exists.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) Put(policy *WritePolicy, key *Key, binMap BinMap) Error {
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
 	command, err := newWriteCommand(clnt.cluster, policy, key, nil, binMap, _WRITE)
 	if err != nil {
 		return err
 	}
 
-	return command.Execute()
+	err = clnt.journaled(func() *JournalEntry {
+		return newJournalEntry(policy, key, JournalPut, nil, binMap, nil)
+	}, clnt.inDoubtVerified(policy, key, command.Execute))
+	clnt.invalidateCached(key)
+	return err
 }
 
 // PutBins writes record bin(s) to the server.
@@ -298,13 +350,17 @@ func (clnt *Client) Put(policy *WritePolicy, key *Key, binMap BinMap) Error {
 // This method avoids using the BinMap allocation and iteration and is lighter on GC.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) PutBins(policy *WritePolicy, key *Key, bins ...*Bin) Error {
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
 	command, err := newWriteCommand(clnt.cluster, policy, key, bins, nil, _WRITE)
 	if err != nil {
 		return err
 	}
 
-	return command.Execute()
+	err = clnt.journaled(func() *JournalEntry {
+		return newJournalEntry(policy, key, JournalPut, bins, nil, nil)
+	}, clnt.inDoubtVerified(policy, key, command.Execute))
+	clnt.invalidateCached(key)
+	return err
 }
 
 //-------------------------------------------------------
@@ -317,24 +373,40 @@ func (clnt *Client) PutBins(policy *WritePolicy, key *Key, bins ...*Bin) Error {
 // This call only works for string and []byte values.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) Append(policy *WritePolicy, key *Key, binMap BinMap) Error {
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
+	if policy.WriteTokenBin != "" {
+		return clnt.tokenWrite(policy, key, nil, binMap, AppendOp)
+	}
+
 	command, err := newWriteCommand(clnt.cluster, policy, key, nil, binMap, _APPEND)
 	if err != nil {
 		return err
 	}
 
-	return command.Execute()
+	err = clnt.journaled(func() *JournalEntry {
+		return newJournalEntry(policy, key, JournalAppend, nil, binMap, nil)
+	}, clnt.inDoubtVerified(policy, key, command.Execute))
+	clnt.invalidateCached(key)
+	return err
 }
 
 // AppendBins works the same as Append, but avoids BinMap allocation and iteration.
 func (clnt *Client) AppendBins(policy *WritePolicy, key *Key, bins ...*Bin) Error {
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
+	if policy.WriteTokenBin != "" {
+		return clnt.tokenWrite(policy, key, bins, nil, AppendOp)
+	}
+
 	command, err := newWriteCommand(clnt.cluster, policy, key, bins, nil, _APPEND)
 	if err != nil {
 		return err
 	}
 
-	return command.Execute()
+	err = clnt.journaled(func() *JournalEntry {
+		return newJournalEntry(policy, key, JournalAppend, bins, nil, nil)
+	}, clnt.inDoubtVerified(policy, key, command.Execute))
+	clnt.invalidateCached(key)
+	return err
 }
 
 // Prepend prepends bin value's string to existing record bin values.
@@ -343,24 +415,40 @@ func (clnt *Client) AppendBins(policy *WritePolicy, key *Key, bins ...*Bin) Erro
 // This call works only for string and []byte values.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) Prepend(policy *WritePolicy, key *Key, binMap BinMap) Error {
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
+	if policy.WriteTokenBin != "" {
+		return clnt.tokenWrite(policy, key, nil, binMap, PrependOp)
+	}
+
 	command, err := newWriteCommand(clnt.cluster, policy, key, nil, binMap, _PREPEND)
 	if err != nil {
 		return err
 	}
 
-	return command.Execute()
+	err = clnt.journaled(func() *JournalEntry {
+		return newJournalEntry(policy, key, JournalPrepend, nil, binMap, nil)
+	}, clnt.inDoubtVerified(policy, key, command.Execute))
+	clnt.invalidateCached(key)
+	return err
 }
 
 // PrependBins works the same as Prepend, but avoids BinMap allocation and iteration.
 func (clnt *Client) PrependBins(policy *WritePolicy, key *Key, bins ...*Bin) Error {
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
+	if policy.WriteTokenBin != "" {
+		return clnt.tokenWrite(policy, key, bins, nil, PrependOp)
+	}
+
 	command, err := newWriteCommand(clnt.cluster, policy, key, bins, nil, _PREPEND)
 	if err != nil {
 		return err
 	}
 
-	return command.Execute()
+	err = clnt.journaled(func() *JournalEntry {
+		return newJournalEntry(policy, key, JournalPrepend, bins, nil, nil)
+	}, clnt.inDoubtVerified(policy, key, command.Execute))
+	clnt.invalidateCached(key)
+	return err
 }
 
 //-------------------------------------------------------
@@ -373,24 +461,40 @@ func (clnt *Client) PrependBins(policy *WritePolicy, key *Key, bins ...*Bin) Err
 // This call only works for integer values.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) Add(policy *WritePolicy, key *Key, binMap BinMap) Error {
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
+	if policy.WriteTokenBin != "" {
+		return clnt.tokenWrite(policy, key, nil, binMap, AddOp)
+	}
+
 	command, err := newWriteCommand(clnt.cluster, policy, key, nil, binMap, _ADD)
 	if err != nil {
 		return err
 	}
 
-	return command.Execute()
+	err = clnt.journaled(func() *JournalEntry {
+		return newJournalEntry(policy, key, JournalAdd, nil, binMap, nil)
+	}, clnt.inDoubtVerified(policy, key, command.Execute))
+	clnt.invalidateCached(key)
+	return err
 }
 
 // AddBins works the same as Add, but avoids BinMap allocation and iteration.
 func (clnt *Client) AddBins(policy *WritePolicy, key *Key, bins ...*Bin) Error {
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
+	if policy.WriteTokenBin != "" {
+		return clnt.tokenWrite(policy, key, bins, nil, AddOp)
+	}
+
 	command, err := newWriteCommand(clnt.cluster, policy, key, bins, nil, _ADD)
 	if err != nil {
 		return err
 	}
 
-	return command.Execute()
+	err = clnt.journaled(func() *JournalEntry {
+		return newJournalEntry(policy, key, JournalAdd, bins, nil, nil)
+	}, clnt.inDoubtVerified(policy, key, command.Execute))
+	clnt.invalidateCached(key)
+	return err
 }
 
 //-------------------------------------------------------
@@ -401,13 +505,20 @@ func (clnt *Client) AddBins(policy *WritePolicy, key *Key, bins ...*Bin) Error {
 // The policy specifies the transaction timeout.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) Delete(policy *WritePolicy, key *Key) (bool, Error) {
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
+	if err := clnt.checkDurableDelete(policy); err != nil {
+		return false, err
+	}
+
 	command, err := newDeleteCommand(clnt.cluster, policy, key)
 	if err != nil {
 		return false, err
 	}
 
-	err = command.Execute()
+	err = clnt.journaled(func() *JournalEntry {
+		return newJournalEntry(policy, key, JournalDelete, nil, nil, nil)
+	}, command.Execute)
+	clnt.invalidateCached(key)
 	return command.Existed(), err
 }
 
@@ -420,13 +531,17 @@ func (clnt *Client) Delete(policy *WritePolicy, key *Key) (bool, Error) {
 // policy's expiration.
 // If the record doesn't exist, it will return an error.
 func (clnt *Client) Touch(policy *WritePolicy, key *Key) Error {
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
 	command, err := newTouchCommand(clnt.cluster, policy, key)
 	if err != nil {
 		return err
 	}
 
-	return command.Execute()
+	err = clnt.journaled(func() *JournalEntry {
+		return newJournalEntry(policy, key, JournalTouch, nil, nil, nil)
+	}, clnt.inDoubtVerified(policy, key, command.Execute))
+	clnt.invalidateCached(key)
+	return err
 }
 
 //-------------------------------------------------------
@@ -437,13 +552,27 @@ func (clnt *Client) Touch(policy *WritePolicy, key *Key) Error {
 // The policy can be used to specify timeouts.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) Exists(policy *BasePolicy, key *Key) (bool, Error) {
-	policy = clnt.getUsablePolicy(policy)
-	command, err := newExistsCommand(clnt.cluster, policy, key)
+	policy = clnt.getUsablePolicyFor(policy, key.namespace, key.setName)
+
+	useCache := clnt.cache != nil && cacheable(policy)
+	if useCache {
+		if rec, found := clnt.cache.Get(key.digest[:]); found {
+			return rec != nil, nil
+		}
+	}
+
+	cmd, err := newExistsCommand(clnt.cluster, policy, key)
 	if err != nil {
 		return false, err
 	}
 
-	err = command.Execute()
+	winner, err := clnt.cluster.executeHedged(policy, cmd, func(ptn *Partition) (hedgedCommand, Error) {
+		return &existsCommand{singleCommand: newSingleCommand(clnt.cluster, key, ptn), policy: policy}, nil
+	})
+	command := winner.(*existsCommand)
+	if useCache && err == nil && !command.Exists() {
+		clnt.cache.Put(key.digest[:], nil)
+	}
 	return command.Exists(), err
 }
 
@@ -452,7 +581,7 @@ func (clnt *Client) Exists(policy *BasePolicy, key *Key) (bool, Error) {
 // The policy can be used to specify timeouts.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) BatchExists(policy *BatchPolicy, keys []*Key) ([]bool, Error) {
-	policy = clnt.getUsableBatchPolicy(policy)
+	policy = clnt.getUsableBatchPolicyFor(policy, keys)
 
 	// same array can be used without synchronization;
 	// when a key exists, the corresponding index will be marked true
@@ -485,35 +614,94 @@ func (clnt *Client) BatchExists(policy *BatchPolicy, keys []*Key) ([]bool, Error
 // The policy can be used to specify timeouts.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) Get(policy *BasePolicy, key *Key, binNames ...string) (*Record, Error) {
-	policy = clnt.getUsablePolicy(policy)
+	policy = clnt.getUsablePolicyFor(policy, key.namespace, key.setName)
+
+	useCache := clnt.cache != nil && cacheable(policy)
+	if useCache {
+		if rec, found := clnt.cache.Get(key.digest[:]); found {
+			if rec == nil {
+				return nil, ErrKeyNotFound.err()
+			}
+			return cachedRecord(key, rec, binNames), nil
+		}
+	}
+
+	if policy.CollapseReads && policy.FilterExpression == nil {
+		return clnt.collapsedGet(policy, key, binNames, useCache)
+	}
+	return clnt.get(policy, key, binNames, useCache)
+}
 
-	command, err := newReadCommand(clnt.cluster, policy, key, binNames, nil)
+// get reads the record from the server, and caches the result if required.
+func (clnt *Client) get(policy *BasePolicy, key *Key, binNames []string, useCache bool) (*Record, Error) {
+	cmd, err := newReadCommand(clnt.cluster, policy, key, binNames, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := command.Execute(); err != nil {
+	winner, err := clnt.cluster.executeHedged(policy, &cmd, func(ptn *Partition) (hedgedCommand, Error) {
+		hedge, err := newReadCommand(clnt.cluster, policy, key, binNames, ptn)
+		return &hedge, err
+	})
+	command := winner.(*readCommand)
+	if useCache && len(binNames) == 0 {
+		clnt.cacheReadResult(key, command.GetRecord(), err)
+	}
+
+	if err != nil {
 		return nil, err
 	}
 	return command.GetRecord(), nil
 }
 
+// GetBin reads the value of a single bin for the specified key.
+// If the record exists but does not contain the bin, a nil value is returned.
+// GetBin is a fast path for the common single bin read: the commands are pooled
+// and reused, and neither a Record nor a BinMap is allocated for the result.
+// The policy can be used to specify timeouts.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) GetBin(policy *BasePolicy, key *Key, binName string) (interface{}, Error) {
+	policy = clnt.getUsablePolicyFor(policy, key.namespace, key.setName)
+
+	if clnt.cache != nil {
+		rec, err := clnt.Get(policy, key, binName)
+		if err != nil {
+			return nil, err
+		}
+		return rec.Bins[binName], nil
+	}
+
+	command, err := newReadBinCommand(clnt.cluster, policy, key, binName)
+	if err != nil {
+		return nil, err
+	}
+	defer command.release()
+
+	if err := command.Execute(); err != nil {
+		return nil, err
+	}
+	return command.value, nil
+}
+
 // GetHeader reads a record generation and expiration only for specified key.
 // Bins are not read.
 // The policy can be used to specify timeouts.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) GetHeader(policy *BasePolicy, key *Key) (*Record, Error) {
-	policy = clnt.getUsablePolicy(policy)
+	policy = clnt.getUsablePolicyFor(policy, key.namespace, key.setName)
 
-	command, err := newReadHeaderCommand(clnt.cluster, policy, key)
+	cmd, err := newReadHeaderCommand(clnt.cluster, policy, key)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := command.Execute(); err != nil {
+	winner, err := clnt.cluster.executeHedged(policy, &cmd, func(ptn *Partition) (hedgedCommand, Error) {
+		return &readHeaderCommand{singleCommand: newSingleCommand(clnt.cluster, key, ptn), policy: policy}, nil
+	})
+	if err != nil {
 		return nil, err
 	}
-	return command.GetRecord(), nil
+	return winner.(*readHeaderCommand).GetRecord(), nil
 }
 
 //-------------------------------------------------------
@@ -526,8 +714,15 @@ func (clnt *Client) GetHeader(policy *BasePolicy, key *Key) (*Record, Error) {
 // The policy can be used to specify timeouts.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) BatchGet(policy *BatchPolicy, keys []*Key, binNames ...string) ([]*Record, Error) {
-	policy = clnt.getUsableBatchPolicy(policy)
+	policy = clnt.getUsableBatchPolicyFor(policy, keys)
 
+	if clnt.cache != nil && cacheable(&policy.BasePolicy) {
+		return clnt.cachedBatchGet(policy, keys, binNames)
+	}
+	return clnt.batchGet(policy, keys, binNames)
+}
+
+func (clnt *Client) batchGet(policy *BatchPolicy, keys []*Key, binNames []string) ([]*Record, Error) {
 	// same array can be used without synchronization;
 	// when a key exists, the corresponding index will be set to record
 	records := make([]*Record, len(keys))
@@ -556,7 +751,7 @@ func (clnt *Client) BatchGet(policy *BatchPolicy, keys []*Key, binNames ...strin
 //
 // If a batch request to a node fails, the entire batch is cancelled.
 func (clnt *Client) BatchGetOperate(policy *BatchPolicy, keys []*Key, ops ...*Operation) ([]*Record, Error) {
-	policy = clnt.getUsableBatchPolicy(policy)
+	policy = clnt.getUsableBatchPolicyFor(policy, keys)
 
 	// same array can be used without synchronization;
 	// when a key exists, the corresponding index will be set to record
@@ -587,7 +782,7 @@ func (clnt *Client) BatchGetOperate(policy *BatchPolicy, keys []*Key, ops ...*Op
 // The policy can be used to specify timeouts and maximum concurrent goroutines.
 // This method requires Aerospike Server version >= 3.6.0.
 func (clnt *Client) BatchGetComplex(policy *BatchPolicy, records []*BatchRead) Error {
-	policy = clnt.getUsableBatchPolicy(policy)
+	policy = clnt.getUsableBatchPolicyForRecords(policy, len(records), func(i int) *Key { return records[i].Key })
 
 	cmd := newBatchIndexCommandGet(clnt, nil, policy, records, true)
 
@@ -614,7 +809,7 @@ func (clnt *Client) BatchGetComplex(policy *BatchPolicy, records []*BatchRead) E
 // The policy can be used to specify timeouts.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) BatchGetHeader(policy *BatchPolicy, keys []*Key) ([]*Record, Error) {
-	policy = clnt.getUsableBatchPolicy(policy)
+	policy = clnt.getUsableBatchPolicyFor(policy, keys)
 
 	// same array can be used without synchronization;
 	// when a key exists, the corresponding index will be set to record
@@ -643,11 +838,12 @@ func (clnt *Client) BatchGetHeader(policy *BatchPolicy, keys []*Key) ([]*Record,
 //
 // Requires server version 6.0+
 func (clnt *Client) BatchDelete(policy *BatchPolicy, deletePolicy *BatchDeletePolicy, keys []*Key) ([]*BatchRecord, Error) {
-	policy = clnt.getUsableBatchPolicy(policy)
+	policy = clnt.getUsableBatchPolicyFor(policy, keys)
 	deletePolicy = clnt.getUsableBatchDeletePolicy(deletePolicy)
 
 	attr := &batchAttr{}
 	attr.setBatchDelete(deletePolicy)
+	attr.sendKey = attr.sendKey || policy.SendKey
 
 	// same array can be used without synchronization;
 	// when a key exists, the corresponding index will be set to record
@@ -663,6 +859,9 @@ func (clnt *Client) BatchDelete(policy *BatchPolicy, deletePolicy *BatchDeletePo
 
 	cmd := newBatchCommandDelete(clnt, nil, policy, deletePolicy, keys, records, attr)
 	_, err = clnt.batchExecute(policy, batchNodes, cmd)
+	for _, key := range keys {
+		clnt.invalidateCached(key)
+	}
 	return records, err
 }
 
@@ -674,7 +873,11 @@ func (clnt *Client) BatchDelete(policy *BatchPolicy, deletePolicy *BatchDeletePo
 //
 // Requires server version 6.0+
 func (clnt *Client) BatchOperate(policy *BatchPolicy, records []BatchRecordIfc) Error {
-	policy = clnt.getUsableBatchPolicy(policy)
+	policy = clnt.getUsableBatchPolicyForRecords(policy, len(records), func(i int) *Key { return records[i].key() })
+
+	if err := clnt.cluster.clientPolicy.Schemas.validateBatch(records); err != nil {
+		return err
+	}
 
 	batchNodes, err := newBatchOperateNodeListIfc(clnt.cluster, policy, records)
 	if err != nil && policy.RespondAllKeys {
@@ -683,6 +886,7 @@ func (clnt *Client) BatchOperate(policy *BatchPolicy, records []BatchRecordIfc)
 
 	cmd := newBatchCommandOperate(clnt, nil, policy, records)
 	_, err = clnt.batchExecute(policy, batchNodes, cmd)
+	clnt.invalidateCachedBatch(records)
 	return err
 }
 
@@ -694,11 +898,12 @@ func (clnt *Client) BatchOperate(policy *BatchPolicy, records []BatchRecordIfc)
 //
 // Requires server version 6.0+
 func (clnt *Client) BatchExecute(policy *BatchPolicy, udfPolicy *BatchUDFPolicy, keys []*Key, packageName string, functionName string, args ...Value) ([]*BatchRecord, Error) {
-	policy = clnt.getUsableBatchPolicy(policy)
+	policy = clnt.getUsableBatchPolicyFor(policy, keys)
 	udfPolicy = clnt.getUsableBatchUDFPolicy(udfPolicy)
 
 	attr := &batchAttr{}
 	attr.setBatchUDF(udfPolicy)
+	attr.sendKey = attr.sendKey || policy.SendKey
 
 	// same array can be used without synchronization;
 	// when a key exists, the corresponding index will be set to record
@@ -714,6 +919,11 @@ func (clnt *Client) BatchExecute(policy *BatchPolicy, udfPolicy *BatchUDFPolicy,
 
 	cmd := newBatchCommandUDF(clnt, nil, policy, udfPolicy, keys, packageName, functionName, args, records, attr)
 	_, err = clnt.batchExecute(policy, batchNodes, cmd)
+	if attr.hasWrite {
+		for _, key := range keys {
+			clnt.invalidateCached(key)
+		}
+	}
 	return records, err
 }
 
@@ -733,17 +943,46 @@ func (clnt *Client) Operate(policy *WritePolicy, key *Key, operations ...*Operat
 // useOpResults is used in batch single nodes commands and should be true to return the right type for BatchOperate results
 func (clnt *Client) operate(policy *WritePolicy, key *Key, useOpResults bool, operations ...*Operation) (*Record, Error) {
 	// TODO: Remove this method in the next major release.
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
 	args, err := newOperateArgs(clnt.cluster, policy, key, operations)
 	if err != nil {
 		return nil, err
 	}
+
+	// the policy and the operations actually sent
+	userPolicy, sent := policy, operations
+	if args.hasWrite && policy.WriteTokenBin != "" {
+		if policy, sent, err = withWriteToken(policy, operations); err != nil {
+			return nil, err
+		}
+		if args, err = newOperateArgs(clnt.cluster, policy, key, sent); err != nil {
+			return nil, err
+		}
+	}
+
 	command, err := newOperateCommand(clnt.cluster, policy, key, args, useOpResults)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := command.Execute(); err != nil {
+	if args.hasWrite {
+		execute := clnt.inDoubtVerified(policy, key, command.Execute)
+		if policy.WriteTokenBin != "" {
+			verified := execute
+			execute = func() Error {
+				return clnt.resolveWriteToken(userPolicy, policy, key, verified())
+			}
+		}
+
+		err = clnt.journaled(func() *JournalEntry {
+			return newJournalEntry(policy, key, JournalOperate, nil, nil, sent)
+		}, execute)
+		clnt.invalidateCached(key)
+	} else {
+		err = command.Execute()
+	}
+
+	if err != nil {
 		return nil, err
 	}
 	return command.GetRecord(), nil
@@ -760,7 +999,7 @@ func (clnt *Client) operate(policy *WritePolicy, key *Key, useOpResults bool, op
 // If the policy is nil, the default relevant policy will be used.
 // This method is only supported by Aerospike 4.9+ servers.
 func (clnt *Client) ScanPartitions(apolicy *ScanPolicy, partitionFilter *PartitionFilter, namespace string, setName string, binNames ...string) (*Recordset, Error) {
-	policy := *clnt.getUsableScanPolicy(apolicy)
+	policy := *clnt.getUsableScanPolicyFor(apolicy, namespace, setName)
 
 	nodes := clnt.cluster.GetNodes()
 	if len(nodes) == 0 {
@@ -792,7 +1031,7 @@ func (clnt *Client) ScanAll(apolicy *ScanPolicy, namespace string, setName strin
 // scanNodePartitions reads all records in specified namespace and set for one node only.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) scanNodePartitions(apolicy *ScanPolicy, node *Node, namespace string, setName string, binNames ...string) (*Recordset, Error) {
-	policy := *clnt.getUsableScanPolicy(apolicy)
+	policy := *clnt.getUsableScanPolicyFor(apolicy, namespace, setName)
 	tracker := newPartitionTrackerForNode(&policy.MultiPolicy, node)
 
 	// result recordset
@@ -808,6 +1047,27 @@ func (clnt *Client) ScanNode(apolicy *ScanPolicy, node *Node, namespace string,
 	return clnt.scanNodePartitions(apolicy, node, namespace, setName, binNames...)
 }
 
+// ScanNodePartitions reads records in the specified namespace, set and partition filter
+// from one node only. Partitions in the filter that are not currently owned by the node
+// for the replica policy are skipped.
+// The partition filter is updated with the progress of the scan, and can be reused
+// to retry only the partitions that are not done.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) ScanNodePartitions(apolicy *ScanPolicy, node *Node, partitionFilter *PartitionFilter, namespace string, setName string, binNames ...string) (*Recordset, Error) {
+	if partitionFilter == nil {
+		return clnt.scanNodePartitions(apolicy, node, namespace, setName, binNames...)
+	}
+
+	policy := *clnt.getUsableScanPolicyFor(apolicy, namespace, setName)
+	tracker := newPartitionTrackerForNodeFilter(&policy.MultiPolicy, partitionFilter, node)
+
+	// result recordset
+	res := newRecordset(policy.RecordQueueSize, 1)
+	go clnt.scanPartitions(&policy, tracker, namespace, setName, res, binNames...)
+
+	return res, nil
+}
+
 //---------------------------------------------------------------
 // User defined functions (Supported by Aerospike 3+ servers only)
 //---------------------------------------------------------------
@@ -833,7 +1093,8 @@ func (clnt *Client) RegisterUDFFromFile(policy *WritePolicy, clientPath string,
 // RegisterUDF registers a package containing user defined functions with server.
 // This asynchronous server call will return before command is complete.
 // The user can optionally wait for command completion by using the returned
-// RegisterTask instance.
+// RegisterTask instance, which completes when the package is registered on all
+// the nodes with the hash of udfBody.
 //
 // This method is only supported by Aerospike 3+ servers.
 // If the policy is nil, the default relevant policy will be used.
@@ -862,7 +1123,7 @@ func (clnt *Client) RegisterUDF(policy *WritePolicy, udfBody []byte, serverPath
 
 	response := responseMap[strCmd.String()]
 	if strings.EqualFold(response, "ok") {
-		return NewRegisterTask(clnt.cluster, serverPath), nil
+		return newRegisterTask(clnt.cluster, map[string]string{serverPath: udfHash(udfBody)}), nil
 	}
 
 	err = parseInfoErrorCode(response)
@@ -888,6 +1149,36 @@ func (clnt *Client) RegisterUDF(policy *WritePolicy, udfBody []byte, serverPath
 	return nil, parseInfoErrorCode(response)
 }
 
+// RegisterUDFDir registers all the packages of the language in the client directory
+// with the server, under their file names. Subdirectories are not registered.
+// The returned RegisterTask completes when all the packages are registered on all
+// the nodes with the hash of their content.
+// If the registration of a package fails, the packages before it remain registered.
+//
+// This method is only supported by Aerospike 3+ servers.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) RegisterUDFDir(policy *WritePolicy, clientDir string, language Language) (*RegisterTask, Error) {
+	policy = clnt.getUsableWritePolicy(policy)
+	names, bodies, err := readUDFDir(clientDir, language)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(names) == 0 {
+		return nil, newError(types.PARAMETER_ERROR, "No UDF packages found in "+clientDir)
+	}
+
+	packages := make(map[string]string, len(names))
+	for _, name := range names {
+		if _, err := clnt.RegisterUDF(policy, bodies[name], name, language); err != nil {
+			return nil, err
+		}
+		packages[name] = udfHash(bodies[name])
+	}
+
+	return newRegisterTask(clnt.cluster, packages), nil
+}
+
 // RemoveUDF removes a package containing user defined functions in the server.
 // This asynchronous server call will return before command is complete.
 // The user can optionally wait for command completion by using the returned
@@ -918,47 +1209,37 @@ func (clnt *Client) RemoveUDF(policy *WritePolicy, udfName string) (*RemoveTask,
 }
 
 // ListUDF lists all packages containing user defined functions in the server.
+// All the nodes are queried; UDF.Nodes lists the nodes each package is registered on,
+// and the hash is the one reported by the first node listing the package.
 // This method is only supported by Aerospike 3+ servers.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) ListUDF(policy *BasePolicy) ([]*UDF, Error) {
 	policy = clnt.getUsablePolicy(policy)
 
-	var strCmd bytes.Buffer
-	// errors are to remove errcheck warnings
-	// they will always be nil as stated in golang docs
-	strCmd.WriteString("udf-list")
-
-	// Send command to one node. That node will distribute it to other nodes.
-	responseMap, err := clnt.sendInfoCommand(policy.TotalTimeout, strCmd.String())
-	if err != nil {
-		return nil, err
+	nodes := clnt.cluster.GetNodes()
+	if len(nodes) == 0 {
+		return nil, ErrClusterIsEmpty.err()
 	}
 
-	response := responseMap[strCmd.String()]
-	vals := strings.Split(response, ";")
-	res := make([]*UDF, 0, len(vals))
-
-	for _, udfInfo := range vals {
-		if strings.Trim(udfInfo, " ") == "" {
-			continue
+	infoPolicy := InfoPolicy{Timeout: policy.TotalTimeout}
+	res := []*UDF{}
+	udfs := map[string]*UDF{}
+	for _, node := range nodes {
+		responseMap, err := node.RequestInfo(&infoPolicy, "udf-list")
+		if err != nil {
+			return nil, err
 		}
-		udfParts := strings.Split(udfInfo, ",")
-
-		udf := &UDF{}
-		for _, values := range udfParts {
-			valueParts := strings.Split(values, "=")
-			if len(valueParts) == 2 {
-				switch valueParts[0] {
-				case "filename":
-					udf.Filename = valueParts[1]
-				case "hash":
-					udf.Hash = valueParts[1]
-				case "type":
-					udf.Language = Language(valueParts[1])
-				}
+
+		for _, udf := range parseUDFList(responseMap["udf-list"]) {
+			if u, exists := udfs[udf.Filename]; exists {
+				u.Nodes = append(u.Nodes, node.GetName())
+				continue
 			}
+
+			udf.Nodes = []string{node.GetName()}
+			udfs[udf.Filename] = udf
+			res = append(res, udf)
 		}
-		res = append(res, udf)
 	}
 
 	return res, nil
@@ -986,7 +1267,7 @@ func (clnt *Client) Execute(policy *WritePolicy, key *Key, packageName string, f
 		if strings.Contains(k, "SUCCESS") {
 			return v, nil
 		} else if strings.Contains(k, "FAILURE") {
-			return nil, newError(ErrUDFBadResponse.ResultCode, fmt.Sprintf("%v", v))
+			return nil, newUDFError(ErrUDFBadResponse.ResultCode, packageName, functionName, fmt.Sprintf("%v", v))
 		}
 	}
 
@@ -994,13 +1275,15 @@ func (clnt *Client) Execute(policy *WritePolicy, key *Key, packageName string, f
 }
 
 func (clnt *Client) execute(policy *WritePolicy, key *Key, packageName string, functionName string, args ...Value) (*Record, Error) {
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
 	command, err := newExecuteCommand(clnt.cluster, policy, key, packageName, functionName, NewValueArray(args))
 	if err != nil {
 		return nil, err
 	}
 
-	if err := command.Execute(); err != nil {
+	err = command.Execute()
+	clnt.invalidateCached(key)
+	if err != nil {
 		return nil, err
 	}
 
@@ -1029,8 +1312,8 @@ func (clnt *Client) QueryExecute(policy *QueryPolicy,
 		return nil, ErrNoBinNamesAllowedInQueryExecute.err()
 	}
 
-	policy = clnt.getUsableQueryPolicy(policy)
-	writePolicy = clnt.getUsableWritePolicy(writePolicy)
+	policy = clnt.getUsableQueryPolicyFor(policy, statement)
+	writePolicy = clnt.getUsableWritePolicyFor(writePolicy, statement.Namespace, statement.SetName)
 
 	nodes := clnt.cluster.GetNodes()
 	if len(nodes) == 0 {
@@ -1064,7 +1347,7 @@ func (clnt *Client) ExecuteUDF(policy *QueryPolicy,
 	functionName string,
 	functionArgs ...Value,
 ) (*ExecuteTask, Error) {
-	policy = clnt.getUsableQueryPolicy(policy)
+	policy = clnt.getUsableQueryPolicyFor(policy, statement)
 
 	nodes := clnt.cluster.GetNodes()
 	if len(nodes) == 0 {
@@ -1099,7 +1382,7 @@ func (clnt *Client) ExecuteUDFNode(policy *QueryPolicy,
 	functionName string,
 	functionArgs ...Value,
 ) (*ExecuteTask, Error) {
-	policy = clnt.getUsableQueryPolicy(policy)
+	policy = clnt.getUsableQueryPolicyFor(policy, statement)
 
 	if node == nil {
 		return nil, ErrClusterIsEmpty.err()
@@ -1185,12 +1468,22 @@ func parseInfoErrorCode(response string) Error {
 // This method is only supported by Aerospike 4.9+ servers.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) QueryPartitions(policy *QueryPolicy, statement *Statement, partitionFilter *PartitionFilter) (*Recordset, Error) {
-	policy = clnt.getUsableQueryPolicy(policy)
+	policy = clnt.getUsableQueryPolicyFor(policy, statement)
+	if len(policy.sortKeys) > 0 {
+		return querySorted(policy, func(policy *QueryPolicy) (*Recordset, Error) {
+			return clnt.QueryPartitions(policy, statement, partitionFilter)
+		})
+	}
+
 	nodes := clnt.cluster.GetNodes()
 	if len(nodes) == 0 {
 		return nil, ErrClusterIsEmpty.err()
 	}
 
+	if err := clnt.cluster.requireFilterSupport(statement.Filter); err != nil {
+		return nil, err
+	}
+
 	var tracker *partitionTracker
 	if partitionFilter == nil {
 		tracker = newPartitionTrackerForNodes(&policy.MultiPolicy, nodes)
@@ -1226,8 +1519,30 @@ func (clnt *Client) QueryNode(policy *QueryPolicy, node *Node, statement *Statem
 	return clnt.queryNodePartitions(policy, node, statement)
 }
 
+// QueryNodePartitions executes a query for the specified partitions on a specific node and
+// returns a recordset. Partitions in the filter that are not currently owned by the node
+// for the replica policy are skipped.
+// The partition filter is updated with the progress of the query, and can be reused
+// to retry only the partitions that are not done.
+//
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) QueryNodePartitions(policy *QueryPolicy, node *Node, statement *Statement, partitionFilter *PartitionFilter) (*Recordset, Error) {
+	if partitionFilter == nil {
+		return clnt.queryNodePartitions(policy, node, statement)
+	}
+
+	policy = clnt.getUsableQueryPolicyFor(policy, statement)
+	tracker := newPartitionTrackerForNodeFilter(&policy.MultiPolicy, partitionFilter, node)
+
+	// result recordset
+	res := newRecordset(policy.RecordQueueSize, 1)
+	go clnt.queryPartitions(policy, tracker, statement, res)
+
+	return res, nil
+}
+
 func (clnt *Client) queryNodePartitions(policy *QueryPolicy, node *Node, statement *Statement) (*Recordset, Error) {
-	policy = clnt.getUsableQueryPolicy(policy)
+	policy = clnt.getUsableQueryPolicyFor(policy, statement)
 	tracker := newPartitionTrackerForNode(&policy.MultiPolicy, node)
 
 	// result recordset
@@ -1278,6 +1593,12 @@ func (clnt *Client) CreateComplexIndex(
 ) (*IndexTask, Error) {
 	policy = clnt.getUsableWritePolicy(policy)
 
+	if indexType == BLOB {
+		if err := clnt.cluster.requireServerVersion("blob index", 7, 0); err != nil {
+			return nil, err
+		}
+	}
+
 	var strCmd bytes.Buffer
 	strCmd.WriteString("sindex-create:ns=")
 	strCmd.WriteString(namespace)
@@ -1387,6 +1708,134 @@ func (clnt *Client) DropIndex(
 func (clnt *Client) Truncate(policy *InfoPolicy, namespace, set string, beforeLastUpdate *time.Time) Error {
 	policy = clnt.getUsableInfoPolicy(policy)
 
+	strCmd := truncateCommand(namespace, set, beforeLastUpdate)
+	responseMap, err := clnt.sendInfoCommand(policy.Timeout, strCmd)
+	if clnt.cache != nil {
+		clnt.cache.Clear()
+	}
+	if err != nil {
+		return err
+	}
+
+	response := responseMap[strCmd]
+	if strings.EqualFold(response, "OK") {
+		return nil
+	}
+
+	return parseInfoErrorCode(response)
+}
+
+// TruncateWithPolicy removes records in specified namespace/set, similar to Truncate.
+// If beforeLastUpdate is not zero, only the records last updated before that time are removed.
+// The cutoff is validated against the estimated clocks of the cluster nodes: cutoffs in the future
+// of any node, before the Citrusleaf epoch, or computed on a client with a clock skew larger than
+// policy.MaxClockSkew are rejected.
+// If policy.DryRun is set, the truncation is not performed, and only the estimated number of
+// affected records is returned.
+func (clnt *Client) TruncateWithPolicy(policy *TruncatePolicy, namespace, set string, beforeLastUpdate time.Time) (*TruncateResult, Error) {
+	if policy == nil {
+		policy = NewTruncatePolicy()
+	}
+
+	var lut *time.Time
+	if !beforeLastUpdate.IsZero() {
+		if beforeLastUpdate.Unix() <= types.CITRUSLEAF_EPOCH {
+			return nil, newError(types.PARAMETER_ERROR, "beforeLastUpdate must be after the Citrusleaf epoch (2010-01-01)")
+		}
+		lut = &beforeLastUpdate
+	}
+
+	res, err := clnt.truncateEstimate(policy, namespace, set, lut)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.DryRun {
+		return res, nil
+	}
+
+	if err := clnt.Truncate(&policy.InfoPolicy, namespace, set, lut); err != nil {
+		return res, err
+	}
+
+	res.Truncated = true
+	return res, nil
+}
+
+// truncateEstimate estimates the number of records affected by the truncation,
+// and validates the cutoff time against the clocks of the nodes.
+func (clnt *Client) truncateEstimate(policy *TruncatePolicy, namespace, set string, lut *time.Time) (*TruncateResult, Error) {
+	nodes := clnt.cluster.GetNodes()
+	if len(nodes) == 0 {
+		return nil, ErrClusterIsEmpty.err()
+	}
+
+	nsCmd := "namespace/" + namespace
+	setCmd := "sets/" + namespace + "/" + set
+	cmds := []string{nsCmd}
+	if len(set) > 0 {
+		cmds = append(cmds, setCmd)
+	}
+	if lut != nil {
+		cmds = append(cmds, "statistics")
+	}
+
+	res := &TruncateResult{}
+	var objects int64
+	replicationFactor := int64(1)
+	for _, node := range nodes {
+		start := time.Now()
+		info, err := node.RequestInfo(&policy.InfoPolicy, cmds...)
+		if err != nil {
+			return nil, err
+		}
+		localNow := start.Add(time.Since(start) / 2)
+
+		nsStats := parseInfoStats(info[nsCmd], ";")
+		if len(nsStats) == 0 {
+			return nil, newError(types.PARAMETER_ERROR, fmt.Sprintf("namespace `%s` not found on node %s", namespace, node.String()))
+		}
+
+		if len(set) > 0 {
+			n, _ := strconv.ParseInt(parseInfoStats(info[setCmd], ":")["objects"], 10, 64)
+			objects += n
+
+			if rf := infoReplicationFactor(nsStats); rf > replicationFactor {
+				replicationFactor = rf
+			}
+		} else {
+			n, _ := strconv.ParseInt(nsStats["master_objects"], 10, 64)
+			objects += n
+		}
+
+		if lut != nil {
+			secs, perr := strconv.ParseInt(parseInfoStats(info["statistics"], ";")["current_time"], 10, 64)
+			if perr != nil {
+				return nil, newError(types.PARSE_ERROR, fmt.Sprintf("could not determine the clock of node %s", node.String()))
+			}
+
+			serverNow := time.Unix(types.CITRUSLEAF_EPOCH+secs, 0)
+			skew := serverNow.Sub(localNow)
+			if absDuration(skew) > absDuration(res.ClockSkew) {
+				res.ClockSkew = skew
+			}
+
+			// server clock has one second resolution
+			if lut.After(serverNow.Add(time.Second)) {
+				return res, newError(types.PARAMETER_ERROR, fmt.Sprintf("beforeLastUpdate is in the future for node %s (estimated clock skew: %s)", node.String(), skew))
+			}
+
+			if policy.MaxClockSkew > 0 && absDuration(skew) > policy.MaxClockSkew+time.Second {
+				return res, newError(types.PARAMETER_ERROR, fmt.Sprintf("estimated clock skew of %s with node %s exceeds MaxClockSkew", skew, node.String()))
+			}
+		}
+	}
+
+	res.EstimatedRecords = objects / replicationFactor
+	return res, nil
+}
+
+func truncateCommand(namespace, set string, beforeLastUpdate *time.Time) string {
 	var strCmd bytes.Buffer
 	if len(set) > 0 {
 		strCmd.WriteString("truncate:namespace=")
@@ -1401,18 +1850,25 @@ func (clnt *Client) Truncate(policy *InfoPolicy, namespace, set string, beforeLa
 		strCmd.WriteString(";lut=")
 		strCmd.WriteString(strconv.FormatInt(beforeLastUpdate.UnixNano(), 10))
 	}
+	return strCmd.String()
+}
 
-	responseMap, err := clnt.sendInfoCommand(policy.Timeout, strCmd.String())
-	if err != nil {
-		return err
+// parseInfoStats parses the info responses in the key1=value1<sep>key2=value2 format.
+func parseInfoStats(response, sep string) map[string]string {
+	res := map[string]string{}
+	for _, kv := range strings.Split(strings.TrimSpace(response), sep) {
+		if k, v, found := strings.Cut(kv, "="); found {
+			res[k] = v
+		}
 	}
+	return res
+}
 
-	response := responseMap[strCmd.String()]
-	if strings.EqualFold(response, "OK") {
-		return nil
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
 	}
-
-	return parseInfoErrorCode(response)
+	return d
 }
 
 //-------------------------------------------------------
@@ -1464,7 +1920,7 @@ func (clnt *Client) DropUser(policy *AdminPolicy, user string) Error {
 func (clnt *Client) ChangePassword(policy *AdminPolicy, user string, password string) Error {
 	policy = clnt.getUsableAdminPolicy(policy)
 
-	if clnt.cluster.user == "" {
+	if clnt.cluster.userName() == "" {
 		return ErrInvalidUser.err()
 	}
 
@@ -1482,7 +1938,7 @@ func (clnt *Client) ChangePassword(policy *AdminPolicy, user string, password st
 	node.usingTendConn(policy.Timeout, func(conn *Connection) {
 		command := NewAdminCommand(nil)
 
-		if user == clnt.cluster.user {
+		if user == clnt.cluster.userName() {
 			// Change own password.
 			err = command.changePassword(conn, policy, user, clnt.cluster.Password(), hash)
 		} else {
@@ -1763,16 +2219,81 @@ func (clnt *Client) Stats() (map[string]interface{}, Error) {
 		return nil, newCommonError(err)
 	}
 
+	nodes := clnt.cluster.GetNodes()
+	for _, node := range nodes {
+		if nodeStats, ok := res[node.host.String()].(map[string]interface{}); ok {
+			nodeStats["connection-pool-shards"] = node.connections.shardStats()
+		}
+	}
+
 	res["open-connections"] = clusterStats.ConnectionsOpen.Get()
-	res["total-nodes"] = len(clnt.cluster.GetNodes())
+	res["total-nodes"] = len(nodes)
 
 	aggstats := res["cluster-aggregated-stats"].(map[string]interface{})
 	aggstats["exceeded-max-retries"] = clnt.cluster.maxRetriesExceededCount.Get()
 	aggstats["exceeded-total-timeout"] = clnt.cluster.totalTimeoutExceededCount.Get()
+	aggstats["tend-metrics"] = clnt.cluster.TendMetrics()
+	aggstats["hedged-reads-won"] = clnt.cluster.hedgedReadsWon.Get()
+	aggstats["hedged-reads-wasted"] = clnt.cluster.hedgedReadsWasted.Get()
+	aggstats["collapsed-reads"] = clnt.cluster.collapsedReads.Get()
+	if r := clnt.cluster.reconnect; r != nil {
+		aggstats["reconnect-waves"] = r.waves.Get()
+		aggstats["reconnect-seeds-delayed"] = r.seedsDelayed.Get()
+		aggstats["reconnect-connects-waited"] = r.connectsWaited.Get()
+	}
+	if t := clnt.cluster.loginThrottle; t != nil {
+		aggstats["logins-failed"] = t.failed.Get()
+		aggstats["logins-throttled"] = t.throttled.Get()
+		aggstats["logins-waited"] = t.waited.Get()
+	}
 
 	return res, nil
 }
 
+// HotKeys returns the most accessed keys in the current window, the hottest first.
+// The counts are estimated from the sampled commands; see HotKeyPolicy.
+// It returns nil if ClientPolicy.HotKeyPolicy was not set.
+func (clnt *Client) HotKeys() []HotKey {
+	if clnt.cluster.hotKeys == nil {
+		return nil
+	}
+	return clnt.cluster.hotKeys.report()
+}
+
+// WireCaptures returns the raw wire protocol buffers of the most recent sampled commands,
+// the oldest first; see WireCapturePolicy and DecodeWireMessages.
+// It returns nil if ClientPolicy.WireCapturePolicy was not set.
+func (clnt *Client) WireCaptures() []WireCapture {
+	if clnt.cluster.wireCapture == nil {
+		return nil
+	}
+	return clnt.cluster.wireCapture.report()
+}
+
+// Snapshot returns a snapshot of the nodes, the session tokens and the partition maps of the cluster.
+// A new client can be initialized from the snapshot via ClientPolicy.Snapshot without a login and a tend.
+// Use ClusterSnapshot.WriteFile to persist it.
+func (clnt *Client) Snapshot() (*ClusterSnapshot, Error) {
+	return clnt.cluster.snapshot()
+}
+
+// QuiesceNode stops routing new commands to the node, e.g. while it is drained for a rolling restart.
+// See Cluster.QuiesceNode.
+func (clnt *Client) QuiesceNode(nodeName string) Error {
+	return clnt.cluster.QuiesceNode(nodeName)
+}
+
+// UnquiesceNode resumes routing new commands to a node quiesced with QuiesceNode.
+func (clnt *Client) UnquiesceNode(nodeName string) {
+	clnt.cluster.UnquiesceNode(nodeName)
+}
+
+// TendNow refreshes the cluster immediately, instead of waiting for the next tend interval,
+// e.g. after a known failover. It blocks until the tend is over.
+func (clnt *Client) TendNow() Error {
+	return clnt.cluster.TendNow()
+}
+
 // WarmUp fills the connection pool with connections for all nodes.
 // This is necessary on startup for high traffic programs.
 // If the count is <= 0, the connection queue will be filled.
@@ -24,6 +24,7 @@ import (
 
 	lualib "github.com/aerospike/aerospike-client-go/v7/internal/lua"
 	"github.com/aerospike/aerospike-client-go/v7/logger"
+	"github.com/aerospike/aerospike-client-go/v7/types"
 	lua "github.com/yuin/gopher-lua"
 )
 
@@ -47,7 +48,7 @@ func SetLuaPath(lpath string) {
 func (clnt *Client) QueryAggregate(policy *QueryPolicy, statement *Statement, packageName, functionName string, functionArgs ...Value) (*Recordset, Error) {
 	statement.SetAggregateFunction(packageName, functionName, functionArgs, true)
 
-	policy = clnt.getUsableQueryPolicy(policy)
+	policy = clnt.getUsableQueryPolicyFor(policy, statement)
 
 	nodes := clnt.cluster.GetNodes()
 	if len(nodes) == 0 {
@@ -58,7 +59,8 @@ func (clnt *Client) QueryAggregate(policy *QueryPolicy, statement *Statement, pa
 	recSet := newRecordset(policy.RecordQueueSize, len(nodes))
 
 	// get a lua instance
-	luaInstance := lualib.LuaPool.Get().(*lua.LState)
+	luaPool := clnt.getLuaPool()
+	luaInstance, _ := luaPool.Get().(*lua.LState)
 	if luaInstance == nil {
 		return nil, ErrLuaPoolEmpty.err()
 	}
@@ -114,16 +116,17 @@ func (clnt *Client) QueryAggregate(policy *QueryPolicy, statement *Statement, pa
 		}()
 
 		for val := range outputChan {
-			recSet.records <- &Result{Record: &Record{Bins: BinMap{"SUCCESS": val}}, Err: nil}
+			// keep draining the output of the stream after the recordset is closed
+			recSet.send(&Result{Record: &Record{Bins: BinMap{"SUCCESS": val}}, Err: nil})
 		}
 	}()
 
 	go func() {
 		defer close(outputChan)
-		defer luaInstance.Close()
 
-		err := luaInstance.DoFile(lualib.Path() + packageName + ".lua")
+		err := luaInstance.DoFile(clnt.luaPath() + packageName + ".lua")
 		if err != nil {
+			luaInstance.Close()
 			recSet.sendError(newCommonError(err))
 			return
 		}
@@ -142,13 +145,33 @@ func (clnt *Client) QueryAggregate(policy *QueryPolicy, statement *Statement, pa
 		},
 			luaArgs...,
 		); err != nil {
+			luaInstance.Close()
 			recSet.sendError(newCommonError(err))
 			return
 		}
 
 		luaInstance.Get(-1) // returned value
 		luaInstance.Pop(1)  // remove received value
+
+		// the instance is only reused after a successful run
+		luaPool.Put(luaInstance)
 	}()
 
 	return recSet, nil
 }
+
+// getLuaPool returns the pool of Lua instances of the client.
+func (clnt *Client) getLuaPool() *types.Pool {
+	clnt.luaPoolOnce.Do(func() {
+		clnt.luaPool = lualib.NewPool(clnt.cluster.clientPolicy.LuaPoolSize)
+	})
+	return clnt.luaPool
+}
+
+// luaPath returns the path the stream UDFs are loaded from.
+func (clnt *Client) luaPath() string {
+	if clnt.cluster.clientPolicy.LuaPath != "" {
+		return clnt.cluster.clientPolicy.LuaPath
+	}
+	return lualib.Path()
+}
@@ -30,10 +30,7 @@ func CreateClientWithPolicyAndHost(typ ClientType, policy *ClientPolicy, hosts .
 	case CTNative:
 		return NewClientWithPolicyAndHost(policy, hosts...)
 	case CTProxy:
-		if len(hosts) > 1 {
-			return nil, newError(types.GRPC_ERROR, "Only one proxy host is acceptable")
-		}
-		return NewProxyClientWithPolicyAndHost(policy, hosts[0])
+		return NewProxyClientWithPolicyAndHosts(policy, hosts)
 	}
 	return nil, newError(types.SERVER_NOT_AVAILABLE, "Invalid client type")
 }
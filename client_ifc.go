@@ -17,10 +17,14 @@
 package aerospike
 
 import (
+	"context"
 	"time"
 )
 
 // ClientIfc abstracts an Aerospike cluster.
+// Both Client and ProxyClient implement it; the methods the proxy client does not support
+// panic when called on it. A few extensions of the native client, like DefaultPolicies and
+// NewBulkLoader, are only available on Client.
 type ClientIfc interface {
 	Add(policy *WritePolicy, key *Key, binMap BinMap) Error
 	AddBins(policy *WritePolicy, key *Key, bins ...*Bin) Error
@@ -29,35 +33,50 @@ type ClientIfc interface {
 	BatchDelete(policy *BatchPolicy, deletePolicy *BatchDeletePolicy, keys []*Key) ([]*BatchRecord, Error)
 	BatchExecute(policy *BatchPolicy, udfPolicy *BatchUDFPolicy, keys []*Key, packageName string, functionName string, args ...Value) ([]*BatchRecord, Error)
 	BatchExists(policy *BatchPolicy, keys []*Key) ([]bool, Error)
+	BatchExistsFunc(policy *BatchPolicy, keys []*Key, chunkSize int, fn func(index int, exists bool) bool) Error
 	BatchGet(policy *BatchPolicy, keys []*Key, binNames ...string) ([]*Record, Error)
 	BatchGetComplex(policy *BatchPolicy, records []*BatchRead) Error
+	BatchGetDigest(policy *BatchPolicy, keys []*Key) ([][]byte, Error)
 	BatchGetHeader(policy *BatchPolicy, keys []*Key) ([]*Record, Error)
 	BatchGetOperate(policy *BatchPolicy, keys []*Key, ops ...*Operation) ([]*Record, Error)
 	BatchOperate(policy *BatchPolicy, records []BatchRecordIfc) Error
 	ChangePassword(policy *AdminPolicy, user string, password string) Error
 	Close()
+	CloseGracefully(ctx context.Context) (int, Error)
 	Cluster() *Cluster
 	CreateComplexIndex(policy *WritePolicy, namespace string, setName string, indexName string, binName string, indexType IndexType, indexCollectionType IndexCollectionType, ctx ...*CDTContext) (*IndexTask, Error)
 	CreateIndex(policy *WritePolicy, namespace string, setName string, indexName string, binName string, indexType IndexType) (*IndexTask, Error)
 	CreateRole(policy *AdminPolicy, roleName string, privileges []Privilege, whitelist []string, readQuota, writeQuota uint32) Error
 	CreateUser(policy *AdminPolicy, user string, password string, roles []string) Error
 	Delete(policy *WritePolicy, key *Key) (bool, Error)
+	DeleteWithResult(policy *WritePolicy, key *Key) (*DeleteResult, Error)
+	DisableMetrics()
+	DocAppend(policy *WritePolicy, key *Key, binName string, path string, value interface{}) Error
+	DocDelete(policy *WritePolicy, key *Key, binName string, path string) Error
+	DocGet(policy *BasePolicy, key *Key, binName string, path string) (interface{}, Error)
+	DocSet(policy *WritePolicy, key *Key, binName string, path string, value interface{}) Error
 	DropIndex(policy *WritePolicy, namespace string, setName string, indexName string) Error
 	DropRole(policy *AdminPolicy, roleName string) Error
 	DropUser(policy *AdminPolicy, user string) Error
 	Execute(policy *WritePolicy, key *Key, packageName string, functionName string, args ...Value) (interface{}, Error)
 	ExecuteUDF(policy *QueryPolicy, statement *Statement, packageName string, functionName string, functionArgs ...Value) (*ExecuteTask, Error)
 	ExecuteUDFNode(policy *QueryPolicy, node *Node, statement *Statement, packageName string, functionName string, functionArgs ...Value) (*ExecuteTask, Error)
+	EnableMetrics(policy *MetricsPolicy)
 	Exists(policy *BasePolicy, key *Key) (bool, Error)
 	Get(policy *BasePolicy, key *Key, binNames ...string) (*Record, Error)
+	GetBin(policy *BasePolicy, key *Key, binName string) (interface{}, Error)
 	GetHeader(policy *BasePolicy, key *Key) (*Record, Error)
 	GetNodeNames() []string
 	GetNodes() []*Node
 	GrantPrivileges(policy *AdminPolicy, roleName string, privileges []Privilege) Error
 	GrantRoles(policy *AdminPolicy, user string, roles []string) Error
+	HotKeys() []HotKey
 	IsConnected() bool
 	ListUDF(policy *BasePolicy) ([]*UDF, Error)
+	MetricsEnabled() bool
 	Operate(policy *WritePolicy, key *Key, operations ...*Operation) (*Record, Error)
+	OperateReplicas(policy *WritePolicy, key *Key, operations ...*Operation) ([]*ReplicaRecord, Error)
+	OperateWithResults(policy *WritePolicy, key *Key, operations ...*Operation) (*Record, []OpResult, Error)
 	Prepend(policy *WritePolicy, key *Key, binMap BinMap) Error
 	PrependBins(policy *WritePolicy, key *Key, bins ...*Bin) Error
 	Put(policy *WritePolicy, key *Key, binMap BinMap) Error
@@ -66,27 +85,38 @@ type ClientIfc interface {
 	QueryExecute(policy *QueryPolicy, writePolicy *WritePolicy, statement *Statement, ops ...*Operation) (*ExecuteTask, Error)
 	QueryNode(policy *QueryPolicy, node *Node, statement *Statement) (*Recordset, Error)
 	queryNodePartitions(policy *QueryPolicy, node *Node, statement *Statement) (*Recordset, Error)
+	QueryNodePartitions(policy *QueryPolicy, node *Node, statement *Statement, partitionFilter *PartitionFilter) (*Recordset, Error)
 	QueryPartitions(policy *QueryPolicy, statement *Statement, partitionFilter *PartitionFilter) (*Recordset, Error)
 	QueryRole(policy *AdminPolicy, role string) (*Role, Error)
 	QueryRoles(policy *AdminPolicy) ([]*Role, Error)
 	QueryUser(policy *AdminPolicy, user string) (*UserRoles, Error)
 	QueryUsers(policy *AdminPolicy) ([]*UserRoles, Error)
+	QuiesceNode(nodeName string) Error
 	RegisterUDF(policy *WritePolicy, udfBody []byte, serverPath string, language Language) (*RegisterTask, Error)
+	RegisterUDFDir(policy *WritePolicy, clientDir string, language Language) (*RegisterTask, Error)
 	RegisterUDFFromFile(policy *WritePolicy, clientPath string, serverPath string, language Language) (*RegisterTask, Error)
 	RemoveUDF(policy *WritePolicy, udfName string) (*RemoveTask, Error)
 	RevokePrivileges(policy *AdminPolicy, roleName string, privileges []Privilege) Error
 	RevokeRoles(policy *AdminPolicy, user string, roles []string) Error
 	ScanAll(apolicy *ScanPolicy, namespace string, setName string, binNames ...string) (*Recordset, Error)
 	ScanNode(apolicy *ScanPolicy, node *Node, namespace string, setName string, binNames ...string) (*Recordset, Error)
+	ScanNodePartitions(apolicy *ScanPolicy, node *Node, partitionFilter *PartitionFilter, namespace string, setName string, binNames ...string) (*Recordset, Error)
 	ScanPartitions(apolicy *ScanPolicy, partitionFilter *PartitionFilter, namespace string, setName string, binNames ...string) (*Recordset, Error)
 	SetQuotas(policy *AdminPolicy, roleName string, readQuota, writeQuota uint32) Error
 	SetWhitelist(policy *AdminPolicy, roleName string, whitelist []string) Error
 	SetXDRFilter(policy *InfoPolicy, datacenter string, namespace string, filter *Expression) Error
 	Stats() (map[string]interface{}, Error)
 	String() string
+	TendNow() Error
 	Touch(policy *WritePolicy, key *Key) Error
 	Truncate(policy *InfoPolicy, namespace, set string, beforeLastUpdate *time.Time) Error
+	TruncateWithPolicy(policy *TruncatePolicy, namespace, set string, beforeLastUpdate time.Time) (*TruncateResult, Error)
+	ListSets(policy *InfoPolicy, namespace string) ([]*SetInfo, Error)
+	DeleteSet(policy *DeleteSetPolicy, namespace, set string) Error
+	DropSetIndexes(policy *WritePolicy, namespace, set string) ([]string, Error)
+	UnquiesceNode(nodeName string)
 	WarmUp(count int) (int, Error)
+	WireCaptures() []WireCapture
 
 	BatchGetObjects(policy *BatchPolicy, keys []*Key, objects []interface{}) (found []bool, err Error)
 	GetObject(policy *BasePolicy, key *Key, obj interface{}) Error
@@ -116,6 +146,7 @@ type ClientIfc interface {
 
 	GetDefaultPolicy() *BasePolicy
 	GetDefaultBatchPolicy() *BatchPolicy
+	GetDefaultBatchReadPolicy() *BatchReadPolicy
 	GetDefaultBatchWritePolicy() *BatchWritePolicy
 	GetDefaultBatchDeletePolicy() *BatchDeletePolicy
 	GetDefaultBatchUDFPolicy() *BatchUDFPolicy
@@ -127,6 +158,7 @@ type ClientIfc interface {
 
 	SetDefaultPolicy(*BasePolicy)
 	SetDefaultBatchPolicy(*BatchPolicy)
+	SetDefaultBatchReadPolicy(*BatchReadPolicy)
 	SetDefaultBatchWritePolicy(*BatchWritePolicy)
 	SetDefaultBatchDeletePolicy(*BatchDeletePolicy)
 	SetDefaultBatchUDFPolicy(*BatchUDFPolicy)
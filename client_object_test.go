@@ -53,6 +53,13 @@ var _ = gg.Describe("Aerospike", func() {
 			gg.BeforeEach(func() {
 				key, err = as.NewKey(ns, set, randString(50))
 				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				// the objects store uint64 values beyond math.MaxInt64
+				as.SetValuePolicy(&as.ValuePolicy{IntegerOverflow: as.IntegerOverflowWrap})
+			})
+
+			gg.AfterEach(func() {
+				as.SetValuePolicy(nil)
 			})
 
 			type SomeBool bool
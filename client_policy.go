@@ -31,6 +31,13 @@ type ClientPolicy struct {
 	// in hashed format. Leave empty for clusters running without restricted access.
 	Password string
 
+	// CredentialsProvider provides the user and password on each login instead of User and
+	// Password, to support short-lived credentials that rotate while the client is running.
+	// The commands are authenticated when it is set, even if User is empty.
+	//
+	// Default: nil
+	CredentialsProvider CredentialsProvider // = nil
+
 	// ClusterName sets the expected cluster ID.  If not nil, server nodes must return this cluster ID in order to
 	// join the client's view of the cluster. Should only be set when connecting to servers that
 	// support the "cluster-name" info command. (v3.10+)
@@ -161,6 +168,273 @@ type ClientPolicy struct {
 	// Peers nodes for the cluster are not discovered and seed nodes are
 	// retained despite connection failures.
 	SeedOnlyCluster bool // = false
+
+	// WriteJournal is an optional write-ahead journal. If set, the client records the intent of
+	// every single record write command before sending it, and marks it complete after a definitive
+	// result is received. In-doubt writes remain unresolved in the journal and can be verified
+	// or replayed via Client.RecoverJournal.
+	// UDF and batch writes are not journaled; see WriteJournal.
+	// Only the native client supports the journal.
+	//
+	// Default: nil
+	WriteJournal WriteJournal
+
+	// MaxConcurrentBatchNodes is the size of the worker pool shared between all batch commands
+	// of the client. It limits the total number of concurrent batch requests to server nodes,
+	// regardless of the BatchPolicy.ConcurrentNodes of each individual batch command.
+	// This prevents too many goroutines and connections being used when the application
+	// issues many parallel batch commands on large clusters.
+	//
+	// Default: 0 (no limit)
+	MaxConcurrentBatchNodes int // = 0
+
+	// ConnectionReconcileWindow determines how often, in tend iterations, the client compares the
+	// number of connections it tracks for each node with the number of connections the node reports
+	// for this client. Persistent differences are counted in the `connections-desync` node statistic,
+	// logged as warnings, and can be inspected via Node.ConnectionDesync. Positive differences hint at
+	// leaked connections, and negative ones at half-open connections.
+	// When enabled, each new connection identifies itself to the server with an additional info command.
+	// Requires server support for user agents; ignored for other servers.
+	//
+	// Default: 0 (disabled)
+	ConnectionReconcileWindow int // = 0
+
+	// MaxConnectionWaiters is the maximum number of commands per node allowed to wait for a connection
+	// when the connection pool is exhausted. Commands that find the wait queue full fail immediately.
+	// Only relevant for commands with BasePolicy.WaitForConnection set.
+	//
+	// Default: 0 (same as ConnectionQueueSize)
+	MaxConnectionWaiters int // = 0
+
+	// ConnectionPoolShards is the number of independent shards the connection pool of each node is split into.
+	// Commands use the shard of the processor they run on, and take connections from the other shards
+	// only when theirs is exhausted, which reduces the contention on the pool under heavy load.
+	// The pool never has more shards than ConnectionQueueSize, or 256 shards.
+	// The usage of each shard is reported in the `connection-pool-shards` node statistic of Client.Stats.
+	//
+	// Default: 0 (one shard per CPU)
+	ConnectionPoolShards int // = 0
+
+	// LockFreeConnectionPool replaces the mutex protected shards of the connection pools with lock-free rings.
+	// Like the default pool, the rings hand out the most recently used connection first, so the connections
+	// that are not needed under the current load reach IdleTimeout and get dropped.
+	// This option is experimental, and will be removed once the lock-free pool becomes the default.
+	//
+	// Default: false
+	LockFreeConnectionPool bool // = false
+
+	// MultiplexedConnectionsPerNode pipelines the single record commands (Get, Put, Operate, Delete, etc.)
+	// over this number of shared connections per node, instead of using a pooled connection for each
+	// command in flight. It reduces the number of sockets high-concurrency services need.
+	// The server processes the commands sent on a connection in order, so a slow command delays
+	// the commands behind it on the same connection. A shared connection that does not respond
+	// within Timeout while commands are waiting on it is closed, and those commands fail.
+	// Batch, scan and query commands still use the connection pool.
+	// This option is experimental.
+	//
+	// Default: 0 (disabled)
+	MultiplexedConnectionsPerNode int // = 0
+
+	// EnableZstdCompression allows the commands to be compressed with ZSTD if their
+	// BasePolicy.CompressionAlgorithm is CompressionZstd, and a compressor is registered via
+	// RegisterZstdCompressor. The servers must accept ZSTD compressed commands: Aerospike servers
+	// only accept zlib, so only set this if the commands reach servers or proxies known to inflate ZSTD.
+	// Otherwise the commands are compressed with zlib, which is counted in the compression-fallbacks
+	// node statistic.
+	//
+	// Default: false
+	EnableZstdCompression bool // = false
+
+	// ConnectionHealthCheckInterval determines how often the idle connections in the pools are
+	// probed with a lightweight info command. Connections that have not been used or probed
+	// during the interval are checked, and the ones that fail are closed and discarded.
+	// This lets the client detect half-closed connections, e.g. after a NAT or firewall idle timeout,
+	// before commands are sent on them and have to be retried.
+	// Probing a connection does not reset its IdleTimeout, so idle connections are still
+	// dropped from the pool as usual.
+	// A value of 0 disables the health checks.
+	//
+	// Default: 0
+	ConnectionHealthCheckInterval time.Duration // = 0
+
+	// TendConcurrency is the maximum number of nodes refreshed in parallel during a tend.
+	// Bounding the concurrency limits the bursts of info requests and goroutines in large clusters.
+	//
+	// Default: 0 (all the nodes are refreshed in parallel)
+	TendConcurrency int // = 0
+
+	// PartitionMapSelfHealing determines if the client forces all the nodes to refresh their
+	// partition maps, and tends the cluster immediately, when the partition map fails validation
+	// after a tend (e.g. partitions without a master node). Otherwise the map is only refreshed
+	// when the partition generation of a node changes.
+	// At most one immediate tend is scheduled per TendInterval.
+	//
+	// Default: false
+	PartitionMapSelfHealing bool // = false
+
+	// RecordCache is an optional client side cache of records. If set, Get, BatchGet and
+	// Exists commands are served from the cache when possible, and the writes issued through
+	// the client invalidate the cached entries of their keys. See RecordCache for the
+	// consistency guarantees. Use NewMemoryRecordCache for an in-memory LRU implementation.
+	// Only the native client supports the cache.
+	//
+	// Default: nil
+	RecordCache RecordCache
+
+	// HotKeyPolicy enables the detection of hot keys. If set, the client samples the keys of the
+	// single record commands, and reports the most accessed ones via Client.HotKeys.
+	// Only the native client supports the detection.
+	//
+	// Default: nil
+	HotKeyPolicy *HotKeyPolicy
+
+	// WireCapturePolicy enables the capture of the raw wire protocol buffers of the commands for
+	// debugging, e.g. to file server bugs. If set, the client captures the requests and responses of a
+	// sample of the commands, and keeps them for Client.WireCaptures. Captures contain the keys and the
+	// bins of the records, and should be handled accordingly.
+	// Only the native client supports the capture.
+	//
+	// Default: nil
+	WireCapturePolicy *WireCapturePolicy
+
+	// Interceptors are called before and after the commands sent to the nodes of the cluster,
+	// in the order they are set. See CommandInterceptor.
+	// Only the native client supports the interceptors.
+	//
+	// Default: nil
+	Interceptors []CommandInterceptor
+
+	// FaultInjector simulates faults in the communication with the cluster for testing.
+	// It must not be set in production. See FaultInjector.
+	// Only the native client supports the fault injection.
+	//
+	// Default: nil
+	FaultInjector *FaultInjector
+
+	// Clock is the source of time for the command timeouts, the tend interval and the aging of
+	// the idle connections. Tests can set a FakeClock to simulate the passage of time.
+	// If nil, the system clock is used. See Clock.
+	//
+	// Default: nil
+	Clock Clock
+
+	// Snapshot initializes the client from a snapshot of the cluster taken with Client.Snapshot,
+	// instead of logging in and tending the cluster before the client is returned. The cluster is tended
+	// on the tend interval afterwards, and the state of the snapshot is replaced as the cluster is refreshed.
+	// The session tokens of the snapshot are only used if they were issued to the same user.
+	// If the snapshot is invalid, the client is initialized from the seeds.
+	// Only the native client supports the snapshots.
+	//
+	// Default: nil
+	Snapshot *ClusterSnapshot
+
+	// LazyConnect defers the seeding of the cluster and the start of the tend goroutine to the first command,
+	// so that creating a client does not block. The first command waits for the cluster to be seeded.
+	// FailIfNotConnected is ignored, and the commands fail if the cluster could not be reached.
+	//
+	// Default: false
+	LazyConnect bool
+
+	// LightweightMode is meant for short-lived processes and function-as-a-service environments.
+	// It keeps at most one connection per node, disables the connection health check, and stops the
+	// tend goroutine after IdleTimeout without commands. The next command tends the cluster once and
+	// resumes the tend goroutine. Concurrent commands on the same node fail with a pool exhausted error,
+	// unless BasePolicy.WaitForConnection is set.
+	//
+	// Default: false
+	LightweightMode bool
+
+	// ReconnectPolicy protects the cluster from the reconnection storms of the clients after a restart
+	// of the whole cluster, by spreading the seedings of the clients with a jittered backoff and limiting
+	// the connections opened at the same time. The reconnections are reported in Client.Stats.
+	//
+	// Default: nil
+	ReconnectPolicy *ReconnectPolicy
+
+	// LoginPolicy protects the cluster from the login storms of the client when its credentials are
+	// rejected, by suspending the logins with a jittered backoff and limiting the logins in progress
+	// at the same time. The failed logins are reported in Client.Stats.
+	//
+	// Default: nil
+	LoginPolicy *LoginPolicy
+
+	// Schemas holds the schemas of the sets used by the application. The bins written by the
+	// client to a set with a schema are validated against it before the command is sent, and
+	// writes of values with the wrong type fail with a BIN_TYPE_ERROR. See SchemaRegistry.
+	//
+	// Default: nil
+	Schemas *SchemaRegistry
+
+	// DefaultSendKey determines if the user key is sent and stored with the records on all writes
+	// issued with the default policies of the client. It sets SendKey on the default WritePolicy,
+	// BatchPolicy, BatchWritePolicy, BatchDeletePolicy and BatchUDFPolicy when the client is created.
+	// Policies passed explicitly to the commands, or set later as defaults, are not affected.
+	//
+	// Default: false
+	DefaultSendKey bool // = false
+
+	// LuaPoolSize is the number of idle Lua instances the client keeps to run the stream UDFs
+	// of QueryAggregate. Each client has its own pool, so that the packages loaded by the
+	// aggregations of a client are not visible to the other clients.
+	//
+	// Default: 0 (64 instances)
+	LuaPoolSize int // = 0
+
+	// LuaPath is the path of the directory the client loads the stream UDFs of QueryAggregate from,
+	// including the trailing path separator. If empty, the path set with SetLuaPath is used.
+	//
+	// Default: ""
+	LuaPath string // = ""
+
+	// ProxyLoadBalancing determines how the proxy client distributes the commands between
+	// the proxy endpoints passed to NewProxyClientWithPolicyAndHosts.
+	// Only the proxy client uses this field.
+	//
+	// Default: ProxyRoundRobin
+	ProxyLoadBalancing ProxyLoadBalancing // = ProxyRoundRobin
+
+	// ProxyChannelsPerEndpoint is the number of gRPC channels the proxy client opens to each
+	// proxy endpoint. Each channel is a single HTTP/2 connection that multiplexes the concurrent
+	// commands, and the commands are sent on the channel with the fewest commands in flight.
+	// The channels are opened on demand.
+	// Only the proxy client uses this field.
+	//
+	// Default: 0 (4 channels)
+	ProxyChannelsPerEndpoint int // = 0
+
+	// ProxyStreamsPerChannel is the maximum number of concurrent commands on each gRPC channel.
+	// Commands that find all the channels of the endpoints at the limit fail with
+	// NO_AVAILABLE_CONNECTIONS_TO_NODE. Keep the value below the max concurrent streams
+	// setting of the proxy server, otherwise the commands above it are queued by gRPC.
+	// Only the proxy client uses this field.
+	//
+	// Default: 0 (no limit)
+	ProxyStreamsPerChannel int // = 0
+
+	// ProxyHealthCheckInterval determines how often the proxy client checks the state of the
+	// channels of each proxy endpoint. Endpoints whose channels all failed are skipped by the
+	// load balancer until one of their channels reconnects. If all the endpoints are unhealthy,
+	// the commands are sent to them anyway.
+	// A value of 0 disables the health checks.
+	// Only the proxy client uses this field.
+	//
+	// Default: 0
+	ProxyHealthCheckInterval time.Duration // = 0
+
+	// ProxyTokenSource provides the access tokens of the proxy client instead of the login
+	// with User and Password. The commands are authenticated with the tokens of the source
+	// when it is set, even if User is empty.
+	// Only the proxy client uses this field.
+	//
+	// Default: nil
+	ProxyTokenSource ProxyTokenSource // = nil
+
+	// ProxyTokenRefreshBefore determines how long before the expiry of its access token the proxy
+	// client fetches a new token. The token is refreshed no later than half of its lifetime.
+	// Only the proxy client uses this field.
+	//
+	// Default: 0 (5 seconds)
+	ProxyTokenRefreshBefore time.Duration // = 0
 }
 
 // NewClientPolicy generates a new ClientPolicy with default values.
@@ -182,9 +456,9 @@ func NewClientPolicy() *ClientPolicy {
 	}
 }
 
-// RequiresAuthentication returns true if a User or Password is set for ClientPolicy.
+// RequiresAuthentication returns true if a User, Password or CredentialsProvider is set for ClientPolicy.
 func (cp *ClientPolicy) RequiresAuthentication() bool {
-	return (cp.User != "") || (cp.Password != "") || (cp.AuthMode == AuthModePKI)
+	return (cp.User != "") || (cp.Password != "") || (cp.AuthMode == AuthModePKI) || (cp.CredentialsProvider != nil)
 }
 
 func (cp *ClientPolicy) servicesString() string {
@@ -34,17 +34,24 @@ import (
 //			Name string `as:"name"`
 //	 		Address string `as:"desc,omitempty"`
 //	 		Age uint8 `as:",omitempty"`
+//	 		Born time.Time `as:"born,omitzero"`
+//	 		Home Address `asm:"prefix=home_"`
 //	 		Password string `as:"-"`
 //	 }
 //
 // Tag `as:` denotes Aerospike fields. The first value will be the alias for the field.
 // `,omitempty` (without any spaces between the comma and the word) will act like the
 // json package, and will not send the value of the field to the database if the value is zero value.
+// `,omitzero` will not send the value of the field if it is the zero value of its type,
+// or if the value has an `IsZero() bool` method returning true, like time.Time.
 // Tag `asm:` denotes Aerospike Meta fields, and includes ttl and generation values.
+// Tag `asm:"prefix=..."` flattens the fields of a nested struct into the bins of the record,
+// prefixing their names, e.g. the `Street` field of `Home` above will be stored in bin `home_Street`.
 // If a tag is marked with `-`, it will not be sent to the database at all.
 // Note: Tag `as` can be replaced with any other user-defined tag via the function `SetAerospikeTag`.
+// The names of the fields without an alias can be mapped to bin names via the function `SetNameMapper`.
 func (clnt *Client) PutObject(policy *WritePolicy, key *Key, obj interface{}) (err Error) {
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
 
 	binMap := marshal(obj)
 	command, err := newWriteCommand(clnt.cluster, policy, key, nil, binMap, _WRITE)
@@ -53,6 +60,7 @@ func (clnt *Client) PutObject(policy *WritePolicy, key *Key, obj interface{}) (e
 	}
 
 	res := command.Execute()
+	clnt.invalidateCached(key)
 	return res
 }
 
@@ -60,7 +68,7 @@ func (clnt *Client) PutObject(policy *WritePolicy, key *Key, obj interface{}) (e
 // The policy can be used to specify timeouts.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) GetObject(policy *BasePolicy, key *Key, obj interface{}) Error {
-	policy = clnt.getUsablePolicy(policy)
+	policy = clnt.getUsablePolicyFor(policy, key.namespace, key.setName)
 
 	rval := reflect.ValueOf(obj)
 	binNames := objectMappings.getFields(rval.Type())
@@ -83,7 +91,7 @@ func (clnt *Client) GetObject(policy *BasePolicy, key *Key, obj interface{}) Err
 // The policy can be used to specify timeouts.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) getObjectDirect(policy *BasePolicy, key *Key, rval *reflect.Value) Error {
-	policy = clnt.getUsablePolicy(policy)
+	policy = clnt.getUsablePolicyFor(policy, key.namespace, key.setName)
 
 	binNames := objectMappings.getFields(rval.Type())
 	command, err := newReadCommand(clnt.cluster, policy, key, binNames, nil)
@@ -101,7 +109,7 @@ func (clnt *Client) getObjectDirect(policy *BasePolicy, key *Key, rval *reflect.
 // The policy can be used to specify timeouts.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) BatchGetObjects(policy *BatchPolicy, keys []*Key, objects []interface{}) (found []bool, err Error) {
-	policy = clnt.getUsableBatchPolicy(policy)
+	policy = clnt.getUsableBatchPolicyFor(policy, keys)
 
 	// check the size of  key and objects
 	if len(keys) != len(objects) {
@@ -155,7 +163,7 @@ func (clnt *Client) BatchGetObjects(policy *BatchPolicy, keys []*Key, objects []
 // If the policy is nil, the default relevant policy will be used.
 // This method is only supported by Aerospike 4.9+ servers.
 func (clnt *Client) ScanPartitionObjects(apolicy *ScanPolicy, objChan interface{}, partitionFilter *PartitionFilter, namespace string, setName string, binNames ...string) (*Recordset, Error) {
-	policy := *clnt.getUsableScanPolicy(apolicy)
+	policy := *clnt.getUsableScanPolicyFor(apolicy, namespace, setName)
 
 	nodes := clnt.cluster.GetNodes()
 	if len(nodes) == 0 {
@@ -189,7 +197,7 @@ func (clnt *Client) ScanAllObjects(apolicy *ScanPolicy, objChan interface{}, nam
 // scanNodePartitions reads all records in specified namespace and set for one node only.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) scanNodePartitionsObjects(apolicy *ScanPolicy, node *Node, objChan interface{}, namespace string, setName string, binNames ...string) (*Recordset, Error) {
-	policy := *clnt.getUsableScanPolicy(apolicy)
+	policy := *clnt.getUsableScanPolicyFor(apolicy, namespace, setName)
 
 	tracker := newPartitionTrackerForNode(&policy.MultiPolicy, node)
 
@@ -219,7 +227,7 @@ func (clnt *Client) ScanNodeObjects(apolicy *ScanPolicy, node *Node, objChan int
 // This method is only supported by Aerospike 4.9+ servers.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) QueryPartitionObjects(policy *QueryPolicy, statement *Statement, objChan interface{}, partitionFilter *PartitionFilter) (*Recordset, Error) {
-	policy = clnt.getUsableQueryPolicy(policy)
+	policy = clnt.getUsableQueryPolicyFor(policy, statement)
 
 	nodes := clnt.cluster.GetNodes()
 	if len(nodes) == 0 {
@@ -254,7 +262,7 @@ func (clnt *Client) QueryObjects(policy *QueryPolicy, statement *Statement, objC
 }
 
 func (clnt *Client) queryNodePartitionsObjects(policy *QueryPolicy, node *Node, statement *Statement, objChan interface{}) (*Recordset, Error) {
-	policy = clnt.getUsableQueryPolicy(policy)
+	policy = clnt.getUsableQueryPolicyFor(policy, statement)
 
 	tracker := newPartitionTrackerForNode(&policy.MultiPolicy, node)
 
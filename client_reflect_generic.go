@@ -0,0 +1,188 @@
+//go:build !as_performance
+
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+// ObjectIterator iterates over the objects of a scan or query returned by the generic
+// QueryObjects and ScanAllObjects functions.
+//
+// Example:
+//
+//	it, err := aerospike.QueryObjects[Person](client, nil, stmt)
+//	handleError(err)
+//	defer it.Close()
+//	for it.Next() {
+//	  fmt.Println(it.Object().Name)
+//	}
+//	handleError(it.Err())
+type ObjectIterator[T any] struct {
+	rs      *Recordset
+	objects chan *T
+	errors  <-chan Error
+
+	obj  *T
+	err  Error
+	done bool
+}
+
+func newObjectIterator[T any](rs *Recordset, objects chan *T) *ObjectIterator[T] {
+	return &ObjectIterator[T]{
+		rs:      rs,
+		objects: objects,
+		errors:  rs.Errors(),
+	}
+}
+
+// Next advances the iterator to the next object, which will then be available through
+// the Object method. It returns false when the iteration stops, either by reaching the end
+// of the results or on the first error. After Next returns false, the Err method will return
+// the error that stopped the iteration, if any.
+func (it *ObjectIterator[T]) Next() bool {
+	if it.done {
+		return false
+	}
+
+	for {
+		select {
+		case obj, ok := <-it.objects:
+			if !ok {
+				// the objects channel is closed before the errors channel;
+				// drain the errors that were sent before the end
+				if it.errors != nil {
+					for err := range it.errors {
+						if it.err == nil {
+							it.err = err
+						}
+					}
+				}
+				it.stop()
+				return false
+			}
+			it.obj = obj
+			return true
+		case err, ok := <-it.errors:
+			if !ok {
+				// keep consuming the buffered objects
+				it.errors = nil
+				continue
+			}
+			it.err = err
+			it.stop()
+			return false
+		}
+	}
+}
+
+// Object returns the current object of the iteration.
+func (it *ObjectIterator[T]) Object() *T {
+	return it.obj
+}
+
+// Err returns the error that stopped the iteration, or nil if the iteration
+// reached the end of the results.
+func (it *ObjectIterator[T]) Err() Error {
+	return it.err
+}
+
+// Recordset returns the underlying recordset of the iteration.
+func (it *ObjectIterator[T]) Recordset() *Recordset {
+	return it.rs
+}
+
+// Close stops the iteration and closes the underlying recordset.
+// It is safe to call Close multiple times, and after Next has returned false.
+func (it *ObjectIterator[T]) Close() Error {
+	it.stop()
+	return nil
+}
+
+func (it *ObjectIterator[T]) stop() {
+	if !it.done {
+		it.done = true
+		it.obj = nil
+		it.rs.Close()
+	}
+}
+
+// QueryObjects executes a query and returns an iterator over the records
+// marshalled into objects of type T.
+// See Client.PutObject for the tags influencing the way the objects are filled.
+// If the policy is nil, the default relevant policy of the client will be used.
+func QueryObjects[T any](clnt ClientIfc, policy *QueryPolicy, statement *Statement) (*ObjectIterator[T], Error) {
+	if policy == nil {
+		policy = clnt.GetDefaultQueryPolicy()
+	}
+
+	objects := make(chan *T, policy.RecordQueueSize)
+	rs, err := clnt.QueryObjects(policy, statement, objects)
+	if err != nil {
+		return nil, err
+	}
+	return newObjectIterator(rs, objects), nil
+}
+
+// ScanAllObjects reads all records in specified namespace and set, and returns an
+// iterator over the records marshalled into objects of type T.
+// See Client.PutObject for the tags influencing the way the objects are filled.
+// If the policy is nil, the default relevant policy of the client will be used.
+func ScanAllObjects[T any](clnt ClientIfc, policy *ScanPolicy, namespace string, setName string, binNames ...string) (*ObjectIterator[T], Error) {
+	if policy == nil {
+		policy = clnt.GetDefaultScanPolicy()
+	}
+
+	objects := make(chan *T, policy.RecordQueueSize)
+	rs, err := clnt.ScanAllObjects(policy, objects, namespace, setName, binNames...)
+	if err != nil {
+		return nil, err
+	}
+	return newObjectIterator(rs, objects), nil
+}
+
+// GetObject reads the record for the specified key and returns it marshalled into
+// a new object of type T.
+// If the policy is nil, the default relevant policy of the client will be used.
+func GetObject[T any](clnt ClientIfc, policy *BasePolicy, key *Key) (*T, Error) {
+	obj := new(T)
+	if err := clnt.GetObject(policy, key, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// BatchGetObjects reads the records for the specified keys in one batch request, and returns
+// them marshalled into new objects of type T, in positional order with the keys.
+// The positional object of a key that is not found will be nil.
+// If the policy is nil, the default relevant policy of the client will be used.
+func BatchGetObjects[T any](clnt ClientIfc, policy *BatchPolicy, keys []*Key) ([]*T, Error) {
+	res := make([]*T, len(keys))
+	objects := make([]interface{}, len(keys))
+	for i := range keys {
+		res[i] = new(T)
+		objects[i] = res[i]
+	}
+
+	found, err := clnt.BatchGetObjects(policy, keys, objects)
+	if found == nil {
+		return nil, err
+	}
+
+	for i := range found {
+		if !found[i] {
+			res[i] = nil
+		}
+	}
+	return res, err
+}
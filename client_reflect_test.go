@@ -61,6 +61,10 @@ var _ = gg.Describe("Aerospike", func() {
 					})
 
 					gg.It("must save a key with Array Types", func() {
+						// the lists store uint64 values beyond math.MaxInt64
+						as.SetValuePolicy(&as.ValuePolicy{IntegerOverflow: as.IntegerOverflowWrap})
+						defer as.SetValuePolicy(nil)
+
 						bin1 := as.NewBin("Aerospike1", []int8{math.MinInt8, 0, 1, 2, 3, math.MaxInt8})
 						bin2 := as.NewBin("Aerospike2", []int16{math.MinInt16, 0, 1, 2, 3, math.MaxInt16})
 						bin3 := as.NewBin("Aerospike3", []int32{math.MinInt32, 0, 1, 2, 3, math.MaxInt32})
@@ -168,6 +172,10 @@ var _ = gg.Describe("Aerospike", func() {
 				gg.Context("Bins with LIST type", func() {
 
 					gg.It("must save a key with Array Types", func() {
+						// the lists store uint64 values beyond math.MaxInt64
+						as.SetValuePolicy(&as.ValuePolicy{IntegerOverflow: as.IntegerOverflowWrap})
+						defer as.SetValuePolicy(nil)
+
 						bin1 := as.NewBin("Aerospike1", []interface{}{math.MinInt8, 0, 1, 2, 3, math.MaxInt8})
 						bin2 := as.NewBin("Aerospike2", []interface{}{math.MinInt16, 0, 1, 2, 3, math.MaxInt16})
 						bin3 := as.NewBin("Aerospike3", []interface{}{math.MinInt32, 0, 1, 2, 3, math.MaxInt32})
@@ -22,6 +22,7 @@ import (
 	"math/rand"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	as "github.com/aerospike/aerospike-client-go/v7"
@@ -663,6 +664,10 @@ var _ = gg.Describe("Aerospike", func() {
 				gg.Context("Bins with LIST type", func() {
 
 					gg.It("must save a key with Array Types", func() {
+						// the lists store uint64 values beyond math.MaxInt64
+						as.SetValuePolicy(&as.ValuePolicy{IntegerOverflow: as.IntegerOverflowWrap})
+						defer as.SetValuePolicy(nil)
+
 						// All int types and sizes should be encoded into an int64,
 						// unless if they are of type uint64, which always encodes to uint64
 						// regardless of the values inside
@@ -812,6 +817,72 @@ var _ = gg.Describe("Aerospike", func() {
 				gm.Expect(len(rec.Bins)).To(gm.Equal(2))
 				gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"bin1": 1, "bin2": 2}))
 			})
+
+			gg.It("must get the value of a single bin", func() {
+				if *proxy {
+					gg.Skip("Not supported in Proxy Client")
+				}
+
+				err := client.Put(wpolicy, key, as.BinMap{"bin1": 1, "bin2": "value"})
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				v, err := nativeClient.GetBin(rpolicy, key, "bin2")
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(v).To(gm.Equal("value"))
+
+				v, err = nativeClient.GetBin(rpolicy, key, "bin3")
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(v).To(gm.BeNil())
+
+				_, err = client.Delete(wpolicy, key)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				_, err = nativeClient.GetBin(rpolicy, key, "bin1")
+				gm.Expect(err).To(gm.HaveOccurred())
+				gm.Expect(err.Matches(ast.KEY_NOT_FOUND_ERROR)).To(gm.BeTrue())
+			})
+
+			gg.It("must collapse identical concurrent reads", func() {
+				if *proxy {
+					gg.Skip("Not supported in Proxy Client")
+				}
+
+				err := client.Put(wpolicy, key, as.BinMap{"bin1": 1, "bin2": "value"})
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				cpolicy := *rpolicy
+				cpolicy.CollapseReads = true
+
+				const readers = 50
+				recs := make([]*as.Record, readers)
+				errs := make([]error, readers)
+				var wg sync.WaitGroup
+				for i := 0; i < readers; i++ {
+					wg.Add(1)
+					go func(i int) {
+						defer wg.Done()
+						var err as.Error
+						recs[i], err = nativeClient.Get(&cpolicy, key, "bin1", "bin2")
+						if err != nil {
+							errs[i] = err
+						}
+					}(i)
+				}
+				wg.Wait()
+
+				for i := 0; i < readers; i++ {
+					gm.Expect(errs[i]).ToNot(gm.HaveOccurred())
+					gm.Expect(recs[i].Bins).To(gm.Equal(as.BinMap{"bin1": 1, "bin2": "value"}))
+				}
+
+				// every caller owns its record
+				recs[0].Bins["bin1"] = 2
+				gm.Expect(recs[readers-1].Bins["bin1"]).To(gm.Equal(1))
+
+				stats, err := nativeClient.Stats()
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(stats["cluster-aggregated-stats"]).To(gm.HaveKey("collapsed-reads"))
+			})
 		})
 
 		gg.Context("Append operations", func() {
@@ -902,6 +973,38 @@ var _ = gg.Describe("Aerospike", func() {
 				gm.Expect(rec.Bins[bin.Name]).To(gm.Equal(addBin.Value.GetObject().(int) + bin.Value.GetObject().(int)))
 			})
 
+			gg.It("must Add only once when retried with the same write token", func() {
+				if *proxy {
+					gg.Skip("Not supported in Proxy Client")
+				}
+
+				tpolicy := as.NewWritePolicy(0, 0)
+				tpolicy.WriteTokenBin = "wtoken"
+				tpolicy.WriteToken, err = as.NewWriteToken()
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				addBin := as.NewBin(bin.Name, rand.Intn(math.MaxInt16))
+				for i := 0; i < 3; i++ {
+					err = client.AddBins(tpolicy, key, addBin)
+					gm.Expect(err).ToNot(gm.HaveOccurred())
+				}
+
+				rec, err = client.Get(rpolicy, key)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(rec.Bins[bin.Name]).To(gm.Equal(addBin.Value.GetObject().(int) + bin.Value.GetObject().(int)))
+				gm.Expect(rec.Bins["wtoken"]).To(gm.Equal(tpolicy.WriteToken))
+
+				// a new token applies the write again
+				tpolicy.WriteToken, err = as.NewWriteToken()
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				err = client.AddBins(tpolicy, key, addBin)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				rec, err = client.Get(rpolicy, key)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(rec.Bins[bin.Name]).To(gm.Equal(2*addBin.Value.GetObject().(int) + bin.Value.GetObject().(int)))
+			})
+
 		}) // add context
 
 		gg.Context("Delete operations", func() {
@@ -934,6 +1037,36 @@ var _ = gg.Describe("Aerospike", func() {
 				gm.Expect(existed).To(gm.Equal(false))
 			})
 
+			gg.It("must report tombstones of durable deletes", func() {
+				var res *as.DeleteResult
+				res, err = client.DeleteWithResult(wpolicy, key)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(res.Existed).To(gm.BeTrue())
+				gm.Expect(res.Tombstone).To(gm.BeFalse())
+
+				err = client.PutBins(wpolicy, key, bin)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				dpolicy := as.NewWritePolicy(0, 0)
+				dpolicy.DurableDelete = true
+				dpolicy.RequireDurableDelete = true
+				var derr as.Error
+				res, derr = client.DeleteWithResult(dpolicy, key)
+				if !isEnterpriseEdition() {
+					gm.Expect(derr).To(gm.HaveOccurred())
+					gm.Expect(derr.Matches(ast.ENTERPRISE_ONLY)).To(gm.BeTrue())
+					return
+				}
+				gm.Expect(derr).ToNot(gm.HaveOccurred())
+				gm.Expect(res.Existed).To(gm.BeTrue())
+				gm.Expect(res.Tombstone).To(gm.BeTrue())
+
+				res, err = client.DeleteWithResult(dpolicy, key)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(res.Existed).To(gm.BeFalse())
+				gm.Expect(res.Tombstone).To(gm.BeFalse())
+			})
+
 		}) // Delete context
 
 		gg.Context("Touch operations", func() {
@@ -1451,6 +1584,34 @@ var _ = gg.Describe("Aerospike", func() {
 			}
 		}) // Batch Get Header context
 
+		gg.Context("BatchGetDigest operations", func() {
+			bin := as.NewBin("Aerospike", rand.Int())
+
+			gg.It("must return the digests of the existing records passing the filter expression", func() {
+				keys := []*as.Key{}
+				for i := 0; i < 10; i++ {
+					key, err := as.NewKey(ns, set, randString(50))
+					gm.Expect(err).ToNot(gm.HaveOccurred())
+					keys = append(keys, key)
+
+					if i%2 == 0 {
+						err = client.PutBins(wpolicy, key, as.NewBin(bin.Name, i))
+						gm.Expect(err).ToNot(gm.HaveOccurred())
+					}
+				}
+
+				digests, err := client.BatchGetDigest(nil, keys)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(digests).To(gm.Equal([][]byte{keys[0].Digest(), keys[2].Digest(), keys[4].Digest(), keys[6].Digest(), keys[8].Digest()}))
+
+				bpolicy := as.NewBatchPolicy()
+				bpolicy.FilterExpression = as.ExpGreater(as.ExpIntBin(bin.Name), as.ExpIntVal(4))
+				digests, err = client.BatchGetDigest(bpolicy, keys)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(digests).To(gm.Equal([][]byte{keys[6].Digest(), keys[8].Digest()}))
+			})
+		}) // BatchGetDigest context
+
 		gg.Context("Operate operations", func() {
 			bin1 := as.NewBin("Aerospike1", rand.Intn(math.MaxInt16))
 			bin2 := as.NewBin("Aerospike2", randString(100))
@@ -1484,6 +1645,114 @@ var _ = gg.Describe("Aerospike", func() {
 				gm.Expect(err.Error()).To(gm.ContainSubstring("No operations were passed."))
 			})
 
+			gg.It("must return the result of each operation in order", func() {
+				key, err := as.NewKey(ns, set, randString(50))
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				ops := []*as.Operation{
+					as.PutOp(as.NewBin("count", 1)),
+					as.AddOp(as.NewBin("count", 2)),
+					as.GetBinOp("count"),
+					as.AddOp(as.NewBin("count", 3)),
+					as.GetBinOp("count"),
+				}
+
+				rec, results, err := client.OperateWithResults(nil, key, ops...)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(rec.Generation).To(gm.Equal(uint32(1)))
+				gm.Expect(len(results)).To(gm.Equal(len(ops)))
+				for i, res := range results {
+					gm.Expect(res.Op).To(gm.BeIdenticalTo(ops[i]))
+					gm.Expect(res.BinName).To(gm.Equal("count"))
+				}
+				gm.Expect(results[2].Value).To(gm.Equal(3))
+				gm.Expect(results[4].Value).To(gm.Equal(6))
+
+				_, _, err = client.OperateWithResults(nil, key, as.GetOp())
+				gm.Expect(err).To(gm.HaveOccurred())
+				gm.Expect(err.Matches(ast.PARAMETER_ERROR)).To(gm.BeTrue())
+			})
+
+			gg.It("must read every replica of a record", func() {
+				if *proxy {
+					gg.Skip("Not supported in Proxy Client")
+				}
+
+				key, err := as.NewKey(ns, set, randString(50))
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				before := time.Now().Add(-time.Minute)
+				err = client.Put(nil, key, as.BinMap{"bin1": 1, "bin2": "value"})
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				replicas, err := client.OperateReplicas(nil, key)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(len(replicas)).To(gm.BeNumerically(">=", 1))
+				for i, replica := range replicas {
+					gm.Expect(replica.Replica).To(gm.Equal(i))
+					if replica.Node == nil {
+						continue
+					}
+					gm.Expect(replica.Err).ToNot(gm.HaveOccurred())
+					gm.Expect(replica.Record.Bins).To(gm.Equal(as.BinMap{"bin1": 1, "bin2": "value"}))
+					gm.Expect(replica.Record.Generation).To(gm.Equal(uint32(1)))
+					gm.Expect(replica.LastUpdateTime.After(before)).To(gm.BeTrue())
+				}
+
+				_, err = client.OperateReplicas(nil, key, as.TouchOp())
+				gm.Expect(err.Matches(ast.PARAMETER_ERROR)).To(gm.BeTrue())
+			})
+
+			gg.It("must read the last update time of a record", func() {
+				key, err := as.NewKey(ns, set, randString(50))
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				before := time.Now().Add(-time.Minute)
+				err = client.Put(nil, key, as.BinMap{"bin1": 1})
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				rec, err := client.Operate(nil, key, as.GetOp(), as.LastUpdateTimeOp())
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"bin1": 1}))
+				gm.Expect(rec.LastUpdateTime.After(before)).To(gm.BeTrue())
+
+				rec, err = client.Get(nil, key)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(rec.LastUpdateTime.IsZero()).To(gm.BeTrue())
+			})
+
+			gg.It("must manipulate JSON documents with the document API", func() {
+				key, err := as.NewKey(ns, set, randString(50))
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				doc := map[string]interface{}{
+					"store": map[string]interface{}{
+						"book": []interface{}{
+							map[string]interface{}{"title": "Go", "price": 10},
+						},
+					},
+				}
+				gm.Expect(client.DocSet(nil, key, "doc", "$", doc)).ToNot(gm.HaveOccurred())
+
+				v, err := client.DocGet(nil, key, "doc", "$.store.book[0].title")
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(v).To(gm.Equal("Go"))
+
+				gm.Expect(client.DocSet(nil, key, "doc", "$.store.book[0].price", 12)).ToNot(gm.HaveOccurred())
+				gm.Expect(client.DocAppend(nil, key, "doc", "$.store.book", map[string]interface{}{"title": "Lua"})).ToNot(gm.HaveOccurred())
+				gm.Expect(client.DocDelete(nil, key, "doc", "$['store'].book[0].title")).ToNot(gm.HaveOccurred())
+
+				v, err = client.DocGet(nil, key, "doc", "$.store.book")
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(v).To(gm.Equal([]interface{}{
+					map[interface{}]interface{}{"price": 12},
+					map[interface{}]interface{}{"title": "Lua"},
+				}))
+
+				_, err = client.DocGet(nil, key, "doc", "$.store.*")
+				gm.Expect(err.Matches(ast.PARAMETER_ERROR)).To(gm.BeTrue())
+			})
+
 			gg.It("must send key on Put operations", func() {
 				key, err := as.NewKey(ns, set, randString(50))
 				gm.Expect(err).ToNot(gm.HaveOccurred())
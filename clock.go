@@ -0,0 +1,144 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time of the client for the command timeouts, the tend interval
+// and the aging of the idle connections. Set it via ClientPolicy.Clock to simulate the
+// passage of time in the tests without sleeping, e.g. with FakeClock.
+//
+// The socket deadlines are always set in real time, for the time remaining on the Clock.
+// The latency metrics are always measured in real time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After waits for the duration to elapse and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+
+	// Sleep pauses the current goroutine for at least the duration.
+	Sleep(d time.Duration)
+}
+
+// systemClock is the Clock of the time package.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (systemClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+
+// SystemClock is the Clock of the time package. It is used if ClientPolicy.Clock is not set.
+var SystemClock Clock = systemClock{}
+
+// clockOrSystem returns the clock, or the system clock if it is nil.
+func clockOrSystem(clock Clock) Clock {
+	if clock == nil {
+		return SystemClock
+	}
+	return clock
+}
+
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// FakeClock is a Clock for the tests whose time only changes via Advance and Set.
+// Timers and sleeps on the clock complete once the clock is advanced past their deadline.
+// It is safe for concurrent use.
+type FakeClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// NewFakeClock returns a FakeClock set to the time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the current time of the clock.
+func (fc *FakeClock) Now() time.Time {
+	fc.mutex.Lock()
+	defer fc.mutex.Unlock()
+
+	return fc.now
+}
+
+// After returns a channel which receives the time of the clock once it is advanced by the duration.
+func (fc *FakeClock) After(d time.Duration) <-chan time.Time {
+	fc.mutex.Lock()
+	defer fc.mutex.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- fc.now
+		return ch
+	}
+
+	fc.timers = append(fc.timers, &fakeTimer{at: fc.now.Add(d), ch: ch})
+	return ch
+}
+
+// Sleep blocks until the clock is advanced by the duration.
+func (fc *FakeClock) Sleep(d time.Duration) {
+	<-fc.After(d)
+}
+
+// Advance moves the clock forward by the duration, and fires the timers which are due.
+func (fc *FakeClock) Advance(d time.Duration) {
+	fc.mutex.Lock()
+	defer fc.mutex.Unlock()
+
+	fc.set(fc.now.Add(d))
+}
+
+// Set sets the time of the clock, and fires the timers which are due.
+func (fc *FakeClock) Set(now time.Time) {
+	fc.mutex.Lock()
+	defer fc.mutex.Unlock()
+
+	fc.set(now)
+}
+
+func (fc *FakeClock) set(now time.Time) {
+	fc.now = now
+
+	pending := fc.timers[:0]
+	for _, t := range fc.timers {
+		if t.at.After(now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.ch <- now
+	}
+	for i := len(pending); i < len(fc.timers); i++ {
+		fc.timers[i] = nil
+	}
+	fc.timers = pending
+}
+
+// Waiters returns the number of the pending timers and sleeps on the clock.
+// Tests can use it to wait for the goroutines to block on the clock before advancing it.
+func (fc *FakeClock) Waiters() int {
+	fc.mutex.Lock()
+	defer fc.mutex.Unlock()
+
+	return len(fc.timers)
+}
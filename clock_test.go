@@ -0,0 +1,106 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Clock", func() {
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	gg.It("must fire the timers of a fake clock when advanced", func() {
+		clock := NewFakeClock(start)
+		gm.Expect(clock.Now()).To(gm.Equal(start))
+
+		t1 := clock.After(time.Second)
+		t2 := clock.After(time.Minute)
+		gm.Expect(clock.Waiters()).To(gm.Equal(2))
+		gm.Expect(clock.After(0)).To(gm.Receive(gm.Equal(start)))
+
+		clock.Advance(time.Second)
+		gm.Expect(t1).To(gm.Receive(gm.Equal(start.Add(time.Second))))
+		gm.Expect(t2).ToNot(gm.Receive())
+		gm.Expect(clock.Waiters()).To(gm.Equal(1))
+
+		clock.Set(start.Add(time.Hour))
+		gm.Expect(t2).To(gm.Receive(gm.Equal(start.Add(time.Hour))))
+		gm.Expect(clock.Waiters()).To(gm.BeZero())
+
+		done := make(chan struct{})
+		go func() {
+			clock.Sleep(time.Second)
+			close(done)
+		}()
+		gm.Eventually(clock.Waiters).Should(gm.Equal(1))
+		gm.Expect(done).ToNot(gm.BeClosed())
+		clock.Advance(time.Second)
+		gm.Eventually(done).Should(gm.BeClosed())
+	})
+
+	gg.It("must age the idle connections on the clock", func() {
+		clock := NewFakeClock(start)
+		conn := &Connection{clock: clock, bufferAdjustDeadline: time.Now().Add(time.Hour)}
+		conn.setIdleTimeout(time.Minute)
+		conn.refresh()
+
+		clock.Advance(59 * time.Second)
+		gm.Expect(conn.isIdle()).To(gm.BeFalse())
+		clock.Advance(time.Second + time.Nanosecond)
+		gm.Expect(conn.isIdle()).To(gm.BeTrue())
+	})
+
+	gg.It("must time out the commands on the clock", func() {
+		clock := NewFakeClock(start)
+		cluster := &Cluster{}
+		cluster.clientPolicy.Clock = clock
+
+		// the node is never active, so the command is retried until it times out
+		node := &Node{name: "BB9000000000001", cluster: cluster}
+
+		key, err := NewKey("test", "set", 1)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		policy := NewPolicy()
+		policy.TotalTimeout = 10 * time.Second
+		policy.MaxRetries = 100
+		policy.SleepBetweenRetries = time.Second
+		rc, err := newReadCommand(cluster, policy, key, nil, &Partition{})
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		cmd := &nodeReadCommand{readCommand: rc, node: node}
+
+		res := make(chan Error, 1)
+		go func() {
+			res <- cmd.execute(cmd)
+		}()
+
+		// the command sleeps between the retries until the total timeout
+		for i := 0; i < 10; i++ {
+			gm.Eventually(clock.Waiters).Should(gm.Equal(1))
+			gm.Expect(res).ToNot(gm.Receive())
+			clock.Advance(time.Second)
+		}
+
+		var cmdErr Error
+		gm.Eventually(res).Should(gm.Receive(&cmdErr))
+		gm.Expect(cmdErr.Matches(types.TIMEOUT)).To(gm.BeTrue())
+		gm.Expect(clock.Waiters()).To(gm.BeZero())
+	})
+})
@@ -15,6 +15,7 @@
 package aerospike
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"runtime/debug"
@@ -29,6 +30,7 @@ import (
 	"github.com/aerospike/aerospike-client-go/v7/internal/seq"
 	"github.com/aerospike/aerospike-client-go/v7/logger"
 	"github.com/aerospike/aerospike-client-go/v7/types"
+	hist "github.com/aerospike/aerospike-client-go/v7/types/histogram"
 )
 
 // Cluster encapsulates the aerospike cluster nodes and manages
@@ -63,6 +65,32 @@ type Cluster struct {
 
 	maxRetriesExceededCount   iatomic.Int // number of times the commands on this cluster were exceeded the specifiedmax retries
 	totalTimeoutExceededCount iatomic.Int // number of times the commands on this cluster were exceeded the specified total timeout
+	hedgedReadsWon            iatomic.Int // number of hedged reads whose response was used
+	hedgedReadsWasted         iatomic.Int // number of hedged reads sent in vain, because the original read responded first
+	collapsedReads            iatomic.Int // number of reads served by an identical read in flight
+
+	// samples the keys of the commands if ClientPolicy.HotKeyPolicy is set
+	hotKeys *hotKeyTracker
+
+	// captures the raw buffers of the sampled commands if ClientPolicy.WireCapturePolicy is set
+	wireCapture *wireCapturer
+
+	// spreads the reconnections to the cluster if ClientPolicy.ReconnectPolicy is set
+	reconnect *reconnector
+
+	// throttles the logins to the cluster if ClientPolicy.LoginPolicy is set
+	loginThrottle *loginThrottle
+
+	// lazy seeding and idle shutdown of the tend goroutine,
+	// see ClientPolicy.LazyConnect and ClientPolicy.LightweightMode
+	activateLock sync.Mutex
+	seeded       iatomic.Bool // the cluster was seeded or restored from a snapshot
+	tending      iatomic.Bool // the tend goroutine is running
+	lastCommand  iatomic.Int  // time of the last command on the clock, in unix nanoseconds
+
+	// graceful shutdown, see CloseGracefully
+	draining iatomic.Bool // new commands are rejected
+	commands iatomic.Int  // number of commands in flight
 
 	nodeIndex    iatomic.Int // only used via atomic operations
 	replicaIndex iatomic.Int // only used via atomic operations
@@ -72,13 +100,34 @@ type Cluster struct {
 	closed      iatomic.Bool
 	tendCount   int
 
+	// tendNow schedules an immediate tend when signaled.
+	tendNow chan struct{}
+
+	// forcedTend is true while a tend scheduled via tendNow is running.
+	// Only accessed within cluster tend goroutine.
+	forcedTend bool
+
+	// tendRequests receives the tends requested with TendNow.
+	// The result of the tend is sent on the request channel.
+	tendRequests chan chan Error
+
+	// tendMetrics is the histogram of the tend durations, in microseconds.
+	tendMetrics *hist.SyncHistogram[uint64]
+
+	// quiescedNodes are the names of the nodes quiesced with QuiesceNode.
+	quiescedNodes     map[string]struct{}
+	quiescedNodesLock sync.Mutex
+
 	supportsPartitionQuery iatomic.Bool // whether all nodes in the cluster support query by partition.
 
-	// User name in UTF-8 encoded bytes.
-	user string
+	// credentials of the last login; nil if the cluster does not require authentication.
+	credentials iatomic.TypedVal[*authCredentials]
+
+	// credentialsLock serializes the calls to ClientPolicy.CredentialsProvider.
+	credentialsLock sync.Mutex
 
-	// Password in hashed format in bytes.
-	password iatomic.SyncVal[[]byte]
+	// clientID identifies the connections of this cluster object on the server.
+	clientID string
 }
 
 // NewCluster generates a Cluster instance.
@@ -114,10 +163,28 @@ func NewCluster(policy *ClientPolicy, hosts []*Host) (*Cluster, Error) {
 		clientPolicy.IdleTimeout = 55 * time.Second
 	}
 
+	// the commands fail instead if the cluster could not be reached
+	if clientPolicy.LazyConnect {
+		clientPolicy.FailIfNotConnected = false
+	}
+
+	// keep at most one connection per node
+	if clientPolicy.LightweightMode {
+		clientPolicy.ConnectionQueueSize = 1
+		clientPolicy.MinConnectionsPerNode = 0
+		clientPolicy.ConnectionPoolShards = 1
+		clientPolicy.LimitConnectionsToQueueSize = true
+	}
+
 	newCluster := &Cluster{
 		clientPolicy: clientPolicy,
 		infoPolicy:   InfoPolicy{Timeout: policy.Timeout},
 		tendChannel:  make(chan struct{}),
+		tendNow:      make(chan struct{}, 1),
+		tendRequests: make(chan chan Error),
+		tendMetrics:  hist.NewSync[uint64](hist.Logarithmic, 2, 24),
+
+		quiescedNodes: map[string]struct{}{},
 
 		seeds:    *iatomic.NewSyncVal(hosts),
 		aliases:  *sm.New[Host, *Node](16),
@@ -125,29 +192,70 @@ func NewCluster(policy *ClientPolicy, hosts []*Host) (*Cluster, Error) {
 		nodes:    *iatomic.NewSyncVal([]*Node{}),
 		stats:    map[string]*nodeStats{},
 
-		password: *iatomic.NewSyncVal[[]byte](nil),
+		clientID: newClientID(),
 
 		supportsPartitionQuery: *iatomic.NewBool(false),
 	}
 
 	newCluster.partitionWriteMap.Set(make(partitionMap))
 
+	if policy.HotKeyPolicy != nil {
+		newCluster.hotKeys = newHotKeyTracker(*policy.HotKeyPolicy)
+	}
+
+	if policy.WireCapturePolicy != nil {
+		newCluster.wireCapture = newWireCapturer(*policy.WireCapturePolicy)
+	}
+
+	if policy.ReconnectPolicy != nil {
+		newCluster.reconnect = newReconnector(*policy.ReconnectPolicy)
+	}
+
+	if policy.LoginPolicy != nil {
+		newCluster.loginThrottle = newLoginThrottle(*policy.LoginPolicy)
+	}
+
 	// setup auth info for cluster
 	if policy.RequiresAuthentication() {
 		if policy.AuthMode == AuthModeExternal && policy.TlsConfig == nil {
 			return nil, newError(types.PARAMETER_ERROR, "External Authentication requires TLS configuration to be set, because it sends clear password on the wire.")
 		}
 
-		newCluster.user = policy.User
-		hashedPass, err := hashPassword(policy.Password)
-		if err != nil {
-			return nil, err
+		if policy.CredentialsProvider != nil {
+			// fail fast if the provider cannot provide the credentials
+			if _, err := newCluster.loginCredentials(); err != nil {
+				return nil, err
+			}
+		} else {
+			creds, err := newAuthCredentials(policy.User, policy.Password)
+			if err != nil {
+				return nil, err
+			}
+			newCluster.credentials.Set(creds)
 		}
-		newCluster.password = *iatomic.NewSyncVal(hashedPass)
 	}
 
+	// initialize the cluster from the snapshot if possible, otherwise
 	// try to seed connections for first use
-	err := newCluster.waitTillStabilized()
+	var err Error
+	if policy.Snapshot != nil {
+		if err = newCluster.restoreSnapshot(policy.Snapshot); err != nil {
+			logger.Logger.Warn("Cluster snapshot was not restored, the cluster will be seeded: %s", err.Error())
+		} else {
+			newCluster.seeded.Set(true)
+		}
+	}
+
+	// the cluster is seeded and tended on the first command
+	if policy.LazyConnect {
+		logger.Logger.Debug("New cluster initialized, and will connect on the first command...")
+		return newCluster, nil
+	}
+
+	if !newCluster.seeded.Get() {
+		err = newCluster.waitTillStabilized()
+		newCluster.seeded.Set(true)
+	}
 
 	// apply policy rules
 	if policy.FailIfNotConnected && !newCluster.IsConnected() {
@@ -158,9 +266,16 @@ func NewCluster(policy *ClientPolicy, hosts []*Host) (*Cluster, Error) {
 	}
 
 	// start up cluster maintenance go routine
+	newCluster.touch()
+	newCluster.tending.Set(true)
 	newCluster.wgTend.Add(1)
 	go newCluster.clusterBoss(&newCluster.clientPolicy)
 
+	if policy.ConnectionHealthCheckInterval > 0 && !policy.LightweightMode {
+		newCluster.wgTend.Add(1)
+		go newCluster.connectionHealthCheck(policy.ConnectionHealthCheckInterval)
+	}
+
 	if err == nil {
 		logger.Logger.Debug("New cluster initialized and ready to be used...")
 	} else {
@@ -170,6 +285,11 @@ func NewCluster(policy *ClientPolicy, hosts []*Host) (*Cluster, Error) {
 	return newCluster, err
 }
 
+// clock returns the source of time of the cluster.
+func (clstr *Cluster) clock() Clock {
+	return clockOrSystem(clstr.clientPolicy.Clock)
+}
+
 // String implements the stringer interface
 func (clstr *Cluster) String() string {
 	return fmt.Sprintf("%v", clstr.GetNodes())
@@ -201,9 +321,26 @@ Loop:
 			// tend channel closed
 			logger.Logger.Debug("Tend channel closed. Shutting down the cluster...")
 			break Loop
-		case <-time.After(tendInterval):
+		case <-clstr.tendNow:
+			clstr.forcedTend = true
+			if err := clstr.timedTend(); err != nil {
+				logger.Logger.Warn(err.Error())
+			}
+			clstr.forcedTend = false
+		case res := <-clstr.tendRequests:
+			res <- clstr.timedTend()
+		case <-clstr.clock().After(tendInterval):
+			// stop tending while the client is idle; the next command resumes it
+			if clstr.clientPolicy.LightweightMode && clstr.idle() {
+				clstr.activateLock.Lock()
+				clstr.tending.Set(false)
+				clstr.activateLock.Unlock()
+				logger.Logger.Debug("Cluster is idle. Stopping the tend goroutine...")
+				return
+			}
+
 			tm := time.Now()
-			if err := clstr.tend(); err != nil {
+			if err := clstr.timedTend(); err != nil {
 				logger.Logger.Warn(err.Error())
 			}
 
@@ -223,6 +360,74 @@ Loop:
 	}
 }
 
+// TendNow refreshes the cluster immediately, instead of waiting for the next tend interval,
+// e.g. after a known failover. It blocks until the tend is over, and returns its error.
+func (clstr *Cluster) TendNow() Error {
+	clstr.activate()
+
+	res := make(chan Error, 1)
+	select {
+	case clstr.tendRequests <- res:
+	case <-clstr.tendChannel:
+		return newError(types.COMMON_ERROR, "Cluster is closed")
+	}
+	return <-res
+}
+
+// QuiesceNode stops routing new commands to the node, e.g. while it is drained for a restart.
+// The commands are sent to the other replicas of the partitions instead; the node is only used when
+// no other replica of a partition is active. Commands already running on the node are not affected.
+// The node remains quiesced when it leaves and rejoins the cluster, until UnquiesceNode is called.
+// Nodes quiesced on the server are handed no partitions after the cluster rebalances, so the
+// client stops routing commands to them as soon as it receives the new partition map.
+func (clstr *Cluster) QuiesceNode(nodeName string) Error {
+	node, err := clstr.GetNodeByName(nodeName)
+	if err != nil {
+		return err
+	}
+
+	clstr.quiescedNodesLock.Lock()
+	clstr.quiescedNodes[nodeName] = struct{}{}
+	clstr.quiescedNodesLock.Unlock()
+
+	node.quiesced.Set(true)
+	logger.Logger.Info("Node %s is quiesced on the client", node)
+	return nil
+}
+
+// UnquiesceNode resumes routing new commands to a node quiesced with QuiesceNode.
+func (clstr *Cluster) UnquiesceNode(nodeName string) {
+	clstr.quiescedNodesLock.Lock()
+	delete(clstr.quiescedNodes, nodeName)
+	clstr.quiescedNodesLock.Unlock()
+
+	if node := clstr.findNodeByName(nodeName); node != nil {
+		node.quiesced.Set(false)
+		logger.Logger.Info("Node %s is not quiesced on the client anymore", node)
+	}
+}
+
+func (clstr *Cluster) isNodeQuiesced(nodeName string) bool {
+	clstr.quiescedNodesLock.Lock()
+	defer clstr.quiescedNodesLock.Unlock()
+	_, exists := clstr.quiescedNodes[nodeName]
+	return exists
+}
+
+// TendMetrics returns a copy of the histogram of the tend durations, in microseconds.
+// Compare them with ClientPolicy.TendInterval to find out if the tends fall behind.
+func (clstr *Cluster) TendMetrics() *hist.SyncHistogram[uint64] {
+	return clstr.tendMetrics.Clone()
+}
+
+// timedTend tends the cluster and records the duration of the tend.
+func (clstr *Cluster) timedTend() Error {
+	tm := time.Now()
+	err := clstr.tend()
+	clstr.tendMetrics.Add(uint64(time.Since(tm).Microseconds()))
+	return err
+}
+
 // AddSeeds adds new hosts to the cluster.
 // They will be added to the cluster on next tend call.
 func (clstr *Cluster) AddSeeds(hosts []*Host) {
@@ -241,6 +446,77 @@ func (clstr *Cluster) Healthy() Error {
 	return p.validate()
 }
 
+// PartitionHealth returns the health of the partition map of each namespace known
+// to the client, sorted by namespace. Use Healthy to get the validation errors.
+func (clstr *Cluster) PartitionHealth() []NamespacePartitionHealth {
+	p := clstr.getPartitions()
+	if p == nil {
+		return nil
+	}
+	return p.health()
+}
+
+// NodePartitions returns the ids of the partitions of the namespace owned by each node as master,
+// according to the current partition map of the client. Partitions without an active master are not listed.
+func (clstr *Cluster) NodePartitions(namespace string) (map[*Node][]int, Error) {
+	pmap := clstr.getPartitions()
+	partitions := pmap[namespace]
+	if partitions == nil {
+		return nil, newInvalidNamespaceError(namespace, len(pmap))
+	}
+	return partitions.masterPartitions(), nil
+}
+
+// GroupKeysByNode groups the keys by the node that owns their partition as master,
+// according to the current partition map of the client. The keys keep their relative order.
+// This is useful to split a batch into per-node pipelines. The partition map may change
+// after the keys are grouped, so commands must still be ready to handle migrations.
+func (clstr *Cluster) GroupKeysByNode(keys []*Key) (map[*Node][]*Key, Error) {
+	pmap := clstr.getPartitions()
+	res := make(map[*Node][]*Key)
+	for _, key := range keys {
+		partitions := pmap[key.namespace]
+		if partitions == nil {
+			return nil, newInvalidNamespaceError(key.namespace, len(pmap))
+		}
+
+		node, err := NewPartition(partitions, key, MASTER, nil, false).getMasterNode(clstr)
+		if err != nil {
+			return nil, err
+		}
+		res[node] = append(res[node], key)
+	}
+	return res, nil
+}
+
+// selfHealPartitions forces all the nodes to refresh their partition maps and schedules
+// an immediate tend, instead of waiting for the next tend interval.
+// Only one immediate tend is scheduled per regular tend, so that the client does not tend
+// in a tight loop when the partitions are actually unavailable in the cluster.
+func (clstr *Cluster) selfHealPartitions(partitions partitionMap) {
+	if !clstr.clientPolicy.PartitionMapSelfHealing || clstr.forcedTend {
+		return
+	}
+
+	var namespaces []string
+	for _, h := range partitions.health() {
+		if !h.Healthy() {
+			namespaces = append(namespaces, h.Namespace)
+		}
+	}
+	logger.Logger.Info("Partition map is missing nodes for namespaces %v. Forcing an immediate partition map refresh...", namespaces)
+
+	// the server does not support fetching the partitions of a single namespace
+	for _, node := range clstr.GetNodes() {
+		node.partitionGeneration.Set(-1)
+	}
+
+	select {
+	case clstr.tendNow <- struct{}{}:
+	default:
+	}
+}
+
 // Updates cluster state
 func (clstr *Cluster) tend() Error {
 
@@ -250,18 +526,28 @@ func (clstr *Cluster) tend() Error {
 	// All node additions/deletions are performed in tend goroutine.
 	// If active nodes don't exist, seed cluster.
 	if len(nodes) == 0 || (clstr.clientPolicy.SeedOnlyCluster && len(nodes) < clstr.GetSeedCount()) {
+		// spread the reconnections of the clients after the whole cluster restarted
+		if len(nodes) == 0 && !clstr.reconnect.allowSeed(clstr.clock().Now()) {
+			logger.Logger.Debug("No nodes available; seeding is delayed by the reconnect backoff...")
+			return nil
+		}
+
 		logger.Logger.Info("No nodes available; seeding...")
-		if newNodesFound, err := clstr.seedNodes(); !newNodesFound {
+		newNodesFound, err := clstr.seedNodes()
+		clstr.reconnect.seeded(clstr.clock().Now(), newNodesFound)
+		if !newNodesFound {
 			return err
 		}
 
 		// refresh nodes list after seeding
 		nodes = clstr.GetNodes()
+	} else {
+		clstr.reconnect.connectedTo()
 	}
 
 	peers := newPeers(len(nodes)+16, 16)
 
-	seq.ParDo(nodes, func(node *Node) {
+	seq.ParDoLimit(nodes, clstr.clientPolicy.TendConcurrency, func(node *Node) {
 		if err := node.Refresh(peers); err != nil {
 			logger.Logger.Debug("Error occurred while refreshing node: %s", node.String())
 		}
@@ -272,12 +558,14 @@ func (clstr *Cluster) tend() Error {
 		// Refresh peers for all nodes that responded the first time even if only one node's peers changed.
 		peers.refreshCount.Set(0)
 
-		seq.ParDo(nodes, func(node *Node) {
+		seq.ParDoLimit(nodes, clstr.clientPolicy.TendConcurrency, func(node *Node) {
 			node.refreshPeers(peers)
 		})
 	}
 
-	var partMap iatomic.Guard[partitionMap]
+	// The nodes refresh the partition map concurrently under shared access;
+	// the partition parser serializes the modifications of the map itself.
+	var partMap iatomic.RWGuard[partitionMap]
 
 	// find the first host that connects
 	seq.ParDo(peers.peers(), func(_peer *peer) {
@@ -307,7 +595,7 @@ func (clstr *Cluster) tend() Error {
 			// Create new node.
 			node := clstr.createNode(&nv)
 			peers.addNode(nv.name, node)
-			partMap.InitDoVal(clstr.getPartitions().clone, func(partMap partitionMap) {
+			partMap.InitRDoVal(clstr.getPartitions().clone, func(partMap partitionMap) {
 				node.refreshPartitions(peers, partMap, true)
 			})
 			return seq.Break
@@ -315,9 +603,9 @@ func (clstr *Cluster) tend() Error {
 	})
 
 	// Refresh partition map when necessary.
-	seq.ParDo(nodes, func(node *Node) {
-		if node.partitionChanged.Get() {
-			partMap.InitDoVal(clstr.getPartitions().clone, func(partMap partitionMap) {
+	seq.ParDoLimit(nodes, clstr.clientPolicy.TendConcurrency, func(node *Node) {
+		if node.partitionChanged.Get() && !clstr.clientPolicy.FaultInjector.skipPartitionRefresh(node) {
+			partMap.InitRDoVal(clstr.getPartitions().clone, func(partMap partitionMap) {
 				node.refreshPartitions(peers, partMap, false)
 			})
 		}
@@ -347,11 +635,20 @@ func (clstr *Cluster) tend() Error {
 	})
 
 	if updatePartitionMap {
-		clstr.setPartitions(*partMap.Release())
+		newPartMap := *partMap.Release()
+		if logger.Logger.IsLevelEnabled(logger.DEBUG) {
+			if changes := diffPartitionMaps(clstr.getPartitions(), newPartMap); len(changes) > 0 {
+				logger.Logger.Debug("Partition map ownership changes after tend %d:%s", clstr.tendCount, formatPartitionDiff(changes))
+			}
+		}
+		clstr.setPartitions(newPartMap)
 	}
 
-	if err := clstr.getPartitions().validate(); err != nil {
-		logger.Logger.Error("Error validating the cluster partition map after tend: %s", err.Error())
+	if partitions := clstr.getPartitions(); partitions != nil {
+		if err := partitions.validate(); err != nil {
+			logger.Logger.Error("Error validating the cluster partition map after tend: %s", err.Error())
+			clstr.selfHealPartitions(partitions)
+		}
 	}
 
 	// only log if node count is changed
@@ -361,6 +658,11 @@ func (clstr *Cluster) tend() Error {
 
 	clstr.aggregateNodeStats(clstr.GetNodes())
 
+	// publish the recent latencies of the nodes for the adaptive timeouts
+	for _, node := range clstr.GetNodes() {
+		node.latencies.publish()
+	}
+
 	// Reset connection error window for all nodes every connErrorWindow tend iterations.
 	if clstr.clientPolicy.MaxErrorRate > 0 && clstr.tendCount%clstr.clientPolicy.ErrorRateWindow == 0 {
 		for _, node := range clstr.GetNodes() {
@@ -499,6 +801,63 @@ func (clstr *Cluster) getPartitions() partitionMap {
 	return clstr.partitionWriteMap.Get()
 }
 
+// commandPartitions returns the partition map for a command,
+// after connecting to the cluster if necessary.
+func (clstr *Cluster) commandPartitions() partitionMap {
+	clstr.activate()
+	return clstr.partitionWriteMap.Get()
+}
+
+// touch records the time of the last command.
+func (clstr *Cluster) touch() {
+	clstr.lastCommand.Set(int(clstr.clock().Now().UnixNano()))
+}
+
+// idle returns true if there was no command on the cluster for ClientPolicy.IdleTimeout.
+func (clstr *Cluster) idle() bool {
+	last := time.Unix(0, int64(clstr.lastCommand.Get()))
+	return clstr.clock().Now().Sub(last) > clstr.clientPolicy.IdleTimeout
+}
+
+// activate seeds the cluster and starts the tend goroutine on the first command if
+// ClientPolicy.LazyConnect is set, and resumes tending the cluster after it was stopped while idle.
+func (clstr *Cluster) activate() {
+	if !clstr.clientPolicy.LazyConnect && !clstr.clientPolicy.LightweightMode {
+		return
+	}
+
+	clstr.touch()
+	if clstr.tending.Get() {
+		return
+	}
+
+	clstr.activateLock.Lock()
+	defer clstr.activateLock.Unlock()
+
+	if clstr.tending.Get() || clstr.closed.Get() {
+		return
+	}
+
+	if !clstr.seeded.Get() {
+		if err := clstr.waitTillStabilized(); err != nil {
+			logger.Logger.Error("Cluster was not initialized successfully, but the client will keep trying to connect to the database. Error: %s", err.Error())
+		}
+		clstr.seeded.Set(true)
+	} else if err := clstr.tend(); err != nil {
+		// the cluster may have changed while it was not tended
+		logger.Logger.Warn(err.Error())
+	}
+
+	clstr.tending.Set(true)
+	clstr.wgTend.Add(1)
+	go clstr.clusterBoss(&clstr.clientPolicy)
+
+	if clstr.clientPolicy.ConnectionHealthCheckInterval > 0 && !clstr.clientPolicy.LightweightMode {
+		clstr.wgTend.Add(1)
+		go clstr.connectionHealthCheck(clstr.clientPolicy.ConnectionHealthCheckInterval)
+	}
+}
+
 // discoverSeeds will lookup the seed hosts and convert seed hosts
 // to IP addresses.
 func discoverSeedIPs(seeds []*Host) (res []*Host) {
@@ -766,22 +1125,33 @@ func (clstr *Cluster) IsConnected() bool {
 
 // GetRandomNode returns a random node on the cluster
 func (clstr *Cluster) GetRandomNode() (*Node, Error) {
+	clstr.activate()
+
 	// Must copy array reference for copy on write semantics to work.
 	nodeArray := clstr.GetNodes()
 	length := len(nodeArray)
 
 	// prevent division by zero
 	if length > 0 {
+		var quiesced *Node
 		for i := 0; i < length; i++ {
 			// Must handle concurrency with other non-tending goroutines, so nodeIndex is consistent.
 			index := clstr.nodeIndex.IncrementAndGet() % length
 			node := nodeArray[index]
 
 			if node != nil && node.IsActive() {
+				if node.IsQuiesced() {
+					quiesced = node
+					continue
+				}
 				//logger.Logger.Debug("Node `%s` is active. index=%d", node, index)
 				return node, nil
 			}
 		}
+
+		if quiesced != nil {
+			return quiesced, nil
+		}
 	}
 
 	return nil, ErrClusterIsEmpty.err()
@@ -842,6 +1212,39 @@ func (clstr *Cluster) findNodeByName(nodeName string) *Node {
 	return nil
 }
 
+// startCommand registers a new command in flight. It returns false if the cluster is closing.
+func (clstr *Cluster) startCommand() bool {
+	clstr.commands.IncrementAndGet()
+	if clstr.draining.Get() {
+		clstr.commands.DecrementAndGet()
+		return false
+	}
+	return true
+}
+
+// CloseGracefully stops accepting new commands, waits for the commands in flight to complete
+// until the context is done, and then closes the cluster. It returns the number of commands
+// which were aborted since they did not complete in time.
+func (clstr *Cluster) CloseGracefully(ctx context.Context) (int, Error) {
+	clstr.draining.Set(true)
+
+	ticker := time.NewTicker(5 * time.Millisecond)
+	defer ticker.Stop()
+
+	for clstr.commands.Get() > 0 {
+		select {
+		case <-ctx.Done():
+			aborted := clstr.commands.Get()
+			clstr.Close()
+			return aborted, newErrorAndWrap(ctx.Err(), types.TIMEOUT, fmt.Sprintf("%d commands in flight were aborted when the client was closed", aborted))
+		case <-ticker.C:
+		}
+	}
+
+	clstr.Close()
+	return 0, nil
+}
+
 // Close closes all cached connections to the cluster nodes
 // and stops the tend goroutine.
 func (clstr *Cluster) Close() {
@@ -852,6 +1255,15 @@ func (clstr *Cluster) Close() {
 		// wait until tend is over
 		clstr.wgTend.Wait()
 
+		// close the nodes if the tend goroutine was not running
+		clstr.activateLock.Lock()
+		if !clstr.tending.Get() {
+			for _, node := range clstr.GetNodes() {
+				node.Close()
+			}
+		}
+		clstr.activateLock.Unlock()
+
 		// remove node references from the partition table
 		// to allow GC to work its magic. Leaks otherwise.
 		clstr.getPartitions().cleanup()
@@ -924,18 +1336,19 @@ func (clstr *Cluster) WaitUntillMigrationIsFinished(timeout time.Duration) Error
 
 // Password returns the password that is currently used with the cluster.
 func (clstr *Cluster) Password() (res []byte) {
-	pass := clstr.password.Get()
-	if pass != nil {
-		return pass
+	if creds := clstr.currentCredentials(); creds != nil {
+		return creds.hash
 	}
 	return nil
 }
 
+// changePassword updates the password used to login after the password of the user is changed.
+// If ClientPolicy.CredentialsProvider is set, the provider must return the new password as well.
 func (clstr *Cluster) changePassword(user string, password string, hash []byte) {
 	// change password ONLY if the user is the same
-	if clstr.user == user {
+	if clstr.userName() == user {
 		clstr.clientPolicy.Password = password
-		clstr.password.Set(hash)
+		clstr.credentials.Set(&authCredentials{user: user, password: password, hash: hash})
 	}
 }
 
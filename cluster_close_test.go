@@ -0,0 +1,90 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Graceful close", func() {
+
+	newCluster := func() *Cluster {
+		policy := NewClientPolicy()
+		policy.TendInterval = time.Hour
+		policy.Snapshot = &ClusterSnapshot{Nodes: []NodeSnapshot{{Name: "BB9000000000001", Host: Host{Name: "127.0.0.1", Port: 1}}}}
+
+		cluster, err := NewCluster(policy, []*Host{NewHost("127.0.0.1", 1)})
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		return cluster
+	}
+
+	gg.It("must wait for the commands in flight", func() {
+		cluster := newCluster()
+		gm.Expect(cluster.startCommand()).To(gm.BeTrue())
+
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			cluster.commands.DecrementAndGet()
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		aborted, err := cluster.CloseGracefully(ctx)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(aborted).To(gm.BeZero())
+		gm.Expect(cluster.closed.Get()).To(gm.BeTrue())
+		gm.Expect(cluster.startCommand()).To(gm.BeFalse())
+	})
+
+	gg.It("must abort the commands in flight on the deadline", func() {
+		cluster := newCluster()
+		gm.Expect(cluster.startCommand()).To(gm.BeTrue())
+		gm.Expect(cluster.startCommand()).To(gm.BeTrue())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		aborted, err := cluster.CloseGracefully(ctx)
+		gm.Expect(aborted).To(gm.Equal(2))
+		gm.Expect(err.Matches(types.TIMEOUT)).To(gm.BeTrue())
+		gm.Expect(errors.Is(err, context.DeadlineExceeded)).To(gm.BeTrue())
+		gm.Expect(cluster.closed.Get()).To(gm.BeTrue())
+	})
+
+	gg.It("must reject the new commands while closing", func() {
+		cluster := newCluster()
+		defer cluster.Close()
+		cluster.draining.Set(true)
+
+		node := cluster.GetNodes()[0]
+		key, err := NewKey("test", "set", 1)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		rc, err := newReadCommand(cluster, NewPolicy(), key, nil, &Partition{})
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		cmd := &nodeReadCommand{readCommand: rc, node: node}
+
+		err = cmd.execute(cmd)
+		gm.Expect(errors.Is(err, ErrClientClosing)).To(gm.BeTrue())
+		gm.Expect(cluster.commands.Get()).To(gm.BeZero())
+	})
+})
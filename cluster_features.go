@@ -0,0 +1,94 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"strconv"
+
+	ParticleType "github.com/aerospike/aerospike-client-go/v7/types/particle_type"
+)
+
+// SupportsFeature returns true if every node in the cluster reported the feature
+// in the response of the `features` info command, e.g. `pquery` or `batch-any`.
+// It returns false if the cluster has no nodes.
+func (clstr *Cluster) SupportsFeature(feature string) bool {
+	return clstr.allNodes(func(nd *Node) bool { return nd.HasFeature(feature) })
+}
+
+// SupportsQueryShow returns true if every node in the cluster supports the
+// query-show info command.
+func (clstr *Cluster) SupportsQueryShow() bool {
+	return clstr.allNodes((*Node).SupportsQueryShow)
+}
+
+// SupportsPartitionQuery returns true if every node in the cluster supports
+// queries by partition.
+func (clstr *Cluster) SupportsPartitionQuery() bool {
+	return clstr.allNodes((*Node).SupportsPartitionQuery)
+}
+
+// SupportsBlobIndex returns true if every node in the cluster supports
+// secondary indexes on BLOB bins. Requires server version 7.0+.
+func (clstr *Cluster) SupportsBlobIndex() bool {
+	return clstr.allNodes((*Node).SupportsBlobIndex)
+}
+
+// allNodes returns true if the cluster has nodes and f returns true for all of them.
+func (clstr *Cluster) allNodes(f func(*Node) bool) bool {
+	nodes := clstr.GetNodes()
+	if len(nodes) == 0 {
+		return false
+	}
+
+	for _, nd := range nodes {
+		if !f(nd) {
+			return false
+		}
+	}
+	return true
+}
+
+// requireServerVersion returns an error wrapping a ServerFeatureError if any node in
+// the cluster is running a server version older than major.minor.
+// Nodes with an unknown server version are not checked.
+func (clstr *Cluster) requireServerVersion(feature string, major, minor int) Error {
+	var old map[string]string
+	for _, nd := range clstr.GetNodes() {
+		build := nd.Build()
+		nmajor, nminor, ok := parseServerVersion(build)
+		if !ok || nmajor > major || (nmajor == major && nminor >= minor) {
+			continue
+		}
+
+		if old == nil {
+			old = map[string]string{}
+		}
+		old[nd.name] = build
+	}
+
+	if len(old) == 0 {
+		return nil
+	}
+	return newServerFeatureError(feature, strconv.Itoa(major)+"."+strconv.Itoa(minor), old)
+}
+
+// requireFilterSupport returns an error if the filter queries a secondary index
+// that is not supported by all the nodes in the cluster.
+func (clstr *Cluster) requireFilterSupport(filter *Filter) Error {
+	if filter != nil && filter.valueParticleType == ParticleType.BLOB {
+		return clstr.requireServerVersion("blob index query", 7, 0)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Lazy connect and lightweight mode", func() {
+
+	hosts := []*Host{NewHost("127.0.0.1", 1)}
+
+	gg.It("must seed the cluster on the first command", func() {
+		policy := NewClientPolicy()
+		policy.LazyConnect = true
+		policy.Timeout = 100 * time.Millisecond
+
+		start := time.Now()
+		cluster, err := NewCluster(policy, hosts)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		defer cluster.Close()
+
+		gm.Expect(time.Since(start)).To(gm.BeNumerically("<", policy.Timeout))
+		gm.Expect(cluster.seeded.Get()).To(gm.BeFalse())
+		gm.Expect(cluster.tending.Get()).To(gm.BeFalse())
+
+		_, err = PartitionForWrite(cluster, NewPolicy(), &Key{namespace: "test"})
+		gm.Expect(err).To(gm.HaveOccurred())
+		gm.Expect(cluster.seeded.Get()).To(gm.BeTrue())
+		gm.Expect(cluster.tending.Get()).To(gm.BeTrue())
+	})
+
+	gg.It("must not start tending the cluster once closed", func() {
+		policy := NewClientPolicy()
+		policy.LazyConnect = true
+
+		cluster, err := NewCluster(policy, hosts)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		cluster.Close()
+
+		gm.Expect(cluster.TendNow()).To(gm.HaveOccurred())
+		gm.Expect(cluster.seeded.Get()).To(gm.BeFalse())
+		gm.Expect(cluster.tending.Get()).To(gm.BeFalse())
+	})
+
+	gg.It("must stop tending while idle in lightweight mode", func() {
+		clock := NewFakeClock(time.Now())
+
+		policy := NewClientPolicy()
+		policy.LightweightMode = true
+		policy.Clock = clock
+		policy.Timeout = 100 * time.Millisecond
+		policy.IdleTimeout = time.Minute
+		policy.TendInterval = time.Second
+		policy.Snapshot = &ClusterSnapshot{Nodes: []NodeSnapshot{{Name: "BB9000000000001", Host: *hosts[0]}}}
+
+		cluster, err := NewCluster(policy, hosts)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		defer cluster.Close()
+
+		gm.Expect(cluster.clientPolicy.ConnectionQueueSize).To(gm.Equal(1))
+		gm.Expect(cluster.tending.Get()).To(gm.BeTrue())
+
+		// the cluster is tended while it is not idle
+		gm.Eventually(clock.Waiters).Should(gm.Equal(1))
+		clock.Advance(30 * time.Second)
+		gm.Eventually(clock.Waiters).Should(gm.Equal(1))
+		gm.Expect(cluster.tending.Get()).To(gm.BeTrue())
+
+		clock.Advance(31 * time.Second)
+		gm.Eventually(cluster.tending.Get).Should(gm.BeFalse())
+
+		// the next command resumes tending
+		cluster.commandPartitions()
+		gm.Expect(cluster.tending.Get()).To(gm.BeTrue())
+		gm.Eventually(clock.Waiters).Should(gm.Equal(1))
+	})
+})
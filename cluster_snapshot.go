@@ -0,0 +1,247 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// ClusterSnapshot is the state of a cluster a new client can be initialized from without
+// logging in and tending the cluster, e.g. in short-lived command line tools or serverless functions.
+// Take a snapshot with Client.Snapshot, and set it via ClientPolicy.Snapshot.
+//
+// Snapshots contain the session tokens of the nodes, and must be stored securely.
+type ClusterSnapshot struct {
+	// Time the snapshot was taken.
+	Time time.Time `json:"time"`
+
+	// ClusterName is the name of the cluster, if set in the ClientPolicy.
+	ClusterName string `json:"clusterName,omitempty"`
+
+	// User the session tokens were issued to.
+	User string `json:"user,omitempty"`
+
+	// Nodes of the cluster.
+	Nodes []NodeSnapshot `json:"nodes"`
+
+	// Partitions are the partition maps of the namespaces.
+	Partitions []PartitionsSnapshot `json:"partitions"`
+}
+
+// NodeSnapshot is the state of a node in a ClusterSnapshot.
+type NodeSnapshot struct {
+	Name    string         `json:"name"`
+	Host    Host           `json:"host"`
+	Aliases []Host         `json:"aliases,omitempty"`
+	Racks   map[string]int `json:"racks,omitempty"`
+
+	// Build and FeatureList are the server version and features reported by the node.
+	// The features the client relies on are derived from FeatureList when the snapshot is restored.
+	Build       string   `json:"build,omitempty"`
+	FeatureList []string `json:"featureList,omitempty"`
+
+	// SessionToken of the node, if the cluster requires authentication.
+	SessionToken      []byte    `json:"sessionToken,omitempty"`
+	SessionExpiration time.Time `json:"sessionExpiration,omitempty"`
+}
+
+// PartitionsSnapshot is the partition map of a namespace in a ClusterSnapshot.
+type PartitionsSnapshot struct {
+	Namespace string `json:"namespace"`
+	SCMode    bool   `json:"scMode,omitempty"`
+	Regimes   []int  `json:"regimes"`
+
+	// Replicas are the indexes of the nodes of the replicas of the partitions in ClusterSnapshot.Nodes,
+	// or -1 if the replica of the partition has no node.
+	Replicas [][]int `json:"replicas"`
+}
+
+// ReadClusterSnapshot reads a snapshot written with ClusterSnapshot.WriteFile.
+func ReadClusterSnapshot(path string) (*ClusterSnapshot, Error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, newErrorAndWrap(err, types.PARAMETER_ERROR, fmt.Sprintf("error reading cluster snapshot file %s: %s", path, err))
+	}
+
+	res := new(ClusterSnapshot)
+	if err := json.Unmarshal(data, res); err != nil {
+		return nil, newErrorAndWrap(err, types.PARSE_ERROR, fmt.Sprintf("error parsing cluster snapshot file %s: %s", path, err))
+	}
+	return res, nil
+}
+
+// WriteFile writes the snapshot to the file as JSON. The file is only readable by its owner,
+// since it contains the session tokens.
+func (cs *ClusterSnapshot) WriteFile(path string) Error {
+	data, err := json.Marshal(cs)
+	if err != nil {
+		return newErrorAndWrap(err, types.SERIALIZE_ERROR, fmt.Sprintf("error serializing cluster snapshot: %s", err))
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return newErrorAndWrap(err, types.COMMON_ERROR, fmt.Sprintf("error writing cluster snapshot file %s: %s", path, err))
+	}
+	return nil
+}
+
+// snapshot takes a snapshot of the nodes and the partition maps of the cluster.
+func (clstr *Cluster) snapshot() (*ClusterSnapshot, Error) {
+	nodes := clstr.GetNodes()
+	if len(nodes) == 0 {
+		return nil, ErrClusterIsEmpty.err()
+	}
+
+	res := &ClusterSnapshot{
+		Time:        time.Now(),
+		ClusterName: clstr.clientPolicy.ClusterName,
+		User:        clstr.userName(),
+		Nodes:       make([]NodeSnapshot, 0, len(nodes)),
+	}
+
+	index := make(map[*Node]int, len(nodes))
+	for i, node := range nodes {
+		index[node] = i
+
+		ns := NodeSnapshot{
+			Name:        node.name,
+			Host:        *node.host,
+			Build:       node.Build(),
+			FeatureList: node.Features(),
+		}
+		if racks := node.racks.Get(); len(racks) > 0 {
+			ns.Racks = make(map[string]int, len(racks))
+			for k, v := range racks {
+				ns.Racks[k] = v
+			}
+		}
+		for _, alias := range node.GetAliases() {
+			ns.Aliases = append(ns.Aliases, *alias)
+		}
+		if si := node.sessionInfo.Get(); si.isValid() {
+			ns.SessionToken = si.token
+			ns.SessionExpiration = si.expiration
+		}
+		res.Nodes = append(res.Nodes, ns)
+	}
+
+	for namespace, partitions := range clstr.getPartitions() {
+		ps := PartitionsSnapshot{
+			Namespace: namespace,
+			SCMode:    partitions.SCMode,
+			Regimes:   append([]int(nil), partitions.regimes...),
+			Replicas:  make([][]int, len(partitions.Replicas)),
+		}
+		for i, replica := range partitions.Replicas {
+			ps.Replicas[i] = make([]int, len(replica))
+			for j, node := range replica {
+				if n, exists := index[node]; exists && node != nil {
+					ps.Replicas[i][j] = n
+				} else {
+					ps.Replicas[i][j] = -1
+				}
+			}
+		}
+		res.Partitions = append(res.Partitions, ps)
+	}
+
+	return res, nil
+}
+
+// restoreSnapshot adds the nodes and sets the partition maps of the snapshot.
+// The session tokens are only used if they were issued to the user of the cluster.
+func (clstr *Cluster) restoreSnapshot(cs *ClusterSnapshot) Error {
+	if len(cs.Nodes) == 0 {
+		return newError(types.PARAMETER_ERROR, "cluster snapshot has no nodes")
+	}
+
+	if cs.ClusterName != clstr.clientPolicy.ClusterName {
+		return newError(types.CLUSTER_NAME_MISMATCH_ERROR, fmt.Sprintf("cluster snapshot is for cluster `%s`, but the client expects `%s`", cs.ClusterName, clstr.clientPolicy.ClusterName))
+	}
+
+	pm := make(partitionMap, len(cs.Partitions))
+	nodes := make([]*Node, len(cs.Nodes))
+	for _, ps := range cs.Partitions {
+		if len(ps.Regimes) != _PARTITIONS {
+			return newError(types.PARAMETER_ERROR, fmt.Sprintf("cluster snapshot has %d regimes for namespace `%s`", len(ps.Regimes), ps.Namespace))
+		}
+		for _, replica := range ps.Replicas {
+			if len(replica) != _PARTITIONS {
+				return newError(types.PARAMETER_ERROR, fmt.Sprintf("cluster snapshot has %d partitions for namespace `%s`", len(replica), ps.Namespace))
+			}
+			for _, n := range replica {
+				if n < -1 || n >= len(nodes) {
+					return newError(types.PARAMETER_ERROR, fmt.Sprintf("cluster snapshot has an invalid node index %d for namespace `%s`", n, ps.Namespace))
+				}
+			}
+		}
+	}
+
+	nodesToAdd := make(map[string]*Node, len(cs.Nodes))
+	for i := range cs.Nodes {
+		ns := &cs.Nodes[i]
+
+		host := ns.Host
+		features := strings.Join(ns.FeatureList, ";")
+		nv := &nodeValidator{name: ns.Name, primaryHost: &host, build: ns.Build, featureList: parseFeatureList(features)}
+		nv.setFeatures(features)
+		for j := range ns.Aliases {
+			alias := ns.Aliases[j]
+			nv.aliases = append(nv.aliases, &alias)
+		}
+		if len(nv.aliases) == 0 {
+			nv.aliases = []*Host{&host}
+		}
+		if len(ns.SessionToken) > 0 && cs.User == clstr.userName() {
+			nv.sessionInfo = &sessionInfo{user: cs.User, token: ns.SessionToken, expiration: ns.SessionExpiration}
+		}
+
+		node := clstr.createNode(nv)
+
+		// the node is usable now, and its partition map is refreshed on the next tend
+		node.partitionGeneration.Set(-1)
+
+		racks := make(map[string]int, len(ns.Racks))
+		for k, v := range ns.Racks {
+			racks[k] = v
+		}
+		node.racks.Set(racks)
+
+		nodes[i] = node
+		nodesToAdd[node.name] = node
+	}
+
+	for _, ps := range cs.Partitions {
+		partitions := newPartitions(_PARTITIONS, len(ps.Replicas), ps.SCMode)
+		copy(partitions.regimes, ps.Regimes)
+		for i, replica := range ps.Replicas {
+			for j, n := range replica {
+				if n >= 0 {
+					partitions.Replicas[i][j] = nodes[n]
+				}
+			}
+		}
+		pm[ps.Namespace] = partitions
+	}
+
+	clstr.addNodes(nodesToAdd)
+	clstr.setPartitions(pm)
+	return nil
+}
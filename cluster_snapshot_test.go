@@ -0,0 +1,148 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Cluster snapshot", func() {
+
+	hosts := []*Host{NewHost("127.0.0.1", 1)}
+
+	newSnapshot := func() *ClusterSnapshot {
+		ps := PartitionsSnapshot{
+			Namespace: "test",
+			Regimes:   make([]int, _PARTITIONS),
+			Replicas:  [][]int{make([]int, _PARTITIONS), make([]int, _PARTITIONS)},
+		}
+		for i := 0; i < _PARTITIONS; i++ {
+			ps.Regimes[i] = 3
+			ps.Replicas[0][i] = i % 2
+			ps.Replicas[1][i] = (i + 1) % 2
+		}
+
+		return &ClusterSnapshot{
+			Time: time.Now(),
+			User: "admin",
+			Nodes: []NodeSnapshot{
+				{
+					Name:              "BB9000000000001",
+					Host:              Host{Name: "127.0.0.1", Port: 1},
+					Aliases:           []Host{{Name: "127.0.0.1", Port: 1}},
+					Build:             "7.0.0.1",
+					FeatureList:       []string{"pquery", "pscans"},
+					Racks:             map[string]int{"test": 1},
+					SessionToken:      []byte("token"),
+					SessionExpiration: time.Now().Add(time.Hour).Truncate(time.Second).In(time.UTC),
+				},
+				{
+					Name:    "BB9000000000002",
+					Host:    Host{Name: "127.0.0.1", Port: 2},
+					Aliases: []Host{{Name: "127.0.0.1", Port: 2}},
+				},
+			},
+			Partitions: []PartitionsSnapshot{ps},
+		}
+	}
+
+	newPolicy := func(snapshot *ClusterSnapshot) *ClientPolicy {
+		policy := NewClientPolicy()
+		policy.User = "admin"
+		policy.Password = "admin"
+		policy.TendInterval = time.Hour
+		policy.Timeout = 100 * time.Millisecond
+		policy.Snapshot = snapshot
+		return policy
+	}
+
+	gg.It("must initialize the cluster from a snapshot without a tend", func() {
+		snapshot := newSnapshot()
+		path := filepath.Join(gg.GinkgoT().TempDir(), "snapshot.json")
+		gm.Expect(snapshot.WriteFile(path)).ToNot(gm.HaveOccurred())
+
+		restored, err := ReadClusterSnapshot(path)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(restored.Nodes).To(gm.Equal(snapshot.Nodes))
+
+		cluster, err := NewCluster(newPolicy(restored), hosts)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		defer cluster.Close()
+
+		nodes := cluster.GetNodes()
+		gm.Expect(nodes).To(gm.HaveLen(2))
+		gm.Expect(cluster.IsConnected()).To(gm.BeTrue())
+
+		node, err := cluster.GetNodeByName("BB9000000000001")
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(node.IsActive()).To(gm.BeTrue())
+		gm.Expect(node.sessionInfo.Get().isValid()).To(gm.BeTrue())
+		gm.Expect(node.SupportsPartitionQuery()).To(gm.BeTrue())
+		gm.Expect(node.serverInfo.Get().features & _SUPPORTS_PARTITION_SCAN).ToNot(gm.BeZero())
+		gm.Expect(node.serverInfo.Get().features & _SUPPORTS_BATCH_ANY).To(gm.BeZero())
+		gm.Expect(node.Build()).To(gm.Equal("7.0.0.1"))
+		gm.Expect(node.HasFeature("pquery")).To(gm.BeTrue())
+
+		partitions := cluster.getPartitions()["test"]
+		gm.Expect(partitions.Replicas[0][0]).To(gm.Equal(node))
+		gm.Expect(partitions.Replicas[1][1]).To(gm.Equal(node))
+		gm.Expect(partitions.regimes[0]).To(gm.Equal(3))
+
+		res, err := cluster.snapshot()
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(res.User).To(gm.Equal("admin"))
+		gm.Expect(res.Nodes).To(gm.ConsistOf(snapshot.Nodes[0], snapshot.Nodes[1]))
+
+		// the node indexes may differ, but the nodes of the partitions must match
+		name := func(s *ClusterSnapshot, i int) string { return s.Nodes[i].Name }
+		for r := range snapshot.Partitions[0].Replicas {
+			for p := 0; p < _PARTITIONS; p++ {
+				gm.Expect(name(res, res.Partitions[0].Replicas[r][p])).To(gm.Equal(name(snapshot, snapshot.Partitions[0].Replicas[r][p])))
+			}
+		}
+	})
+
+	gg.It("must not use the session tokens of another user", func() {
+		snapshot := newSnapshot()
+		snapshot.User = "other"
+
+		cluster, err := NewCluster(newPolicy(snapshot), hosts)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		defer cluster.Close()
+
+		node, err := cluster.GetNodeByName("BB9000000000001")
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(node.sessionInfo.Get().isValid()).To(gm.BeFalse())
+	})
+
+	gg.It("must seed the cluster if the snapshot is invalid", func() {
+		snapshot := newSnapshot()
+		snapshot.ClusterName = "other"
+		gm.Expect(NewCluster(newPolicy(snapshot), hosts)).Error().To(gm.HaveOccurred())
+
+		snapshot = newSnapshot()
+		snapshot.Partitions[0].Replicas[0][0] = 2
+		cluster := &Cluster{}
+		err := cluster.restoreSnapshot(snapshot)
+		gm.Expect(err).To(gm.HaveOccurred())
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+	})
+})
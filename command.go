@@ -16,10 +16,11 @@ package aerospike
 
 import (
 	"bytes"
-	"compress/zlib"
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/aerospike/aerospike-client-go/v7/logger"
@@ -138,17 +139,51 @@ const (
 	ttBatchWrite
 )
 
+// String implements the Stringer interface.
+func (tt transactionType) String() string {
+	switch tt {
+	case ttGet:
+		return "get"
+	case ttGetHeader:
+		return "get-header"
+	case ttExists:
+		return "exists"
+	case ttPut:
+		return "put"
+	case ttDelete:
+		return "delete"
+	case ttOperate:
+		return "operate"
+	case ttQuery:
+		return "query"
+	case ttScan:
+		return "scan"
+	case ttUDF:
+		return "udf"
+	case ttBatchRead:
+		return "batch-read"
+	case ttBatchWrite:
+		return "batch-write"
+	}
+	return ""
+}
+
 var (
 	buffPool = pool.NewTieredBufferPool(MinBufferSize, PoolCutOffBufferSize)
 )
 
+// keyedCommand is implemented by the commands on a single record.
+type keyedCommand interface {
+	commandKey() *Key
+}
+
 // command interface describes all commands available
 type command interface {
 	getPolicy(ifc command) Policy
 
 	writeBuffer(ifc command) Error
 	getNode(ifc command) (*Node, Error)
-	getConnection(policy Policy) (*Connection, Error)
+	getConnection(policy Policy, deadline time.Time) (*Connection, Error)
 	putConnection(conn *Connection)
 	parseResult(ifc command, conn *Connection) Error
 	parseRecordResults(ifc command, receiveSize int) (bool, Error)
@@ -158,6 +193,9 @@ type command interface {
 
 	isRead() bool
 
+	// clock returns the source of time of the timeouts of the command
+	clock() Clock
+
 	execute(ifc command) Error
 	executeAt(ifc command, policy *BasePolicy, deadline time.Time, iterations int) Error
 
@@ -189,8 +227,21 @@ type baseCommand struct {
 	// before being sent to the server
 	compressed bool
 
+	// compression parameters, set from the policy
+	compressionAlgorithm CompressionAlgorithm
+	compressionLevel     int
+	minCompressSize      int
+
 	commandSentCounter int
 	commandWasSent     bool
+
+	// serverTimeout is the server timeout sent with the current attempt; 0 if none was sent.
+	serverTimeout time.Duration
+
+	// cancelCtx is cancelled when the result of the command is no longer needed,
+	// e.g. when the command is a hedged read that lost the race.
+	// The pending read is interrupted, and no new attempt is made afterwards.
+	cancelCtx context.Context
 }
 
 // Writes the command for write operations
@@ -212,20 +263,29 @@ func (cmd *baseCommand) setWrite(policy *WritePolicy, operation OperationType, k
 		}
 	}
 
+	guard := newRecordSizeGuard(policy)
 	if binMap == nil {
 		for i := range bins {
+			offset := cmd.dataOffset
 			if err := cmd.estimateOperationSizeForBin(bins[i]); err != nil {
 				return err
 			}
+			guard.add(bins[i].Name, cmd.dataOffset-offset)
 		}
 	} else {
 		for name, value := range binMap {
+			offset := cmd.dataOffset
 			if err := cmd.estimateOperationSizeForBinNameAndValue(name, value); err != nil {
 				return err
 			}
+			guard.add(name, cmd.dataOffset-offset)
 		}
 	}
 
+	if err := guard.check(); err != nil {
+		return err
+	}
+
 	if err := cmd.sizeBuffer(policy.compress()); err != nil {
 		return err
 	}
@@ -516,10 +576,19 @@ func (cmd *baseCommand) setOperate(policy *WritePolicy, key *Key, args *operateA
 	cmd.begin()
 	fieldCount := 0
 
+	guard := newRecordSizeGuard(policy)
 	for i := range args.operations {
+		offset := cmd.dataOffset
 		if err := cmd.estimateOperationSizeForOperation(args.operations[i], false); err != nil {
 			return err
 		}
+		if args.operations[i].opType.isWrite {
+			guard.add(args.operations[i].binName, cmd.dataOffset-offset)
+		}
+	}
+
+	if err := guard.check(); err != nil {
+		return err
 	}
 
 	ksz, err := cmd.estimateKeySize(key, policy.SendKey && args.hasWrite)
@@ -707,6 +776,11 @@ func (cmd *baseCommand) setBatchOperateIfc(client ClientIfc, policy *BatchPolicy
 				br := record.(*BatchRead)
 
 				attr.setBatchRead(client.getUsableBatchReadPolicy(br.Policy))
+				if br.Policy == nil && attr.expiration == 0 {
+					// records without a policy inherit the TTL reset of the batch policy,
+					// unless the default batch read policy overrides it.
+					attr.expiration = uint32(policy.ReadTouchTTLPercent)
+				}
 				if len(br.BinNames) > 0 {
 					cmd.writeBatchBinNames(key, br.BinNames, attr, attr.filterExp)
 				} else if br.Ops != nil {
@@ -721,6 +795,7 @@ func (cmd *baseCommand) setBatchOperateIfc(client ClientIfc, policy *BatchPolicy
 				bw := record.(*BatchWrite)
 
 				attr.setBatchWrite(client.getUsableBatchWritePolicy(bw.Policy))
+				attr.sendKey = policy.SendKey || (bw.Policy != nil && bw.Policy.SendKey)
 				attr.adjustWrite(bw.Ops)
 				cmd.writeBatchOperations(key, bw.Ops, attr, attr.filterExp)
 
@@ -728,6 +803,7 @@ func (cmd *baseCommand) setBatchOperateIfc(client ClientIfc, policy *BatchPolicy
 				bu := record.(*BatchUDF)
 
 				attr.setBatchUDF(client.getUsableBatchUDFPolicy(bu.Policy))
+				attr.sendKey = policy.SendKey || (bu.Policy != nil && bu.Policy.SendKey)
 				cmd.writeBatchWrite(key, attr, attr.filterExp, 3, 0)
 				cmd.writeFieldString(bu.PackageName, UDF_PACKAGE_NAME)
 				cmd.writeFieldString(bu.FunctionName, UDF_FUNCTION)
@@ -737,6 +813,7 @@ func (cmd *baseCommand) setBatchOperateIfc(client ClientIfc, policy *BatchPolicy
 				bd := record.(*BatchDelete)
 
 				attr.setBatchDelete(client.getUsableBatchDeletePolicy(bd.Policy))
+				attr.sendKey = policy.SendKey || (bd.Policy != nil && bd.Policy.SendKey)
 				cmd.writeBatchWrite(key, attr, attr.filterExp, 0, 0)
 			}
 			prev = record
@@ -2451,13 +2528,27 @@ func (cmd *baseCommand) end() {
 
 func (cmd *baseCommand) markCompressed(policy Policy) {
 	cmd.compressed = policy.compress()
+	if cmd.compressed {
+		bp := policy.GetBasePolicy()
+		cmd.compressionAlgorithm = bp.CompressionAlgorithm
+		cmd.compressionLevel = bp.CompressionLevel
+		cmd.minCompressSize = bp.MinCompressSize
+	}
 }
 
 func (cmd *baseCommand) compress() Error {
-	if cmd.compressed && cmd.dataOffset > _COMPRESS_THRESHOLD {
+	threshold := _COMPRESS_THRESHOLD
+	if cmd.minCompressSize > 0 {
+		threshold = cmd.minCompressSize
+	}
+
+	if cmd.compressed && cmd.dataOffset > threshold {
 		b := bytes.NewBuffer(cmd.dataBufferCompress[msgHeaderPad:])
 		b.Reset()
-		w := zlib.NewWriter(b)
+		w, err := compressorFor(cmd.compressionAlgorithm, cmd.node).NewWriter(b, cmd.compressionLevel)
+		if err != nil {
+			return newErrorAndWrap(err, types.SERIALIZE_ERROR)
+		}
 
 		// There seems to be a bug either in Go's zlib or in zlibc
 		// which messes up a single write block of bigger than 64KB to
@@ -2535,6 +2626,13 @@ func (cmd *baseCommand) isRead() bool {
 	return true
 }
 
+func (cmd *baseCommand) clock() Clock {
+	if cmd.node == nil {
+		return SystemClock
+	}
+	return cmd.node.clock()
+}
+
 // grpcPutBufferBack puts the assigned buffer back in the pool.
 // This function should only be called from grpc commands.
 func (cmd *baseCommand) grpcPutBufferBack() {
@@ -2551,7 +2649,7 @@ func (cmd *baseCommand) grpcPutBufferBack() {
 
 func (cmd *baseCommand) execute(ifc command) Error {
 	policy := ifc.getPolicy(ifc).GetBasePolicy()
-	deadline := policy.deadline()
+	deadline := policy.deadlineFrom(ifc.clock().Now())
 
 	return cmd.executeAt(ifc, policy, deadline, -1)
 }
@@ -2560,6 +2658,8 @@ func (cmd *baseCommand) executeAt(ifc command, policy *BasePolicy, deadline time
 	// for exponential backoff
 	interval := policy.SleepBetweenRetries
 
+	clock := ifc.clock()
+
 	transStart := time.Now()
 
 	notFirstIteration := false
@@ -2568,8 +2668,54 @@ func (cmd *baseCommand) executeAt(ifc command, policy *BasePolicy, deadline time
 
 	var err Error
 
+	// the failed attempts, attached to the returned error if the command was retried
+	var attempts []CommandAttempt
+	var iterationStart time.Time
+
+	// the node the current attempt is in flight on
+	var inFlight *Node
+
+	// if the key of the command has been sampled for the hot key report
+	sampled := false
+
+	// the interceptors of the command, set once the node is known
+	var interceptors *interceptorChain
+
+	// the cluster the command is registered in flight on
+	var registered *Cluster
+	defer func() {
+		if registered != nil {
+			registered.commands.DecrementAndGet()
+		}
+
+		if inFlight != nil {
+			inFlight.inFlight.DecrementAndGet()
+		}
+
+		if errChain != nil && errChain != errCommandCancelled {
+			if len(attempts) > 1 {
+				errChain.setAttempts(attempts)
+			}
+
+			var key *Key
+			if kc, ok := ifc.(keyedCommand); ok && !policy.OmitKeyInErrors {
+				key = kc.commandKey()
+			}
+			errChain.setCommand(ifc.transactionType(), key)
+		}
+
+		if interceptors != nil {
+			interceptors.after(cmd.node, cmd.commandSentCounter, cmd.serverTimeout, errChain)
+		}
+	}()
+
 	// Execute command until successful, timed out or maximum iterations have been reached.
 	for {
+		if inFlight != nil {
+			inFlight.inFlight.DecrementAndGet()
+			inFlight = nil
+		}
+
 		cmd.commandSentCounter++
 		loopCount++
 
@@ -2585,11 +2731,11 @@ func (cmd *baseCommand) executeAt(ifc command, policy *BasePolicy, deadline time
 		// Sleep before trying again, after the first iteration
 		if policy.SleepBetweenRetries > 0 && notFirstIteration {
 			// Do not sleep if you know you'll wake up after the deadline
-			if policy.TotalTimeout > 0 && time.Now().Add(interval).After(deadline) {
+			if policy.TotalTimeout > 0 && clock.Now().Add(interval).After(deadline) {
 				break
 			}
 
-			time.Sleep(interval)
+			clock.Sleep(interval)
 			if policy.SleepMultiplier > 1 {
 				interval = time.Duration(float64(interval) * policy.SleepMultiplier)
 			}
@@ -2626,10 +2772,18 @@ func (cmd *baseCommand) executeAt(ifc command, policy *BasePolicy, deadline time
 		notFirstIteration = true
 
 		// check for command timeout
-		if policy.TotalTimeout > 0 && time.Now().After(deadline) {
+		if policy.TotalTimeout > 0 && clock.Now().After(deadline) {
 			break
 		}
 
+		// the result is no longer needed
+		if cmd.cancelCtx != nil && cmd.cancelCtx.Err() != nil {
+			return errCommandCancelled
+		}
+
+		iterationStart = time.Now()
+		cmd.serverTimeout = 0
+
 		// set command node, so when you return a record it has the node
 		cmd.node, err = ifc.getNode(ifc)
 		if cmd.node == nil || !cmd.node.IsActive() || err != nil {
@@ -2637,6 +2791,7 @@ func (cmd *baseCommand) executeAt(ifc command, policy *BasePolicy, deadline time
 
 			// chain the errors
 			if err != nil {
+				attempts = cmd.appendAttempt(attempts, err, iterationStart)
 				errChain = chainErrors(err, errChain).iter(cmd.commandSentCounter).setInDoubt(ifc.isRead(), cmd.commandSentCounter)
 			}
 
@@ -2644,6 +2799,30 @@ func (cmd *baseCommand) executeAt(ifc command, policy *BasePolicy, deadline time
 			continue
 		}
 
+		// sample the key of the command once for the hot key report
+		if !sampled && cmd.node.cluster != nil && cmd.node.cluster.hotKeys != nil {
+			if kc, ok := ifc.(keyedCommand); ok {
+				cmd.node.cluster.hotKeys.record(kc.commandKey())
+			}
+			sampled = true
+		}
+
+		// register the command in flight once, and reject it if the client is closing
+		if registered == nil && cmd.node.cluster != nil {
+			if !cmd.node.cluster.startCommand() {
+				return ErrClientClosing.err()
+			}
+			registered = cmd.node.cluster
+		}
+
+		// run the interceptors of the client once, on the first node of the command
+		if interceptors == nil && cmd.node.cluster != nil && len(cmd.node.cluster.clientPolicy.Interceptors) > 0 {
+			interceptors = &interceptorChain{interceptors: cmd.node.cluster.clientPolicy.Interceptors}
+			if err = interceptors.before(ifc, policy, cmd.node); err != nil {
+				return err.setNode(cmd.node)
+			}
+		}
+
 		// check if node has encountered too many errors
 		if err = cmd.node.validateErrorCount(); err != nil {
 			isClientTimeout = false
@@ -2651,17 +2830,19 @@ func (cmd *baseCommand) executeAt(ifc command, policy *BasePolicy, deadline time
 			applyTransactionErrorMetrics(cmd.node)
 
 			// chain the errors
+			attempts = cmd.appendAttempt(attempts, err, iterationStart)
 			errChain = chainErrors(err, errChain).iter(cmd.commandSentCounter).setNode(cmd.node).setInDoubt(ifc.isRead(), cmd.commandSentCounter)
 
 			// Max error rate achieved, try again per policy
 			continue
 		}
 
-		cmd.conn, err = ifc.getConnection(policy)
+		cmd.conn, err = ifc.getConnection(policy, deadline)
 		if err != nil {
 			isClientTimeout = false
 
 			// chain the errors
+			attempts = cmd.appendAttempt(attempts, err, iterationStart)
 			errChain = chainErrors(err, errChain).iter(cmd.commandSentCounter).setNode(cmd.node).setInDoubt(ifc.isRead(), cmd.commandSentCounter)
 
 			applyTransactionErrorMetrics(cmd.node)
@@ -2683,6 +2864,10 @@ func (cmd *baseCommand) executeAt(ifc command, policy *BasePolicy, deadline time
 			continue
 		}
 
+		attemptStart := time.Now()
+		inFlight = cmd.node
+		inFlight.inFlight.IncrementAndGet()
+
 		// Assign the connection buffer to the command buffer
 		cmd.dataBuffer = cmd.conn.dataBuffer
 
@@ -2692,6 +2877,7 @@ func (cmd *baseCommand) executeAt(ifc command, policy *BasePolicy, deadline time
 			applyTransactionErrorMetrics(cmd.node)
 
 			// chain the errors
+			attempts = cmd.appendAttempt(attempts, err, iterationStart)
 			err = chainErrors(err, errChain).iter(cmd.commandSentCounter).setNode(cmd.node).setInDoubt(ifc.isRead(), cmd.commandSentCounter)
 
 			// All runtime exceptions are considered fatal. Do not retry.
@@ -2703,35 +2889,38 @@ func (cmd *baseCommand) executeAt(ifc command, policy *BasePolicy, deadline time
 		}
 
 		// Reset timeout in send buffer (destined for server) and socket.
-		binary.BigEndian.PutUint32(cmd.dataBuffer[22:], 0)
-		if !deadline.IsZero() {
-			serverTimeout := time.Until(deadline)
-			if serverTimeout < time.Millisecond {
-				serverTimeout = time.Millisecond
-			}
-			binary.BigEndian.PutUint32(cmd.dataBuffer[22:], uint32(serverTimeout/time.Millisecond))
-		}
+		cmd.setServerTimeout(deadline, clock.Now())
 
 		// now that the deadline has been set in the buffer, compress the contents
 		if err = cmd.compress(); err != nil {
 			applyTransactionErrorMetrics(cmd.node)
+			attempts = cmd.appendAttempt(attempts, err, iterationStart)
 			return chainErrors(err, errChain).iter(cmd.commandSentCounter).setNode(cmd.node).setInDoubt(ifc.isRead(), cmd.commandSentCounter)
 		}
 
 		// now that the deadline has been set in the buffer, compress the contents
 		if err = cmd.prepareBuffer(ifc, deadline); err != nil {
 			applyTransactionErrorMetrics(cmd.node)
+			attempts = cmd.appendAttempt(attempts, err, iterationStart)
 			applyTransactionMetrics(cmd.node, ifc.transactionType(), transStart)
 			return chainErrors(err, errChain).iter(cmd.commandSentCounter).setNode(cmd.node)
 		}
 
+		// capture the raw buffers of the sampled commands
+		var capture *wireCaptureRecorder
+		if cmd.node.cluster != nil && cmd.node.cluster.wireCapture.sample() {
+			capture = cmd.node.cluster.wireCapture.start(ifc.transactionType().String(), cmd.node.String(), cmd.dataBuffer[:cmd.dataOffset])
+		}
+
 		// Send command.
 		cmd.commandWasSent = true
 		_, err = cmd.conn.Write(cmd.dataBuffer[:cmd.dataOffset])
 		if err != nil {
+			capture.finish(err)
 			applyTransactionErrorMetrics(cmd.node)
 
 			// chain the errors
+			attempts = cmd.appendAttempt(attempts, err, iterationStart)
 			errChain = chainErrors(err, errChain).iter(cmd.commandSentCounter).setNode(cmd.node).setInDoubt(ifc.isRead(), cmd.commandSentCounter)
 
 			isClientTimeout = false
@@ -2748,12 +2937,21 @@ func (cmd *baseCommand) executeAt(ifc command, policy *BasePolicy, deadline time
 			continue
 		}
 
+		// inject the faults of the tests
+		if cmd.node.cluster != nil {
+			cmd.node.cluster.clientPolicy.FaultInjector.inject(cmd.conn, ifc.transactionType().String(), cmd.node)
+		}
+
 		// Parse results.
-		err = ifc.parseResult(ifc, cmd.conn)
+		cmd.conn.capture = capture
+		err = cmd.parseResultOrCancel(ifc, cmd.conn)
+		cmd.conn.capture = nil
+		capture.finish(err)
 		if err != nil {
 			applyTransactionErrorMetrics(cmd.node)
 
 			// chain the errors
+			attempts = cmd.appendAttempt(attempts, err, iterationStart)
 			errChain = chainErrors(err, errChain).iter(cmd.commandSentCounter).setNode(cmd.node).setInDoubt(ifc.isRead(), cmd.commandSentCounter)
 
 			if networkError(err) {
@@ -2795,6 +2993,7 @@ func (cmd *baseCommand) executeAt(ifc command, policy *BasePolicy, deadline time
 		}
 
 		applyTransactionMetrics(cmd.node, ifc.transactionType(), transStart)
+		applyLatencySample(cmd.node, ifc.transactionType(), attemptStart)
 
 		// in case it has grown and re-allocated, it means
 		// it was borrowed from the pool, sp put it back.
@@ -2824,6 +3023,18 @@ func (cmd *baseCommand) executeAt(ifc command, policy *BasePolicy, deadline time
 	return errChain
 }
 
+// appendAttempt records a failed attempt of the command on the current node.
+func (cmd *baseCommand) appendAttempt(attempts []CommandAttempt, err Error, start time.Time) []CommandAttempt {
+	return append(attempts, CommandAttempt{
+		Iteration:     cmd.commandSentCounter,
+		Node:          cmd.node,
+		Latency:       time.Since(start),
+		ServerTimeout: cmd.serverTimeout,
+		ResultCode:    err.resultCode(),
+		Err:           err,
+	})
+}
+
 func (cmd *baseCommand) prepareBuffer(ifc command, deadline time.Time) Error {
 	// Set command buffer.
 	if err := ifc.writeBuffer(ifc); err != nil {
@@ -2831,19 +3042,37 @@ func (cmd *baseCommand) prepareBuffer(ifc command, deadline time.Time) Error {
 	}
 
 	// Reset timeout in send buffer (destined for server) and socket.
-	binary.BigEndian.PutUint32(cmd.dataBuffer[22:], 0)
-	if !deadline.IsZero() {
-		serverTimeout := time.Until(deadline)
-		if serverTimeout < time.Millisecond {
-			serverTimeout = time.Millisecond
-		}
-		binary.BigEndian.PutUint32(cmd.dataBuffer[22:], uint32(serverTimeout/time.Millisecond))
-	}
+	cmd.setServerTimeout(deadline, ifc.clock().Now())
 
 	// now that the deadline has been set in the buffer, compress the contents
 	return cmd.compress()
 }
 
+// setServerTimeout writes the time remaining until the deadline of the command to the
+// server timeout field of the header, so that the server abandons the command when the client does.
+// It is recomputed from the original deadline for each attempt.
+func (cmd *baseCommand) setServerTimeout(deadline, now time.Time) {
+	cmd.serverTimeout = serverTimeoutFor(deadline, now)
+	binary.BigEndian.PutUint32(cmd.dataBuffer[22:], uint32(cmd.serverTimeout/time.Millisecond))
+}
+
+// serverTimeoutFor returns the server timeout of a command sent at now, in whole milliseconds.
+// It is 0, meaning no timeout, for the commands without a deadline, and at least
+// a millisecond for the commands past their deadline.
+func serverTimeoutFor(deadline, now time.Time) time.Duration {
+	if deadline.IsZero() {
+		return 0
+	}
+
+	timeout := deadline.Sub(now).Truncate(time.Millisecond)
+	if timeout < time.Millisecond {
+		return time.Millisecond
+	} else if timeout > math.MaxUint32*time.Millisecond {
+		return math.MaxUint32 * time.Millisecond
+	}
+	return timeout
+}
+
 func (cmd *baseCommand) canPutConnBack() bool {
 	return true
 }
@@ -2866,6 +3095,15 @@ func applyTransactionMetrics(node *Node, tt transactionType, tb time.Time) {
 	}
 }
 
+// applyLatencySample records the latency of a successful attempt of a single record command
+// for the adaptive timeouts of the node.
+func applyLatencySample(node *Node, tt transactionType, tb time.Time) {
+	switch tt {
+	case ttGet, ttGetHeader, ttExists, ttPut, ttDelete, ttOperate, ttUDF:
+		node.latencies.add(time.Since(tb))
+	}
+}
+
 func applyTransactionErrorMetrics(node *Node) {
 	if node != nil {
 		node.stats.TransactionErrorCount.GetAndIncrement()
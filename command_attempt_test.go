@@ -0,0 +1,160 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+// failingNodeCommand fails to select a node, returning the errors in order.
+type failingNodeCommand struct {
+	readCommand
+
+	nodes []*Node
+	errs  []Error
+	calls int
+}
+
+func (cmd *failingNodeCommand) getNode(ifc command) (*Node, Error) {
+	i := cmd.calls % len(cmd.errs)
+	cmd.calls++
+	return cmd.nodes[i], cmd.errs[i]
+}
+
+func (cmd *failingNodeCommand) prepareRetry(ifc command, isTimeout bool) bool {
+	return true
+}
+
+var _ = gg.Describe("Command attempts", func() {
+
+	newCommand := func(maxRetries int, omitKey bool) *failingNodeCommand {
+		policy := NewPolicy()
+		policy.OmitKeyInErrors = omitKey
+		policy.MaxRetries = maxRetries
+		policy.SleepBetweenRetries = 0
+		policy.TotalTimeout = 0
+
+		key, err := NewKey("test", "set", 1)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		rc, err := newReadCommand(nil, policy, key, nil, nil)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		clstr := new(Cluster)
+		return &failingNodeCommand{
+			readCommand: rc,
+			nodes:       []*Node{{name: "A", cluster: clstr}, {name: "B", cluster: clstr}},
+			errs:        []Error{newError(types.TIMEOUT, "timed out on A"), newError(types.NETWORK_ERROR, "EOF on B")},
+		}
+	}
+
+	gg.It("must attach the errors of all the attempts to the returned error", func() {
+		cmd := newCommand(2, false)
+		err := cmd.execute(cmd)
+		gm.Expect(err.Matches(types.MAX_RETRIES_EXCEEDED)).To(gm.BeTrue())
+
+		attempts := err.Attempts()
+		gm.Expect(attempts).To(gm.HaveLen(2))
+		for i, attempt := range attempts {
+			gm.Expect(attempt.Iteration).To(gm.Equal(i + 1))
+			gm.Expect(attempt.Node).To(gm.BeIdenticalTo(cmd.nodes[i]))
+			gm.Expect(attempt.Err).To(gm.BeIdenticalTo(cmd.errs[i]))
+			gm.Expect(attempt.Latency).To(gm.BeNumerically(">=", 0))
+		}
+		gm.Expect(attempts[0].ResultCode).To(gm.Equal(types.TIMEOUT))
+		gm.Expect(attempts[1].ResultCode).To(gm.Equal(types.NETWORK_ERROR))
+
+		ae := &AerospikeError{}
+		gm.Expect(errors.As(err, &ae)).To(gm.BeTrue())
+		gm.Expect(ae.Attempts()).To(gm.HaveLen(2))
+	})
+
+	gg.It("must not report a server timeout for the attempts that were not sent", func() {
+		cmd := newCommand(2, false)
+		cmd.policy.TotalTimeout = time.Minute
+		err := cmd.execute(cmd)
+		gm.Expect(err).To(gm.HaveOccurred())
+		for _, attempt := range err.Attempts() {
+			gm.Expect(attempt.ServerTimeout).To(gm.BeZero())
+		}
+	})
+
+	gg.It("must recompute the server timeout from the deadline of the command", func() {
+		cmd := newCommand(0, false)
+		cmd.dataBuffer = make([]byte, 64)
+		headerTimeout := func() time.Duration {
+			return time.Duration(binary.BigEndian.Uint32(cmd.dataBuffer[22:])) * time.Millisecond
+		}
+
+		now := time.Now()
+		deadline := now.Add(100 * time.Millisecond)
+		cmd.setServerTimeout(deadline, now)
+		gm.Expect(cmd.serverTimeout).To(gm.Equal(100 * time.Millisecond))
+		gm.Expect(headerTimeout()).To(gm.Equal(100 * time.Millisecond))
+
+		// the retry only gets the time left
+		cmd.setServerTimeout(deadline, now.Add(60*time.Millisecond+500*time.Microsecond))
+		gm.Expect(cmd.serverTimeout).To(gm.Equal(39 * time.Millisecond))
+		gm.Expect(headerTimeout()).To(gm.Equal(39 * time.Millisecond))
+
+		cmd.setServerTimeout(deadline, now.Add(time.Second))
+		gm.Expect(headerTimeout()).To(gm.Equal(time.Millisecond))
+
+		cmd.setServerTimeout(time.Time{}, now)
+		gm.Expect(cmd.serverTimeout).To(gm.BeZero())
+		gm.Expect(headerTimeout()).To(gm.BeZero())
+
+		gm.Expect(serverTimeoutFor(now.Add(100*24*time.Hour), now)).To(gm.Equal(math.MaxUint32 * time.Millisecond))
+	})
+
+	gg.It("must not attach the attempts to the errors of commands that were not retried", func() {
+		cmd := newCommand(0, false)
+		err := cmd.execute(cmd)
+		gm.Expect(err).To(gm.HaveOccurred())
+		gm.Expect(err.Attempts()).To(gm.BeNil())
+	})
+
+	gg.It("must attach the command and the record to the returned error", func() {
+		cmd := newCommand(0, false)
+		err := cmd.execute(cmd)
+		gm.Expect(err).To(gm.HaveOccurred())
+		gm.Expect(err.CommandType()).To(gm.Equal("get"))
+		gm.Expect(err.Namespace()).To(gm.Equal("test"))
+		gm.Expect(err.SetName()).To(gm.Equal("set"))
+		gm.Expect(err.Digest()).To(gm.Equal(cmd.key.Digest()))
+		gm.Expect(err.Error()).To(gm.ContainSubstring(", Command: get, Record: test:set:"))
+
+		ae := &AerospikeError{}
+		gm.Expect(errors.As(err, &ae)).To(gm.BeTrue())
+		gm.Expect(ae.Digest()).To(gm.Equal(cmd.key.Digest()))
+	})
+
+	gg.It("must not attach the record to the returned error if the policy omits it", func() {
+		cmd := newCommand(0, true)
+		err := cmd.execute(cmd)
+		gm.Expect(err).To(gm.HaveOccurred())
+		gm.Expect(err.CommandType()).To(gm.Equal("get"))
+		gm.Expect(err.Namespace()).To(gm.BeEmpty())
+		gm.Expect(err.Digest()).To(gm.BeNil())
+		gm.Expect(err.Error()).ToNot(gm.ContainSubstring("Record:"))
+	})
+})
@@ -0,0 +1,125 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import "time"
+
+// CommandInfo describes a command for the interceptors of the client. See CommandInterceptor.
+type CommandInfo struct {
+	// Command is the type of the command, e.g. "get" or "batch-read".
+	Command string
+
+	// Key of the record for the single record commands. It is nil for the other commands.
+	Key *Key
+
+	// Statement of the query for the query commands. It is nil for the other commands.
+	Statement *Statement
+
+	// Policy of the command. The changes made by the Before hooks apply to the command.
+	Policy *BasePolicy
+
+	// AuditContext is the BasePolicy.AuditContext of the command, identifying the effective
+	// user or tenant of the command.
+	AuditContext string
+
+	// Node the command is sent to. In the After hooks, it is the node of the last attempt.
+	Node *Node
+
+	// Iterations is the number of attempts made to send the command. It is set for the After hooks.
+	Iterations int
+
+	// Latency is the duration of the command, including the retries. It is set for the After hooks.
+	Latency time.Duration
+
+	// ServerTimeout is the server timeout sent with the last attempt, computed from the time
+	// remaining until the deadline of the command. It is set for the After hooks, and is 0
+	// if the last attempt was not sent, or if the command has no deadline.
+	ServerTimeout time.Duration
+
+	start time.Time
+}
+
+// CommandInterceptor hooks into the execution of the commands of the client, e.g. for authorization
+// checks, fault injection or custom telemetry. Interceptors are set via ClientPolicy.Interceptors,
+// and are called for every command sent to a node of the cluster; batch, scan and query commands
+// call them once per node.
+//
+// The hooks are called synchronously on the goroutine of the command, and must be safe
+// for concurrent use.
+type CommandInterceptor interface {
+	// Before is called once the node of the command is selected, before the command is sent.
+	// It may change the policy of the command. A non-nil error cancels the command without retries,
+	// and is returned to the caller. Errors which do not implement Error are wrapped in an Error
+	// with the COMMON_ERROR result code.
+	Before(info *CommandInfo) error
+
+	// After is called when the command completes with the result of the command.
+	// It is called only for the interceptors whose Before hook returned nil, in reverse order.
+	After(info *CommandInfo, err Error)
+}
+
+// statementCommand is implemented by the query commands.
+type statementCommand interface {
+	commandStatement() *Statement
+}
+
+// interceptorChain runs the interceptors of a command.
+type interceptorChain struct {
+	interceptors []CommandInterceptor
+	info         CommandInfo
+
+	// number of interceptors whose Before hook succeeded
+	passed int
+}
+
+// before runs the Before hooks of the interceptors in order, and stops at the first error.
+func (ic *interceptorChain) before(ifc command, policy *BasePolicy, node *Node) Error {
+	ic.info = CommandInfo{
+		Command:      ifc.transactionType().String(),
+		Policy:       policy,
+		AuditContext: policy.AuditContext,
+		Node:         node,
+		start:        time.Now(),
+	}
+	if kc, ok := ifc.(keyedCommand); ok {
+		ic.info.Key = kc.commandKey()
+	}
+	if sc, ok := ifc.(statementCommand); ok {
+		ic.info.Statement = sc.commandStatement()
+	}
+
+	for _, i := range ic.interceptors {
+		if err := i.Before(&ic.info); err != nil {
+			if ae, ok := err.(Error); ok {
+				return ae
+			}
+			return newCommonError(err, err.Error())
+		}
+		ic.passed++
+	}
+	return nil
+}
+
+// after runs the After hooks of the interceptors whose Before hooks succeeded, in reverse order.
+func (ic *interceptorChain) after(node *Node, iterations int, serverTimeout time.Duration, err Error) {
+	ic.info.Node = node
+	ic.info.Iterations = iterations
+	ic.info.ServerTimeout = serverTimeout
+	ic.info.Latency = time.Since(ic.info.start)
+
+	for i := ic.passed - 1; i >= 0; i-- {
+		ic.interceptors[i].After(&ic.info, err)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+// recordingInterceptor logs the calls of its hooks, and fails the Before hook with err.
+type recordingInterceptor struct {
+	name  string
+	calls *[]string
+	err   error
+	after func(info *CommandInfo, err Error)
+}
+
+func (ri *recordingInterceptor) Before(info *CommandInfo) error {
+	*ri.calls = append(*ri.calls, ri.name+".before")
+	info.Policy.MaxRetries = 7
+	return ri.err
+}
+
+func (ri *recordingInterceptor) After(info *CommandInfo, err Error) {
+	*ri.calls = append(*ri.calls, ri.name+".after")
+	if ri.after != nil {
+		ri.after(info, err)
+	}
+}
+
+// nodeReadCommand is a read command sent to a fixed node.
+type nodeReadCommand struct {
+	readCommand
+
+	node *Node
+}
+
+func (cmd *nodeReadCommand) getNode(ifc command) (*Node, Error) {
+	return cmd.node, nil
+}
+
+var _ = gg.Describe("Command interceptors", func() {
+
+	gg.It("must run the hooks in order, and the After hooks in reverse order", func() {
+		var calls []string
+		chain := &interceptorChain{interceptors: []CommandInterceptor{
+			&recordingInterceptor{name: "a", calls: &calls},
+			&recordingInterceptor{name: "b", calls: &calls},
+		}}
+
+		key, err := NewKey("test", "set", 1)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		cmd, err := newReadCommand(nil, NewPolicy(), key, nil, nil)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		gm.Expect(chain.before(&cmd, cmd.policy, nil)).ToNot(gm.HaveOccurred())
+		gm.Expect(chain.info.Key).To(gm.Equal(key))
+		gm.Expect(chain.info.Command).To(gm.Equal("get"))
+		gm.Expect(cmd.policy.MaxRetries).To(gm.Equal(7))
+
+		chain.after(nil, 1, 20*time.Millisecond, nil)
+		gm.Expect(calls).To(gm.Equal([]string{"a.before", "b.before", "b.after", "a.after"}))
+		gm.Expect(chain.info.Iterations).To(gm.Equal(1))
+		gm.Expect(chain.info.ServerTimeout).To(gm.Equal(20 * time.Millisecond))
+	})
+
+	gg.It("must report the audit context of the command", func() {
+		var calls []string
+		var audit string
+		chain := &interceptorChain{interceptors: []CommandInterceptor{
+			&recordingInterceptor{name: "a", calls: &calls, after: func(info *CommandInfo, err Error) {
+				audit = info.AuditContext
+			}},
+		}}
+
+		key, err := NewKey("test", "set", 1)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		policy := NewPolicy()
+		policy.AuditContext = "tenant=acme;user=alice"
+		cmd, err := newReadCommand(nil, policy, key, nil, nil)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		gm.Expect(chain.before(&cmd, cmd.policy, nil)).ToNot(gm.HaveOccurred())
+		chain.after(nil, 1, 0, nil)
+		gm.Expect(audit).To(gm.Equal("tenant=acme;user=alice"))
+	})
+
+	gg.It("must short-circuit the command with the error of a Before hook", func() {
+		var calls []string
+		var afterInfo *CommandInfo
+		var afterErr Error
+
+		node := &Node{name: "BB9000000000001", cluster: &Cluster{}}
+		node.active.Set(true)
+		node.cluster.clientPolicy.Interceptors = []CommandInterceptor{
+			&recordingInterceptor{name: "a", calls: &calls, after: func(info *CommandInfo, err Error) {
+				afterInfo, afterErr = info, err
+			}},
+			&recordingInterceptor{name: "b", calls: &calls, err: errors.New("denied")},
+			&recordingInterceptor{name: "c", calls: &calls},
+		}
+
+		key, err := NewKey("test", "set", 1)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		rc, err := newReadCommand(nil, NewPolicy(), key, nil, nil)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		cmd := &nodeReadCommand{readCommand: rc, node: node}
+
+		err = cmd.execute(cmd)
+		gm.Expect(err).To(gm.HaveOccurred())
+		gm.Expect(err.Matches(types.COMMON_ERROR)).To(gm.BeTrue())
+		gm.Expect(err.Error()).To(gm.ContainSubstring("denied"))
+		gm.Expect(err.CommandType()).To(gm.Equal("get"))
+		gm.Expect(cmd.commandWasSent).To(gm.BeFalse())
+
+		gm.Expect(calls).To(gm.Equal([]string{"a.before", "b.before", "a.after"}))
+		gm.Expect(afterErr).To(gm.Equal(err))
+		gm.Expect(afterInfo.Node).To(gm.Equal(node))
+		gm.Expect(afterInfo.Key).To(gm.Equal(key))
+	})
+})
@@ -0,0 +1,273 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+	ParticleType "github.com/aerospike/aerospike-client-go/v7/types/particle_type"
+)
+
+// compressedValueMagic is the prefix of the blobs written by CompressedValue.
+var compressedValueMagic = []byte{0xAC, 'C', 'V'}
+
+// compressedValueHeaderSize is the size of the magic, the algorithm and the particle type of the
+// original value. It is followed by the uvarint size of the original value, and the compressed data.
+const compressedValueHeaderSize = 5
+
+// ValueCompressionPolicy determines how CompressedValue compresses the bin values.
+type ValueCompressionPolicy struct {
+	// Algorithm is the compression algorithm of the values.
+	// ZSTD and LZ4 require a compressor registered via RegisterZstdCompressor and RegisterLZ4Compressor;
+	// if none is registered, the values are compressed with zlib.
+	// Default: CompressionZlib
+	Algorithm CompressionAlgorithm
+
+	// Level is the compression level. 0 means the default level of the algorithm.
+	// Default: 0
+	Level int
+
+	// Threshold is the minimum size of a value, in bytes, for it to be compressed.
+	// Smaller values, and values that do not shrink, are stored as is.
+	// Default: 1024
+	Threshold int
+}
+
+// NewValueCompressionPolicy returns a ValueCompressionPolicy with the default values.
+func NewValueCompressionPolicy() *ValueCompressionPolicy {
+	return &ValueCompressionPolicy{
+		Algorithm: CompressionZlib,
+		Threshold: 1024,
+	}
+}
+
+// CompressedValue compresses a bin value on the client before it is sent to the server.
+// The compressed value is stored as a blob with a small header, and is decompressed
+// transparently when the bin is read, so the reads return the original value.
+// The server sees the bin as a blob: expressions, secondary indexes and CDT operations
+// cannot be used on the original value.
+//
+// Compression pays off for large values like JSON documents. The value is compressed once,
+// when the command is serialized; a CompressedValue can be reused in several commands.
+type CompressedValue struct {
+	value  Value
+	policy ValueCompressionPolicy
+
+	once sync.Once
+	data []byte
+	err  Error
+}
+
+// NewCompressedValue returns a CompressedValue for the value.
+// If the policy is nil, the default policy will be used.
+func NewCompressedValue(policy *ValueCompressionPolicy, value interface{}) *CompressedValue {
+	if policy == nil {
+		policy = NewValueCompressionPolicy()
+	}
+
+	return &CompressedValue{
+		value:  NewValue(value),
+		policy: *policy,
+	}
+}
+
+// compress compresses the value if it is larger than the threshold.
+func (vl *CompressedValue) compress() {
+	vl.once.Do(func() {
+		size, err := vl.value.EstimateSize()
+		if err != nil {
+			vl.err = err
+			return
+		}
+
+		if size < vl.policy.Threshold {
+			return
+		}
+
+		buf := newBuffer(size)
+		if _, err := vl.value.write(buf); err != nil {
+			vl.err = err
+			return
+		}
+
+		vl.data, vl.err = compressValueBytes(vl.policy.Algorithm, vl.policy.Level, vl.value.GetType(), buf.Bytes())
+	})
+}
+
+// Compressed returns true if the value is stored compressed.
+func (vl *CompressedValue) Compressed() bool {
+	vl.compress()
+	return vl.data != nil
+}
+
+// EstimateSize returns the size of the value in wire protocol.
+func (vl *CompressedValue) EstimateSize() (int, Error) {
+	vl.compress()
+	if vl.err != nil {
+		return 0, vl.err
+	}
+
+	if vl.data == nil {
+		return vl.value.EstimateSize()
+	}
+	return len(vl.data), nil
+}
+
+func (vl *CompressedValue) write(cmd BufferEx) (int, Error) {
+	if vl.data == nil {
+		return vl.value.write(cmd)
+	}
+	return cmd.Write(vl.data)
+}
+
+func (vl *CompressedValue) pack(cmd BufferEx) (int, Error) {
+	vl.compress()
+	if vl.err != nil {
+		return 0, vl.err
+	}
+
+	if vl.data == nil {
+		return vl.value.pack(cmd)
+	}
+	return packBytes(cmd, vl.data)
+}
+
+// GetType returns wire protocol value type.
+func (vl *CompressedValue) GetType() int {
+	vl.compress()
+	if vl.data == nil {
+		return vl.value.GetType()
+	}
+	return ParticleType.BLOB
+}
+
+// GetObject returns the original value as an interface{}.
+func (vl *CompressedValue) GetObject() interface{} {
+	return vl.value.GetObject()
+}
+
+// String implements Stringer interface.
+func (vl *CompressedValue) String() string {
+	return vl.value.String()
+}
+
+// valueCompressor returns the compressor of the algorithm, or zlib if the algorithm has no registered compressor.
+// The fallback is logged once per algorithm.
+func valueCompressor(algo CompressionAlgorithm) (Compressor, CompressionAlgorithm) {
+	switch algo {
+	case CompressionZlib:
+		return zlibCompressor{}, CompressionZlib
+	case CompressionZstd:
+		if c := zstdCompressor.Get(); c != nil {
+			return c, algo
+		}
+	case CompressionLZ4:
+		if c := lz4Compressor.Get(); c != nil {
+			return c, algo
+		}
+	}
+
+	warnCompressionFallback(algo)
+	return zlibCompressor{}, CompressionZlib
+}
+
+// compressValueBytes returns the compressed value with its header, or nil if the value does not shrink.
+func compressValueBytes(algo CompressionAlgorithm, level int, particleType int, b []byte) ([]byte, Error) {
+	c, algo := valueCompressor(algo)
+
+	var buf bytes.Buffer
+	buf.Write(compressedValueMagic)
+	buf.WriteByte(byte(algo))
+	buf.WriteByte(byte(particleType))
+
+	var size [binary.MaxVarintLen64]byte
+	buf.Write(size[:binary.PutUvarint(size[:], uint64(len(b)))])
+
+	w, err := c.NewWriter(&buf, level)
+	if err != nil {
+		return nil, newCommonError(err, "failed to compress the value")
+	}
+	if _, err := w.Write(b); err != nil {
+		return nil, newCommonError(err, "failed to compress the value")
+	}
+	if err := w.Close(); err != nil {
+		return nil, newCommonError(err, "failed to compress the value")
+	}
+
+	if buf.Len() >= len(b) {
+		return nil, nil
+	}
+	return buf.Bytes(), nil
+}
+
+// isCompressedValue returns true if the blob starts with the header of a CompressedValue.
+func isCompressedValue(b []byte) bool {
+	return len(b) > compressedValueHeaderSize && bytes.Equal(b[:len(compressedValueMagic)], compressedValueMagic)
+}
+
+// decompressValue returns the original value of a blob written by CompressedValue.
+// ok is false if the blob is not a valid compressed value, and must be returned as is.
+func decompressValue(b []byte) (res interface{}, ok bool, err Error) {
+	algo := CompressionAlgorithm(b[3])
+	particleType := int(b[4])
+
+	var c Compressor
+	switch algo {
+	case CompressionZlib:
+		c = zlibCompressor{}
+	case CompressionZstd:
+		c = zstdCompressor.Get()
+	case CompressionLZ4:
+		c = lz4Compressor.Get()
+	default:
+		return nil, false, nil
+	}
+
+	size, n := binary.Uvarint(b[compressedValueHeaderSize:])
+	if n <= 0 {
+		return nil, false, nil
+	}
+
+	if c == nil {
+		return nil, false, newError(types.PARSE_ERROR, fmt.Sprintf("the value is compressed with algorithm %d, but no compressor is registered for it", algo))
+	}
+
+	r, rerr := c.NewReader(bytes.NewReader(b[compressedValueHeaderSize+n:]))
+	if rerr != nil {
+		return nil, false, nil
+	}
+	defer r.Close()
+
+	// do not trust the size for the allocation, the blob may not be a compressed value
+	data, rerr := io.ReadAll(io.LimitReader(r, int64(size)+1))
+	if rerr != nil || uint64(len(data)) != size {
+		return nil, false, nil
+	}
+
+	if particleType == ParticleType.BLOB {
+		return data, true, nil
+	}
+
+	res, err = bytesToParticle(particleType, data, 0, len(data))
+	if err != nil {
+		return nil, false, nil
+	}
+	return res, true, nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"compress/flate"
+	"io"
+	"strings"
+
+	ParticleType "github.com/aerospike/aerospike-client-go/v7/types/particle_type"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+// flateCompressor stands in for an LZ4 implementation in the tests.
+type flateCompressor struct{}
+
+func (flateCompressor) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	if level == 0 {
+		level = flate.DefaultCompression
+	}
+	return flate.NewWriter(w, level)
+}
+
+func (flateCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return flate.NewReader(r), nil
+}
+
+var _ = gg.Describe("CompressedValue", func() {
+
+	// serialize returns the bytes of the value as sent in the wire protocol.
+	serialize := func(v Value) []byte {
+		size, err := v.EstimateSize()
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		buf := newBuffer(size)
+		n, err := v.write(buf)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(n).To(gm.Equal(size))
+		return buf.Bytes()
+	}
+
+	roundTrip := func(v Value) interface{} {
+		b := serialize(v)
+		res, err := bytesToParticle(v.GetType(), b, 0, len(b))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		return res
+	}
+
+	json := strings.Repeat(`{"name":"Alice","tags":["a","b","c"]},`, 100)
+
+	gg.It("must compress and decompress large values", func() {
+		v := NewCompressedValue(nil, json)
+		gm.Expect(v.Compressed()).To(gm.BeTrue())
+		gm.Expect(v.GetType()).To(gm.Equal(ParticleType.BLOB))
+		gm.Expect(v.GetObject()).To(gm.Equal(json))
+
+		size, err := v.EstimateSize()
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(size).To(gm.BeNumerically("<", len(json)/10))
+		gm.Expect(roundTrip(v)).To(gm.Equal(json))
+
+		blob := []byte(json)
+		gm.Expect(roundTrip(NewCompressedValue(nil, blob))).To(gm.Equal(blob))
+
+		list := make([]interface{}, 500)
+		for i := range list {
+			list[i] = "item"
+		}
+		lv := NewCompressedValue(nil, list)
+		gm.Expect(lv.Compressed()).To(gm.BeTrue())
+		gm.Expect(roundTrip(lv)).To(gm.Equal(list))
+	})
+
+	gg.It("must store small values and values that do not shrink as is", func() {
+		v := NewCompressedValue(nil, "small")
+		gm.Expect(v.Compressed()).To(gm.BeFalse())
+		gm.Expect(v.GetType()).To(gm.Equal(ParticleType.STRING))
+		gm.Expect(roundTrip(v)).To(gm.Equal("small"))
+
+		policy := NewValueCompressionPolicy()
+		policy.Threshold = 0
+		random := make([]byte, 64)
+		for i := range random {
+			random[i] = byte(i*151 + 17)
+		}
+		gm.Expect(NewCompressedValue(policy, random).Compressed()).To(gm.BeFalse())
+	})
+
+	gg.It("must use the registered compressors", func() {
+		policy := NewValueCompressionPolicy()
+		policy.Algorithm = CompressionLZ4
+
+		// falls back to zlib without a registered compressor
+		b := serialize(NewCompressedValue(policy, json))
+		gm.Expect(CompressionAlgorithm(b[3])).To(gm.Equal(CompressionZlib))
+
+		RegisterLZ4Compressor(flateCompressor{})
+		b = serialize(NewCompressedValue(policy, json))
+		gm.Expect(CompressionAlgorithm(b[3])).To(gm.Equal(CompressionLZ4))
+		res, err := bytesToParticle(ParticleType.BLOB, b, 0, len(b))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(res).To(gm.Equal(json))
+
+		RegisterLZ4Compressor(nil)
+		_, err = bytesToParticle(ParticleType.BLOB, b, 0, len(b))
+		gm.Expect(err).To(gm.HaveOccurred())
+	})
+
+	gg.It("must return the blobs that are not compressed values as is", func() {
+		b := append(append([]byte{}, compressedValueMagic...), 0, byte(ParticleType.STRING), 10, 1, 2, 3)
+		res, err := bytesToParticle(ParticleType.BLOB, b, 0, len(b))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(res).To(gm.Equal(b))
+	})
+})
@@ -0,0 +1,160 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"sync"
+
+	iatomic "github.com/aerospike/aerospike-client-go/v7/internal/atomic"
+	"github.com/aerospike/aerospike-client-go/v7/logger"
+)
+
+// CompressionAlgorithm determines the algorithm used to compress the commands
+// sent to the server when BasePolicy.UseCompression is set.
+type CompressionAlgorithm byte
+
+const (
+	// CompressionZlib compresses the commands using zlib. This is supported by all servers.
+	CompressionZlib CompressionAlgorithm = iota
+
+	// CompressionZstd compresses the commands using ZSTD. The client does not ship a ZSTD
+	// implementation; one must be registered via RegisterZstdCompressor.
+	// Aerospike servers only accept zlib compressed commands, so ZSTD is only used for the commands
+	// if ClientPolicy.EnableZstdCompression is set. Otherwise, or if no compressor is registered,
+	// the client falls back to zlib; see compressorFor.
+	CompressionZstd
+
+	// CompressionLZ4 compresses the bin values of CompressedValue using LZ4. The client does not ship
+	// an LZ4 implementation; one must be registered via RegisterLZ4Compressor.
+	// LZ4 is not supported by the servers for the compression of the commands; the client uses zlib instead.
+	CompressionLZ4
+)
+
+// Compressor provides the streaming encoder and decoder for a compression algorithm.
+// Implementations must be safe for concurrent use.
+type Compressor interface {
+	// NewWriter returns a writer that compresses into w with the given level.
+	// Level 0 means the default level of the algorithm.
+	NewWriter(w io.Writer, level int) (io.WriteCloser, error)
+
+	// NewReader returns a reader that decompresses the data read from r.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// zstdMagic is the magic number at the beginning of every ZSTD frame.
+var zstdMagic = []byte{0x28, 0xB5, 0x2F, 0xFD}
+
+var zstdCompressor iatomic.TypedVal[Compressor]
+
+// RegisterZstdCompressor sets the ZSTD implementation used by the client.
+// Passing nil disables ZSTD support.
+func RegisterZstdCompressor(c Compressor) {
+	zstdCompressor.Set(c)
+}
+
+var lz4Compressor iatomic.TypedVal[Compressor]
+
+// RegisterLZ4Compressor sets the LZ4 implementation used by the client for CompressedValue.
+// Passing nil disables LZ4 support.
+func RegisterLZ4Compressor(c Compressor) {
+	lz4Compressor.Set(c)
+}
+
+type zlibCompressor struct{}
+
+func (zlibCompressor) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	if level == 0 {
+		return zlib.NewWriter(w), nil
+	}
+	return zlib.NewWriterLevel(w, level)
+}
+
+func (zlibCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return zlib.NewReader(r)
+}
+
+// compressorFor returns the compressor to use for the commands to the node.
+// The commands fall back to zlib if the algorithm is not enabled for the node or has no
+// registered compressor. The fallbacks are counted in the compression-fallbacks node statistic,
+// and logged once per algorithm.
+func compressorFor(algo CompressionAlgorithm, node *Node) Compressor {
+	if algo == CompressionZlib {
+		return zlibCompressor{}
+	}
+
+	if algo == CompressionZstd && node != nil && node.SupportsZstdCompression() {
+		if c := zstdCompressor.Get(); c != nil {
+			return c
+		}
+	}
+
+	if node != nil {
+		node.stats.CompressionFallbacks.IncrementAndGet()
+	}
+	warnCompressionFallback(algo)
+	return zlibCompressor{}
+}
+
+var compressionFallbackWarnings [CompressionLZ4 + 1]sync.Once
+
+// warnCompressionFallback logs the first fallback of the algorithm to zlib.
+func warnCompressionFallback(algo CompressionAlgorithm) {
+	if int(algo) >= len(compressionFallbackWarnings) {
+		return
+	}
+
+	compressionFallbackWarnings[algo].Do(func() {
+		var reason string
+		switch {
+		case algo == CompressionZstd && zstdCompressor.Get() == nil:
+			reason = "no ZSTD compressor is registered via RegisterZstdCompressor"
+		case algo == CompressionZstd:
+			reason = "ClientPolicy.EnableZstdCompression is not set"
+		case algo == CompressionLZ4 && lz4Compressor.Get() == nil:
+			reason = "no LZ4 compressor is registered via RegisterLZ4Compressor"
+		default:
+			reason = "the servers do not accept LZ4 compressed commands"
+		}
+		logger.Logger.Warn("Compression algorithm %d falls back to zlib: %s", algo, reason)
+	})
+}
+
+// decompressorFor detects the compression algorithm of the stream from its magic
+// number and returns the reader to inflate it.
+// Servers compress the responses with zlib unless ZSTD was negotiated.
+func decompressorFor(r io.Reader) (io.ReadCloser, error) {
+	c := zstdCompressor.Get()
+	if c == nil {
+		return zlib.NewReader(r)
+	}
+
+	var magic [4]byte
+	n, err := io.ReadFull(r, magic[:])
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// too short for a ZSTD frame; let zlib inflate or reject the bytes read
+		return zlib.NewReader(bytes.NewReader(magic[:n]))
+	} else if err != nil {
+		return nil, err
+	}
+
+	r = io.MultiReader(bytes.NewReader(magic[:n]), r)
+	if bytes.Equal(magic[:], zstdMagic) {
+		return c.NewReader(r)
+	}
+	return zlib.NewReader(r)
+}
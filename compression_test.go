@@ -0,0 +1,63 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Command compression", func() {
+
+	gg.AfterEach(func() {
+		RegisterZstdCompressor(nil)
+	})
+
+	gg.It("must only use ZSTD if it is enabled and registered", func() {
+		cluster := &Cluster{}
+		node := &Node{name: "BB9000000000001", cluster: cluster}
+
+		// not enabled
+		RegisterZstdCompressor(flateCompressor{})
+		gm.Expect(compressorFor(CompressionZstd, node)).To(gm.Equal(zlibCompressor{}))
+		gm.Expect(node.stats.CompressionFallbacks.Get()).To(gm.Equal(1))
+
+		// not registered
+		cluster.clientPolicy.EnableZstdCompression = true
+		RegisterZstdCompressor(nil)
+		gm.Expect(compressorFor(CompressionZstd, node)).To(gm.Equal(zlibCompressor{}))
+		gm.Expect(node.stats.CompressionFallbacks.Get()).To(gm.Equal(2))
+
+		RegisterZstdCompressor(flateCompressor{})
+		gm.Expect(compressorFor(CompressionZstd, node)).To(gm.Equal(flateCompressor{}))
+		gm.Expect(node.stats.CompressionFallbacks.Get()).To(gm.Equal(2))
+	})
+
+	gg.It("must count the commands that fall back to zlib", func() {
+		cluster := &Cluster{}
+		cluster.clientPolicy.EnableZstdCompression = true
+		node := &Node{name: "BB9000000000001", cluster: cluster}
+
+		RegisterLZ4Compressor(flateCompressor{})
+		defer RegisterLZ4Compressor(nil)
+
+		gm.Expect(compressorFor(CompressionZlib, node)).To(gm.Equal(zlibCompressor{}))
+		gm.Expect(node.stats.CompressionFallbacks.Get()).To(gm.Equal(0))
+
+		// the servers never accept LZ4 compressed commands
+		gm.Expect(compressorFor(CompressionLZ4, node)).To(gm.Equal(zlibCompressor{}))
+		gm.Expect(node.stats.CompressionFallbacks.Get()).To(gm.Equal(1))
+	})
+})
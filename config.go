@@ -0,0 +1,330 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config describes a client and its default policies. It can be loaded from a YAML or JSON file
+// with LoadConfig, and is used to create a client with CreateClientWithConfig.
+// References to environment variables in the form of $VAR or ${VAR} are expanded when the
+// config is parsed, so that secrets like passwords do not have to be stored in the file.
+//
+// Fields that are not set keep their default values. Durations are written as strings
+// like "1500ms" or "2s".
+//
+//	hosts: ["10.0.0.1:3000", "10.0.0.2:3000"]
+//	user: app
+//	password: ${AEROSPIKE_PASSWORD}
+//	tls:
+//	  ca_file: /etc/aerospike/ca.pem
+//	  server_name: cluster1
+//	policies:
+//	  read:
+//	    total_timeout: 50ms
+//	    max_retries: 3
+//	  scan:
+//	    records_per_second: 5000
+type Config struct {
+	// Hosts are the seed nodes of the cluster, in the form of host:port.
+	Hosts []string `yaml:"hosts"`
+
+	// Proxy creates a proxy client instead of a native client.
+	Proxy bool `yaml:"proxy"`
+
+	// User and Password are the credentials used to authenticate to the cluster.
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+
+	// AuthMode is one of "internal", "external" or "pki".
+	AuthMode string `yaml:"auth_mode"`
+
+	// ClusterName sets ClientPolicy.ClusterName.
+	ClusterName string `yaml:"cluster_name"`
+
+	// TLS enables secure connections to the cluster.
+	TLS *TLSConfig `yaml:"tls"`
+
+	// The following fields set the ClientPolicy fields of the same name.
+	Timeout               *time.Duration `yaml:"timeout"`
+	IdleTimeout           *time.Duration `yaml:"idle_timeout"`
+	LoginTimeout          *time.Duration `yaml:"login_timeout"`
+	TendInterval          *time.Duration `yaml:"tend_interval"`
+	ConnectionQueueSize   *int           `yaml:"connection_queue_size"`
+	MinConnectionsPerNode *int           `yaml:"min_connections_per_node"`
+	MaxErrorRate          *int           `yaml:"max_error_rate"`
+	ErrorRateWindow       *int           `yaml:"error_rate_window"`
+	RackAware             bool           `yaml:"rack_aware"`
+	RackIds               []int          `yaml:"rack_ids"`
+
+	// Policies overrides the fields of the default policies of the client.
+	// Unlike the other fields, they can be changed while the client is running. See Config.Apply.
+	Policies PoliciesConfig `yaml:"policies"`
+}
+
+// TLSConfig describes the TLS settings of a Config.
+type TLSConfig struct {
+	// CAFile is the PEM file of the certificate authorities used to verify the server certificates.
+	// The system certificate pool is used if it is not set.
+	CAFile string `yaml:"ca_file"`
+
+	// CertFile and KeyFile are the PEM files of the client certificate, used for mutual authentication.
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+
+	// ServerName is the TLS name of the server nodes. It is set as the TLSName of the hosts.
+	ServerName string `yaml:"server_name"`
+
+	// InsecureSkipVerify disables the verification of the server certificates.
+	// It should only be used for testing.
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify"`
+}
+
+// PoliciesConfig overrides the fields of the default policies of a client.
+// The batch overrides also apply to the default batch record policies.
+type PoliciesConfig struct {
+	Read  *PolicyConfig `yaml:"read"`
+	Write *PolicyConfig `yaml:"write"`
+	Batch *PolicyConfig `yaml:"batch"`
+	Scan  *PolicyConfig `yaml:"scan"`
+	Query *PolicyConfig `yaml:"query"`
+}
+
+// PolicyConfig describes the fields of a policy that can be changed while the client is running.
+// Fields that are not set keep their current values.
+type PolicyConfig struct {
+	TotalTimeout        *time.Duration `yaml:"total_timeout"`
+	SocketTimeout       *time.Duration `yaml:"socket_timeout"`
+	MaxRetries          *int           `yaml:"max_retries"`
+	SleepBetweenRetries *time.Duration `yaml:"sleep_between_retries"`
+	UseCompression      *bool          `yaml:"use_compression"`
+	SendKey             *bool          `yaml:"send_key"`
+	ReadTouchTTLPercent *int32         `yaml:"read_touch_ttl_percent"`
+
+	// RecordsPerSecond limits the throughput of scans and queries. Ignored for the other policies.
+	RecordsPerSecond *int `yaml:"records_per_second"`
+}
+
+// LoadConfig reads and parses the YAML or JSON config file.
+func LoadConfig(path string) (*Config, Error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, newErrorAndWrap(err, types.PARAMETER_ERROR, fmt.Sprintf("error reading config file %s: %s", path, err))
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig parses a YAML or JSON config. Unknown fields are reported as errors.
+func ParseConfig(data []byte) (*Config, Error) {
+	data = []byte(os.ExpandEnv(string(data)))
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	c := new(Config)
+	// an empty document leaves the config unchanged
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, newErrorAndWrap(err, types.PARAMETER_ERROR, fmt.Sprintf("error parsing config: %s", err))
+	}
+	return c, nil
+}
+
+// SeedHosts returns the hosts of the config.
+func (c *Config) SeedHosts() ([]*Host, Error) {
+	hosts, err := NewHosts(c.Hosts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.TLS != nil && c.TLS.ServerName != "" {
+		for _, host := range hosts {
+			host.TLSName = c.TLS.ServerName
+		}
+	}
+	return hosts, nil
+}
+
+// ClientPolicy creates a ClientPolicy from the config. The TLS certificates are loaded from their files.
+func (c *Config) ClientPolicy() (*ClientPolicy, Error) {
+	policy := NewClientPolicy()
+	policy.User = c.User
+	policy.Password = c.Password
+	policy.ClusterName = c.ClusterName
+	policy.RackAware = c.RackAware
+	policy.RackIds = c.RackIds
+
+	switch strings.ToLower(c.AuthMode) {
+	case "", "internal":
+		policy.AuthMode = AuthModeInternal
+	case "external":
+		policy.AuthMode = AuthModeExternal
+	case "pki":
+		policy.AuthMode = AuthModePKI
+	default:
+		return nil, newError(types.PARAMETER_ERROR, fmt.Sprintf("invalid auth mode in config: %s", c.AuthMode))
+	}
+
+	setIfNotNil(&policy.Timeout, c.Timeout)
+	setIfNotNil(&policy.IdleTimeout, c.IdleTimeout)
+	setIfNotNil(&policy.LoginTimeout, c.LoginTimeout)
+	setIfNotNil(&policy.TendInterval, c.TendInterval)
+	setIfNotNil(&policy.ConnectionQueueSize, c.ConnectionQueueSize)
+	setIfNotNil(&policy.MinConnectionsPerNode, c.MinConnectionsPerNode)
+	setIfNotNil(&policy.MaxErrorRate, c.MaxErrorRate)
+	setIfNotNil(&policy.ErrorRateWindow, c.ErrorRateWindow)
+
+	if c.TLS != nil {
+		tlsConfig, err := c.TLS.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		policy.TlsConfig = tlsConfig
+	}
+
+	return policy, nil
+}
+
+func (tc *TLSConfig) tlsConfig() (*tls.Config, Error) {
+	res := &tls.Config{
+		InsecureSkipVerify: tc.InsecureSkipVerify,
+	}
+
+	if tc.CAFile != "" {
+		pem, err := os.ReadFile(tc.CAFile)
+		if err != nil {
+			return nil, newErrorAndWrap(err, types.PARAMETER_ERROR, fmt.Sprintf("error reading CA file %s: %s", tc.CAFile, err))
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, newError(types.PARAMETER_ERROR, fmt.Sprintf("no certificates found in CA file %s", tc.CAFile))
+		}
+		res.RootCAs = pool
+	}
+
+	if tc.CertFile != "" || tc.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(tc.CertFile, tc.KeyFile)
+		if err != nil {
+			return nil, newErrorAndWrap(err, types.PARAMETER_ERROR, fmt.Sprintf("error loading client certificate: %s", err))
+		}
+		res.Certificates = []tls.Certificate{cert}
+	}
+
+	return res, nil
+}
+
+// CreateClientWithConfig creates a native or proxy client from the config, and sets its default policies.
+func CreateClientWithConfig(c *Config) (ClientIfc, Error) {
+	policy, err := c.ClientPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	hosts, err := c.SeedHosts()
+	if err != nil {
+		return nil, err
+	}
+
+	typ := CTNative
+	if c.Proxy {
+		typ = CTProxy
+	}
+
+	clnt, err := CreateClientWithPolicyAndHost(typ, policy, hosts...)
+	if clnt != nil {
+		c.Apply(clnt)
+	}
+	return clnt, err
+}
+
+// Apply sets the default policies of the client to copies of the current ones, with the
+// overrides of the config applied. Commands that are already running are not affected.
+// Only Config.Policies is applied; the other fields require a new client.
+func (c *Config) Apply(clnt ClientIfc) {
+	if pc := c.Policies.Read; pc != nil {
+		policy := *clnt.GetDefaultPolicy()
+		pc.applyTo(&policy)
+		clnt.SetDefaultPolicy(&policy)
+	}
+
+	if pc := c.Policies.Write; pc != nil {
+		policy := *clnt.GetDefaultWritePolicy()
+		pc.applyTo(&policy.BasePolicy)
+		clnt.SetDefaultWritePolicy(&policy)
+	}
+
+	if pc := c.Policies.Batch; pc != nil {
+		policy := *clnt.GetDefaultBatchPolicy()
+		pc.applyTo(&policy.BasePolicy)
+		clnt.SetDefaultBatchPolicy(&policy)
+
+		if pc.SendKey != nil {
+			bwp := *clnt.GetDefaultBatchWritePolicy()
+			bwp.SendKey = *pc.SendKey
+			clnt.SetDefaultBatchWritePolicy(&bwp)
+
+			bdp := *clnt.GetDefaultBatchDeletePolicy()
+			bdp.SendKey = *pc.SendKey
+			clnt.SetDefaultBatchDeletePolicy(&bdp)
+
+			bup := *clnt.GetDefaultBatchUDFPolicy()
+			bup.SendKey = *pc.SendKey
+			clnt.SetDefaultBatchUDFPolicy(&bup)
+		}
+	}
+
+	if pc := c.Policies.Scan; pc != nil {
+		policy := *clnt.GetDefaultScanPolicy()
+		pc.applyTo(&policy.BasePolicy)
+		setIfNotNil(&policy.RecordsPerSecond, pc.RecordsPerSecond)
+		clnt.SetDefaultScanPolicy(&policy)
+	}
+
+	if pc := c.Policies.Query; pc != nil {
+		policy := *clnt.GetDefaultQueryPolicy()
+		pc.applyTo(&policy.BasePolicy)
+		setIfNotNil(&policy.RecordsPerSecond, pc.RecordsPerSecond)
+		clnt.SetDefaultQueryPolicy(&policy)
+	}
+}
+
+func (pc *PolicyConfig) applyTo(policy *BasePolicy) {
+	setIfNotNil(&policy.TotalTimeout, pc.TotalTimeout)
+	setIfNotNil(&policy.SocketTimeout, pc.SocketTimeout)
+	setIfNotNil(&policy.MaxRetries, pc.MaxRetries)
+	setIfNotNil(&policy.SleepBetweenRetries, pc.SleepBetweenRetries)
+	setIfNotNil(&policy.UseCompression, pc.UseCompression)
+	setIfNotNil(&policy.SendKey, pc.SendKey)
+	setIfNotNil(&policy.ReadTouchTTLPercent, pc.ReadTouchTTLPercent)
+}
+
+func setIfNotNil[T any](dst *T, v *T) {
+	if v != nil {
+		*dst = *v
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Config", func() {
+
+	gg.Context("Parsing", func() {
+
+		gg.It("must parse a YAML config", func() {
+			os.Setenv("AS_TEST_CONFIG_PASSWORD", "secret")
+			defer os.Unsetenv("AS_TEST_CONFIG_PASSWORD")
+
+			c, err := as.ParseConfig([]byte(`
+hosts: ["127.0.0.1:3000", "127.0.0.2:3100"]
+user: app
+password: ${AS_TEST_CONFIG_PASSWORD}
+auth_mode: external
+timeout: 5s
+connection_queue_size: 64
+rack_ids: [1, 2]
+tls:
+  server_name: cluster1
+policies:
+  read:
+    total_timeout: 50ms
+    max_retries: 3
+    read_touch_ttl_percent: 80
+  scan:
+    records_per_second: 5000
+`))
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+
+			hosts, err := c.SeedHosts()
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(hosts).To(gm.HaveLen(2))
+			gm.Expect(hosts[1].Port).To(gm.Equal(3100))
+			gm.Expect(hosts[1].TLSName).To(gm.Equal("cluster1"))
+
+			policy, err := c.ClientPolicy()
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(policy.Password).To(gm.Equal("secret"))
+			gm.Expect(policy.AuthMode).To(gm.Equal(as.AuthModeExternal))
+			gm.Expect(policy.Timeout).To(gm.Equal(5 * time.Second))
+			gm.Expect(policy.ConnectionQueueSize).To(gm.Equal(64))
+			gm.Expect(policy.RackIds).To(gm.Equal([]int{1, 2}))
+			gm.Expect(policy.TlsConfig).ToNot(gm.BeNil())
+
+			// unset fields keep their defaults
+			gm.Expect(policy.TendInterval).To(gm.Equal(time.Second))
+
+			gm.Expect(*c.Policies.Read.TotalTimeout).To(gm.Equal(50 * time.Millisecond))
+			gm.Expect(*c.Policies.Read.MaxRetries).To(gm.Equal(3))
+			gm.Expect(*c.Policies.Read.ReadTouchTTLPercent).To(gm.Equal(int32(80)))
+			gm.Expect(*c.Policies.Scan.RecordsPerSecond).To(gm.Equal(5000))
+			gm.Expect(c.Policies.Write).To(gm.BeNil())
+		})
+
+		gg.It("must parse a JSON config", func() {
+			c, err := as.ParseConfig([]byte(`{"hosts": ["127.0.0.1:3000"], "policies": {"write": {"send_key": true}}}`))
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(c.Hosts).To(gm.Equal([]string{"127.0.0.1:3000"}))
+			gm.Expect(*c.Policies.Write.SendKey).To(gm.BeTrue())
+		})
+
+		gg.It("must reject invalid configs", func() {
+			_, err := as.ParseConfig([]byte("unknown_field: 1"))
+			gm.Expect(err).To(gm.HaveOccurred())
+
+			c, err := as.ParseConfig([]byte("auth_mode: magic"))
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			_, err = c.ClientPolicy()
+			gm.Expect(err).To(gm.HaveOccurred())
+		})
+	})
+
+	gg.Context("Client", func() {
+
+		var cclient *as.Client
+
+		gg.BeforeEach(func() {
+			if *proxy {
+				gg.Skip("Not supported in Proxy Client")
+			}
+
+			cp := *clientPolicy
+
+			dbHost := as.NewHost(*host, *port)
+			dbHost.TLSName = *nodeTLSName
+
+			var err error
+			cclient, err = as.NewClientWithPolicyAndHost(&cp, dbHost)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+		})
+
+		gg.AfterEach(func() {
+			if cclient != nil {
+				cclient.Close()
+			}
+		})
+
+		gg.It("must apply the policies to the client", func() {
+			c, err := as.ParseConfig([]byte(`
+policies:
+  read:
+    total_timeout: 75ms
+  batch:
+    send_key: true
+  query:
+    records_per_second: 100
+`))
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+
+			writePolicy := cclient.GetDefaultWritePolicy()
+			c.Apply(cclient)
+
+			gm.Expect(cclient.GetDefaultPolicy().TotalTimeout).To(gm.Equal(75 * time.Millisecond))
+			gm.Expect(cclient.GetDefaultBatchPolicy().SendKey).To(gm.BeTrue())
+			gm.Expect(cclient.GetDefaultBatchWritePolicy().SendKey).To(gm.BeTrue())
+			gm.Expect(cclient.GetDefaultQueryPolicy().RecordsPerSecond).To(gm.Equal(100))
+			gm.Expect(cclient.GetDefaultWritePolicy()).To(gm.BeIdenticalTo(writePolicy))
+		})
+
+		gg.It("must reload the config file when it changes", func() {
+			path := filepath.Join(gg.GinkgoT().TempDir(), "aerospike.yaml")
+			gm.Expect(os.WriteFile(path, []byte("policies: {read: {max_retries: 1}}"), 0o600)).To(gm.Succeed())
+
+			cw, err := as.WatchConfig(cclient, path, 10*time.Millisecond, nil)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			defer cw.Close()
+
+			gm.Expect(os.WriteFile(path, []byte("policies: {read: {max_retries: 7}}"), 0o600)).To(gm.Succeed())
+			// make sure the change is visible even on file systems with a coarse modification time
+			gm.Expect(os.Chtimes(path, time.Now(), time.Now().Add(time.Second))).To(gm.Succeed())
+
+			gm.Eventually(func() int {
+				return cclient.GetDefaultPolicy().MaxRetries
+			}).Should(gm.Equal(7))
+		})
+	})
+})
@@ -0,0 +1,129 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v7/logger"
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// ConfigWatcher polls a config file, and applies the policies of the config to a client
+// every time the file changes. See Config.Apply for the fields that are applied.
+type ConfigWatcher struct {
+	clnt     ClientIfc
+	path     string
+	interval time.Duration
+	onError  func(Error)
+
+	modTime time.Time
+	size    int64
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// WatchConfig starts watching the config file, and applies it to the client when it changes.
+// The file is checked every interval. The file is not applied until it changes for the first time;
+// use LoadConfig and Config.Apply to apply the current file.
+// Errors reading or parsing the file are passed to onError if set, or logged otherwise,
+// and the previous policies remain in use.
+// The watcher stops when Close is called or the client is closed.
+func WatchConfig(clnt ClientIfc, path string, interval time.Duration, onError func(Error)) (*ConfigWatcher, Error) {
+	if interval <= 0 {
+		return nil, newError(types.PARAMETER_ERROR, "config watch interval must be greater than zero")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, newErrorAndWrap(err, types.PARAMETER_ERROR, fmt.Sprintf("error reading config file %s: %s", path, err))
+	}
+
+	cw := &ConfigWatcher{
+		clnt:     clnt,
+		path:     path,
+		interval: interval,
+		onError:  onError,
+		modTime:  info.ModTime(),
+		size:     info.Size(),
+		done:     make(chan struct{}),
+	}
+
+	cw.wg.Add(1)
+	go cw.watch()
+
+	return cw, nil
+}
+
+// Close stops the watcher and waits for a running reload to finish.
+func (cw *ConfigWatcher) Close() {
+	cw.closeOnce.Do(func() {
+		close(cw.done)
+	})
+	cw.wg.Wait()
+}
+
+func (cw *ConfigWatcher) watch() {
+	defer cw.wg.Done()
+
+	ticker := time.NewTicker(cw.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-cw.done:
+			return
+		case <-ticker.C:
+			if !cw.clnt.IsConnected() {
+				return
+			}
+
+			if err := cw.reloadIfChanged(); err != nil {
+				if cw.onError != nil {
+					cw.onError(err)
+				} else {
+					logger.Logger.Warn("Failed to reload config file %s: %s", cw.path, err.Error())
+				}
+			}
+		}
+	}
+}
+
+// reloadIfChanged applies the config file if its modification time or size changed since the last check.
+func (cw *ConfigWatcher) reloadIfChanged() Error {
+	info, err := os.Stat(cw.path)
+	if err != nil {
+		return newErrorAndWrap(err, types.PARAMETER_ERROR, fmt.Sprintf("error reading config file %s: %s", cw.path, err))
+	}
+
+	if info.ModTime().Equal(cw.modTime) && info.Size() == cw.size {
+		return nil
+	}
+	cw.modTime, cw.size = info.ModTime(), info.Size()
+
+	c, aerr := LoadConfig(cw.path)
+	if aerr != nil {
+		return aerr
+	}
+
+	c.Apply(cw.clnt)
+	logger.Logger.Info("Applied the policies of config file %s", cw.path)
+	return nil
+}
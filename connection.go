@@ -16,8 +16,8 @@ package aerospike
 
 import (
 	"bytes"
-	"compress/zlib"
 	"crypto/tls"
+	"errors"
 	"io"
 	"net"
 	"runtime"
@@ -67,10 +67,16 @@ type Connection struct {
 	socketTimeout time.Duration
 	deadline      time.Time
 
+	// the source of time of the deadlines, nil for the system clock
+	clock Clock
+
 	// duration after which connection is considered idle
 	idleTimeout  time.Duration
 	idleDeadline time.Time
 
+	// last time the connection was probed by the health check
+	lastProbe time.Time
+
 	// connection object
 	conn net.Conn
 
@@ -93,8 +99,18 @@ type Connection struct {
 	// LimitReader is used to avoid that problem.
 	limitReader *io.LimitedReader
 
+	// capture records the bytes read for a sampled command if the wire capture is enabled
+	capture *wireCaptureRecorder
+
+	// faults are the pending faults injected by ClientPolicy.FaultInjector
+	faults connectionFaults
+
 	closer sync.Once
 
+	// multiplexed connections are virtual connections of a single command
+	// on the shared sockets of the node; see ClientPolicy.MultiplexedConnectionsPerNode
+	multiplexed bool
+
 	grpcConn         bool
 	grpcReadCallback func() ([]byte, Error)
 	grpcReader       io.ReadWriter
@@ -190,6 +206,7 @@ func NewConnection(policy *ClientPolicy, host *Host) (*Connection, Error) {
 	if err != nil {
 		return nil, err
 	}
+	conn.clock = policy.Clock
 
 	if policy.TlsConfig == nil {
 		return conn, nil
@@ -265,6 +282,11 @@ func (ctn *Connection) Read(buf []byte, length int) (total int, aerr Error) {
 
 	var err error
 
+	if ctn.faults.delay > 0 {
+		time.Sleep(ctn.faults.delay)
+		ctn.faults.delay = 0
+	}
+
 	// if all bytes are not read, retry until successful
 	// Don't worry about the loop; we've already set the timeout elsewhere
 	for total < length {
@@ -288,6 +310,13 @@ func (ctn *Connection) Read(buf []byte, length int) (total int, aerr Error) {
 		}
 	}
 
+	if ctn.faults.corrupt && total > 0 {
+		buf[0] ^= 0xff
+		ctn.faults.corrupt = false
+	}
+
+	ctn.capture.read(buf[:total])
+
 	if total == length {
 		// If all required bytes are read, ignore any potential error.
 		// The error will bubble up on the next network io if it matters.
@@ -378,7 +407,7 @@ func (ctn *Connection) IsConnected() bool {
 // this function is called before each read and write operation. If deadline has passed,
 // the function will return a TIMEOUT error.
 func (ctn *Connection) updateDeadline() Error {
-	now := time.Now()
+	now := ctn.now()
 	var socketDeadline time.Time
 	if ctn.deadline.IsZero() {
 		if ctn.socketTimeout > 0 {
@@ -405,6 +434,11 @@ func (ctn *Connection) updateDeadline() Error {
 		}
 	}
 
+	// the socket deadline is in real time
+	if ctn.clock != nil && !socketDeadline.IsZero() {
+		socketDeadline = time.Now().Add(socketDeadline.Sub(now))
+	}
+
 	if err := ctn.conn.SetDeadline(socketDeadline); err != nil {
 		if ctn.node != nil {
 			ctn.node.stats.ConnectionsFailed.IncrementAndGet()
@@ -427,13 +461,16 @@ func (ctn *Connection) SetTimeout(deadline time.Time, socketTimeout time.Duratio
 func (ctn *Connection) Close() {
 	ctn.closer.Do(func() {
 		if ctn != nil && ctn.conn != nil {
-			// deregister
-			if ctn.node != nil {
+			// deregister; virtual connections are not counted
+			if ctn.node != nil && !ctn.multiplexed {
 				ctn.node.connectionCount.DecrementAndGet()
 				ctn.node.stats.ConnectionsClosed.IncrementAndGet()
+
+				// a new connection can be opened in place of this one
+				ctn.node.connWaiters.handOff(nil)
 			}
 
-			if err := ctn.conn.Close(); err != nil {
+			if err := ctn.conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
 				logger.Logger.Warn(err.Error())
 			}
 			ctn.conn = nil
@@ -448,17 +485,38 @@ func (ctn *Connection) Close() {
 	})
 }
 
+// interrupt closes the underlying socket to unblock the pending reads and writes
+// from another goroutine. The connection will not be usable afterwards,
+// and must be closed by its owner via Close.
+// The shared socket of a multiplexed connection stays open; only its stream is interrupted.
+func (ctn *Connection) interrupt() {
+	switch conn := ctn.conn.(type) {
+	case nil:
+	case *muxStream:
+		conn.interrupt()
+	default:
+		conn.Close()
+	}
+}
+
 // Login will send authentication information to the server.
-func (ctn *Connection) login(policy *ClientPolicy, hashedPassword []byte, sessionInfo *sessionInfo) Error {
+// The login with the credentials is only sent if there is no valid session token,
+// or the server rejects the token.
+func (ctn *Connection) login(policy *ClientPolicy, login func(command *loginCommand) Error, sessionInfo *sessionInfo) Error {
 	// need to authenticate
 	if policy.RequiresAuthentication() {
 		var err Error
 		command := newLoginCommand(ctn.dataBuffer)
 
+		loginWithCredentials := func() Error {
+			command = newLoginCommand(ctn.dataBuffer)
+			return login(command)
+		}
+
 		if !sessionInfo.isValid() {
-			err = command.login(policy, ctn, hashedPassword)
+			err = loginWithCredentials()
 		} else {
-			err = command.authenticateViaToken(policy, ctn, sessionInfo.token)
+			err = command.authenticateViaToken(policy, ctn, sessionInfo.user, sessionInfo.token)
 			if err != nil && err.Matches(types.INVALID_CREDENTIAL, types.EXPIRED_SESSION) {
 				// invalidate the token
 				if ctn.node != nil {
@@ -466,10 +524,7 @@ func (ctn *Connection) login(policy *ClientPolicy, hashedPassword []byte, sessio
 				}
 
 				// retry via user/pass
-				if hashedPassword != nil {
-					command = newLoginCommand(ctn.dataBuffer)
-					err = command.login(policy, ctn, hashedPassword)
-				}
+				err = loginWithCredentials()
 			}
 		}
 
@@ -499,12 +554,12 @@ func (ctn *Connection) Login(policy *ClientPolicy) Error {
 		return nil
 	}
 
-	hashedPassword, err := hashPassword(policy.Password)
+	creds, err := newAuthCredentials(policy.User, policy.Password)
 	if err != nil {
 		return err
 	}
 
-	return ctn.login(policy, hashedPassword, nil)
+	return ctn.login(policy, func(command *loginCommand) Error { return command.login(policy, ctn, creds) }, nil)
 }
 
 // RequestInfo gets info values by name from the specified connection.
@@ -525,7 +580,15 @@ func (ctn *Connection) setIdleTimeout(timeout time.Duration) {
 
 // isIdle returns true if the connection has reached the idle deadline.
 func (ctn *Connection) isIdle() bool {
-	return ctn.idleTimeout > 0 && time.Now().After(ctn.idleDeadline)
+	return ctn.idleTimeout > 0 && ctn.now().After(ctn.idleDeadline)
+}
+
+// now returns the current time of the clock of the connection.
+func (ctn *Connection) now() time.Time {
+	if ctn.clock != nil {
+		return ctn.clock.Now()
+	}
+	return time.Now()
 }
 
 func selectWithinRange[T int | uint | int64 | uint64](min, val, max T) T {
@@ -540,7 +603,7 @@ func selectWithinRange[T int | uint | int64 | uint64](min, val, max T) T {
 // refresh extends the idle deadline of the connection.
 func (ctn *Connection) refresh() {
 	now := time.Now()
-	ctn.idleDeadline = now.Add(ctn.idleTimeout)
+	ctn.idleDeadline = ctn.now().Add(ctn.idleTimeout)
 	if ctn.inflater != nil {
 		ctn.inflater.Close()
 	}
@@ -566,13 +629,13 @@ func (ctn *Connection) refresh() {
 	}
 }
 
-// initInflater sets up the zlib inflater to read compressed data from the connection
+// initInflater sets up the inflater to read compressed data from the connection
 func (ctn *Connection) initInflater(enabled bool, length int) Error {
 	ctn.compressed = enabled
 	ctn.inflater = nil
 	if ctn.compressed {
 		ctn.limitReader.N = int64(length)
-		r, err := zlib.NewReader(ctn.limitReader)
+		r, err := decompressorFor(ctn.limitReader)
 		if err != nil {
 			return newCommonError(err)
 		}
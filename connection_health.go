@@ -0,0 +1,100 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"runtime/debug"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v7/logger"
+)
+
+// timeout for the info command used to probe idle connections
+const _CONNECTION_PROBE_TIMEOUT = time.Second
+
+// connectionHealthCheck probes the idle connections in the node pools on intervals.
+// It runs in its own goroutine so that slow probes do not delay the cluster tend.
+func (clstr *Cluster) connectionHealthCheck(interval time.Duration) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Logger.Error("Connection health check goroutine crashed: %s", debug.Stack())
+			go clstr.connectionHealthCheck(interval)
+			return
+		}
+		clstr.wgTend.Done()
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-clstr.tendChannel:
+			return
+		case <-ticker.C:
+			for _, node := range clstr.GetNodes() {
+				if node.IsActive() {
+					node.probeIdleConnections(interval)
+				}
+			}
+		}
+	}
+}
+
+// needsProbe returns true if the connection has neither been used nor probed since the cutoff.
+func (ctn *Connection) needsProbe(cutoff time.Time) bool {
+	lastUsed := ctn.idleDeadline.Add(-ctn.idleTimeout)
+	return lastUsed.Before(cutoff) && ctn.lastProbe.Before(cutoff)
+}
+
+// probeIdleConnections checks the connections in the pool that have not been used or probed
+// during the interval, starting from the least recently used, and closes the dead ones.
+func (nd *Node) probeIdleConnections(interval time.Duration) {
+	cutoff := time.Now().Add(-interval)
+	needsProbe := func(conn *Connection) bool { return conn.needsProbe(cutoff) }
+
+	// each connection is checked at most once per run
+	for i := nd.connections.LenAll(); i > 0; i-- {
+		conn, hint := nd.connections.PollTailIf(needsProbe)
+		if conn == nil {
+			return
+		}
+
+		nd.stats.ConnectionsProbed.IncrementAndGet()
+		if err := nd.probeConnection(conn); err != nil {
+			logger.Logger.Debug("Idle connection to node %s failed the health check and will be closed: %s", nd.String(), err.Error())
+			nd.stats.ConnectionsProbeFailed.IncrementAndGet()
+			conn.Close()
+			continue
+		}
+
+		// the connection is not refreshed, so that it is still dropped when it reaches the idle timeout
+		if !nd.active.Get() || !nd.connections.Offer(conn, hint) {
+			nd.stats.ConnectionsPoolOverflow.IncrementAndGet()
+			conn.Close()
+		}
+	}
+}
+
+// probeConnection sends a lightweight info command on the connection.
+func (nd *Node) probeConnection(conn *Connection) Error {
+	conn.lastProbe = time.Now()
+	if err := conn.SetTimeout(conn.lastProbe.Add(_CONNECTION_PROBE_TIMEOUT), _CONNECTION_PROBE_TIMEOUT); err != nil {
+		return err
+	}
+
+	_, err := conn.RequestInfo("node")
+	return err
+}
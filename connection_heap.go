@@ -17,8 +17,21 @@ package aerospike
 import (
 	"runtime"
 	"sync"
+
+	iatomic "github.com/aerospike/aerospike-client-go/v7/internal/atomic"
 )
 
+// connectionQueue is a bounded pool of connections, used as a shard of the connectionHeap.
+type connectionQueue interface {
+	Offer(conn *Connection) bool
+	Poll() *Connection
+	DropIdleTail() bool
+	PollTailIf(pred func(*Connection) bool) *Connection
+	Len() int
+	Cap() int
+	cleanup()
+}
+
 // singleConnectionHeap is a non-blocking LIFO heap.
 // If the heap is empty, nil is returned.
 // if the heap is full, offer will return false
@@ -142,6 +155,34 @@ func (h *singleConnectionHeap) DropIdleTail() bool {
 	return false
 }
 
+// PollTailIf removes and returns the connection in the tail of the heap
+// if it satisfies the predicate. Otherwise nil will be returned.
+func (h *singleConnectionHeap) PollTailIf(pred func(*Connection) bool) *Connection {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	// the heap has been cleaned up
+	if h.data == nil {
+		return nil
+	}
+
+	// if heap is not empty
+	if h.full || (h.tail != h.head) {
+		idx := (h.tail + 1) % h.size
+		conn := h.data[idx]
+		if !pred(conn) {
+			return nil
+		}
+
+		h.tail = idx
+		h.data[idx] = nil
+		h.full = false
+		return conn
+	}
+
+	return nil
+}
+
 // Len returns the number of connections in the heap
 func (h *singleConnectionHeap) Len() int {
 	cnt := 0
@@ -160,13 +201,42 @@ func (h *singleConnectionHeap) Len() int {
 	return cnt
 }
 
-// connectionHeap is a non-blocking FIFO heap.
+// connectionHeapShard is one of the independent sub-heaps of a connectionHeap.
+type connectionHeapShard struct {
+	connectionQueue
+
+	// number of connections polled from this shard by the commands assigned to it
+	polls iatomic.Int
+	// number of connections the commands assigned to this shard took from the other shards
+	steals iatomic.Int
+	// number of connections the commands assigned to this shard offered to the other shards
+	// because the shard was full
+	spills iatomic.Int
+
+	// keeps the mutexes of neighbouring shards on different cache lines
+	_ [64]byte
+}
+
+// Cap returns the capacity of the heap
+func (h *singleConnectionHeap) Cap() int {
+	return int(h.size)
+}
+
+// connectionHeap is a non-blocking FIFO heap, sharded to reduce the contention
+// on the mutexes of the sub-heaps.
+// Commands are assigned to the shard of the processor they run on, and take
+// connections from the other shards when theirs is exhausted.
 // If the heap is empty, nil is returned.
 // if the heap is full, offer will return false
 type connectionHeap struct {
 	maxSize int
 	minSize int
-	heaps   []singleConnectionHeap
+	heaps   []connectionHeapShard
+
+	// tokens holds the shard indexes. sync.Pool keeps its items in a per-processor
+	// cache, so the goroutines running on the same processor mostly get the same shard.
+	tokens    *sync.Pool
+	nextToken iatomic.Int
 }
 
 // Close cleans up all the data and removes all the references from
@@ -177,46 +247,88 @@ func (h *connectionHeap) cleanup() {
 	}
 }
 
-func newConnectionHeap(minSize, maxSize int) *connectionHeap {
+// newConnectionHeap creates a heap of maxSize connections split into the given number of shards.
+// If shards is not positive, a shard per CPU is used. There are never more shards than
+// connections, or 256 shards.
+// If lockFree is set, the shards are lock-free rings instead of mutex protected LIFO heaps.
+func newConnectionHeap(minSize, maxSize, shards int, lockFree bool) *connectionHeap {
 	if minSize > maxSize {
 		panic("minSize is bigger than maxSize for connection heap")
 	}
 
-	heapCount := runtime.NumCPU()
+	heapCount := shards
+	if heapCount <= 0 {
+		heapCount = runtime.NumCPU()
+	}
 	if heapCount > maxSize {
 		heapCount = maxSize
 	}
+	if heapCount > 256 {
+		heapCount = 256
+	}
 
 	// will be >= 1
 	perHeapSize := maxSize / heapCount
 
-	heaps := make([]singleConnectionHeap, heapCount)
-	for i := range heaps {
-		heaps[i] = *newSingleConnectionHeap(perHeapSize)
-	}
-
-	// add a heap for the remainder
+	// the remainder is spread over the first shards
 	remainder := maxSize - heapCount*perHeapSize
-	if remainder > 0 {
-		heaps = append(heaps, *newSingleConnectionHeap(remainder))
+
+	heaps := make([]connectionHeapShard, heapCount)
+	for i := range heaps {
+		size := perHeapSize
+		if i < remainder {
+			size++
+		}
+		if lockFree {
+			heaps[i].connectionQueue = newConnectionRing(size)
+		} else {
+			heaps[i].connectionQueue = newSingleConnectionHeap(size)
+		}
 	}
 
-	return &connectionHeap{
+	h := &connectionHeap{
 		maxSize: maxSize,
 		minSize: minSize,
 		heaps:   heaps,
 	}
+
+	h.tokens = &sync.Pool{
+		New: func() interface{} {
+			idx := byte(h.nextToken.GetAndIncrement() % len(heaps))
+			return &idx
+		},
+	}
+
+	return h
+}
+
+// shard returns the index of the shard assigned to the processor the calling goroutine runs on.
+func (h *connectionHeap) shard() byte {
+	if len(h.heaps) == 1 {
+		return 0
+	}
+
+	token := h.tokens.Get().(*byte)
+	idx := *token
+	h.tokens.Put(token)
+	return idx
 }
 
 // Offer adds an item to the heap unless the heap is full.
+// The connection is added to the shard selected by the hint, or to the next
+// shards if it is full.
 // In case the heap is full, the item will not be added to the heap
 // and false will be returned
 func (h *connectionHeap) Offer(conn *Connection, hint byte) bool {
 	idx := int(hint) % len(h.heaps)
+	if h.heaps[idx].Offer(conn) {
+		return true
+	}
+
 	end := idx + len(h.heaps)
-	for i := idx; i < end; i++ {
+	for i := idx + 1; i < end; i++ {
 		if h.heaps[i%len(h.heaps)].Offer(conn) {
-			// success
+			h.heaps[idx].spills.IncrementAndGet()
 			return true
 		}
 	}
@@ -224,13 +336,20 @@ func (h *connectionHeap) Offer(conn *Connection, hint byte) bool {
 }
 
 // Poll removes and returns an item from the heap.
+// The connection is taken from the shard selected by the hint, or stolen from
+// the next shards if it is empty.
 // If the heap is empty, nil will be returned.
 func (h *connectionHeap) Poll(hint byte) (res *Connection) {
-	idx := int(hint)
+	idx := int(hint) % len(h.heaps)
+	if conn := h.heaps[idx].Poll(); conn != nil {
+		h.heaps[idx].polls.IncrementAndGet()
+		return conn
+	}
 
 	end := idx + len(h.heaps)
-	for i := idx; i < end; i++ {
+	for i := idx + 1; i < end; i++ {
 		if conn := h.heaps[i%len(h.heaps)].Poll(); conn != nil {
+			h.heaps[idx].steals.IncrementAndGet()
 			return conn
 		}
 	}
@@ -259,6 +378,18 @@ func (h *connectionHeap) DropIdle() {
 	}
 }
 
+// PollTailIf removes and returns the least recently used connection of the first
+// sub-heap whose tail satisfies the predicate, along with the hint of the sub-heap.
+// If no such connection exists, nil will be returned.
+func (h *connectionHeap) PollTailIf(pred func(*Connection) bool) (*Connection, byte) {
+	for i := range h.heaps {
+		if conn := h.heaps[i].PollTailIf(pred); conn != nil {
+			return conn, byte(i)
+		}
+	}
+	return nil, 0
+}
+
 // Cap returns the total capacity of the connectionHeap
 func (h *connectionHeap) Cap() int {
 	return h.maxSize
@@ -266,7 +397,7 @@ func (h *connectionHeap) Cap() int {
 
 // Len returns the number of connections in a specific sub-heap.
 func (h *connectionHeap) Len(hint byte) (cnt int) {
-	return h.heaps[hint].Len()
+	return h.heaps[int(hint)%len(h.heaps)].Len()
 }
 
 // LenAll returns the number of connections in all sub-heaps.
@@ -278,3 +409,19 @@ func (h *connectionHeap) LenAll() int {
 
 	return cnt
 }
+
+// shardStats returns the size, capacity and usage counters of each shard.
+func (h *connectionHeap) shardStats() []map[string]interface{} {
+	res := make([]map[string]interface{}, len(h.heaps))
+	for i := range h.heaps {
+		shard := &h.heaps[i]
+		res[i] = map[string]interface{}{
+			"connections": shard.Len(),
+			"capacity":    shard.Cap(),
+			"polls":       shard.polls.Get(),
+			"steals":      shard.steals.Get(),
+			"spills":      shard.spills.Get(),
+		}
+	}
+	return res
+}
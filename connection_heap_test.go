@@ -15,6 +15,10 @@
 package aerospike
 
 import (
+	"net"
+	"sync"
+	"time"
+
 	gg "github.com/onsi/ginkgo/v2"
 	gm "github.com/onsi/gomega"
 )
@@ -85,5 +89,215 @@ var _ = gg.Describe("Connection Heap tests", func() {
 
 		})
 
+		gg.It("Must PollTailIf the least recently offered connection", func() {
+			h := newSingleConnectionHeap(3)
+			conn1, conn2 := new(Connection), new(Connection)
+			gm.Expect(h.Offer(conn1)).To(gm.BeTrue())
+			gm.Expect(h.Offer(conn2)).To(gm.BeTrue())
+
+			gm.Expect(h.PollTailIf(func(*Connection) bool { return false })).To(gm.BeNil())
+			gm.Expect(h.Len()).To(gm.Equal(2))
+
+			always := func(*Connection) bool { return true }
+			gm.Expect(h.PollTailIf(always)).To(gm.BeIdenticalTo(conn1))
+			gm.Expect(h.Len()).To(gm.Equal(1))
+			gm.Expect(h.Poll()).To(gm.BeIdenticalTo(conn2))
+			gm.Expect(h.PollTailIf(always)).To(gm.BeNil())
+			gm.Expect(h.Len()).To(gm.Equal(0))
+		})
+
+	})
+
+	gg.Context("connectionHeap", func() {
+
+		gg.It("Must split the capacity between the shards", func() {
+			h := newConnectionHeap(0, 10, 4, false)
+			gm.Expect(len(h.heaps)).To(gm.Equal(4))
+			gm.Expect(h.Cap()).To(gm.Equal(10))
+
+			total := 0
+			for i := range h.heaps {
+				gm.Expect(h.heaps[i].Cap()).To(gm.BeNumerically(">=", 2))
+				gm.Expect(h.heaps[i].Cap()).To(gm.BeNumerically("<=", 3))
+				total += h.heaps[i].Cap()
+			}
+			gm.Expect(total).To(gm.Equal(10))
+
+			gm.Expect(len(newConnectionHeap(0, 3, 8, false).heaps)).To(gm.Equal(3))
+			gm.Expect(len(newConnectionHeap(0, 1000, 1000, false).heaps)).To(gm.Equal(256))
+			gm.Expect(len(newConnectionHeap(0, 10, 0, false).heaps)).To(gm.BeNumerically(">=", 1))
+		})
+
+		gg.It("Must steal connections from the other shards when a shard is exhausted", func() {
+			h := newConnectionHeap(0, 4, 2, false)
+			conn1, conn2 := new(Connection), new(Connection)
+			gm.Expect(h.Offer(conn1, 1)).To(gm.BeTrue())
+			gm.Expect(h.Offer(conn2, 1)).To(gm.BeTrue())
+			gm.Expect(h.Len(0)).To(gm.Equal(0))
+			gm.Expect(h.Len(1)).To(gm.Equal(2))
+
+			gm.Expect(h.Poll(1)).To(gm.BeIdenticalTo(conn2))
+			gm.Expect(h.Poll(0)).To(gm.BeIdenticalTo(conn1))
+			gm.Expect(h.Poll(0)).To(gm.BeNil())
+
+			stats := h.shardStats()
+			gm.Expect(stats[0]["polls"]).To(gm.Equal(0))
+			gm.Expect(stats[0]["steals"]).To(gm.Equal(1))
+			gm.Expect(stats[1]["polls"]).To(gm.Equal(1))
+			gm.Expect(stats[1]["steals"]).To(gm.Equal(0))
+		})
+
+		gg.It("Must spill connections to the other shards when a shard is full", func() {
+			h := newConnectionHeap(0, 4, 2, false)
+			for i := 0; i < 4; i++ {
+				gm.Expect(h.Offer(new(Connection), 0)).To(gm.BeTrue())
+			}
+			gm.Expect(h.Offer(new(Connection), 0)).To(gm.BeFalse())
+			gm.Expect(h.Len(0)).To(gm.Equal(2))
+			gm.Expect(h.Len(1)).To(gm.Equal(2))
+			gm.Expect(h.LenAll()).To(gm.Equal(4))
+
+			stats := h.shardStats()
+			gm.Expect(stats[0]["spills"]).To(gm.Equal(2))
+			gm.Expect(stats[0]["connections"]).To(gm.Equal(2))
+			gm.Expect(stats[0]["capacity"]).To(gm.Equal(2))
+		})
+
+		gg.It("Must assign valid shards to the goroutines", func() {
+			h := newConnectionHeap(0, 100, 8, false)
+			for i := 0; i < 100; i++ {
+				gm.Expect(int(h.shard())).To(gm.BeNumerically("<", 8))
+			}
+			gm.Expect(newConnectionHeap(0, 100, 1, false).shard()).To(gm.Equal(byte(0)))
+		})
+
+		gg.It("Must use lock-free rings as shards", func() {
+			h := newConnectionHeap(0, 4, 2, true)
+			gm.Expect(h.heaps[0].connectionQueue).To(gm.BeAssignableToTypeOf(&connectionRing{}))
+
+			conn1, conn2 := new(Connection), new(Connection)
+			gm.Expect(h.Offer(conn1, 1)).To(gm.BeTrue())
+			gm.Expect(h.Offer(conn2, 1)).To(gm.BeTrue())
+			gm.Expect(h.LenAll()).To(gm.Equal(2))
+
+			// rings are LIFO
+			gm.Expect(h.Poll(1)).To(gm.BeIdenticalTo(conn2))
+			gm.Expect(h.Poll(0)).To(gm.BeIdenticalTo(conn1))
+			gm.Expect(h.Poll(1)).To(gm.BeNil())
+		})
+
+	})
+
+	gg.Context("connectionRing", func() {
+
+		gg.It("Must add until full, then Poll in reverse order", func() {
+			r := newConnectionRing(3)
+			conns := []*Connection{new(Connection), new(Connection), new(Connection)}
+			for i := range conns {
+				gm.Expect(r.Len()).To(gm.Equal(i))
+				gm.Expect(r.Offer(conns[i])).To(gm.BeTrue())
+			}
+			gm.Expect(r.Offer(conn)).To(gm.BeFalse())
+			gm.Expect(r.Len()).To(gm.Equal(3))
+			gm.Expect(r.Cap()).To(gm.Equal(3))
+
+			for i := len(conns) - 1; i >= 0; i-- {
+				gm.Expect(r.Poll()).To(gm.BeIdenticalTo(conns[i]))
+			}
+			gm.Expect(r.Poll()).To(gm.BeNil())
+			gm.Expect(r.Len()).To(gm.Equal(0))
+
+			// wrap around
+			for i := 0; i < 10; i++ {
+				gm.Expect(r.Offer(conns[i%3])).To(gm.BeTrue())
+				gm.Expect(r.Poll()).To(gm.BeIdenticalTo(conns[i%3]))
+			}
+		})
+
+		gg.It("Must PollTailIf the least recently offered connection", func() {
+			r := newConnectionRing(3)
+			conn1, conn2, conn3 := new(Connection), new(Connection), new(Connection)
+			gm.Expect(r.Offer(conn1)).To(gm.BeTrue())
+			gm.Expect(r.Offer(conn2)).To(gm.BeTrue())
+
+			gm.Expect(r.PollTailIf(func(*Connection) bool { return false })).To(gm.BeNil())
+			gm.Expect(r.Len()).To(gm.Equal(2))
+
+			// a connection that did not satisfy the predicate keeps its place
+			gm.Expect(r.Offer(conn3)).To(gm.BeTrue())
+			gm.Expect(r.Poll()).To(gm.BeIdenticalTo(conn3))
+
+			always := func(*Connection) bool { return true }
+			gm.Expect(r.PollTailIf(always)).To(gm.BeIdenticalTo(conn1))
+			gm.Expect(r.Len()).To(gm.Equal(1))
+			gm.Expect(r.Poll()).To(gm.BeIdenticalTo(conn2))
+			gm.Expect(r.PollTailIf(always)).To(gm.BeNil())
+		})
+
+		gg.It("Must drop the idle connections in the tail", func() {
+			r := newConnectionRing(3)
+			idle := &Connection{idleTimeout: time.Second, idleDeadline: time.Now().Add(-time.Second)}
+			client, server := net.Pipe()
+			defer client.Close()
+			defer server.Close()
+			active := &Connection{conn: client, idleTimeout: time.Second, idleDeadline: time.Now().Add(time.Hour)}
+			gm.Expect(r.Offer(idle)).To(gm.BeTrue())
+			gm.Expect(r.Offer(active)).To(gm.BeTrue())
+
+			gm.Expect(r.DropIdleTail()).To(gm.BeTrue())
+			gm.Expect(r.DropIdleTail()).To(gm.BeFalse())
+			gm.Expect(r.Len()).To(gm.Equal(1))
+			gm.Expect(r.Poll()).To(gm.BeIdenticalTo(active))
+		})
+
+		gg.It("Must reject connections after cleanup", func() {
+			r := newConnectionRing(3)
+			gm.Expect(r.Offer(new(Connection))).To(gm.BeTrue())
+			r.cleanup()
+
+			gm.Expect(r.Len()).To(gm.Equal(0))
+			gm.Expect(r.Offer(new(Connection))).To(gm.BeFalse())
+			gm.Expect(r.Poll()).To(gm.BeNil())
+		})
+
+		gg.It("Must not lose connections under concurrent use", func() {
+			const size, workers, rounds = 16, 8, 1000
+
+			r := newConnectionRing(size)
+			for i := 0; i < size; i++ {
+				gm.Expect(r.Offer(new(Connection))).To(gm.BeTrue())
+			}
+
+			var wg sync.WaitGroup
+			for w := 0; w < workers; w++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < rounds; i++ {
+						if conn := r.Poll(); conn != nil {
+							r.Offer(conn)
+						}
+					}
+				}()
+			}
+
+			// the tail is taken out and put back concurrently
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < rounds; i++ {
+					r.PollTailIf(func(*Connection) bool { return false })
+				}
+			}()
+			wg.Wait()
+
+			gm.Expect(r.Len()).To(gm.Equal(size))
+			seen := map[*Connection]struct{}{}
+			for conn := r.Poll(); conn != nil; conn = r.Poll() {
+				seen[conn] = struct{}{}
+			}
+			gm.Expect(seen).To(gm.HaveLen(size))
+		})
+
 	})
 })
@@ -0,0 +1,458 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	iatomic "github.com/aerospike/aerospike-client-go/v7/internal/atomic"
+	"github.com/aerospike/aerospike-client-go/v7/logger"
+	"github.com/aerospike/aerospike-client-go/v7/types/histogram"
+	Buffer "github.com/aerospike/aerospike-client-go/v7/utils/buffer"
+)
+
+// The wire protocol does not carry a request identifier, but the server answers the commands
+// sent on a connection in the order it received them. The multiplexer pipelines the commands of
+// many goroutines over a few sockets, and a reader goroutine per socket hands each response to
+// the command at the head of the socket's queue. The responses of the commands that gave up
+// waiting are read and discarded, so a timeout does not poison the socket.
+//
+// While commands are waiting, the socket must make progress within ClientPolicy.Timeout.
+// Otherwise it is considered half-open, and is closed with all the commands waiting on it.
+//
+// Each command gets a virtual Connection backed by a muxStream, so that the commands send and
+// parse their messages exactly as they do over a pooled connection. The streams are recycled
+// once both the command and the reader goroutine are done with them.
+
+var errMuxStreamUsed = errors.New("multiplexed stream can only send one command")
+
+// connMultiplexer holds the shared sockets of a node.
+type connMultiplexer struct {
+	node  *Node
+	pipes []muxPipe
+	next  iatomic.Int
+
+	// recycled streams, with their virtual connections
+	streams sync.Pool
+}
+
+func newConnMultiplexer(node *Node, size int) *connMultiplexer {
+	m := &connMultiplexer{
+		node:  node,
+		pipes: make([]muxPipe, size),
+	}
+	m.streams.New = func() interface{} {
+		return newMuxStream()
+	}
+	for i := range m.pipes {
+		m.pipes[i].node = node
+		m.pipes[i].timeout = node.cluster.clientPolicy.Timeout
+	}
+	return m
+}
+
+// connection returns a virtual connection for a single command.
+// The command is sent on the shared sockets in turns.
+func (m *connMultiplexer) connection(deadline time.Time, timeout time.Duration) *Connection {
+	s := m.streams.Get().(*muxStream)
+	s.pipe = &m.pipes[m.next.IncrementAndGet()%len(m.pipes)]
+	s.pool = &m.streams
+	s.refs.Set(1)
+
+	s.hist.Reset()
+	s.limitReader = io.LimitedReader{R: s, N: 0}
+	s.vconn = Connection{
+		node:        m.node,
+		conn:        s,
+		clock:       m.node.cluster.clientPolicy.Clock,
+		multiplexed: true,
+		buffHist:    s.hist,
+		dataBuffer:  buffPool.Get(DefaultBufferSize),
+		limitReader: &s.limitReader,
+	}
+
+	conn := &s.vconn
+	conn.origDataBuffer = conn.dataBuffer
+	conn.SetTimeout(deadline, timeout)
+	return conn
+}
+
+// put releases the virtual connection of a command.
+// Its stream is recycled once the response of the command is read or discarded.
+func (m *connMultiplexer) put(conn *Connection) {
+	s, _ := conn.conn.(*muxStream)
+	conn.Close()
+	if s != nil {
+		s.release()
+	}
+}
+
+// close closes the shared sockets, failing the commands waiting for their responses.
+func (m *connMultiplexer) close() {
+	for i := range m.pipes {
+		m.pipes[i].close()
+	}
+}
+
+// muxPipe is a slot of the multiplexer. It reconnects its socket when it fails.
+type muxPipe struct {
+	node *Node
+
+	// the time the socket has to make progress while commands are waiting on it; 0 to wait indefinitely
+	timeout time.Duration
+
+	// serializes the writes to the socket and the order of the pending commands
+	wl   sync.Mutex
+	sock *muxSocket
+}
+
+// send writes the command of the stream to the socket of the pipe,
+// and queues the stream to receive the response.
+func (p *muxPipe) send(s *muxStream, buf []byte) (int, error) {
+	p.wl.Lock()
+	defer p.wl.Unlock()
+
+	if p.sock == nil || p.sock.dead {
+		conn, err := p.node.newConnection(true)
+		if err != nil {
+			return 0, err
+		}
+		p.sock = newMuxSocket(p, conn)
+	}
+
+	sock := p.sock
+	s.sock = sock
+	if err := sock.nc.SetWriteDeadline(s.deadline); err != nil {
+		sock.killLocked()
+		return 0, err
+	}
+
+	// queue before writing, so that the reader always finds the stream of a response
+	s.refs.IncrementAndGet()
+	sock.queued()
+	sock.pending.Push(s)
+	n, err := sock.nc.Write(buf)
+	if err != nil {
+		// the socket is out of sync; the reader fails every command on it
+		sock.killLocked()
+		return n, err
+	}
+	return n, nil
+}
+
+func (p *muxPipe) close() {
+	p.wl.Lock()
+	defer p.wl.Unlock()
+	if p.sock != nil {
+		p.sock.killLocked()
+		p.sock = nil
+	}
+}
+
+// muxSocket is a physical connection of a pipe, with the commands waiting for their responses.
+// The commands are queued by the writers, and popped only by the reader goroutine of the socket.
+type muxSocket struct {
+	pipe    *muxPipe
+	conn    *Connection
+	nc      net.Conn
+	pending *iatomic.MPSCQueue[*muxStream]
+	timeout time.Duration
+
+	// the number of commands waiting for their responses, to roll the read deadline
+	wmutex  sync.Mutex
+	waiting int
+
+	// set under the write lock of the pipe; no command is queued afterwards
+	dead bool
+}
+
+func newMuxSocket(pipe *muxPipe, conn *Connection) *muxSocket {
+	sock := &muxSocket{
+		pipe:    pipe,
+		conn:    conn,
+		nc:      conn.conn,
+		pending: iatomic.NewMPSCQueue[*muxStream](),
+		timeout: pipe.timeout,
+	}
+
+	// the idle socket waits for the responses indefinitely
+	sock.setReadDeadline(false)
+
+	go sock.read()
+	return sock
+}
+
+// queued arms the read deadline of the socket when a command starts waiting on the idle socket.
+func (sock *muxSocket) queued() {
+	sock.wmutex.Lock()
+	defer sock.wmutex.Unlock()
+
+	sock.waiting++
+	if sock.waiting == 1 {
+		sock.setReadDeadline(true)
+	}
+}
+
+// answered rolls the read deadline of the socket forward after a response,
+// or clears it if no command is waiting anymore.
+func (sock *muxSocket) answered() {
+	sock.wmutex.Lock()
+	defer sock.wmutex.Unlock()
+
+	sock.waiting--
+	sock.setReadDeadline(sock.waiting > 0)
+}
+
+func (sock *muxSocket) setReadDeadline(waiting bool) {
+	var deadline time.Time
+	if waiting && sock.timeout > 0 {
+		deadline = time.Now().Add(sock.timeout)
+	}
+	if err := sock.nc.SetReadDeadline(deadline); err != nil {
+		logger.Logger.Debug("Failed to set the read deadline of a multiplexed connection: %s", err.Error())
+	}
+}
+
+// read hands the responses on the socket to the pending commands, in order.
+func (sock *muxSocket) read() {
+	var header [8]byte
+	for {
+		if _, err := io.ReadFull(sock.nc, header[:]); err != nil {
+			sock.fail(err)
+			return
+		}
+
+		// corrupted data streams can result in a huge length
+		size := Buffer.BytesToInt64(header[:], 0) & 0xFFFFFFFFFFFF
+		if size > int64(MaxBufferSize) {
+			sock.fail(fmt.Errorf("invalid response size on a multiplexed connection: %d", size))
+			return
+		}
+
+		msg := make([]byte, 8+size)
+		copy(msg, header[:])
+		if _, err := io.ReadFull(sock.nc, msg[8:]); err != nil {
+			sock.fail(err)
+			return
+		}
+
+		s, ok := sock.pending.Pop()
+		if !ok {
+			// a response nobody asked for; the socket is out of sync
+			sock.fail(errors.New("unexpected response on a multiplexed connection"))
+			return
+		}
+
+		// the channel is buffered, so this does not block even if the command gave up
+		s.resp <- muxResponse{msg: msg}
+		s.release()
+		sock.answered()
+	}
+}
+
+// killLocked marks the socket dead and closes it, which makes the reader fail.
+// The write lock of the pipe must be held.
+func (sock *muxSocket) killLocked() {
+	sock.dead = true
+	sock.nc.Close()
+}
+
+// fail releases the socket after a read error or a stall, and fails the commands waiting for their responses.
+func (sock *muxSocket) fail(err error) {
+	sock.pipe.wl.Lock()
+	defer sock.pipe.wl.Unlock()
+
+	sock.dead = true
+	sock.conn.Close()
+
+	sock.pending.Drain(func(s *muxStream) {
+		s.resp <- muxResponse{err: err}
+		s.release()
+	})
+}
+
+type muxResponse struct {
+	msg []byte
+	err error
+}
+
+// muxStream is the net.Conn of the virtual connection of a command.
+// It sends a single command, and reads its response.
+type muxStream struct {
+	pipe *muxPipe
+	sock *muxSocket // set when the command is sent
+
+	// the pool to recycle the stream to, and its holders: the command, and the reader once it is sent
+	pool *sync.Pool
+	refs iatomic.Int
+
+	// the virtual connection of the command, kept with the stream to be recycled
+	vconn       Connection
+	hist        *histogram.Log2
+	limitReader io.LimitedReader
+
+	deadline time.Time
+	sent     bool
+	closed   bool
+
+	resp     chan muxResponse
+	cancel   chan struct{}
+	received bool
+	buf      []byte
+	err      error
+}
+
+var _ net.Conn = &muxStream{}
+
+func newMuxStream() *muxStream {
+	return &muxStream{
+		resp:   make(chan muxResponse, 1),
+		cancel: make(chan struct{}, 1),
+		hist:   histogram.NewLog2(32),
+	}
+}
+
+// release drops a holder of the stream, and recycles the stream after the last one.
+func (s *muxStream) release() {
+	if s.refs.DecrementAndGet() > 0 || s.pool == nil {
+		return
+	}
+
+	// discard the response the command did not read, and a late interrupt
+	select {
+	case <-s.resp:
+	default:
+	}
+	select {
+	case <-s.cancel:
+	default:
+	}
+
+	pool := s.pool
+	s.pipe, s.sock, s.pool = nil, nil, nil
+	s.deadline = time.Time{}
+	s.sent, s.closed, s.received = false, false, false
+	s.buf, s.err = nil, nil
+	pool.Put(s)
+}
+
+// Write implements the net.Conn interface.
+func (s *muxStream) Write(b []byte) (int, error) {
+	if s.closed {
+		return 0, net.ErrClosed
+	}
+	if s.sent {
+		return 0, errMuxStreamUsed
+	}
+	s.sent = true
+	return s.pipe.send(s, b)
+}
+
+// Read implements the net.Conn interface.
+func (s *muxStream) Read(b []byte) (int, error) {
+	if s.closed {
+		return 0, net.ErrClosed
+	}
+
+	if !s.received {
+		if !s.sent {
+			return 0, io.EOF
+		}
+
+		var timer <-chan time.Time
+		if !s.deadline.IsZero() {
+			t := time.NewTimer(time.Until(s.deadline))
+			defer t.Stop()
+			timer = t.C
+		}
+
+		select {
+		case r := <-s.resp:
+			s.received = true
+			s.buf, s.err = r.msg, r.err
+		case <-timer:
+			return 0, os.ErrDeadlineExceeded
+		case <-s.cancel:
+			return 0, net.ErrClosed
+		}
+	}
+
+	if len(s.buf) == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+		return 0, io.EOF
+	}
+
+	n := copy(b, s.buf)
+	s.buf = s.buf[n:]
+	return n, nil
+}
+
+// interrupt unblocks the pending Read of the command from another goroutine.
+// Unlike Close, it is safe to call concurrently with Read.
+func (s *muxStream) interrupt() {
+	select {
+	case s.cancel <- struct{}{}:
+	default:
+	}
+}
+
+// Close implements the net.Conn interface. A response that arrives afterwards is discarded.
+func (s *muxStream) Close() error {
+	s.closed = true
+	s.buf = nil
+	return nil
+}
+
+// SetDeadline implements the net.Conn interface.
+func (s *muxStream) SetDeadline(t time.Time) error {
+	s.deadline = t
+	return nil
+}
+
+// SetReadDeadline implements the net.Conn interface.
+func (s *muxStream) SetReadDeadline(t time.Time) error {
+	s.deadline = t
+	return nil
+}
+
+// SetWriteDeadline implements the net.Conn interface.
+func (s *muxStream) SetWriteDeadline(t time.Time) error {
+	s.deadline = t
+	return nil
+}
+
+// LocalAddr implements the net.Conn interface.
+func (s *muxStream) LocalAddr() net.Addr {
+	if s.sock == nil {
+		return nil
+	}
+	return s.sock.nc.LocalAddr()
+}
+
+// RemoteAddr implements the net.Conn interface.
+func (s *muxStream) RemoteAddr() net.Addr {
+	if s.sock == nil {
+		return nil
+	}
+	return s.sock.nc.RemoteAddr()
+}
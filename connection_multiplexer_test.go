@@ -0,0 +1,292 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	Buffer "github.com/aerospike/aerospike-client-go/v7/utils/buffer"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+// muxFrame wraps the payload in a proto header
+func muxFrame(payload string) []byte {
+	res := make([]byte, 8+len(payload))
+	binary.BigEndian.PutUint64(res, uint64(int64(len(payload))|(_CL_MSG_VERSION<<56)|(_AS_MSG_TYPE<<48)))
+	copy(res[8:], payload)
+	return res
+}
+
+// readMuxFrame reads a framed message from the connection
+func readMuxFrame(conn net.Conn) ([]byte, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, err
+	}
+	body := make([]byte, Buffer.BytesToInt64(header, 0)&0xFFFFFFFFFFFF)
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return nil, err
+	}
+	return append(header, body...), nil
+}
+
+// newTestMuxPipe returns a pipe with a socket connected to the returned server end.
+func newTestMuxPipe(timeout time.Duration) (*muxPipe, net.Conn) {
+	client, server := net.Pipe()
+	p := &muxPipe{timeout: timeout}
+	p.sock = newMuxSocket(p, &Connection{conn: client})
+	return p, server
+}
+
+func newTestMuxStream(p *muxPipe) *muxStream {
+	return &muxStream{pipe: p, resp: make(chan muxResponse, 1)}
+}
+
+var _ = gg.Describe("Connection Multiplexer tests", func() {
+
+	gg.It("must hand the responses to the commands in the order they were sent", func() {
+		p, server := newTestMuxPipe(0)
+		defer p.close()
+
+		// echo server
+		go func() {
+			for {
+				msg, err := readMuxFrame(server)
+				if err != nil {
+					return
+				}
+				if _, err := server.Write(msg); err != nil {
+					return
+				}
+			}
+		}()
+
+		const commands = 50
+		var wg sync.WaitGroup
+		wg.Add(commands)
+		for i := 0; i < commands; i++ {
+			go func(i int) {
+				defer gg.GinkgoRecover()
+				defer wg.Done()
+
+				s := newTestMuxStream(p)
+				s.SetDeadline(time.Now().Add(5 * time.Second))
+
+				req := muxFrame(fmt.Sprintf("command %d", i))
+				_, err := s.Write(req)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				res := make([]byte, len(req))
+				_, err = io.ReadFull(s, res)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(res).To(gm.Equal(req))
+			}(i)
+		}
+		wg.Wait()
+	})
+
+	gg.It("must discard the response of a command that timed out and keep the socket", func() {
+		p, server := newTestMuxPipe(0)
+		defer p.close()
+
+		requests := make(chan []byte, 2)
+		respond := make(chan struct{})
+		go func() {
+			for i := 0; i < 2; i++ {
+				msg, err := readMuxFrame(server)
+				if err != nil {
+					return
+				}
+				requests <- msg
+			}
+			<-respond
+			for i := 0; i < 2; i++ {
+				server.Write(<-requests)
+			}
+		}()
+
+		slow := newTestMuxStream(p)
+		slow.SetDeadline(time.Now().Add(10 * time.Millisecond))
+		_, err := slow.Write(muxFrame("slow"))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		_, err = slow.Read(make([]byte, 8))
+		gm.Expect(errors.Is(err, os.ErrDeadlineExceeded)).To(gm.BeTrue())
+		slow.Close()
+
+		next := newTestMuxStream(p)
+		next.SetDeadline(time.Now().Add(5 * time.Second))
+		req := muxFrame("next")
+		_, err = next.Write(req)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		close(respond)
+
+		res := make([]byte, len(req))
+		_, err = io.ReadFull(next, res)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(res).To(gm.Equal(req))
+	})
+
+	gg.It("must fail the pending commands when the socket is closed", func() {
+		p, server := newTestMuxPipe(0)
+		defer p.close()
+
+		go func() {
+			readMuxFrame(server)
+			server.Close()
+		}()
+
+		s := newTestMuxStream(p)
+		s.SetDeadline(time.Now().Add(5 * time.Second))
+		_, err := s.Write(muxFrame("lost"))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		_, err = s.Read(make([]byte, 8))
+		gm.Expect(err).To(gm.HaveOccurred())
+		gm.Expect(errors.Is(err, os.ErrDeadlineExceeded)).To(gm.BeFalse())
+
+		p.wl.Lock()
+		gm.Expect(p.sock.dead).To(gm.BeTrue())
+		p.wl.Unlock()
+	})
+
+	gg.It("must fail the pending commands when the socket stalls", func() {
+		p, server := newTestMuxPipe(50 * time.Millisecond)
+		defer p.close()
+
+		// the server reads the commands, but never responds
+		go func() {
+			for {
+				if _, err := readMuxFrame(server); err != nil {
+					return
+				}
+			}
+		}()
+
+		streams := []*muxStream{newTestMuxStream(p), newTestMuxStream(p)}
+		for _, s := range streams {
+			s.SetDeadline(time.Now().Add(5 * time.Second))
+			_, err := s.Write(muxFrame("stalled"))
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+		}
+
+		start := time.Now()
+		for _, s := range streams {
+			_, err := s.Read(make([]byte, 8))
+			gm.Expect(err).To(gm.HaveOccurred())
+		}
+		gm.Expect(time.Since(start)).To(gm.BeNumerically("<", time.Second))
+
+		p.wl.Lock()
+		gm.Expect(p.sock.dead).To(gm.BeTrue())
+		p.wl.Unlock()
+	})
+
+	gg.It("must keep an idle socket open", func() {
+		p, _ := newTestMuxPipe(10 * time.Millisecond)
+		defer p.close()
+
+		time.Sleep(50 * time.Millisecond)
+		p.wl.Lock()
+		gm.Expect(p.sock.dead).To(gm.BeFalse())
+		p.wl.Unlock()
+	})
+
+	gg.It("must fail the socket on a response size over MaxBufferSize", func() {
+		p, server := newTestMuxPipe(0)
+		defer p.close()
+
+		go func() {
+			if _, err := readMuxFrame(server); err != nil {
+				return
+			}
+			header := make([]byte, 8)
+			binary.BigEndian.PutUint64(header, uint64(int64(MaxBufferSize+1)|(_CL_MSG_VERSION<<56)|(_AS_MSG_TYPE<<48)))
+			server.Write(header)
+		}()
+
+		s := newTestMuxStream(p)
+		s.SetDeadline(time.Now().Add(5 * time.Second))
+		_, err := s.Write(muxFrame("huge"))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		_, err = s.Read(make([]byte, 8))
+		gm.Expect(err).To(gm.MatchError(gm.ContainSubstring("invalid response size")))
+	})
+
+	gg.It("must recycle a stream only after its response is discarded", func() {
+		p, server := newTestMuxPipe(0)
+		defer p.close()
+
+		respond := make(chan struct{})
+		go func() {
+			msg, err := readMuxFrame(server)
+			if err != nil {
+				return
+			}
+			<-respond
+			server.Write(msg)
+		}()
+
+		var pool sync.Pool
+		s := newMuxStream()
+		s.pipe, s.pool = p, &pool
+		s.refs.Set(1)
+		s.SetDeadline(time.Now().Add(10 * time.Millisecond))
+		_, err := s.Write(muxFrame("late"))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		_, err = s.Read(make([]byte, 8))
+		gm.Expect(errors.Is(err, os.ErrDeadlineExceeded)).To(gm.BeTrue())
+
+		// the command is done, but the reader still holds the stream
+		s.Close()
+		s.release()
+		gm.Expect(s.refs.Get()).To(gm.Equal(1))
+		gm.Expect(s.pool).ToNot(gm.BeNil())
+
+		// the reader releases the stream before it counts the response
+		sock := s.sock
+		close(respond)
+		gm.Eventually(func() int {
+			sock.wmutex.Lock()
+			defer sock.wmutex.Unlock()
+			return sock.waiting
+		}).Should(gm.Equal(0))
+		gm.Expect(s.refs.Get()).To(gm.Equal(0))
+		gm.Expect(s.pool).To(gm.BeNil())
+		gm.Expect(s.resp).To(gm.BeEmpty())
+		gm.Expect(s.closed).To(gm.BeFalse())
+	})
+
+	gg.It("must send a single command per stream", func() {
+		p, server := newTestMuxPipe(0)
+		defer p.close()
+		go readMuxFrame(server)
+
+		s := newTestMuxStream(p)
+		_, err := s.Write(muxFrame("first"))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		_, err = s.Write(muxFrame("second"))
+		gm.Expect(err).To(gm.MatchError(errMuxStreamUsed))
+	})
+})
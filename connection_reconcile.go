@@ -0,0 +1,135 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/aerospike/aerospike-client-go/v7/logger"
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+const (
+	_USER_AGENT_SET = "user-agent-set:value="
+	_USER_AGENTS    = "user-agents"
+)
+
+// newClientID generates a random identifier for the cluster object, used to
+// attribute the connections reported by the server to this client.
+func newClientID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		logger.Logger.Warn("Failed to generate a random client id: %s", err.Error())
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// userAgent returns the user agent string reported to the server for each connection.
+// Format: <format-version>,<client-language>,<client-id>
+func (clstr *Cluster) userAgent() string {
+	return base64.StdEncoding.EncodeToString([]byte("0,go," + clstr.clientID))
+}
+
+// reconcileConnectionsEnabled returns true if the connection reconciliation is
+// enabled and the node supports identifying connections.
+func (nd *Node) reconcileConnectionsEnabled() bool {
+	return nd.cluster.clientPolicy.ConnectionReconcileWindow > 0 && (nd.serverInfo.Get().features&_SUPPORTS_USER_AGENT) != 0
+}
+
+// identifyConnection tags the connection on the server side with the client id,
+// so that the connections of this client can be counted by the server.
+func (nd *Node) identifyConnection(conn *Connection) Error {
+	if !nd.reconcileConnectionsEnabled() {
+		return nil
+	}
+
+	cmd := _USER_AGENT_SET + nd.cluster.userAgent()
+	info, err := conn.RequestInfo(cmd)
+	if err != nil {
+		return err
+	}
+
+	if res := info[cmd]; !strings.EqualFold(res, "ok") {
+		return newError(types.SERVER_ERROR, "failed to set the connection user agent: "+res)
+	}
+	return nil
+}
+
+// reconcileConnectionsDue returns true if the connection counts should be reconciled in the current tend.
+func (nd *Node) reconcileConnectionsDue() bool {
+	return nd.reconcileConnectionsEnabled() && nd.cluster.tendCount%nd.cluster.clientPolicy.ConnectionReconcileWindow == 0
+}
+
+// reconcileConnections compares the number of connections the server reports for this
+// client with the number of open connections tracked by the client.
+// Commands may be opening and closing connections while the info command is in flight,
+// so a desync is only flagged if it is observed in two consecutive reconciliations.
+func (nd *Node) reconcileConnections(infoMap map[string]string) {
+	response, exists := infoMap[_USER_AGENTS]
+	if !exists {
+		return
+	}
+
+	serverCount, found := parseUserAgentCount(response, nd.cluster.userAgent())
+	if !found {
+		// none of the connections have been identified yet
+		return
+	}
+
+	desync := serverCount - nd.connectionCount.Get()
+	prev := nd.connectionsDesync.GetAndSet(desync)
+	if desync == 0 || prev == 0 || (desync > 0) != (prev > 0) {
+		return
+	}
+
+	nd.stats.ConnectionsDesync.IncrementAndGet()
+	if desync > 0 {
+		logger.Logger.Warn("Node %s reports %d more connections for this client than it tracks. The client may be leaking connections.", nd.String(), desync)
+	} else {
+		logger.Logger.Warn("Node %s reports %d fewer connections for this client than it tracks. Some of the client connections may be half-open.", nd.String(), -desync)
+	}
+}
+
+// parseUserAgentCount finds the connection count for the user agent in the
+// response of the user-agents info command, formatted as:
+// user-agent=<base64>:count=<count>;user-agent=<base64>:count=<count>;...
+func parseUserAgentCount(response, userAgent string) (int, bool) {
+	for _, entry := range strings.Split(response, ";") {
+		stats := parseInfoStats(entry, ":")
+		if stats["user-agent"] != userAgent {
+			continue
+		}
+
+		count, err := strconv.Atoi(stats["count"])
+		if err != nil {
+			return 0, false
+		}
+		return count, true
+	}
+	return 0, false
+}
+
+// ConnectionDesync returns the difference between the number of connections the node
+// reported for this client and the number of connections tracked by the client in the
+// last reconciliation. Positive values mean the server has more connections than the client
+// tracks. Only available if ClientPolicy.ConnectionReconcileWindow is set and the server
+// supports the user-agents info command.
+func (nd *Node) ConnectionDesync() int {
+	return nd.connectionsDesync.Get()
+}
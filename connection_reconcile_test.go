@@ -0,0 +1,45 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Connection reconciliation tests", func() {
+
+	gg.It("must find the connection count for the user agent", func() {
+		clstr := &Cluster{clientID: "0123456789abcdef"}
+		ua := clstr.userAgent()
+
+		response := "user-agent=MCxqYXZhLDguMS4w:count=12;user-agent=" + ua + ":count=7"
+		count, found := parseUserAgentCount(response, ua)
+		gm.Expect(found).To(gm.BeTrue())
+		gm.Expect(count).To(gm.Equal(7))
+	})
+
+	gg.It("must not find the connection count for missing user agents", func() {
+		_, found := parseUserAgentCount("user-agent=MCxqYXZhLDguMS4w:count=12", "MCxnbyxmb28=")
+		gm.Expect(found).To(gm.BeFalse())
+
+		_, found = parseUserAgentCount("", "MCxnbyxmb28=")
+		gm.Expect(found).To(gm.BeFalse())
+	})
+
+	gg.It("must generate different client ids", func() {
+		gm.Expect(newClientID()).ToNot(gm.Equal(newClientID()))
+	})
+})
@@ -0,0 +1,235 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// connectionRing is a bounded lock-free multi-producer, multi-consumer LIFO heap of connections.
+// Like singleConnectionHeap, Poll returns the most recently offered connection, so the
+// connections that are not needed under the current load sink to the tail, become idle and
+// are dropped from there.
+//
+// The head and the length of the ring are packed in a single word. A goroutine claims a slot by
+// swapping the word, and only then moves the connection in or out of the slot. The slot is
+// handed over through its cell: a connection is stored only in an empty cell, and taken out
+// only from a full one, so the goroutines that claimed the same slot in turns wait for each other.
+// If the ring is empty, nil is returned.
+// if the ring is full, offer will return false
+type connectionRing struct {
+	cells []atomic.Pointer[Connection]
+	size  uint64
+
+	_ [64]byte
+	// index of the head in the high 32 bits, number of connections in the low 32 bits
+	state atomic.Uint64
+	_     [64]byte
+
+	closed atomic.Bool
+}
+
+// newConnectionRing creates a new ring with the given capacity.
+func newConnectionRing(size int) *connectionRing {
+	if size <= 0 {
+		panic("Ring size cannot be less than 1")
+	}
+
+	return &connectionRing{
+		cells: make([]atomic.Pointer[Connection], size),
+		size:  uint64(size),
+	}
+}
+
+func packRingState(head, count uint64) uint64 {
+	return head<<32 | count
+}
+
+func unpackRingState(state uint64) (head, count uint64) {
+	return state >> 32, state & 0xFFFFFFFF
+}
+
+// cleanup closes the connections in the ring, and makes sure offer and poll both fail.
+func (r *connectionRing) cleanup() {
+	r.closed.Store(true)
+	r.drain()
+}
+
+func (r *connectionRing) drain() {
+	for conn := r.pollHead(); conn != nil; conn = r.pollHead() {
+		conn.Close()
+	}
+}
+
+// put stores the connection in the claimed slot, once the slot has been emptied.
+func (r *connectionRing) put(idx uint64, conn *Connection) {
+	for !r.cells[idx].CompareAndSwap(nil, conn) {
+		// a goroutine that claimed the slot before is still taking its connection out
+		runtime.Gosched()
+	}
+
+	// the ring was cleaned up while the connection was being added
+	if r.closed.Load() {
+		r.drain()
+	}
+}
+
+// take removes the connection from the claimed slot, once it has been stored.
+func (r *connectionRing) take(idx uint64) *Connection {
+	for {
+		if conn := r.cells[idx].Swap(nil); conn != nil {
+			return conn
+		}
+		// a goroutine that claimed the slot before is still storing its connection
+		runtime.Gosched()
+	}
+}
+
+// Offer adds an item to the head of the ring unless the ring is full.
+// In case the ring is full, the item will not be added to the ring
+// and false will be returned
+func (r *connectionRing) Offer(conn *Connection) bool {
+	if r.closed.Load() {
+		return false
+	}
+
+	for {
+		state := r.state.Load()
+		head, count := unpackRingState(state)
+		if count == r.size {
+			return false
+		}
+
+		head = (head + 1) % r.size
+		if r.state.CompareAndSwap(state, packRingState(head, count+1)) {
+			r.put(head, conn)
+			return true
+		}
+	}
+}
+
+// Poll removes and returns the most recently offered item from the ring.
+// If the ring is empty, nil will be returned.
+func (r *connectionRing) Poll() *Connection {
+	if r.closed.Load() {
+		return nil
+	}
+	return r.pollHead()
+}
+
+func (r *connectionRing) pollHead() *Connection {
+	for {
+		state := r.state.Load()
+		head, count := unpackRingState(state)
+		if count == 0 {
+			return nil
+		}
+
+		if r.state.CompareAndSwap(state, packRingState((head+r.size-1)%r.size, count-1)) {
+			return r.take(head)
+		}
+	}
+}
+
+// pollTail removes and returns the least recently used connection.
+// If the ring is empty, nil will be returned.
+func (r *connectionRing) pollTail() *Connection {
+	for {
+		state := r.state.Load()
+		head, count := unpackRingState(state)
+		if count == 0 {
+			return nil
+		}
+
+		if r.state.CompareAndSwap(state, packRingState(head, count-1)) {
+			return r.take((head + r.size - count + 1) % r.size)
+		}
+	}
+}
+
+// restoreTail puts back a connection taken by pollTail.
+// If the ring has been filled up in the meantime, the connection is closed,
+// just like a connection returned to a full pool.
+func (r *connectionRing) restoreTail(conn *Connection) {
+	for !r.closed.Load() {
+		state := r.state.Load()
+		head, count := unpackRingState(state)
+		if count == r.size {
+			break
+		}
+
+		if r.state.CompareAndSwap(state, packRingState(head, count+1)) {
+			r.put((head+r.size-count)%r.size, conn)
+			return
+		}
+	}
+	conn.Close()
+}
+
+// DropIdleTail closes the least recently used connection if it is idle.
+// It will return true if the connection was idle and dropped
+func (r *connectionRing) DropIdleTail() bool {
+	if r.closed.Load() {
+		return false
+	}
+
+	// the connection is taken out before it is checked, so no other goroutine is using it
+	conn := r.pollTail()
+	if conn == nil {
+		return false
+	}
+
+	if conn.IsConnected() && !conn.isIdle() {
+		r.restoreTail(conn)
+		return false
+	}
+
+	if conn.node != nil {
+		conn.node.stats.ConnectionsIdleDropped.IncrementAndGet()
+	}
+	conn.Close()
+	return true
+}
+
+// PollTailIf removes and returns the least recently used connection
+// if it satisfies the predicate. Otherwise nil will be returned.
+func (r *connectionRing) PollTailIf(pred func(*Connection) bool) *Connection {
+	if r.closed.Load() {
+		return nil
+	}
+
+	conn := r.pollTail()
+	if conn == nil {
+		return nil
+	}
+
+	if !pred(conn) {
+		r.restoreTail(conn)
+		return nil
+	}
+	return conn
+}
+
+// Len returns the number of connections in the ring
+func (r *connectionRing) Len() int {
+	_, count := unpackRingState(r.state.Load())
+	return int(count)
+}
+
+// Cap returns the capacity of the ring
+func (r *connectionRing) Cap() int {
+	return int(r.size)
+}
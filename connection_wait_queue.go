@@ -0,0 +1,119 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"sync"
+	"time"
+)
+
+// connectionWaitQueue is a bounded FIFO queue of the commands waiting for a connection
+// to be returned to an exhausted pool. Returned connections are handed off directly
+// to the first waiter instead of being put back in the pool.
+type connectionWaitQueue struct {
+	m       sync.Mutex
+	waiters []chan *Connection
+	size    int
+}
+
+func newConnectionWaitQueue(size int) *connectionWaitQueue {
+	return &connectionWaitQueue{
+		size: size,
+	}
+}
+
+// Len returns the number of waiting commands.
+func (q *connectionWaitQueue) Len() int {
+	if q == nil {
+		return 0
+	}
+
+	q.m.Lock()
+	defer q.m.Unlock()
+	return len(q.waiters)
+}
+
+// handOff passes the connection to the first waiter. Returns false if there are no waiters.
+// A nil connection only wakes up the waiter to retry the checkout, which is used
+// when a connection is closed and a new one can be opened instead.
+func (q *connectionWaitQueue) handOff(conn *Connection) bool {
+	if q == nil {
+		return false
+	}
+
+	q.m.Lock()
+	if len(q.waiters) == 0 {
+		q.m.Unlock()
+		return false
+	}
+
+	w := q.waiters[0]
+	q.waiters[0] = nil
+	q.waiters = q.waiters[1:]
+	q.m.Unlock()
+
+	// the channel is buffered and is only ever sent to once
+	w <- conn
+	return true
+}
+
+// wait blocks until a connection is handed off or the deadline on the clock is reached.
+// The second return value is false if the queue was full or the deadline was reached.
+// The returned connection can be nil if the waiter was woken up to retry the checkout.
+func (q *connectionWaitQueue) wait(clock Clock, deadline time.Time) (*Connection, bool) {
+	if q == nil || deadline.IsZero() {
+		return nil, false
+	}
+
+	q.m.Lock()
+	if len(q.waiters) >= q.size {
+		q.m.Unlock()
+		return nil, false
+	}
+
+	w := make(chan *Connection, 1)
+	q.waiters = append(q.waiters, w)
+	q.m.Unlock()
+
+	// only the timers of the system clock can be stopped
+	var expired <-chan time.Time
+	if clock == SystemClock {
+		t := time.NewTimer(deadline.Sub(clock.Now()))
+		defer t.Stop()
+		expired = t.C
+	} else {
+		expired = clock.After(deadline.Sub(clock.Now()))
+	}
+
+	select {
+	case conn := <-w:
+		return conn, true
+	case <-expired:
+	}
+
+	// remove the waiter from the queue. If it is not there anymore,
+	// a connection has already been handed off to it.
+	q.m.Lock()
+	for i := range q.waiters {
+		if q.waiters[i] == w {
+			q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+			q.m.Unlock()
+			return nil, false
+		}
+	}
+	q.m.Unlock()
+
+	return <-w, true
+}
@@ -0,0 +1,94 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Connection Wait Queue tests", func() {
+
+	gg.It("must hand off connections to the waiters in order", func() {
+		q := newConnectionWaitQueue(2)
+		conn1, conn2 := new(Connection), new(Connection)
+
+		res := make(chan *Connection, 2)
+		for i := 0; i < 2; i++ {
+			go func() {
+				defer gg.GinkgoRecover()
+				conn, ok := q.wait(SystemClock, time.Now().Add(5*time.Second))
+				gm.Expect(ok).To(gm.BeTrue())
+				res <- conn
+			}()
+			gm.Eventually(q.Len).Should(gm.Equal(i + 1))
+		}
+
+		gm.Expect(q.handOff(conn1)).To(gm.BeTrue())
+		gm.Expect(<-res).To(gm.BeIdenticalTo(conn1))
+		gm.Expect(q.handOff(conn2)).To(gm.BeTrue())
+		gm.Expect(<-res).To(gm.BeIdenticalTo(conn2))
+
+		gm.Expect(q.Len()).To(gm.Equal(0))
+		gm.Expect(q.handOff(conn1)).To(gm.BeFalse())
+	})
+
+	gg.It("must not wait if the queue is full", func() {
+		q := newConnectionWaitQueue(1)
+		go q.wait(SystemClock, time.Now().Add(time.Second))
+		gm.Eventually(q.Len).Should(gm.Equal(1))
+
+		start := time.Now()
+		_, ok := q.wait(SystemClock, time.Now().Add(time.Second))
+		gm.Expect(ok).To(gm.BeFalse())
+		gm.Expect(time.Since(start)).To(gm.BeNumerically("<", 100*time.Millisecond))
+	})
+
+	gg.It("must time out and leave the queue", func() {
+		q := newConnectionWaitQueue(1)
+		conn, ok := q.wait(SystemClock, time.Now().Add(10*time.Millisecond))
+		gm.Expect(ok).To(gm.BeFalse())
+		gm.Expect(conn).To(gm.BeNil())
+		gm.Expect(q.Len()).To(gm.Equal(0))
+	})
+
+	gg.It("must time out on the clock of the deadline", func() {
+		clock := NewFakeClock(time.Now().Add(-time.Hour))
+		q := newConnectionWaitQueue(1)
+
+		res := make(chan bool, 1)
+		go func() {
+			_, ok := q.wait(clock, clock.Now().Add(time.Second))
+			res <- ok
+		}()
+		gm.Eventually(clock.Waiters).Should(gm.Equal(1))
+
+		// neither the real time elapsed nor the offset to the real time expire the wait
+		gm.Consistently(res, 50*time.Millisecond).ShouldNot(gm.Receive())
+		gm.Expect(q.Len()).To(gm.Equal(1))
+
+		clock.Advance(time.Second)
+		gm.Eventually(res).Should(gm.Receive(gm.BeFalse()))
+		gm.Expect(q.Len()).To(gm.Equal(0))
+	})
+
+	gg.It("must never wait without a deadline", func() {
+		q := newConnectionWaitQueue(1)
+		_, ok := q.wait(SystemClock, time.Time{})
+		gm.Expect(ok).To(gm.BeFalse())
+	})
+})
@@ -0,0 +1,67 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+// ConsistencyCheckPolicy contains attributes used by CheckConsistency.
+type ConsistencyCheckPolicy struct {
+	// ScanPolicy is the policy used to scan the partitions on both sources.
+	// If nil, the default scan policy of each client is used.
+	// IncludeBinData is ignored, and set depending on CompareBins.
+	ScanPolicy *ScanPolicy
+
+	// CompareGenerations determines if records present on both sources must also have
+	// the same generation. XDR does not ship generations, so turn it off when comparing
+	// a source cluster with its XDR destination.
+	//
+	// Default: true
+	CompareGenerations bool // = true
+
+	// CompareBins determines if the bins of the records present on both sources are read
+	// and compared by checksum. Comparing bins requires transferring the record data
+	// from both sources, and is considerably more expensive than comparing digests.
+	//
+	// Default: false
+	CompareBins bool // = false
+
+	// BinNames limits the bins compared when CompareBins is set.
+	// If empty, all bins are compared.
+	BinNames []string
+
+	// PartitionBegin is the first partition to check.
+	//
+	// Default: 0
+	PartitionBegin int // = 0
+
+	// PartitionCount is the number of partitions to check starting from PartitionBegin.
+	// A value of 0 or less means all partitions up to the last one.
+	//
+	// Default: 4096
+	PartitionCount int // = 4096
+
+	// ConcurrentPartitions is the maximum number of partitions checked in parallel.
+	// The records of each partition being checked are held in memory.
+	//
+	// Default: 4
+	ConcurrentPartitions int // = 4
+}
+
+// NewConsistencyCheckPolicy initializes a new ConsistencyCheckPolicy instance with default parameters.
+func NewConsistencyCheckPolicy() *ConsistencyCheckPolicy {
+	return &ConsistencyCheckPolicy{
+		CompareGenerations:   true,
+		PartitionCount:       _PARTITIONS,
+		ConcurrentPartitions: 4,
+	}
+}
@@ -0,0 +1,457 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"sort"
+	"sync"
+
+	iatomic "github.com/aerospike/aerospike-client-go/v7/internal/atomic"
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// RecordDiffType determines the kind of difference found by CheckConsistency.
+type RecordDiffType int
+
+const (
+	// RECORD_MISSING_IN_TARGET means the record only exists on the source.
+	RECORD_MISSING_IN_TARGET RecordDiffType = iota
+
+	// RECORD_MISSING_IN_SOURCE means the record only exists on the target.
+	RECORD_MISSING_IN_SOURCE
+
+	// GENERATION_MISMATCH means the record exists on both sources with different generations.
+	GENERATION_MISMATCH
+
+	// BINS_MISMATCH means the record exists on both sources with different bins.
+	BINS_MISMATCH
+)
+
+// String implements the Stringer interface.
+func (dt RecordDiffType) String() string {
+	switch dt {
+	case RECORD_MISSING_IN_TARGET:
+		return "RECORD_MISSING_IN_TARGET"
+	case RECORD_MISSING_IN_SOURCE:
+		return "RECORD_MISSING_IN_SOURCE"
+	case GENERATION_MISMATCH:
+		return "GENERATION_MISMATCH"
+	case BINS_MISMATCH:
+		return "BINS_MISMATCH"
+	}
+	return "UNKNOWN"
+}
+
+// ConsistencySource is one side of a consistency check: a namespace on a cluster.
+// The source and target of a check can be the same cluster with different namespaces,
+// or different clusters, as for XDR.
+type ConsistencySource struct {
+	// Client is the client connected to the cluster.
+	Client ClientIfc
+
+	// Namespace is the namespace of the records.
+	Namespace string
+}
+
+// RecordDifference describes a record that differs between the source and the target.
+type RecordDifference struct {
+	// Type is the kind of the difference.
+	Type RecordDiffType
+
+	// Key is the key of the record as returned by the side it was found on,
+	// the source if it exists there. Only the digest is set, unless the user key is stored on the server.
+	Key *Key
+
+	// PartitionId is the partition of the record.
+	PartitionId int
+
+	// SourceGeneration and TargetGeneration are the generations of the record
+	// on each side. They are 0 on the side the record is missing from.
+	SourceGeneration, TargetGeneration uint32
+
+	// SourceChecksum and TargetChecksum are the checksums of the bins of the record
+	// on each side. They are only set if the bins are compared.
+	SourceChecksum, TargetChecksum uint64
+}
+
+// ConsistencyCheckResult contains the totals of a consistency check.
+type ConsistencyCheckResult struct {
+	// PartitionsChecked is the number of partitions that were completely checked.
+	PartitionsChecked int
+
+	// SourceRecords and TargetRecords are the number of records of the checked partitions on each side.
+	SourceRecords, TargetRecords int
+
+	// Differences is the number of differences passed to the handler.
+	Differences int
+}
+
+// ConsistencyDiffHandler is called by CheckConsistency for each difference found.
+// Calls are serialized, so the handler does not need to be safe for concurrent use.
+// Returning false stops the check.
+type ConsistencyDiffHandler func(diff *RecordDifference) bool
+
+// CheckConsistency compares the records of a set in two namespaces, on the same or different clusters,
+// partition by partition. Records are matched by digest, and compared by generation and
+// optionally by a checksum of their bins, and the differences are streamed to the handler.
+// It is the building block of XDR validation jobs.
+// Digests depend on the set name, so the same set is compared on both sides.
+// An empty set name compares the whole namespaces.
+//
+// Each partition is scanned on both sides, so records written during the check
+// can be reported as differences; recheck the reported records to rule those out.
+// If the policy is nil, the default ConsistencyCheckPolicy will be used.
+// The totals are returned even if the check fails or is stopped by the handler.
+func CheckConsistency(policy *ConsistencyCheckPolicy, source, target *ConsistencySource, setName string, onDiff ConsistencyDiffHandler) (*ConsistencyCheckResult, Error) {
+	if policy == nil {
+		policy = NewConsistencyCheckPolicy()
+	}
+
+	if source == nil || source.Client == nil || target == nil || target.Client == nil {
+		return nil, newError(types.PARAMETER_ERROR, "The source and the target of a consistency check must have a client")
+	}
+
+	if onDiff == nil {
+		return nil, newError(types.PARAMETER_ERROR, "A consistency check requires a difference handler")
+	}
+
+	begin, count := policy.PartitionBegin, policy.PartitionCount
+	if begin < 0 || begin >= _PARTITIONS {
+		return nil, newError(types.PARAMETER_ERROR, "Invalid partition begin for the consistency check")
+	}
+
+	if count <= 0 || begin+count > _PARTITIONS {
+		count = _PARTITIONS - begin
+	}
+
+	cc := &consistencyChecker{
+		policy:       policy,
+		source:       source,
+		target:       target,
+		setName:      setName,
+		sourcePolicy: consistencyScanPolicy(policy, source.Client),
+		targetPolicy: consistencyScanPolicy(policy, target.Client),
+		onDiff:       onDiff,
+	}
+
+	concurrency := policy.ConcurrentPartitions
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	if concurrency > count {
+		concurrency = count
+	}
+
+	partitions := make(chan int, count)
+	for id := begin; id < begin+count; id++ {
+		partitions <- id
+	}
+	close(partitions)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range partitions {
+				if cc.stopped.Get() {
+					return
+				}
+
+				if err := cc.checkPartition(id); err != nil {
+					cc.fail(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return &cc.res, cc.err
+}
+
+// consistencyScanPolicy returns a copy of the scan policy of the check for the client,
+// reading the bin data only if needed.
+func consistencyScanPolicy(policy *ConsistencyCheckPolicy, clnt ClientIfc) *ScanPolicy {
+	var res ScanPolicy
+	if policy.ScanPolicy != nil {
+		res = *policy.ScanPolicy
+	} else {
+		res = *clnt.GetDefaultScanPolicy()
+	}
+	res.IncludeBinData = policy.CompareBins
+	return &res
+}
+
+type consistencyChecker struct {
+	policy         *ConsistencyCheckPolicy
+	source, target *ConsistencySource
+	setName        string
+
+	sourcePolicy, targetPolicy *ScanPolicy
+
+	// guards res, err and the calls to onDiff.
+	m      sync.Mutex
+	res    ConsistencyCheckResult
+	err    Error
+	onDiff ConsistencyDiffHandler
+
+	stopped iatomic.Bool
+}
+
+type consistencyEntry struct {
+	key        *Key
+	generation uint32
+	checksum   uint64
+}
+
+// checkPartition scans the partition on the source, holding the records in memory,
+// then streams the records of the partition on the target and compares them.
+// The records not found on the target are reported last, in digest order.
+func (cc *consistencyChecker) checkPartition(partitionId int) Error {
+	sourceRecords := map[[20]byte]consistencyEntry{}
+	err := cc.scan(cc.source, cc.sourcePolicy, partitionId, func(rec *Record) bool {
+		var digest [20]byte
+		copy(digest[:], rec.Key.Digest())
+		sourceRecords[digest] = cc.entry(rec)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	sourceCount := len(sourceRecords)
+
+	targetCount := 0
+	err = cc.scan(cc.target, cc.targetPolicy, partitionId, func(rec *Record) bool {
+		targetCount++
+
+		var digest [20]byte
+		copy(digest[:], rec.Key.Digest())
+		targetEntry := cc.entry(rec)
+
+		sourceEntry, exists := sourceRecords[digest]
+		if !exists {
+			return cc.report(&RecordDifference{
+				Type:             RECORD_MISSING_IN_SOURCE,
+				Key:              rec.Key,
+				PartitionId:      partitionId,
+				TargetGeneration: targetEntry.generation,
+				TargetChecksum:   targetEntry.checksum,
+			})
+		}
+		delete(sourceRecords, digest)
+
+		diff := &RecordDifference{
+			Key:              sourceEntry.key,
+			PartitionId:      partitionId,
+			SourceGeneration: sourceEntry.generation,
+			TargetGeneration: targetEntry.generation,
+			SourceChecksum:   sourceEntry.checksum,
+			TargetChecksum:   targetEntry.checksum,
+		}
+
+		switch {
+		case cc.policy.CompareGenerations && sourceEntry.generation != targetEntry.generation:
+			diff.Type = GENERATION_MISMATCH
+		case cc.policy.CompareBins && sourceEntry.checksum != targetEntry.checksum:
+			diff.Type = BINS_MISMATCH
+		default:
+			return true
+		}
+		return cc.report(diff)
+	})
+	if err != nil {
+		return err
+	}
+
+	if cc.stopped.Get() {
+		return nil
+	}
+
+	digests := make([][20]byte, 0, len(sourceRecords))
+	for digest := range sourceRecords {
+		digests = append(digests, digest)
+	}
+	sort.Slice(digests, func(i, j int) bool {
+		return bytes.Compare(digests[i][:], digests[j][:]) < 0
+	})
+
+	for _, digest := range digests {
+		sourceEntry := sourceRecords[digest]
+		if !cc.report(&RecordDifference{
+			Type:             RECORD_MISSING_IN_TARGET,
+			Key:              sourceEntry.key,
+			PartitionId:      partitionId,
+			SourceGeneration: sourceEntry.generation,
+			SourceChecksum:   sourceEntry.checksum,
+		}) {
+			return nil
+		}
+	}
+
+	cc.m.Lock()
+	cc.res.PartitionsChecked++
+	cc.res.SourceRecords += sourceCount
+	cc.res.TargetRecords += targetCount
+	cc.m.Unlock()
+
+	return nil
+}
+
+// entry returns the fields of the record to compare.
+func (cc *consistencyChecker) entry(rec *Record) consistencyEntry {
+	res := consistencyEntry{key: rec.Key, generation: rec.Generation}
+	if cc.policy.CompareBins {
+		res.checksum = binsChecksum(rec.Bins)
+	}
+	return res
+}
+
+// scan streams the records of the partition on the source to f, until f returns false.
+func (cc *consistencyChecker) scan(src *ConsistencySource, policy *ScanPolicy, partitionId int, f func(*Record) bool) Error {
+	var binNames []string
+	if cc.policy.CompareBins {
+		binNames = cc.policy.BinNames
+	}
+
+	rs, err := src.Client.ScanPartitions(policy, NewPartitionFilterById(partitionId), src.Namespace, cc.setName, binNames...)
+	if err != nil {
+		return err
+	}
+	defer rs.Close()
+
+	for res := range rs.Results() {
+		if res.Err != nil {
+			return res.Err
+		}
+
+		if cc.stopped.Get() || !f(res.Record) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// report passes the difference to the handler, and returns false if the check must stop.
+func (cc *consistencyChecker) report(diff *RecordDifference) bool {
+	cc.m.Lock()
+	defer cc.m.Unlock()
+
+	if cc.stopped.Get() {
+		return false
+	}
+
+	cc.res.Differences++
+	if !cc.onDiff(diff) {
+		cc.stopped.Set(true)
+		return false
+	}
+	return true
+}
+
+// fail records the error and stops the check.
+func (cc *consistencyChecker) fail(err Error) {
+	cc.m.Lock()
+	defer cc.m.Unlock()
+
+	cc.stopped.Set(true)
+	cc.err = chainErrors(err, cc.err)
+}
+
+// binsChecksum returns a checksum of the bins that does not depend on the order of
+// the bins or of the map entries, so that the same record read from different
+// clusters has the same checksum.
+func binsChecksum(bins BinMap) uint64 {
+	names := make([]string, 0, len(bins))
+	for name := range bins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := fnv.New64a()
+	for _, name := range names {
+		writeChecksumValue(h, name)
+		writeChecksumValue(h, bins[name])
+	}
+	return h.Sum64()
+}
+
+// writeChecksumValue writes the value to the hash in a canonical form.
+// Lists are written element by element, and the entries of maps are sorted by their encoding.
+// Other values are written in their msgpack encoding.
+func writeChecksumValue(h hash.Hash64, value interface{}) {
+	var size [4]byte
+
+	switch v := value.(type) {
+	case []interface{}:
+		binary.BigEndian.PutUint32(size[:], uint32(len(v)))
+		h.Write([]byte{'l'})
+		h.Write(size[:])
+		for i := range v {
+			writeChecksumValue(h, v[i])
+		}
+		return
+	case map[interface{}]interface{}:
+		entries := make([][]byte, 0, len(v))
+		for k, val := range v {
+			entries = append(entries, checksumMapEntry(k, val))
+		}
+		writeChecksumMap(h, entries)
+		return
+	case []MapPair:
+		entries := make([][]byte, 0, len(v))
+		for i := range v {
+			entries = append(entries, checksumMapEntry(v[i].Key, v[i].Value))
+		}
+		writeChecksumMap(h, entries)
+		return
+	}
+
+	packer := newPacker()
+	if _, err := packObject(packer, value, false); err != nil {
+		// values read from the server can always be packed
+		h.Write([]byte(err.Error()))
+		return
+	}
+	h.Write(packer.Bytes())
+}
+
+func checksumMapEntry(k, v interface{}) []byte {
+	var buf bytes.Buffer
+	hk, hv := fnv.New64a(), fnv.New64a()
+	writeChecksumValue(hk, k)
+	writeChecksumValue(hv, v)
+	buf.Write(hk.Sum(nil))
+	buf.Write(hv.Sum(nil))
+	return buf.Bytes()
+}
+
+func writeChecksumMap(h hash.Hash64, entries [][]byte) {
+	var size [4]byte
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i], entries[j]) < 0
+	})
+
+	binary.BigEndian.PutUint32(size[:], uint32(len(entries)))
+	h.Write([]byte{'m'})
+	h.Write(size[:])
+	for _, entry := range entries {
+		h.Write(entry)
+	}
+}
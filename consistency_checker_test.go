@@ -0,0 +1,135 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	as "github.com/aerospike/aerospike-client-go/v7"
+	"github.com/aerospike/aerospike-client-go/v7/mock"
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Consistency checker tests", func() {
+
+	var source, target *as.ConsistencySource
+
+	put := func(src *as.ConsistencySource, key string, bins as.BinMap) {
+		k, err := as.NewKey(src.Namespace, "set", key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(src.Client.Put(nil, k, bins)).ToNot(gm.HaveOccurred())
+	}
+
+	check := func(policy *as.ConsistencyCheckPolicy) ([]*as.RecordDifference, *as.ConsistencyCheckResult) {
+		var diffs []*as.RecordDifference
+		res, err := as.CheckConsistency(policy, source, target, "set", func(diff *as.RecordDifference) bool {
+			diffs = append(diffs, diff)
+			return true
+		})
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		return diffs, res
+	}
+
+	gg.BeforeEach(func() {
+		source = &as.ConsistencySource{Client: mock.NewClient(), Namespace: "test"}
+		target = &as.ConsistencySource{Client: mock.NewClient(), Namespace: "dest"}
+	})
+
+	gg.It("must not report differences for identical namespaces", func() {
+		for _, src := range []*as.ConsistencySource{source, target} {
+			put(src, "a", as.BinMap{"i": 1, "m": map[interface{}]interface{}{"x": 1, "y": []interface{}{1, "z"}}})
+			put(src, "b", as.BinMap{"s": "str"})
+		}
+
+		policy := as.NewConsistencyCheckPolicy()
+		policy.CompareBins = true
+		diffs, res := check(policy)
+		gm.Expect(diffs).To(gm.BeEmpty())
+		gm.Expect(res).To(gm.Equal(&as.ConsistencyCheckResult{PartitionsChecked: 4096, SourceRecords: 2, TargetRecords: 2}))
+	})
+
+	gg.It("must report the missing records and the mismatched generations", func() {
+		put(source, "a", as.BinMap{"i": 1})
+		put(source, "b", as.BinMap{"i": 1})
+		put(source, "b", as.BinMap{"i": 2})
+		put(target, "b", as.BinMap{"i": 2})
+		put(target, "c", as.BinMap{"i": 1})
+
+		diffs, res := check(nil)
+		gm.Expect(res.Differences).To(gm.Equal(3))
+		gm.Expect(res.SourceRecords).To(gm.Equal(2))
+		gm.Expect(res.TargetRecords).To(gm.Equal(2))
+
+		found := map[as.RecordDiffType]*as.RecordDifference{}
+		for _, diff := range diffs {
+			found[diff.Type] = diff
+		}
+		gm.Expect(found).To(gm.HaveLen(3))
+
+		keyA, _ := as.NewKey("test", "set", "a")
+		gm.Expect(found[as.RECORD_MISSING_IN_TARGET].Key.Digest()).To(gm.Equal(keyA.Digest()))
+		gm.Expect(found[as.RECORD_MISSING_IN_TARGET].PartitionId).To(gm.Equal(keyA.PartitionId()))
+
+		keyC, _ := as.NewKey("dest", "set", "c")
+		gm.Expect(found[as.RECORD_MISSING_IN_SOURCE].Key.Digest()).To(gm.Equal(keyC.Digest()))
+
+		gm.Expect(found[as.GENERATION_MISMATCH].SourceGeneration).To(gm.Equal(uint32(2)))
+		gm.Expect(found[as.GENERATION_MISMATCH].TargetGeneration).To(gm.Equal(uint32(1)))
+	})
+
+	gg.It("must compare the bins by checksum", func() {
+		put(source, "a", as.BinMap{"i": 1, "s": "x"})
+		put(target, "a", as.BinMap{"i": 1, "s": "y"})
+
+		policy := as.NewConsistencyCheckPolicy()
+		diffs, _ := check(policy)
+		gm.Expect(diffs).To(gm.BeEmpty())
+
+		policy.CompareBins = true
+		diffs, _ = check(policy)
+		gm.Expect(diffs).To(gm.HaveLen(1))
+		gm.Expect(diffs[0].Type).To(gm.Equal(as.BINS_MISMATCH))
+		gm.Expect(diffs[0].SourceChecksum).ToNot(gm.Equal(diffs[0].TargetChecksum))
+
+		policy.BinNames = []string{"i"}
+		diffs, _ = check(policy)
+		gm.Expect(diffs).To(gm.BeEmpty())
+	})
+
+	gg.It("must stop when the handler returns false", func() {
+		for _, key := range []string{"a", "b", "c", "d"} {
+			put(source, key, as.BinMap{"i": 1})
+		}
+
+		calls := 0
+		res, err := as.CheckConsistency(nil, source, target, "set", func(diff *as.RecordDifference) bool {
+			calls++
+			return false
+		})
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(calls).To(gm.Equal(1))
+		gm.Expect(res.Differences).To(gm.Equal(1))
+		gm.Expect(res.PartitionsChecked).To(gm.BeNumerically("<", 4096))
+	})
+
+	gg.It("must validate the arguments", func() {
+		_, err := as.CheckConsistency(nil, source, nil, "set", func(*as.RecordDifference) bool { return true })
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+
+		_, err = as.CheckConsistency(nil, source, target, "set", nil)
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+	})
+})
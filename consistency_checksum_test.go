@@ -0,0 +1,41 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Consistency checker checksums", func() {
+
+	gg.It("must compute the bin checksums independent of the map order", func() {
+		m1 := map[interface{}]interface{}{}
+		m2 := map[interface{}]interface{}{}
+		pairs := []MapPair{}
+		for i := 0; i < 50; i++ {
+			m1[i] = []interface{}{i, "v"}
+			m2[49-i] = []interface{}{49 - i, "v"}
+		}
+		for i := 0; i < 50; i++ {
+			pairs = append(pairs, MapPair{Key: i, Value: []interface{}{i, "v"}})
+		}
+
+		gm.Expect(binsChecksum(BinMap{"m": m1, "a": 1})).To(gm.Equal(binsChecksum(BinMap{"a": 1, "m": m2})))
+		gm.Expect(binsChecksum(BinMap{"m": m1})).To(gm.Equal(binsChecksum(BinMap{"m": pairs})))
+		gm.Expect(binsChecksum(BinMap{"m": m1})).ToNot(gm.Equal(binsChecksum(BinMap{"n": m1})))
+		gm.Expect(binsChecksum(BinMap{"a": []interface{}{1, 2}})).ToNot(gm.Equal(binsChecksum(BinMap{"a": []interface{}{2, 1}})))
+	})
+})
@@ -0,0 +1,111 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"context"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// CredentialsProvider provides the user and password of the client instead of
+// ClientPolicy.User and ClientPolicy.Password, for example from a secrets manager
+// that issues short-lived credentials.
+// GetCredentials is called on every login to the server: when a node joins the cluster,
+// when the session token of a node is refreshed or rejected, and when a connection is
+// opened without a valid session token. Rotated credentials are therefore picked up
+// without restarting the client. Implementations should cache the credentials until
+// they rotate; the client only hashes the password again when it changes.
+// Calls to GetCredentials are not concurrent. The context carries the
+// ClientPolicy.LoginTimeout deadline.
+type CredentialsProvider interface {
+	GetCredentials(ctx context.Context) (user string, password string, err error)
+}
+
+// CredentialsProviderFunc is an adapter to use a function as a CredentialsProvider.
+type CredentialsProviderFunc func(ctx context.Context) (user string, password string, err error)
+
+// GetCredentials calls f(ctx).
+func (f CredentialsProviderFunc) GetCredentials(ctx context.Context) (string, string, error) {
+	return f(ctx)
+}
+
+// authCredentials are the user and password used to login to the server.
+type authCredentials struct {
+	user     string
+	password string
+	// hash is the bcrypt hash of the password sent to the server.
+	hash []byte
+}
+
+// newAuthCredentials hashes the password and returns the credentials.
+func newAuthCredentials(user, password string) (*authCredentials, Error) {
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+	return &authCredentials{user: user, password: password, hash: hash}, nil
+}
+
+// currentCredentials returns the credentials of the last login, or nil
+// if the cluster does not require authentication.
+func (clstr *Cluster) currentCredentials() *authCredentials {
+	return clstr.credentials.Get()
+}
+
+// userName returns the user of the last login.
+func (clstr *Cluster) userName() string {
+	if creds := clstr.currentCredentials(); creds != nil {
+		return creds.user
+	}
+	return ""
+}
+
+// loginCredentials returns the credentials to login to the server with.
+// If ClientPolicy.CredentialsProvider is set, the provider is asked for the
+// current credentials on each call.
+func (clstr *Cluster) loginCredentials() (*authCredentials, Error) {
+	provider := clstr.clientPolicy.CredentialsProvider
+	if provider == nil {
+		return clstr.currentCredentials(), nil
+	}
+
+	clstr.credentialsLock.Lock()
+	defer clstr.credentialsLock.Unlock()
+
+	ctx := context.Background()
+	if clstr.clientPolicy.LoginTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, clstr.clientPolicy.LoginTimeout)
+		defer cancel()
+	}
+
+	user, password, err := provider.GetCredentials(ctx)
+	if err != nil {
+		return nil, newErrorAndWrap(err, types.NOT_AUTHENTICATED, "failed to get the credentials from ClientPolicy.CredentialsProvider")
+	}
+
+	// avoid hashing the password again if the credentials have not changed
+	if creds := clstr.currentCredentials(); creds != nil && creds.user == user && creds.password == password {
+		return creds, nil
+	}
+
+	creds, aerr := newAuthCredentials(user, password)
+	if aerr != nil {
+		return nil, aerr
+	}
+	clstr.credentials.Set(creds)
+	return creds, nil
+}
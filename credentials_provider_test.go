@@ -0,0 +1,92 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Credentials provider", func() {
+
+	var clstr *Cluster
+	var calls int
+	var user, password string
+	var providerErr error
+
+	gg.BeforeEach(func() {
+		calls = 0
+		user, password, providerErr = "admin", "secret", nil
+
+		policy := NewClientPolicy()
+		policy.CredentialsProvider = CredentialsProviderFunc(func(ctx context.Context) (string, string, error) {
+			calls++
+			_, hasDeadline := ctx.Deadline()
+			gm.Expect(hasDeadline).To(gm.BeTrue())
+			return user, password, providerErr
+		})
+		clstr = &Cluster{clientPolicy: *policy}
+	})
+
+	gg.It("must require authentication when a provider is set", func() {
+		gm.Expect(clstr.clientPolicy.RequiresAuthentication()).To(gm.BeTrue())
+		gm.Expect(NewClientPolicy().RequiresAuthentication()).To(gm.BeFalse())
+	})
+
+	gg.It("must ask the provider on each login and only hash changed passwords", func() {
+		creds, err := clstr.loginCredentials()
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(creds.user).To(gm.Equal("admin"))
+		gm.Expect(clstr.userName()).To(gm.Equal("admin"))
+		gm.Expect(clstr.Password()).To(gm.Equal(creds.hash))
+
+		again, err := clstr.loginCredentials()
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(again).To(gm.BeIdenticalTo(creds))
+		gm.Expect(calls).To(gm.Equal(2))
+
+		// rotate the password
+		password = "rotated"
+		rotated, err := clstr.loginCredentials()
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(rotated.password).To(gm.Equal("rotated"))
+		gm.Expect(rotated.hash).ToNot(gm.Equal(creds.hash))
+		gm.Expect(clstr.currentCredentials()).To(gm.BeIdenticalTo(rotated))
+	})
+
+	gg.It("must return an authentication error if the provider fails", func() {
+		providerErr = errors.New("vault is sealed")
+		_, err := clstr.loginCredentials()
+		gm.Expect(err).To(gm.HaveOccurred())
+		gm.Expect(err.Matches(types.NOT_AUTHENTICATED)).To(gm.BeTrue())
+		gm.Expect(errors.Is(err, providerErr)).To(gm.BeTrue())
+		gm.Expect(clstr.currentCredentials()).To(gm.BeNil())
+	})
+
+	gg.It("must keep the session user with the session token", func() {
+		creds, err := clstr.loginCredentials()
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		lcmd := newLoginCommand(make([]byte, 512))
+		lcmd.user = creds.user
+		lcmd.SessionToken = []byte("token")
+		gm.Expect(lcmd.sessionInfo().user).To(gm.Equal("admin"))
+	})
+})
@@ -0,0 +1,74 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import "github.com/aerospike/aerospike-client-go/v7/types"
+
+// DeleteResult is the result of DeleteWithResult.
+//
+// Tombstones are not returned by reads; whether a record is in tombstone
+// state can only be checked in XDR filters with ExpIsTombstone.
+type DeleteResult struct {
+	// Existed is true if the record existed before the delete.
+	Existed bool
+
+	// Tombstone is true if the delete left a tombstone for the record.
+	// This is the case when the record existed, the delete was durable
+	// and the server keeps tombstones, which requires an Enterprise Edition server.
+	Tombstone bool
+}
+
+// checkDurableDelete returns an error if the policy requires durable deletes
+// and a node of the cluster does not support them.
+func (clnt *Client) checkDurableDelete(policy *WritePolicy) Error {
+	if !policy.DurableDelete || !policy.RequireDurableDelete {
+		return nil
+	}
+
+	for _, node := range clnt.cluster.GetNodes() {
+		if !node.SupportsDurableDelete() {
+			return newError(types.ENTERPRISE_ONLY, "Durable deletes are not supported by node "+node.String())
+		}
+	}
+	return nil
+}
+
+// supportsDurableDelete returns true if all the nodes of the cluster leave tombstones for durable deletes.
+func (clnt *Client) supportsDurableDelete() bool {
+	nodes := clnt.cluster.GetNodes()
+	for _, node := range nodes {
+		if !node.SupportsDurableDelete() {
+			return false
+		}
+	}
+	return len(nodes) > 0
+}
+
+// DeleteWithResult deletes a record for specified key, and reports if the record
+// existed and if the delete left a tombstone.
+// See WritePolicy.DurableDelete and WritePolicy.RequireDurableDelete.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) DeleteWithResult(policy *WritePolicy, key *Key) (*DeleteResult, Error) {
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
+	existed, err := clnt.Delete(policy, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeleteResult{
+		Existed:   existed,
+		Tombstone: existed && policy.DurableDelete && clnt.supportsDurableDelete(),
+	}, nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+)
+
+// DeleteSetPolicy contains attributes used for the DeleteSet command.
+type DeleteSetPolicy struct {
+	InfoPolicy
+
+	// DropIndexes determines if the secondary indexes of the set are dropped
+	// after its records are removed.
+	//
+	// Default: false
+	DropIndexes bool // = false
+
+	// WaitTimeout is the maximum time to wait for the records of the set to be removed on all the nodes.
+	// A value of 0 returns as soon as the truncation is accepted by the server, without waiting.
+	//
+	// Default: 30 seconds
+	WaitTimeout time.Duration // = 30 * time.Second
+
+	// PollInterval is the time between the checks of the set statistics while waiting.
+	//
+	// Default: 100 milliseconds
+	PollInterval time.Duration // = 100 * time.Millisecond
+}
+
+// NewDeleteSetPolicy generates a new DeleteSetPolicy with default values.
+func NewDeleteSetPolicy() *DeleteSetPolicy {
+	return &DeleteSetPolicy{
+		InfoPolicy:   *NewInfoPolicy(),
+		WaitTimeout:  30 * time.Second,
+		PollInterval: 100 * time.Millisecond,
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// The document API stores JSON documents in map bins, and addresses their nested fields
+// with JSONPath-like paths, translated to CDT contexts.
+//
+// The supported paths start with `$`, the root of the document, followed by any number of:
+//
+//	.key       a key of a map
+//	['key']    a key of a map, which may contain dots
+//	[index]    an index of a list; negative indexes start from the end of the list
+//
+// For example `$.store.book[0].title` or `$['store']['book'][-1]`.
+// Wildcards, recursive descent and filters are not supported.
+// The maps and lists on the path must exist; only the last element of the path is created or removed.
+
+// docPathPart is an element of a document path: either a map key or a list index.
+type docPathPart struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+func (p docPathPart) ctx() *CDTContext {
+	if p.isIndex {
+		return CtxListIndex(p.index)
+	}
+	return CtxMapKey(NewStringValue(p.key))
+}
+
+func newDocPathError(path, msg string) Error {
+	return newError(types.PARAMETER_ERROR, "Invalid document path `"+path+"`: "+msg)
+}
+
+// parseDocPath parses a document path to its elements.
+func parseDocPath(path string) ([]docPathPart, Error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, newDocPathError(path, "the path must start with `$`")
+	}
+
+	var res []docPathPart
+	for i := 1; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+			end := i
+			for end < len(path) && path[end] != '.' && path[end] != '[' {
+				end++
+			}
+
+			key := path[i:end]
+			switch {
+			case len(key) == 0:
+				return nil, newDocPathError(path, "empty key or recursive descent")
+			case key == "*":
+				return nil, newDocPathError(path, "wildcards are not supported")
+			}
+			res = append(res, docPathPart{key: key})
+			i = end
+
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, newDocPathError(path, "missing `]`")
+			}
+
+			elem := path[i+1 : i+end]
+			if l := len(elem); l >= 2 && (elem[0] == '\'' || elem[0] == '"') && elem[l-1] == elem[0] {
+				res = append(res, docPathPart{key: elem[1 : l-1]})
+			} else if index, err := strconv.Atoi(elem); err == nil {
+				res = append(res, docPathPart{index: index, isIndex: true})
+			} else {
+				return nil, newDocPathError(path, "unsupported element `["+elem+"]`")
+			}
+			i += end + 1
+
+		default:
+			return nil, newDocPathError(path, "unexpected character `"+path[i:i+1]+"`")
+		}
+	}
+	return res, nil
+}
+
+// docPathCtx returns the contexts of the elements of the path.
+func docPathCtx(parts []docPathPart) []*CDTContext {
+	ctx := make([]*CDTContext, len(parts))
+	for i := range parts {
+		ctx[i] = parts[i].ctx()
+	}
+	return ctx
+}
+
+// docGet reads the value at the path of the document stored in the bin.
+func docGet(clnt ClientIfc, policy *BasePolicy, key *Key, binName, path string) (interface{}, Error) {
+	parts, err := parseDocPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	op := GetBinOp(binName)
+	if n := len(parts); n > 0 {
+		ctx, last := docPathCtx(parts[:n-1]), parts[n-1]
+		if last.isIndex {
+			op = ListGetByIndexOp(binName, last.index, ListReturnTypeValue, ctx...)
+		} else {
+			op = MapGetByKeyOp(binName, last.key, MapReturnType.VALUE, ctx...)
+		}
+	}
+
+	wpolicy := *clnt.GetDefaultWritePolicy()
+	wpolicy.BasePolicy = *clnt.getUsablePolicy(policy)
+
+	rec, err := clnt.Operate(&wpolicy, key, op)
+	if err != nil {
+		return nil, err
+	}
+	return rec.Bins[binName], nil
+}
+
+// docSet sets the value at the path of the document stored in the bin.
+func docSet(clnt ClientIfc, policy *WritePolicy, key *Key, binName, path string, value interface{}) Error {
+	parts, err := parseDocPath(path)
+	if err != nil {
+		return err
+	}
+
+	op := PutOp(NewBin(binName, value))
+	if n := len(parts); n > 0 {
+		ctx, last := docPathCtx(parts[:n-1]), parts[n-1]
+		if last.isIndex {
+			op = ListSetOp(binName, last.index, value, ctx...)
+		} else {
+			op = MapPutOp(DefaultMapPolicy(), binName, last.key, value, ctx...)
+		}
+	}
+
+	_, err = clnt.Operate(policy, key, op)
+	return err
+}
+
+// docAppend appends the value to the list at the path of the document stored in the bin.
+func docAppend(clnt ClientIfc, policy *WritePolicy, key *Key, binName, path string, value interface{}) Error {
+	parts, err := parseDocPath(path)
+	if err != nil {
+		return err
+	}
+
+	_, err = clnt.Operate(policy, key, ListAppendWithPolicyContextOp(DefaultListPolicy(), binName, docPathCtx(parts), value))
+	return err
+}
+
+// docDelete removes the element at the path of the document stored in the bin.
+func docDelete(clnt ClientIfc, policy *WritePolicy, key *Key, binName, path string) Error {
+	parts, err := parseDocPath(path)
+	if err != nil {
+		return err
+	}
+
+	op := PutOp(NewBin(binName, nil))
+	if n := len(parts); n > 0 {
+		ctx, last := docPathCtx(parts[:n-1]), parts[n-1]
+		if last.isIndex {
+			op = ListRemoveOp(binName, last.index, ctx...)
+		} else {
+			op = MapRemoveByKeyOp(binName, last.key, MapReturnType.NONE, ctx...)
+		}
+	}
+
+	_, err = clnt.Operate(policy, key, op)
+	return err
+}
+
+// DocGet reads the value at the path of the JSON document stored in the map bin.
+// See the package documentation of the document API for the supported paths.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) DocGet(policy *BasePolicy, key *Key, binName string, path string) (interface{}, Error) {
+	return docGet(clnt, policy, key, binName, path)
+}
+
+// DocSet sets the value at the path of the JSON document stored in the map bin.
+// The path `$` replaces the whole document. The parent of the value must exist.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) DocSet(policy *WritePolicy, key *Key, binName string, path string, value interface{}) Error {
+	return docSet(clnt, policy, key, binName, path, value)
+}
+
+// DocAppend appends the value to the list at the path of the JSON document stored in the map bin.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) DocAppend(policy *WritePolicy, key *Key, binName string, path string, value interface{}) Error {
+	return docAppend(clnt, policy, key, binName, path, value)
+}
+
+// DocDelete removes the element at the path of the JSON document stored in the map bin.
+// The path `$` removes the whole document.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) DocDelete(policy *WritePolicy, key *Key, binName string, path string) Error {
+	return docDelete(clnt, policy, key, binName, path)
+}
@@ -0,0 +1,50 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Document path tests", func() {
+
+	gg.It("must parse the supported paths", func() {
+		parts, err := parseDocPath("$")
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(parts).To(gm.BeEmpty())
+
+		parts, err = parseDocPath(`$.store.book[0]['a.b']["c"][-1]`)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(parts).To(gm.Equal([]docPathPart{
+			{key: "store"},
+			{key: "book"},
+			{index: 0, isIndex: true},
+			{key: "a.b"},
+			{key: "c"},
+			{index: -1, isIndex: true},
+		}))
+	})
+
+	gg.It("must reject the unsupported paths", func() {
+		for _, path := range []string{"", "store", "$store", "$.store.*", "$..book", "$.book[", "$.book[*]", "$.book[?(@.price)]", "$.book[1:2]"} {
+			_, err := parseDocPath(path)
+			gm.Expect(err).To(gm.HaveOccurred(), path)
+			gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+		}
+	})
+})
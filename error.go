@@ -19,9 +19,12 @@ import (
 	"errors"
 	"fmt"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	iatomic "github.com/aerospike/aerospike-client-go/v7/internal/atomic"
 	"github.com/aerospike/aerospike-client-go/v7/types"
 	grpc "google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -43,6 +46,16 @@ type Error interface {
 	// but the client is not able to confirm that due an error.
 	IsInDoubt() bool
 
+	// IsRetryable returns true if the command failed with a transient error, and may
+	// succeed if retried. See types.ResultCode.IsRetryable for the classification.
+	IsRetryable() bool
+
+	// IsClientError returns true if the error was generated by the client.
+	IsClientError() bool
+
+	// IsServerError returns true if the error was returned by the server.
+	IsServerError() bool
+
 	// resultCode returns the error result code.
 	resultCode() types.ResultCode
 
@@ -52,12 +65,30 @@ type Error interface {
 	// Trace returns a stack trace of where the error originates from
 	Trace() string
 
+	// Attempts returns the failed attempts of the command that returned the error,
+	// in the order they were made. It returns nil if the command was not retried.
+	Attempts() []CommandAttempt
+
+	// Namespace returns the namespace of the record the failed command was sent for, if available.
+	Namespace() string
+
+	// SetName returns the set name of the record the failed command was sent for, if available.
+	SetName() string
+
+	// Digest returns the digest of the record the failed command was sent for, or nil.
+	Digest() []byte
+
+	// CommandType returns the type of the failed command, e.g. "get" or "operate", if available.
+	CommandType() string
+
 	iter(int) Error
 	setInDoubt(bool, int) Error
 	setNode(*Node) Error
 	markInDoubt(bool) Error
 	markInDoubtIf(bool) Error
 	wrap(error) Error
+	setAttempts([]CommandAttempt) Error
+	setCommand(transactionType, *Key) Error
 }
 
 // AerospikeError implements Error interface for aerospike specific errors.
@@ -109,8 +140,17 @@ type AerospikeError struct {
 	// Iteration determies on which retry the error occurred
 	Iteration int
 
-	// Includes stack frames for the error
-	stackFrames []stackFrame
+	// Includes the program counters of the stack frames for the error.
+	// They are resolved lazily in Trace().
+	stack []uintptr
+
+	// the failed attempts of the command
+	attempts []CommandAttempt
+
+	// the command and the record it was sent for
+	cmdType            transactionType
+	namespace, setName string
+	digest             []byte
 }
 
 var _ error = &AerospikeError{}
@@ -124,7 +164,7 @@ func newError(code types.ResultCode, messages ...string) Error {
 		messages = []string{types.ResultCodeToString(code)}
 	}
 
-	return &AerospikeError{msg: strings.Join(messages, " "), ResultCode: code, stackFrames: stackTrace(nil)}
+	return &AerospikeError{msg: strings.Join(messages, " "), ResultCode: code, stack: stackTrace(code)}
 }
 
 func newErrorAndWrap(e error, code types.ResultCode, messages ...string) Error {
@@ -208,6 +248,30 @@ func newGrpcError(isWrite bool, e error, messages ...string) Error {
 	return ne
 }
 
+// CommandAttempt describes a failed attempt of a command, so that the errors of all the
+// retries can be inspected after the command fails, and not only the last one.
+type CommandAttempt struct {
+	// Iteration is the 1-based number of the attempt.
+	Iteration int
+
+	// Node is the node the attempt was sent to, or nil if no node could be selected.
+	Node *Node
+
+	// Latency is the time from the start of the attempt to its failure.
+	Latency time.Duration
+
+	// ServerTimeout is the server timeout sent with the attempt, computed from the time
+	// remaining until the deadline of the command. It is 0 if the attempt was not sent,
+	// or if the command has no deadline.
+	ServerTimeout time.Duration
+
+	// ResultCode is the result code of the failure.
+	ResultCode types.ResultCode
+
+	// Err is the error of the attempt.
+	Err Error
+}
+
 // SetInDoubt sets whether it is possible that the write transaction may have completed
 // even though this error was generated.  This may be the case when a
 // client error occurs (like timeout) after the command was sent to the server.
@@ -238,9 +302,21 @@ func (ase *AerospikeError) resultCode() types.ResultCode {
 // Trace returns a stack trace of where the error originates from
 func (ase *AerospikeError) Trace() string {
 	var sb strings.Builder
-	for i := range ase.stackFrames {
-		sb.WriteString(ase.stackFrames[i].String())
-		sb.WriteString("\n")
+	if len(ase.stack) > 0 {
+		frames := runtime.CallersFrames(ase.stack)
+		for {
+			frame, more := frames.Next()
+			sFrame := stackFrame{
+				fl: frame.File,
+				fn: frame.Function,
+				ln: frame.Line,
+			}
+			sb.WriteString(sFrame.String())
+			sb.WriteString("\n")
+			if !more {
+				break
+			}
+		}
 	}
 
 	if ase.wrapped != nil {
@@ -254,14 +330,77 @@ func (ase *AerospikeError) Trace() string {
 	return sb.String()
 }
 
+// Attempts returns the failed attempts of the command that returned the error,
+// in the order they were made. It returns nil if the command was not retried.
+func (ase *AerospikeError) Attempts() []CommandAttempt {
+	for e := error(ase); e != nil; e = errors.Unwrap(e) {
+		if ae, ok := e.(*AerospikeError); ok && ae.attempts != nil {
+			return ae.attempts
+		}
+	}
+	return nil
+}
+
+func (ase *AerospikeError) setAttempts(attempts []CommandAttempt) Error {
+	ase.attempts = attempts
+	return ase
+}
+
+// Namespace returns the namespace of the record the failed command was sent for, if available.
+func (ase *AerospikeError) Namespace() string {
+	return ase.namespace
+}
+
+// SetName returns the set name of the record the failed command was sent for, if available.
+func (ase *AerospikeError) SetName() string {
+	return ase.setName
+}
+
+// Digest returns the digest of the record the failed command was sent for, or nil.
+func (ase *AerospikeError) Digest() []byte {
+	return ase.digest
+}
+
+// CommandType returns the type of the failed command, e.g. "get" or "operate", if available.
+func (ase *AerospikeError) CommandType() string {
+	return ase.cmdType.String()
+}
+
+func (ase *AerospikeError) setCommand(tt transactionType, key *Key) Error {
+	ase.cmdType = tt
+	if key != nil {
+		ase.namespace = key.namespace
+		ase.setName = key.setName
+		ase.digest = key.digest[:]
+	}
+	return ase
+}
+
+// commandString returns the command and record metadata of the error for its message.
+func (ase *AerospikeError) commandString() string {
+	if ase.cmdType == ttNone && ase.digest == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	if ase.cmdType != ttNone {
+		sb.WriteString(", Command: ")
+		sb.WriteString(ase.cmdType.String())
+	}
+	if ase.digest != nil {
+		fmt.Fprintf(&sb, ", Record: %s:%s:%x", ase.namespace, ase.setName, ase.digest)
+	}
+	return sb.String()
+}
+
 // Error implements the error interface
 func (ase *AerospikeError) Error() string {
-	const cErr = "ResultCode: %s, Iteration: %d, InDoubt: %t, Node: %s: %s"
+	const cErr = "ResultCode: %s, Iteration: %d, InDoubt: %t, Node: %s%s: %s"
 	const cErrNL = cErr + "\n  %s"
 	if ase.wrapped != nil {
-		return fmt.Sprintf(cErrNL, ase.ResultCode.String(), ase.Iteration, ase.InDoubt, ase.Node, ase.msg, ase.wrapped.Error())
+		return fmt.Sprintf(cErrNL, ase.ResultCode.String(), ase.Iteration, ase.InDoubt, ase.Node, ase.commandString(), ase.msg, ase.wrapped.Error())
 	}
-	return fmt.Sprintf(cErr, ase.ResultCode.String(), ase.Iteration, ase.InDoubt, ase.Node, ase.msg)
+	return fmt.Sprintf(cErr, ase.ResultCode.String(), ase.Iteration, ase.InDoubt, ase.Node, ase.commandString(), ase.msg)
 }
 
 func (ase *AerospikeError) wrap(err error) Error {
@@ -284,6 +423,23 @@ func (ase *AerospikeError) IsInDoubt() bool {
 	return ase.InDoubt
 }
 
+// IsRetryable returns true if the command failed with a transient error, and may
+// succeed if retried. Writes may still have been applied; check IsInDoubt before
+// retrying writes that are not idempotent.
+func (ase *AerospikeError) IsRetryable() bool {
+	return ase.ResultCode.IsRetryable()
+}
+
+// IsClientError returns true if the error was generated by the client.
+func (ase *AerospikeError) IsClientError() bool {
+	return ase.ResultCode.IsClientError()
+}
+
+// IsServerError returns true if the error was returned by the server.
+func (ase *AerospikeError) IsServerError() bool {
+	return ase.ResultCode.IsServerError()
+}
+
 // Matches returns true if the error or any of its wrapped errors contains
 // any of the passed results codes.
 // For convenience, it will return false if the error is nil.
@@ -300,6 +456,10 @@ func (ase *AerospikeError) Matches(rcs ...types.ResultCode) bool {
 		}
 	}
 
+	if be, ok := ase.wrapped.(*BatchError); ok {
+		return be.Matches(rcs...)
+	}
+
 	ae := &AerospikeError{}
 	if ase.wrapped != nil && errors.As(ase.wrapped, &ae) {
 		return ae.Matches(rcs...)
@@ -320,6 +480,11 @@ func (ase *AerospikeError) As(target interface{}) bool {
 	ae.ResultCode = ase.ResultCode
 	ae.InDoubt = ase.InDoubt
 	ae.Node = ase.Node
+	ae.attempts = ase.attempts
+	ae.cmdType = ase.cmdType
+	ae.namespace = ase.namespace
+	ae.setName = ase.setName
+	ae.digest = ase.digest
 	return true
 }
 
@@ -401,6 +566,170 @@ func newInvalidNodeError(clusterSize int, partition *Partition) Error {
 	return res
 }
 
+/*
+	UDF Error
+*/
+
+// UDFError describes the failure of a UDF call on a record.
+// The Error returned for the call wraps it, and it can be extracted with errors.As:
+//
+//	udfErr := &as.UDFError{}
+//	if errors.As(br.Err, &udfErr) {
+//	    println(udfErr.FunctionName, udfErr.Message)
+//	}
+type UDFError struct {
+	// PackageName is the Lua module of the UDF.
+	PackageName string
+
+	// FunctionName is the name of the UDF.
+	FunctionName string
+
+	// Message is the error message of the UDF, as returned by the server.
+	Message string
+}
+
+// Error implements the error interface
+func (ue *UDFError) Error() string {
+	return fmt.Sprintf("UDF %s.%s failed: %s", ue.PackageName, ue.FunctionName, ue.Message)
+}
+
+func newUDFError(code types.ResultCode, packageName, functionName, msg string) Error {
+	if msg == "" {
+		msg = types.ResultCodeToString(code)
+	}
+	return newErrorAndWrap(&UDFError{PackageName: packageName, FunctionName: functionName, Message: msg}, code, msg)
+}
+
+/*
+	Server Feature Error
+*/
+
+// ServerFeatureError describes a command that requires a newer server version than
+// some of the nodes in the cluster are running. The command is not sent to the server.
+// The Error returned for the command has the UNSUPPORTED_FEATURE result code, matches
+// ErrUnsupportedServerFeature with errors.Is, and wraps the ServerFeatureError:
+//
+//	featureErr := &as.ServerFeatureError{}
+//	if errors.As(err, &featureErr) {
+//	    println(featureErr.Feature, featureErr.MinVersion)
+//	}
+type ServerFeatureError struct {
+	// Feature is the name of the feature required by the command.
+	Feature string
+
+	// MinVersion is the minimum server version supporting the feature.
+	MinVersion string
+
+	// NodeVersions maps the names of the nodes that do not support the
+	// feature to their server versions.
+	NodeVersions map[string]string
+}
+
+// Error implements the error interface
+func (fe *ServerFeatureError) Error() string {
+	names := make([]string, 0, len(fe.NodeVersions))
+	for name := range fe.NodeVersions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString(fe.Feature)
+	sb.WriteString(" requires server version ")
+	sb.WriteString(fe.MinVersion)
+	sb.WriteString("+, but found nodes:")
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(" ")
+		sb.WriteString(name)
+		sb.WriteString("=")
+		sb.WriteString(fe.NodeVersions[name])
+	}
+	return sb.String()
+}
+
+func newServerFeatureError(feature, minVersion string, nodeVersions map[string]string) Error {
+	fe := &ServerFeatureError{Feature: feature, MinVersion: minVersion, NodeVersions: nodeVersions}
+	return newErrorAndWrap(fe, types.UNSUPPORTED_FEATURE, fe.Error())
+}
+
+/*
+	Batch Error
+*/
+
+// BatchError is wrapped by the Error returned from a batch command when the sub-batches
+// of more than one node fail. It contains the error of each failed sub-batch,
+// which includes the node it was sent to.
+// BatchError implements the multi-error Unwrap method, so errors.Is and errors.As
+// check all of the errors it contains:
+//
+//	batchErr := &as.BatchError{}
+//	if errors.As(err, &batchErr) {
+//	    for _, e := range batchErr.Errors {
+//	        ...
+//	    }
+//	}
+type BatchError struct {
+	// Errors contains the errors of the failed sub-batches, in the order they occurred.
+	Errors []Error
+}
+
+// Error implements the error interface
+func (be *BatchError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(strconv.Itoa(len(be.Errors)))
+	sb.WriteString(" sub-batches failed:")
+	for i := range be.Errors {
+		sb.WriteString("\n  ")
+		sb.WriteString(be.Errors[i].Error())
+	}
+	return sb.String()
+}
+
+// Unwrap returns the errors of the failed sub-batches.
+func (be *BatchError) Unwrap() []error {
+	res := make([]error, len(be.Errors))
+	for i := range be.Errors {
+		res[i] = be.Errors[i]
+	}
+	return res
+}
+
+// Matches returns true if any of the errors of the failed sub-batches
+// contains any of the passed results codes.
+func (be *BatchError) Matches(rcs ...types.ResultCode) bool {
+	for i := range be.Errors {
+		if be.Errors[i].Matches(rcs...) {
+			return true
+		}
+	}
+	return false
+}
+
+// newBatchError returns the error for the failed sub-batches of a batch command.
+// If only one sub-batch failed, its error is returned as is.
+// Otherwise, the returned error takes the result code of the first error, is
+// in doubt if any of the errors are, and wraps a BatchError.
+func newBatchError(errs []Error) Error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+
+	inDoubt := false
+	for i := range errs {
+		inDoubt = inDoubt || errs[i].IsInDoubt()
+	}
+
+	res := newError(errs[0].resultCode(), fmt.Sprintf("Batch command failed on %d nodes", len(errs)))
+	res.wrap(&BatchError{Errors: errs})
+	return res.markInDoubt(inDoubt)
+}
+
 /*
 	constAerospikeError
 */
@@ -455,8 +784,14 @@ var (
 	ErrMaxRetriesExceeded              = newConstError(types.MAX_RETRIES_EXCEEDED, "command execution timed out on client: Exceeded number of retries. See `Policy.MaxRetries`.")
 	ErrInvalidParam                    = newConstError(types.PARAMETER_ERROR)
 	ErrLuaPoolEmpty                    = newConstError(types.COMMON_ERROR, "Error fetching a lua instance from pool")
+	ErrBatchAborted                    = newConstError(types.BATCH_FAILED, "batch command was aborted due to an error in another node's sub-batch. See `BatchPolicy.AbortOnFirstError`")
+	ErrClientClosing                   = newConstError(types.COMMON_ERROR, "client is closing, and does not accept new commands. See `Client.CloseGracefully`")
+	ErrLoginThrottled                  = newConstError(types.NOT_AUTHENTICATED, "login was not attempted because the previous logins were rejected, or too many logins are in progress. See `ClientPolicy.LoginPolicy`")
+	ErrUnsupportedServerFeature        = newConstError(types.UNSUPPORTED_FEATURE, "the command requires a newer server version. See `ServerFeatureError`")
 
 	errGRPCStreamEnd = newError(types.OK, "GRPC Steam was ended successfully")
+
+	errCommandCancelled = newError(types.TIMEOUT, "command was cancelled because its result is no longer needed")
 )
 
 //revive:enable
@@ -504,25 +839,62 @@ func (st *stackFrame) String() string {
 	return st.fl + ":" + strconv.Itoa(st.ln) + " " + st.fn + "()"
 }
 
-func stackTrace(err Error) []stackFrame {
-	const maxDepth = 10
-	sFrames := make([]stackFrame, 0, maxDepth)
-	for i := 3; i <= maxDepth+3; i++ {
-		pc, fl, ln, ok := runtime.Caller(i)
-		if !ok {
-			break
-		}
-		fn := runtime.FuncForPC(pc)
-		sFrame := stackFrame{
-			fl: fl,
-			fn: fn.Name(),
-			ln: ln,
+// StackTraceMode determines when the stack trace of an error is collected.
+type StackTraceMode int
+
+const (
+	// StackTraceAlways collects the stack trace of every error. This is the default.
+	StackTraceAlways StackTraceMode = iota
+
+	// StackTraceUnexpected does not collect the stack trace of errors that are
+	// expected in the normal course of business, like KEY_NOT_FOUND_ERROR,
+	// KEY_EXISTS_ERROR, GENERATION_ERROR or FILTERED_OUT.
+	StackTraceUnexpected
+
+	// StackTraceNever does not collect the stack trace of any error.
+	StackTraceNever
+)
+
+var stackTraceMode = iatomic.NewInt(int(StackTraceAlways))
+
+// SetStackTraceMode sets when the client collects the stack trace of the errors it returns.
+// Collecting the stack trace is relatively costly, and can be disabled
+// for applications with high throughput. AerospikeError.Trace() returns an
+// empty string for errors without a stack trace.
+// This function is safe to call concurrently with commands.
+func SetStackTraceMode(mode StackTraceMode) {
+	stackTraceMode.Set(int(mode))
+}
+
+// expectedResultCodes are the result codes that are returned in the normal course of business,
+// and do not indicate a problem in the application or the cluster.
+var expectedResultCodes = map[types.ResultCode]struct{}{
+	types.KEY_NOT_FOUND_ERROR:    {},
+	types.KEY_EXISTS_ERROR:       {},
+	types.GENERATION_ERROR:       {},
+	types.FILTERED_OUT:           {},
+	types.BIN_EXISTS_ERROR:       {},
+	types.BIN_NOT_FOUND:          {},
+	types.FAIL_ELEMENT_NOT_FOUND: {},
+	types.FAIL_ELEMENT_EXISTS:    {},
+}
+
+func stackTrace(code types.ResultCode) []uintptr {
+	switch StackTraceMode(stackTraceMode.Get()) {
+	case StackTraceNever:
+		return nil
+	case StackTraceUnexpected:
+		if _, exists := expectedResultCodes[code]; exists {
+			return nil
 		}
-		sFrames = append(sFrames, sFrame)
 	}
 
-	if len(sFrames) > 0 {
-		return sFrames
+	const maxDepth = 10
+	var pcs [maxDepth + 1]uintptr
+	// skip runtime.Callers, stackTrace, newError and its immediate caller
+	n := runtime.Callers(4, pcs[:])
+	if n > 0 {
+		return append([]uintptr(nil), pcs[:n]...)
 	}
 	return nil
 }
@@ -50,6 +50,53 @@ var _ = gg.Describe("Aerospike Error Tests", func() {
 
 	})
 
+	gg.Context("Classification", func() {
+
+		gg.It("should classify the errors by result code", func() {
+			err := ErrTimeout.err()
+			gm.Expect(err.IsRetryable()).To(gm.BeTrue())
+
+			err = newError(ast.KEY_BUSY)
+			gm.Expect(err.IsRetryable()).To(gm.BeTrue())
+			gm.Expect(err.IsServerError()).To(gm.BeTrue())
+			gm.Expect(err.IsClientError()).To(gm.BeFalse())
+
+			err = newError(ast.PARAMETER_ERROR)
+			gm.Expect(err.IsRetryable()).To(gm.BeFalse())
+
+			err = newWrapNetworkError(errors.New("reset"))
+			gm.Expect(err.IsRetryable()).To(gm.BeTrue())
+			gm.Expect(err.IsClientError()).To(gm.BeTrue())
+			gm.Expect(err.IsServerError()).To(gm.BeFalse())
+		})
+	})
+
+	gg.Context("UDFError", func() {
+
+		gg.It("should be extracted with errors.As", func() {
+			err := newUDFError(ast.UDF_BAD_RESPONSE, "pkg", "fn", "boom")
+			gm.Expect(err.Matches(ast.UDF_BAD_RESPONSE)).To(gm.BeTrue())
+
+			udfErr := &UDFError{}
+			gm.Expect(errors.As(err, &udfErr)).To(gm.BeTrue())
+			gm.Expect(*udfErr).To(gm.Equal(UDFError{PackageName: "pkg", FunctionName: "fn", Message: "boom"}))
+		})
+
+		gg.It("should be set on the batch record with the failure message", func() {
+			br := newSimpleBatchRecord(nil, true)
+			br.setRecord(newRecord(nil, nil, BinMap{"FAILURE": "boom"}, 1, 0))
+			br.setUDFError(nil, ast.UDF_BAD_RESPONSE, "pkg", "fn", false)
+
+			gm.Expect(br.ResultCode).To(gm.Equal(ast.UDF_BAD_RESPONSE))
+			gm.Expect(br.Record).ToNot(gm.BeNil())
+			gm.Expect(br.UDFResult()).To(gm.BeNil())
+
+			udfErr := &UDFError{}
+			gm.Expect(errors.As(br.Err, &udfErr)).To(gm.BeTrue())
+			gm.Expect(udfErr.Message).To(gm.Equal("boom"))
+		})
+	})
+
 	gg.Context("chainErrors()", func() {
 
 		gg.It("should handle nil for inner error", func() {
@@ -142,4 +189,60 @@ var _ = gg.Describe("Aerospike Error Tests", func() {
 
 	})
 
+	gg.Context("Stack traces", func() {
+
+		gg.AfterEach(func() {
+			SetStackTraceMode(StackTraceAlways)
+		})
+
+		gg.It("should collect the stack trace by default", func() {
+			err := newError(ast.KEY_NOT_FOUND_ERROR)
+			gm.Expect(err.Trace()).ToNot(gm.BeEmpty())
+		})
+
+		gg.It("should not collect the stack trace of expected errors if asked", func() {
+			SetStackTraceMode(StackTraceUnexpected)
+			gm.Expect(newError(ast.KEY_NOT_FOUND_ERROR).Trace()).To(gm.BeEmpty())
+			gm.Expect(newError(ast.TIMEOUT).Trace()).ToNot(gm.BeEmpty())
+		})
+
+		gg.It("should not collect any stack traces if disabled", func() {
+			SetStackTraceMode(StackTraceNever)
+			gm.Expect(newError(ast.KEY_NOT_FOUND_ERROR).Trace()).To(gm.BeEmpty())
+			gm.Expect(newError(ast.TIMEOUT).Trace()).To(gm.BeEmpty())
+		})
+
+	})
+
+	gg.Context("BatchError", func() {
+
+		gg.It("should return the error of a single failed sub-batch as is", func() {
+			err := newError(ast.TIMEOUT)
+			gm.Expect(newBatchError([]Error{err})).To(gm.BeIdenticalTo(err))
+			gm.Expect(newBatchError(nil)).To(gm.BeNil())
+		})
+
+		gg.It("should contain the errors of all failed sub-batches", func() {
+			node1, node2 := &Node{name: "BB9000000000001"}, &Node{name: "BB9000000000002"}
+			err1 := newCustomNodeError(node1, ast.TIMEOUT)
+			err2 := newCustomNodeError(node2, ast.DEVICE_OVERLOAD).markInDoubt(true)
+			err := newBatchError([]Error{err1, err2})
+
+			gm.Expect(err.Matches(ast.TIMEOUT)).To(gm.BeTrue())
+			gm.Expect(err.Matches(ast.DEVICE_OVERLOAD)).To(gm.BeTrue())
+			gm.Expect(err.Matches(ast.KEY_NOT_FOUND_ERROR)).To(gm.BeFalse())
+			gm.Expect(err.IsInDoubt()).To(gm.BeTrue())
+
+			gm.Expect(errors.Is(err, &AerospikeError{ResultCode: ast.DEVICE_OVERLOAD})).To(gm.BeTrue())
+			gm.Expect(errors.Is(err, &AerospikeError{ResultCode: ast.TIMEOUT, Node: node1})).To(gm.BeTrue())
+			gm.Expect(errors.Is(err, &AerospikeError{ResultCode: ast.TIMEOUT, Node: node2})).To(gm.BeFalse())
+
+			batchErr := &BatchError{}
+			gm.Expect(errors.As(err, &batchErr)).To(gm.BeTrue())
+			gm.Expect(batchErr.Errors).To(gm.Equal([]Error{err1, err2}))
+			gm.Expect(err.Error()).To(gm.ContainSubstring("2 sub-batches failed"))
+		})
+
+	})
+
 }) // Describe
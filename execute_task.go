@@ -52,6 +52,10 @@ func (etsk *ExecuteTask) TaskId() uint64 {
 
 // IsDone queries all nodes for task completion status.
 func (etsk *ExecuteTask) IsDone() (bool, Error) {
+	if etsk.done {
+		return true, nil
+	}
+
 	if etsk.clnt != nil {
 		return etsk.grpcIsDone()
 	}
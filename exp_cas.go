@@ -0,0 +1,77 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	ParticleType "github.com/aerospike/aerospike-client-go/v7/types/particle_type"
+)
+
+// ExpUnchangedSince creates an expression that is true if the record was not updated after lastUpdate.
+// Use it as the filter expression of a write to only apply the write if the record
+// was not modified since it was read.
+// Note that the last update time is kept by the server in nanoseconds since the Unix epoch.
+//
+// Requires server version 5.2+
+func ExpUnchangedSince(lastUpdate time.Time) *Expression {
+	return ExpLessEq(ExpLastUpdate(), ExpIntVal(lastUpdate.UnixNano()))
+}
+
+// ExpBinEquals creates an expression that is true if the bin holds the value.
+// The type of the bin expression is derived from the value. A nil value is true if the bin does not exist.
+// Use it as the filter expression of a write to only apply the write if the bin
+// still holds the value that was read before.
+//
+// ExpBinEquals panics for value types that are not supported by NewValue.
+//
+// Requires server version 5.2+
+func ExpBinEquals(name string, value interface{}) *Expression {
+	if value == nil {
+		return ExpNot(ExpBinExists(name))
+	}
+
+	v := NewValue(value)
+	var typ ExpType
+	switch v.GetType() {
+	case ParticleType.NULL:
+		return ExpNot(ExpBinExists(name))
+	case ParticleType.INTEGER:
+		typ = ExpTypeINT
+	case ParticleType.FLOAT:
+		typ = ExpTypeFLOAT
+	case ParticleType.STRING:
+		typ = ExpTypeSTRING
+	case ParticleType.BOOL:
+		typ = ExpTypeBOOL
+	case ParticleType.LIST:
+		typ = ExpTypeLIST
+	case ParticleType.MAP:
+		typ = ExpTypeMAP
+	case ParticleType.GEOJSON:
+		typ = ExpTypeGEO
+	case ParticleType.HLL:
+		typ = ExpTypeHLL
+	default:
+		typ = ExpTypeBLOB
+	}
+
+	bin := newFilterExpression(&expOpBIN, StringValue(name), nil, nil, &typ, nil)
+	if typ == ExpTypeLIST {
+		// literal lists must be quoted, otherwise they are evaluated as expressions
+		return ExpEq(bin, newFilterExpression(&expOpQUOTED, v, nil, nil, nil, nil))
+	}
+	return ExpEq(bin, newFilterExpression(nil, v, nil, nil, nil, nil))
+}
@@ -88,6 +88,18 @@ func ExpReadOp(name string, exp *Expression, flags ExpReadFlags) *Operation {
 	}
 }
 
+// lastUpdateTimeBin is the name of the result of the operation reading the last update time of the record.
+// It is removed from the bins of the record, and set as Record.LastUpdateTime.
+const lastUpdateTimeBin = "__lut"
+
+// LastUpdateTimeOp creates an operation that reads the last update time of the record.
+// The result is set in Record.LastUpdateTime instead of the bins of the record.
+// It can be passed to Operate and batch reads along with other read operations.
+// Requires server v5.2+.
+func LastUpdateTimeOp() *Operation {
+	return ExpReadOp(lastUpdateTimeBin, ExpLastUpdate(), ExpReadFlagDefault)
+}
+
 // newExpOperationEncoder is used to encode the operation expression wire protocol
 func encodeExpOperation(exp *Expression, flags int) ([]byte, Error) {
 	// expression is double packed: first normally, and then as a BLOB in operation
@@ -16,6 +16,7 @@ package aerospike
 
 import (
 	"encoding/base64"
+	"fmt"
 
 	"github.com/aerospike/aerospike-client-go/v7/types"
 	ParticleType "github.com/aerospike/aerospike-client-go/v7/types/particle_type"
@@ -54,6 +55,33 @@ var (
 	ExpTypeHLL ExpType = 9
 )
 
+// String implements the Stringer interface.
+func (et ExpType) String() string {
+	switch et {
+	case ExpTypeNIL:
+		return "NIL"
+	case ExpTypeBOOL:
+		return "BOOL"
+	case ExpTypeINT:
+		return "INT"
+	case ExpTypeSTRING:
+		return "STRING"
+	case ExpTypeLIST:
+		return "LIST"
+	case ExpTypeMAP:
+		return "MAP"
+	case ExpTypeBLOB:
+		return "BLOB"
+	case ExpTypeFLOAT:
+		return "FLOAT"
+	case ExpTypeGEO:
+		return "GEO"
+	case ExpTypeHLL:
+		return "HLL"
+	}
+	return fmt.Sprintf("ExpType(%d)", uint(et))
+}
+
 type expOp uint
 
 var (
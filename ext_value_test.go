@@ -0,0 +1,84 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("MessagePack extensions", func() {
+
+	gg.AfterEach(func() {
+		SetValuePolicy(nil)
+	})
+
+	pack := func(v interface{}) []byte {
+		p := newPacker()
+		n, err := packObject(p, v, false)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		size, _ := packObject(nil, v, false)
+		gm.Expect(n).To(gm.Equal(size))
+		return p.Bytes()
+	}
+
+	unpack := func(buf []byte) []interface{} {
+		res, err := newUnpacker(buf, 0, len(buf)).UnpackList()
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		return res
+	}
+
+	gg.It("must pack the extensions with the smallest header", func() {
+		gm.Expect(pack(NewExtValue(5, []byte{1}))).To(gm.Equal([]byte{0xd4, 5, 1}))
+		gm.Expect(pack(NewExtValue(5, []byte{1, 2, 3, 4}))).To(gm.Equal([]byte{0xd6, 5, 1, 2, 3, 4}))
+		gm.Expect(pack(NewExtValue(5, []byte{1, 2, 3}))).To(gm.Equal([]byte{0xc7, 3, 5, 1, 2, 3}))
+		gm.Expect(pack(NewExtValue(5, nil))).To(gm.Equal([]byte{0xc7, 0, 5}))
+		gm.Expect(pack(NewExtValue(5, make([]byte, 300)))[:4]).To(gm.Equal([]byte{0xc8, 0x01, 0x2c, 5}))
+		gm.Expect(pack(NewExtValue(5, make([]byte, 70000)))[:6]).To(gm.Equal([]byte{0xc9, 0, 1, 0x11, 0x70, 5}))
+	})
+
+	gg.It("must skip the extensions by default", func() {
+		buf := pack([]interface{}{NewExtValue(5, []byte{1, 2, 3}), 1, 2})
+		gm.Expect(unpack(buf)).To(gm.Equal([]interface{}{1, 2}))
+	})
+
+	gg.It("must decode the extensions when enabled", func() {
+		SetValuePolicy(&ValuePolicy{DecodeExtensions: true})
+
+		large := bytes.Repeat([]byte{7}, 300)
+		buf := pack([]interface{}{NewExtValue(5, []byte{1, 2, 3}), NewWildCardValue(), NewInfinityValue(), NewExtValue(9, large), 2})
+		gm.Expect(unpack(buf)).To(gm.Equal([]interface{}{
+			NewExtValue(5, []byte{1, 2, 3}),
+			NewWildCardValue(),
+			NewInfinityValue(),
+			NewExtValue(9, large),
+			2,
+		}))
+	})
+
+	gg.It("must still skip the collection flags headers", func() {
+		SetValuePolicy(&ValuePolicy{DecodeExtensions: true})
+
+		p := newPacker()
+		packArrayBegin(p, 3)
+		p.Write([]byte{0xc7, 0x00, 0x01})
+		packObject(p, "a", false)
+		packObject(p, "b", false)
+		buf := p.Bytes()
+		gm.Expect(unpack(buf)).To(gm.Equal([]interface{}{"a", "b"}))
+	})
+})
@@ -0,0 +1,205 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"sync"
+	"time"
+)
+
+// FaultType determines the fault a FaultRule injects.
+type FaultType int
+
+const (
+	// FaultConnectionDrop closes the connection after the command is sent,
+	// before its response is read. The command fails with a network error,
+	// and is in doubt if it was a write.
+	FaultConnectionDrop FaultType = iota + 1
+
+	// FaultSlowResponse delays the read of the response of the command by FaultRule.Delay.
+	// The command times out if the delay runs past its total timeout.
+	FaultSlowResponse
+
+	// FaultProtoCorruption corrupts the protocol header of the response of the command.
+	// The command fails with a parse error.
+	FaultProtoCorruption
+
+	// FaultStalePartitionMap skips the refresh of the partition map of the node on a tend,
+	// so that the commands are routed with the stale map until the node is refreshed.
+	FaultStalePartitionMap
+)
+
+// String implements the Stringer interface.
+func (ft FaultType) String() string {
+	switch ft {
+	case FaultConnectionDrop:
+		return "connection-drop"
+	case FaultSlowResponse:
+		return "slow-response"
+	case FaultProtoCorruption:
+		return "proto-corruption"
+	case FaultStalePartitionMap:
+		return "stale-partition-map"
+	}
+	return "unknown"
+}
+
+// FaultRule describes when and how a FaultInjector injects a fault.
+// Rules are deterministic: a rule injects its fault on every Nth event it matches.
+type FaultRule struct {
+	// Type of the fault.
+	Type FaultType
+
+	// Node limits the rule to the node with the name. If empty, the rule applies to all nodes.
+	Node string
+
+	// Command limits the rule to a type of command, e.g. "get" or "batch-read".
+	// If empty, the rule applies to all commands. It is ignored for FaultStalePartitionMap.
+	Command string
+
+	// Every injects the fault on every Nth matching command,
+	// or partition map refresh for FaultStalePartitionMap.
+	// Default: 1, on every matching event
+	Every int
+
+	// Limit is the maximum number of faults the rule injects. If zero, the number is not limited.
+	// Default: 0
+	Limit int
+
+	// Delay of the responses for FaultSlowResponse.
+	Delay time.Duration
+}
+
+type faultRuleState struct {
+	FaultRule
+
+	matched  int
+	injected int
+}
+
+// FaultInjector simulates faults in the communication of the client with the cluster,
+// for testing the behavior of the applications under failures. Set it via ClientPolicy.FaultInjector.
+// Rules can be added and removed while the client is in use.
+//
+// Fault injection is meant for tests only, and must not be enabled in production.
+// Only the native client supports the fault injection.
+type FaultInjector struct {
+	mutex    sync.Mutex
+	rules    []*faultRuleState
+	injected map[FaultType]int
+}
+
+// NewFaultInjector returns a FaultInjector with the rules.
+func NewFaultInjector(rules ...FaultRule) *FaultInjector {
+	fi := &FaultInjector{injected: map[FaultType]int{}}
+	for _, rule := range rules {
+		fi.Add(rule)
+	}
+	return fi
+}
+
+// Add adds a rule to the injector.
+func (fi *FaultInjector) Add(rule FaultRule) *FaultInjector {
+	if rule.Every <= 0 {
+		rule.Every = 1
+	}
+
+	fi.mutex.Lock()
+	defer fi.mutex.Unlock()
+
+	fi.rules = append(fi.rules, &faultRuleState{FaultRule: rule})
+	return fi
+}
+
+// Clear removes all rules of the injector, and resets its counters.
+func (fi *FaultInjector) Clear() {
+	fi.mutex.Lock()
+	defer fi.mutex.Unlock()
+
+	fi.rules = nil
+	fi.injected = map[FaultType]int{}
+}
+
+// Injected returns the number of injected faults of the type.
+func (fi *FaultInjector) Injected(typ FaultType) int {
+	fi.mutex.Lock()
+	defer fi.mutex.Unlock()
+
+	return fi.injected[typ]
+}
+
+// match returns the rules which inject a fault for the event, and advances their counters.
+func (fi *FaultInjector) match(command string, node *Node, stale bool) []FaultRule {
+	if fi == nil {
+		return nil
+	}
+
+	fi.mutex.Lock()
+	defer fi.mutex.Unlock()
+
+	var res []FaultRule
+	for _, rule := range fi.rules {
+		if stale != (rule.Type == FaultStalePartitionMap) {
+			continue
+		}
+		if rule.Node != "" && (node == nil || rule.Node != node.name) {
+			continue
+		}
+		if !stale && rule.Command != "" && rule.Command != command {
+			continue
+		}
+		if rule.Limit > 0 && rule.injected >= rule.Limit {
+			continue
+		}
+
+		rule.matched++
+		if rule.matched%rule.Every != 0 {
+			continue
+		}
+
+		rule.injected++
+		fi.injected[rule.Type]++
+		res = append(res, rule.FaultRule)
+	}
+	return res
+}
+
+// inject applies the faults for a command sent on the connection.
+func (fi *FaultInjector) inject(conn *Connection, command string, node *Node) {
+	for _, rule := range fi.match(command, node, false) {
+		switch rule.Type {
+		case FaultConnectionDrop:
+			conn.interrupt()
+		case FaultSlowResponse:
+			conn.faults.delay += rule.Delay
+		case FaultProtoCorruption:
+			conn.faults.corrupt = true
+		}
+	}
+}
+
+// skipPartitionRefresh returns true if the refresh of the partition map of the node should be skipped.
+func (fi *FaultInjector) skipPartitionRefresh(node *Node) bool {
+	return len(fi.match("", node, true)) > 0
+}
+
+// connectionFaults are the pending faults of a connection.
+type connectionFaults struct {
+	// delay of the next read
+	delay time.Duration
+
+	// corrupts the first byte of the next read
+	corrupt bool
+}
@@ -0,0 +1,108 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"net"
+	"time"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Fault injector", func() {
+
+	node1, node2 := &Node{name: "BB9000000000001"}, &Node{name: "BB9000000000002"}
+
+	// pipeConnection returns a connection whose peer writes the response.
+	pipeConnection := func(response []byte) *Connection {
+		client, server := net.Pipe()
+		go func() {
+			server.Write(response)
+			server.Close()
+		}()
+		return &Connection{conn: client}
+	}
+
+	gg.It("must inject the faults deterministically", func() {
+		fi := NewFaultInjector(
+			FaultRule{Type: FaultConnectionDrop, Every: 2},
+			FaultRule{Type: FaultProtoCorruption, Node: node2.name, Command: "get", Limit: 1},
+			FaultRule{Type: FaultStalePartitionMap, Node: node1.name},
+		)
+
+		var drops []int
+		for i := 0; i < 6; i++ {
+			if faults := fi.match("put", node1, false); len(faults) > 0 {
+				gm.Expect(faults[0].Type).To(gm.Equal(FaultConnectionDrop))
+				drops = append(drops, i)
+			}
+		}
+		gm.Expect(drops).To(gm.Equal([]int{1, 3, 5}))
+		gm.Expect(fi.Injected(FaultConnectionDrop)).To(gm.Equal(3))
+
+		gm.Expect(fi.match("put", node2, false)).To(gm.BeEmpty())
+		gm.Expect(fi.match("get", node2, false)).To(gm.Equal([]FaultRule{{Type: FaultConnectionDrop, Every: 2}, {Type: FaultProtoCorruption, Node: node2.name, Command: "get", Every: 1, Limit: 1}}))
+		gm.Expect(fi.match("get", node2, false)).To(gm.BeEmpty())
+		gm.Expect(fi.Injected(FaultProtoCorruption)).To(gm.Equal(1))
+
+		gm.Expect(fi.skipPartitionRefresh(node1)).To(gm.BeTrue())
+		gm.Expect(fi.skipPartitionRefresh(node2)).To(gm.BeFalse())
+
+		fi.Clear()
+		gm.Expect(fi.skipPartitionRefresh(node1)).To(gm.BeFalse())
+		gm.Expect(fi.Injected(FaultConnectionDrop)).To(gm.BeZero())
+
+		var nilInjector *FaultInjector
+		gm.Expect(nilInjector.skipPartitionRefresh(node1)).To(gm.BeFalse())
+		nilInjector.inject(nil, "get", node1)
+	})
+
+	gg.It("must corrupt and delay the responses", func() {
+		fi := NewFaultInjector(
+			FaultRule{Type: FaultProtoCorruption},
+			FaultRule{Type: FaultSlowResponse, Delay: 50 * time.Millisecond},
+		)
+
+		conn := pipeConnection([]byte{2, 3, 0, 0})
+		fi.inject(conn, "get", node1)
+
+		buf := make([]byte, 4)
+		start := time.Now()
+		n, err := conn.Read(buf, 2)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(n).To(gm.Equal(2))
+		gm.Expect(time.Since(start)).To(gm.BeNumerically(">=", 50*time.Millisecond))
+		gm.Expect(buf[:2]).To(gm.Equal([]byte{2 ^ 0xff, 3}))
+
+		// the faults apply to the next read only
+		start = time.Now()
+		_, err = conn.Read(buf, 2)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(time.Since(start)).To(gm.BeNumerically("<", 50*time.Millisecond))
+		gm.Expect(buf[:2]).To(gm.Equal([]byte{0, 0}))
+	})
+
+	gg.It("must drop the connections", func() {
+		fi := NewFaultInjector(FaultRule{Type: FaultConnectionDrop})
+
+		conn := pipeConnection([]byte{2, 3, 0, 0})
+		fi.inject(conn, "get", node1)
+
+		_, err := conn.Read(make([]byte, 4), 4)
+		gm.Expect(err).To(gm.HaveOccurred())
+		gm.Expect(conn.IsConnected()).To(gm.BeFalse())
+	})
+})
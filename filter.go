@@ -28,6 +28,9 @@ type Filter struct {
 	begin             Value
 	end               Value
 	ctx               []*CDTContext
+
+	// packedCtx is the packed ctx of the prepared query filters, packed once for all the queries.
+	packedCtx []byte
 }
 
 // NewEqualFilter creates a new equality filter instance for query.
@@ -161,6 +164,13 @@ func (fltr *Filter) grpcPackCtxPayload() []byte {
 // Retrieve packed Context.
 // For internal use only.
 func (fltr *Filter) packCtx(cmd BufferEx) (sz int, err Error) {
+	if fltr.packedCtx != nil {
+		if cmd != nil {
+			return cmd.Write(fltr.packedCtx)
+		}
+		return len(fltr.packedCtx), nil
+	}
+
 	if len(fltr.ctx) > 0 {
 		sz, err = cdtContextList(fltr.ctx).packArray(cmd)
 	}
@@ -170,6 +180,10 @@ func (fltr *Filter) packCtx(cmd BufferEx) (sz int, err Error) {
 // Retrieve packed Context size.
 // For internal use only.
 func (fltr *Filter) estimatePackedCtxSize() (sz int, err Error) {
+	if fltr.packedCtx != nil {
+		return len(fltr.packedCtx), nil
+	}
+
 	if len(fltr.ctx) > 0 {
 		sz, err = cdtContextList(fltr.ctx).packArray(nil)
 	}
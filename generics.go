@@ -180,7 +180,7 @@ type uint64Slice []uint64
 func (ts uint64Slice) PackList(buf BufferEx) (int, error) {
 	size := 0
 	for _, elem := range ts {
-		n, err := PackUInt64(buf, elem)
+		n, err := packAUInt64(buf, elem)
 		size += n
 		if err != nil {
 			return size, err
@@ -3082,7 +3082,7 @@ func (tm stringUint64Map) PackMap(buf BufferEx) (int, error) {
 			return size, err
 		}
 
-		n, err = PackUInt64(buf, v)
+		n, err = packAUInt64(buf, v)
 		size += n
 		if err != nil {
 			return size, err
@@ -3108,7 +3108,7 @@ func (tm intUint64Map) PackMap(buf BufferEx) (int, error) {
 			return size, err
 		}
 
-		n, err = PackUInt64(buf, v)
+		n, err = packAUInt64(buf, v)
 		size += n
 		if err != nil {
 			return size, err
@@ -3134,7 +3134,7 @@ func (tm int8Uint64Map) PackMap(buf BufferEx) (int, error) {
 			return size, err
 		}
 
-		n, err = PackUInt64(buf, v)
+		n, err = packAUInt64(buf, v)
 		size += n
 		if err != nil {
 			return size, err
@@ -3160,7 +3160,7 @@ func (tm int16Uint64Map) PackMap(buf BufferEx) (int, error) {
 			return size, err
 		}
 
-		n, err = PackUInt64(buf, v)
+		n, err = packAUInt64(buf, v)
 		size += n
 		if err != nil {
 			return size, err
@@ -3186,7 +3186,7 @@ func (tm int32Uint64Map) PackMap(buf BufferEx) (int, error) {
 			return size, err
 		}
 
-		n, err = PackUInt64(buf, v)
+		n, err = packAUInt64(buf, v)
 		size += n
 		if err != nil {
 			return size, err
@@ -3212,7 +3212,7 @@ func (tm int64Uint64Map) PackMap(buf BufferEx) (int, error) {
 			return size, err
 		}
 
-		n, err = PackUInt64(buf, v)
+		n, err = packAUInt64(buf, v)
 		size += n
 		if err != nil {
 			return size, err
@@ -3238,7 +3238,7 @@ func (tm uint16Uint64Map) PackMap(buf BufferEx) (int, error) {
 			return size, err
 		}
 
-		n, err = PackUInt64(buf, v)
+		n, err = packAUInt64(buf, v)
 		size += n
 		if err != nil {
 			return size, err
@@ -3264,7 +3264,7 @@ func (tm uint32Uint64Map) PackMap(buf BufferEx) (int, error) {
 			return size, err
 		}
 
-		n, err = PackUInt64(buf, v)
+		n, err = packAUInt64(buf, v)
 		size += n
 		if err != nil {
 			return size, err
@@ -3290,7 +3290,7 @@ func (tm float32Uint64Map) PackMap(buf BufferEx) (int, error) {
 			return size, err
 		}
 
-		n, err = PackUInt64(buf, v)
+		n, err = packAUInt64(buf, v)
 		size += n
 		if err != nil {
 			return size, err
@@ -3316,7 +3316,7 @@ func (tm float64Uint64Map) PackMap(buf BufferEx) (int, error) {
 			return size, err
 		}
 
-		n, err = PackUInt64(buf, v)
+		n, err = packAUInt64(buf, v)
 		size += n
 		if err != nil {
 			return size, err
@@ -3336,7 +3336,7 @@ type uint64StringMap map[uint64]string
 func (tm uint64StringMap) PackMap(buf BufferEx) (int, error) {
 	size := 0
 	for k, v := range tm {
-		n, err := PackUInt64(buf, k)
+		n, err := packAUInt64(buf, k)
 		size += n
 		if err != nil {
 			return size, err
@@ -3362,7 +3362,7 @@ type uint64IntMap map[uint64]int
 func (tm uint64IntMap) PackMap(buf BufferEx) (int, error) {
 	size := 0
 	for k, v := range tm {
-		n, err := PackUInt64(buf, k)
+		n, err := packAUInt64(buf, k)
 		size += n
 		if err != nil {
 			return size, err
@@ -3388,7 +3388,7 @@ type uint64Int8Map map[uint64]int8
 func (tm uint64Int8Map) PackMap(buf BufferEx) (int, error) {
 	size := 0
 	for k, v := range tm {
-		n, err := PackUInt64(buf, k)
+		n, err := packAUInt64(buf, k)
 		size += n
 		if err != nil {
 			return size, err
@@ -3414,7 +3414,7 @@ type uint64Int16Map map[uint64]int16
 func (tm uint64Int16Map) PackMap(buf BufferEx) (int, error) {
 	size := 0
 	for k, v := range tm {
-		n, err := PackUInt64(buf, k)
+		n, err := packAUInt64(buf, k)
 		size += n
 		if err != nil {
 			return size, err
@@ -3440,7 +3440,7 @@ type uint64Int32Map map[uint64]int32
 func (tm uint64Int32Map) PackMap(buf BufferEx) (int, error) {
 	size := 0
 	for k, v := range tm {
-		n, err := PackUInt64(buf, k)
+		n, err := packAUInt64(buf, k)
 		size += n
 		if err != nil {
 			return size, err
@@ -3466,7 +3466,7 @@ type uint64Int64Map map[uint64]int64
 func (tm uint64Int64Map) PackMap(buf BufferEx) (int, error) {
 	size := 0
 	for k, v := range tm {
-		n, err := PackUInt64(buf, k)
+		n, err := packAUInt64(buf, k)
 		size += n
 		if err != nil {
 			return size, err
@@ -3492,7 +3492,7 @@ type uint64Uint16Map map[uint64]uint16
 func (tm uint64Uint16Map) PackMap(buf BufferEx) (int, error) {
 	size := 0
 	for k, v := range tm {
-		n, err := PackUInt64(buf, k)
+		n, err := packAUInt64(buf, k)
 		size += n
 		if err != nil {
 			return size, err
@@ -3518,7 +3518,7 @@ type uint64Uint32Map map[uint64]uint32
 func (tm uint64Uint32Map) PackMap(buf BufferEx) (int, error) {
 	size := 0
 	for k, v := range tm {
-		n, err := PackUInt64(buf, k)
+		n, err := packAUInt64(buf, k)
 		size += n
 		if err != nil {
 			return size, err
@@ -3544,13 +3544,13 @@ type uint64Uint64Map map[uint64]uint64
 func (tm uint64Uint64Map) PackMap(buf BufferEx) (int, error) {
 	size := 0
 	for k, v := range tm {
-		n, err := PackUInt64(buf, k)
+		n, err := packAUInt64(buf, k)
 		size += n
 		if err != nil {
 			return size, err
 		}
 
-		n, err = PackUInt64(buf, v)
+		n, err = packAUInt64(buf, v)
 		size += n
 		if err != nil {
 			return size, err
@@ -3570,7 +3570,7 @@ type uint64Float32Map map[uint64]float32
 func (tm uint64Float32Map) PackMap(buf BufferEx) (int, error) {
 	size := 0
 	for k, v := range tm {
-		n, err := PackUInt64(buf, k)
+		n, err := packAUInt64(buf, k)
 		size += n
 		if err != nil {
 			return size, err
@@ -3596,7 +3596,7 @@ type uint64Float64Map map[uint64]float64
 func (tm uint64Float64Map) PackMap(buf BufferEx) (int, error) {
 	size := 0
 	for k, v := range tm {
-		n, err := PackUInt64(buf, k)
+		n, err := packAUInt64(buf, k)
 		size += n
 		if err != nil {
 			return size, err
@@ -3622,7 +3622,7 @@ type uint64InterfaceMap map[uint64]interface{}
 func (tm uint64InterfaceMap) PackMap(buf BufferEx) (int, error) {
 	size := 0
 	for k, v := range tm {
-		n, err := PackUInt64(buf, k)
+		n, err := packAUInt64(buf, k)
 		size += n
 		if err != nil {
 			return size, err
@@ -0,0 +1,184 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"context"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// hedgedCommand is a single record read command that can be duplicated to another replica.
+type hedgedCommand interface {
+	Execute() Error
+
+	hedgePartition(policy *BasePolicy) *Partition
+	cancelWith(ctx context.Context)
+}
+
+func (cmd *baseCommand) cancelWith(ctx context.Context) {
+	cmd.cancelCtx = ctx
+}
+
+// parseResultOrCancel parses the result of the command, and interrupts the blocking
+// read on the connection if the command is cancelled meanwhile. The connection is
+// not touched once it returns, so it can be put back in the pool.
+func (cmd *baseCommand) parseResultOrCancel(ifc command, conn *Connection) Error {
+	if cmd.cancelCtx == nil {
+		return ifc.parseResult(ifc, conn)
+	}
+
+	if cmd.cancelCtx.Err() != nil {
+		return errCommandCancelled
+	}
+
+	interrupted := false
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-cmd.cancelCtx.Done():
+			interrupted = true
+			conn.interrupt()
+		case <-stop:
+		}
+	}()
+
+	err := ifc.parseResult(ifc, conn)
+	close(stop)
+	<-stopped
+
+	// the connection was closed, even if the response was read in full
+	if interrupted {
+		return errCommandCancelled
+	}
+	return err
+}
+
+// hedgePartition returns the partition a duplicate of the command is sent to, which selects
+// the next replica of the partition. It returns nil if the command cannot be hedged,
+// e.g. because the partition has a single replica, or the consistency mode requires
+// the reads to go to the master.
+func (cmd *singleCommand) hedgePartition(policy *BasePolicy) *Partition {
+	ptn := cmd.partition
+	if ptn == nil || ptn.partitions == nil || len(ptn.partitions.Replicas) < 2 || ptn.linearize {
+		return nil
+	}
+
+	if ptn.partitions.SCMode && policy.ReadModeSC == ReadModeSCSession {
+		return nil
+	}
+
+	res := *ptn
+	res.sequence++
+	switch res.replica {
+	case MASTER, RANDOM:
+		res.replica = SEQUENCE
+	}
+	return &res
+}
+
+type hedgeResult struct {
+	cmd   hedgedCommand
+	err   Error
+	hedge bool
+}
+
+// final returns true if the response can be returned to the user, and the other
+// attempt abandoned. Network errors and timeouts are not final, since the other
+// replica may still respond.
+func (r *hedgeResult) final() bool {
+	return r.err == nil || !r.err.Matches(types.TIMEOUT, types.NETWORK_ERROR, types.SERVER_NOT_AVAILABLE, types.MAX_RETRIES_EXCEEDED)
+}
+
+// executeHedged executes the command. If it has not responded within BasePolicy.HedgeDelay,
+// a duplicate created by newHedge is sent to the next replica of the partition.
+// The first final response is returned, along with the command that received it,
+// and the other command is cancelled, which interrupts its pending read.
+// If both commands fail, the error of the original command is returned.
+// A hedge that loses the race is counted as wasted once it completes.
+func (clstr *Cluster) executeHedged(policy *BasePolicy, cmd hedgedCommand, newHedge func(*Partition) (hedgedCommand, Error)) (hedgedCommand, Error) {
+	if policy.HedgeDelay <= 0 {
+		return cmd, cmd.Execute()
+	}
+
+	ptn := cmd.hedgePartition(policy)
+	if ptn == nil {
+		return cmd, cmd.Execute()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd.cancelWith(ctx)
+
+	results := make(chan hedgeResult, 2)
+	run := func(cmd hedgedCommand, hedge bool) {
+		results <- hedgeResult{cmd: cmd, err: cmd.Execute(), hedge: hedge}
+	}
+	go run(cmd, false)
+
+	timer := time.NewTimer(policy.HedgeDelay)
+	defer timer.Stop()
+
+	select {
+	case res := <-results:
+		cancel()
+		return res.cmd, res.err
+	case <-timer.C:
+	}
+
+	hedge, err := newHedge(ptn)
+	if err != nil {
+		// the original command is still running
+		res := <-results
+		cancel()
+		return res.cmd, res.err
+	}
+	hedge.cancelWith(ctx)
+	go run(hedge, true)
+
+	first := <-results
+	running := true
+	if !first.final() {
+		second := <-results
+		running = false
+		if second.final() || first.hedge {
+			first, second = second, first
+		}
+
+		if !first.final() {
+			// both failed; the original command was run first
+			cancel()
+			clstr.hedgedReadsWasted.IncrementAndGet()
+			return first.cmd, first.err
+		}
+	}
+
+	cancel()
+	switch {
+	case first.hedge:
+		clstr.hedgedReadsWon.IncrementAndGet()
+	case running:
+		// the hedge is counted once its read is interrupted
+		go func() {
+			<-results
+			clstr.hedgedReadsWasted.IncrementAndGet()
+		}()
+	default:
+		clstr.hedgedReadsWasted.IncrementAndGet()
+	}
+	return first.cmd, first.err
+}
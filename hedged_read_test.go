@@ -0,0 +1,200 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"context"
+	"net"
+	"time"
+
+	iatomic "github.com/aerospike/aerospike-client-go/v7/internal/atomic"
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+type fakeHedgedCommand struct {
+	delay    time.Duration
+	err      Error
+	ptn      *Partition
+	ctx      context.Context
+	executed iatomic.Bool
+
+	// if set, the command ignores the cancellation and completes when release is closed
+	release chan struct{}
+}
+
+func (cmd *fakeHedgedCommand) Execute() Error {
+	cmd.executed.Set(true)
+	if cmd.release != nil {
+		<-cmd.release
+		return cmd.err
+	}
+
+	var cancelled <-chan struct{}
+	if cmd.ctx != nil {
+		cancelled = cmd.ctx.Done()
+	}
+
+	timer := time.NewTimer(cmd.delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return cmd.err
+	case <-cancelled:
+		return errCommandCancelled
+	}
+}
+
+func (cmd *fakeHedgedCommand) hedgePartition(policy *BasePolicy) *Partition { return cmd.ptn }
+func (cmd *fakeHedgedCommand) cancelWith(ctx context.Context)               { cmd.ctx = ctx }
+
+var _ = gg.Describe("Hedged reads", func() {
+
+	var clstr *Cluster
+	var policy *BasePolicy
+
+	gg.BeforeEach(func() {
+		clstr = new(Cluster)
+		policy = NewPolicy()
+		policy.HedgeDelay = 10 * time.Millisecond
+	})
+
+	newHedge := func(hedge *fakeHedgedCommand) func(*Partition) (hedgedCommand, Error) {
+		return func(*Partition) (hedgedCommand, Error) { return hedge, nil }
+	}
+
+	gg.It("Must not hedge when the delay is not set or the partition cannot be hedged", func() {
+		hedge := &fakeHedgedCommand{}
+
+		policy.HedgeDelay = 0
+		cmd := &fakeHedgedCommand{delay: 30 * time.Millisecond, ptn: new(Partition)}
+		winner, err := clstr.executeHedged(policy, cmd, newHedge(hedge))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(winner).To(gm.BeIdenticalTo(cmd))
+
+		policy.HedgeDelay = 10 * time.Millisecond
+		cmd = &fakeHedgedCommand{delay: 30 * time.Millisecond}
+		winner, err = clstr.executeHedged(policy, cmd, newHedge(hedge))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(winner).To(gm.BeIdenticalTo(cmd))
+
+		gm.Expect(hedge.executed.Get()).To(gm.BeFalse())
+	})
+
+	gg.It("Must not hedge reads that respond within the delay", func() {
+		hedge := &fakeHedgedCommand{}
+		cmd := &fakeHedgedCommand{ptn: new(Partition)}
+
+		winner, err := clstr.executeHedged(policy, cmd, newHedge(hedge))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(winner).To(gm.BeIdenticalTo(cmd))
+		gm.Expect(hedge.executed.Get()).To(gm.BeFalse())
+		gm.Expect(clstr.hedgedReadsWon.Get()).To(gm.Equal(0))
+		gm.Expect(clstr.hedgedReadsWasted.Get()).To(gm.Equal(0))
+	})
+
+	gg.It("Must return the response of the hedge if it is faster", func() {
+		hedge := &fakeHedgedCommand{}
+		cmd := &fakeHedgedCommand{delay: 200 * time.Millisecond, ptn: new(Partition)}
+
+		winner, err := clstr.executeHedged(policy, cmd, newHedge(hedge))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(winner).To(gm.BeIdenticalTo(hedge))
+		gm.Expect(cmd.ctx.Err()).To(gm.HaveOccurred())
+		gm.Expect(clstr.hedgedReadsWon.Get()).To(gm.Equal(1))
+		gm.Expect(clstr.hedgedReadsWasted.Get()).To(gm.Equal(0))
+	})
+
+	gg.It("Must interrupt the hedge and count it as wasted if the original read responds first", func() {
+		hedge := &fakeHedgedCommand{delay: time.Minute}
+		cmd := &fakeHedgedCommand{delay: 30 * time.Millisecond, ptn: new(Partition)}
+
+		winner, err := clstr.executeHedged(policy, cmd, newHedge(hedge))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(winner).To(gm.BeIdenticalTo(cmd))
+		gm.Expect(hedge.executed.Get()).To(gm.BeTrue())
+		gm.Expect(hedge.ctx.Err()).To(gm.HaveOccurred())
+		gm.Eventually(clstr.hedgedReadsWasted.Get).Should(gm.Equal(1))
+		gm.Expect(clstr.hedgedReadsWon.Get()).To(gm.Equal(0))
+	})
+
+	gg.It("Must count the wasted hedge only after it completes", func() {
+		hedge := &fakeHedgedCommand{release: make(chan struct{})}
+		cmd := &fakeHedgedCommand{delay: 30 * time.Millisecond, ptn: new(Partition)}
+
+		winner, err := clstr.executeHedged(policy, cmd, newHedge(hedge))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(winner).To(gm.BeIdenticalTo(cmd))
+		gm.Consistently(clstr.hedgedReadsWasted.Get, 50*time.Millisecond).Should(gm.Equal(0))
+
+		close(hedge.release)
+		gm.Eventually(clstr.hedgedReadsWasted.Get).Should(gm.Equal(1))
+	})
+
+	gg.It("Must interrupt the pending read of a multiplexed stream", func() {
+		s := newMuxStream()
+		s.sent = true
+		conn := &Connection{conn: s}
+
+		done := make(chan error)
+		go func() {
+			_, err := s.Read(make([]byte, 8))
+			done <- err
+		}()
+
+		conn.interrupt()
+		gm.Eventually(done).Should(gm.Receive(gm.MatchError(net.ErrClosed)))
+	})
+
+	gg.It("Must wait for the other read when the first one times out", func() {
+		hedge := &fakeHedgedCommand{err: ErrTimeout.err()}
+		cmd := &fakeHedgedCommand{delay: 30 * time.Millisecond, err: ErrKeyNotFound.err(), ptn: new(Partition)}
+
+		winner, err := clstr.executeHedged(policy, cmd, newHedge(hedge))
+		gm.Expect(err).To(gm.HaveOccurred())
+		gm.Expect(err.Matches(types.KEY_NOT_FOUND_ERROR)).To(gm.BeTrue())
+		gm.Expect(winner).To(gm.BeIdenticalTo(cmd))
+	})
+
+	gg.It("Must return the error of the original read when both fail", func() {
+		hedge := &fakeHedgedCommand{err: ErrNetwork.err()}
+		cmd := &fakeHedgedCommand{delay: 30 * time.Millisecond, err: ErrTimeout.err(), ptn: new(Partition)}
+
+		winner, err := clstr.executeHedged(policy, cmd, newHedge(hedge))
+		gm.Expect(err.Matches(types.TIMEOUT)).To(gm.BeTrue())
+		gm.Expect(winner).To(gm.BeIdenticalTo(cmd))
+	})
+
+	gg.It("Must select the next replica for the hedge", func() {
+		partitions := &Partitions{Replicas: make([][]*Node, 2)}
+		cmd := singleCommand{partition: &Partition{partitions: partitions, replica: MASTER}}
+
+		ptn := cmd.hedgePartition(policy)
+		gm.Expect(ptn).ToNot(gm.BeNil())
+		gm.Expect(ptn).ToNot(gm.BeIdenticalTo(cmd.partition))
+		gm.Expect(ptn.sequence).To(gm.Equal(1))
+		gm.Expect(ptn.replica).To(gm.Equal(SEQUENCE))
+		gm.Expect(cmd.partition.sequence).To(gm.Equal(0))
+
+		partitions.SCMode = true
+		gm.Expect(cmd.hedgePartition(policy)).To(gm.BeNil())
+
+		single := singleCommand{partition: &Partition{partitions: &Partitions{Replicas: make([][]*Node, 1)}}}
+		gm.Expect(single.hedgePartition(policy)).To(gm.BeNil())
+	})
+
+})
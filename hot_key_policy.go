@@ -0,0 +1,57 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+)
+
+// HotKeyPolicy determines how the client samples the keys of the commands to detect hot keys.
+// See ClientPolicy.HotKeyPolicy and Client.HotKeys.
+type HotKeyPolicy struct {
+	// SampleInterval is the number of single record commands per sampled command.
+	// Sampling keeps the overhead of the detection low; the counts of the report are
+	// scaled by the interval.
+	//
+	// Default: 100
+	SampleInterval int //= 100
+
+	// TopN is the number of the most accessed keys reported by Client.HotKeys.
+	//
+	// Default: 10
+	TopN int //= 10
+
+	// Window is the duration the access counts are collected for. The counts are reset
+	// at the start of each window, and the rates are computed over the current window.
+	//
+	// Default: 1 minute
+	Window time.Duration //= 1 minute
+
+	// SketchWidth is the number of counters in each row of the count-min sketch used to
+	// estimate the access counts. Wider sketches overestimate the counts less, at the cost of memory.
+	//
+	// Default: 2048
+	SketchWidth int //= 2048
+}
+
+// NewHotKeyPolicy returns a policy with the default values.
+func NewHotKeyPolicy() *HotKeyPolicy {
+	return &HotKeyPolicy{
+		SampleInterval: 100,
+		TopN:           10,
+		Window:         time.Minute,
+		SketchWidth:    2048,
+	}
+}
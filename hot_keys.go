@@ -0,0 +1,177 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"encoding/binary"
+	"sort"
+	"sync"
+	"time"
+
+	iatomic "github.com/aerospike/aerospike-client-go/v7/internal/atomic"
+)
+
+// HotKey is an entry of the hot key report of the client. See Client.HotKeys.
+type HotKey struct {
+	// Namespace of the record.
+	Namespace string
+
+	// SetName of the record.
+	SetName string
+
+	// Digest of the record.
+	Digest []byte
+
+	// Count is the estimated number of commands for the record in the current window.
+	Count uint64
+
+	// Rate is the estimated number of commands per second for the record in the current window.
+	Rate float64
+}
+
+// number of rows of the count-min sketch. Each row uses 4 bytes of the digest as its hash.
+const hotKeySketchDepth = 4
+
+// hotKeyTracker estimates the access counts of the sampled keys with a count-min sketch,
+// and keeps the keys with the highest counts.
+type hotKeyTracker struct {
+	policy HotKeyPolicy
+
+	commands iatomic.Int
+
+	mutex       sync.Mutex
+	sketch      [hotKeySketchDepth][]uint32
+	top         map[[20]byte]*HotKey
+	windowStart time.Time
+}
+
+func newHotKeyTracker(policy HotKeyPolicy) *hotKeyTracker {
+	def := NewHotKeyPolicy()
+	if policy.SampleInterval <= 0 {
+		policy.SampleInterval = def.SampleInterval
+	}
+	if policy.TopN <= 0 {
+		policy.TopN = def.TopN
+	}
+	if policy.Window <= 0 {
+		policy.Window = def.Window
+	}
+	if policy.SketchWidth <= 0 {
+		policy.SketchWidth = def.SketchWidth
+	}
+
+	t := &hotKeyTracker{policy: policy}
+	for i := range t.sketch {
+		t.sketch[i] = make([]uint32, policy.SketchWidth)
+	}
+	t.reset(time.Now())
+	return t
+}
+
+// reset starts a new window. Must be called with the mutex held.
+func (t *hotKeyTracker) reset(now time.Time) {
+	for i := range t.sketch {
+		row := t.sketch[i]
+		for j := range row {
+			row[j] = 0
+		}
+	}
+	t.top = make(map[[20]byte]*HotKey, t.policy.TopN+1)
+	t.windowStart = now
+}
+
+// record samples the key of a command.
+func (t *hotKeyTracker) record(key *Key) {
+	if key == nil || t.commands.IncrementAndGet()%t.policy.SampleInterval != 0 {
+		return
+	}
+
+	now := time.Now()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if now.Sub(t.windowStart) >= t.policy.Window {
+		t.reset(now)
+	}
+
+	// the digest is uniformly distributed, so its bytes can be used as the hashes of the rows
+	est := ^uint32(0)
+	width := uint32(t.policy.SketchWidth)
+	for i := range t.sketch {
+		j := binary.LittleEndian.Uint32(key.digest[i*4:]) % width
+		t.sketch[i][j]++
+		if t.sketch[i][j] < est {
+			est = t.sketch[i][j]
+		}
+	}
+	count := uint64(est) * uint64(t.policy.SampleInterval)
+
+	if hk := t.top[key.digest]; hk != nil {
+		hk.Count = count
+		return
+	}
+
+	if len(t.top) >= t.policy.TopN {
+		// replace the coldest key if this one is hotter
+		var coldest [20]byte
+		var min *HotKey
+		for d, hk := range t.top {
+			if min == nil || hk.Count < min.Count {
+				coldest, min = d, hk
+			}
+		}
+		if min.Count >= count {
+			return
+		}
+		delete(t.top, coldest)
+	}
+
+	t.top[key.digest] = &HotKey{
+		Namespace: key.namespace,
+		SetName:   key.setName,
+		Digest:    append([]byte(nil), key.digest[:]...),
+		Count:     count,
+	}
+}
+
+// report returns the hottest keys of the current window, the hottest first.
+func (t *hotKeyTracker) report() []HotKey {
+	now := time.Now()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if now.Sub(t.windowStart) >= t.policy.Window {
+		t.reset(now)
+		return nil
+	}
+
+	elapsed := now.Sub(t.windowStart).Seconds()
+	res := make([]HotKey, 0, len(t.top))
+	for _, hk := range t.top {
+		r := *hk
+		r.Digest = append([]byte(nil), hk.Digest...)
+		if elapsed > 0 {
+			r.Rate = float64(r.Count) / elapsed
+		}
+		res = append(res, r)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Count > res[j].Count
+	})
+	return res
+}
@@ -0,0 +1,81 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Hot keys", func() {
+
+	record := func(t *hotKeyTracker, key *Key, n int) {
+		for i := 0; i < n; i++ {
+			t.record(key)
+		}
+	}
+
+	gg.It("must report the most accessed keys, hottest first", func() {
+		t := newHotKeyTracker(HotKeyPolicy{SampleInterval: 1, TopN: 2})
+
+		keyA, _ := NewKey("test", "set", "a")
+		keyB, _ := NewKey("test", "set", "b")
+		keyC, _ := NewKey("test", "other", "c")
+		record(t, keyC, 1)
+		record(t, keyA, 10)
+		record(t, keyB, 5)
+		record(t, keyC, 1)
+
+		res := t.report()
+		gm.Expect(res).To(gm.HaveLen(2))
+		gm.Expect(res[0].Digest).To(gm.Equal(keyA.Digest()))
+		gm.Expect(res[0].Namespace).To(gm.Equal("test"))
+		gm.Expect(res[0].SetName).To(gm.Equal("set"))
+		gm.Expect(res[0].Count).To(gm.Equal(uint64(10)))
+		gm.Expect(res[0].Rate).To(gm.BeNumerically(">", 0))
+		gm.Expect(res[1].Digest).To(gm.Equal(keyB.Digest()))
+		gm.Expect(res[1].Count).To(gm.Equal(uint64(5)))
+	})
+
+	gg.It("must scale the counts by the sample interval", func() {
+		t := newHotKeyTracker(HotKeyPolicy{SampleInterval: 10})
+
+		key, _ := NewKey("test", "set", "a")
+		record(t, key, 100)
+
+		res := t.report()
+		gm.Expect(res).To(gm.HaveLen(1))
+		gm.Expect(res[0].Count).To(gm.Equal(uint64(100)))
+	})
+
+	gg.It("must reset the counts at the start of each window", func() {
+		t := newHotKeyTracker(HotKeyPolicy{SampleInterval: 1, Window: 20 * time.Millisecond})
+
+		key, _ := NewKey("test", "set", "a")
+		record(t, key, 3)
+		gm.Expect(t.report()).To(gm.HaveLen(1))
+
+		time.Sleep(30 * time.Millisecond)
+		gm.Expect(t.report()).To(gm.BeEmpty())
+
+		record(t, key, 2)
+		res := t.report()
+		gm.Expect(res).To(gm.HaveLen(1))
+		gm.Expect(res[0].Count).To(gm.Equal(uint64(2)))
+	})
+
+})
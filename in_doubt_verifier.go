@@ -0,0 +1,77 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+// inDoubtVerified wraps the write command f, so that its in-doubt errors are resolved by reading
+// the record back if the policy requires it. See WritePolicy.VerifyInDoubt.
+func (clnt *Client) inDoubtVerified(policy *WritePolicy, key *Key, f func() Error) func() Error {
+	if !policy.VerifyInDoubt {
+		return f
+	}
+
+	return func() Error {
+		err := f()
+		if err == nil || !err.IsInDoubt() {
+			return err
+		}
+		return clnt.verifyInDoubt(policy, key, err)
+	}
+}
+
+// verifyInDoubt reads the record back after a write has failed with the in-doubt error err.
+// It returns nil if the write was applied, err marked as not in doubt if it was not,
+// and err itself if this cannot be determined.
+func (clnt *Client) verifyInDoubt(policy *WritePolicy, key *Key, err Error) Error {
+	if policy.WriteTokenBin != "" && policy.WriteToken != "" && clnt.writeTokenApplied(policy, key) {
+		return nil
+	}
+
+	if policy.GenerationPolicy != EXPECT_GEN_EQUAL {
+		return err
+	}
+
+	// read the latest version of the record, ignoring the filter of the write
+	rp := policy.BasePolicy
+	rp.FilterExpression = nil
+	rp.ReadModeAP = ReadModeAPAll
+	rp.ReadModeSC = ReadModeSCLinearize
+
+	rec, rerr := clnt.GetHeader(&rp, key)
+	if rerr != nil || rec == nil {
+		return err
+	}
+
+	applied, definitive := resolveInDoubt(policy, rec)
+	switch {
+	case !definitive:
+		return err
+	case applied:
+		return nil
+	default:
+		return err.markInDoubt(false)
+	}
+}
+
+// resolveInDoubt determines if a write with an expected generation was applied,
+// given the current state of the record.
+func resolveInDoubt(policy *WritePolicy, rec *Record) (applied, definitive bool) {
+	switch rec.Generation {
+	case policy.Generation + 1:
+		return true, true
+	case policy.Generation:
+		return false, true
+	}
+	return false, false
+}
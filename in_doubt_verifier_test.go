@@ -0,0 +1,67 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("In-doubt verification", func() {
+
+	gg.It("must resolve the write by the generation of the record", func() {
+		policy := NewWritePolicy(0, 0).IfGenerationEquals(5)
+
+		applied, definitive := resolveInDoubt(policy, &Record{Generation: 6})
+		gm.Expect(applied).To(gm.BeTrue())
+		gm.Expect(definitive).To(gm.BeTrue())
+
+		applied, definitive = resolveInDoubt(policy, &Record{Generation: 5})
+		gm.Expect(applied).To(gm.BeFalse())
+		gm.Expect(definitive).To(gm.BeTrue())
+
+		_, definitive = resolveInDoubt(policy, &Record{Generation: 7})
+		gm.Expect(definitive).To(gm.BeFalse())
+	})
+
+	gg.It("must not verify the write unless the policy requires it", func() {
+		clnt := &Client{}
+		calls := 0
+		f := func() Error {
+			calls++
+			return newError(types.TIMEOUT).markInDoubt(true)
+		}
+
+		err := clnt.inDoubtVerified(NewWritePolicy(0, 0), nil, f)()
+		gm.Expect(err.IsInDoubt()).To(gm.BeTrue())
+		gm.Expect(calls).To(gm.Equal(1))
+	})
+
+	gg.It("must return the original error if the policy does not expect a generation", func() {
+		clnt := &Client{}
+		policy := NewWritePolicy(0, 0)
+		policy.VerifyInDoubt = true
+		f := func() Error {
+			return newError(types.TIMEOUT).markInDoubt(true)
+		}
+
+		err := clnt.inDoubtVerified(policy, nil, f)()
+		gm.Expect(err.Matches(types.TIMEOUT)).To(gm.BeTrue())
+		gm.Expect(err.IsInDoubt()).To(gm.BeTrue())
+	})
+
+})
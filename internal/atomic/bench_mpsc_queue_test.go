@@ -0,0 +1,98 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package atomic_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/aerospike/aerospike-client-go/v7/internal/atomic"
+)
+
+var _popped int
+
+func Benchmark_MPSCQueue_Push_Parallel(b *testing.B) {
+	q := atomic.NewMPSCQueue[int]()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			q.Push(i)
+			i++
+		}
+	})
+	q.Drain(func(v int) { _popped = v })
+}
+
+func Benchmark_MutexSlice_Append_Parallel(b *testing.B) {
+	var m sync.Mutex
+	var l []int
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			m.Lock()
+			l = append(l, i)
+			m.Unlock()
+			i++
+		}
+	})
+	_popped = len(l)
+}
+
+func Benchmark_MPSCQueue_FanIn(b *testing.B) {
+	q := atomic.NewMPSCQueue[int]()
+	done, exited := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(exited)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				q.Drain(func(v int) { _popped = v })
+			}
+		}
+	}()
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			q.Push(i)
+			i++
+		}
+	})
+	close(done)
+	<-exited
+}
+
+func Benchmark_Chan_FanIn(b *testing.B) {
+	ch := make(chan int, 1024)
+	done := make(chan struct{})
+	go func() {
+		for v := range ch {
+			_popped = v
+		}
+		close(done)
+	}()
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			ch <- i
+			i++
+		}
+	})
+	close(ch)
+	<-done
+}
@@ -14,7 +14,10 @@
 
 package atomic
 
-import "sync"
+import (
+	"sync"
+	"time"
+)
 
 // Guard allows synchronized access to a value
 type Guard[T any] struct {
@@ -34,6 +37,17 @@ func (g *Guard[T]) Do(f func(*T)) {
 	f(g.val)
 }
 
+// TryDo calls the passed closure if the guard can be acquired within the timeout.
+// It returns false without calling the closure if the timeout expires.
+func (g *Guard[T]) TryDo(timeout time.Duration, f func(*T)) bool {
+	if !tryLock(g.m.TryLock, timeout) {
+		return false
+	}
+	defer g.m.Unlock()
+	f(g.val)
+	return true
+}
+
 // DoVal calls the passed closure with a dereferenced internal value.
 func (g *Guard[T]) DoVal(f func(T)) {
 	g.m.Lock()
@@ -80,3 +94,38 @@ func (g *Guard[T]) Release() *T {
 	g.val = nil
 	return res
 }
+
+const (
+	minTryLockWait = 10 * time.Microsecond
+	maxTryLockWait = time.Millisecond
+)
+
+// tryLock calls try until it succeeds or the timeout expires,
+// backing off exponentially between the attempts.
+func tryLock(try func() bool, timeout time.Duration) bool {
+	if try() {
+		return true
+	}
+
+	deadline := time.Now().Add(timeout)
+	wait := minTryLockWait
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return false
+		}
+
+		if wait > remaining {
+			wait = remaining
+		}
+		time.Sleep(wait)
+
+		if try() {
+			return true
+		}
+
+		if wait *= 2; wait > maxTryLockWait {
+			wait = maxTryLockWait
+		}
+	}
+}
@@ -16,6 +16,7 @@ package atomic_test
 
 import (
 	"runtime"
+	"time"
 
 	"github.com/aerospike/aerospike-client-go/v7/internal/atomic"
 
@@ -115,4 +116,35 @@ var _ = gg.Describe("Atomic Guard", func() {
 		})
 
 	})
+
+	gg.It("must TryDo() when the guard is free", func() {
+		called := grd.TryDo(time.Millisecond, func(s *S) {
+			s.a++
+		})
+		gm.Expect(called).To(gm.BeTrue())
+
+		grd.Do(func(s *S) {
+			gm.Expect(s.a).To(gm.Equal(2))
+		})
+	})
+
+	gg.It("must fail TryDo() instead of blocking when the guard is held", func() {
+		grd.Do(func(*S) {
+			start := time.Now()
+			called := grd.TryDo(20*time.Millisecond, func(*S) {})
+			gm.Expect(called).To(gm.BeFalse())
+			gm.Expect(time.Since(start)).To(gm.BeNumerically(">=", 20*time.Millisecond))
+		})
+	})
+
+	gg.It("must TryDo() once the guard is released within the timeout", func() {
+		held := make(chan struct{})
+		go grd.Do(func(*S) {
+			close(held)
+			time.Sleep(5 * time.Millisecond)
+		})
+		<-held
+
+		gm.Expect(grd.TryDo(time.Second, func(*S) {})).To(gm.BeTrue())
+	})
 })
@@ -0,0 +1,84 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package atomic
+
+import "sync/atomic"
+
+type queueNode[T any] struct {
+	next atomic.Pointer[queueNode[T]]
+	val  T
+}
+
+// MPSCQueue is an unbounded, lock-free multi-producer single-consumer FIFO queue.
+// Push can be called concurrently from any number of goroutines, but only
+// one goroutine may call Pop and Drain at a time.
+type MPSCQueue[T any] struct {
+	// the last pushed node; producers swap it
+	head atomic.Pointer[queueNode[T]]
+	_    [56]byte // avoid false sharing between the producers and the consumer
+
+	// the node before the next value to pop; only touched by the consumer
+	tail *queueNode[T]
+}
+
+// NewMPSCQueue creates a new empty queue.
+func NewMPSCQueue[T any]() *MPSCQueue[T] {
+	stub := new(queueNode[T])
+	q := &MPSCQueue[T]{tail: stub}
+	q.head.Store(stub)
+	return q
+}
+
+// Push adds the value to the end of the queue. It never blocks.
+func (q *MPSCQueue[T]) Push(v T) {
+	n := &queueNode[T]{val: v}
+	prev := q.head.Swap(n)
+	prev.next.Store(n)
+}
+
+// Pop removes and returns the value at the front of the queue.
+// It returns false if the queue is empty, or if the value at the front
+// is still being pushed concurrently.
+func (q *MPSCQueue[T]) Pop() (T, bool) {
+	next := q.tail.next.Load()
+	if next == nil {
+		var t T
+		return t, false
+	}
+
+	v := next.val
+	// let the value be garbage collected; next becomes the new stub
+	var t T
+	next.val = t
+	q.tail = next
+	return v, true
+}
+
+// Drain pops all the values in the queue and passes them to f in order.
+// It returns the number of values popped.
+func (q *MPSCQueue[T]) Drain(f func(T)) int {
+	n := 0
+	for v, ok := q.Pop(); ok; v, ok = q.Pop() {
+		f(v)
+		n++
+	}
+	return n
+}
+
+// IsEmpty returns true if there is no value to pop. Like Pop, it must only
+// be called by the consumer.
+func (q *MPSCQueue[T]) IsEmpty() bool {
+	return q.tail.next.Load() == nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package atomic_test
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/aerospike/aerospike-client-go/v7/internal/atomic"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Atomic MPSCQueue", func() {
+	// atomic tests require actual parallelism
+	runtime.GOMAXPROCS(runtime.NumCPU())
+
+	var q *atomic.MPSCQueue[int]
+
+	gg.BeforeEach(func() {
+		q = atomic.NewMPSCQueue[int]()
+	})
+
+	gg.It("must Pop() from an empty queue and not block", func() {
+		gm.Expect(q.IsEmpty()).To(gm.BeTrue())
+		v, ok := q.Pop()
+		gm.Expect(ok).To(gm.BeFalse())
+		gm.Expect(v).To(gm.Equal(0))
+	})
+
+	gg.It("must Pop() the values in the order they were pushed", func() {
+		for j := 0; j < 10; j++ {
+			for i := 0; i < 100; i++ {
+				q.Push(i)
+			}
+
+			for i := 0; i < 100; i++ {
+				v, ok := q.Pop()
+				gm.Expect(ok).To(gm.BeTrue())
+				gm.Expect(v).To(gm.Equal(i))
+			}
+
+			_, ok := q.Pop()
+			gm.Expect(ok).To(gm.BeFalse())
+		}
+	})
+
+	gg.It("must not lose values pushed concurrently", func() {
+		const producers, pushes = 8, 10000
+
+		wg := new(sync.WaitGroup)
+		wg.Add(producers)
+		for p := 0; p < producers; p++ {
+			go func(p int) {
+				defer wg.Done()
+				for i := 0; i < pushes; i++ {
+					q.Push(p*pushes + i)
+				}
+			}(p)
+		}
+
+		// consume while the producers are running
+		seen := make([]bool, producers*pushes)
+		last := make([]int, producers)
+		for p := range last {
+			last[p] = -1
+		}
+
+		consume := func(v int) {
+			gm.Expect(seen[v]).To(gm.BeFalse())
+			seen[v] = true
+
+			// values of the same producer must keep their order
+			p, i := v/pushes, v%pushes
+			gm.Expect(i).To(gm.BeNumerically(">", last[p]))
+			last[p] = i
+		}
+
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+
+		count := 0
+	loop:
+		for {
+			select {
+			case <-done:
+				break loop
+			default:
+				count += q.Drain(consume)
+			}
+		}
+		count += q.Drain(consume)
+
+		gm.Expect(count).To(gm.Equal(producers * pushes))
+		gm.Expect(q.IsEmpty()).To(gm.BeTrue())
+	})
+
+})
@@ -0,0 +1,115 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package atomic
+
+import (
+	"sync"
+	"time"
+)
+
+// RWGuard allows synchronized access to a value, with concurrent readers.
+// The closures passed to the R* methods may run at the same time, and must
+// not modify the value, or synchronize their modifications by other means.
+type RWGuard[T any] struct {
+	val *T
+	m   sync.RWMutex
+}
+
+// NewRWGuard creates a new instance of RWGuard
+func NewRWGuard[T any](val *T) *RWGuard[T] {
+	return &RWGuard[T]{val: val}
+}
+
+// Do calls the passed closure with exclusive access.
+func (g *RWGuard[T]) Do(f func(*T)) {
+	g.m.Lock()
+	defer g.m.Unlock()
+	f(g.val)
+}
+
+// RDo calls the passed closure with shared access.
+func (g *RWGuard[T]) RDo(f func(*T)) {
+	g.m.RLock()
+	defer g.m.RUnlock()
+	f(g.val)
+}
+
+// RDoVal calls the passed closure with a dereferenced internal value and shared access.
+func (g *RWGuard[T]) RDoVal(f func(T)) {
+	g.m.RLock()
+	defer g.m.RUnlock()
+	f(*g.val)
+}
+
+// TryDo calls the passed closure with exclusive access if the guard can be acquired
+// within the timeout. It returns false without calling the closure if the timeout expires.
+func (g *RWGuard[T]) TryDo(timeout time.Duration, f func(*T)) bool {
+	if !tryLock(g.m.TryLock, timeout) {
+		return false
+	}
+	defer g.m.Unlock()
+	f(g.val)
+	return true
+}
+
+// TryRDo calls the passed closure with shared access if the guard can be acquired
+// within the timeout. It returns false without calling the closure if the timeout expires.
+func (g *RWGuard[T]) TryRDo(timeout time.Duration, f func(*T)) bool {
+	if !tryLock(g.m.TryRLock, timeout) {
+		return false
+	}
+	defer g.m.RUnlock()
+	f(g.val)
+	return true
+}
+
+// Call the passed closure allowing to replace the content.
+func (g *RWGuard[T]) Update(f func(**T)) {
+	g.m.Lock()
+	defer g.m.Unlock()
+	f(&g.val)
+}
+
+// Calls the passed closure with shared access.
+// It will call the init func with exclusive access if the internal values is nil.
+// It is used for reference values like slices and maps.
+func (g *RWGuard[T]) InitRDoVal(init func() T, f func(T)) {
+	for {
+		g.m.RLock()
+		if g.val != nil {
+			defer g.m.RUnlock()
+			f(*g.val)
+			return
+		}
+		g.m.RUnlock()
+
+		// the value may be released again before the shared access is reacquired
+		g.m.Lock()
+		if g.val == nil {
+			t := init()
+			g.val = &t
+		}
+		g.m.Unlock()
+	}
+}
+
+// Release returns the internal value and sets it to nil
+func (g *RWGuard[T]) Release() *T {
+	g.m.Lock()
+	defer g.m.Unlock()
+	res := g.val
+	g.val = nil
+	return res
+}
@@ -0,0 +1,124 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package atomic_test
+
+import (
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v7/internal/atomic"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Atomic RWGuard", func() {
+	// atomic tests require actual parallelism
+	runtime.GOMAXPROCS(runtime.NumCPU())
+
+	type S struct {
+		a int
+		b bool
+	}
+
+	var grd *atomic.RWGuard[S]
+
+	gg.BeforeEach(func() {
+		grd = atomic.NewRWGuard[S](&S{a: 1, b: true})
+	})
+
+	gg.It("must allow concurrent readers", func() {
+		const readers = 4
+
+		// every reader waits for all the others inside the guard
+		var inside sync.WaitGroup
+		inside.Add(readers)
+
+		var wg sync.WaitGroup
+		wg.Add(readers)
+		for i := 0; i < readers; i++ {
+			go func() {
+				defer wg.Done()
+				grd.RDo(func(s *S) {
+					inside.Done()
+					inside.Wait()
+				})
+			}()
+		}
+		wg.Wait()
+	})
+
+	gg.It("must fail TryDo() while held by a reader, and TryRDo() while held by a writer", func() {
+		grd.RDo(func(*S) {
+			gm.Expect(grd.TryDo(5*time.Millisecond, func(*S) {})).To(gm.BeFalse())
+			gm.Expect(grd.TryRDo(5*time.Millisecond, func(*S) {})).To(gm.BeTrue())
+		})
+
+		grd.Do(func(*S) {
+			gm.Expect(grd.TryRDo(5*time.Millisecond, func(*S) {})).To(gm.BeFalse())
+			gm.Expect(grd.TryDo(5*time.Millisecond, func(*S) {})).To(gm.BeFalse())
+		})
+
+		called := grd.TryDo(5*time.Millisecond, func(s *S) {
+			s.a++
+		})
+		gm.Expect(called).To(gm.BeTrue())
+
+		grd.RDo(func(s *S) {
+			gm.Expect(*s).To(gm.Equal(S{a: 2, b: true}))
+		})
+	})
+
+	gg.It("must initialize the internal value once for concurrent readers", func() {
+		var inits int
+		flocal := func() map[int]int {
+			inits++
+			return map[int]int{}
+		}
+
+		var grd atomic.RWGuard[map[int]int]
+		var m sync.Mutex
+
+		var wg sync.WaitGroup
+		wg.Add(100)
+		for i := 0; i < 100; i++ {
+			go func(i int) {
+				defer wg.Done()
+				grd.InitRDoVal(flocal, func(s map[int]int) {
+					m.Lock()
+					s[i] = i
+					m.Unlock()
+				})
+			}(i)
+		}
+		wg.Wait()
+
+		gm.Expect(inits).To(gm.Equal(1))
+		gm.Expect(len(*grd.Release())).To(gm.Equal(100))
+		gm.Expect(grd.Release()).To(gm.BeNil())
+	})
+
+	gg.It("must replace internal value's reference correctly", func() {
+		local := S{a: 99, b: false}
+		grd.Update(func(s **S) {
+			*s = &local
+		})
+
+		grd.RDo(func(s *S) {
+			gm.Expect(s == &local).To(gm.BeTrue())
+		})
+	})
+})
@@ -34,8 +34,24 @@ func Path() string {
 	return lua.LuaPath
 }
 
+// DefaultPoolSize is the number of instances kept by the pools of Lua instances by default.
+const DefaultPoolSize = 64
+
 // LuaPool is the global LState pool
-var LuaPool = types.NewPool(64)
+var LuaPool = NewPool(DefaultPoolSize)
+
+// NewPool creates a pool of Lua instances holding up to poolSize idle instances.
+// If poolSize is not positive, DefaultPoolSize is used.
+func NewPool(poolSize int) *types.Pool {
+	if poolSize <= 0 {
+		poolSize = DefaultPoolSize
+	}
+
+	pool := types.NewPool(poolSize)
+	pool.New = newInstance
+	pool.Finalize = finalizeInstance
+	return pool
+}
 
 func newInstance(params ...interface{}) interface{} {
 	L := lua.NewState()
@@ -63,8 +79,3 @@ func finalizeInstance(instance interface{}) {
 		instance.(*lua.LState).Close()
 	}
 }
-
-func init() {
-	LuaPool.New = newInstance
-	LuaPool.Finalize = finalizeInstance
-}
@@ -53,4 +53,21 @@ var _ = gg.Describe("Lua Aerospike API Test", func() {
 
 	})
 
+	gg.It("must isolate the instances of the pools", func() {
+		pool1, pool2 := ilua.NewPool(0), ilua.NewPool(1)
+
+		instance := pool1.Get().(*lua.LState)
+		gm.Expect(instance.DoString("function pooled() return 1 end")).NotTo(gm.HaveOccurred())
+		pool1.Put(instance)
+
+		gm.Expect(pool1.Get()).To(gm.BeIdenticalTo(instance))
+		pool1.Put(instance)
+
+		other := pool2.Get().(*lua.LState)
+		defer other.Close()
+		gm.Expect(other).NotTo(gm.BeIdenticalTo(instance))
+		gm.Expect(other.GetGlobal("pooled")).To(gm.Equal(lua.LNil))
+		gm.Expect(other.DoString("aerospike.log(1, 'Warn')")).NotTo(gm.HaveOccurred())
+	})
+
 })
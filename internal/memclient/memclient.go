@@ -0,0 +1,48 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package memclient connects the aerospike package to the in-memory client of the mock package.
+// The in-memory client needs a few internals of the aerospike package, like the encoding of
+// the operations and the construction of recordsets and tasks. The aerospike package hands them
+// over through Bridge, without exporting them from its API.
+package memclient
+
+// OpKind identifies the type of an operation.
+type OpKind int
+
+// The operation kinds reported by the bridge.
+const (
+	OpRead OpKind = iota
+	OpReadHeader
+	OpWrite
+	OpCDTRead
+	OpCDTModify
+	OpMapRead
+	OpMapModify
+	OpAdd
+	OpExpRead
+	OpExpModify
+	OpAppend
+	OpPrepend
+	OpTouch
+	OpBitRead
+	OpBitModify
+	OpDelete
+	OpHLLRead
+	OpHLLModify
+)
+
+// Bridge exposes the internals of the aerospike package to the mock package.
+// It is set by the aerospike package; the mock package asserts it to the interface it uses.
+var Bridge interface{}
@@ -45,6 +45,30 @@ func ParDo[T any](seq []T, f func(T)) {
 	wg.Wait()
 }
 
+// ParDoLimit runs f for the elements of seq in parallel, running at most limit of them at once.
+// If limit is not positive, or not less than the length of seq, it is equivalent to ParDo.
+func ParDoLimit[T any](seq []T, limit int, f func(T)) {
+	if limit <= 0 || limit >= len(seq) {
+		ParDo(seq, f)
+		return
+	}
+
+	sem := make(chan struct{}, limit)
+	wg := new(sync.WaitGroup)
+	wg.Add(len(seq))
+	for i := range seq {
+		sem <- struct{}{}
+		go func(t T) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			f(t)
+		}(seq[i])
+	}
+	wg.Wait()
+}
+
 func Any[T any](seq []T, f func(T) bool) bool {
 	for i := range seq {
 		if f(seq[i]) {
@@ -124,6 +124,41 @@ func NewKeyWithDigest(namespace string, setName string, key interface{}, digest
 	return newKey, nil
 }
 
+// NewKeyFromDigest initializes a key from namespace, optional set name and a precomputed digest,
+// without the user key. The server handles record identifiers by digest only.
+func NewKeyFromDigest(namespace string, setName string, digest []byte) (*Key, Error) {
+	return NewKeyWithDigest(namespace, setName, nil, digest)
+}
+
+// ComputeDigest returns the digest of the record identified by the set name and user key,
+// as computed for the keys created with NewKey.
+func ComputeDigest(setName string, key interface{}) ([]byte, Error) {
+	k := Key{
+		setName: setName,
+		userKey: NewValue(key),
+	}
+
+	if err := k.computeDigest(); err != nil {
+		return nil, err
+	}
+	return k.digest[:], nil
+}
+
+// DigestFunc computes the 20 byte digest of a record from its set name and user key.
+type DigestFunc func(setName string, key Value) ([]byte, error)
+
+// customDigest replaces the RIPEMD-160 digest computation of the keys if set.
+var customDigest DigestFunc
+
+// SetDigestFunc replaces the function used to compute the digests of the keys.
+// This allows using records keyed by digests computed externally with a different scheme.
+// All clients using the same cluster must use the same function, since the records are
+// distributed and identified by the digest only.
+// It must be called before any key is created. Pass nil to restore the default.
+func SetDigestFunc(f DigestFunc) {
+	customDigest = f
+}
+
 // SetDigest sets a custom hash
 func (ky *Key) SetDigest(digest []byte) Error {
 	if len(digest) != 20 {
@@ -136,6 +171,14 @@ func (ky *Key) SetDigest(digest []byte) Error {
 // Generate unique server hash value from set name, key type and user defined key.
 // The hash function is RIPEMD-160 (a 160 bit hash).
 func (ky *Key) computeDigest() Error {
+	if customDigest != nil {
+		digest, err := customDigest(ky.setName, ky.userKey)
+		if err != nil {
+			return newCommonError(err)
+		}
+		return ky.SetDigest(digest)
+	}
+
 	// With custom changes to the ripemd160 package,
 	// now the following line does not allocate on the heap anymore/.
 	ky.keyWriter.hash.Reset()
@@ -138,4 +138,44 @@ var _ = gg.Describe("Key Test", func() {
 
 	})
 
+	gg.Context("Digest utilities", func() {
+
+		gg.It("must compute the same digest as the keys", func() {
+			digest, err := as.ComputeDigest("set", "Aerospike")
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+
+			key, _ := as.NewKey("namespace", "set", "Aerospike")
+			gm.Expect(digest).To(gm.Equal(key.Digest()))
+
+			_, err = as.ComputeDigest("set", []interface{}{})
+			gm.Expect(err).To(gm.HaveOccurred())
+		})
+
+		gg.It("must create keys from digests without the user key", func() {
+			key, err := as.NewKeyFromDigest("namespace", "set", []byte("01234567890123456789"))
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(key.Digest()).To(gm.Equal([]byte("01234567890123456789")))
+			gm.Expect(key.Value().GetObject()).To(gm.BeNil())
+
+			_, err = as.NewKeyFromDigest("namespace", "set", []byte("0123"))
+			gm.Expect(err).To(gm.HaveOccurred())
+		})
+
+		gg.It("must use the custom digest function", func() {
+			as.SetDigestFunc(func(setName string, key as.Value) ([]byte, error) {
+				return []byte(strings.Repeat("x", 20)), nil
+			})
+			defer as.SetDigestFunc(nil)
+
+			key, err := as.NewKey("namespace", "set", 1)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(key.Digest()).To(gm.Equal([]byte(strings.Repeat("x", 20))))
+
+			digest, err := as.ComputeDigest("set", 1)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(digest).To(gm.Equal(key.Digest()))
+		})
+
+	})
+
 })
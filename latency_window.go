@@ -0,0 +1,76 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"math"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// latencyWindowSize is the number of the most recent latencies kept per node.
+const latencyWindowSize = 1024
+
+// latencyWindow keeps the latencies of the most recent successful attempts of single record
+// commands to a node. The samples are sorted once per tend, so that the percentiles
+// can be looked up by the commands without sorting.
+type latencyWindow struct {
+	count   atomic.Uint64
+	samples [latencyWindowSize]atomic.Int64
+
+	sorted atomic.Pointer[[]time.Duration]
+}
+
+// add records the latency of an attempt.
+func (lw *latencyWindow) add(latency time.Duration) {
+	i := lw.count.Add(1) - 1
+	lw.samples[i%latencyWindowSize].Store(int64(latency))
+}
+
+// publish sorts the current samples for the percentile lookups.
+func (lw *latencyWindow) publish() {
+	n := lw.count.Load()
+	if n == 0 {
+		return
+	}
+	if n > latencyWindowSize {
+		n = latencyWindowSize
+	}
+
+	sorted := make([]time.Duration, n)
+	for i := range sorted {
+		sorted[i] = time.Duration(lw.samples[i].Load())
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	lw.sorted.Store(&sorted)
+}
+
+// percentile returns the p-th percentile of the published samples.
+// It returns false if fewer than minSamples samples were published.
+func (lw *latencyWindow) percentile(p float64, minSamples int) (time.Duration, bool) {
+	sorted := lw.sorted.Load()
+	if sorted == nil || len(*sorted) == 0 || len(*sorted) < minSamples {
+		return 0, false
+	}
+
+	idx := int(math.Ceil(p/100*float64(len(*sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	} else if idx >= len(*sorted) {
+		idx = len(*sorted) - 1
+	}
+	return (*sorted)[idx], true
+}
@@ -71,6 +71,12 @@ func (lgr *logger) SetLevel(level LogPriority) {
 	lgr.level = level
 }
 
+// IsLevelEnabled returns true if messages at the level requested will be logged.
+// It can be used to avoid building expensive log messages that would be discarded.
+func (lgr *logger) IsLevelEnabled(level LogPriority) bool {
+	return level != OFF && lgr.level <= level
+}
+
 // LogAtLevel will logs a message at the level requested.
 func (lgr *logger) LogAtLevel(level LogPriority, format string, v ...interface{}) {
 	switch level {
@@ -24,6 +24,8 @@ import (
 )
 
 type sessionInfo struct {
+	// user the session was created for
+	user       string
 	token      []byte
 	expiration time.Time
 }
@@ -46,6 +48,9 @@ type loginCommand struct {
 
 	// SessionExpiration for the current session on the external authentication server.
 	SessionExpiration time.Time
+
+	// user of the last login
+	user string
 }
 
 func newLoginCommand(buf []byte) *loginCommand {
@@ -56,7 +61,7 @@ func newLoginCommand(buf []byte) *loginCommand {
 
 func (lcmd *loginCommand) sessionInfo() *sessionInfo {
 	if lcmd.SessionToken != nil {
-		return &sessionInfo{token: lcmd.SessionToken, expiration: lcmd.SessionExpiration}
+		return &sessionInfo{user: lcmd.user, token: lcmd.SessionToken, expiration: lcmd.SessionExpiration}
 	}
 	return &sessionInfo{}
 }
@@ -64,27 +69,35 @@ func (lcmd *loginCommand) sessionInfo() *sessionInfo {
 // Login tries to authenticate to the aerospike server. Depending on the server configuration and ClientPolicy,
 // the session information will be returned.
 func (lcmd *loginCommand) Login(policy *ClientPolicy, conn *Connection) Error {
-	hashedPass, err := hashPassword(policy.Password)
+	creds, err := newAuthCredentials(policy.User, policy.Password)
 	if err != nil {
 		return err
 	}
 
-	return lcmd.login(policy, conn, hashedPass)
+	return lcmd.login(policy, conn, creds)
 }
 
 // Login tries to authenticate to the aerospike server. Depending on the server configuration and ClientPolicy,
 // the session information will be returned.
-func (lcmd *loginCommand) login(policy *ClientPolicy, conn *Connection, hashedPass []byte) Error {
+func (lcmd *loginCommand) login(policy *ClientPolicy, conn *Connection, creds *authCredentials) Error {
+	if creds == nil && policy.AuthMode != AuthModePKI {
+		return newError(types.NOT_AUTHENTICATED, "no credentials to login with")
+	}
+
+	if creds != nil {
+		lcmd.user = creds.user
+	}
+
 	switch policy.AuthMode {
 	case AuthModeExternal:
 		lcmd.writeHeader(_LOGIN, 3)
-		lcmd.writeFieldStr(_USER, policy.User)
-		lcmd.writeFieldBytes(_CREDENTIAL, hashedPass)
-		lcmd.writeFieldStr(_CLEAR_PASSWORD, policy.Password)
+		lcmd.writeFieldStr(_USER, creds.user)
+		lcmd.writeFieldBytes(_CREDENTIAL, creds.hash)
+		lcmd.writeFieldStr(_CLEAR_PASSWORD, creds.password)
 	case AuthModeInternal:
 		lcmd.writeHeader(_LOGIN, 2)
-		lcmd.writeFieldStr(_USER, policy.User)
-		lcmd.writeFieldBytes(_CREDENTIAL, hashedPass)
+		lcmd.writeFieldStr(_USER, creds.user)
+		lcmd.writeFieldBytes(_CREDENTIAL, creds.hash)
 	case AuthModePKI:
 		lcmd.writeHeader(_LOGIN, 0)
 	default:
@@ -169,8 +182,8 @@ func (lcmd *loginCommand) login(policy *ClientPolicy, conn *Connection, hashedPa
 	return nil
 }
 
-func (lcmd *loginCommand) authenticateViaToken(policy *ClientPolicy, conn *Connection, sessionToken []byte) Error {
-	lcmd.setAuthenticate(policy, sessionToken)
+func (lcmd *loginCommand) authenticateViaToken(policy *ClientPolicy, conn *Connection, user string, sessionToken []byte) Error {
+	lcmd.setAuthenticate(policy, user, sessionToken)
 
 	if _, err := conn.Write(lcmd.dataBuffer[:lcmd.dataOffset]); err != nil {
 		return err
@@ -188,10 +201,10 @@ func (lcmd *loginCommand) authenticateViaToken(policy *ClientPolicy, conn *Conne
 	return nil
 }
 
-func (lcmd *loginCommand) setAuthenticate(policy *ClientPolicy, sessionToken []byte) Error {
+func (lcmd *loginCommand) setAuthenticate(policy *ClientPolicy, user string, sessionToken []byte) Error {
 	if policy.AuthMode != AuthModePKI {
 		lcmd.writeHeader(_AUTHENTICATE, 2)
-		lcmd.writeFieldStr(_USER, policy.User)
+		lcmd.writeFieldStr(_USER, user)
 	} else {
 		lcmd.writeHeader(_AUTHENTICATE, 1)
 	}
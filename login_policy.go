@@ -0,0 +1,65 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+)
+
+// LoginPolicy protects the cluster from the login storms of a client when its credentials are
+// rejected, e.g. during a credential outage, when all connections without a valid session token
+// try to login again at the same time. See ClientPolicy.LoginPolicy.
+type LoginPolicy struct {
+	// InitialBackoff is the maximum time the logins are suspended after a login is rejected due to the
+	// credentials. The logins attempted in the meantime fail immediately with ErrLoginThrottled.
+	// The actual delay is random up to the backoff, and the backoff grows by Multiplier after each
+	// rejected login. A successful login resets the backoff.
+	//
+	// Default: 1 second
+	InitialBackoff time.Duration //= 1 second
+
+	// MaxBackoff is the maximum backoff between the logins.
+	//
+	// Default: 30 seconds
+	MaxBackoff time.Duration //= 30 seconds
+
+	// Multiplier is the factor the backoff grows by after each rejected login.
+	//
+	// Default: 2
+	Multiplier float64 //= 2
+
+	// MaxConcurrentLogins limits the number of logins in progress at the same time to all the nodes
+	// of the cluster. Logins wait up to ClientPolicy.LoginTimeout for their turn.
+	// If zero, the number is not limited.
+	//
+	// Default: 4
+	MaxConcurrentLogins int //= 4
+
+	// OnFailure is called after each failed login with the host of the node and the error.
+	// It must not block.
+	//
+	// Default: nil
+	OnFailure func(host *Host, err Error)
+}
+
+// NewLoginPolicy returns a policy with the default values.
+func NewLoginPolicy() *LoginPolicy {
+	return &LoginPolicy{
+		InitialBackoff:      time.Second,
+		MaxBackoff:          30 * time.Second,
+		Multiplier:          2,
+		MaxConcurrentLogins: 4,
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"sync"
+	"time"
+
+	iatomic "github.com/aerospike/aerospike-client-go/v7/internal/atomic"
+	"github.com/aerospike/aerospike-client-go/v7/logger"
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// loginThrottle suspends the logins with a jittered exponential backoff after the credentials
+// are rejected, and limits the logins in progress at the same time.
+type loginThrottle struct {
+	policy LoginPolicy
+
+	// slots for the logins in progress; nil if not limited
+	logins chan struct{}
+
+	mutex     sync.Mutex
+	backoff   time.Duration
+	nextLogin time.Time
+	lastErr   Error
+
+	failed    iatomic.Int // number of failed logins
+	throttled iatomic.Int // number of logins not attempted due to the backoff
+	waited    iatomic.Int // number of logins which waited for a slot
+}
+
+func newLoginThrottle(policy LoginPolicy) *loginThrottle {
+	def := NewLoginPolicy()
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = def.InitialBackoff
+	}
+	if policy.MaxBackoff < policy.InitialBackoff {
+		policy.MaxBackoff = policy.InitialBackoff
+	}
+	if policy.Multiplier < 1 {
+		policy.Multiplier = def.Multiplier
+	}
+
+	t := &loginThrottle{policy: policy}
+	if policy.MaxConcurrentLogins > 0 {
+		t.logins = make(chan struct{}, policy.MaxConcurrentLogins)
+	}
+	return t
+}
+
+// isCredentialError returns true if the server rejected the login due to the credentials.
+// Network errors and timeouts do not suspend the logins.
+func isCredentialError(err Error) bool {
+	return err.Matches(types.NOT_AUTHENTICATED, types.INVALID_USER, types.INVALID_PASSWORD,
+		types.EXPIRED_PASSWORD, types.INVALID_CREDENTIAL, types.EXPIRED_SESSION)
+}
+
+// acquire returns an error if the logins are suspended at the time, or waits up to the timeout
+// for a slot to login.
+func (t *loginThrottle) acquire(now time.Time, timeout time.Duration) Error {
+	if t == nil {
+		return nil
+	}
+
+	t.mutex.Lock()
+	suspended, lastErr := now.Before(t.nextLogin), t.lastErr
+	t.mutex.Unlock()
+
+	if suspended {
+		t.throttled.IncrementAndGet()
+		return chainErrors(ErrLoginThrottled.err(), lastErr)
+	}
+
+	if t.logins == nil {
+		return nil
+	}
+
+	select {
+	case t.logins <- struct{}{}:
+		return nil
+	default:
+	}
+
+	t.waited.IncrementAndGet()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case t.logins <- struct{}{}:
+		return nil
+	case <-timer.C:
+		t.throttled.IncrementAndGet()
+		return ErrLoginThrottled.err()
+	}
+}
+
+// release frees the slot of a login, and records its result.
+func (t *loginThrottle) release(now time.Time, host *Host, err Error) {
+	if t == nil {
+		return
+	}
+
+	if t.logins != nil {
+		<-t.logins
+	}
+
+	t.mutex.Lock()
+	if err == nil {
+		t.backoff = 0
+		t.nextLogin = time.Time{}
+		t.lastErr = nil
+		t.mutex.Unlock()
+		return
+	}
+
+	if isCredentialError(err) {
+		if t.backoff == 0 {
+			t.backoff = t.policy.InitialBackoff
+		} else {
+			t.backoff = time.Duration(float64(t.backoff) * t.policy.Multiplier)
+			if t.backoff > t.policy.MaxBackoff {
+				t.backoff = t.policy.MaxBackoff
+			}
+		}
+		t.nextLogin = now.Add(jitter(t.backoff))
+		t.lastErr = err
+	}
+	t.mutex.Unlock()
+
+	t.failed.IncrementAndGet()
+	logger.Logger.Warn("Login to node %s failed: %s", host, err)
+	if t.policy.OnFailure != nil {
+		t.policy.OnFailure(host, err)
+	}
+}
+
+// login authenticates the connection with the current credentials of the cluster.
+// The logins are throttled if ClientPolicy.LoginPolicy is set.
+func (clstr *Cluster) login(policy *ClientPolicy, conn *Connection, host *Host, command *loginCommand) Error {
+	timeout := policy.LoginTimeout
+	if timeout <= 0 {
+		timeout = policy.Timeout
+	}
+
+	if err := clstr.loginThrottle.acquire(clstr.clock().Now(), timeout); err != nil {
+		return err
+	}
+
+	creds, err := clstr.loginCredentials()
+	if err == nil {
+		err = command.login(policy, conn, creds)
+	}
+
+	clstr.loginThrottle.release(clstr.clock().Now(), host, err)
+	return err
+}
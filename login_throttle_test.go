@@ -0,0 +1,125 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Login policy", func() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	host := NewHost("127.0.0.1", 3000)
+
+	gg.It("must suspend the logins after the credentials are rejected", func() {
+		var failures []Error
+
+		policy := NewLoginPolicy()
+		policy.InitialBackoff = time.Second
+		policy.MaxBackoff = 3 * time.Second
+		policy.OnFailure = func(h *Host, err Error) {
+			gm.Expect(h).To(gm.Equal(host))
+			failures = append(failures, err)
+		}
+		t := newLoginThrottle(*policy)
+
+		gm.Expect(t.acquire(now, time.Second)).ToNot(gm.HaveOccurred())
+		t.release(now, host, newError(types.INVALID_CREDENTIAL))
+		gm.Expect(t.backoff).To(gm.Equal(time.Second))
+		gm.Expect(t.nextLogin).To(gm.BeTemporally(">", now))
+		gm.Expect(t.nextLogin).To(gm.BeTemporally("<=", now.Add(time.Second)))
+		gm.Expect(t.failed.Get()).To(gm.Equal(1))
+		gm.Expect(failures).To(gm.HaveLen(1))
+
+		// the logins fail immediately until the backoff is over
+		err := t.acquire(now, time.Second)
+		gm.Expect(errors.Is(err, ErrLoginThrottled)).To(gm.BeTrue())
+		gm.Expect(err.Matches(types.INVALID_CREDENTIAL)).To(gm.BeTrue())
+		gm.Expect(t.throttled.Get()).To(gm.Equal(1))
+
+		// the backoff grows after each rejected login, up to the maximum
+		now = t.nextLogin
+		gm.Expect(t.acquire(now, time.Second)).ToNot(gm.HaveOccurred())
+		t.release(now, host, newError(types.EXPIRED_SESSION))
+		gm.Expect(t.backoff).To(gm.Equal(2 * time.Second))
+		now = t.nextLogin
+		gm.Expect(t.acquire(now, time.Second)).ToNot(gm.HaveOccurred())
+		t.release(now, host, newError(types.NOT_AUTHENTICATED))
+		gm.Expect(t.backoff).To(gm.Equal(3 * time.Second))
+
+		// network errors are counted, but do not grow the backoff
+		now = t.nextLogin
+		gm.Expect(t.acquire(now, time.Second)).ToNot(gm.HaveOccurred())
+		t.release(now, host, ErrNetTimeout.err())
+		gm.Expect(t.backoff).To(gm.Equal(3 * time.Second))
+		gm.Expect(t.acquire(now, time.Second)).ToNot(gm.HaveOccurred())
+		gm.Expect(t.failed.Get()).To(gm.Equal(4))
+		gm.Expect(failures).To(gm.HaveLen(4))
+
+		// a successful login resets the backoff
+		t.release(now, host, nil)
+		gm.Expect(t.backoff).To(gm.BeZero())
+		gm.Expect(t.acquire(now, time.Second)).ToNot(gm.HaveOccurred())
+		t.release(now, host, nil)
+
+		var nilThrottle *loginThrottle
+		gm.Expect(nilThrottle.acquire(now, 0)).ToNot(gm.HaveOccurred())
+		nilThrottle.release(now, host, ErrNetTimeout.err())
+	})
+
+	gg.It("must limit the logins in progress at the same time", func() {
+		policy := NewLoginPolicy()
+		policy.MaxConcurrentLogins = 2
+		t := newLoginThrottle(*policy)
+
+		gm.Expect(t.acquire(now, time.Second)).ToNot(gm.HaveOccurred())
+		gm.Expect(t.acquire(now, time.Second)).ToNot(gm.HaveOccurred())
+
+		err := t.acquire(now, 10*time.Millisecond)
+		gm.Expect(errors.Is(err, ErrLoginThrottled)).To(gm.BeTrue())
+		gm.Expect(t.waited.Get()).To(gm.Equal(1))
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			t.release(now, host, nil)
+		}()
+		gm.Expect(t.acquire(now, time.Second)).ToNot(gm.HaveOccurred())
+		gm.Expect(t.waited.Get()).To(gm.Equal(2))
+	})
+
+	gg.It("must throttle the logins of the cluster when the credentials are unavailable", func() {
+		policy := NewClientPolicy()
+		policy.LoginPolicy = NewLoginPolicy()
+		policy.CredentialsProvider = CredentialsProviderFunc(func(ctx context.Context) (string, string, error) {
+			return "", "", errors.New("vault is sealed")
+		})
+		clstr := &Cluster{clientPolicy: *policy, loginThrottle: newLoginThrottle(*policy.LoginPolicy)}
+
+		err := clstr.login(&clstr.clientPolicy, nil, host, newLoginCommand(make([]byte, 512)))
+		gm.Expect(err.Matches(types.NOT_AUTHENTICATED)).To(gm.BeTrue())
+		gm.Expect(clstr.loginThrottle.failed.Get()).To(gm.Equal(1))
+
+		err = clstr.login(&clstr.clientPolicy, nil, host, newLoginCommand(make([]byte, 512)))
+		gm.Expect(errors.Is(err, ErrLoginThrottled)).To(gm.BeTrue())
+		gm.Expect(clstr.loginThrottle.throttled.Get()).To(gm.Equal(1))
+	})
+})
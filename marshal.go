@@ -18,10 +18,13 @@ package aerospike
 
 import (
 	"fmt"
+	"math"
+	"math/big"
 	"reflect"
 	"strings"
 	"sync"
 	"time"
+	"unicode"
 )
 
 // UseNativeBoolTypeInReflection determines if Boolean values should be directly translated to native Boolean type in reflection API
@@ -36,8 +39,17 @@ const (
 	aerospikeMetaTag    = "asm"
 	aerospikeMetaTagGen = "gen"
 	aerospikeMetaTagTTL = "ttl"
+
+	// aerospikeMetaTagPrefix flattens the fields of a nested struct into
+	// the bins of the parent, prefixing their names: `asm:"prefix=addr_"`
+	aerospikeMetaTagPrefix = "prefix="
 )
 
+var bigIntType = reflect.TypeOf(big.Int{})
+
+// nameMapper maps the names of the untagged struct fields to bin names.
+var nameMapper func(fieldName string) string
+
 // This method is copied verbatim from https://golang.org/src/encoding/json/encode.go
 // to ensure compatibility with the json package.
 func isEmptyValue(v reflect.Value) bool {
@@ -66,6 +78,35 @@ func SetAerospikeTag(tag string) {
 	aerospikeTag = tag
 }
 
+// SetNameMapper sets the function used by the reflection API to map the names of the struct
+// fields without an alias in their tag to bin names, e.g. SnakeCaseNameMapper to follow
+// a snake_case naming convention. Aliases set in the tags are always used verbatim.
+// The mapper applies to all clients, and should be set before the objects are used.
+// Passing nil restores the default behavior of using the field names as bin names.
+func SetNameMapper(mapper func(fieldName string) string) {
+	nameMapper = mapper
+	objectMappings.reset()
+}
+
+// SnakeCaseNameMapper maps a Go field name to snake_case, e.g. UserID to user_id and
+// HTTPServer to http_server. It can be passed to SetNameMapper.
+func SnakeCaseNameMapper(fieldName string) string {
+	runes := []rune(fieldName)
+	var sb strings.Builder
+	sb.Grow(len(fieldName) + 4)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
 func valueToInterface(f reflect.Value) interface{} {
 	// get to the core value
 	for f.Kind() == reflect.Ptr {
@@ -79,13 +120,20 @@ func valueToInterface(f reflect.Value) interface{} {
 	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8:
 		return IntegerValue(f.Int())
 	case reflect.Uint64, reflect.Uint, reflect.Uint8, reflect.Uint32, reflect.Uint16:
+		if u := f.Uint(); u > math.MaxInt64 {
+			return NewUint64Value(u)
+		}
 		return int64(f.Uint())
 	case reflect.Float64, reflect.Float32:
 		return FloatValue(f.Float())
 
 	case reflect.Struct:
 		if f.Type().PkgPath() == "time" && f.Type().Name() == "Time" {
-			return f.Interface().(time.Time).UTC().UnixNano()
+			return timeToInterface(f.Interface().(time.Time))
+		}
+		if f.Type() == bigIntType {
+			v := f.Interface().(big.Int)
+			return NewBigIntValue(&v)
 		}
 		return structToMap(f)
 	case reflect.Bool:
@@ -137,11 +185,42 @@ func fieldIsMetadata(f reflect.StructField) bool {
 	return strings.Trim(meta, " ") != ""
 }
 
+// fieldPrefix returns the prefix of the bins of a flattened struct field.
+func fieldPrefix(f reflect.StructField) (string, bool) {
+	meta := strings.Trim(f.Tag.Get(aerospikeMetaTag), " ")
+	if !strings.HasPrefix(meta, aerospikeMetaTagPrefix) {
+		return "", false
+	}
+
+	if f.Type.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("Cannot flatten the non-struct attribute on struct: %s", f.Name))
+	}
+	return strings.TrimPrefix(meta, aerospikeMetaTagPrefix), true
+}
+
 func fieldIsOmitOnEmpty(f reflect.StructField) bool {
 	tag := f.Tag.Get(aerospikeTag)
 	return strings.Contains(tag, ",omitempty")
 }
 
+func fieldIsOmitOnZero(f reflect.StructField) bool {
+	tag := f.Tag.Get(aerospikeTag)
+	return strings.Contains(tag, ",omitzero")
+}
+
+// isZeroValue reports if the value is the zero value of its type, or if it
+// implements an `IsZero() bool` method returning true, like time.Time.
+func isZeroValue(v reflect.Value) bool {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return true
+	}
+
+	if z, ok := v.Interface().(interface{ IsZero() bool }); ok {
+		return z.IsZero()
+	}
+	return v.IsZero()
+}
+
 func stripOptions(tag string) string {
 	i := strings.Index(tag, ",")
 	if i < 0 {
@@ -159,10 +238,14 @@ func fieldAlias(f reflect.StructField) string {
 		}
 		return alias
 	}
+
+	if nameMapper != nil {
+		return nameMapper(f.Name)
+	}
 	return f.Name
 }
 
-func setBinMap(s reflect.Value, typeOfT reflect.Type, binMap BinMap, index []int) {
+func setBinMap(s reflect.Value, typeOfT reflect.Type, binMap BinMap, index []int, prefix string) {
 	numFields := typeOfT.NumField()
 	var fld reflect.StructField
 	for i := 0; i < numFields; i++ {
@@ -170,8 +253,13 @@ func setBinMap(s reflect.Value, typeOfT reflect.Type, binMap BinMap, index []int
 
 		fldIndex := append(index, fld.Index...)
 
+		if fldPrefix, ok := fieldPrefix(fld); ok {
+			setBinMap(s, fld.Type, binMap, fldIndex, prefix+fldPrefix)
+			continue
+		}
+
 		if fld.Anonymous && fld.Type.Kind() == reflect.Struct {
-			setBinMap(s, fld.Type, binMap, fldIndex)
+			setBinMap(s, fld.Type, binMap, fldIndex, prefix)
 			continue
 		}
 
@@ -189,12 +277,17 @@ func setBinMap(s reflect.Value, typeOfT reflect.Type, binMap BinMap, index []int
 		if alias == "" {
 			continue
 		}
+		alias = prefix + alias
 
 		value := s.FieldByIndex(fldIndex)
 		if fieldIsOmitOnEmpty(fld) && isEmptyValue(value) {
 			continue
 		}
 
+		if fieldIsOmitOnZero(fld) && isZeroValue(value) {
+			continue
+		}
+
 		binValue := valueToInterface(value)
 
 		if _, ok := binMap[alias]; ok {
@@ -211,7 +304,7 @@ func structToMap(s reflect.Value) BinMap {
 
 	binMap := make(BinMap, s.NumField())
 
-	setBinMap(s, s.Type(), binMap, nil)
+	setBinMap(s, s.Type(), binMap, nil, "")
 
 	return binMap
 }
@@ -238,6 +331,16 @@ func (sm *syncMap) setMapping(objType reflect.Type, mapping map[string][]int, fi
 	sm.mutex.Unlock()
 }
 
+// reset clears the cached mappings, e.g. after the name mapper has changed.
+func (sm *syncMap) reset() {
+	sm.mutex.Lock()
+	sm.objectMappings = map[reflect.Type]map[string][]int{}
+	sm.objectFields = map[reflect.Type][]string{}
+	sm.objectTTLs = map[reflect.Type][][]int{}
+	sm.objectGen = map[reflect.Type][][]int{}
+	sm.mutex.Unlock()
+}
+
 func indirect(obj reflect.Value) reflect.Value {
 	for obj.Kind() == reflect.Ptr {
 		if obj.IsNil() {
@@ -311,13 +414,21 @@ var objectMappings = &syncMap{
 	objectGen:      map[reflect.Type][][]int{},
 }
 
-func fillMapping(objType reflect.Type, mapping map[string][]int, fields []string, ttl, gen [][]int, index []int) ([]string, [][]int, [][]int) {
+func fillMapping(objType reflect.Type, mapping map[string][]int, fields []string, ttl, gen [][]int, index []int, prefix string) ([]string, [][]int, [][]int) {
 	numFields := objType.NumField()
 	for i := 0; i < numFields; i++ {
 		f := objType.Field(i)
 		fIndex := append(index, f.Index...)
+		if fPrefix, ok := fieldPrefix(f); ok {
+			if strings.Trim(stripOptions(f.Tag.Get(aerospikeTag)), " ") != "" {
+				panic(fmt.Sprintf("Cannot accept both data and metadata tags on the same attribute on struct: %s.%s", objType.Name(), f.Name))
+			}
+			fields, ttl, gen = fillMapping(f.Type, mapping, fields, ttl, gen, fIndex, prefix+fPrefix)
+			continue
+		}
+
 		if f.Anonymous && f.Type.Kind() == reflect.Struct {
-			fields, ttl, gen = fillMapping(f.Type, mapping, fields, ttl, gen, fIndex)
+			fields, ttl, gen = fillMapping(f.Type, mapping, fields, ttl, gen, fIndex, prefix)
 			continue
 		}
 
@@ -334,9 +445,7 @@ func fillMapping(objType reflect.Type, mapping map[string][]int, fields []string
 		}
 
 		if tag != "-" && tagM == "" {
-			if tag == "" {
-				tag = f.Name
-			}
+			tag = prefix + fieldAlias(f)
 			if _, ok := mapping[tag]; ok {
 				panic(fmt.Sprintf("ambiguous fields with the same name or alias: %s", tag))
 			}
@@ -357,6 +466,6 @@ func fillMapping(objType reflect.Type, mapping map[string][]int, fields []string
 
 func cacheObjectTags(objType reflect.Type) {
 	mapping := map[string][]int{}
-	fields, ttl, gen := fillMapping(objType, mapping, []string{}, nil, nil, nil)
+	fields, ttl, gen := fillMapping(objType, mapping, []string{}, nil, nil, nil, "")
 	objectMappings.setMapping(objType, mapping, fields, ttl, gen)
 }
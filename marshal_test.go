@@ -0,0 +1,151 @@
+//go:build !as_performance
+
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"math/big"
+	"reflect"
+	"time"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+type marshalAddress struct {
+	Street string `as:"street"`
+	City   string
+}
+
+type marshalFriend struct {
+	Name string         `as:"name"`
+	Home marshalAddress `asm:"prefix=home_"`
+}
+
+type marshalPerson struct {
+	Name    string          `as:"name"`
+	Nick    string          `as:"nick,omitempty"`
+	Born    time.Time       `as:"born,omitzero"`
+	Home    marshalAddress  `asm:"prefix=home_"`
+	Work    marshalAddress  `asm:"prefix=work_"`
+	Friends []marshalFriend `as:"friends"`
+}
+
+type marshalNative struct {
+	At      time.Time     `as:"at"`
+	AtPtr   *time.Time    `as:"atp"`
+	Timeout time.Duration `as:"timeout"`
+	Big     big.Int       `as:"big"`
+	BigPtr  *big.Int      `as:"bigp"`
+	Max     uint64        `as:"max"`
+}
+
+type marshalMapped struct {
+	UserID     int
+	HTTPServer string
+	Alias      string `as:"AL"`
+}
+
+var _ = gg.Describe("Object marshaller", func() {
+
+	gg.It("must flatten the nested structs with prefixes", func() {
+		obj := &marshalPerson{
+			Name:    "Alice",
+			Home:    marshalAddress{Street: "Main", City: "Springfield"},
+			Work:    marshalAddress{Street: "Elm", City: "Shelbyville"},
+			Friends: []marshalFriend{{Name: "Bob", Home: marshalAddress{Street: "Oak", City: "Ogdenville"}}},
+		}
+
+		bins := marshal(obj)
+		gm.Expect(bins).To(gm.HaveLen(6))
+		gm.Expect(bins["home_street"]).To(gm.Equal("Main"))
+		gm.Expect(bins["home_City"]).To(gm.Equal("Springfield"))
+		gm.Expect(bins["work_street"]).To(gm.Equal("Elm"))
+		gm.Expect(bins["work_City"]).To(gm.Equal("Shelbyville"))
+		gm.Expect(bins["friends"]).To(gm.Equal([]interface{}{BinMap{"name": "Bob", "home_street": "Oak", "home_City": "Ogdenville"}}))
+		gm.Expect(objectMappings.getFields(reflect.TypeOf(obj))).To(gm.Equal([]string{"name", "nick", "born", "home_street", "home_City", "work_street", "work_City", "friends"}))
+
+	})
+
+	gg.It("must omit the zero values tagged omitzero", func() {
+		obj := &marshalPerson{Name: "Alice"}
+		bins := marshal(obj)
+		gm.Expect(bins).ToNot(gm.HaveKey("born"))
+		gm.Expect(bins).ToNot(gm.HaveKey("nick"))
+
+		obj.Born = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		obj.Nick = "Al"
+		bins = marshal(obj)
+		gm.Expect(bins["born"]).To(gm.Equal(obj.Born.UnixNano()))
+		gm.Expect(bins["nick"]).To(gm.Equal("Al"))
+	})
+
+	gg.It("must map the untagged field names with the name mapper", func() {
+		SetNameMapper(SnakeCaseNameMapper)
+		defer SetNameMapper(nil)
+
+		obj := &marshalMapped{UserID: 7, HTTPServer: "srv", Alias: "a"}
+		gm.Expect(marshal(obj)).To(gm.Equal(BinMap{"user_id": IntegerValue(7), "http_server": "srv", "AL": "a"}))
+
+		SetNameMapper(nil)
+		gm.Expect(marshal(obj)).To(gm.HaveKey("UserID"))
+	})
+
+	gg.It("must convert the field names to snake case", func() {
+		for name, expected := range map[string]string{
+			"Name":       "name",
+			"UserID":     "user_id",
+			"HTTPServer": "http_server",
+			"Address2":   "address2",
+			"already_ok": "already_ok",
+		} {
+			gm.Expect(SnakeCaseNameMapper(name)).To(gm.Equal(expected), name)
+		}
+	})
+
+	gg.It("must encode the time as a string with the time encoding of the value policy", func() {
+		defer SetValuePolicy(nil)
+
+		SetValuePolicy(&ValuePolicy{TimeEncoding: TimeAsString})
+		obj := &marshalNative{At: time.Date(2024, 2, 29, 12, 30, 0, 123456789, time.UTC)}
+		gm.Expect(marshal(obj)["at"]).To(gm.Equal("2024-02-29T12:30:00.123456789Z"))
+	})
+
+	gg.It("must fill the fields from the maps decoded per the value policy", func() {
+		var m map[string]int
+		gm.Expect(setValue(reflect.ValueOf(&m).Elem(), map[string]interface{}{"a": 1})).ToNot(gm.HaveOccurred())
+		gm.Expect(m).To(gm.Equal(map[string]int{"a": 1}))
+
+		var om map[int]string
+		gm.Expect(setValue(reflect.ValueOf(&om).Elem(), OrderedMap{{2, "b"}, {1, "a"}})).ToNot(gm.HaveOccurred())
+		gm.Expect(om).To(gm.Equal(map[int]string{1: "a", 2: "b"}))
+
+		var addr marshalAddress
+		gm.Expect(setValue(reflect.ValueOf(&addr).Elem(), map[string]interface{}{"street": "Main"})).ToNot(gm.HaveOccurred())
+		gm.Expect(addr.Street).To(gm.Equal("Main"))
+
+		var ifc interface{}
+		gm.Expect(setValue(reflect.ValueOf(&ifc).Elem(), map[string]interface{}{"a": 1})).ToNot(gm.HaveOccurred())
+		gm.Expect(ifc).To(gm.Equal(map[string]interface{}{"a": 1}))
+	})
+
+	gg.It("must reject flattening non-struct fields", func() {
+		type invalid struct {
+			Name string `asm:"prefix=x_"`
+		}
+		gm.Expect(func() { marshal(&invalid{}) }).To(gm.Panic())
+	})
+})
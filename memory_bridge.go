@@ -0,0 +1,413 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"github.com/aerospike/aerospike-client-go/v7/internal/memclient"
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+func init() {
+	memclient.Bridge = memBridge{}
+}
+
+// memBridge exposes the internals used by the in-memory client of the mock package.
+// The type is unexported, so its methods are only reachable through the internal memclient package.
+type memBridge struct{}
+
+var memOpKinds = map[OperationType]memclient.OpKind{
+	_READ:        memclient.OpRead,
+	_READ_HEADER: memclient.OpReadHeader,
+	_WRITE:       memclient.OpWrite,
+	_CDT_READ:    memclient.OpCDTRead,
+	_CDT_MODIFY:  memclient.OpCDTModify,
+	_MAP_READ:    memclient.OpMapRead,
+	_MAP_MODIFY:  memclient.OpMapModify,
+	_ADD:         memclient.OpAdd,
+	_EXP_READ:    memclient.OpExpRead,
+	_EXP_MODIFY:  memclient.OpExpModify,
+	_APPEND:      memclient.OpAppend,
+	_PREPEND:     memclient.OpPrepend,
+	_TOUCH:       memclient.OpTouch,
+	_BIT_READ:    memclient.OpBitRead,
+	_BIT_MODIFY:  memclient.OpBitModify,
+	_DELETE:      memclient.OpDelete,
+	_HLL_READ:    memclient.OpHLLRead,
+	_HLL_MODIFY:  memclient.OpHLLModify,
+}
+
+// ClientBase returns the value the in-memory client embeds to implement the unexported methods
+// of ClientIfc. The in-memory client implements all the exported methods itself.
+func (memBridge) ClientBase(clnt ClientIfc) ClientIfc {
+	return &memClientBase{clnt: clnt}
+}
+
+// NewError returns a new error with the result code.
+func (memBridge) NewError(code types.ResultCode, messages ...string) Error {
+	return newError(code, messages...)
+}
+
+// WrapError returns a new error with the result code, wrapping err.
+func (memBridge) WrapError(err error, code types.ResultCode, messages ...string) Error {
+	return newErrorAndWrap(err, code, messages...)
+}
+
+// ConstError returns a copy of the constant error, which can be chained.
+func (memBridge) ConstError(err Error) Error {
+	if ce, ok := err.(*constAerospikeError); ok {
+		return ce.err()
+	}
+	return err
+}
+
+// ResultCode returns the result code of the error.
+func (memBridge) ResultCode(err Error) types.ResultCode {
+	return err.resultCode()
+}
+
+// Op returns the kind of the operation, if it writes, and its bin name and value.
+func (memBridge) Op(op *Operation) (kind memclient.OpKind, isWrite bool, binName string, binValue Value) {
+	return memOpKinds[op.opType], op.opType.isWrite, op.binName, op.binValue
+}
+
+// EncodeOp returns the wire encoding of the value of a CDT operation.
+func (memBridge) EncodeOp(op *Operation) ([]byte, Error) {
+	return memEncode(func(buf BufferEx) (int, Error) { return op.encoder(op, buf) })
+}
+
+// PackValue returns the msgpack encoding of the value.
+func (memBridge) PackValue(v Value) ([]byte, Error) {
+	return memEncode(v.pack)
+}
+
+// PackExpression returns the wire encoding of the expression.
+func (memBridge) PackExpression(exp *Expression) ([]byte, Error) {
+	return memEncode(exp.pack)
+}
+
+// Unpack decodes the msgpack value at the offset of the buffer, and returns the offset after it.
+func (memBridge) Unpack(buf []byte, offset int, isMapKey bool) (interface{}, int, Error) {
+	upckr := newUnpacker(buf, offset, len(buf)-offset)
+	v, err := upckr.unpackObject(isMapKey)
+	if err != nil {
+		return nil, offset, err
+	}
+	return v, upckr.offset, nil
+}
+
+// Particle converts the value to the form the client decodes from the server.
+func (memBridge) Particle(v Value) (interface{}, Error) {
+	size, err := v.EstimateSize()
+	if err != nil {
+		return nil, err
+	}
+	buf := newBuffer(size)
+	if _, err := v.write(buf); err != nil {
+		return nil, err
+	}
+	b := buf.Bytes()
+	return bytesToParticle(v.GetType(), b, 0, len(b))
+}
+
+// Filter returns the bin name and the range of the secondary index filter,
+// and if it has a CDT context.
+func (memBridge) Filter(f *Filter) (binName string, begin, end Value, hasCtx bool) {
+	return f.name, f.begin, f.end, len(f.ctx) > 0
+}
+
+// AggregateFunction returns the name of the aggregate function of the statement.
+func (memBridge) AggregateFunction(stmt *Statement) string {
+	return stmt.functionName
+}
+
+// BatchWritePolicy returns the write policy of the commands of a batch.
+func (memBridge) BatchWritePolicy(policy *BatchPolicy) *WritePolicy {
+	return policy.toWritePolicy()
+}
+
+// BatchReadWritePolicy returns the write policy of a batch read command.
+func (memBridge) BatchReadWritePolicy(policy *BatchReadPolicy, parent *BatchPolicy) *WritePolicy {
+	return policy.toWritePolicy(parent)
+}
+
+// BatchWriteWritePolicy returns the write policy of a batch write command.
+func (memBridge) BatchWriteWritePolicy(policy *BatchWritePolicy, parent *BatchPolicy) *WritePolicy {
+	return policy.toWritePolicy(parent)
+}
+
+// BatchDeleteWritePolicy returns the write policy of a batch delete command.
+func (memBridge) BatchDeleteWritePolicy(policy *BatchDeletePolicy, parent *BatchPolicy) *WritePolicy {
+	return policy.toWritePolicy(parent)
+}
+
+// NewKey returns the key of a stored record. The user key is nil if it was not stored.
+func (memBridge) NewKey(namespace, setName string, userKey Value, digest [20]byte) *Key {
+	return &Key{namespace: namespace, setName: setName, userKey: userKey, digest: digest}
+}
+
+// NewRecord returns a record read by a command.
+func (memBridge) NewRecord(key *Key, bins BinMap, generation, expiration uint32) *Record {
+	return newRecord(nil, key, bins, generation, expiration)
+}
+
+// NewBatchRecord returns a new batch record for the key.
+func (memBridge) NewBatchRecord(key *Key, hasWrite bool) *BatchRecord {
+	return newSimpleBatchRecord(key, hasWrite)
+}
+
+// PrepareBatchRecord resets the results of the batch record before the command.
+func (memBridge) PrepareBatchRecord(br BatchRecordIfc) {
+	br.prepare()
+}
+
+// SetBatchRecord sets the record read or written by the batch command.
+func (memBridge) SetBatchRecord(br BatchRecordIfc, rec *Record) {
+	br.setRecord(rec)
+}
+
+// SetBatchError sets the result code of the failed batch command.
+func (memBridge) SetBatchError(br BatchRecordIfc, err Error) {
+	br.setError(nil, err.resultCode(), false)
+}
+
+// SetBatchErrorWithMsg sets the result code and message of the failed batch command.
+func (memBridge) SetBatchErrorWithMsg(br BatchRecordIfc, err Error) {
+	br.setErrorWithMsg(nil, err.resultCode(), err.Error(), false)
+}
+
+// ExecuteTask returns a completed task for the background query.
+func (memBridge) ExecuteTask(taskID uint64, scan bool) *ExecuteTask {
+	return &ExecuteTask{baseTask: newCompletedTask(), taskID: taskID, scan: scan}
+}
+
+// RegisterTask returns a completed task for the registered UDF packages and their hashes.
+func (memBridge) RegisterTask(packages map[string]string) *RegisterTask {
+	return &RegisterTask{baseTask: newCompletedTask(), packages: packages}
+}
+
+// RemoveTask returns a completed task for the removed UDF package.
+func (memBridge) RemoveTask(packageName string) *RemoveTask {
+	return &RemoveTask{baseTask: newCompletedTask(), packageName: packageName}
+}
+
+// IndexTask returns a completed task for the created or dropped index.
+func (memBridge) IndexTask(namespace, indexName string) *IndexTask {
+	return &IndexTask{baseTask: newCompletedTask(), namespace: namespace, indexName: indexName}
+}
+
+// NewRecordset returns a new recordset, fed by a single goroutine.
+func (memBridge) NewRecordset(queueSize int) *Recordset {
+	return newRecordset(queueSize, 1)
+}
+
+// SendRecord queues the record for the consumer of the recordset.
+// It returns false if the recordset was closed.
+func (memBridge) SendRecord(rs *Recordset, rec *Record) bool {
+	return rs.send(&Result{Record: rec})
+}
+
+// SendError sends the error to the consumer of the recordset.
+func (memBridge) SendError(rs *Recordset, err Error) {
+	rs.sendError(err)
+}
+
+// SignalEnd closes the recordset after the last result.
+func (memBridge) SignalEnd(rs *Recordset) {
+	rs.signalEnd()
+}
+
+// QuerySorted runs the query with the sort of the policy.
+func (memBridge) QuerySorted(policy *QueryPolicy, run func(policy *QueryPolicy) (*Recordset, Error)) (*Recordset, Error) {
+	return querySorted(policy, run)
+}
+
+// BatchExistsChunks checks the keys in chunks, and reports the result of each key to fn.
+func (memBridge) BatchExistsChunks(keys []*Key, chunkSize int, exists func(keys []*Key, existsArray []bool) Error, fn func(index int, exists bool) bool) Error {
+	return batchExistsChunks(keys, chunkSize, exists, fn)
+}
+
+// ExistingDigests returns the digests of the keys that exist.
+func (memBridge) ExistingDigests(keys []*Key, existsArray []bool) [][]byte {
+	return existingDigests(keys, existsArray)
+}
+
+// SortedSetInfos returns the sets sorted by name.
+func (memBridge) SortedSetInfos(sets map[string]*SetInfo, replicationFactor int64) []*SetInfo {
+	return sortedSetInfos(sets, replicationFactor)
+}
+
+// ValidateOpResultsOps validates the operations of OperateWithResults.
+func (memBridge) ValidateOpResultsOps(operations []*Operation) Error {
+	return validateOpResultsOps(operations)
+}
+
+// OpResults returns the result of each operation of OperateWithResults.
+func (memBridge) OpResults(operations []*Operation, rec *Record) []OpResult {
+	return opResults(operations, rec)
+}
+
+// DocGet reads the value at the path of the JSON document.
+func (memBridge) DocGet(clnt ClientIfc, policy *BasePolicy, key *Key, binName, path string) (interface{}, Error) {
+	return docGet(clnt, policy, key, binName, path)
+}
+
+// DocSet sets the value at the path of the JSON document.
+func (memBridge) DocSet(clnt ClientIfc, policy *WritePolicy, key *Key, binName, path string, value interface{}) Error {
+	return docSet(clnt, policy, key, binName, path, value)
+}
+
+// DocAppend appends the value to the list at the path of the JSON document.
+func (memBridge) DocAppend(clnt ClientIfc, policy *WritePolicy, key *Key, binName, path string, value interface{}) Error {
+	return docAppend(clnt, policy, key, binName, path, value)
+}
+
+// DocDelete removes the element at the path of the JSON document.
+func (memBridge) DocDelete(clnt ClientIfc, policy *WritePolicy, key *Key, binName, path string) Error {
+	return docDelete(clnt, policy, key, binName, path)
+}
+
+// UDFHash returns the hash of the UDF package reported by the server.
+func (memBridge) UDFHash(udfBody []byte) string {
+	return udfHash(udfBody)
+}
+
+// ReadUDFDir reads the UDF packages of the language in the directory.
+func (memBridge) ReadUDFDir(clientDir string, language Language) ([]string, map[string][]byte, Error) {
+	return readUDFDir(clientDir, language)
+}
+
+// memEncode packs the value with the encoder and returns the encoded bytes.
+func memEncode(pack func(buf BufferEx) (int, Error)) ([]byte, Error) {
+	size, err := pack(nil)
+	if err != nil {
+		return nil, err
+	}
+	buf := newBuffer(size)
+	if _, err := pack(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// memClientBase implements the unexported methods of ClientIfc for the in-memory client.
+// The embedded ClientIfc is nil; the in-memory client implements all the exported methods.
+type memClientBase struct {
+	ClientIfc
+
+	clnt ClientIfc
+}
+
+func (b *memClientBase) getUsablePolicy(policy *BasePolicy) *BasePolicy {
+	if policy == nil {
+		if policy = b.clnt.GetDefaultPolicy(); policy == nil {
+			return NewPolicy()
+		}
+	}
+	return policy
+}
+
+func (b *memClientBase) getUsableBatchPolicy(policy *BatchPolicy) *BatchPolicy {
+	if policy == nil {
+		if policy = b.clnt.GetDefaultBatchPolicy(); policy == nil {
+			return NewBatchPolicy()
+		}
+	}
+	return policy
+}
+
+func (b *memClientBase) getUsableBatchReadPolicy(policy *BatchReadPolicy) *BatchReadPolicy {
+	if policy == nil {
+		if policy = b.clnt.GetDefaultBatchReadPolicy(); policy == nil {
+			return NewBatchReadPolicy()
+		}
+	}
+	return policy
+}
+
+func (b *memClientBase) getUsableBatchWritePolicy(policy *BatchWritePolicy) *BatchWritePolicy {
+	if policy == nil {
+		if policy = b.clnt.GetDefaultBatchWritePolicy(); policy == nil {
+			return NewBatchWritePolicy()
+		}
+	}
+	return policy
+}
+
+func (b *memClientBase) getUsableBatchDeletePolicy(policy *BatchDeletePolicy) *BatchDeletePolicy {
+	if policy == nil {
+		if policy = b.clnt.GetDefaultBatchDeletePolicy(); policy == nil {
+			return NewBatchDeletePolicy()
+		}
+	}
+	return policy
+}
+
+func (b *memClientBase) getUsableBatchUDFPolicy(policy *BatchUDFPolicy) *BatchUDFPolicy {
+	if policy == nil {
+		if policy = b.clnt.GetDefaultBatchUDFPolicy(); policy == nil {
+			return NewBatchUDFPolicy()
+		}
+	}
+	return policy
+}
+
+func (b *memClientBase) getUsableWritePolicy(policy *WritePolicy) *WritePolicy {
+	if policy == nil {
+		if policy = b.clnt.GetDefaultWritePolicy(); policy == nil {
+			return NewWritePolicy(0, 0)
+		}
+	}
+	return policy
+}
+
+func (b *memClientBase) getUsableScanPolicy(policy *ScanPolicy) *ScanPolicy {
+	if policy == nil {
+		if policy = b.clnt.GetDefaultScanPolicy(); policy == nil {
+			return NewScanPolicy()
+		}
+	}
+	return policy
+}
+
+func (b *memClientBase) getUsableQueryPolicy(policy *QueryPolicy) *QueryPolicy {
+	if policy == nil {
+		if policy = b.clnt.GetDefaultQueryPolicy(); policy == nil {
+			return NewQueryPolicy()
+		}
+	}
+	return policy
+}
+
+func (b *memClientBase) getUsableAdminPolicy(policy *AdminPolicy) *AdminPolicy {
+	if policy == nil {
+		if policy = b.clnt.GetDefaultAdminPolicy(); policy == nil {
+			return NewAdminPolicy()
+		}
+	}
+	return policy
+}
+
+func (b *memClientBase) getUsableInfoPolicy(policy *InfoPolicy) *InfoPolicy {
+	if policy == nil {
+		if policy = b.clnt.GetDefaultInfoPolicy(); policy == nil {
+			return NewInfoPolicy()
+		}
+	}
+	return policy
+}
+
+func (b *memClientBase) queryNodePartitions(policy *QueryPolicy, node *Node, statement *Statement) (*Recordset, Error) {
+	return b.clnt.QueryNode(policy, node, statement)
+}
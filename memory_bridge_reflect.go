@@ -0,0 +1,71 @@
+//go:build !as_performance
+
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"reflect"
+)
+
+// Marshal returns the bins of the object.
+func (memBridge) Marshal(obj interface{}) BinMap {
+	return marshal(obj)
+}
+
+// ObjectBinNames returns the names of the bins of the struct type.
+func (memBridge) ObjectBinNames(objType reflect.Type) []string {
+	return objectMappings.getFields(objType)
+}
+
+// FillObject sets the fields of the struct pointed to by rval from the record.
+func (memBridge) FillObject(rval reflect.Value, rec *Record) Error {
+	if rval.Kind() != reflect.Ptr {
+		return ErrInvalidObjectType.err()
+	}
+	rv := rval.Elem()
+	if !rv.CanAddr() || rv.Kind() != reflect.Struct {
+		return ErrInvalidObjectType.err()
+	}
+
+	iobj := indirect(rv)
+	mappings := objectMappings.getMapping(iobj.Type())
+
+	if err := setObjectMetaFields(iobj, rec.Expiration, rec.Generation); err != nil {
+		return err
+	}
+
+	for name, value := range rec.Bins {
+		if err := setObjectField(mappings, iobj, name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewObjectset returns a new recordset sending the objects to the channel, fed by a single goroutine.
+func (memBridge) NewObjectset(objChan interface{}) *Recordset {
+	return &Recordset{objectset: *newObjectset(reflect.ValueOf(objChan), 1)}
+}
+
+// SendObject sends the object to the channel of the recordset.
+// It returns false if the recordset was closed.
+func (memBridge) SendObject(rs *Recordset, obj reflect.Value) bool {
+	chosen, _, _ := reflect.Select([]reflect.SelectCase{
+		{Dir: reflect.SelectSend, Chan: rs.objChan, Send: obj},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(rs.cancelled)},
+	})
+	return chosen == 0
+}
@@ -0,0 +1,676 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+
+	iatomic "github.com/aerospike/aerospike-client-go/v7/internal/atomic"
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// MigrationPolicy encapsulates the parameters of a MigrationClient.
+type MigrationPolicy struct {
+	// ShadowReadPercent is the percentage of the reads that are compared with the target cluster,
+	// from 0 to 100.
+	// Default: 100
+	ShadowReadPercent int
+
+	// ShadowReadWorkers is the number of goroutines reading from the target cluster to compare the reads.
+	// Default: 4
+	ShadowReadWorkers int
+
+	// ShadowReadQueueSize is the maximum number of reads waiting to be compared. When the queue is full,
+	// the reads are not compared, and are counted in MigrationStats.ShadowReadsDropped.
+	// Default: 1024
+	ShadowReadQueueSize int
+
+	// FailOnTargetWriteError returns the errors of the writes to the target cluster to the caller.
+	// Otherwise, the errors are only counted and reported to OnTargetWriteError.
+	// Default: false
+	FailOnTargetWriteError bool
+
+	// OnTargetWriteError is called when a write to the target cluster fails, if set.
+	// The key is nil for commands that are not on a single record, like truncates and background queries.
+	OnTargetWriteError func(key *Key, err Error)
+
+	// OnMismatch is called when a shadow read does not return the same result as the read on
+	// the source cluster, if set. A nil record means the record was not found.
+	// OnMismatch is called from the shadow read workers and must not block.
+	OnMismatch func(key *Key, source, target *Record)
+}
+
+// NewMigrationPolicy returns a MigrationPolicy with the default values.
+func NewMigrationPolicy() *MigrationPolicy {
+	return &MigrationPolicy{
+		ShadowReadPercent:   100,
+		ShadowReadWorkers:   4,
+		ShadowReadQueueSize: 1024,
+	}
+}
+
+// MigrationStats are the counters of a MigrationClient.
+type MigrationStats struct {
+	// TargetWrites is the number of writes sent to the target cluster.
+	TargetWrites int
+	// TargetWriteErrors is the number of writes to the target cluster that failed.
+	TargetWriteErrors int
+	// ShadowReads is the number of reads compared with the target cluster.
+	ShadowReads int
+	// ShadowReadErrors is the number of shadow reads that failed on the target cluster.
+	ShadowReadErrors int
+	// ShadowReadsDropped is the number of reads not compared because the shadow read queue was full.
+	ShadowReadsDropped int
+	// Mismatches is the number of shadow reads whose result differed from the source cluster.
+	Mismatches int
+}
+
+type migrationReadMode byte
+
+const (
+	migrationReadBins migrationReadMode = iota
+	migrationReadHeader
+	migrationReadExists
+)
+
+// migrationShadowRead is a read of the source cluster to compare with the target cluster.
+type migrationShadowRead struct {
+	mode     migrationReadMode
+	policy   *BasePolicy
+	key      *Key
+	binNames []string
+	found    bool
+	record   *Record
+}
+
+// MigrationClient supports live migrations between two clusters.
+// MigrationClient implements ClientIfc on top of the client of the source cluster: the applications
+// keep using it as their client, while the writes are also sent to the target cluster, and a sample
+// of the reads is compared with the target cluster in the background.
+//
+// The writes are sent to the target cluster after they succeed on the source cluster.
+// The generation checks of the write policies are not applied on the target cluster,
+// since the generations of the two clusters differ.
+// Record writes, operations with writes, UDFs, batch writes, background queries and truncates
+// are duplicated; the remaining commands, including index and UDF management, only run on the source cluster.
+//
+// Get, GetHeader, Exists, BatchGet, BatchGetHeader and BatchExists are compared.
+type MigrationClient struct {
+	ClientIfc
+
+	target ClientIfc
+	policy MigrationPolicy
+
+	shadowReads chan *migrationShadowRead
+	mutex       sync.RWMutex
+	closed      bool
+	wg          sync.WaitGroup
+
+	targetWrites       iatomic.Int
+	targetWriteErrors  iatomic.Int
+	shadowReadCount    iatomic.Int
+	shadowReadErrors   iatomic.Int
+	shadowReadsDropped iatomic.Int
+	mismatches         iatomic.Int
+}
+
+// NewMigrationClient returns a MigrationClient that migrates the records from the source cluster
+// to the target cluster. Closing the MigrationClient closes both clients.
+// If the policy is nil, the default policy will be used.
+func NewMigrationClient(policy *MigrationPolicy, source, target ClientIfc) *MigrationClient {
+	if policy == nil {
+		policy = NewMigrationPolicy()
+	}
+
+	clnt := &MigrationClient{
+		ClientIfc: source,
+		target:    target,
+		policy:    *policy,
+	}
+
+	if clnt.policy.ShadowReadPercent > 0 {
+		workers := clnt.policy.ShadowReadWorkers
+		if workers <= 0 {
+			workers = 4
+		}
+
+		queueSize := clnt.policy.ShadowReadQueueSize
+		if queueSize <= 0 {
+			queueSize = 1024
+		}
+
+		clnt.shadowReads = make(chan *migrationShadowRead, queueSize)
+		clnt.wg.Add(workers)
+		for i := 0; i < workers; i++ {
+			go clnt.shadowReadWorker()
+		}
+	}
+
+	return clnt
+}
+
+// Source returns the client of the source cluster.
+func (clnt *MigrationClient) Source() ClientIfc {
+	return clnt.ClientIfc
+}
+
+// Target returns the client of the target cluster.
+func (clnt *MigrationClient) Target() ClientIfc {
+	return clnt.target
+}
+
+// MigrationStats returns the counters of the migration.
+func (clnt *MigrationClient) MigrationStats() MigrationStats {
+	return MigrationStats{
+		TargetWrites:       clnt.targetWrites.Get(),
+		TargetWriteErrors:  clnt.targetWriteErrors.Get(),
+		ShadowReads:        clnt.shadowReadCount.Get(),
+		ShadowReadErrors:   clnt.shadowReadErrors.Get(),
+		ShadowReadsDropped: clnt.shadowReadsDropped.Get(),
+		Mismatches:         clnt.mismatches.Get(),
+	}
+}
+
+// Close waits for the queued shadow reads to be compared, and closes the clients of both clusters.
+func (clnt *MigrationClient) Close() {
+	clnt.mutex.Lock()
+	if clnt.closed {
+		clnt.mutex.Unlock()
+		return
+	}
+	clnt.closed = true
+	if clnt.shadowReads != nil {
+		close(clnt.shadowReads)
+	}
+	clnt.mutex.Unlock()
+
+	clnt.wg.Wait()
+	clnt.ClientIfc.Close()
+	clnt.target.Close()
+}
+
+// String implements the Stringer interface.
+func (clnt *MigrationClient) String() string {
+	return "migration from " + clnt.ClientIfc.String() + " to " + clnt.target.String()
+}
+
+//-------------------------------------------------------
+// Writes
+//-------------------------------------------------------
+
+// targetWritePolicy returns the policy of a write to the target cluster.
+func targetWritePolicy(policy *WritePolicy) *WritePolicy {
+	if policy == nil || policy.GenerationPolicy == NONE {
+		return policy
+	}
+
+	res := *policy
+	res.GenerationPolicy = NONE
+	res.Generation = 0
+	return &res
+}
+
+// targetWriteResult counts the write to the target cluster and reports its error.
+func (clnt *MigrationClient) targetWriteResult(key *Key, err Error) Error {
+	clnt.targetWrites.IncrementAndGet()
+	if err == nil {
+		return nil
+	}
+
+	clnt.targetWriteErrors.IncrementAndGet()
+	if clnt.policy.OnTargetWriteError != nil {
+		clnt.policy.OnTargetWriteError(key, err)
+	}
+
+	if clnt.policy.FailOnTargetWriteError {
+		return err
+	}
+	return nil
+}
+
+// migrationWrite runs the write on the source cluster and, if it succeeds, on the target cluster.
+// The result of the source cluster is returned.
+func migrationWrite[T any](clnt *MigrationClient, policy *WritePolicy, key *Key, fn func(c ClientIfc, policy *WritePolicy) (T, Error)) (T, Error) {
+	res, err := fn(clnt.ClientIfc, policy)
+	if err != nil {
+		return res, err
+	}
+
+	_, terr := fn(clnt.target, targetWritePolicy(policy))
+	return res, clnt.targetWriteResult(key, terr)
+}
+
+func (clnt *MigrationClient) write(policy *WritePolicy, key *Key, fn func(c ClientIfc, policy *WritePolicy) Error) Error {
+	_, err := migrationWrite(clnt, policy, key, func(c ClientIfc, policy *WritePolicy) (struct{}, Error) {
+		return struct{}{}, fn(c, policy)
+	})
+	return err
+}
+
+// Put writes the bins of a record to both clusters.
+func (clnt *MigrationClient) Put(policy *WritePolicy, key *Key, binMap BinMap) Error {
+	return clnt.write(policy, key, func(c ClientIfc, policy *WritePolicy) Error {
+		return c.Put(policy, key, binMap)
+	})
+}
+
+// PutBins writes the bins of a record to both clusters.
+func (clnt *MigrationClient) PutBins(policy *WritePolicy, key *Key, bins ...*Bin) Error {
+	return clnt.write(policy, key, func(c ClientIfc, policy *WritePolicy) Error {
+		return c.PutBins(policy, key, bins...)
+	})
+}
+
+// Append appends the bin values of a record on both clusters.
+func (clnt *MigrationClient) Append(policy *WritePolicy, key *Key, binMap BinMap) Error {
+	return clnt.write(policy, key, func(c ClientIfc, policy *WritePolicy) Error {
+		return c.Append(policy, key, binMap)
+	})
+}
+
+// AppendBins appends the bin values of a record on both clusters.
+func (clnt *MigrationClient) AppendBins(policy *WritePolicy, key *Key, bins ...*Bin) Error {
+	return clnt.write(policy, key, func(c ClientIfc, policy *WritePolicy) Error {
+		return c.AppendBins(policy, key, bins...)
+	})
+}
+
+// Prepend prepends the bin values of a record on both clusters.
+func (clnt *MigrationClient) Prepend(policy *WritePolicy, key *Key, binMap BinMap) Error {
+	return clnt.write(policy, key, func(c ClientIfc, policy *WritePolicy) Error {
+		return c.Prepend(policy, key, binMap)
+	})
+}
+
+// PrependBins prepends the bin values of a record on both clusters.
+func (clnt *MigrationClient) PrependBins(policy *WritePolicy, key *Key, bins ...*Bin) Error {
+	return clnt.write(policy, key, func(c ClientIfc, policy *WritePolicy) Error {
+		return c.PrependBins(policy, key, bins...)
+	})
+}
+
+// Add adds the integer bin values of a record on both clusters.
+func (clnt *MigrationClient) Add(policy *WritePolicy, key *Key, binMap BinMap) Error {
+	return clnt.write(policy, key, func(c ClientIfc, policy *WritePolicy) Error {
+		return c.Add(policy, key, binMap)
+	})
+}
+
+// AddBins adds the integer bin values of a record on both clusters.
+func (clnt *MigrationClient) AddBins(policy *WritePolicy, key *Key, bins ...*Bin) Error {
+	return clnt.write(policy, key, func(c ClientIfc, policy *WritePolicy) Error {
+		return c.AddBins(policy, key, bins...)
+	})
+}
+
+// Touch resets the TTL of a record on both clusters.
+func (clnt *MigrationClient) Touch(policy *WritePolicy, key *Key) Error {
+	return clnt.write(policy, key, func(c ClientIfc, policy *WritePolicy) Error {
+		return c.Touch(policy, key)
+	})
+}
+
+// Delete deletes a record from both clusters, and returns whether it existed on the source cluster.
+func (clnt *MigrationClient) Delete(policy *WritePolicy, key *Key) (bool, Error) {
+	return migrationWrite(clnt, policy, key, func(c ClientIfc, policy *WritePolicy) (bool, Error) {
+		return c.Delete(policy, key)
+	})
+}
+
+// DeleteWithResult deletes a record from both clusters, and returns the result of the source cluster.
+func (clnt *MigrationClient) DeleteWithResult(policy *WritePolicy, key *Key) (*DeleteResult, Error) {
+	return migrationWrite(clnt, policy, key, func(c ClientIfc, policy *WritePolicy) (*DeleteResult, Error) {
+		return c.DeleteWithResult(policy, key)
+	})
+}
+
+// operationsHaveWrite returns true if any of the operations writes to the record.
+func operationsHaveWrite(operations []*Operation) bool {
+	for _, op := range operations {
+		if op.opType.isWrite {
+			return true
+		}
+	}
+	return false
+}
+
+// Operate runs the operations on the source cluster, and on the target cluster if they write to the record.
+// The record of the source cluster is returned.
+func (clnt *MigrationClient) Operate(policy *WritePolicy, key *Key, operations ...*Operation) (*Record, Error) {
+	if !operationsHaveWrite(operations) {
+		return clnt.ClientIfc.Operate(policy, key, operations...)
+	}
+
+	return migrationWrite(clnt, policy, key, func(c ClientIfc, policy *WritePolicy) (*Record, Error) {
+		return c.Operate(policy, key, operations...)
+	})
+}
+
+// OperateWithResults runs the operations on the source cluster, and on the target cluster
+// if they write to the record. The results of the source cluster are returned.
+func (clnt *MigrationClient) OperateWithResults(policy *WritePolicy, key *Key, operations ...*Operation) (*Record, []OpResult, Error) {
+	if !operationsHaveWrite(operations) {
+		return clnt.ClientIfc.OperateWithResults(policy, key, operations...)
+	}
+
+	var results []OpResult
+	source := true
+	rec, err := migrationWrite(clnt, policy, key, func(c ClientIfc, wpolicy *WritePolicy) (*Record, Error) {
+		rec, res, err := c.OperateWithResults(wpolicy, key, operations...)
+		if source {
+			results, source = res, false
+		}
+		return rec, err
+	})
+	return rec, results, err
+}
+
+// Execute runs the UDF on the record of both clusters, and returns the result of the source cluster.
+func (clnt *MigrationClient) Execute(policy *WritePolicy, key *Key, packageName string, functionName string, args ...Value) (interface{}, Error) {
+	return migrationWrite(clnt, policy, key, func(c ClientIfc, policy *WritePolicy) (interface{}, Error) {
+		return c.Execute(policy, key, packageName, functionName, args...)
+	})
+}
+
+// DocSet sets the value at the path of the JSON document on both clusters.
+func (clnt *MigrationClient) DocSet(policy *WritePolicy, key *Key, binName string, path string, value interface{}) Error {
+	return docSet(clnt, policy, key, binName, path, value)
+}
+
+// DocAppend appends the value to the list at the path of the JSON document on both clusters.
+func (clnt *MigrationClient) DocAppend(policy *WritePolicy, key *Key, binName string, path string, value interface{}) Error {
+	return docAppend(clnt, policy, key, binName, path, value)
+}
+
+// DocDelete removes the element at the path of the JSON document on both clusters.
+func (clnt *MigrationClient) DocDelete(policy *WritePolicy, key *Key, binName string, path string) Error {
+	return docDelete(clnt, policy, key, binName, path)
+}
+
+// targetBatchResults reports the records that failed on the target cluster, but succeeded on the source cluster.
+func (clnt *MigrationClient) targetBatchResults(source, target []*BatchRecord, err Error) Error {
+	if err != nil {
+		return clnt.targetWriteResult(nil, err)
+	}
+
+	for i, tr := range target {
+		if i < len(source) && source[i].ResultCode == types.OK {
+			if terr := clnt.targetWriteResult(tr.Key, tr.Err); terr != nil {
+				return terr
+			}
+		}
+	}
+	return nil
+}
+
+// BatchDelete deletes the records from both clusters, and returns the results of the source cluster.
+func (clnt *MigrationClient) BatchDelete(policy *BatchPolicy, deletePolicy *BatchDeletePolicy, keys []*Key) ([]*BatchRecord, Error) {
+	res, err := clnt.ClientIfc.BatchDelete(policy, deletePolicy, keys)
+	if err != nil {
+		return res, err
+	}
+
+	tres, terr := clnt.target.BatchDelete(policy, deletePolicy, keys)
+	return res, clnt.targetBatchResults(res, tres, terr)
+}
+
+// BatchExecute runs the UDF on the records of both clusters, and returns the results of the source cluster.
+func (clnt *MigrationClient) BatchExecute(policy *BatchPolicy, udfPolicy *BatchUDFPolicy, keys []*Key, packageName string, functionName string, args ...Value) ([]*BatchRecord, Error) {
+	res, err := clnt.ClientIfc.BatchExecute(policy, udfPolicy, keys, packageName, functionName, args...)
+	if err != nil {
+		return res, err
+	}
+
+	tres, terr := clnt.target.BatchExecute(policy, udfPolicy, keys, packageName, functionName, args...)
+	return res, clnt.targetBatchResults(res, tres, terr)
+}
+
+// BatchOperate runs the batch commands on the source cluster, and the writes that succeeded
+// on the target cluster. The records keep the results of the source cluster.
+func (clnt *MigrationClient) BatchOperate(policy *BatchPolicy, records []BatchRecordIfc) Error {
+	err := clnt.ClientIfc.BatchOperate(policy, records)
+
+	writes := make([]BatchRecordIfc, 0, len(records))
+	saved := make([]BatchRecord, 0, len(records))
+	for _, r := range records {
+		if r.isWrite() && r.BatchRec().ResultCode == types.OK {
+			writes = append(writes, r)
+			saved = append(saved, *r.BatchRec())
+		}
+	}
+
+	if len(writes) == 0 {
+		return err
+	}
+
+	terr := clnt.target.BatchOperate(policy, writes)
+	if terr != nil {
+		terr = clnt.targetWriteResult(nil, terr)
+	} else {
+		for _, r := range writes {
+			if terr = clnt.targetWriteResult(r.key(), r.BatchRec().Err); terr != nil {
+				break
+			}
+		}
+	}
+
+	// restore the results of the source cluster
+	for i, r := range writes {
+		*r.BatchRec() = saved[i]
+	}
+
+	if err != nil {
+		return err
+	}
+	return terr
+}
+
+// QueryExecute runs the background query on both clusters, and returns the task of the source cluster.
+func (clnt *MigrationClient) QueryExecute(policy *QueryPolicy, writePolicy *WritePolicy, statement *Statement, ops ...*Operation) (*ExecuteTask, Error) {
+	task, err := clnt.ClientIfc.QueryExecute(policy, writePolicy, statement, ops...)
+	if err != nil {
+		return task, err
+	}
+
+	_, terr := clnt.target.QueryExecute(policy, targetWritePolicy(writePolicy), statement, ops...)
+	return task, clnt.targetWriteResult(nil, terr)
+}
+
+// ExecuteUDF runs the background UDF on both clusters, and returns the task of the source cluster.
+func (clnt *MigrationClient) ExecuteUDF(policy *QueryPolicy, statement *Statement, packageName string, functionName string, functionArgs ...Value) (*ExecuteTask, Error) {
+	task, err := clnt.ClientIfc.ExecuteUDF(policy, statement, packageName, functionName, functionArgs...)
+	if err != nil {
+		return task, err
+	}
+
+	_, terr := clnt.target.ExecuteUDF(policy, statement, packageName, functionName, functionArgs...)
+	return task, clnt.targetWriteResult(nil, terr)
+}
+
+// Truncate removes the records of the namespace or set on both clusters.
+func (clnt *MigrationClient) Truncate(policy *InfoPolicy, namespace, set string, beforeLastUpdate *time.Time) Error {
+	if err := clnt.ClientIfc.Truncate(policy, namespace, set, beforeLastUpdate); err != nil {
+		return err
+	}
+	return clnt.targetWriteResult(nil, clnt.target.Truncate(policy, namespace, set, beforeLastUpdate))
+}
+
+// TruncateWithPolicy removes the records of the namespace or set on both clusters,
+// and returns the result of the source cluster.
+func (clnt *MigrationClient) TruncateWithPolicy(policy *TruncatePolicy, namespace, set string, beforeLastUpdate time.Time) (*TruncateResult, Error) {
+	res, err := clnt.ClientIfc.TruncateWithPolicy(policy, namespace, set, beforeLastUpdate)
+	if err != nil {
+		return res, err
+	}
+
+	_, terr := clnt.target.TruncateWithPolicy(policy, namespace, set, beforeLastUpdate)
+	return res, clnt.targetWriteResult(nil, terr)
+}
+
+//-------------------------------------------------------
+// Shadow reads
+//-------------------------------------------------------
+
+// shadow queues the read to be compared with the target cluster, if it is sampled.
+func (clnt *MigrationClient) shadow(sr *migrationShadowRead) {
+	if clnt.shadowReads == nil || (clnt.policy.ShadowReadPercent < 100 && rand.Intn(100) >= clnt.policy.ShadowReadPercent) {
+		return
+	}
+
+	clnt.mutex.RLock()
+	defer clnt.mutex.RUnlock()
+	if clnt.closed {
+		return
+	}
+
+	select {
+	case clnt.shadowReads <- sr:
+	default:
+		clnt.shadowReadsDropped.IncrementAndGet()
+	}
+}
+
+// shadowRecord queues a read whose result is a record. Reads that failed on the source cluster are not compared.
+func (clnt *MigrationClient) shadowRecord(mode migrationReadMode, policy *BasePolicy, key *Key, binNames []string, rec *Record, err Error) {
+	if err != nil && !err.Matches(types.KEY_NOT_FOUND_ERROR) {
+		return
+	}
+	clnt.shadow(&migrationShadowRead{mode: mode, policy: policy, key: key, binNames: binNames, found: rec != nil, record: rec})
+}
+
+func (clnt *MigrationClient) shadowReadWorker() {
+	defer clnt.wg.Done()
+	for sr := range clnt.shadowReads {
+		clnt.compare(sr)
+	}
+}
+
+// compare reads the record from the target cluster and compares it with the result of the source cluster.
+func (clnt *MigrationClient) compare(sr *migrationShadowRead) {
+	var rec *Record
+	var err Error
+	switch sr.mode {
+	case migrationReadBins:
+		rec, err = clnt.target.Get(sr.policy, sr.key, sr.binNames...)
+	case migrationReadHeader:
+		rec, err = clnt.target.GetHeader(sr.policy, sr.key)
+	case migrationReadExists:
+		var exists bool
+		exists, err = clnt.target.Exists(sr.policy, sr.key)
+		if exists {
+			rec = &Record{Key: sr.key}
+		}
+	}
+
+	if err != nil && !err.Matches(types.KEY_NOT_FOUND_ERROR) {
+		clnt.shadowReadErrors.IncrementAndGet()
+		return
+	}
+	clnt.shadowReadCount.IncrementAndGet()
+
+	found := rec != nil
+	match := found == sr.found
+	if match && found && sr.mode == migrationReadBins {
+		match = reflect.DeepEqual(sr.record.Bins, rec.Bins)
+	}
+
+	if !match {
+		clnt.mismatches.IncrementAndGet()
+		if clnt.policy.OnMismatch != nil {
+			clnt.policy.OnMismatch(sr.key, sr.record, rec)
+		}
+	}
+}
+
+// Get reads a record from the source cluster, and compares it with the target cluster in the background.
+func (clnt *MigrationClient) Get(policy *BasePolicy, key *Key, binNames ...string) (*Record, Error) {
+	rec, err := clnt.ClientIfc.Get(policy, key, binNames...)
+	clnt.shadowRecord(migrationReadBins, policy, key, binNames, rec, err)
+	return rec, err
+}
+
+// GetHeader reads a record header from the source cluster, and compares its existence
+// with the target cluster in the background.
+func (clnt *MigrationClient) GetHeader(policy *BasePolicy, key *Key) (*Record, Error) {
+	rec, err := clnt.ClientIfc.GetHeader(policy, key)
+	clnt.shadowRecord(migrationReadHeader, policy, key, nil, rec, err)
+	return rec, err
+}
+
+// Exists checks the existence of a record on the source cluster, and compares it with
+// the target cluster in the background.
+func (clnt *MigrationClient) Exists(policy *BasePolicy, key *Key) (bool, Error) {
+	exists, err := clnt.ClientIfc.Exists(policy, key)
+	if err == nil {
+		var rec *Record
+		if exists {
+			rec = &Record{Key: key}
+		}
+		clnt.shadow(&migrationShadowRead{mode: migrationReadExists, policy: policy, key: key, found: exists, record: rec})
+	}
+	return exists, err
+}
+
+// shadowBatch queues the records of a batch read to be compared with the target cluster.
+func (clnt *MigrationClient) shadowBatch(mode migrationReadMode, policy *BatchPolicy, keys []*Key, binNames []string, records []*Record) {
+	var bpolicy *BasePolicy
+	if policy != nil {
+		bpolicy = &policy.BasePolicy
+	}
+
+	for i := range keys {
+		if i < len(records) {
+			clnt.shadow(&migrationShadowRead{mode: mode, policy: bpolicy, key: keys[i], binNames: binNames, found: records[i] != nil, record: records[i]})
+		}
+	}
+}
+
+// BatchGet reads the records from the source cluster, and compares them with the target cluster in the background.
+func (clnt *MigrationClient) BatchGet(policy *BatchPolicy, keys []*Key, binNames ...string) ([]*Record, Error) {
+	records, err := clnt.ClientIfc.BatchGet(policy, keys, binNames...)
+	if err == nil {
+		clnt.shadowBatch(migrationReadBins, policy, keys, binNames, records)
+	}
+	return records, err
+}
+
+// BatchGetHeader reads the record headers from the source cluster, and compares their existence
+// with the target cluster in the background.
+func (clnt *MigrationClient) BatchGetHeader(policy *BatchPolicy, keys []*Key) ([]*Record, Error) {
+	records, err := clnt.ClientIfc.BatchGetHeader(policy, keys)
+	if err == nil {
+		clnt.shadowBatch(migrationReadHeader, policy, keys, nil, records)
+	}
+	return records, err
+}
+
+// BatchExists checks the existence of the records on the source cluster, and compares it with
+// the target cluster in the background.
+func (clnt *MigrationClient) BatchExists(policy *BatchPolicy, keys []*Key) ([]bool, Error) {
+	exists, err := clnt.ClientIfc.BatchExists(policy, keys)
+	if err == nil {
+		records := make([]*Record, len(exists))
+		for i := range exists {
+			if exists[i] {
+				records[i] = &Record{Key: keys[i]}
+			}
+		}
+		clnt.shadowBatch(migrationReadExists, policy, keys, nil, records)
+	}
+	return exists, err
+}
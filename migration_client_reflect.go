@@ -0,0 +1,25 @@
+//go:build !as_performance
+
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+// PutObject writes the object to both clusters.
+// See Client.PutObject for the tags influencing the way the object is stored.
+func (clnt *MigrationClient) PutObject(policy *WritePolicy, key *Key, obj interface{}) (err Error) {
+	return clnt.write(policy, key, func(c ClientIfc, policy *WritePolicy) Error {
+		return c.PutObject(policy, key, obj)
+	})
+}
@@ -0,0 +1,106 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock
+
+import (
+	as "github.com/aerospike/aerospike-client-go/v7"
+	"github.com/aerospike/aerospike-client-go/v7/internal/memclient"
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// memBridge is implemented by the aerospike package, to give the in-memory client access to the
+// internals it needs to encode the operations and build the results of the commands.
+type memBridge interface {
+	ClientBase(clnt as.ClientIfc) as.ClientIfc
+
+	NewError(code types.ResultCode, messages ...string) as.Error
+	WrapError(err error, code types.ResultCode, messages ...string) as.Error
+	ConstError(err as.Error) as.Error
+	ResultCode(err as.Error) types.ResultCode
+
+	Op(op *as.Operation) (kind memclient.OpKind, isWrite bool, binName string, binValue as.Value)
+	EncodeOp(op *as.Operation) ([]byte, as.Error)
+	PackValue(v as.Value) ([]byte, as.Error)
+	PackExpression(exp *as.Expression) ([]byte, as.Error)
+	Unpack(buf []byte, offset int, isMapKey bool) (interface{}, int, as.Error)
+	Particle(v as.Value) (interface{}, as.Error)
+	Filter(f *as.Filter) (binName string, begin, end as.Value, hasCtx bool)
+	AggregateFunction(stmt *as.Statement) string
+
+	NewKey(namespace, setName string, userKey as.Value, digest [20]byte) *as.Key
+	NewRecord(key *as.Key, bins as.BinMap, generation, expiration uint32) *as.Record
+	BatchWritePolicy(policy *as.BatchPolicy) *as.WritePolicy
+	BatchReadWritePolicy(policy *as.BatchReadPolicy, parent *as.BatchPolicy) *as.WritePolicy
+	BatchWriteWritePolicy(policy *as.BatchWritePolicy, parent *as.BatchPolicy) *as.WritePolicy
+	BatchDeleteWritePolicy(policy *as.BatchDeletePolicy, parent *as.BatchPolicy) *as.WritePolicy
+	NewBatchRecord(key *as.Key, hasWrite bool) *as.BatchRecord
+	PrepareBatchRecord(br as.BatchRecordIfc)
+	SetBatchRecord(br as.BatchRecordIfc, rec *as.Record)
+	SetBatchError(br as.BatchRecordIfc, err as.Error)
+	SetBatchErrorWithMsg(br as.BatchRecordIfc, err as.Error)
+
+	ExecuteTask(taskID uint64, scan bool) *as.ExecuteTask
+	RegisterTask(packages map[string]string) *as.RegisterTask
+	RemoveTask(packageName string) *as.RemoveTask
+	IndexTask(namespace, indexName string) *as.IndexTask
+
+	NewRecordset(queueSize int) *as.Recordset
+	SendRecord(rs *as.Recordset, rec *as.Record) bool
+	SendError(rs *as.Recordset, err as.Error)
+	SignalEnd(rs *as.Recordset)
+
+	QuerySorted(policy *as.QueryPolicy, run func(policy *as.QueryPolicy) (*as.Recordset, as.Error)) (*as.Recordset, as.Error)
+	BatchExistsChunks(keys []*as.Key, chunkSize int, exists func(keys []*as.Key, existsArray []bool) as.Error, fn func(index int, exists bool) bool) as.Error
+	ExistingDigests(keys []*as.Key, existsArray []bool) [][]byte
+	SortedSetInfos(sets map[string]*as.SetInfo, replicationFactor int64) []*as.SetInfo
+	ValidateOpResultsOps(operations []*as.Operation) as.Error
+	OpResults(operations []*as.Operation, rec *as.Record) []as.OpResult
+	DocGet(clnt as.ClientIfc, policy *as.BasePolicy, key *as.Key, binName, path string) (interface{}, as.Error)
+	DocSet(clnt as.ClientIfc, policy *as.WritePolicy, key *as.Key, binName, path string, value interface{}) as.Error
+	DocAppend(clnt as.ClientIfc, policy *as.WritePolicy, key *as.Key, binName, path string, value interface{}) as.Error
+	DocDelete(clnt as.ClientIfc, policy *as.WritePolicy, key *as.Key, binName, path string) as.Error
+	UDFHash(udfBody []byte) string
+	ReadUDFDir(clientDir string, language as.Language) ([]string, map[string][]byte, as.Error)
+}
+
+// bridge is set by the aerospike package, which is always initialized before this package.
+var bridge = memclient.Bridge.(memBridge)
+
+func newError(code types.ResultCode, messages ...string) as.Error {
+	return bridge.NewError(code, messages...)
+}
+
+func newErrorAndWrap(err error, code types.ResultCode, messages ...string) as.Error {
+	return bridge.WrapError(err, code, messages...)
+}
+
+// constError returns a copy of one of the constant errors of the aerospike package.
+func constError(err as.Error) as.Error {
+	return bridge.ConstError(err)
+}
+
+// memOp is an operation with its fields decoded.
+type memOp struct {
+	op       *as.Operation
+	kind     memclient.OpKind
+	isWrite  bool
+	binName  string
+	binValue as.Value
+}
+
+func memDecodeOp(op *as.Operation) memOp {
+	kind, isWrite, binName, binValue := bridge.Op(op)
+	return memOp{op: op, kind: kind, isWrite: isWrite, binName: binName, binValue: binValue}
+}
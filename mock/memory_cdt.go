@@ -0,0 +1,1471 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+	"github.com/aerospike/aerospike-client-go/v7/types"
+	Buffer "github.com/aerospike/aerospike-client-go/v7/utils/buffer"
+)
+
+// The in-memory client keeps the bin values in the form the client decodes them from the wire:
+// int, float64, string, []byte, bool, GeoJSONValue, HLLValue, []interface{} for lists, and
+// map[interface{}]interface{} or []MapPair (for key ordered maps) for maps. Blob map keys are byte arrays.
+
+// The CDT operation codes and context types of the wire protocol.
+
+const (
+	_CDT_LIST_SET_TYPE                       = 0
+	_CDT_LIST_APPEND                         = 1
+	_CDT_LIST_APPEND_ITEMS                   = 2
+	_CDT_LIST_INSERT                         = 3
+	_CDT_LIST_INSERT_ITEMS                   = 4
+	_CDT_LIST_POP                            = 5
+	_CDT_LIST_POP_RANGE                      = 6
+	_CDT_LIST_REMOVE                         = 7
+	_CDT_LIST_REMOVE_RANGE                   = 8
+	_CDT_LIST_SET                            = 9
+	_CDT_LIST_TRIM                           = 10
+	_CDT_LIST_CLEAR                          = 11
+	_CDT_LIST_INCREMENT                      = 12
+	_CDT_LIST_SORT                           = 13
+	_CDT_LIST_SIZE                           = 16
+	_CDT_LIST_GET                            = 17
+	_CDT_LIST_GET_RANGE                      = 18
+	_CDT_LIST_GET_BY_INDEX                   = 19
+	_CDT_LIST_GET_BY_RANK                    = 21
+	_CDT_LIST_GET_BY_VALUE                   = 22
+	_CDT_LIST_GET_BY_VALUE_LIST              = 23
+	_CDT_LIST_GET_BY_INDEX_RANGE             = 24
+	_CDT_LIST_GET_BY_VALUE_INTERVAL          = 25
+	_CDT_LIST_GET_BY_RANK_RANGE              = 26
+	_CDT_LIST_GET_BY_VALUE_REL_RANK_RANGE    = 27
+	_CDT_LIST_REMOVE_BY_INDEX                = 32
+	_CDT_LIST_REMOVE_BY_RANK                 = 34
+	_CDT_LIST_REMOVE_BY_VALUE                = 35
+	_CDT_LIST_REMOVE_BY_VALUE_LIST           = 36
+	_CDT_LIST_REMOVE_BY_INDEX_RANGE          = 37
+	_CDT_LIST_REMOVE_BY_VALUE_INTERVAL       = 38
+	_CDT_LIST_REMOVE_BY_RANK_RANGE           = 39
+	_CDT_LIST_REMOVE_BY_VALUE_REL_RANK_RANGE = 40
+)
+
+const (
+	cdtMapOpTypeSetType                   = 64
+	cdtMapOpTypeAdd                       = 65
+	cdtMapOpTypeAddItems                  = 66
+	cdtMapOpTypePut                       = 67
+	cdtMapOpTypePutItems                  = 68
+	cdtMapOpTypeReplace                   = 69
+	cdtMapOpTypeReplaceItems              = 70
+	cdtMapOpTypeIncrement                 = 73
+	cdtMapOpTypeDecrement                 = 74
+	cdtMapOpTypeClear                     = 75
+	cdtMapOpTypeRemoveByKey               = 76
+	cdtMapOpTypeRemoveByIndex             = 77
+	cdtMapOpTypeRemoveByRank              = 79
+	cdtMapOpTypeRemoveKeyList             = 81
+	cdtMapOpTypeRemoveByValue             = 82
+	cdtMapOpTypeRemoveValueList           = 83
+	cdtMapOpTypeRemoveByKeyInterval       = 84
+	cdtMapOpTypeRemoveByIndexRange        = 85
+	cdtMapOpTypeRemoveByValueInterval     = 86
+	cdtMapOpTypeRemoveByRankRange         = 87
+	cdtMapOpTypeRemoveByKeyRelIndexRange  = 88
+	cdtMapOpTypeRemoveByValueRelRankRange = 89
+	cdtMapOpTypeSize                      = 96
+	cdtMapOpTypeGetByKey                  = 97
+	cdtMapOpTypeGetByIndex                = 98
+	cdtMapOpTypeGetByRank                 = 100
+	cdtMapOpTypeGetByValue                = 102
+	cdtMapOpTypeGetByKeyInterval          = 103
+	cdtMapOpTypeGetByIndexRange           = 104
+	cdtMapOpTypeGetByValueInterval        = 105
+	cdtMapOpTypeGetByRankRange            = 106
+	cdtMapOpTypeGetByKeyList              = 107
+	cdtMapOpTypeGetByValueList            = 108
+	cdtMapOpTypeGetByKeyRelIndexRange     = 109
+	cdtMapOpTypeGetByValueRelRankRange    = 110
+)
+
+const (
+	ctxTypeListIndex = 0x10
+	ctxTypeListRank  = 0x11
+	ctxTypeListValue = 0x13
+	ctxTypeMapIndex  = 0x20
+	ctxTypeMapRank   = 0x21
+	ctxTypeMapKey    = 0x22
+	ctxTypeMapValue  = 0x23
+)
+
+// memSpecial represents the wildcard and infinity values of CDT operations and expressions.
+type memSpecial byte
+
+const (
+	memWildcard memSpecial = iota
+	memInfinity
+)
+
+var errMemElementNotFound = newError(types.FAIL_ELEMENT_NOT_FOUND)
+
+func memUnsupported(what string) as.Error {
+	return newError(types.UNSUPPORTED_FEATURE, what+" is not supported by the in-memory client")
+}
+
+// memTypeOrder returns the rank of the value type in the server sort order of values.
+func memTypeOrder(v interface{}) int {
+	switch v := v.(type) {
+	case nil:
+		return 1
+	case bool:
+		return 2
+	case int, int64:
+		return 3
+	case string:
+		return 4
+	case []interface{}:
+		return 5
+	case map[interface{}]interface{}, []as.MapPair:
+		return 6
+	case []byte, as.HLLValue:
+		return 7
+	case float64:
+		return 8
+	case as.GeoJSONValue:
+		return 9
+	case memSpecial:
+		if v == memInfinity {
+			return 100
+		}
+		return 0
+	default:
+		if reflect.TypeOf(v).Kind() == reflect.Array {
+			return 7
+		}
+		return 50
+	}
+}
+
+// memCompare compares the values in the server sort order of values.
+func memCompare(a, b interface{}) int {
+	ta, tb := memTypeOrder(a), memTypeOrder(b)
+	if ta != tb {
+		if ta < tb {
+			return -1
+		}
+		return 1
+	}
+
+	switch ta {
+	case 2:
+		x, y := a.(bool), b.(bool)
+		if x == y {
+			return 0
+		} else if !x {
+			return -1
+		}
+		return 1
+	case 3:
+		x, _ := memToInt(a)
+		y, _ := memToInt(b)
+		return memCompareOrdered(x, y)
+	case 4:
+		return memCompareOrdered(a.(string), b.(string))
+	case 5:
+		x, y := a.([]interface{}), b.([]interface{})
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if c := memCompare(x[i], y[i]); c != 0 {
+				return c
+			}
+		}
+		return memCompareOrdered(len(x), len(y))
+	case 6:
+		x, y := memMapPairs(a), memMapPairs(b)
+		if c := memCompareOrdered(len(x), len(y)); c != 0 {
+			return c
+		}
+		for i := range x {
+			if c := memCompare(x[i].Key, y[i].Key); c != 0 {
+				return c
+			}
+			if c := memCompare(x[i].Value, y[i].Value); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case 7:
+		return bytes.Compare(memBytes(a), memBytes(b))
+	case 8:
+		return memCompareOrdered(a.(float64), b.(float64))
+	case 9:
+		return memCompareOrdered(string(a.(as.GeoJSONValue)), string(b.(as.GeoJSONValue)))
+	}
+	return 0
+}
+
+func memCompareOrdered[T int | int64 | float64 | string](a, b T) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+// memEqual reports if the values are equal. The wildcard matches any value.
+func memEqual(a, b interface{}) bool {
+	if a == memWildcard || b == memWildcard {
+		return true
+	}
+	if x, ok := a.([]interface{}); ok {
+		if y, ok := b.([]interface{}); ok && len(x) == len(y) {
+			for i := range x {
+				if !memEqual(x[i], y[i]) {
+					return false
+				}
+			}
+			return true
+		}
+		return false
+	}
+	return memCompare(a, b) == 0
+}
+
+func memBytes(v interface{}) []byte {
+	switch v := v.(type) {
+	case []byte:
+		return v
+	case as.HLLValue:
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	b := make([]byte, rv.Len())
+	reflect.Copy(reflect.ValueOf(b), rv)
+	return b
+}
+
+func memToInt(v interface{}) (int64, bool) {
+	switch v := v.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	}
+	return 0, false
+}
+
+func memInt(v int64) interface{} {
+	if Buffer.Arch64Bits {
+		return int(v)
+	}
+	return v
+}
+
+// memClone returns a deep copy of the value, so that the callers cannot modify the stored records.
+func memClone(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []byte:
+		return append([]byte{}, v...)
+	case as.HLLValue:
+		return append(as.HLLValue{}, v...)
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i := range v {
+			res[i] = memClone(v[i])
+		}
+		return res
+	case map[interface{}]interface{}:
+		res := make(map[interface{}]interface{}, len(v))
+		for k, e := range v {
+			res[k] = memClone(e)
+		}
+		return res
+	case []as.MapPair:
+		res := make([]as.MapPair, len(v))
+		for i := range v {
+			res[i] = as.MapPair{Key: v[i].Key, Value: memClone(v[i].Value)}
+		}
+		return res
+	}
+	return v
+}
+
+// memMapKey converts a value to a valid key of a Go map.
+func memMapKey(v interface{}) interface{} {
+	if b, ok := v.([]byte); ok {
+		arr := reflect.Indirect(reflect.New(reflect.ArrayOf(len(b), reflect.TypeOf(byte(0)))))
+		reflect.Copy(arr, reflect.ValueOf(b))
+		return arr.Interface()
+	}
+	return v
+}
+
+// memMapPairs returns the entries of the map sorted by key.
+func memMapPairs(v interface{}) []as.MapPair {
+	var res []as.MapPair
+	switch v := v.(type) {
+	case []as.MapPair:
+		res = append(make([]as.MapPair, 0, len(v)), v...)
+	case map[interface{}]interface{}:
+		res = make([]as.MapPair, 0, len(v))
+		for k, e := range v {
+			res = append(res, as.MapPair{Key: k, Value: e})
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool { return memCompare(res[i].Key, res[j].Key) < 0 })
+	return res
+}
+
+// memMapValue converts the sorted entries back to a map, keeping the order if the map is ordered.
+func memMapValue(pairs []as.MapPair, ordered bool) interface{} {
+	if ordered {
+		return pairs
+	}
+	res := make(map[interface{}]interface{}, len(pairs))
+	for _, p := range pairs {
+		res[p.Key] = p.Value
+	}
+	return res
+}
+
+func memIsMap(v interface{}) bool {
+	switch v.(type) {
+	case map[interface{}]interface{}, []as.MapPair:
+		return true
+	}
+	return false
+}
+
+// memArgs gives access to the optional arguments of a CDT operation.
+type memArgs []interface{}
+
+func (a memArgs) has(i int) bool {
+	return i < len(a)
+}
+
+func (a memArgs) get(i int) interface{} {
+	if i < len(a) {
+		return a[i]
+	}
+	return nil
+}
+
+func (a memArgs) int(i int, def int64) (int64, as.Error) {
+	if i >= len(a) {
+		return def, nil
+	}
+	v, ok := memToInt(a[i])
+	if !ok {
+		return 0, newError(types.PARAMETER_ERROR, fmt.Sprintf("expected an integer CDT argument, got %T", a[i]))
+	}
+	return v, nil
+}
+
+func (a memArgs) list(i int) ([]interface{}, as.Error) {
+	v, ok := a.get(i).([]interface{})
+	if !ok {
+		return nil, newError(types.PARAMETER_ERROR, fmt.Sprintf("expected a list CDT argument, got %T", a.get(i)))
+	}
+	return v, nil
+}
+
+// memCDTOp is a list or map operation decoded from the wire encoding.
+type memCDTOp struct {
+	code int
+	args memArgs
+	// ctx is the flattened context: type id, value, type id, value...
+	ctx []interface{}
+}
+
+func (op *memCDTOp) isMapOp() bool {
+	return op.code >= cdtMapOpTypeSetType
+}
+
+// memDecodeCDTOp decodes a CDT operation in the [code, args...] or
+// [0xff, [ctx...], [code, args...]] form.
+func memDecodeCDTOp(v interface{}) (*memCDTOp, as.Error) {
+	l, ok := v.([]interface{})
+	if !ok || len(l) == 0 {
+		return nil, newError(types.PARAMETER_ERROR, "invalid CDT operation")
+	}
+
+	op := &memCDTOp{}
+	if code, _ := memToInt(l[0]); code == 0xff && len(l) == 3 {
+		ctx, ok1 := l[1].([]interface{})
+		rest, ok2 := l[2].([]interface{})
+		if !ok1 || !ok2 || len(rest) == 0 {
+			return nil, newError(types.PARAMETER_ERROR, "invalid CDT context")
+		}
+		op.ctx, l = ctx, rest
+	}
+
+	code, ok := memToInt(l[0])
+	if !ok {
+		return nil, newError(types.PARAMETER_ERROR, "invalid CDT operation code")
+	}
+	op.code = int(code)
+	op.args = memArgs(l[1:])
+	return op, nil
+}
+
+// memDecodeCDTOpBytes decodes a CDT operation as encoded by the operation encoders.
+// Operations without a context start with the operation code as a raw 16 bit integer,
+// followed by the array of the arguments if there are any.
+func memDecodeCDTOpBytes(b []byte) (*memCDTOp, as.Error) {
+	if len(b) >= 2 && b[0] == 0 {
+		op := &memCDTOp{code: int(b[1])}
+		if len(b) > 2 {
+			d := &memDecoder{buf: b, offset: 2}
+			v, err := d.value(false)
+			if err != nil {
+				return nil, err
+			}
+			args, ok := v.([]interface{})
+			if !ok {
+				return nil, newError(types.PARAMETER_ERROR, "invalid CDT operation arguments")
+			}
+			op.args = memArgs(args)
+		}
+		return op, nil
+	}
+
+	d := &memDecoder{buf: b}
+	v, err := d.value(false)
+	if err != nil {
+		return nil, err
+	}
+	return memDecodeCDTOp(v)
+}
+
+// memApplyCDT applies the CDT operation to the bin value, and returns the result of the
+// operation and the new bin value.
+func memApplyCDT(bin interface{}, op *memCDTOp) (res interface{}, newBin interface{}, modified bool, err as.Error) {
+	return memApplyCDTCtx(bin, op.ctx, op)
+}
+
+func memApplyCDTCtx(v interface{}, ctx []interface{}, op *memCDTOp) (interface{}, interface{}, bool, as.Error) {
+	if len(ctx) == 0 {
+		if op.isMapOp() {
+			return memMapOp(v, op)
+		}
+		return memListOp(v, op)
+	}
+
+	if len(ctx) < 2 {
+		return nil, nil, false, newError(types.PARAMETER_ERROR, "invalid CDT context")
+	}
+	id, _ := memToInt(ctx[0])
+	typ, create := int(id)&0x3f, int(id)&0xc0 != 0
+	cv := ctx[1]
+
+	// the value to create when a context with create flags is not found
+	newChild := func() interface{} {
+		if len(ctx) > 2 {
+			if next, _ := memToInt(ctx[2]); int(next)&0x30 == ctxTypeMapIndex {
+				return map[interface{}]interface{}{}
+			}
+			return []interface{}{}
+		}
+		if op.isMapOp() {
+			if int(id)&0xc0 == 0xc0 || int(id)&0xc0 == 0x80 {
+				return []as.MapPair{}
+			}
+			return map[interface{}]interface{}{}
+		}
+		return []interface{}{}
+	}
+
+	switch typ {
+	case ctxTypeListIndex, ctxTypeListRank, ctxTypeListValue:
+		l, ok := v.([]interface{})
+		if !ok {
+			if v != nil || !create {
+				return nil, nil, false, newError(types.BIN_TYPE_ERROR)
+			}
+			l = []interface{}{}
+		}
+
+		idx := -1
+		switch typ {
+		case ctxTypeListIndex:
+			i, _ := memToInt(cv)
+			if i < 0 {
+				i += int64(len(l))
+			}
+			if i >= 0 && i < int64(len(l)) {
+				idx = int(i)
+			} else if create && i >= 0 {
+				for int64(len(l)) <= i {
+					l = append(l, nil)
+				}
+				l[i] = newChild()
+				idx = int(i)
+			}
+		case ctxTypeListRank:
+			r, _ := memToInt(cv)
+			if sel := memRankRange(l, r, 1, true); len(sel) == 1 {
+				idx = sel[0]
+			}
+		case ctxTypeListValue:
+			for i := range l {
+				if memEqual(l[i], cv) {
+					idx = i
+					break
+				}
+			}
+		}
+		if idx < 0 {
+			return nil, nil, false, errMemElementNotFound
+		}
+
+		res, child, modified, err := memApplyCDTCtx(l[idx], ctx[2:], op)
+		if err != nil || !modified {
+			return res, v, modified, err
+		}
+		l = append([]interface{}{}, l...)
+		l[idx] = child
+		return res, l, true, nil
+
+	case ctxTypeMapIndex, ctxTypeMapRank, ctxTypeMapKey, ctxTypeMapValue:
+		if v == nil && create {
+			v = map[interface{}]interface{}{}
+		}
+		if !memIsMap(v) {
+			return nil, nil, false, newError(types.BIN_TYPE_ERROR)
+		}
+		_, ordered := v.([]as.MapPair)
+		pairs := memMapPairs(v)
+
+		idx := -1
+		switch typ {
+		case ctxTypeMapIndex:
+			i, _ := memToInt(cv)
+			if i < 0 {
+				i += int64(len(pairs))
+			}
+			if i >= 0 && i < int64(len(pairs)) {
+				idx = int(i)
+			}
+		case ctxTypeMapRank:
+			r, _ := memToInt(cv)
+			if sel := memRankRange(memPairValues(pairs), r, 1, true); len(sel) == 1 {
+				idx = sel[0]
+			}
+		case ctxTypeMapKey:
+			idx = memFindKey(pairs, cv)
+			if idx < 0 && create {
+				pairs = memPutPair(pairs, memMapKey(cv), newChild())
+				idx = memFindKey(pairs, cv)
+			}
+		case ctxTypeMapValue:
+			for i := range pairs {
+				if memEqual(pairs[i].Value, cv) {
+					idx = i
+					break
+				}
+			}
+		}
+		if idx < 0 {
+			return nil, nil, false, errMemElementNotFound
+		}
+
+		res, child, modified, err := memApplyCDTCtx(pairs[idx].Value, ctx[2:], op)
+		if err != nil || !modified {
+			return res, v, modified, err
+		}
+		pairs[idx].Value = child
+		return res, memMapValue(pairs, ordered), true, nil
+	}
+
+	return nil, nil, false, memUnsupported(fmt.Sprintf("CDT context type %d", typ))
+}
+
+// memRange returns the bounds of the index range, in the server semantics of negative indexes.
+func memRange(size int, index int64, count int64, hasCount bool) (int, int) {
+	if index < 0 {
+		index += int64(size)
+		if index < 0 {
+			if hasCount {
+				count += index
+			}
+			index = 0
+		}
+	}
+	if index > int64(size) {
+		return size, size
+	}
+	end := int64(size)
+	if hasCount {
+		if count < 0 {
+			count = 0
+		}
+		if index+count < end {
+			end = index + count
+		}
+	}
+	return int(index), int(end)
+}
+
+// memRanks returns the indexes of the values sorted by rank.
+func memRanks(values []interface{}) []int {
+	idx := make([]int, len(values))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool { return memCompare(values[idx[i]], values[idx[j]]) < 0 })
+	return idx
+}
+
+func memRankRange(values []interface{}, rank, count int64, hasCount bool) []int {
+	ranks := memRanks(values)
+	begin, end := memRange(len(ranks), rank, count, hasCount)
+	return ranks[begin:end]
+}
+
+func memIndexRange(size int, index, count int64, hasCount bool) []int {
+	begin, end := memRange(size, index, count, hasCount)
+	res := make([]int, 0, end-begin)
+	for i := begin; i < end; i++ {
+		res = append(res, i)
+	}
+	return res
+}
+
+func memValueInterval(values []interface{}, begin, end interface{}) []int {
+	var res []int
+	for i, v := range values {
+		if memCompare(v, begin) >= 0 && (end == nil || memCompare(v, end) < 0) {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+func memValueList(values []interface{}, list []interface{}) []int {
+	var res []int
+	for i, v := range values {
+		for _, e := range list {
+			if memEqual(v, e) {
+				res = append(res, i)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// memRelRankRange selects the values by rank relative to the rank of the value.
+func memRelRankRange(values []interface{}, value interface{}, rank, count int64, hasCount bool) []int {
+	ranks := memRanks(values)
+	base := sort.Search(len(ranks), func(i int) bool { return memCompare(values[ranks[i]], value) >= 0 })
+	begin := int64(base) + rank
+	if begin < 0 {
+		if hasCount {
+			count += begin
+		}
+		begin = 0
+	}
+	if begin > int64(len(ranks)) {
+		return nil
+	}
+	end := int64(len(ranks))
+	if hasCount && begin+count < end {
+		end = begin + count
+	}
+	if end < begin {
+		return nil
+	}
+	return ranks[begin:end]
+}
+
+// memInvert returns the indexes that are not selected, in index order.
+func memInvert(size int, sel []int) []int {
+	selected := make(map[int]struct{}, len(sel))
+	for _, i := range sel {
+		selected[i] = struct{}{}
+	}
+	res := make([]int, 0, size-len(sel))
+	for i := 0; i < size; i++ {
+		if _, ok := selected[i]; !ok {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+func memRemoveIndexes[T any](values []T, sel []int) []T {
+	removed := make(map[int]struct{}, len(sel))
+	for _, i := range sel {
+		removed[i] = struct{}{}
+	}
+	res := make([]T, 0, len(values))
+	for i := range values {
+		if _, ok := removed[i]; !ok {
+			res = append(res, values[i])
+		}
+	}
+	return res
+}
+
+func memPairValues(pairs []as.MapPair) []interface{} {
+	res := make([]interface{}, len(pairs))
+	for i := range pairs {
+		res[i] = pairs[i].Value
+	}
+	return res
+}
+
+func memPairKeys(pairs []as.MapPair) []interface{} {
+	res := make([]interface{}, len(pairs))
+	for i := range pairs {
+		res[i] = pairs[i].Key
+	}
+	return res
+}
+
+func memFindKey(pairs []as.MapPair, key interface{}) int {
+	key = memMapKey(key)
+	for i := range pairs {
+		if memCompare(pairs[i].Key, key) == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// memPutPair sets the value of the key, keeping the entries sorted by key.
+func memPutPair(pairs []as.MapPair, key, value interface{}) []as.MapPair {
+	if i := memFindKey(pairs, key); i >= 0 {
+		pairs[i].Value = value
+		return pairs
+	}
+	i := sort.Search(len(pairs), func(i int) bool { return memCompare(pairs[i].Key, key) > 0 })
+	pairs = append(pairs, as.MapPair{})
+	copy(pairs[i+1:], pairs[i:])
+	pairs[i] = as.MapPair{Key: key, Value: value}
+	return pairs
+}
+
+// memSelection is the result of a selection by index, rank, key or value.
+type memSelection struct {
+	sel []int
+	// single is set for the operations that select one element, and return scalars.
+	single bool
+}
+
+// memResult builds the result of the selection for the return type.
+// values are the values in index order, and keys the keys for maps.
+func memResult(values []interface{}, keys []interface{}, ordered bool, s memSelection, returnType int64) (interface{}, as.Error) {
+	sel := s.sel
+	if returnType&int64(as.ListReturnTypeInverted) != 0 {
+		sel = memInvert(len(values), sel)
+		s.single = false
+	}
+	returnType &^= int64(as.ListReturnTypeInverted)
+
+	size := len(values)
+	var ranks []int
+	rankOf := func(i int) int {
+		if ranks == nil {
+			ranks = make([]int, size)
+			for r, idx := range memRanks(values) {
+				ranks[idx] = r
+			}
+		}
+		return ranks[i]
+	}
+
+	collect := func(f func(i int) interface{}) interface{} {
+		if s.single {
+			if len(sel) == 0 {
+				return nil
+			}
+			return f(sel[0])
+		}
+		res := make([]interface{}, 0, len(sel))
+		for _, i := range sel {
+			res = append(res, f(i))
+		}
+		return res
+	}
+
+	switch returnType {
+	case int64(as.ListReturnTypeNone):
+		return nil, nil
+	case int64(as.ListReturnTypeIndex):
+		return collect(func(i int) interface{} { return memInt(int64(i)) }), nil
+	case int64(as.ListReturnTypeReverseIndex):
+		return collect(func(i int) interface{} { return memInt(int64(size - 1 - i)) }), nil
+	case int64(as.ListReturnTypeRank):
+		return collect(func(i int) interface{} { return memInt(int64(rankOf(i))) }), nil
+	case int64(as.ListReturnTypeReverseRank):
+		return collect(func(i int) interface{} { return memInt(int64(size - 1 - rankOf(i))) }), nil
+	case int64(as.ListReturnTypeCount):
+		return memInt(int64(len(sel))), nil
+	case int64(as.ListReturnTypeValue):
+		return collect(func(i int) interface{} { return memClone(values[i]) }), nil
+	case int64(as.ListReturnTypeExists):
+		return len(sel) > 0, nil
+	}
+
+	if keys != nil {
+		switch returnType {
+		case int64(as.MapReturnType.KEY):
+			return collect(func(i int) interface{} { return keys[i] }), nil
+		case int64(as.MapReturnType.KEY_VALUE), int64(as.MapReturnType.UNORDERED_MAP), int64(as.MapReturnType.ORDERED_MAP):
+			pairs := make([]as.MapPair, 0, len(sel))
+			for _, i := range sel {
+				pairs = append(pairs, as.MapPair{Key: keys[i], Value: memClone(values[i])})
+			}
+			switch returnType {
+			case int64(as.MapReturnType.UNORDERED_MAP):
+				ordered = false
+			case int64(as.MapReturnType.ORDERED_MAP):
+				ordered = true
+			}
+			return memMapValue(pairs, ordered), nil
+		}
+	}
+
+	return nil, memUnsupported(fmt.Sprintf("CDT return type %d", returnType))
+}
+
+// memListTypeCheck converts the bin value to a list.
+func memListTypeCheck(v interface{}) ([]interface{}, as.Error) {
+	if v == nil {
+		return nil, nil
+	}
+	l, ok := v.([]interface{})
+	if !ok {
+		return nil, newError(types.BIN_TYPE_ERROR)
+	}
+	return l, nil
+}
+
+func memListInsert(l []interface{}, index int64, items []interface{}, flags int64) ([]interface{}, as.Error) {
+	if index < 0 {
+		index += int64(len(l))
+		if index < 0 {
+			return nil, newError(types.OP_NOT_APPLICABLE, "list index out of bounds")
+		}
+	}
+	if index > int64(len(l)) {
+		if flags&int64(as.ListWriteFlagsInsertBounded) != 0 {
+			return nil, newError(types.OP_NOT_APPLICABLE, "list index out of bounds")
+		}
+		for int64(len(l)) < index {
+			l = append(l, nil)
+		}
+	}
+	res := make([]interface{}, 0, len(l)+len(items))
+	res = append(res, l[:index]...)
+	res = append(res, items...)
+	return append(res, l[index:]...), nil
+}
+
+// memListUnique filters out the items already in the list if the flags require unique items.
+// ok is false if the operation must not be applied.
+func memListUnique(l []interface{}, items []interface{}, flags int64) (res []interface{}, ok bool, err as.Error) {
+	if flags&int64(as.ListWriteFlagsAddUnique) == 0 {
+		return items, true, nil
+	}
+	for _, item := range items {
+		dup := false
+		for _, e := range append(append([]interface{}{}, l...), res...) {
+			if memEqual(e, item) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			res = append(res, item)
+			continue
+		}
+
+		switch {
+		case flags&int64(as.ListWriteFlagsNoFail) == 0:
+			return nil, false, newError(types.FAIL_ELEMENT_EXISTS)
+		case flags&int64(as.ListWriteFlagsPartial) == 0:
+			return nil, false, nil
+		}
+	}
+	return res, true, nil
+}
+
+func memSortList(l []interface{}, flags int64) []interface{} {
+	res := append([]interface{}{}, l...)
+	sort.SliceStable(res, func(i, j int) bool { return memCompare(res[i], res[j]) < 0 })
+	if flags&int64(as.ListSortFlagsDropDuplicates) != 0 && len(res) > 0 {
+		dedup := res[:1]
+		for _, v := range res[1:] {
+			if !memEqual(v, dedup[len(dedup)-1]) {
+				dedup = append(dedup, v)
+			}
+		}
+		res = dedup
+	}
+	if flags&int64(as.ListSortFlagsDescending) != 0 {
+		for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+			res[i], res[j] = res[j], res[i]
+		}
+	}
+	return res
+}
+
+func memIncrement(v, by interface{}) (interface{}, as.Error) {
+	if by == nil {
+		by = memInt(1)
+	}
+	if v == nil {
+		if _, ok := by.(float64); ok {
+			v = float64(0)
+		} else {
+			v = memInt(0)
+		}
+	}
+
+	if x, ok := memToInt(v); ok {
+		if y, ok := memToInt(by); ok {
+			return memInt(x + y), nil
+		}
+	} else if x, ok := v.(float64); ok {
+		if y, ok := by.(float64); ok {
+			return x + y, nil
+		}
+	}
+	return nil, newError(types.BIN_TYPE_ERROR, "cannot increment a non numeric value")
+}
+
+// memListSelect resolves the elements selected by the get/remove by index, rank and value operations.
+func memListSelect(l []interface{}, code int, args memArgs) (memSelection, int64, as.Error) {
+	rt, err := args.int(0, 0)
+	if err != nil {
+		return memSelection{}, 0, err
+	}
+
+	switch code {
+	case _CDT_LIST_GET_BY_INDEX, _CDT_LIST_REMOVE_BY_INDEX:
+		index, err := args.int(1, 0)
+		if err != nil {
+			return memSelection{}, 0, err
+		}
+		begin, end := memRange(len(l), index, 1, true)
+		if index >= int64(len(l)) || index < -int64(len(l)) {
+			return memSelection{single: true}, rt, newError(types.OP_NOT_APPLICABLE, "list index out of bounds")
+		}
+		return memSelection{sel: memIndexRange(len(l), int64(begin), int64(end-begin), true), single: true}, rt, nil
+
+	case _CDT_LIST_GET_BY_RANK, _CDT_LIST_REMOVE_BY_RANK:
+		rank, err := args.int(1, 0)
+		if err != nil {
+			return memSelection{}, 0, err
+		}
+		if rank >= int64(len(l)) || rank < -int64(len(l)) {
+			return memSelection{single: true}, rt, newError(types.OP_NOT_APPLICABLE, "list rank out of bounds")
+		}
+		return memSelection{sel: memRankRange(l, rank, 1, true), single: true}, rt, nil
+
+	case _CDT_LIST_GET_BY_VALUE, _CDT_LIST_REMOVE_BY_VALUE:
+		return memSelection{sel: memValueList(l, []interface{}{args.get(1)})}, rt, nil
+
+	case _CDT_LIST_GET_BY_VALUE_LIST, _CDT_LIST_REMOVE_BY_VALUE_LIST:
+		list, err := args.list(1)
+		if err != nil {
+			return memSelection{}, 0, err
+		}
+		return memSelection{sel: memValueList(l, list)}, rt, nil
+
+	case _CDT_LIST_GET_BY_INDEX_RANGE, _CDT_LIST_REMOVE_BY_INDEX_RANGE, _CDT_LIST_GET_BY_RANK_RANGE, _CDT_LIST_REMOVE_BY_RANK_RANGE:
+		index, err := args.int(1, 0)
+		if err != nil {
+			return memSelection{}, 0, err
+		}
+		count, err := args.int(2, 0)
+		if err != nil {
+			return memSelection{}, 0, err
+		}
+		if code == _CDT_LIST_GET_BY_RANK_RANGE || code == _CDT_LIST_REMOVE_BY_RANK_RANGE {
+			return memSelection{sel: memRankRange(l, index, count, args.has(2))}, rt, nil
+		}
+		return memSelection{sel: memIndexRange(len(l), index, count, args.has(2))}, rt, nil
+
+	case _CDT_LIST_GET_BY_VALUE_INTERVAL, _CDT_LIST_REMOVE_BY_VALUE_INTERVAL:
+		return memSelection{sel: memValueInterval(l, args.get(1), args.get(2))}, rt, nil
+
+	case _CDT_LIST_GET_BY_VALUE_REL_RANK_RANGE, _CDT_LIST_REMOVE_BY_VALUE_REL_RANK_RANGE:
+		rank, err := args.int(2, 0)
+		if err != nil {
+			return memSelection{}, 0, err
+		}
+		count, err := args.int(3, 0)
+		if err != nil {
+			return memSelection{}, 0, err
+		}
+		return memSelection{sel: memRelRankRange(l, args.get(1), rank, count, args.has(3))}, rt, nil
+	}
+
+	return memSelection{}, 0, memUnsupported(fmt.Sprintf("list operation %d", code))
+}
+
+// memListOp applies a list operation to the value.
+func memListOp(v interface{}, op *memCDTOp) (interface{}, interface{}, bool, as.Error) {
+	l, err := memListTypeCheck(v)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	args := op.args
+
+	switch op.code {
+	case _CDT_LIST_SET_TYPE:
+		order, err := args.int(0, 0)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if l == nil {
+			l = []interface{}{}
+		}
+		if order&int64(as.ListOrderOrdered) != 0 {
+			l = memSortList(l, 0)
+		}
+		return nil, l, true, nil
+
+	case _CDT_LIST_APPEND, _CDT_LIST_APPEND_ITEMS, _CDT_LIST_INSERT, _CDT_LIST_INSERT_ITEMS:
+		var items []interface{}
+		var attr, flags, index int64
+		var err as.Error
+		switch op.code {
+		case _CDT_LIST_APPEND:
+			items = []interface{}{args.get(0)}
+			attr, _ = args.int(1, 0)
+			flags, _ = args.int(2, 0)
+		case _CDT_LIST_APPEND_ITEMS:
+			if items, err = args.list(0); err != nil {
+				return nil, nil, false, err
+			}
+			attr, _ = args.int(1, 0)
+			flags, _ = args.int(2, 0)
+		case _CDT_LIST_INSERT:
+			index, _ = args.int(0, 0)
+			items = []interface{}{args.get(1)}
+			flags, _ = args.int(2, 0)
+		case _CDT_LIST_INSERT_ITEMS:
+			index, _ = args.int(0, 0)
+			if items, err = args.list(1); err != nil {
+				return nil, nil, false, err
+			}
+			flags, _ = args.int(2, 0)
+		}
+
+		items, ok, err := memListUnique(l, items, flags)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if !ok || len(items) == 0 {
+			return memInt(int64(len(l))), v, false, nil
+		}
+
+		var res []interface{}
+		if op.code == _CDT_LIST_INSERT || op.code == _CDT_LIST_INSERT_ITEMS {
+			if res, err = memListInsert(l, index, items, flags); err != nil {
+				return nil, nil, false, err
+			}
+		} else {
+			res = append(append(make([]interface{}, 0, len(l)+len(items)), l...), items...)
+			if attr&int64(as.ListOrderOrdered) != 0 {
+				res = memSortList(res, 0)
+			}
+		}
+		return memInt(int64(len(res))), res, true, nil
+
+	case _CDT_LIST_SET:
+		index, err := args.int(0, 0)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		flags, _ := args.int(2, 0)
+		if index < 0 {
+			index += int64(len(l))
+			if index < 0 {
+				return nil, nil, false, newError(types.OP_NOT_APPLICABLE, "list index out of bounds")
+			}
+		}
+		res := append([]interface{}{}, l...)
+		if index >= int64(len(res)) {
+			if flags&int64(as.ListWriteFlagsInsertBounded) != 0 {
+				return nil, nil, false, newError(types.OP_NOT_APPLICABLE, "list index out of bounds")
+			}
+			for int64(len(res)) <= index {
+				res = append(res, nil)
+			}
+		}
+		res[index] = args.get(1)
+		return nil, res, true, nil
+
+	case _CDT_LIST_INCREMENT:
+		index, err := args.int(0, 0)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		begin, end := memRange(len(l), index, 1, true)
+		if begin == end {
+			return nil, nil, false, newError(types.OP_NOT_APPLICABLE, "list index out of bounds")
+		}
+		n, err := memIncrement(l[begin], args.get(1))
+		if err != nil {
+			return nil, nil, false, err
+		}
+		res := append([]interface{}{}, l...)
+		res[begin] = n
+		return n, res, true, nil
+
+	case _CDT_LIST_POP, _CDT_LIST_REMOVE, _CDT_LIST_GET:
+		index, err := args.int(0, 0)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if index >= int64(len(l)) || index < -int64(len(l)) {
+			return nil, nil, false, newError(types.OP_NOT_APPLICABLE, "list index out of bounds")
+		}
+		begin, _ := memRange(len(l), index, 1, true)
+		switch op.code {
+		case _CDT_LIST_GET:
+			return memClone(l[begin]), v, false, nil
+		case _CDT_LIST_POP:
+			return memClone(l[begin]), memRemoveIndexes(l, []int{begin}), true, nil
+		}
+		return memInt(1), memRemoveIndexes(l, []int{begin}), true, nil
+
+	case _CDT_LIST_POP_RANGE, _CDT_LIST_REMOVE_RANGE, _CDT_LIST_GET_RANGE, _CDT_LIST_TRIM:
+		index, err := args.int(0, 0)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		count, err := args.int(1, 0)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		sel := memIndexRange(len(l), index, count, args.has(1))
+		switch op.code {
+		case _CDT_LIST_GET_RANGE:
+			res, _ := memResult(l, nil, false, memSelection{sel: sel}, int64(as.ListReturnTypeValue))
+			return res, v, false, nil
+		case _CDT_LIST_POP_RANGE:
+			res, _ := memResult(l, nil, false, memSelection{sel: sel}, int64(as.ListReturnTypeValue))
+			return res, memRemoveIndexes(l, sel), true, nil
+		case _CDT_LIST_TRIM:
+			removed := memInvert(len(l), sel)
+			return memInt(int64(len(removed))), memRemoveIndexes(l, removed), true, nil
+		}
+		return memInt(int64(len(sel))), memRemoveIndexes(l, sel), true, nil
+
+	case _CDT_LIST_CLEAR:
+		if l == nil {
+			return nil, v, false, nil
+		}
+		return nil, []interface{}{}, true, nil
+
+	case _CDT_LIST_SORT:
+		flags, err := args.int(0, 0)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		return nil, memSortList(l, flags), true, nil
+
+	case _CDT_LIST_SIZE:
+		if v == nil {
+			return nil, v, false, nil
+		}
+		return memInt(int64(len(l))), v, false, nil
+	}
+
+	if v == nil {
+		return nil, v, false, nil
+	}
+
+	s, rt, err := memListSelect(l, op.code, args)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	res, err := memResult(l, nil, false, s, rt)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	if op.code >= _CDT_LIST_REMOVE_BY_INDEX {
+		sel := s.sel
+		if rt&int64(as.ListReturnTypeInverted) != 0 {
+			sel = memInvert(len(l), sel)
+		}
+		if len(sel) == 0 {
+			return res, v, false, nil
+		}
+		return res, memRemoveIndexes(l, sel), true, nil
+	}
+	return res, v, false, nil
+}
+
+// memMapSelect resolves the entries selected by the get/remove by key, index, rank and value operations.
+func memMapSelect(pairs []as.MapPair, code int, args memArgs) (memSelection, int64, as.Error) {
+	rt, err := args.int(0, 0)
+	if err != nil {
+		return memSelection{}, 0, err
+	}
+	keys, values := memPairKeys(pairs), memPairValues(pairs)
+
+	switch code {
+	case cdtMapOpTypeGetByKey, cdtMapOpTypeRemoveByKey:
+		s := memSelection{single: true}
+		if i := memFindKey(pairs, args.get(1)); i >= 0 {
+			s.sel = []int{i}
+		}
+		return s, rt, nil
+
+	case cdtMapOpTypeGetByKeyList, cdtMapOpTypeRemoveKeyList:
+		list, err := args.list(1)
+		if err != nil {
+			return memSelection{}, 0, err
+		}
+		return memSelection{sel: memValueList(keys, list)}, rt, nil
+
+	case cdtMapOpTypeGetByKeyInterval, cdtMapOpTypeRemoveByKeyInterval:
+		return memSelection{sel: memValueInterval(keys, args.get(1), args.get(2))}, rt, nil
+
+	case cdtMapOpTypeGetByKeyRelIndexRange, cdtMapOpTypeRemoveByKeyRelIndexRange:
+		index, err := args.int(2, 0)
+		if err != nil {
+			return memSelection{}, 0, err
+		}
+		count, err := args.int(3, 0)
+		if err != nil {
+			return memSelection{}, 0, err
+		}
+		// keys are sorted, so ranks of keys are their indexes
+		return memSelection{sel: memRelRankRange(keys, args.get(1), index, count, args.has(3))}, rt, nil
+
+	case cdtMapOpTypeGetByIndex, cdtMapOpTypeRemoveByIndex:
+		index, err := args.int(1, 0)
+		if err != nil {
+			return memSelection{}, 0, err
+		}
+		s := memSelection{single: true}
+		if index < int64(len(pairs)) && index >= -int64(len(pairs)) {
+			s.sel = memIndexRange(len(pairs), index, 1, true)
+		}
+		return s, rt, nil
+
+	case cdtMapOpTypeGetByIndexRange, cdtMapOpTypeRemoveByIndexRange:
+		index, err := args.int(1, 0)
+		if err != nil {
+			return memSelection{}, 0, err
+		}
+		count, err := args.int(2, 0)
+		if err != nil {
+			return memSelection{}, 0, err
+		}
+		return memSelection{sel: memIndexRange(len(pairs), index, count, args.has(2))}, rt, nil
+
+	case cdtMapOpTypeGetByRank, cdtMapOpTypeRemoveByRank:
+		rank, err := args.int(1, 0)
+		if err != nil {
+			return memSelection{}, 0, err
+		}
+		s := memSelection{single: true}
+		if rank < int64(len(pairs)) && rank >= -int64(len(pairs)) {
+			s.sel = memRankRange(values, rank, 1, true)
+		}
+		return s, rt, nil
+
+	case cdtMapOpTypeGetByRankRange, cdtMapOpTypeRemoveByRankRange:
+		rank, err := args.int(1, 0)
+		if err != nil {
+			return memSelection{}, 0, err
+		}
+		count, err := args.int(2, 0)
+		if err != nil {
+			return memSelection{}, 0, err
+		}
+		return memSelection{sel: memRankRange(values, rank, count, args.has(2))}, rt, nil
+
+	case cdtMapOpTypeGetByValue, cdtMapOpTypeRemoveByValue:
+		return memSelection{sel: memValueList(values, []interface{}{args.get(1)})}, rt, nil
+
+	case cdtMapOpTypeGetByValueList, cdtMapOpTypeRemoveValueList:
+		list, err := args.list(1)
+		if err != nil {
+			return memSelection{}, 0, err
+		}
+		return memSelection{sel: memValueList(values, list)}, rt, nil
+
+	case cdtMapOpTypeGetByValueInterval, cdtMapOpTypeRemoveByValueInterval:
+		return memSelection{sel: memValueInterval(values, args.get(1), args.get(2))}, rt, nil
+
+	case cdtMapOpTypeGetByValueRelRankRange, cdtMapOpTypeRemoveByValueRelRankRange:
+		rank, err := args.int(2, 0)
+		if err != nil {
+			return memSelection{}, 0, err
+		}
+		count, err := args.int(3, 0)
+		if err != nil {
+			return memSelection{}, 0, err
+		}
+		return memSelection{sel: memRelRankRange(values, args.get(1), rank, count, args.has(3))}, rt, nil
+	}
+
+	return memSelection{}, 0, memUnsupported(fmt.Sprintf("map operation %d", code))
+}
+
+func memIsMapRemove(code int) bool {
+	return code >= cdtMapOpTypeRemoveByKey && code <= cdtMapOpTypeRemoveByValueRelRankRange
+}
+
+// memMapOp applies a map operation to the value.
+func memMapOp(v interface{}, op *memCDTOp) (interface{}, interface{}, bool, as.Error) {
+	if v != nil && !memIsMap(v) {
+		return nil, nil, false, newError(types.BIN_TYPE_ERROR)
+	}
+	_, ordered := v.([]as.MapPair)
+	pairs := memMapPairs(v)
+	args := op.args
+
+	// the map order of new maps is set by the attributes of the operation
+	setOrder := func(i int) {
+		if attr, _ := args.int(i, 0); v == nil && attr&1 != 0 {
+			ordered = true
+		}
+	}
+
+	switch op.code {
+	case cdtMapOpTypeSetType:
+		attr, err := args.int(0, 0)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		return nil, memMapValue(pairs, attr&1 != 0), true, nil
+
+	case cdtMapOpTypeAdd, cdtMapOpTypePut, cdtMapOpTypeReplace, cdtMapOpTypeAddItems, cdtMapOpTypePutItems, cdtMapOpTypeReplaceItems:
+		var items []as.MapPair
+		var flags int64
+		switch op.code {
+		case cdtMapOpTypeAdd, cdtMapOpTypePut, cdtMapOpTypeReplace:
+			items = []as.MapPair{{Key: memMapKey(args.get(0)), Value: args.get(1)}}
+			setOrder(2)
+			flags, _ = args.int(3, 0)
+		default:
+			if !memIsMap(args.get(0)) {
+				return nil, nil, false, newError(types.PARAMETER_ERROR, "expected a map CDT argument")
+			}
+			items = memMapPairs(args.get(0))
+			setOrder(1)
+			flags, _ = args.int(2, 0)
+		}
+		switch op.code {
+		case cdtMapOpTypeAdd, cdtMapOpTypeAddItems:
+			flags |= int64(as.MapWriteFlagsCreateOnly)
+		case cdtMapOpTypeReplace, cdtMapOpTypeReplaceItems:
+			flags |= int64(as.MapWriteFlagsUpdateOnly)
+		}
+
+		res := append([]as.MapPair{}, pairs...)
+		for _, item := range items {
+			exists := memFindKey(res, item.Key) >= 0
+			var err as.Error
+			if exists && flags&int64(as.MapWriteFlagsCreateOnly) != 0 {
+				err = newError(types.FAIL_ELEMENT_EXISTS)
+			} else if !exists && flags&int64(as.MapWriteFlagsUpdateOnly) != 0 {
+				err = errMemElementNotFound
+			}
+
+			if err != nil {
+				switch {
+				case flags&int64(as.MapWriteFlagsNoFail) == 0:
+					return nil, nil, false, err
+				case flags&int64(as.MapWriteFlagsPartial) == 0:
+					return memInt(int64(len(pairs))), v, false, nil
+				}
+				continue
+			}
+			res = memPutPair(res, item.Key, item.Value)
+		}
+		return memInt(int64(len(res))), memMapValue(res, ordered), true, nil
+
+	case cdtMapOpTypeIncrement, cdtMapOpTypeDecrement:
+		key := memMapKey(args.get(0))
+		var cur interface{}
+		if i := memFindKey(pairs, key); i >= 0 {
+			cur = pairs[i].Value
+		}
+		by := args.get(1)
+		if op.code == cdtMapOpTypeDecrement {
+			if by == nil {
+				by = memInt(1)
+			}
+			switch n := by.(type) {
+			case float64:
+				by = -n
+			default:
+				i, _ := memToInt(n)
+				by = memInt(-i)
+			}
+		}
+		setOrder(2)
+		n, err := memIncrement(cur, by)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		return n, memMapValue(memPutPair(pairs, key, n), ordered), true, nil
+
+	case cdtMapOpTypeClear:
+		if v == nil {
+			return nil, v, false, nil
+		}
+		return nil, memMapValue(nil, ordered), true, nil
+
+	case cdtMapOpTypeSize:
+		if v == nil {
+			return nil, v, false, nil
+		}
+		return memInt(int64(len(pairs))), v, false, nil
+	}
+
+	if v == nil {
+		return nil, v, false, nil
+	}
+
+	s, rt, err := memMapSelect(pairs, op.code, args)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	res, err := memResult(memPairValues(pairs), memPairKeys(pairs), ordered, s, rt)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	if memIsMapRemove(op.code) {
+		sel := s.sel
+		if rt&int64(as.MapReturnType.INVERTED) != 0 {
+			sel = memInvert(len(pairs), sel)
+		}
+		if len(sel) == 0 {
+			return res, v, false, nil
+		}
+		return res, memMapValue(memRemoveIndexes(pairs, sel), ordered), true, nil
+	}
+	return res, v, false, nil
+}
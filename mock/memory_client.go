@@ -0,0 +1,2198 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+	"github.com/aerospike/aerospike-client-go/v7/internal/memclient"
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// memRecord is a record of the in-memory client.
+type memRecord struct {
+	// key is only set if the user key was sent with the write
+	key     *as.Key
+	setName string
+	digest  [20]byte
+	bins    map[string]interface{}
+
+	generation uint32
+	// voidTime is zero for records that never expire
+	voidTime   time.Time
+	lastUpdate time.Time
+	// writeTTL is the TTL of the most recent write, used by reads with ReadTouchTTLPercent
+	writeTTL time.Duration
+}
+
+func (rec *memRecord) expired(now time.Time) bool {
+	return !rec.voidTime.IsZero() && !now.Before(rec.voidTime)
+}
+
+// expiration returns the TTL of the record in the form returned by the server.
+func (rec *memRecord) expiration(now time.Time) uint32 {
+	if rec.voidTime.IsZero() {
+		return math.MaxUint32
+	}
+	if ttl := rec.voidTime.Sub(now) / time.Second; ttl > 0 {
+		return uint32(ttl)
+	}
+	return 1
+}
+
+// resultKey returns the key of the record as returned by scans and queries.
+func (rec *memRecord) resultKey(namespace string) *as.Key {
+	var userKey as.Value
+	if rec.key != nil {
+		userKey = rec.key.Value()
+	}
+	return bridge.NewKey(namespace, rec.setName, userKey, rec.digest)
+}
+
+// memDigest returns the digest of the key, as stored in the keyspace.
+func memDigest(key *as.Key) (digest [20]byte) {
+	copy(digest[:], key.Digest())
+	return digest
+}
+
+type memIndex struct {
+	namespace      string
+	setName        string
+	name           string
+	binName        string
+	indexType      as.IndexType
+	collectionType as.IndexCollectionType
+}
+
+type memUser struct {
+	password string
+	roles    []string
+}
+
+// memoryClient implements ClientIfc with an in-memory keyspace.
+// It is exposed to applications through the mock package.
+type memoryClient struct {
+	// implements the unexported methods of ClientIfc
+	as.ClientIfc
+
+	mutex sync.Mutex
+	clock func() time.Time
+
+	namespaces map[string]map[[20]byte]*memRecord
+	indexes    map[string]*memIndex
+	udfs       map[string]*as.UDF
+	users      map[string]*memUser
+	roles      map[string]*as.Role
+
+	closed bool
+
+	// DefaultPolicy is used for all read commands without a specific policy.
+	DefaultPolicy *as.BasePolicy
+	// DefaultBatchPolicy is the default parent policy used in batch read commands.
+	DefaultBatchPolicy *as.BatchPolicy
+	// DefaultBatchReadPolicy is the default read policy used in batch operate commands.
+	DefaultBatchReadPolicy *as.BatchReadPolicy
+	// DefaultBatchWritePolicy is the default write policy used in batch operate commands.
+	DefaultBatchWritePolicy *as.BatchWritePolicy
+	// DefaultBatchDeletePolicy is the default delete policy used in batch delete commands.
+	DefaultBatchDeletePolicy *as.BatchDeletePolicy
+	// DefaultBatchUDFPolicy is the default user defined function policy used in batch UDF execute commands.
+	DefaultBatchUDFPolicy *as.BatchUDFPolicy
+	// DefaultWritePolicy is used for all write commands without a specific policy.
+	DefaultWritePolicy *as.WritePolicy
+	// DefaultScanPolicy is used for all scan commands without a specific policy.
+	DefaultScanPolicy *as.ScanPolicy
+	// DefaultQueryPolicy is used for all query commands without a specific policy.
+	DefaultQueryPolicy *as.QueryPolicy
+	// DefaultAdminPolicy is used for all security commands without a specific policy.
+	DefaultAdminPolicy *as.AdminPolicy
+	// DefaultInfoPolicy is used for all info commands without a specific policy.
+	DefaultInfoPolicy *as.InfoPolicy
+}
+
+func newMemoryClient() *memoryClient {
+	clnt := &memoryClient{
+		clock: time.Now,
+
+		DefaultPolicy:            as.NewPolicy(),
+		DefaultBatchPolicy:       as.NewBatchPolicy(),
+		DefaultBatchReadPolicy:   as.NewBatchReadPolicy(),
+		DefaultBatchWritePolicy:  as.NewBatchWritePolicy(),
+		DefaultBatchDeletePolicy: as.NewBatchDeletePolicy(),
+		DefaultBatchUDFPolicy:    as.NewBatchUDFPolicy(),
+		DefaultWritePolicy:       as.NewWritePolicy(0, 0),
+		DefaultScanPolicy:        as.NewScanPolicy(),
+		DefaultQueryPolicy:       as.NewQueryPolicy(),
+		DefaultAdminPolicy:       as.NewAdminPolicy(),
+		DefaultInfoPolicy:        as.NewInfoPolicy(),
+	}
+	clnt.ClientIfc = bridge.ClientBase(clnt)
+	clnt.Reset()
+	return clnt
+}
+
+// SetClock sets the source of the current time used for the record TTLs and last update times.
+func (clnt *memoryClient) SetClock(clock func() time.Time) {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+	clnt.clock = clock
+}
+
+// Reset removes all the records, indexes, UDFs, users and roles.
+func (clnt *memoryClient) Reset() {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+
+	clnt.namespaces = map[string]map[[20]byte]*memRecord{}
+	clnt.indexes = map[string]*memIndex{}
+	clnt.udfs = map[string]*as.UDF{}
+	clnt.users = map[string]*memUser{}
+	clnt.roles = map[string]*as.Role{}
+}
+
+//-------------------------------------------------------
+// Policy methods
+//-------------------------------------------------------
+
+// DefaultPolicy returns corresponding default policy from the client
+func (clnt *memoryClient) GetDefaultPolicy() *as.BasePolicy {
+	return clnt.DefaultPolicy
+}
+
+// DefaultBatchPolicy returns corresponding default policy from the client
+func (clnt *memoryClient) GetDefaultBatchPolicy() *as.BatchPolicy {
+	return clnt.DefaultBatchPolicy
+}
+
+// DefaultBatchWritePolicy returns corresponding default policy from the client
+func (clnt *memoryClient) GetDefaultBatchWritePolicy() *as.BatchWritePolicy {
+	return clnt.DefaultBatchWritePolicy
+}
+
+// DefaultBatchReadPolicy returns corresponding default policy from the client
+func (clnt *memoryClient) GetDefaultBatchReadPolicy() *as.BatchReadPolicy {
+	return clnt.DefaultBatchReadPolicy
+}
+
+// DefaultBatchDeletePolicy returns corresponding default policy from the client
+func (clnt *memoryClient) GetDefaultBatchDeletePolicy() *as.BatchDeletePolicy {
+	return clnt.DefaultBatchDeletePolicy
+}
+
+// DefaultBatchUDFPolicy returns corresponding default policy from the client
+func (clnt *memoryClient) GetDefaultBatchUDFPolicy() *as.BatchUDFPolicy {
+	return clnt.DefaultBatchUDFPolicy
+}
+
+// DefaultWritePolicy returns corresponding default policy from the client
+func (clnt *memoryClient) GetDefaultWritePolicy() *as.WritePolicy {
+	return clnt.DefaultWritePolicy
+}
+
+// DefaultScanPolicy returns corresponding default policy from the client
+func (clnt *memoryClient) GetDefaultScanPolicy() *as.ScanPolicy {
+	return clnt.DefaultScanPolicy
+}
+
+// DefaultQueryPolicy returns corresponding default policy from the client
+func (clnt *memoryClient) GetDefaultQueryPolicy() *as.QueryPolicy {
+	return clnt.DefaultQueryPolicy
+}
+
+// DefaultAdminPolicy returns corresponding default policy from the client
+func (clnt *memoryClient) GetDefaultAdminPolicy() *as.AdminPolicy {
+	return clnt.DefaultAdminPolicy
+}
+
+// DefaultInfoPolicy returns corresponding default policy from the client
+func (clnt *memoryClient) GetDefaultInfoPolicy() *as.InfoPolicy {
+	return clnt.DefaultInfoPolicy
+}
+
+// DefaultPolicy returns corresponding default policy from the client
+func (clnt *memoryClient) SetDefaultPolicy(policy *as.BasePolicy) {
+	clnt.DefaultPolicy = policy
+}
+
+// DefaultBatchPolicy returns corresponding default policy from the client
+func (clnt *memoryClient) SetDefaultBatchPolicy(policy *as.BatchPolicy) {
+	clnt.DefaultBatchPolicy = policy
+}
+
+// DefaultBatchReadPolicy returns corresponding default policy from the client
+func (clnt *memoryClient) SetDefaultBatchReadPolicy(policy *as.BatchReadPolicy) {
+	clnt.DefaultBatchReadPolicy = policy
+}
+
+// DefaultBatchWritePolicy returns corresponding default policy from the client
+func (clnt *memoryClient) SetDefaultBatchWritePolicy(policy *as.BatchWritePolicy) {
+	clnt.DefaultBatchWritePolicy = policy
+}
+
+// DefaultBatchDeletePolicy returns corresponding default policy from the client
+func (clnt *memoryClient) SetDefaultBatchDeletePolicy(policy *as.BatchDeletePolicy) {
+	clnt.DefaultBatchDeletePolicy = policy
+}
+
+// DefaultBatchUDFPolicy returns corresponding default policy from the client
+func (clnt *memoryClient) SetDefaultBatchUDFPolicy(policy *as.BatchUDFPolicy) {
+	clnt.DefaultBatchUDFPolicy = policy
+}
+
+// DefaultWritePolicy returns corresponding default policy from the client
+func (clnt *memoryClient) SetDefaultWritePolicy(policy *as.WritePolicy) {
+	clnt.DefaultWritePolicy = policy
+}
+
+// DefaultScanPolicy returns corresponding default policy from the client
+func (clnt *memoryClient) SetDefaultScanPolicy(policy *as.ScanPolicy) {
+	clnt.DefaultScanPolicy = policy
+}
+
+// DefaultQueryPolicy returns corresponding default policy from the client
+func (clnt *memoryClient) SetDefaultQueryPolicy(policy *as.QueryPolicy) {
+	clnt.DefaultQueryPolicy = policy
+}
+
+// DefaultAdminPolicy returns corresponding default policy from the client
+func (clnt *memoryClient) SetDefaultAdminPolicy(policy *as.AdminPolicy) {
+	clnt.DefaultAdminPolicy = policy
+}
+
+// DefaultInfoPolicy returns corresponding default policy from the client
+func (clnt *memoryClient) SetDefaultInfoPolicy(policy *as.InfoPolicy) {
+	clnt.DefaultInfoPolicy = policy
+}
+
+func (clnt *memoryClient) getUsablePolicy(policy *as.BasePolicy) *as.BasePolicy {
+	if policy == nil {
+		if clnt.DefaultPolicy != nil {
+			return clnt.DefaultPolicy
+		}
+		return as.NewPolicy()
+	}
+	return policy
+}
+
+func (clnt *memoryClient) getUsableBatchPolicy(policy *as.BatchPolicy) *as.BatchPolicy {
+	if policy == nil {
+		if clnt.DefaultBatchPolicy != nil {
+			return clnt.DefaultBatchPolicy
+		}
+		return as.NewBatchPolicy()
+	}
+	return policy
+}
+
+func (clnt *memoryClient) getUsableBatchReadPolicy(policy *as.BatchReadPolicy) *as.BatchReadPolicy {
+	if policy == nil {
+		if clnt.DefaultBatchReadPolicy != nil {
+			return clnt.DefaultBatchReadPolicy
+		}
+		return as.NewBatchReadPolicy()
+	}
+	return policy
+}
+
+func (clnt *memoryClient) getUsableBatchWritePolicy(policy *as.BatchWritePolicy) *as.BatchWritePolicy {
+	if policy == nil {
+		if clnt.DefaultBatchWritePolicy != nil {
+			return clnt.DefaultBatchWritePolicy
+		}
+		return as.NewBatchWritePolicy()
+	}
+	return policy
+}
+
+func (clnt *memoryClient) getUsableBatchDeletePolicy(policy *as.BatchDeletePolicy) *as.BatchDeletePolicy {
+	if policy == nil {
+		if clnt.DefaultBatchDeletePolicy != nil {
+			return clnt.DefaultBatchDeletePolicy
+		}
+		return as.NewBatchDeletePolicy()
+	}
+	return policy
+}
+
+func (clnt *memoryClient) getUsableBatchUDFPolicy(policy *as.BatchUDFPolicy) *as.BatchUDFPolicy {
+	if policy == nil {
+		if clnt.DefaultBatchUDFPolicy != nil {
+			return clnt.DefaultBatchUDFPolicy
+		}
+		return as.NewBatchUDFPolicy()
+	}
+	return policy
+}
+
+func (clnt *memoryClient) getUsableWritePolicy(policy *as.WritePolicy) *as.WritePolicy {
+	if policy == nil {
+		if clnt.DefaultWritePolicy != nil {
+			return clnt.DefaultWritePolicy
+		}
+		return as.NewWritePolicy(0, 0)
+	}
+	return policy
+}
+
+func (clnt *memoryClient) getUsableScanPolicy(policy *as.ScanPolicy) *as.ScanPolicy {
+	if policy == nil {
+		if clnt.DefaultScanPolicy != nil {
+			return clnt.DefaultScanPolicy
+		}
+		return as.NewScanPolicy()
+	}
+	return policy
+}
+
+func (clnt *memoryClient) getUsableQueryPolicy(policy *as.QueryPolicy) *as.QueryPolicy {
+	if policy == nil {
+		if clnt.DefaultQueryPolicy != nil {
+			return clnt.DefaultQueryPolicy
+		}
+		return as.NewQueryPolicy()
+	}
+	return policy
+}
+
+func (clnt *memoryClient) getUsableAdminPolicy(policy *as.AdminPolicy) *as.AdminPolicy {
+	if policy == nil {
+		if clnt.DefaultAdminPolicy != nil {
+			return clnt.DefaultAdminPolicy
+		}
+		return as.NewAdminPolicy()
+	}
+	return policy
+}
+
+func (clnt *memoryClient) getUsableInfoPolicy(policy *as.InfoPolicy) *as.InfoPolicy {
+	if policy == nil {
+		if clnt.DefaultInfoPolicy != nil {
+			return clnt.DefaultInfoPolicy
+		}
+		return as.NewInfoPolicy()
+	}
+	return policy
+}
+
+//-------------------------------------------------------
+// Cluster Connection Management
+//-------------------------------------------------------
+
+// Close marks the client as closed. The records are kept in memory.
+func (clnt *memoryClient) Close() {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+	clnt.closed = true
+}
+
+// CloseGracefully closes the client. The commands of the memory client complete synchronously,
+// so no command is ever aborted.
+func (clnt *memoryClient) CloseGracefully(ctx context.Context) (int, as.Error) {
+	clnt.Close()
+	return 0, nil
+}
+
+// IsConnected returns true until the client is closed.
+func (clnt *memoryClient) IsConnected() bool {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+	return !clnt.closed
+}
+
+// Cluster returns nil, since the in-memory client is not connected to a cluster.
+func (clnt *memoryClient) Cluster() *as.Cluster {
+	return nil
+}
+
+// GetNodes returns an empty list, since the in-memory client is not connected to a cluster.
+func (clnt *memoryClient) GetNodes() []*as.Node {
+	return []*as.Node{}
+}
+
+// GetNodeNames returns an empty list, since the in-memory client is not connected to a cluster.
+func (clnt *memoryClient) GetNodeNames() []string {
+	return []string{}
+}
+
+// HotKeys returns nil, since the in-memory client does not sample its commands.
+func (clnt *memoryClient) HotKeys() []as.HotKey {
+	return nil
+}
+
+// WireCaptures returns nil, since the in-memory client does not use the wire protocol.
+func (clnt *memoryClient) WireCaptures() []as.WireCapture {
+	return nil
+}
+
+// QuiesceNode returns an error, since the in-memory client has no nodes.
+func (clnt *memoryClient) QuiesceNode(nodeName string) as.Error {
+	return newError(types.INVALID_NODE_ERROR, "Invalid node name "+nodeName)
+}
+
+// UnquiesceNode does nothing, since the in-memory client has no nodes.
+func (clnt *memoryClient) UnquiesceNode(nodeName string) {}
+
+// TendNow does nothing, since there is no cluster to tend.
+func (clnt *memoryClient) TendNow() as.Error {
+	return nil
+}
+
+// WarmUp does nothing, and returns 0.
+func (clnt *memoryClient) WarmUp(count int) (int, as.Error) {
+	return 0, nil
+}
+
+// String implements the Stringer interface.
+func (clnt *memoryClient) String() string {
+	return "in-memory client"
+}
+
+// Stats returns the number of records in each namespace.
+func (clnt *memoryClient) Stats() (map[string]interface{}, as.Error) {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+
+	res := make(map[string]interface{}, len(clnt.namespaces))
+	for ns, recs := range clnt.namespaces {
+		res[ns] = map[string]interface{}{"objects": len(recs)}
+	}
+	return res, nil
+}
+
+// EnableMetrics does nothing; metrics are not collected by the in-memory client.
+func (clnt *memoryClient) EnableMetrics(policy *as.MetricsPolicy) {}
+
+// DisableMetrics does nothing; metrics are not collected by the in-memory client.
+func (clnt *memoryClient) DisableMetrics() {}
+
+// MetricsEnabled always returns false.
+func (clnt *memoryClient) MetricsEnabled() bool {
+	return false
+}
+
+//-------------------------------------------------------
+// Storage
+//-------------------------------------------------------
+
+// record returns the live record of the key, removing it if it has expired.
+// Must be called while holding the mutex.
+func (clnt *memoryClient) record(namespace string, digest [20]byte, now time.Time) *memRecord {
+	recs := clnt.namespaces[namespace]
+	rec := recs[digest]
+	if rec != nil && rec.expired(now) {
+		delete(recs, digest)
+		return nil
+	}
+	return rec
+}
+
+func (clnt *memoryClient) store(namespace string, rec *memRecord) {
+	recs := clnt.namespaces[namespace]
+	if recs == nil {
+		recs = map[[20]byte]*memRecord{}
+		clnt.namespaces[namespace] = recs
+	}
+	recs[rec.digest] = rec
+}
+
+// voidTime computes the void time of a write with the expiration of the policy.
+func memVoidTime(expiration uint32, rec *memRecord, now time.Time) time.Time {
+	switch expiration {
+	case as.TTLServerDefault, as.TTLDontExpire:
+		return time.Time{}
+	case as.TTLDontUpdate:
+		if rec != nil {
+			return rec.voidTime
+		}
+		return time.Time{}
+	}
+	return now.Add(time.Duration(expiration) * time.Second)
+}
+
+// memReadTouch resets the TTL of a record that is read within percent of the TTL of its
+// most recent write from its end of life, like the server does for ReadTouchTTLPercent.
+// Zero is the default of the namespaces, which is not to touch the records.
+func memReadTouch(rec *memRecord, percent int32, now time.Time) {
+	if percent <= 0 || percent > 100 || rec.voidTime.IsZero() || rec.writeTTL <= 0 {
+		return
+	}
+
+	if rec.voidTime.Sub(now) <= rec.writeTTL*time.Duration(percent)/100 {
+		rec.voidTime = now.Add(rec.writeTTL)
+		rec.lastUpdate = now
+		rec.generation++
+		if rec.generation > 0xFFFF {
+			rec.generation = 1
+		}
+	}
+}
+
+// memBinResult is the result of an operation on a bin.
+type memBinResult struct {
+	name  string
+	value interface{}
+}
+
+// operate runs the operations on the record of the key atomically.
+// respondAll returns a result for every operation, as batch writes and
+// WritePolicy.RespondPerEachOp do.
+func (clnt *memoryClient) operate(policy *as.WritePolicy, key *as.Key, ops []*as.Operation, respondAll, useOpResults bool) (*as.Record, as.Error) {
+	if len(ops) == 0 {
+		return nil, newError(types.PARAMETER_ERROR, "no operations were passed to Operate")
+	}
+
+	mops := make([]memOp, len(ops))
+	hasWrite := false
+	for i, op := range ops {
+		mops[i] = memDecodeOp(op)
+		hasWrite = hasWrite || mops[i].isWrite
+	}
+	respondAll = respondAll || policy.RespondPerEachOp
+
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+
+	now := clnt.clock()
+	rec := clnt.record(key.Namespace(), memDigest(key), now)
+
+	if rec != nil && policy.FilterExpression != nil {
+		env := &memEvalEnv{rec: rec, bins: rec.bins, now: now}
+		ok, err := memEvalFilter(policy.FilterExpression, env)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, constError(as.ErrFilteredOut)
+		}
+	}
+
+	if rec == nil && !hasWrite {
+		return nil, constError(as.ErrKeyNotFound)
+	}
+
+	if !hasWrite {
+		memReadTouch(rec, policy.ReadTouchTTLPercent, now)
+	}
+
+	if hasWrite {
+		if err := memCheckWrite(policy, rec); err != nil {
+			return nil, err
+		}
+	}
+
+	// the operations are applied to a copy of the record, so that a failure does not modify it
+	work := &memRecord{key: key, setName: key.SetName(), digest: memDigest(key), lastUpdate: now, bins: map[string]interface{}{}}
+	if rec != nil {
+		work.voidTime, work.lastUpdate, work.generation, work.writeTTL = rec.voidTime, rec.lastUpdate, rec.generation, rec.writeTTL
+		if !policy.SendKey {
+			work.key = rec.key
+		}
+		if !hasWrite || (policy.RecordExistsAction != as.REPLACE && policy.RecordExistsAction != as.REPLACE_ONLY) {
+			for name, v := range rec.bins {
+				work.bins[name] = memClone(v)
+			}
+		}
+	} else if !policy.SendKey {
+		work.key = nil
+	}
+
+	results := make([]memBinResult, 0, len(ops))
+	for i := range mops {
+		op := &mops[i]
+		res, respond, err := clnt.applyOp(work, op, now)
+		if err != nil {
+			return nil, err
+		}
+		if respond || (respondAll && op.binName != "") {
+			if len(res) == 0 && op.binName != "" {
+				// the server responds with a nil value for the operations without results
+				res = []memBinResult{{name: op.binName}}
+			}
+			results = append(results, res...)
+		}
+	}
+
+	if hasWrite {
+		switch {
+		case len(work.bins) == 0:
+			// records without bins do not exist
+			if rec == nil {
+				return nil, constError(as.ErrKeyNotFound)
+			}
+			delete(clnt.namespaces[key.Namespace()], memDigest(key))
+		default:
+			work.generation++
+			if work.generation > 0xFFFF {
+				work.generation = 1
+			}
+			work.voidTime = memVoidTime(policy.Expiration, rec, now)
+			if policy.Expiration != as.TTLDontUpdate {
+				work.writeTTL = 0
+				if !work.voidTime.IsZero() {
+					work.writeTTL = work.voidTime.Sub(now)
+				}
+			}
+			work.lastUpdate = now
+			clnt.store(key.Namespace(), work)
+		}
+	}
+
+	bins := make(as.BinMap, len(results))
+	var multi []string
+	for _, res := range results {
+		v := memClone(res.value)
+		if prev, exists := bins[res.name]; exists {
+			if l, ok := prev.(as.OpResults); ok {
+				bins[res.name] = append(l, v)
+			} else {
+				bins[res.name] = as.OpResults{prev, v}
+				multi = append(multi, res.name)
+			}
+		} else {
+			bins[res.name] = v
+		}
+	}
+	if !useOpResults {
+		for _, name := range multi {
+			bins[name] = []interface{}(bins[name].(as.OpResults))
+		}
+	}
+
+	return bridge.NewRecord(key, bins, work.generation, work.expiration(now)), nil
+}
+
+// memCheckWrite validates the record exists action and generation policy of a write.
+func memCheckWrite(policy *as.WritePolicy, rec *memRecord) as.Error {
+	switch policy.RecordExistsAction {
+	case as.UPDATE_ONLY, as.REPLACE_ONLY:
+		if rec == nil {
+			return constError(as.ErrKeyNotFound)
+		}
+	case as.CREATE_ONLY:
+		if rec != nil {
+			return newError(types.KEY_EXISTS_ERROR)
+		}
+	}
+
+	if rec != nil {
+		switch policy.GenerationPolicy {
+		case as.EXPECT_GEN_EQUAL:
+			if rec.generation != policy.Generation {
+				return newError(types.GENERATION_ERROR)
+			}
+		case as.EXPECT_GEN_GT:
+			if rec.generation >= policy.Generation {
+				return newError(types.GENERATION_ERROR)
+			}
+		}
+	}
+	return nil
+}
+
+// applyOp applies the operation to the record, and returns its results.
+// respond is true for the operations that always return a result.
+func (clnt *memoryClient) applyOp(rec *memRecord, op *memOp, now time.Time) (res []memBinResult, respond bool, err as.Error) {
+	bins := rec.bins
+
+	switch op.kind {
+	case memclient.OpRead:
+		if op.binName == "" {
+			names := make([]string, 0, len(bins))
+			for name := range bins {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				res = append(res, memBinResult{name: name, value: bins[name]})
+			}
+			return res, true, nil
+		}
+		if v, exists := bins[op.binName]; exists {
+			return []memBinResult{{name: op.binName, value: v}}, true, nil
+		}
+		return nil, false, nil
+
+	case memclient.OpReadHeader:
+		return nil, false, nil
+
+	case memclient.OpWrite:
+		v, err := memParticle(op.binValue)
+		if err != nil {
+			return nil, false, err
+		}
+		if v == nil {
+			delete(bins, op.binName)
+		} else {
+			bins[op.binName] = v
+		}
+
+	case memclient.OpAdd:
+		v, err := memParticle(op.binValue)
+		if err != nil {
+			return nil, false, err
+		}
+		prev, exists := bins[op.binName]
+		if !exists {
+			bins[op.binName] = v
+			break
+		}
+		if _, ok := v.(float64); !ok {
+			if _, ok := memToInt(v); !ok {
+				return nil, false, newError(types.PARAMETER_ERROR, "only integer and float values can be added to a bin")
+			}
+		}
+		if memTypeOrder(prev) != memTypeOrder(v) {
+			return nil, false, newError(types.BIN_TYPE_ERROR)
+		}
+		sum, err := memIncrement(prev, v)
+		if err != nil {
+			return nil, false, err
+		}
+		bins[op.binName] = sum
+
+	case memclient.OpAppend, memclient.OpPrepend:
+		v, err := memParticle(op.binValue)
+		if err != nil {
+			return nil, false, err
+		}
+		prev, exists := bins[op.binName]
+		if !exists {
+			bins[op.binName] = v
+			break
+		}
+		switch p := prev.(type) {
+		case string:
+			s, ok := v.(string)
+			if !ok {
+				return nil, false, newError(types.BIN_TYPE_ERROR)
+			}
+			if op.kind == memclient.OpAppend {
+				bins[op.binName] = p + s
+			} else {
+				bins[op.binName] = s + p
+			}
+		case []byte:
+			b, ok := v.([]byte)
+			if !ok {
+				return nil, false, newError(types.BIN_TYPE_ERROR)
+			}
+			if op.kind == memclient.OpAppend {
+				bins[op.binName] = append(p, b...)
+			} else {
+				bins[op.binName] = append(append([]byte{}, b...), p...)
+			}
+		default:
+			return nil, false, newError(types.BIN_TYPE_ERROR)
+		}
+
+	case memclient.OpTouch:
+		// the generation and TTL of all writes are updated after the operations
+
+	case memclient.OpDelete:
+		for name := range bins {
+			delete(bins, name)
+		}
+
+	case memclient.OpCDTRead, memclient.OpCDTModify, memclient.OpMapRead, memclient.OpMapModify:
+		b, err := bridge.EncodeOp(op.op)
+		if err != nil {
+			return nil, false, err
+		}
+		cdtOp, err := memDecodeCDTOpBytes(b)
+		if err != nil {
+			return nil, false, err
+		}
+
+		result, newBin, modified, err := memApplyCDT(memClone(bins[op.binName]), cdtOp)
+		if err != nil {
+			return nil, false, err
+		}
+		if op.isWrite && modified {
+			if newBin == nil {
+				delete(bins, op.binName)
+			} else {
+				bins[op.binName] = newBin
+			}
+		}
+		return []memBinResult{{name: op.binName, value: result}}, true, nil
+
+	case memclient.OpExpRead, memclient.OpExpModify:
+		return clnt.applyExpOp(rec, op, now)
+
+	default:
+		return nil, false, memUnsupported("bit and HLL operations")
+	}
+
+	return []memBinResult{{name: op.binName}}, false, nil
+}
+
+// applyExpOp evaluates the expression of the expression read and write operations.
+func (clnt *memoryClient) applyExpOp(rec *memRecord, op *memOp, now time.Time) ([]memBinResult, bool, as.Error) {
+	bv, ok := op.binValue.(as.BytesValue)
+	if !ok {
+		return nil, false, newError(types.PARAMETER_ERROR, "invalid expression operation")
+	}
+	d := &memDecoder{buf: bv}
+	if _, ok, err := d.arrayLen(); err != nil || !ok {
+		return nil, false, newError(types.PARAMETER_ERROR, "invalid expression operation")
+	}
+	exp, err := d.expression()
+	if err != nil {
+		return nil, false, err
+	}
+	flags, err := d.int()
+	if err != nil {
+		return nil, false, err
+	}
+
+	env := &memEvalEnv{rec: rec, bins: rec.bins, now: now}
+	v, err := env.eval(exp)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if v == memUnknown {
+		if flags&int64(as.ExpWriteFlagEvalNoFail) == 0 {
+			return nil, false, newError(types.OP_NOT_APPLICABLE, "expression evaluated to unknown")
+		}
+		if op.kind == memclient.OpExpRead {
+			return []memBinResult{{name: op.binName}}, true, nil
+		}
+		return []memBinResult{{name: op.binName}}, false, nil
+	}
+
+	if op.kind == memclient.OpExpRead {
+		return []memBinResult{{name: op.binName, value: v}}, true, nil
+	}
+
+	noFail := flags&int64(as.ExpWriteFlagPolicyNoFail) != 0
+	_, exists := rec.bins[op.binName]
+	switch {
+	case flags&int64(as.ExpWriteFlagCreateOnly) != 0 && exists:
+		if !noFail {
+			return nil, false, newError(types.BIN_EXISTS_ERROR)
+		}
+	case flags&int64(as.ExpWriteFlagUpdateOnly) != 0 && !exists:
+		if !noFail {
+			return nil, false, newError(types.BIN_NOT_FOUND)
+		}
+	case v == nil:
+		if flags&int64(as.ExpWriteFlagAllowDelete) != 0 {
+			delete(rec.bins, op.binName)
+		} else if !noFail {
+			return nil, false, newError(types.OP_NOT_APPLICABLE, "expression evaluated to nil")
+		}
+	default:
+		rec.bins[op.binName] = v
+	}
+	return []memBinResult{{name: op.binName}}, false, nil
+}
+
+//-------------------------------------------------------
+// Write Record Operations
+//-------------------------------------------------------
+
+func memBinOps(newOp func(*as.Bin) *as.Operation, binMap as.BinMap) []*as.Operation {
+	names := make([]string, 0, len(binMap))
+	for name := range binMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ops := make([]*as.Operation, 0, len(binMap))
+	for _, name := range names {
+		ops = append(ops, newOp(as.NewBin(name, binMap[name])))
+	}
+	return ops
+}
+
+func memBinsOps(newOp func(*as.Bin) *as.Operation, bins []*as.Bin) []*as.Operation {
+	ops := make([]*as.Operation, 0, len(bins))
+	for _, bin := range bins {
+		ops = append(ops, newOp(bin))
+	}
+	return ops
+}
+
+func (clnt *memoryClient) write(policy *as.WritePolicy, key *as.Key, ops []*as.Operation) as.Error {
+	policy = clnt.getUsableWritePolicy(policy)
+	if len(ops) == 0 {
+		ops = []*as.Operation{as.TouchOp()}
+	}
+	_, err := clnt.operate(policy, key, ops, false, false)
+	return err
+}
+
+// Put writes record bin(s) to the in-memory keyspace.
+// The policy specifies the command timeout, record expiration and how the command is
+// handled when the record already exists.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) Put(policy *as.WritePolicy, key *as.Key, binMap as.BinMap) as.Error {
+	return clnt.write(policy, key, memBinOps(as.PutOp, binMap))
+}
+
+// PutBins writes record bin(s) to the in-memory keyspace.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) PutBins(policy *as.WritePolicy, key *as.Key, bins ...*as.Bin) as.Error {
+	return clnt.write(policy, key, memBinsOps(as.PutOp, bins))
+}
+
+// Append appends bin value's string to existing record bin values.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) Append(policy *as.WritePolicy, key *as.Key, binMap as.BinMap) as.Error {
+	return clnt.write(policy, key, memBinOps(as.AppendOp, binMap))
+}
+
+// AppendBins works the same as Append, but avoids BinMap allocation and iteration.
+func (clnt *memoryClient) AppendBins(policy *as.WritePolicy, key *as.Key, bins ...*as.Bin) as.Error {
+	return clnt.write(policy, key, memBinsOps(as.AppendOp, bins))
+}
+
+// Prepend prepends bin value's string to existing record bin values.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) Prepend(policy *as.WritePolicy, key *as.Key, binMap as.BinMap) as.Error {
+	return clnt.write(policy, key, memBinOps(as.PrependOp, binMap))
+}
+
+// PrependBins works the same as Prepend, but avoids BinMap allocation and iteration.
+func (clnt *memoryClient) PrependBins(policy *as.WritePolicy, key *as.Key, bins ...*as.Bin) as.Error {
+	return clnt.write(policy, key, memBinsOps(as.PrependOp, bins))
+}
+
+// Add adds integer bin values to existing record bin values.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) Add(policy *as.WritePolicy, key *as.Key, binMap as.BinMap) as.Error {
+	return clnt.write(policy, key, memBinOps(as.AddOp, binMap))
+}
+
+// AddBins works the same as Add, but avoids BinMap allocation and iteration.
+func (clnt *memoryClient) AddBins(policy *as.WritePolicy, key *as.Key, bins ...*as.Bin) as.Error {
+	return clnt.write(policy, key, memBinsOps(as.AddOp, bins))
+}
+
+// Delete deletes a record for specified key.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) Delete(policy *as.WritePolicy, key *as.Key) (bool, as.Error) {
+	policy = clnt.getUsableWritePolicy(policy)
+	_, err := clnt.operate(policy, key, []*as.Operation{as.DeleteOp()}, false, false)
+	if err != nil {
+		if err.Matches(types.KEY_NOT_FOUND_ERROR) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteWithResult deletes a record for specified key, and reports if the record
+// existed and if the delete left a tombstone.
+// The in-memory keyspace behaves like an Enterprise Edition server, but does not keep the tombstones.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) DeleteWithResult(policy *as.WritePolicy, key *as.Key) (*as.DeleteResult, as.Error) {
+	policy = clnt.getUsableWritePolicy(policy)
+	existed, err := clnt.Delete(policy, key)
+	if err != nil {
+		return nil, err
+	}
+	return &as.DeleteResult{Existed: existed, Tombstone: existed && policy.DurableDelete}, nil
+}
+
+// Touch updates a record's metadata.
+// If the record exists, the record's TTL will be reset to the
+// policy's expiration.
+// If the record doesn't exist, it will return an error.
+func (clnt *memoryClient) Touch(policy *as.WritePolicy, key *as.Key) as.Error {
+	policy = clnt.getUsableWritePolicy(policy)
+	if !clnt.exists(key) {
+		return constError(as.ErrKeyNotFound)
+	}
+	_, err := clnt.operate(policy, key, []*as.Operation{as.TouchOp()}, false, false)
+	return err
+}
+
+func (clnt *memoryClient) exists(key *as.Key) bool {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+	return clnt.record(key.Namespace(), memDigest(key), clnt.clock()) != nil
+}
+
+//-------------------------------------------------------
+// Read Record Operations
+//-------------------------------------------------------
+
+func memReadOps(binNames []string) []*as.Operation {
+	if len(binNames) == 0 {
+		return []*as.Operation{as.GetOp()}
+	}
+	ops := make([]*as.Operation, len(binNames))
+	for i := range binNames {
+		ops[i] = as.GetBinOp(binNames[i])
+	}
+	return ops
+}
+
+func (clnt *memoryClient) read(policy *as.BasePolicy, key *as.Key, ops []*as.Operation) (*as.Record, as.Error) {
+	wp := as.NewWritePolicy(0, 0)
+	wp.BasePolicy = *clnt.getUsablePolicy(policy)
+	return clnt.operate(wp, key, ops, false, false)
+}
+
+// Exists determine if a record key exists.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) Exists(policy *as.BasePolicy, key *as.Key) (bool, as.Error) {
+	_, err := clnt.read(policy, key, []*as.Operation{as.GetHeaderOp()})
+	if err != nil {
+		if err.Matches(types.KEY_NOT_FOUND_ERROR) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Get reads a record header and bins for specified key.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) Get(policy *as.BasePolicy, key *as.Key, binNames ...string) (*as.Record, as.Error) {
+	return clnt.read(policy, key, memReadOps(binNames))
+}
+
+// GetBin reads a single bin of the record for specified key.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) GetBin(policy *as.BasePolicy, key *as.Key, binName string) (interface{}, as.Error) {
+	rec, err := clnt.Get(policy, key, binName)
+	if err != nil {
+		return nil, err
+	}
+	return rec.Bins[binName], nil
+}
+
+// GetHeader reads a record generation and expiration only for specified key.
+// Bins are not read.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) GetHeader(policy *as.BasePolicy, key *as.Key) (*as.Record, as.Error) {
+	return clnt.read(policy, key, []*as.Operation{as.GetHeaderOp()})
+}
+
+// Operate performs multiple read/write operations on a single key in one batch request.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) Operate(policy *as.WritePolicy, key *as.Key, operations ...*as.Operation) (*as.Record, as.Error) {
+	policy = clnt.getUsableWritePolicy(policy)
+	return clnt.operate(policy, key, operations, false, false)
+}
+
+// OperateReplicas performs read-only operations on the single copy of the in-memory record.
+// The returned replica has no node.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) OperateReplicas(policy *as.WritePolicy, key *as.Key, operations ...*as.Operation) ([]*as.ReplicaRecord, as.Error) {
+	policy = clnt.getUsableWritePolicy(policy)
+	if len(operations) == 0 {
+		operations = []*as.Operation{as.GetOp()}
+	}
+
+	for _, op := range operations {
+		if memDecodeOp(op).isWrite {
+			return nil, newError(types.PARAMETER_ERROR, "OperateReplicas does not allow write operations")
+		}
+	}
+
+	rr := &as.ReplicaRecord{}
+	rr.Record, rr.Err = clnt.operate(policy, key, operations, false, false)
+	if rr.Err == nil {
+		clnt.mutex.Lock()
+		if rec := clnt.record(key.Namespace(), memDigest(key), clnt.clock()); rec != nil {
+			rr.LastUpdateTime = rec.lastUpdate
+			rr.Record.LastUpdateTime = rec.lastUpdate
+		}
+		clnt.mutex.Unlock()
+	}
+	return []*as.ReplicaRecord{rr}, nil
+}
+
+// OperateWithResults works like Operate, but also returns the result of each operation,
+// in the same order as the operations.
+// Operations reading all the bins of the record are not supported.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) OperateWithResults(policy *as.WritePolicy, key *as.Key, operations ...*as.Operation) (*as.Record, []as.OpResult, as.Error) {
+	if err := bridge.ValidateOpResultsOps(operations); err != nil {
+		return nil, nil, err
+	}
+
+	rec, err := clnt.operate(clnt.getUsableWritePolicy(policy), key, operations, true, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rec, bridge.OpResults(operations, rec), nil
+}
+
+// DocGet reads the value at the path of the JSON document stored in the map bin.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) DocGet(policy *as.BasePolicy, key *as.Key, binName string, path string) (interface{}, as.Error) {
+	return bridge.DocGet(clnt, policy, key, binName, path)
+}
+
+// DocSet sets the value at the path of the JSON document stored in the map bin.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) DocSet(policy *as.WritePolicy, key *as.Key, binName string, path string, value interface{}) as.Error {
+	return bridge.DocSet(clnt, policy, key, binName, path, value)
+}
+
+// DocAppend appends the value to the list at the path of the JSON document stored in the map bin.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) DocAppend(policy *as.WritePolicy, key *as.Key, binName string, path string, value interface{}) as.Error {
+	return bridge.DocAppend(clnt, policy, key, binName, path, value)
+}
+
+// DocDelete removes the element at the path of the JSON document stored in the map bin.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) DocDelete(policy *as.WritePolicy, key *as.Key, binName string, path string) as.Error {
+	return bridge.DocDelete(clnt, policy, key, binName, path)
+}
+
+//-------------------------------------------------------
+// Batch Read Operations
+//-------------------------------------------------------
+
+// batchRead reads the keys, and returns nil for the records that are not found or filtered out.
+func (clnt *memoryClient) batchRead(policy *as.BatchPolicy, keys []*as.Key, ops []*as.Operation) ([]*as.Record, as.Error) {
+	policy = clnt.getUsableBatchPolicy(policy)
+	wp := bridge.BatchWritePolicy(policy)
+
+	records := make([]*as.Record, len(keys))
+	filteredOut := 0
+	for i, key := range keys {
+		rec, err := clnt.operate(wp, key, ops, false, false)
+		switch {
+		case err == nil:
+			records[i] = rec
+		case err.Matches(types.FILTERED_OUT):
+			filteredOut++
+		case !err.Matches(types.KEY_NOT_FOUND_ERROR):
+			return nil, err
+		}
+	}
+
+	if filteredOut > 0 {
+		return records, constError(as.ErrFilteredOut)
+	}
+	return records, nil
+}
+
+// BatchExists determines if multiple record keys exist in one batch request.
+// The returned boolean array is in positional order with the original key array order.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) BatchExists(policy *as.BatchPolicy, keys []*as.Key) ([]bool, as.Error) {
+	records, err := clnt.batchRead(policy, keys, []*as.Operation{as.GetHeaderOp()})
+	if records == nil {
+		return nil, err
+	}
+
+	res := make([]bool, len(keys))
+	for i := range records {
+		res[i] = records[i] != nil
+	}
+	return res, err
+}
+
+// BatchExistsFunc determines if multiple record keys exist, and reports the result of each key
+// to fn in the positional order of the keys.
+// The keys are checked in consecutive chunks of chunkSize keys.
+// Return false from fn to stop the iteration.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) BatchExistsFunc(policy *as.BatchPolicy, keys []*as.Key, chunkSize int, fn func(index int, exists bool) bool) as.Error {
+	return bridge.BatchExistsChunks(keys, chunkSize, func(keys []*as.Key, existsArray []bool) as.Error {
+		res, err := clnt.BatchExists(policy, keys)
+		copy(existsArray, res)
+		return err
+	}, fn)
+}
+
+// BatchGet reads multiple record headers and bins for specified keys in one batch request.
+// The returned records are in positional order with the original key array order.
+// If a key is not found, the positional record will be nil.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) BatchGet(policy *as.BatchPolicy, keys []*as.Key, binNames ...string) ([]*as.Record, as.Error) {
+	return clnt.batchRead(policy, keys, memReadOps(binNames))
+}
+
+// BatchGetOperate reads multiple records for specified keys using read operations in one batch call.
+// The returned records are in positional order with the original key array order.
+// If a key is not found, the positional record will be nil.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) BatchGetOperate(policy *as.BatchPolicy, keys []*as.Key, ops ...*as.Operation) ([]*as.Record, as.Error) {
+	return clnt.batchRead(policy, keys, ops)
+}
+
+// BatchGetHeader reads multiple record header data for specified keys in one batch request.
+// The returned records are in positional order with the original key array order.
+// If a key is not found, the positional record will be nil.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) BatchGetHeader(policy *as.BatchPolicy, keys []*as.Key) ([]*as.Record, as.Error) {
+	return clnt.batchRead(policy, keys, []*as.Operation{as.GetHeaderOp()})
+}
+
+// BatchGetDigest returns the digests of the records that exist and pass the filter expression
+// of the policy, in the positional order of the keys.
+// Records rejected by the filter expression are left out of the result.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) BatchGetDigest(policy *as.BatchPolicy, keys []*as.Key) ([][]byte, as.Error) {
+	records, err := clnt.batchRead(policy, keys, []*as.Operation{as.GetHeaderOp()})
+	if records == nil {
+		return nil, err
+	}
+
+	existsArray := make([]bool, len(keys))
+	for i := range records {
+		existsArray[i] = records[i] != nil
+	}
+	return bridge.ExistingDigests(keys, existsArray), nil
+}
+
+// BatchGetComplex reads multiple records for specified batch keys in one batch call.
+// This method allows different namespaces/bins to be requested for each key in the batch.
+// The returned records are located in the same list.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) BatchGetComplex(policy *as.BatchPolicy, records []*as.BatchRead) as.Error {
+	policy = clnt.getUsableBatchPolicy(policy)
+
+	filteredOut := false
+	for _, br := range records {
+		bridge.PrepareBatchRecord(br)
+		clnt.batchOperateRecord(policy, br)
+		if br.ResultCode == types.FILTERED_OUT {
+			filteredOut = true
+		}
+	}
+
+	if filteredOut {
+		return constError(as.ErrFilteredOut)
+	}
+	return nil
+}
+
+// BatchOperate will read/write multiple records for specified batch keys in one batch call.
+// This method allows different namespaces/bins for each key in the batch.
+// The returned records are located in the same list.
+// BatchUDF commands are not supported, and fail with an UNSUPPORTED_FEATURE result code.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) BatchOperate(policy *as.BatchPolicy, records []as.BatchRecordIfc) as.Error {
+	policy = clnt.getUsableBatchPolicy(policy)
+
+	for _, br := range records {
+		bridge.PrepareBatchRecord(br)
+		clnt.batchOperateRecord(policy, br)
+	}
+	return nil
+}
+
+func (clnt *memoryClient) batchOperateRecord(policy *as.BatchPolicy, br as.BatchRecordIfc) {
+	var wp *as.WritePolicy
+	var ops []*as.Operation
+	respondAll := false
+
+	switch r := br.(type) {
+	case *as.BatchRead:
+		wp = bridge.BatchReadWritePolicy(clnt.getUsableBatchReadPolicy(r.Policy), policy)
+		switch {
+		case len(r.Ops) > 0:
+			ops = r.Ops
+		case len(r.BinNames) > 0:
+			ops = memReadOps(r.BinNames)
+		case r.ReadAllBins:
+			ops = []*as.Operation{as.GetOp()}
+		default:
+			ops = []*as.Operation{as.GetHeaderOp()}
+		}
+	case *as.BatchWrite:
+		wp = bridge.BatchWriteWritePolicy(clnt.getUsableBatchWritePolicy(r.Policy), policy)
+		ops = r.Ops
+		respondAll = true
+	case *as.BatchDelete:
+		wp = bridge.BatchDeleteWritePolicy(clnt.getUsableBatchDeletePolicy(r.Policy), policy)
+		ops = []*as.Operation{as.DeleteOp()}
+	default:
+		err := memUnsupported("UDFs")
+		bridge.SetBatchErrorWithMsg(br, err)
+		return
+	}
+
+	rec, err := clnt.operate(wp, br.BatchRec().Key, ops, respondAll, true)
+	if err != nil {
+		bridge.SetBatchError(br, err)
+		return
+	}
+	bridge.SetBatchRecord(br, rec)
+}
+
+//-------------------------------------------------------
+// Batch Write Operations
+//-------------------------------------------------------
+
+// BatchDelete deletes records for specified keys. If a key is not found, the corresponding result
+// BatchRecord.ResultCode will be types.KEY_NOT_FOUND_ERROR.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) BatchDelete(policy *as.BatchPolicy, deletePolicy *as.BatchDeletePolicy, keys []*as.Key) ([]*as.BatchRecord, as.Error) {
+	policy = clnt.getUsableBatchPolicy(policy)
+	wp := bridge.BatchDeleteWritePolicy(clnt.getUsableBatchDeletePolicy(deletePolicy), policy)
+
+	res := make([]*as.BatchRecord, len(keys))
+	for i, key := range keys {
+		res[i] = bridge.NewBatchRecord(key, true)
+		rec, err := clnt.operate(wp, key, []*as.Operation{as.DeleteOp()}, false, true)
+		if err != nil {
+			bridge.SetBatchError(res[i], err)
+			continue
+		}
+		bridge.SetBatchRecord(res[i], rec)
+	}
+	return res, nil
+}
+
+// BatchExecute is not supported by the in-memory client, since it does not run UDFs.
+func (clnt *memoryClient) BatchExecute(policy *as.BatchPolicy, udfPolicy *as.BatchUDFPolicy, keys []*as.Key, packageName string, functionName string, args ...as.Value) ([]*as.BatchRecord, as.Error) {
+	return nil, memUnsupported("UDFs")
+}
+
+//-------------------------------------------------------
+// Scan and Query Operations
+//-------------------------------------------------------
+
+// memQuery describes the records a scan or a query reads.
+type memQuery struct {
+	policy    *as.MultiPolicy
+	namespace string
+	setName   string
+	binNames  []string
+	exclude   []string
+	filter    *as.Filter
+	index     *memIndex
+}
+
+// findIndex returns the index of the query filter.
+// Must be called while holding the mutex.
+func (clnt *memoryClient) findIndex(stmt *as.Statement) (*memIndex, as.Error) {
+	binName, _, _, hasCtx := bridge.Filter(stmt.Filter)
+	if hasCtx {
+		return nil, memUnsupported("secondary index filters with a CDT context")
+	}
+
+	for _, idx := range clnt.indexes {
+		if idx.namespace != stmt.Namespace || idx.binName != binName || idx.collectionType != stmt.Filter.IndexCollectionType() {
+			continue
+		}
+		if idx.setName != "" && idx.setName != stmt.SetName {
+			continue
+		}
+		if stmt.IndexName != "" && idx.name != stmt.IndexName {
+			continue
+		}
+		if idx.indexType == as.GEO2DSPHERE {
+			return nil, memUnsupported("geospatial queries")
+		}
+		return idx, nil
+	}
+	return nil, newError(types.INDEX_NOTFOUND)
+}
+
+// matches reports if the bin value of the record matches the secondary index filter.
+func (q *memQuery) matches(rec *memRecord) (bool, as.Error) {
+	if q.filter == nil {
+		return true, nil
+	}
+
+	binName, beginValue, endValue, _ := bridge.Filter(q.filter)
+	v, exists := rec.bins[binName]
+	if !exists {
+		return false, nil
+	}
+
+	var candidates []interface{}
+	switch q.filter.IndexCollectionType() {
+	case as.ICT_DEFAULT:
+		candidates = []interface{}{v}
+	case as.ICT_LIST:
+		candidates, _ = v.([]interface{})
+	case as.ICT_MAPKEYS:
+		candidates = memPairKeys(memMapPairs(v))
+	case as.ICT_MAPVALUES:
+		candidates = memPairValues(memMapPairs(v))
+	}
+
+	begin, err := memParticle(beginValue)
+	if err != nil {
+		return false, err
+	}
+	end, err := memParticle(endValue)
+	if err != nil {
+		return false, err
+	}
+
+	for _, c := range candidates {
+		switch q.index.indexType {
+		case as.NUMERIC:
+			i, ok := memToInt(c)
+			b, _ := memToInt(begin)
+			e, _ := memToInt(end)
+			if ok && i >= b && i <= e {
+				return true, nil
+			}
+		case as.STRING:
+			if s, ok := c.(string); ok && s == begin {
+				return true, nil
+			}
+		case as.BLOB:
+			// blob map keys are stored as byte arrays
+			_, isHLL := c.(as.HLLValue)
+			if b, ok := begin.([]byte); ok && !isHLL && memTypeOrder(c) == memTypeOrder(b) && bytes.Equal(memBytes(c), b) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// execute collects the records of the scan or query, and sends them to a new recordset.
+func (clnt *memoryClient) execute(q *memQuery, pf *as.PartitionFilter) (*as.Recordset, as.Error) {
+	records, err := clnt.collect(q, pf)
+	if err != nil {
+		return nil, err
+	}
+
+	res := bridge.NewRecordset(q.policy.RecordQueueSize)
+	go func() {
+		defer bridge.SignalEnd(res)
+		for _, rec := range records {
+			if !bridge.SendRecord(res, rec) {
+				return
+			}
+		}
+	}()
+	return res, nil
+}
+
+func (clnt *memoryClient) collect(q *memQuery, pf *as.PartitionFilter) ([]*as.Record, as.Error) {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+
+	now := clnt.clock()
+
+	var parts map[int]*as.PartitionStatus
+	if pf != nil {
+		if pf.Partitions == nil {
+			pf.Partitions = make([]*as.PartitionStatus, 0, pf.Count)
+			for i := 0; i < pf.Count; i++ {
+				ps := &as.PartitionStatus{Id: pf.Begin + i, Retry: true}
+				if i == 0 && len(pf.Digest) > 0 {
+					ps.Digest = pf.Digest
+				}
+				pf.Partitions = append(pf.Partitions, ps)
+			}
+		}
+		parts = make(map[int]*as.PartitionStatus, len(pf.Partitions))
+		for _, ps := range pf.Partitions {
+			parts[ps.Id] = ps
+		}
+	}
+
+	if q.filter != nil {
+		stmt := &as.Statement{Namespace: q.namespace, SetName: q.setName, Filter: q.filter}
+		idx, err := clnt.findIndex(stmt)
+		if err != nil {
+			return nil, err
+		}
+		q.index = idx
+	}
+
+	type candidate struct {
+		pid int
+		rec *memRecord
+	}
+	var candidates []candidate
+	for digest, rec := range clnt.namespaces[q.namespace] {
+		if rec.expired(now) {
+			delete(clnt.namespaces[q.namespace], digest)
+			continue
+		}
+		if q.setName != "" && rec.setName != q.setName {
+			continue
+		}
+		pid := rec.resultKey(q.namespace).PartitionId()
+		if parts != nil {
+			ps := parts[pid]
+			if ps == nil || (len(ps.Digest) > 0 && bytes.Compare(rec.digest[:], ps.Digest) <= 0) {
+				continue
+			}
+		}
+		candidates = append(candidates, candidate{pid: pid, rec: rec})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].pid != candidates[j].pid {
+			return candidates[i].pid < candidates[j].pid
+		}
+		return bytes.Compare(candidates[i].rec.digest[:], candidates[j].rec.digest[:]) < 0
+	})
+
+	var records []*as.Record
+	done := true
+	for _, c := range candidates {
+		rec := c.rec
+		if ok, err := q.matches(rec); err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+
+		if q.policy.FilterExpression != nil {
+			ok, err := memEvalFilter(q.policy.FilterExpression, &memEvalEnv{rec: rec, bins: rec.bins, now: now})
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		if q.policy.MaxRecords > 0 && int64(len(records)) >= q.policy.MaxRecords {
+			done = false
+			break
+		}
+
+		bins := as.BinMap{}
+		if q.policy.IncludeBinData {
+			if len(q.binNames) > 0 {
+				for _, name := range q.binNames {
+					if v, exists := rec.bins[name]; exists {
+						bins[name] = memClone(v)
+					}
+				}
+			} else {
+				for name, v := range rec.bins {
+					bins[name] = memClone(v)
+				}
+			}
+			for _, name := range q.exclude {
+				delete(bins, name)
+			}
+		}
+		records = append(records, bridge.NewRecord(rec.resultKey(q.namespace), bins, rec.generation, rec.expiration(now)))
+
+		if parts != nil {
+			ps := parts[c.pid]
+			ps.Digest = append([]byte{}, rec.digest[:]...)
+		}
+	}
+
+	if pf != nil {
+		pf.Done = done
+		for _, ps := range pf.Partitions {
+			ps.Retry = !done
+		}
+	}
+
+	return records, nil
+}
+
+// ScanPartitions Read records in specified namespace, set and partition filter.
+// If partitionFilter is nil, all partitions will be scanned.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) ScanPartitions(apolicy *as.ScanPolicy, partitionFilter *as.PartitionFilter, namespace string, setName string, binNames ...string) (*as.Recordset, as.Error) {
+	policy := clnt.getUsableScanPolicy(apolicy)
+	return clnt.execute(&memQuery{policy: &policy.MultiPolicy, namespace: namespace, setName: setName, binNames: binNames}, partitionFilter)
+}
+
+// ScanAll reads all records in specified namespace and set.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) ScanAll(apolicy *as.ScanPolicy, namespace string, setName string, binNames ...string) (*as.Recordset, as.Error) {
+	return clnt.ScanPartitions(apolicy, nil, namespace, setName, binNames...)
+}
+
+// ScanNode reads all records in specified namespace and set; the node is ignored.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) ScanNode(apolicy *as.ScanPolicy, node *as.Node, namespace string, setName string, binNames ...string) (*as.Recordset, as.Error) {
+	return clnt.ScanPartitions(apolicy, nil, namespace, setName, binNames...)
+}
+
+// ScanNodePartitions reads records in specified namespace, set and partition filter; the node is ignored.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) ScanNodePartitions(apolicy *as.ScanPolicy, node *as.Node, partitionFilter *as.PartitionFilter, namespace string, setName string, binNames ...string) (*as.Recordset, as.Error) {
+	return clnt.ScanPartitions(apolicy, partitionFilter, namespace, setName, binNames...)
+}
+
+func (clnt *memoryClient) query(policy *as.QueryPolicy, statement *as.Statement, partitionFilter *as.PartitionFilter) (*as.Recordset, as.Error) {
+	policy = clnt.getUsableQueryPolicy(policy)
+	if bridge.AggregateFunction(statement) != "" {
+		return nil, memUnsupported("UDFs")
+	}
+
+	q := &memQuery{
+		policy:    &policy.MultiPolicy,
+		namespace: statement.Namespace,
+		setName:   statement.SetName,
+		binNames:  statement.BinNames,
+		exclude:   statement.ExcludeBins,
+		filter:    statement.Filter,
+	}
+	return clnt.execute(q, partitionFilter)
+}
+
+// QueryPartitions executes a query for specified partitions and returns a recordset.
+// Secondary index filters require an index created with CreateIndex or CreateComplexIndex.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) QueryPartitions(policy *as.QueryPolicy, statement *as.Statement, partitionFilter *as.PartitionFilter) (*as.Recordset, as.Error) {
+	return bridge.QuerySorted(clnt.getUsableQueryPolicy(policy), func(policy *as.QueryPolicy) (*as.Recordset, as.Error) {
+		return clnt.query(policy, statement, partitionFilter)
+	})
+}
+
+// Query executes a query and returns a Recordset.
+// Secondary index filters require an index created with CreateIndex or CreateComplexIndex.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) Query(policy *as.QueryPolicy, statement *as.Statement) (*as.Recordset, as.Error) {
+	return clnt.QueryPartitions(policy, statement, nil)
+}
+
+// QueryNode executes a query and returns a Recordset; the node is ignored.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) QueryNode(policy *as.QueryPolicy, node *as.Node, statement *as.Statement) (*as.Recordset, as.Error) {
+	return clnt.query(policy, statement, nil)
+}
+
+func (clnt *memoryClient) queryNodePartitions(policy *as.QueryPolicy, node *as.Node, statement *as.Statement) (*as.Recordset, as.Error) {
+	return clnt.query(policy, statement, nil)
+}
+
+// QueryNodePartitions executes a query for specified partitions and returns a recordset; the node is ignored.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) QueryNodePartitions(policy *as.QueryPolicy, node *as.Node, statement *as.Statement, partitionFilter *as.PartitionFilter) (*as.Recordset, as.Error) {
+	return clnt.query(policy, statement, partitionFilter)
+}
+
+// QueryExecute applies operations on records that match the statement filter.
+// The operations are applied before the method returns, and the returned task is complete.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) QueryExecute(policy *as.QueryPolicy, writePolicy *as.WritePolicy, statement *as.Statement, ops ...*as.Operation) (*as.ExecuteTask, as.Error) {
+	if len(ops) == 0 {
+		return nil, constError(as.ErrNoOperationsSpecified)
+	} else if len(statement.BinNames) > 0 {
+		return nil, constError(as.ErrNoBinNamesAllowedInQueryExecute)
+	}
+
+	policy = clnt.getUsableQueryPolicy(policy)
+	writePolicy = clnt.getUsableWritePolicy(writePolicy)
+
+	q := &memQuery{
+		policy:    &policy.MultiPolicy,
+		namespace: statement.Namespace,
+		setName:   statement.SetName,
+		filter:    statement.Filter,
+	}
+	records, err := clnt.collect(q, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rec := range records {
+		if _, err := clnt.operate(writePolicy, rec.Key, ops, false, false); err != nil && !err.Matches(types.KEY_NOT_FOUND_ERROR, types.FILTERED_OUT) {
+			return nil, err
+		}
+	}
+
+	return bridge.ExecuteTask(statement.TaskId, statement.IsScan()), nil
+}
+
+//-------------------------------------------------------
+// User defined functions
+//-------------------------------------------------------
+
+// RegisterUDFFromFile reads a file from the local path and registers the package.
+// The UDFs are listed by ListUDF, but are never run.
+// The returned task is complete.
+func (clnt *memoryClient) RegisterUDFFromFile(policy *as.WritePolicy, clientPath string, serverPath string, language as.Language) (*as.RegisterTask, as.Error) {
+	udfBody, err := os.ReadFile(clientPath)
+	if err != nil {
+		return nil, newErrorAndWrap(err, types.UDF_BAD_RESPONSE)
+	}
+	return clnt.RegisterUDF(policy, udfBody, serverPath, language)
+}
+
+// RegisterUDF registers a package containing user defined functions.
+// The UDFs are listed by ListUDF, but are never run.
+// The returned task is complete.
+func (clnt *memoryClient) RegisterUDF(policy *as.WritePolicy, udfBody []byte, serverPath string, language as.Language) (*as.RegisterTask, as.Error) {
+	hash := bridge.UDFHash(udfBody)
+
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+	clnt.udfs[serverPath] = &as.UDF{Filename: serverPath, Hash: hash, Language: language}
+
+	return bridge.RegisterTask(map[string]string{serverPath: hash}), nil
+}
+
+// RegisterUDFDir registers all the packages of the language in the local directory,
+// under their file names. The UDFs are listed by ListUDF, but are never run.
+// The returned task is complete.
+func (clnt *memoryClient) RegisterUDFDir(policy *as.WritePolicy, clientDir string, language as.Language) (*as.RegisterTask, as.Error) {
+	names, bodies, err := bridge.ReadUDFDir(clientDir, language)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(names) == 0 {
+		return nil, newError(types.PARAMETER_ERROR, "No UDF packages found in "+clientDir)
+	}
+
+	packages := make(map[string]string, len(names))
+	for _, name := range names {
+		if _, err := clnt.RegisterUDF(policy, bodies[name], name, language); err != nil {
+			return nil, err
+		}
+		packages[name] = bridge.UDFHash(bodies[name])
+	}
+	return bridge.RegisterTask(packages), nil
+}
+
+// RemoveUDF removes a package containing user defined functions.
+// The returned task is complete.
+func (clnt *memoryClient) RemoveUDF(policy *as.WritePolicy, udfName string) (*as.RemoveTask, as.Error) {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+	delete(clnt.udfs, udfName)
+
+	return bridge.RemoveTask(udfName), nil
+}
+
+// ListUDF lists all packages containing user defined functions.
+func (clnt *memoryClient) ListUDF(policy *as.BasePolicy) ([]*as.UDF, as.Error) {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+
+	res := make([]*as.UDF, 0, len(clnt.udfs))
+	for _, udf := range clnt.udfs {
+		u := *udf
+		res = append(res, &u)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Filename < res[j].Filename })
+	return res, nil
+}
+
+// Execute is not supported by the in-memory client, since it does not run UDFs.
+func (clnt *memoryClient) Execute(policy *as.WritePolicy, key *as.Key, packageName string, functionName string, args ...as.Value) (interface{}, as.Error) {
+	return nil, memUnsupported("UDFs")
+}
+
+// ExecuteUDF is not supported by the in-memory client, since it does not run UDFs.
+func (clnt *memoryClient) ExecuteUDF(policy *as.QueryPolicy, statement *as.Statement, packageName string, functionName string, functionArgs ...as.Value) (*as.ExecuteTask, as.Error) {
+	return nil, memUnsupported("UDFs")
+}
+
+// ExecuteUDFNode is not supported by the in-memory client, since it does not run UDFs.
+func (clnt *memoryClient) ExecuteUDFNode(policy *as.QueryPolicy, node *as.Node, statement *as.Statement, packageName string, functionName string, functionArgs ...as.Value) (*as.ExecuteTask, as.Error) {
+	return nil, memUnsupported("UDFs")
+}
+
+//----------------------------------------------------------
+// Secondary Indexes and Info Commands
+//----------------------------------------------------------
+
+// CreateIndex creates a secondary index.
+// The returned task is complete.
+func (clnt *memoryClient) CreateIndex(policy *as.WritePolicy, namespace string, setName string, indexName string, binName string, indexType as.IndexType) (*as.IndexTask, as.Error) {
+	return clnt.CreateComplexIndex(policy, namespace, setName, indexName, binName, indexType, as.ICT_DEFAULT)
+}
+
+// CreateComplexIndex creates a secondary index, with the ability to put indexes
+// on bin containing complex data types, e.g: Maps and Lists.
+// Indexes with a CDT context are not supported.
+// The returned task is complete.
+func (clnt *memoryClient) CreateComplexIndex(policy *as.WritePolicy, namespace string, setName string, indexName string, binName string, indexType as.IndexType, indexCollectionType as.IndexCollectionType, ctx ...*as.CDTContext) (*as.IndexTask, as.Error) {
+	if len(ctx) > 0 {
+		return nil, memUnsupported("secondary indexes with a CDT context")
+	}
+
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+
+	id := namespace + ":" + indexName
+	if _, exists := clnt.indexes[id]; exists {
+		return nil, newError(types.INDEX_FOUND)
+	}
+	clnt.indexes[id] = &memIndex{
+		namespace:      namespace,
+		setName:        setName,
+		name:           indexName,
+		binName:        binName,
+		indexType:      indexType,
+		collectionType: indexCollectionType,
+	}
+
+	return bridge.IndexTask(namespace, indexName), nil
+}
+
+// DropIndex deletes a secondary index. Dropping an index that does not exist is not an error.
+func (clnt *memoryClient) DropIndex(policy *as.WritePolicy, namespace string, setName string, indexName string) as.Error {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+	delete(clnt.indexes, namespace+":"+indexName)
+	return nil
+}
+
+// SetXDRFilter does nothing, since the in-memory client does not replicate records.
+func (clnt *memoryClient) SetXDRFilter(policy *as.InfoPolicy, datacenter string, namespace string, filter *as.Expression) as.Error {
+	return nil
+}
+
+// Truncate removes records in specified namespace/set.
+// If beforeLastUpdate is not nil, only the records last updated before that time are removed.
+// If set is empty, all the records of the namespace are removed.
+func (clnt *memoryClient) Truncate(policy *as.InfoPolicy, namespace, set string, beforeLastUpdate *time.Time) as.Error {
+	var lut time.Time
+	if beforeLastUpdate != nil {
+		lut = *beforeLastUpdate
+	}
+	clnt.truncate(namespace, set, lut, true)
+	return nil
+}
+
+// TruncateWithPolicy removes records in specified namespace/set, similar to Truncate.
+// The returned result contains the exact number of records in the set or namespace.
+func (clnt *memoryClient) TruncateWithPolicy(policy *as.TruncatePolicy, namespace, set string, beforeLastUpdate time.Time) (*as.TruncateResult, as.Error) {
+	if policy == nil {
+		policy = as.NewTruncatePolicy()
+	}
+
+	if !beforeLastUpdate.IsZero() && beforeLastUpdate.Unix() <= types.CITRUSLEAF_EPOCH {
+		return nil, newError(types.PARAMETER_ERROR, "beforeLastUpdate must be after the Citrusleaf epoch (2010-01-01)")
+	}
+
+	count := clnt.truncate(namespace, set, beforeLastUpdate, !policy.DryRun)
+	return &as.TruncateResult{EstimatedRecords: int64(count), Truncated: !policy.DryRun}, nil
+}
+
+// ListSets returns the metadata of the sets of the namespace that have records, sorted by name.
+// Only the number of records and of indexes are reported.
+func (clnt *memoryClient) ListSets(policy *as.InfoPolicy, namespace string) ([]*as.SetInfo, as.Error) {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+
+	sets := map[string]*as.SetInfo{}
+	for _, rec := range clnt.namespaces[namespace] {
+		si := sets[rec.setName]
+		if si == nil {
+			si = &as.SetInfo{Namespace: namespace, Name: rec.setName}
+			sets[rec.setName] = si
+		}
+		si.Objects++
+	}
+	delete(sets, "")
+
+	for _, idx := range clnt.indexes {
+		if si := sets[idx.setName]; si != nil && idx.namespace == namespace {
+			si.Indexes++
+		}
+	}
+
+	return bridge.SortedSetInfos(sets, 1), nil
+}
+
+// DeleteSet removes all the records of the set, and drops its indexes if policy.DropIndexes is set.
+func (clnt *memoryClient) DeleteSet(policy *as.DeleteSetPolicy, namespace, set string) as.Error {
+	if policy == nil {
+		policy = as.NewDeleteSetPolicy()
+	}
+
+	if len(set) == 0 {
+		return newError(types.PARAMETER_ERROR, "set name is required to delete a set")
+	}
+
+	clnt.truncate(namespace, set, time.Time{}, true)
+	if policy.DropIndexes {
+		if _, err := clnt.DropSetIndexes(nil, namespace, set); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DropSetIndexes drops all the secondary indexes of the set, and returns their names.
+func (clnt *memoryClient) DropSetIndexes(policy *as.WritePolicy, namespace, set string) ([]string, as.Error) {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+
+	var names []string
+	for key, idx := range clnt.indexes {
+		if idx.namespace == namespace && idx.setName == set {
+			names = append(names, idx.name)
+			delete(clnt.indexes, key)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// truncate removes the records of the namespace/set last updated before the cutoff,
+// and returns the number of records in the set.
+func (clnt *memoryClient) truncate(namespace, set string, beforeLastUpdate time.Time, remove bool) int {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+
+	count := 0
+	recs := clnt.namespaces[namespace]
+	for digest, rec := range recs {
+		if set != "" && rec.setName != set {
+			continue
+		}
+		count++
+		if remove && (beforeLastUpdate.IsZero() || rec.lastUpdate.Before(beforeLastUpdate)) {
+			delete(recs, digest)
+		}
+	}
+	return count
+}
+
+//-------------------------------------------------------
+// User administration
+//-------------------------------------------------------
+
+// CreateUser creates a new user with password and roles. Clear-text password will be hashed using bcrypt
+// before sending to server.
+func (clnt *memoryClient) CreateUser(policy *as.AdminPolicy, user string, password string, roles []string) as.Error {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+
+	if _, exists := clnt.users[user]; exists {
+		return newError(types.USER_ALREADY_EXISTS)
+	}
+	clnt.users[user] = &memUser{password: password, roles: append([]string{}, roles...)}
+	return nil
+}
+
+// DropUser removes a user from the cluster.
+func (clnt *memoryClient) DropUser(policy *as.AdminPolicy, user string) as.Error {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+
+	if _, exists := clnt.users[user]; !exists {
+		return constError(as.ErrInvalidUser)
+	}
+	delete(clnt.users, user)
+	return nil
+}
+
+// ChangePassword changes a user's password.
+func (clnt *memoryClient) ChangePassword(policy *as.AdminPolicy, user string, password string) as.Error {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+
+	u, exists := clnt.users[user]
+	if !exists {
+		return constError(as.ErrInvalidUser)
+	}
+	u.password = password
+	return nil
+}
+
+// GrantRoles adds roles to user's list of roles.
+func (clnt *memoryClient) GrantRoles(policy *as.AdminPolicy, user string, roles []string) as.Error {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+
+	u, exists := clnt.users[user]
+	if !exists {
+		return constError(as.ErrInvalidUser)
+	}
+	for _, role := range roles {
+		if memIndexOf(u.roles, role) < 0 {
+			u.roles = append(u.roles, role)
+		}
+	}
+	return nil
+}
+
+// RevokeRoles removes roles from user's list of roles.
+func (clnt *memoryClient) RevokeRoles(policy *as.AdminPolicy, user string, roles []string) as.Error {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+
+	u, exists := clnt.users[user]
+	if !exists {
+		return constError(as.ErrInvalidUser)
+	}
+	for _, role := range roles {
+		if i := memIndexOf(u.roles, role); i >= 0 {
+			u.roles = append(u.roles[:i], u.roles[i+1:]...)
+		}
+	}
+	return nil
+}
+
+func memIndexOf(l []string, s string) int {
+	for i := range l {
+		if l[i] == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// QueryUser retrieves roles for a given user.
+func (clnt *memoryClient) QueryUser(policy *as.AdminPolicy, user string) (*as.UserRoles, as.Error) {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+
+	u, exists := clnt.users[user]
+	if !exists {
+		return nil, constError(as.ErrInvalidUser)
+	}
+	return &as.UserRoles{User: user, Roles: append([]string{}, u.roles...)}, nil
+}
+
+// QueryUsers retrieves all users and their roles.
+func (clnt *memoryClient) QueryUsers(policy *as.AdminPolicy) ([]*as.UserRoles, as.Error) {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+
+	res := make([]*as.UserRoles, 0, len(clnt.users))
+	for name, u := range clnt.users {
+		res = append(res, &as.UserRoles{User: name, Roles: append([]string{}, u.roles...)})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].User < res[j].User })
+	return res, nil
+}
+
+// QueryRole retrieves privileges for a given role.
+func (clnt *memoryClient) QueryRole(policy *as.AdminPolicy, role string) (*as.Role, as.Error) {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+
+	r, exists := clnt.roles[role]
+	if !exists {
+		return nil, newError(types.INVALID_ROLE)
+	}
+	return memCopyRole(r), nil
+}
+
+// QueryRoles retrieves all roles and their privileges.
+func (clnt *memoryClient) QueryRoles(policy *as.AdminPolicy) ([]*as.Role, as.Error) {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+
+	res := make([]*as.Role, 0, len(clnt.roles))
+	for _, r := range clnt.roles {
+		res = append(res, memCopyRole(r))
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+func memCopyRole(r *as.Role) *as.Role {
+	res := *r
+	res.Privileges = append([]as.Privilege{}, r.Privileges...)
+	res.Whitelist = append([]string{}, r.Whitelist...)
+	return &res
+}
+
+// CreateRole creates a user-defined role.
+// Quotas require server security configuration "enable-quotas" to be set to true.
+// Pass 0 for quota values for no limit.
+func (clnt *memoryClient) CreateRole(policy *as.AdminPolicy, roleName string, privileges []as.Privilege, whitelist []string, readQuota, writeQuota uint32) as.Error {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+
+	if _, exists := clnt.roles[roleName]; exists {
+		return newError(types.ROLE_ALREADY_EXISTS)
+	}
+	clnt.roles[roleName] = memCopyRole(&as.Role{
+		Name:       roleName,
+		Privileges: privileges,
+		Whitelist:  whitelist,
+		ReadQuota:  readQuota,
+		WriteQuota: writeQuota,
+	})
+	return nil
+}
+
+// updateRole applies the function to the role. Must be called without holding the mutex.
+func (clnt *memoryClient) updateRole(roleName string, f func(r *as.Role)) as.Error {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+
+	r, exists := clnt.roles[roleName]
+	if !exists {
+		return newError(types.INVALID_ROLE)
+	}
+	f(r)
+	return nil
+}
+
+// DropRole removes a user-defined role.
+func (clnt *memoryClient) DropRole(policy *as.AdminPolicy, roleName string) as.Error {
+	return clnt.updateRole(roleName, func(r *as.Role) { delete(clnt.roles, roleName) })
+}
+
+// GrantPrivileges grant privileges to a user-defined role.
+func (clnt *memoryClient) GrantPrivileges(policy *as.AdminPolicy, roleName string, privileges []as.Privilege) as.Error {
+	return clnt.updateRole(roleName, func(r *as.Role) {
+		for _, p := range privileges {
+			found := false
+			for _, rp := range r.Privileges {
+				if rp == p {
+					found = true
+					break
+				}
+			}
+			if !found {
+				r.Privileges = append(r.Privileges, p)
+			}
+		}
+	})
+}
+
+// RevokePrivileges revokes privileges from a user-defined role.
+func (clnt *memoryClient) RevokePrivileges(policy *as.AdminPolicy, roleName string, privileges []as.Privilege) as.Error {
+	return clnt.updateRole(roleName, func(r *as.Role) {
+		res := r.Privileges[:0]
+		for _, rp := range r.Privileges {
+			revoked := false
+			for _, p := range privileges {
+				if rp == p {
+					revoked = true
+					break
+				}
+			}
+			if !revoked {
+				res = append(res, rp)
+			}
+		}
+		r.Privileges = res
+	})
+}
+
+// SetWhitelist sets IP address whitelist for a role.
+// If whitelist is nil or empty, it removes existing whitelist from role.
+func (clnt *memoryClient) SetWhitelist(policy *as.AdminPolicy, roleName string, whitelist []string) as.Error {
+	return clnt.updateRole(roleName, func(r *as.Role) { r.Whitelist = append([]string{}, whitelist...) })
+}
+
+// SetQuotas sets maximum reads/writes per second limits for a role.
+// If a quota is zero, the limit is removed.
+func (clnt *memoryClient) SetQuotas(policy *as.AdminPolicy, roleName string, readQuota, writeQuota uint32) as.Error {
+	return clnt.updateRole(roleName, func(r *as.Role) { r.ReadQuota, r.WriteQuota = readQuota, writeQuota })
+}
@@ -0,0 +1,26 @@
+//go:build !app_engine
+
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock
+
+import (
+	as "github.com/aerospike/aerospike-client-go/v7"
+)
+
+// QueryAggregate is not supported by the in-memory client, since it does not run UDFs.
+func (clnt *memoryClient) QueryAggregate(policy *as.QueryPolicy, statement *as.Statement, packageName, functionName string, functionArgs ...as.Value) (*as.Recordset, as.Error) {
+	return nil, memUnsupported("UDFs")
+}
@@ -0,0 +1,191 @@
+//go:build !as_performance
+
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock
+
+import (
+	"reflect"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+	"github.com/aerospike/aerospike-client-go/v7/internal/memclient"
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// memReflectBridge is implemented by the aerospike package for the reflection API.
+type memReflectBridge interface {
+	Marshal(obj interface{}) as.BinMap
+	ObjectBinNames(objType reflect.Type) []string
+	FillObject(rval reflect.Value, rec *as.Record) as.Error
+	NewObjectset(objChan interface{}) *as.Recordset
+	SendObject(rs *as.Recordset, obj reflect.Value) bool
+}
+
+var reflectBridge = memclient.Bridge.(memReflectBridge)
+
+// memFillObject sets the fields of the struct pointed to by rval from the record.
+func memFillObject(rval reflect.Value, rec *as.Record) as.Error {
+	bins := make(as.BinMap, len(rec.Bins))
+	for name, value := range rec.Bins {
+		if pairs, ok := value.([]as.MapPair); ok {
+			// the struct fields only accept unordered maps
+			value = memMapValue(pairs, false)
+		}
+		bins[name] = value
+	}
+
+	res := *rec
+	res.Bins = bins
+	return reflectBridge.FillObject(rval, &res)
+}
+
+// PutObject writes record bin(s) to the in-memory keyspace.
+// See Client.PutObject for the tags influencing the way the object is stored.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) PutObject(policy *as.WritePolicy, key *as.Key, obj interface{}) (err as.Error) {
+	return clnt.Put(policy, key, reflectBridge.Marshal(obj))
+}
+
+// GetObject reads a record for specified key and puts the result into the provided object.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) GetObject(policy *as.BasePolicy, key *as.Key, obj interface{}) as.Error {
+	rval := reflect.ValueOf(obj)
+	binNames := reflectBridge.ObjectBinNames(rval.Type())
+
+	rec, err := clnt.Get(policy, key, binNames...)
+	if err != nil {
+		return err
+	}
+	return memFillObject(rval, rec)
+}
+
+// BatchGetObjects reads multiple record headers and bins for specified keys in one batch request.
+// The returned objects are in positional order with the original key array order.
+// If a key is not found, the positional object will not change, and the positional found boolean will be false.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) BatchGetObjects(policy *as.BatchPolicy, keys []*as.Key, objects []interface{}) (found []bool, err as.Error) {
+	if len(keys) != len(objects) {
+		return nil, newError(types.PARAMETER_ERROR, "wrong number of arguments to BatchGetObjects: number of keys and objects do not match")
+	}
+
+	if len(keys) == 0 {
+		return nil, newError(types.PARAMETER_ERROR, "wrong number of arguments to BatchGetObjects: keys are empty")
+	}
+
+	found = make([]bool, len(keys))
+	for i := range keys {
+		rval := reflect.ValueOf(objects[i])
+		rec, rerr := clnt.read(&clnt.getUsableBatchPolicy(policy).BasePolicy, keys[i], memReadOps(reflectBridge.ObjectBinNames(rval.Type())))
+		if rerr != nil {
+			if rerr.Matches(types.KEY_NOT_FOUND_ERROR) {
+				continue
+			}
+			if rerr.Matches(types.FILTERED_OUT) {
+				err = constError(as.ErrFilteredOut)
+				continue
+			}
+			return nil, rerr
+		}
+
+		if ferr := memFillObject(rval, rec); ferr != nil {
+			return nil, ferr
+		}
+		found[i] = true
+	}
+
+	return found, err
+}
+
+// executeObjects collects the records of the scan or query, and marshals them into the objects
+// of the channel.
+func (clnt *memoryClient) executeObjects(q *memQuery, pf *as.PartitionFilter, objChan interface{}) (*as.Recordset, as.Error) {
+	records, err := clnt.collect(q, pf)
+	if err != nil {
+		return nil, err
+	}
+
+	res := reflectBridge.NewObjectset(objChan)
+	go func() {
+		defer bridge.SignalEnd(res)
+
+		elemType := reflect.TypeOf(objChan).Elem().Elem()
+		for _, rec := range records {
+			obj := reflect.New(elemType)
+			if err := memFillObject(obj, rec); err != nil {
+				bridge.SendError(res, err)
+				return
+			}
+			if !reflectBridge.SendObject(res, obj) {
+				return
+			}
+		}
+	}()
+	return res, nil
+}
+
+// ScanPartitionObjects Reads records in specified namespace, set and partition filter.
+// If partitionFilter is nil, all partitions will be scanned.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) ScanPartitionObjects(apolicy *as.ScanPolicy, objChan interface{}, partitionFilter *as.PartitionFilter, namespace string, setName string, binNames ...string) (*as.Recordset, as.Error) {
+	policy := clnt.getUsableScanPolicy(apolicy)
+	return clnt.executeObjects(&memQuery{policy: &policy.MultiPolicy, namespace: namespace, setName: setName, binNames: binNames}, partitionFilter, objChan)
+}
+
+// ScanAllObjects reads all records in specified namespace and set.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) ScanAllObjects(apolicy *as.ScanPolicy, objChan interface{}, namespace string, setName string, binNames ...string) (*as.Recordset, as.Error) {
+	return clnt.ScanPartitionObjects(apolicy, objChan, nil, namespace, setName, binNames...)
+}
+
+// ScanNodeObjects reads all records in specified namespace and set; the node is ignored.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) ScanNodeObjects(apolicy *as.ScanPolicy, node *as.Node, objChan interface{}, namespace string, setName string, binNames ...string) (*as.Recordset, as.Error) {
+	return clnt.ScanPartitionObjects(apolicy, objChan, nil, namespace, setName, binNames...)
+}
+
+func (clnt *memoryClient) queryObjects(policy *as.QueryPolicy, statement *as.Statement, objChan interface{}, partitionFilter *as.PartitionFilter) (*as.Recordset, as.Error) {
+	policy = clnt.getUsableQueryPolicy(policy)
+	if bridge.AggregateFunction(statement) != "" {
+		return nil, memUnsupported("UDFs")
+	}
+
+	q := &memQuery{
+		policy:    &policy.MultiPolicy,
+		namespace: statement.Namespace,
+		setName:   statement.SetName,
+		binNames:  statement.BinNames,
+		exclude:   statement.ExcludeBins,
+		filter:    statement.Filter,
+	}
+	return clnt.executeObjects(q, partitionFilter, objChan)
+}
+
+// QueryPartitionObjects executes a query for specified partitions and marshals the records into the given channel.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) QueryPartitionObjects(policy *as.QueryPolicy, statement *as.Statement, objChan interface{}, partitionFilter *as.PartitionFilter) (*as.Recordset, as.Error) {
+	return clnt.queryObjects(policy, statement, objChan, partitionFilter)
+}
+
+// QueryObjects executes a query and marshals the records into the given channel.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) QueryObjects(policy *as.QueryPolicy, statement *as.Statement, objChan interface{}) (*as.Recordset, as.Error) {
+	return clnt.queryObjects(policy, statement, objChan, nil)
+}
+
+// QueryNodeObjects executes a query and marshals the records into the given channel; the node is ignored.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *memoryClient) QueryNodeObjects(policy *as.QueryPolicy, node *as.Node, statement *as.Statement, objChan interface{}) (*as.Recordset, as.Error) {
+	return clnt.queryObjects(policy, statement, objChan, nil)
+}
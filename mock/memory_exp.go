@@ -0,0 +1,984 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"regexp"
+	"time"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+	ParticleType "github.com/aerospike/aerospike-client-go/v7/types/particle_type"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+	Buffer "github.com/aerospike/aerospike-client-go/v7/utils/buffer"
+)
+
+// The in-memory client evaluates the wire encoding of the operations and expressions,
+// so that it sees the commands exactly as the server would.
+
+// expOp is an expression operation code of the wire protocol.
+type expOp uint
+
+const (
+	expOpEQ           expOp = 1
+	expOpNE           expOp = 2
+	expOpGT           expOp = 3
+	expOpGE           expOp = 4
+	expOpLT           expOp = 5
+	expOpLE           expOp = 6
+	expOpREGEX        expOp = 7
+	expOpAND          expOp = 16
+	expOpOR           expOp = 17
+	expOpNOT          expOp = 18
+	expOpExclusive    expOp = 19
+	expOpAdd          expOp = 20
+	expOpSub          expOp = 21
+	expOpMul          expOp = 22
+	expOpDiv          expOp = 23
+	expOpPow          expOp = 24
+	expOpLog          expOp = 25
+	expOpMod          expOp = 26
+	expOpAbs          expOp = 27
+	expOpFloor        expOp = 28
+	expOpCeil         expOp = 29
+	expOpToInt        expOp = 30
+	expOpToFloat      expOp = 31
+	expOpIntAnd       expOp = 32
+	expOpIntOr        expOp = 33
+	expOpIntXor       expOp = 34
+	expOpIntNot       expOp = 35
+	expOpIntLShift    expOp = 36
+	expOpIntRShift    expOp = 37
+	expOpIntARShift   expOp = 38
+	expOpIntCount     expOp = 39
+	expOpMin          expOp = 50
+	expOpMax          expOp = 51
+	expOpLAST_UPDATE  expOp = 66
+	expOpSINCE_UPDATE expOp = 67
+	expOpVOID_TIME    expOp = 68
+	expOpTTL          expOp = 69
+	expOpSET_NAME     expOp = 70
+	expOpKEY_EXISTS   expOp = 71
+	expOpIS_TOMBSTONE expOp = 72
+	expOpKEY          expOp = 80
+	expOpBIN          expOp = 81
+	expOpBIN_TYPE     expOp = 82
+	expOpCond         expOp = 123
+	expOpVar          expOp = 124
+	expOpLet          expOp = 125
+	expOpQUOTED       expOp = 126
+	expOpCALL         expOp = 127
+)
+
+// _MODIFY is the flag of the modify CDT expressions.
+const _MODIFY = 0x40
+
+const expListMODULE int64 = 0
+
+// memExtHeader is the msgpack extension that carries the order of lists and maps.
+type memExtHeader struct {
+	bits byte
+}
+
+// memDecoder decodes the msgpack encoding of CDT operations and expressions.
+type memDecoder struct {
+	buf    []byte
+	offset int
+}
+
+func (d *memDecoder) errEOF() as.Error {
+	return newError(types.PARSE_ERROR, "unexpected end of the encoded value")
+}
+
+// arrayLen consumes an array header and returns its length.
+// ok is false, and nothing is consumed, if the next value is not an array.
+func (d *memDecoder) arrayLen() (n int, ok bool, err as.Error) {
+	if d.offset >= len(d.buf) {
+		return 0, false, d.errEOF()
+	}
+	t := d.buf[d.offset]
+	switch {
+	case t&0xf0 == 0x90:
+		d.offset++
+		return int(t & 0x0f), true, nil
+	case t == 0xdc:
+		n = int(Buffer.BytesToUint16(d.buf, d.offset+1))
+		d.offset += 3
+		return n, true, nil
+	case t == 0xdd:
+		n = int(Buffer.BytesToUint32(d.buf, d.offset+1))
+		d.offset += 5
+		return n, true, nil
+	}
+	return 0, false, nil
+}
+
+func (d *memDecoder) mapLen() (n int, ok bool) {
+	t := d.buf[d.offset]
+	switch {
+	case t&0xf0 == 0x80:
+		d.offset++
+		return int(t & 0x0f), true
+	case t == 0xde:
+		n = int(Buffer.BytesToUint16(d.buf, d.offset+1))
+		d.offset += 3
+		return n, true
+	case t == 0xdf:
+		n = int(Buffer.BytesToUint32(d.buf, d.offset+1))
+		d.offset += 5
+		return n, true
+	}
+	return 0, false
+}
+
+// rawString decodes a string without the particle type prefix of the values.
+func (d *memDecoder) rawString() (string, as.Error) {
+	if d.offset >= len(d.buf) {
+		return "", d.errEOF()
+	}
+	t := d.buf[d.offset]
+	var n int
+	switch {
+	case t&0xe0 == 0xa0:
+		n = int(t & 0x1f)
+		d.offset++
+	case t == 0xd9 || t == 0xc4:
+		n = int(d.buf[d.offset+1])
+		d.offset += 2
+	case t == 0xda || t == 0xc5:
+		n = int(Buffer.BytesToUint16(d.buf, d.offset+1))
+		d.offset += 3
+	case t == 0xdb || t == 0xc6:
+		n = int(Buffer.BytesToUint32(d.buf, d.offset+1))
+		d.offset += 5
+	default:
+		return "", newError(types.PARSE_ERROR, "expected a string")
+	}
+	if d.offset+n > len(d.buf) {
+		return "", d.errEOF()
+	}
+	s := string(d.buf[d.offset : d.offset+n])
+	d.offset += n
+	return s, nil
+}
+
+func (d *memDecoder) int() (int64, as.Error) {
+	v, err := d.value(false)
+	if err != nil {
+		return 0, err
+	}
+	i, ok := memToInt(v)
+	if !ok {
+		return 0, newError(types.PARSE_ERROR, fmt.Sprintf("expected an integer, got %T", v))
+	}
+	return i, nil
+}
+
+// value decodes the next value in the representation of the values read from the server.
+func (d *memDecoder) value(isMapKey bool) (interface{}, as.Error) {
+	if d.offset >= len(d.buf) {
+		return nil, d.errEOF()
+	}
+
+	if n, ok, err := d.arrayLen(); err != nil {
+		return nil, err
+	} else if ok {
+		res := make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			v, err := d.value(false)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := v.(memExtHeader); !ok {
+				res = append(res, v)
+			}
+		}
+		return res, nil
+	}
+
+	if n, ok := d.mapLen(); ok {
+		ordered := false
+		pairs := make([]as.MapPair, 0, n)
+		for i := 0; i < n; i++ {
+			k, err := d.value(true)
+			if err != nil {
+				return nil, err
+			}
+			v, err := d.value(false)
+			if err != nil {
+				return nil, err
+			}
+			if h, ok := k.(memExtHeader); ok {
+				ordered = h.bits&(0x01|0x04|0x08) != 0
+				continue
+			}
+			pairs = append(pairs, as.MapPair{Key: memMapKey(k), Value: v})
+		}
+		if ordered {
+			return pairs, nil
+		}
+		return memMapValue(pairs, false), nil
+	}
+
+	switch t := d.buf[d.offset]; t {
+	case 0xd4:
+		typ, data := d.buf[d.offset+1], d.buf[d.offset+2]
+		d.offset += 3
+		if typ == 0xff {
+			if data == 0x01 {
+				return memInfinity, nil
+			}
+			return memWildcard, nil
+		}
+		return memExtHeader{bits: data}, nil
+	case 0xc7, 0xc8, 0xc9, 0xd5, 0xd6, 0xd7, 0xd8:
+		var n, hdr int
+		switch t {
+		case 0xc7:
+			n, hdr = int(d.buf[d.offset+1]), 3
+		case 0xc8:
+			n, hdr = int(Buffer.BytesToUint16(d.buf, d.offset+1)), 4
+		case 0xc9:
+			n, hdr = int(Buffer.BytesToUint32(d.buf, d.offset+1)), 6
+		default:
+			n, hdr = 1<<(t-0xd4), 2
+		}
+		var bits byte
+		if n > 0 {
+			bits = d.buf[d.offset+hdr]
+		}
+		d.offset += hdr + n
+		return memExtHeader{bits: bits}, nil
+	}
+
+	v, offset, err := bridge.Unpack(d.buf, d.offset, isMapKey)
+	if err != nil {
+		return nil, err
+	}
+	d.offset = offset
+	if f, ok := v.(float32); ok {
+		return float64(f), nil
+	}
+	return v, nil
+}
+
+// memParticle converts a value to the form the client decodes from the server.
+func memParticle(v as.Value) (interface{}, as.Error) {
+	switch v := v.(type) {
+	case nil, as.NullValue:
+		return nil, nil
+	case as.GeoJSONValue:
+		return v, nil
+	case as.HLLValue:
+		return append(as.HLLValue{}, v...), nil
+	}
+
+	switch v.GetType() {
+	case ParticleType.LIST, ParticleType.MAP:
+		return memDecodeValue(v)
+	}
+
+	return bridge.Particle(v)
+}
+
+// memDecodeValue decodes the msgpack encoding of the value.
+func memDecodeValue(v as.Value) (interface{}, as.Error) {
+	b, err := bridge.PackValue(v)
+	if err != nil {
+		return nil, err
+	}
+	d := memDecoder{buf: b}
+	return d.value(false)
+}
+
+// memParticleType returns the server particle type of a value.
+func memParticleType(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return ParticleType.NULL
+	case bool:
+		return ParticleType.BOOL
+	case int, int64:
+		return ParticleType.INTEGER
+	case float64:
+		return ParticleType.FLOAT
+	case string:
+		return ParticleType.STRING
+	case []byte:
+		return ParticleType.BLOB
+	case as.HLLValue:
+		return ParticleType.HLL
+	case as.GeoJSONValue:
+		return ParticleType.GEOJSON
+	case []interface{}:
+		return ParticleType.LIST
+	case map[interface{}]interface{}, []as.MapPair:
+		return ParticleType.MAP
+	}
+	return ParticleType.BLOB
+}
+
+// memMatchesExpType reports if the value is of the expression type.
+func memMatchesExpType(v interface{}, t as.ExpType) bool {
+	switch t {
+	case as.ExpTypeNIL:
+		return v == nil
+	case as.ExpTypeBOOL:
+		_, ok := v.(bool)
+		return ok
+	case as.ExpTypeINT:
+		_, ok := memToInt(v)
+		return ok
+	case as.ExpTypeSTRING:
+		_, ok := v.(string)
+		return ok
+	case as.ExpTypeLIST:
+		_, ok := v.([]interface{})
+		return ok
+	case as.ExpTypeMAP:
+		return memIsMap(v)
+	case as.ExpTypeBLOB:
+		_, ok := v.([]byte)
+		return ok
+	case as.ExpTypeFLOAT:
+		_, ok := v.(float64)
+		return ok
+	case as.ExpTypeGEO:
+		_, ok := v.(as.GeoJSONValue)
+		return ok
+	case as.ExpTypeHLL:
+		_, ok := v.(as.HLLValue)
+		return ok
+	}
+	return false
+}
+
+// memExp is a decoded expression.
+type memExp struct {
+	// op is the expression op code, or -1 for values
+	op  int
+	val interface{}
+	// name of bins and variables, or the regular expression
+	name  string
+	typ   int64
+	flags int64
+	args  []*memExp
+	defs  []memExpDef
+	bin   *memExp
+
+	// CDT operation of module calls
+	cdtCode int
+	cdtCtx  []interface{}
+}
+
+type memExpDef struct {
+	name string
+	exp  *memExp
+}
+
+// memDecodeExpression decodes the wire encoding of the expression.
+func memDecodeExpression(exp *as.Expression) (*memExp, as.Error) {
+	b, err := bridge.PackExpression(exp)
+	if err != nil {
+		return nil, err
+	}
+	d := &memDecoder{buf: b}
+	return d.expression()
+}
+
+func (d *memDecoder) expression() (*memExp, as.Error) {
+	n, ok, err := d.arrayLen()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		v, err := d.value(false)
+		if err != nil {
+			return nil, err
+		}
+		return &memExp{op: -1, val: v}, nil
+	}
+	if n == 0 {
+		return nil, newError(types.PARSE_ERROR, "empty expression")
+	}
+
+	code, err := d.int()
+	if err != nil {
+		return nil, err
+	}
+	e := &memExp{op: int(code)}
+
+	switch expOp(code) {
+	case expOpQUOTED:
+		v, err := d.value(false)
+		if err != nil {
+			return nil, err
+		}
+		return &memExp{op: -1, val: v}, nil
+
+	case expOpBIN:
+		if e.typ, err = d.int(); err != nil {
+			return nil, err
+		}
+		e.name, err = d.rawString()
+		return e, err
+
+	case expOpBIN_TYPE, expOpVar:
+		e.name, err = d.rawString()
+		return e, err
+
+	case expOpLet:
+		for i := 0; i < (n-2)/2; i++ {
+			name, err := d.rawString()
+			if err != nil {
+				return nil, err
+			}
+			exp, err := d.expression()
+			if err != nil {
+				return nil, err
+			}
+			e.defs = append(e.defs, memExpDef{name: name, exp: exp})
+		}
+		scope, err := d.expression()
+		if err != nil {
+			return nil, err
+		}
+		e.args = []*memExp{scope}
+		return e, nil
+
+	case expOpREGEX:
+		if e.flags, err = d.int(); err != nil {
+			return nil, err
+		}
+		if e.name, err = d.rawString(); err != nil {
+			return nil, err
+		}
+		e.bin, err = d.expression()
+		return e, err
+
+	case expOpCALL:
+		if e.typ, err = d.int(); err != nil {
+			return nil, err
+		}
+		if e.flags, err = d.int(); err != nil {
+			return nil, err
+		}
+
+		m, ok, err := d.arrayLen()
+		if err != nil || !ok || m == 0 {
+			return nil, newError(types.PARSE_ERROR, "invalid module call expression")
+		}
+		first, err := d.int()
+		if err != nil {
+			return nil, err
+		}
+		if first == 0xff && m == 3 {
+			ctx, err := d.value(false)
+			if err != nil {
+				return nil, err
+			}
+			e.cdtCtx, _ = ctx.([]interface{})
+			if m, ok, err = d.arrayLen(); err != nil || !ok || m == 0 {
+				return nil, newError(types.PARSE_ERROR, "invalid module call expression")
+			}
+			if first, err = d.int(); err != nil {
+				return nil, err
+			}
+		}
+		e.cdtCode = int(first)
+		for i := 1; i < m; i++ {
+			arg, err := d.expression()
+			if err != nil {
+				return nil, err
+			}
+			e.args = append(e.args, arg)
+		}
+		e.bin, err = d.expression()
+		return e, err
+	}
+
+	for i := 1; i < n; i++ {
+		arg, err := d.expression()
+		if err != nil {
+			return nil, err
+		}
+		e.args = append(e.args, arg)
+	}
+	return e, nil
+}
+
+// memUnknownValue is the result of expressions that cannot be evaluated, like reads of missing bins.
+type memUnknownValue struct{}
+
+var memUnknown = memUnknownValue{}
+
+// memEvalEnv is the record an expression is evaluated against.
+type memEvalEnv struct {
+	rec  *memRecord
+	bins map[string]interface{}
+	now  time.Time
+	vars map[string]interface{}
+}
+
+// memEvalFilter evaluates the filter expression. Expressions that cannot be evaluated filter the record out.
+func memEvalFilter(exp *as.Expression, env *memEvalEnv) (bool, as.Error) {
+	e, err := memDecodeExpression(exp)
+	if err != nil {
+		return false, err
+	}
+	v, err := env.eval(e)
+	if err != nil {
+		return false, err
+	}
+	b, _ := v.(bool)
+	return b, nil
+}
+
+func (env *memEvalEnv) evalArgs(args []*memExp) ([]interface{}, bool, as.Error) {
+	res := make([]interface{}, len(args))
+	for i, arg := range args {
+		v, err := env.eval(arg)
+		if err != nil {
+			return nil, false, err
+		}
+		if v == memUnknown {
+			return nil, false, nil
+		}
+		res[i] = v
+	}
+	return res, true, nil
+}
+
+func (env *memEvalEnv) eval(e *memExp) (interface{}, as.Error) {
+	if e.op < 0 {
+		return e.val, nil
+	}
+
+	switch op := expOp(e.op); op {
+	case expOpBIN:
+		v, exists := env.bins[e.name]
+		if !exists || !memMatchesExpType(v, as.ExpType(e.typ)) {
+			return memUnknown, nil
+		}
+		return v, nil
+
+	case expOpBIN_TYPE:
+		v, exists := env.bins[e.name]
+		if !exists {
+			return memInt(ParticleType.NULL), nil
+		}
+		return memInt(int64(memParticleType(v))), nil
+
+	case expOpVar:
+		v, exists := env.vars[e.name]
+		if !exists {
+			return nil, newError(types.PARAMETER_ERROR, "undefined expression variable "+e.name)
+		}
+		return v, nil
+
+	case expOpLet:
+		vars := make(map[string]interface{}, len(env.vars)+len(e.defs))
+		for k, v := range env.vars {
+			vars[k] = v
+		}
+		scope := *env
+		scope.vars = vars
+		for _, def := range e.defs {
+			v, err := scope.eval(def.exp)
+			if err != nil {
+				return nil, err
+			}
+			vars[def.name] = v
+		}
+		return scope.eval(e.args[0])
+
+	case expOpCond:
+		for i := 0; i+1 < len(e.args); i += 2 {
+			c, err := env.eval(e.args[i])
+			if err != nil {
+				return nil, err
+			}
+			if c == memUnknown {
+				return memUnknown, nil
+			}
+			if b, _ := c.(bool); b {
+				return env.eval(e.args[i+1])
+			}
+		}
+		if len(e.args)%2 == 1 {
+			return env.eval(e.args[len(e.args)-1])
+		}
+		return memUnknown, nil
+
+	case expOpAND, expOpOR:
+		unknown := false
+		for _, arg := range e.args {
+			v, err := env.eval(arg)
+			if err != nil {
+				return nil, err
+			}
+			if v == memUnknown {
+				unknown = true
+				continue
+			}
+			if b, _ := v.(bool); b == (op == expOpOR) {
+				return b, nil
+			}
+		}
+		if unknown {
+			return memUnknown, nil
+		}
+		return op == expOpAND, nil
+
+	case expOpREGEX:
+		v, err := env.eval(e.bin)
+		if err != nil || v == memUnknown {
+			return v, err
+		}
+		s, ok := v.(string)
+		if !ok {
+			return memUnknown, nil
+		}
+		pattern := e.name
+		if e.flags&int64(as.ExpRegexFlagICASE) != 0 {
+			pattern = "(?i)" + pattern
+		}
+		if e.flags&int64(as.ExpRegexFlagNEWLINE) != 0 {
+			pattern = "(?m)" + pattern
+		}
+		re, rerr := regexp.Compile(pattern)
+		if rerr != nil {
+			return nil, newErrorAndWrap(rerr, types.PARAMETER_ERROR, "invalid regular expression: "+rerr.Error())
+		}
+		return re.MatchString(s), nil
+
+	case expOpCALL:
+		return env.evalCall(e)
+	}
+
+	args, ok, err := env.evalArgs(e.args)
+	if err != nil || !ok {
+		return memUnknown, err
+	}
+	return env.evalOp(expOp(e.op), args)
+}
+
+// evalOp evaluates the operators that only depend on the values of their arguments.
+func (env *memEvalEnv) evalOp(op expOp, args []interface{}) (interface{}, as.Error) {
+	rec := env.rec
+
+	switch op {
+	case expOpEQ, expOpNE:
+		if len(args) != 2 {
+			break
+		}
+		return memEqual(args[0], args[1]) == (op == expOpEQ), nil
+
+	case expOpGT, expOpGE, expOpLT, expOpLE:
+		if len(args) != 2 {
+			break
+		}
+		if memTypeOrder(args[0]) != memTypeOrder(args[1]) {
+			return memUnknown, nil
+		}
+		c := memCompare(args[0], args[1])
+		switch op {
+		case expOpGT:
+			return c > 0, nil
+		case expOpGE:
+			return c >= 0, nil
+		case expOpLT:
+			return c < 0, nil
+		}
+		return c <= 0, nil
+
+	case expOpNOT:
+		if len(args) != 1 {
+			break
+		}
+		b, _ := args[0].(bool)
+		return !b, nil
+
+	case expOpExclusive:
+		count := 0
+		for _, arg := range args {
+			if b, _ := arg.(bool); b {
+				count++
+			}
+		}
+		return count == 1, nil
+
+	case expOpKEY:
+		if rec.key == nil || len(args) != 1 {
+			return memUnknown, nil
+		}
+		typ, _ := memToInt(args[0])
+		v, err := memParticle(rec.key.Value())
+		if err != nil {
+			return nil, err
+		}
+		if !memMatchesExpType(v, as.ExpType(typ)) {
+			return memUnknown, nil
+		}
+		return v, nil
+
+	case expOpKEY_EXISTS:
+		return rec.key != nil, nil
+
+	case expOpSET_NAME:
+		return rec.setName, nil
+
+	case expOpIS_TOMBSTONE:
+		return false, nil
+
+	case expOpLAST_UPDATE:
+		return memInt(rec.lastUpdate.UnixNano()), nil
+
+	case expOpSINCE_UPDATE:
+		return memInt(env.now.Sub(rec.lastUpdate).Milliseconds()), nil
+
+	case expOpVOID_TIME:
+		if rec.voidTime.IsZero() {
+			return memInt(-1), nil
+		}
+		return memInt(rec.voidTime.UnixNano()), nil
+
+	case expOpTTL:
+		if rec.voidTime.IsZero() {
+			return memInt(-1), nil
+		}
+		return memInt(int64(rec.voidTime.Sub(env.now) / time.Second)), nil
+
+	case expOpAdd, expOpSub, expOpMul, expOpDiv, expOpMin, expOpMax:
+		return memArithmetic(op, args)
+
+	case expOpMod, expOpIntAnd, expOpIntOr, expOpIntXor, expOpIntLShift, expOpIntRShift, expOpIntARShift:
+		return memIntArithmetic(op, args)
+
+	case expOpIntNot, expOpIntCount:
+		if len(args) != 1 {
+			break
+		}
+		i, ok := memToInt(args[0])
+		if !ok {
+			return memUnknown, nil
+		}
+		if op == expOpIntNot {
+			return memInt(^i), nil
+		}
+		return memInt(int64(bits.OnesCount64(uint64(i)))), nil
+
+	case expOpAbs:
+		if len(args) != 1 {
+			break
+		}
+		switch v := args[0].(type) {
+		case float64:
+			return math.Abs(v), nil
+		default:
+			if i, ok := memToInt(v); ok {
+				if i < 0 {
+					i = -i
+				}
+				return memInt(i), nil
+			}
+		}
+		return memUnknown, nil
+
+	case expOpPow, expOpLog, expOpFloor, expOpCeil, expOpToInt:
+		fs := make([]float64, len(args))
+		for i := range args {
+			f, ok := args[i].(float64)
+			if !ok {
+				return memUnknown, nil
+			}
+			fs[i] = f
+		}
+		switch {
+		case op == expOpPow && len(fs) == 2:
+			return math.Pow(fs[0], fs[1]), nil
+		case op == expOpLog && len(fs) == 2:
+			return math.Log(fs[0]) / math.Log(fs[1]), nil
+		case op == expOpFloor && len(fs) == 1:
+			return math.Floor(fs[0]), nil
+		case op == expOpCeil && len(fs) == 1:
+			return math.Ceil(fs[0]), nil
+		case op == expOpToInt && len(fs) == 1:
+			return memInt(int64(fs[0])), nil
+		}
+
+	case expOpToFloat:
+		if len(args) != 1 {
+			break
+		}
+		i, ok := memToInt(args[0])
+		if !ok {
+			return memUnknown, nil
+		}
+		return float64(i), nil
+
+	default:
+		return nil, memUnsupported(fmt.Sprintf("expression operator %d", op))
+	}
+
+	return nil, newError(types.PARAMETER_ERROR, fmt.Sprintf("invalid number of arguments for expression operator %d", op))
+}
+
+func memArithmetic(op expOp, args []interface{}) (interface{}, as.Error) {
+	if len(args) == 0 {
+		return memUnknown, nil
+	}
+
+	if _, isFloat := args[0].(float64); isFloat {
+		acc := args[0].(float64)
+		for _, arg := range args[1:] {
+			f, ok := arg.(float64)
+			if !ok {
+				return memUnknown, nil
+			}
+			switch op {
+			case expOpAdd:
+				acc += f
+			case expOpSub:
+				acc -= f
+			case expOpMul:
+				acc *= f
+			case expOpDiv:
+				acc /= f
+			case expOpMin:
+				acc = math.Min(acc, f)
+			case expOpMax:
+				acc = math.Max(acc, f)
+			}
+		}
+		if op == expOpSub && len(args) == 1 {
+			acc = -acc
+		}
+		return acc, nil
+	}
+
+	acc, ok := memToInt(args[0])
+	if !ok {
+		return memUnknown, nil
+	}
+	for _, arg := range args[1:] {
+		i, ok := memToInt(arg)
+		if !ok {
+			return memUnknown, nil
+		}
+		switch op {
+		case expOpAdd:
+			acc += i
+		case expOpSub:
+			acc -= i
+		case expOpMul:
+			acc *= i
+		case expOpDiv:
+			if i == 0 {
+				return memUnknown, nil
+			}
+			acc /= i
+		case expOpMin:
+			if i < acc {
+				acc = i
+			}
+		case expOpMax:
+			if i > acc {
+				acc = i
+			}
+		}
+	}
+	if op == expOpSub && len(args) == 1 {
+		acc = -acc
+	}
+	return memInt(acc), nil
+}
+
+func memIntArithmetic(op expOp, args []interface{}) (interface{}, as.Error) {
+	ints := make([]int64, len(args))
+	for i := range args {
+		v, ok := memToInt(args[i])
+		if !ok {
+			return memUnknown, nil
+		}
+		ints[i] = v
+	}
+	if len(ints) == 0 {
+		return memUnknown, nil
+	}
+
+	switch op {
+	case expOpMod, expOpIntLShift, expOpIntRShift, expOpIntARShift:
+		if len(ints) != 2 {
+			return nil, newError(types.PARAMETER_ERROR, fmt.Sprintf("invalid number of arguments for expression operator %d", op))
+		}
+		a, b := ints[0], ints[1]
+		switch op {
+		case expOpMod:
+			if b == 0 {
+				return memUnknown, nil
+			}
+			return memInt(a % b), nil
+		case expOpIntLShift:
+			return memInt(a << uint64(b)), nil
+		case expOpIntRShift:
+			return memInt(int64(uint64(a) >> uint64(b))), nil
+		}
+		return memInt(a >> uint64(b)), nil
+	}
+
+	acc := ints[0]
+	for _, i := range ints[1:] {
+		switch op {
+		case expOpIntAnd:
+			acc &= i
+		case expOpIntOr:
+			acc |= i
+		case expOpIntXor:
+			acc ^= i
+		}
+	}
+	return memInt(acc), nil
+}
+
+// evalCall evaluates the list and map expressions.
+func (env *memEvalEnv) evalCall(e *memExp) (interface{}, as.Error) {
+	if e.flags&^_MODIFY != expListMODULE {
+		return nil, memUnsupported("bit and HLL expressions")
+	}
+
+	bin, err := env.eval(e.bin)
+	if err != nil || bin == memUnknown {
+		return bin, err
+	}
+
+	args, ok, err := env.evalArgs(e.args)
+	if err != nil || !ok {
+		return memUnknown, err
+	}
+
+	op := &memCDTOp{code: e.cdtCode, args: args, ctx: e.cdtCtx}
+	res, newBin, _, err := memApplyCDT(memClone(bin), op)
+	if err != nil {
+		return nil, err
+	}
+	if e.flags&_MODIFY != 0 {
+		return newBin, nil
+	}
+	return res, nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mock provides an in-memory implementation of aerospike.ClientIfc,
+// so that the tests of applications can run without an Aerospike server.
+//
+// The client keeps the records of each namespace in memory, and supports
+// record TTLs and generations, write policies, filter expressions, list and map
+// operations, secondary index queries on numeric, string and blob bins, scans,
+// and partition filters. Commands that need a server to run, like UDFs,
+// geospatial queries and bit/HLL operations, fail with an UNSUPPORTED_FEATURE error.
+//
+// The clock of the client does not move on its own; use Advance or SetTime to
+// expire records in tests.
+package mock
+
+import (
+	"sync"
+	"time"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+)
+
+// Client is an in-memory Aerospike client.
+type Client struct {
+	as.ClientIfc
+
+	engine *memoryClient
+
+	mutex sync.RWMutex
+	now   time.Time
+}
+
+// NewClient returns a new in-memory client with an empty keyspace.
+// The clock of the client is set to the current time.
+func NewClient() *Client {
+	engine := newMemoryClient()
+	clnt := &Client{
+		ClientIfc: engine,
+		engine:    engine,
+		now:       time.Now(),
+	}
+	engine.SetClock(clnt.Now)
+	return clnt
+}
+
+// Now returns the current time of the client clock.
+func (clnt *Client) Now() time.Time {
+	clnt.mutex.RLock()
+	defer clnt.mutex.RUnlock()
+	return clnt.now
+}
+
+// Advance moves the client clock forward. Records whose TTL has passed are expired.
+func (clnt *Client) Advance(d time.Duration) {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+	clnt.now = clnt.now.Add(d)
+}
+
+// SetTime sets the client clock.
+func (clnt *Client) SetTime(t time.Time) {
+	clnt.mutex.Lock()
+	defer clnt.mutex.Unlock()
+	clnt.now = t
+}
+
+// Reset removes all the records, secondary indexes, UDFs, users and roles.
+func (clnt *Client) Reset() {
+	clnt.engine.Reset()
+}
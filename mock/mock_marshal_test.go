@@ -0,0 +1,138 @@
+//go:build !as_performance
+
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock_test
+
+import (
+	"math"
+	"math/big"
+	"time"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+	"github.com/aerospike/aerospike-client-go/v7/mock"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+type marshalAddress struct {
+	Street string `as:"street"`
+	City   string
+}
+
+type marshalFriend struct {
+	Name string         `as:"name"`
+	Home marshalAddress `asm:"prefix=home_"`
+}
+
+type marshalPerson struct {
+	Name    string          `as:"name"`
+	Nick    string          `as:"nick,omitempty"`
+	Born    time.Time       `as:"born,omitzero"`
+	Home    marshalAddress  `asm:"prefix=home_"`
+	Work    marshalAddress  `asm:"prefix=work_"`
+	Friends []marshalFriend `as:"friends"`
+}
+
+type marshalNative struct {
+	At      time.Time     `as:"at"`
+	AtPtr   *time.Time    `as:"atp"`
+	Timeout time.Duration `as:"timeout"`
+	Big     big.Int       `as:"big"`
+	BigPtr  *big.Int      `as:"bigp"`
+	Max     uint64        `as:"max"`
+}
+
+type marshalMapped struct {
+	UserID     int
+	HTTPServer string
+	Alias      string `as:"AL"`
+}
+
+var _ = gg.Describe("Mock Client object marshalling", func() {
+	var clnt *mock.Client
+	var key *as.Key
+
+	gg.BeforeEach(func() {
+		clnt = mock.NewClient()
+
+		var err error
+		key, err = as.NewKey(ns, "objects", 1)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+	})
+
+	gg.It("must round trip the flattened nested structs", func() {
+		obj := &marshalPerson{
+			Name:    "Alice",
+			Home:    marshalAddress{Street: "Main", City: "Springfield"},
+			Work:    marshalAddress{Street: "Elm", City: "Shelbyville"},
+			Friends: []marshalFriend{{Name: "Bob", Home: marshalAddress{Street: "Oak", City: "Ogdenville"}}},
+		}
+
+		gm.Expect(clnt.PutObject(nil, key, obj)).ToNot(gm.HaveOccurred())
+		res := &marshalPerson{}
+		gm.Expect(clnt.GetObject(nil, key, res)).ToNot(gm.HaveOccurred())
+		gm.Expect(res).To(gm.Equal(obj))
+	})
+
+	gg.It("must round trip the field names mapped with the name mapper", func() {
+		as.SetNameMapper(as.SnakeCaseNameMapper)
+		defer as.SetNameMapper(nil)
+
+		obj := &marshalMapped{UserID: 7, HTTPServer: "srv", Alias: "a"}
+		gm.Expect(clnt.PutObject(nil, key, obj)).ToNot(gm.HaveOccurred())
+		rec, err := clnt.Get(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(rec.Bins).To(gm.HaveKey("user_id"))
+
+		res := &marshalMapped{}
+		gm.Expect(clnt.GetObject(nil, key, res)).ToNot(gm.HaveOccurred())
+		gm.Expect(res).To(gm.Equal(obj))
+	})
+
+	gg.It("must round trip the native time and integer types", func() {
+		defer as.SetValuePolicy(nil)
+
+		at := time.Date(2024, 2, 29, 12, 30, 0, 123456789, time.UTC)
+		huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+		obj := &marshalNative{At: at, AtPtr: &at, Timeout: 3 * time.Second, Big: *big.NewInt(-5), BigPtr: huge, Max: math.MaxUint64}
+
+		// the uint64 and big.Int values beyond int64 fail by default
+		gm.Expect(clnt.PutObject(nil, key, obj)).To(gm.HaveOccurred())
+
+		as.SetValuePolicy(&as.ValuePolicy{IntegerOverflow: as.IntegerOverflowString})
+		gm.Expect(clnt.PutObject(nil, key, obj)).ToNot(gm.HaveOccurred())
+		res := &marshalNative{}
+		gm.Expect(clnt.GetObject(nil, key, res)).ToNot(gm.HaveOccurred())
+		gm.Expect(res.At.Equal(at)).To(gm.BeTrue())
+		gm.Expect(res.AtPtr.Equal(at)).To(gm.BeTrue())
+		gm.Expect(res.Timeout).To(gm.Equal(3 * time.Second))
+		gm.Expect(res.Big.Int64()).To(gm.Equal(int64(-5)))
+		gm.Expect(res.BigPtr.Cmp(huge)).To(gm.Equal(0))
+		gm.Expect(res.Max).To(gm.Equal(uint64(math.MaxUint64)))
+
+		as.SetValuePolicy(&as.ValuePolicy{TimeEncoding: as.TimeAsString, IntegerOverflow: as.IntegerOverflowString})
+		obj.BigPtr = big.NewInt(1)
+		gm.Expect(clnt.PutObject(nil, key, obj)).ToNot(gm.HaveOccurred())
+		rec, err := clnt.Get(nil, key, "at")
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(rec.Bins["at"]).To(gm.Equal("2024-02-29T12:30:00.123456789Z"))
+		res = &marshalNative{}
+		gm.Expect(clnt.GetObject(nil, key, res)).ToNot(gm.HaveOccurred())
+		gm.Expect(res.At.Equal(at)).To(gm.BeTrue())
+		gm.Expect(res.Max).To(gm.Equal(uint64(math.MaxUint64)))
+	})
+})
@@ -0,0 +1,81 @@
+//go:build !as_performance
+
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock_test
+
+import (
+	as "github.com/aerospike/aerospike-client-go/v7"
+	"github.com/aerospike/aerospike-client-go/v7/mock"
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Mock Client reflection API", func() {
+	var clnt *mock.Client
+
+	gg.BeforeEach(func() {
+		clnt = mock.NewClient()
+
+		wp := as.NewWritePolicy(0, 0)
+		wp.SendKey = true
+		for i := 0; i < 100; i++ {
+			k, _ := as.NewKey(ns, "scan", i)
+			gm.Expect(clnt.Put(wp, k, as.BinMap{"i": i, "name": "n"})).ToNot(gm.HaveOccurred())
+		}
+	})
+
+	gg.It("must iterate the records as typed objects", func() {
+		type scanObj struct {
+			I    int    `as:"i"`
+			Name string `as:"name"`
+		}
+
+		it, err := as.ScanAllObjects[scanObj](clnt, nil, ns, "scan")
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		seen := map[int]bool{}
+		for it.Next() {
+			gm.Expect(it.Object().Name).To(gm.Equal("n"))
+			seen[it.Object().I] = true
+		}
+		gm.Expect(it.Err()).ToNot(gm.HaveOccurred())
+		gm.Expect(seen).To(gm.HaveLen(100))
+		gm.Expect(it.Close()).ToNot(gm.HaveOccurred())
+
+		policy := as.NewQueryPolicy()
+		policy.RecordQueueSize = 1
+		it, err = as.QueryObjects[scanObj](clnt, policy, as.NewStatement(ns, "scan"))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(it.Next()).To(gm.BeTrue())
+		gm.Expect(it.Close()).ToNot(gm.HaveOccurred())
+		gm.Expect(it.Next()).To(gm.BeFalse())
+		gm.Expect(it.Object()).To(gm.BeNil())
+
+		k1, _ := as.NewKey(ns, "scan", 1)
+		obj, err := as.GetObject[scanObj](clnt, nil, k1)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(obj).To(gm.Equal(&scanObj{I: 1, Name: "n"}))
+
+		missing, _ := as.NewKey(ns, "scan", -1)
+		_, err = as.GetObject[scanObj](clnt, nil, missing)
+		gm.Expect(err.Matches(types.KEY_NOT_FOUND_ERROR)).To(gm.BeTrue())
+
+		objs, err := as.BatchGetObjects[scanObj](clnt, nil, []*as.Key{k1, missing})
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(objs).To(gm.Equal([]*scanObj{{I: 1, Name: "n"}, nil}))
+	})
+})
@@ -0,0 +1,1258 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+	"github.com/aerospike/aerospike-client-go/v7/mock"
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+const ns = "test"
+
+var _ = gg.Describe("Mock Client", func() {
+	var clnt *mock.Client
+	var key *as.Key
+
+	gg.BeforeEach(func() {
+		clnt = mock.NewClient()
+
+		var err error
+		key, err = as.NewKey(ns, "users", "alice")
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+	})
+
+	gg.It("must implement ClientIfc", func() {
+		var _ as.ClientIfc = clnt
+		gm.Expect(clnt.IsConnected()).To(gm.BeTrue())
+		clnt.Close()
+		gm.Expect(clnt.IsConnected()).To(gm.BeFalse())
+	})
+
+	gg.Context("Record commands", func() {
+
+		gg.It("must put, get and delete records", func() {
+			err := clnt.Put(nil, key, as.BinMap{"name": "Alice", "age": 30, "score": 1.5, "tags": []interface{}{"a", "b"}, "raw": []byte{1, 2}})
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+
+			rec, err := clnt.Get(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"name": "Alice", "age": 30, "score": 1.5, "tags": []interface{}{"a", "b"}, "raw": []byte{1, 2}}))
+			gm.Expect(rec.Generation).To(gm.Equal(uint32(1)))
+
+			rec, err = clnt.Get(nil, key, "name")
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"name": "Alice"}))
+
+			exists, err := clnt.Exists(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(exists).To(gm.BeTrue())
+
+			existed, err := clnt.Delete(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(existed).To(gm.BeTrue())
+
+			_, err = clnt.Get(nil, key)
+			gm.Expect(err).To(gm.HaveOccurred())
+			gm.Expect(err.Matches(types.KEY_NOT_FOUND_ERROR)).To(gm.BeTrue())
+
+			existed, err = clnt.Delete(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(existed).To(gm.BeFalse())
+		})
+
+		gg.It("must read the replicas of a record", func() {
+			gm.Expect(clnt.Put(nil, key, as.BinMap{"name": "Alice", "age": 30})).ToNot(gm.HaveOccurred())
+
+			replicas, err := clnt.OperateReplicas(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(replicas).To(gm.HaveLen(1))
+			gm.Expect(replicas[0].Err).ToNot(gm.HaveOccurred())
+			gm.Expect(replicas[0].Record.Bins).To(gm.Equal(as.BinMap{"name": "Alice", "age": 30}))
+			gm.Expect(replicas[0].Record.Generation).To(gm.Equal(uint32(1)))
+			gm.Expect(replicas[0].LastUpdateTime.IsZero()).To(gm.BeFalse())
+
+			replicas, err = clnt.OperateReplicas(nil, key, as.GetBinOp("age"))
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(replicas[0].Record.Bins).To(gm.Equal(as.BinMap{"age": 30}))
+
+			_, err = clnt.OperateReplicas(nil, key, as.PutOp(as.NewBin("age", 31)))
+			gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+
+			missing, _ := as.NewKey(ns, "users", "nobody")
+			replicas, err = clnt.OperateReplicas(nil, missing)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(replicas[0].Err.Matches(types.KEY_NOT_FOUND_ERROR)).To(gm.BeTrue())
+		})
+
+		gg.It("must read the last update time of records", func() {
+			gm.Expect(clnt.Put(nil, key, as.BinMap{"name": "Alice"})).ToNot(gm.HaveOccurred())
+
+			rec, err := clnt.Operate(nil, key, as.GetOp(), as.LastUpdateTimeOp())
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"name": "Alice"}))
+			gm.Expect(rec.LastUpdateTime.IsZero()).To(gm.BeFalse())
+
+			records, err := clnt.BatchGetOperate(nil, []*as.Key{key}, as.LastUpdateTimeOp())
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(records[0].Bins).To(gm.BeEmpty())
+			gm.Expect(records[0].LastUpdateTime).To(gm.Equal(rec.LastUpdateTime))
+
+			rec, err = clnt.Get(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.LastUpdateTime.IsZero()).To(gm.BeTrue())
+		})
+
+		gg.It("must report tombstones of durable deletes", func() {
+			gm.Expect(clnt.Put(nil, key, as.BinMap{"a": 1})).ToNot(gm.HaveOccurred())
+
+			policy := as.NewWritePolicy(0, 0)
+			policy.DurableDelete = true
+			res, err := clnt.DeleteWithResult(policy, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(*res).To(gm.Equal(as.DeleteResult{Existed: true, Tombstone: true}))
+
+			res, err = clnt.DeleteWithResult(policy, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(*res).To(gm.Equal(as.DeleteResult{}))
+		})
+
+		gg.It("must not expose the stored values to modifications", func() {
+			tags := []interface{}{"a"}
+			gm.Expect(clnt.Put(nil, key, as.BinMap{"tags": tags})).ToNot(gm.HaveOccurred())
+			tags[0] = "changed"
+
+			rec, err := clnt.Get(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			rec.Bins["tags"].([]interface{})[0] = "changed"
+
+			rec, err = clnt.Get(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Bins["tags"]).To(gm.Equal([]interface{}{"a"}))
+		})
+
+		gg.It("must add, append and prepend to bins", func() {
+			gm.Expect(clnt.Put(nil, key, as.BinMap{"count": 1, "name": "b"})).ToNot(gm.HaveOccurred())
+			gm.Expect(clnt.Add(nil, key, as.BinMap{"count": 2})).ToNot(gm.HaveOccurred())
+			gm.Expect(clnt.Append(nil, key, as.BinMap{"name": "c"})).ToNot(gm.HaveOccurred())
+			gm.Expect(clnt.Prepend(nil, key, as.BinMap{"name": "a"})).ToNot(gm.HaveOccurred())
+
+			rec, err := clnt.Get(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"count": 3, "name": "abc"}))
+			gm.Expect(rec.Generation).To(gm.Equal(uint32(4)))
+
+			err = clnt.Add(nil, key, as.BinMap{"name": 1})
+			gm.Expect(err).To(gm.HaveOccurred())
+			gm.Expect(err.Matches(types.BIN_TYPE_ERROR)).To(gm.BeTrue())
+		})
+
+		gg.It("must delete the record when all its bins are removed", func() {
+			gm.Expect(clnt.Put(nil, key, as.BinMap{"a": 1})).ToNot(gm.HaveOccurred())
+			gm.Expect(clnt.Put(nil, key, as.BinMap{"a": nil})).ToNot(gm.HaveOccurred())
+
+			exists, err := clnt.Exists(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(exists).To(gm.BeFalse())
+		})
+
+		gg.It("must honor the record exists action", func() {
+			wp := as.NewWritePolicy(0, 0)
+
+			wp.RecordExistsAction = as.UPDATE_ONLY
+			err := clnt.Put(wp, key, as.BinMap{"a": 1})
+			gm.Expect(err.Matches(types.KEY_NOT_FOUND_ERROR)).To(gm.BeTrue())
+
+			wp.RecordExistsAction = as.CREATE_ONLY
+			gm.Expect(clnt.Put(wp, key, as.BinMap{"a": 1, "b": 2})).ToNot(gm.HaveOccurred())
+			err = clnt.Put(wp, key, as.BinMap{"a": 1})
+			gm.Expect(err.Matches(types.KEY_EXISTS_ERROR)).To(gm.BeTrue())
+
+			wp.RecordExistsAction = as.REPLACE
+			gm.Expect(clnt.Put(wp, key, as.BinMap{"c": 3})).ToNot(gm.HaveOccurred())
+
+			rec, err := clnt.Get(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"c": 3}))
+		})
+
+		gg.It("must check the generation", func() {
+			gm.Expect(clnt.Put(nil, key, as.BinMap{"a": 1})).ToNot(gm.HaveOccurred())
+
+			wp := as.NewWritePolicy(2, 0)
+			wp.GenerationPolicy = as.EXPECT_GEN_EQUAL
+			err := clnt.Put(wp, key, as.BinMap{"a": 2})
+			gm.Expect(err.Matches(types.GENERATION_ERROR)).To(gm.BeTrue())
+
+			wp.Generation = 1
+			gm.Expect(clnt.Put(wp, key, as.BinMap{"a": 2})).ToNot(gm.HaveOccurred())
+
+			rec, err := clnt.GetHeader(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Generation).To(gm.Equal(uint32(2)))
+			gm.Expect(rec.Bins).To(gm.BeEmpty())
+		})
+
+		gg.It("must expire records after their TTL", func() {
+			gm.Expect(clnt.Put(as.NewWritePolicy(0, 10), key, as.BinMap{"a": 1})).ToNot(gm.HaveOccurred())
+
+			rec, err := clnt.Get(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Expiration).To(gm.Equal(uint32(10)))
+
+			clnt.Advance(5 * time.Second)
+			gm.Expect(clnt.Touch(as.NewWritePolicy(0, 10), key)).ToNot(gm.HaveOccurred())
+
+			clnt.Advance(9 * time.Second)
+			exists, err := clnt.Exists(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(exists).To(gm.BeTrue())
+
+			clnt.Advance(time.Second)
+			exists, err = clnt.Exists(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(exists).To(gm.BeFalse())
+		})
+
+		gg.It("must reset the TTL on reads with ReadTouchTTLPercent", func() {
+			gm.Expect(clnt.Put(as.NewWritePolicy(0, 100), key, as.BinMap{"a": 1})).ToNot(gm.HaveOccurred())
+
+			policy := as.NewPolicy()
+			policy.ReadTouchTTLPercent = 80
+
+			clnt.Advance(10 * time.Second)
+			rec, err := clnt.Get(policy, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Expiration).To(gm.Equal(uint32(90)))
+			gm.Expect(rec.Generation).To(gm.Equal(uint32(1)))
+
+			clnt.Advance(15 * time.Second)
+			rec, err = clnt.Get(policy, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Expiration).To(gm.Equal(uint32(100)))
+
+			policy.ReadTouchTTLPercent = -1
+			clnt.Advance(50 * time.Second)
+			rec, err = clnt.Get(policy, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Expiration).To(gm.Equal(uint32(50)))
+		})
+
+		gg.It("must keep the TTL for TTLDontUpdate, and never expire records by default", func() {
+			gm.Expect(clnt.Put(as.NewWritePolicy(0, 10), key, as.BinMap{"a": 1})).ToNot(gm.HaveOccurred())
+			gm.Expect(clnt.Put(as.NewWritePolicy(0, as.TTLDontUpdate), key, as.BinMap{"a": 2})).ToNot(gm.HaveOccurred())
+
+			clnt.Advance(10 * time.Second)
+			exists, err := clnt.Exists(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(exists).To(gm.BeFalse())
+
+			gm.Expect(clnt.Put(nil, key, as.BinMap{"a": 1})).ToNot(gm.HaveOccurred())
+			clnt.Advance(100 * 365 * 24 * time.Hour)
+			rec, err := clnt.Get(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Expiration).To(gm.Equal(uint32(as.TTLDontExpire)))
+		})
+	})
+
+	gg.Context("Filter expressions", func() {
+
+		gg.BeforeEach(func() {
+			gm.Expect(clnt.Put(nil, key, as.BinMap{"age": 30, "name": "Alice", "tags": []interface{}{"a", "b"}})).ToNot(gm.HaveOccurred())
+		})
+
+		gg.It("must filter out records", func() {
+			policy := as.NewPolicy()
+			policy.FilterExpression = as.ExpGreater(as.ExpIntBin("age"), as.ExpIntVal(40))
+			_, err := clnt.Get(policy, key)
+			gm.Expect(err).To(gm.HaveOccurred())
+			gm.Expect(err.Matches(types.FILTERED_OUT)).To(gm.BeTrue())
+
+			policy.FilterExpression = as.ExpAnd(
+				as.ExpLessEq(as.ExpIntBin("age"), as.ExpIntVal(30)),
+				as.ExpRegexCompare("^al", as.ExpRegexFlagICASE, as.ExpStringBin("name")),
+				as.ExpEq(as.ExpListSize(as.ExpListBin("tags")), as.ExpIntVal(2)),
+			)
+			rec, err := clnt.Get(policy, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Bins["age"]).To(gm.Equal(30))
+		})
+
+		gg.It("must treat missing bins as unknown", func() {
+			policy := as.NewPolicy()
+			policy.FilterExpression = as.ExpOr(
+				as.ExpEq(as.ExpIntBin("missing"), as.ExpIntVal(1)),
+				as.ExpBinExists("name"),
+			)
+			_, err := clnt.Get(policy, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+
+			policy.FilterExpression = as.ExpNot(as.ExpEq(as.ExpIntBin("missing"), as.ExpIntVal(1)))
+			_, err = clnt.Get(policy, key)
+			gm.Expect(err.Matches(types.FILTERED_OUT)).To(gm.BeTrue())
+		})
+
+		gg.It("must evaluate let, cond and arithmetic expressions", func() {
+			policy := as.NewPolicy()
+			policy.FilterExpression = as.ExpLet(
+				as.ExpDef("x", as.ExpNumAdd(as.ExpIntBin("age"), as.ExpIntVal(5))),
+				as.ExpCond(
+					as.ExpGreater(as.ExpIntVal(10), as.ExpVar("x")), as.ExpBoolVal(false),
+					as.ExpEq(as.ExpNumMod(as.ExpVar("x"), as.ExpIntVal(7)), as.ExpIntVal(0)),
+				),
+			)
+			_, err := clnt.Get(policy, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+		})
+
+		gg.It("must read and write bins with expression operations", func() {
+			rec, err := clnt.Operate(nil, key,
+				as.ExpWriteOp("older", as.ExpNumMul(as.ExpIntBin("age"), as.ExpIntVal(2)), as.ExpWriteFlagDefault),
+				as.ExpReadOp("isAdult", as.ExpGreaterEq(as.ExpIntBin("age"), as.ExpIntVal(18)), as.ExpReadFlagDefault),
+				as.GetBinOp("older"),
+			)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"isAdult": true, "older": 60}))
+		})
+
+		gg.It("must read and write bins with expression operations in batches", func() {
+			other, err := as.NewKey(ns, "users", "other")
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(clnt.Put(nil, other, as.BinMap{"age": 10})).ToNot(gm.HaveOccurred())
+
+			double := as.ExpNumMul(as.ExpIntBin("age"), as.ExpIntVal(2))
+			read := as.NewBatchReadOps(nil, key, as.ExpReadOp("doubled", double, as.ExpReadFlagDefault))
+			write := as.NewBatchWrite(nil, other, as.ExpWriteOp("older", double, as.ExpWriteFlagCreateOnly))
+			createOnly := as.NewBatchWrite(nil, key, as.ExpWriteOp("age", double, as.ExpWriteFlagCreateOnly))
+			noFail := as.NewBatchWrite(nil, other, as.ExpWriteOp("age", double, as.ExpWriteFlagCreateOnly|as.ExpWriteFlagPolicyNoFail))
+
+			gm.Expect(clnt.BatchOperate(nil, []as.BatchRecordIfc{read, write, createOnly, noFail})).ToNot(gm.HaveOccurred())
+			gm.Expect(read.ResultCode).To(gm.Equal(types.OK))
+			gm.Expect(read.Record.Bins).To(gm.Equal(as.BinMap{"doubled": 60}))
+			gm.Expect(write.ResultCode).To(gm.Equal(types.OK))
+			gm.Expect(createOnly.ResultCode).To(gm.Equal(types.BIN_EXISTS_ERROR))
+			gm.Expect(noFail.ResultCode).To(gm.Equal(types.OK))
+
+			rec, err := clnt.Get(nil, other)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"age": 10, "older": 20}))
+		})
+	})
+
+	gg.Context("Conditional writes", func() {
+
+		gg.BeforeEach(func() {
+			gm.Expect(clnt.Put(nil, key, as.BinMap{"count": 1, "tags": []interface{}{"a"}})).ToNot(gm.HaveOccurred())
+		})
+
+		gg.It("must only write records unchanged since they were read", func() {
+			readAt := clnt.Now()
+			clnt.Advance(time.Second)
+
+			gm.Expect(clnt.Put(as.NewWritePolicy(0, 0).IfUnchangedSince(readAt), key, as.BinMap{"count": 2})).ToNot(gm.HaveOccurred())
+
+			clnt.Advance(time.Second)
+			err := clnt.Put(as.NewWritePolicy(0, 0).IfUnchangedSince(readAt), key, as.BinMap{"count": 3})
+			gm.Expect(err.Matches(types.FILTERED_OUT)).To(gm.BeTrue())
+		})
+
+		gg.It("must only write records whose bins hold the previously read values", func() {
+			policy := as.NewWritePolicy(0, 0).IfBinEquals("count", 1).IfBinEquals("tags", []interface{}{"a"}).IfBinEquals("missing", nil)
+			gm.Expect(clnt.Put(policy, key, as.BinMap{"count": 2})).ToNot(gm.HaveOccurred())
+
+			err := clnt.Put(policy, key, as.BinMap{"count": 3})
+			gm.Expect(err.Matches(types.FILTERED_OUT)).To(gm.BeTrue())
+
+			rec, err := clnt.Get(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Bins["count"]).To(gm.Equal(2))
+		})
+
+		gg.It("must only write records with the previously read generation", func() {
+			err := clnt.Put(as.NewWritePolicy(0, 0).IfGenerationEquals(2), key, as.BinMap{"count": 2})
+			gm.Expect(err.Matches(types.GENERATION_ERROR)).To(gm.BeTrue())
+
+			gm.Expect(clnt.Put(as.NewWritePolicy(0, 0).IfGenerationEquals(1), key, as.BinMap{"count": 2})).ToNot(gm.HaveOccurred())
+		})
+	})
+
+	gg.Context("CDT operations", func() {
+
+		gg.It("must return the result of each operation", func() {
+			ops := []*as.Operation{
+				as.ListAppendOp("list", 1),
+				as.ListAppendOp("list", 2),
+				as.PutOp(as.NewBin("name", "Alice")),
+				as.ListSizeOp("list"),
+				as.GetBinOp("missing"),
+			}
+			rec, results, err := clnt.OperateWithResults(nil, key, ops...)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Bins["list"]).To(gm.Equal([]interface{}{1, 2, 2}))
+
+			values := make([]interface{}, len(results))
+			for i := range results {
+				gm.Expect(results[i].Op).To(gm.BeIdenticalTo(ops[i]))
+				values[i] = results[i].Value
+			}
+			gm.Expect(values).To(gm.Equal([]interface{}{1, 2, nil, 2, nil}))
+		})
+
+		gg.It("must apply list operations", func() {
+			rec, err := clnt.Operate(nil, key,
+				as.ListAppendOp("list", 3, 1, 2),
+				as.ListInsertOp("list", 0, 0),
+				as.ListSortOp("list", as.ListSortFlagsDefault),
+				as.ListGetByIndexOp("list", -1, as.ListReturnTypeValue),
+				as.ListSizeOp("list"),
+			)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Bins["list"]).To(gm.Equal([]interface{}{3, 4, nil, 3, 4}))
+
+			rec, err = clnt.Operate(nil, key,
+				as.ListIncrementOp("list", 0, 10),
+				as.ListRemoveByValueOp("list", 2, as.ListReturnTypeCount),
+				as.ListGetByValueRangeOp("list", 1, 11, as.ListReturnTypeValue),
+			)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Bins["list"]).To(gm.Equal([]interface{}{10, 1, []interface{}{10, 1, 3}}))
+		})
+
+		gg.It("must apply map operations", func() {
+			policy := as.NewMapPolicy(as.MapOrder.KEY_ORDERED, as.MapWriteMode.UPDATE)
+			rec, err := clnt.Operate(nil, key,
+				as.MapPutItemsOp(policy, "map", map[interface{}]interface{}{"b": 2, "a": 1, "c": 3}),
+				as.MapIncrementOp(policy, "map", "a", 10),
+				as.MapGetByKeyOp("map", "a", as.MapReturnType.VALUE),
+				as.MapRemoveByKeyOp("map", "b", as.MapReturnType.VALUE),
+				as.MapGetByRankRangeOp("map", 0, as.MapReturnType.KEY),
+			)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Bins["map"]).To(gm.Equal([]interface{}{3, 11, 11, 2, []interface{}{"c", "a"}}))
+
+			rec, err = clnt.Get(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Bins["map"]).To(gm.Equal([]as.MapPair{{Key: "a", Value: 11}, {Key: "c", Value: 3}}))
+		})
+
+		gg.It("must apply operations on nested CDTs", func() {
+			gm.Expect(clnt.Put(nil, key, as.BinMap{"m": map[interface{}]interface{}{"l": []interface{}{1, 2}}})).ToNot(gm.HaveOccurred())
+
+			rec, err := clnt.Operate(nil, key,
+				as.ListAppendWithPolicyContextOp(as.DefaultListPolicy(), "m", []*as.CDTContext{as.CtxMapKey(as.NewValue("l"))}, 3),
+				as.GetBinOp("m"),
+			)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Bins["m"]).To(gm.Equal([]interface{}{3, map[interface{}]interface{}{"l": []interface{}{1, 2, 3}}}))
+		})
+
+		gg.It("must evaluate CDT expressions", func() {
+			gm.Expect(clnt.Put(nil, key, as.BinMap{"tags": []interface{}{"a", "b", "c"}})).ToNot(gm.HaveOccurred())
+
+			policy := as.NewPolicy()
+			policy.FilterExpression = as.ExpEq(
+				as.ExpListGetByValue(as.ListReturnTypeCount, as.ExpStringVal("b"), as.ExpListBin("tags")),
+				as.ExpIntVal(1),
+			)
+			_, err := clnt.Get(policy, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+		})
+	})
+
+	gg.Context("Batch commands", func() {
+
+		gg.It("must read and write batches", func() {
+			keys := make([]*as.Key, 3)
+			for i := range keys {
+				keys[i], _ = as.NewKey(ns, "batch", i)
+			}
+			gm.Expect(clnt.Put(nil, keys[0], as.BinMap{"a": 0})).ToNot(gm.HaveOccurred())
+
+			records, err := clnt.BatchGet(nil, keys)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(records[0].Bins).To(gm.Equal(as.BinMap{"a": 0}))
+			gm.Expect(records[1]).To(gm.BeNil())
+
+			batch := []as.BatchRecordIfc{
+				as.NewBatchWrite(nil, keys[1], as.PutOp(as.NewBin("a", 1))),
+				as.NewBatchRead(nil, keys[0], nil),
+				as.NewBatchDelete(nil, keys[2]),
+			}
+			gm.Expect(clnt.BatchOperate(nil, batch)).ToNot(gm.HaveOccurred())
+			gm.Expect(batch[0].BatchRec().ResultCode).To(gm.Equal(types.OK))
+			gm.Expect(batch[1].BatchRec().Record.Bins).To(gm.Equal(as.BinMap{"a": 0}))
+			gm.Expect(batch[2].BatchRec().ResultCode).To(gm.Equal(types.KEY_NOT_FOUND_ERROR))
+
+			exists, err := clnt.BatchExists(nil, keys)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(exists).To(gm.Equal([]bool{true, true, false}))
+
+			streamed := []bool{}
+			err = clnt.BatchExistsFunc(nil, keys, 2, func(index int, exists bool) bool {
+				gm.Expect(index).To(gm.Equal(len(streamed)))
+				streamed = append(streamed, exists)
+				return true
+			})
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(streamed).To(gm.Equal([]bool{true, true, false}))
+
+			digests, err := clnt.BatchGetDigest(nil, keys)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(digests).To(gm.Equal([][]byte{keys[0].Digest(), keys[1].Digest()}))
+
+			policy := as.NewBatchPolicy()
+			policy.FilterExpression = as.ExpEq(as.ExpIntBin("a"), as.ExpIntVal(1))
+			digests, err = clnt.BatchGetDigest(policy, keys)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(digests).To(gm.Equal([][]byte{keys[1].Digest()}))
+		})
+
+		gg.It("must check the generation of each record in batch writes", func() {
+			keys := make([]*as.Key, 2)
+			for i := range keys {
+				keys[i], _ = as.NewKey(ns, "batch", i)
+				gm.Expect(clnt.Put(nil, keys[i], as.BinMap{"a": i})).ToNot(gm.HaveOccurred())
+			}
+
+			batch := []as.BatchRecordIfc{
+				as.NewBatchWriteGeneration(nil, keys[0], 1, as.AddOp(as.NewBin("a", 1))),
+				as.NewBatchWriteGeneration(nil, keys[1], 2, as.AddOp(as.NewBin("a", 1))),
+			}
+			gm.Expect(clnt.BatchOperate(nil, batch)).ToNot(gm.HaveOccurred())
+			gm.Expect(batch[0].BatchRec().ResultCode).To(gm.Equal(types.OK))
+			gm.Expect(batch[0].BatchRec().Record.Generation).To(gm.Equal(uint32(2)))
+			gm.Expect(batch[1].BatchRec().ResultCode).To(gm.Equal(types.GENERATION_ERROR))
+
+			rec, err := clnt.Get(nil, keys[1])
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"a": 1}))
+		})
+	})
+
+	gg.Context("Scans and queries", func() {
+
+		gg.BeforeEach(func() {
+			wp := as.NewWritePolicy(0, 0)
+			wp.SendKey = true
+			for i := 0; i < 100; i++ {
+				k, _ := as.NewKey(ns, "scan", i)
+				gm.Expect(clnt.Put(wp, k, as.BinMap{"i": i, "name": "n", "tags": []interface{}{i % 3}})).ToNot(gm.HaveOccurred())
+			}
+			gm.Expect(clnt.Put(nil, key, as.BinMap{"i": 1000})).ToNot(gm.HaveOccurred())
+		})
+
+		gg.It("must scan the records of a set", func() {
+			rs, err := clnt.ScanAll(nil, ns, "scan", "i")
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+
+			seen := map[int]bool{}
+			for res := range rs.Results() {
+				gm.Expect(res.Err).ToNot(gm.HaveOccurred())
+				gm.Expect(res.Record.Bins).To(gm.HaveLen(1))
+				gm.Expect(res.Record.Key.Value().GetObject()).To(gm.Equal(res.Record.Bins["i"]))
+				seen[res.Record.Bins["i"].(int)] = true
+			}
+			gm.Expect(seen).To(gm.HaveLen(100))
+		})
+
+		gg.It("must paginate scans with partition filters", func() {
+			policy := as.NewScanPolicy()
+			policy.MaxRecords = 30
+			pf := as.NewPartitionFilterAll()
+
+			total := 0
+			for !pf.IsDone() {
+				rs, err := clnt.ScanPartitions(policy, pf, ns, "scan")
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				count := 0
+				for res := range rs.Results() {
+					gm.Expect(res.Err).ToNot(gm.HaveOccurred())
+					count++
+				}
+				gm.Expect(count).To(gm.BeNumerically("<=", 30))
+				total += count
+			}
+			gm.Expect(total).To(gm.Equal(100))
+		})
+
+		gg.It("must query secondary indexes", func() {
+			stmt := as.NewStatement(ns, "scan")
+			stmt.Filter = as.NewRangeFilter("i", 10, 19)
+			_, err := clnt.Query(nil, stmt)
+			gm.Expect(err.Matches(types.INDEX_NOTFOUND)).To(gm.BeTrue())
+
+			task, err := clnt.CreateIndex(nil, ns, "scan", "idx_i", "i", as.NUMERIC)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(<-task.OnComplete()).ToNot(gm.HaveOccurred())
+
+			policy := as.NewQueryPolicy()
+			policy.FilterExpression = as.ExpEq(as.ExpNumMod(as.ExpIntBin("i"), as.ExpIntVal(2)), as.ExpIntVal(0))
+			rs, err := clnt.Query(policy, stmt)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			count := 0
+			for res := range rs.Results() {
+				gm.Expect(res.Err).ToNot(gm.HaveOccurred())
+				gm.Expect(res.Record.Bins["i"]).To(gm.BeNumerically(">=", 10))
+				count++
+			}
+			gm.Expect(count).To(gm.Equal(5))
+
+			_, err = clnt.CreateComplexIndex(nil, ns, "scan", "idx_tags", "tags", as.NUMERIC, as.ICT_LIST)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			stmt.Filter = as.NewContainsFilter("tags", as.ICT_LIST, 0)
+			rs, err = clnt.Query(nil, stmt)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			count = 0
+			for range rs.Results() {
+				count++
+			}
+			gm.Expect(count).To(gm.Equal(34))
+		})
+
+		gg.It("must leave the excluded bins out of the query results", func() {
+			stmt := as.NewStatement(ns, "scan")
+			stmt.ExcludeBins = []string{"tags"}
+			rs, err := clnt.Query(nil, stmt)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			count := 0
+			for res := range rs.Results() {
+				gm.Expect(res.Err).ToNot(gm.HaveOccurred())
+				gm.Expect(res.Record.Bins).To(gm.HaveLen(2))
+				gm.Expect(res.Record.Bins).ToNot(gm.HaveKey("tags"))
+				count++
+			}
+			gm.Expect(count).To(gm.Equal(100))
+		})
+
+		gg.It("must execute prepared queries with different filter values", func() {
+			task, err := clnt.CreateIndex(nil, ns, "scan", "idx_i", "i", as.NUMERIC)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(<-task.OnComplete()).ToNot(gm.HaveOccurred())
+
+			stmt := as.NewStatement(ns, "scan", "i")
+			stmt.Filter = as.NewRangeFilter("i", 0, 0)
+			pq, err := as.NewPreparedQuery(stmt)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+
+			count := func(values ...interface{}) int {
+				rs, err := pq.Query(clnt, nil, values...)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				n := 0
+				for res := range rs.Results() {
+					gm.Expect(res.Err).ToNot(gm.HaveOccurred())
+					gm.Expect(res.Record.Bins).To(gm.HaveLen(1))
+					n++
+				}
+				return n
+			}
+			gm.Expect(count(10, 19)).To(gm.Equal(10))
+			gm.Expect(count(42)).To(gm.Equal(1))
+			gm.Expect(count(95, 200)).To(gm.Equal(5))
+
+			_, err = pq.Query(clnt, nil, "not an int")
+			gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+		})
+
+		gg.It("must sort the query results client-side", func() {
+			stmt := as.NewStatement(ns, "scan")
+			policy := as.NewQueryPolicy().SortBy("i", as.SortDescending)
+			policy.MaxRecords = 5
+			rs, err := clnt.Query(policy, stmt)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+
+			values := []interface{}{}
+			for res := range rs.Results() {
+				gm.Expect(res.Err).ToNot(gm.HaveOccurred())
+				values = append(values, res.Record.Bins["i"])
+			}
+			gm.Expect(values).To(gm.Equal([]interface{}{99, 98, 97, 96, 95}))
+			gm.Expect(policy.MaxRecords).To(gm.Equal(int64(5)))
+
+			policy = as.NewQueryPolicy().SortBy("tags", as.SortAscending).SortBy("i", as.SortDescending)
+			policy.MaxRecords = 3
+			policy.FilterExpression = as.ExpLess(as.ExpIntBin("i"), as.ExpIntVal(10))
+			rs, err = clnt.Query(policy, stmt)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+
+			values = values[:0]
+			for res := range rs.Results() {
+				gm.Expect(res.Err).ToNot(gm.HaveOccurred())
+				values = append(values, res.Record.Bins["i"])
+			}
+			gm.Expect(values).To(gm.Equal([]interface{}{9, 6, 3}))
+		})
+
+		gg.It("must aggregate the records client-side", func() {
+			rs, err := clnt.ScanAll(nil, ns, "scan")
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			sum, err := rs.Reduce(0, func(acc interface{}, rec *as.Record) (interface{}, as.Error) {
+				return acc.(int) + rec.Bins["i"].(int), nil
+			})
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(sum).To(gm.Equal(4950))
+
+			rs, err = clnt.ScanAll(nil, ns, "scan")
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			groups, err := rs.GroupBy("name", 0)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(groups).To(gm.HaveLen(1))
+			gm.Expect(groups["n"]).To(gm.HaveLen(100))
+
+			rs, err = clnt.ScanAll(nil, ns, "scan")
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			count, err := rs.CountDistinct("i", 0)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(count).To(gm.Equal(100))
+
+			rs, err = clnt.ScanAll(nil, ns, "scan")
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			_, err = rs.CountDistinct("i", 10)
+			gm.Expect(err.Matches(types.COMMON_ERROR)).To(gm.BeTrue())
+			gm.Expect(rs.IsActive()).To(gm.BeFalse())
+
+			rs, err = clnt.ScanAll(nil, ns, "scan")
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			_, err = rs.GroupBy("tags", 0)
+			gm.Expect(err.Matches(types.TYPE_NOT_SUPPORTED)).To(gm.BeTrue())
+		})
+
+		gg.It("must truncate sets", func() {
+			gm.Expect(clnt.Truncate(nil, ns, "scan", nil)).ToNot(gm.HaveOccurred())
+
+			rs, err := clnt.ScanAll(nil, ns, "")
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			count := 0
+			for range rs.Results() {
+				count++
+			}
+			gm.Expect(count).To(gm.Equal(1))
+		})
+
+		gg.It("must list and delete sets", func() {
+			_, err := clnt.CreateIndex(nil, ns, "scan", "idx_scan_i", "i", as.NUMERIC)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			_, err = clnt.CreateIndex(nil, ns, "other", "idx_other_i", "i", as.NUMERIC)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+
+			sets, err := clnt.ListSets(nil, ns)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(sets).To(gm.HaveLen(2))
+			gm.Expect(sets[0].Name).To(gm.Equal("scan"))
+			gm.Expect(sets[0].Objects).To(gm.Equal(int64(100)))
+			gm.Expect(sets[0].Indexes).To(gm.Equal(1))
+
+			gm.Expect(clnt.DeleteSet(nil, ns, "")).ToNot(gm.Succeed())
+
+			policy := as.NewDeleteSetPolicy()
+			policy.DropIndexes = true
+			gm.Expect(clnt.DeleteSet(policy, ns, "scan")).To(gm.Succeed())
+
+			sets, err = clnt.ListSets(nil, ns)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(sets).To(gm.HaveLen(1))
+			gm.Expect(sets[0].Name).ToNot(gm.Equal("scan"))
+
+			names, err := clnt.DropSetIndexes(nil, ns, "other")
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(names).To(gm.Equal([]string{"idx_other_i"}))
+			names, err = clnt.DropSetIndexes(nil, ns, "scan")
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(names).To(gm.BeEmpty())
+		})
+	})
+
+	gg.It("must reset the keyspace", func() {
+		gm.Expect(clnt.Put(nil, key, as.BinMap{"a": 1})).ToNot(gm.HaveOccurred())
+		clnt.Reset()
+
+		exists, err := clnt.Exists(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(exists).To(gm.BeFalse())
+	})
+
+	gg.It("must manipulate JSON documents", func() {
+		doc := map[string]interface{}{
+			"store": map[string]interface{}{
+				"book": []interface{}{
+					map[string]interface{}{"title": "Go", "price": 10},
+				},
+			},
+		}
+		gm.Expect(clnt.DocSet(nil, key, "doc", "$", doc)).ToNot(gm.HaveOccurred())
+
+		v, err := clnt.DocGet(nil, key, "doc", "$.store.book[0].title")
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(v).To(gm.Equal("Go"))
+
+		gm.Expect(clnt.DocSet(nil, key, "doc", "$.store.book[-1].price", 12)).ToNot(gm.HaveOccurred())
+		gm.Expect(clnt.DocSet(nil, key, "doc", "$.store.owner", "Bob")).ToNot(gm.HaveOccurred())
+		gm.Expect(clnt.DocAppend(nil, key, "doc", "$.store.book", map[string]interface{}{"title": "Lua"})).ToNot(gm.HaveOccurred())
+		gm.Expect(clnt.DocDelete(nil, key, "doc", "$['store'].book[0].title")).ToNot(gm.HaveOccurred())
+
+		v, err = clnt.DocGet(nil, key, "doc", "$.store")
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(v).To(gm.Equal(map[interface{}]interface{}{
+			"owner": "Bob",
+			"book": []interface{}{
+				map[interface{}]interface{}{"price": 12},
+				map[interface{}]interface{}{"title": "Lua"},
+			},
+		}))
+
+		gm.Expect(clnt.DocDelete(nil, key, "doc", "$.store.book[1]")).ToNot(gm.HaveOccurred())
+		v, err = clnt.DocGet(nil, key, "doc", "$.store.book")
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(v).To(gm.HaveLen(1))
+
+		_, err = clnt.DocGet(nil, key, "doc", "$.store.*")
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+
+		gm.Expect(clnt.DocDelete(nil, key, "doc", "$")).ToNot(gm.HaveOccurred())
+		exists, err := clnt.Exists(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(exists).To(gm.BeFalse())
+	})
+
+	gg.It("must decompress compressed values transparently", func() {
+		doc := strings.Repeat(`{"name":"Alice"},`, 200)
+		gm.Expect(clnt.Put(nil, key, as.BinMap{"doc": as.NewCompressedValue(nil, doc), "small": as.NewCompressedValue(nil, "a")})).ToNot(gm.HaveOccurred())
+
+		rec, err := clnt.Get(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"doc": doc, "small": "a"}))
+	})
+
+	gg.It("must register directories of UDFs", func() {
+		dir := gg.GinkgoT().TempDir()
+		gm.Expect(os.WriteFile(filepath.Join(dir, "a.lua"), []byte("function a(rec) end"), 0644)).ToNot(gm.HaveOccurred())
+		gm.Expect(os.WriteFile(filepath.Join(dir, "b.lua"), []byte("function b(rec) end"), 0644)).ToNot(gm.HaveOccurred())
+		gm.Expect(os.WriteFile(filepath.Join(dir, "c.txt"), []byte("text"), 0644)).ToNot(gm.HaveOccurred())
+
+		task, err := clnt.RegisterUDFDir(nil, dir, as.LUA)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(<-task.OnComplete()).ToNot(gm.HaveOccurred())
+
+		udfs, err := clnt.ListUDF(nil)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(udfs).To(gm.HaveLen(2))
+		gm.Expect(udfs[0].Filename).To(gm.Equal("a.lua"))
+		gm.Expect(udfs[1].Hash).To(gm.HaveLen(40))
+
+		_, err = clnt.RegisterUDFDir(nil, filepath.Join(dir, "missing"), as.LUA)
+		gm.Expect(err).To(gm.HaveOccurred())
+	})
+
+	gg.It("must report unsupported commands", func() {
+		_, err := clnt.Execute(nil, key, "pkg", "fn")
+		gm.Expect(err.Matches(types.UNSUPPORTED_FEATURE)).To(gm.BeTrue())
+	})
+})
+
+// flakyClient fails the record commands with err while it is set.
+type flakyClient struct {
+	*mock.Client
+
+	err   as.Error
+	calls int
+}
+
+func (clnt *flakyClient) Get(policy *as.BasePolicy, key *as.Key, binNames ...string) (*as.Record, as.Error) {
+	clnt.calls++
+	if clnt.err != nil {
+		return nil, clnt.err
+	}
+	return clnt.Client.Get(policy, key, binNames...)
+}
+
+func (clnt *flakyClient) Put(policy *as.WritePolicy, key *as.Key, binMap as.BinMap) as.Error {
+	clnt.calls++
+	if clnt.err != nil {
+		return clnt.err
+	}
+	return clnt.Client.Put(policy, key, binMap)
+}
+
+var _ = gg.Describe("Multi-cluster client", func() {
+	var east, west *flakyClient
+	var key *as.Key
+
+	newMultiClusterClient := func(policy *as.MultiClusterPolicy) *as.MultiClusterClient {
+		mc, err := as.NewMultiClusterClient(policy,
+			&as.MultiClusterMember{Name: "east", Client: east},
+			&as.MultiClusterMember{Name: "west", Client: west},
+		)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		return mc
+	}
+
+	gg.BeforeEach(func() {
+		east = &flakyClient{Client: mock.NewClient()}
+		west = &flakyClient{Client: mock.NewClient()}
+
+		var err error
+		key, err = as.NewKey(ns, "users", "alice")
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		gm.Expect(east.Client.Put(nil, key, as.BinMap{"from": "east"})).ToNot(gm.HaveOccurred())
+		gm.Expect(west.Client.Put(nil, key, as.BinMap{"from": "west"})).ToNot(gm.HaveOccurred())
+	})
+
+	gg.It("must validate the members", func() {
+		_, err := as.NewMultiClusterClient(nil)
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+
+		_, err = as.NewMultiClusterClient(nil, &as.MultiClusterMember{Client: east})
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+
+		_, err = as.NewMultiClusterClient(nil, &as.MultiClusterMember{Name: "east", Client: east}, &as.MultiClusterMember{Name: "east", Client: west})
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+
+		_, err = as.NewMultiClusterClient(nil, &as.MultiClusterMember{Name: "east"})
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+
+		policy := as.NewClientPolicy()
+		policy.ClusterName = "west"
+		_, err = as.NewMultiClusterClient(nil, &as.MultiClusterMember{Name: "east", Policy: policy, Hosts: []*as.Host{as.NewHost("10.0.0.1", 3000)}})
+		gm.Expect(err.Matches(types.CLUSTER_NAME_MISMATCH_ERROR)).To(gm.BeTrue())
+
+		_, err = as.NewMultiClusterClient(nil,
+			&as.MultiClusterMember{Name: "east", Hosts: []*as.Host{as.NewHost("10.0.0.1", 3000)}},
+			&as.MultiClusterMember{Name: "west", Hosts: []*as.Host{as.NewHost("10.0.0.2", 3000), as.NewHost("10.0.0.1", 3000)}},
+		)
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+	})
+
+	gg.It("must expose the clusters", func() {
+		mc := newMultiClusterClient(nil)
+		gm.Expect(mc.Clusters()).To(gm.Equal([]string{"east", "west"}))
+		gm.Expect(mc.HealthyClusters()).To(gm.Equal([]string{"east", "west"}))
+		gm.Expect(mc.Client("west")).To(gm.BeIdenticalTo(west))
+		gm.Expect(mc.Client("north")).To(gm.BeNil())
+		gm.Expect(mc.IsConnected()).To(gm.BeTrue())
+
+		// the clients passed to the multi-cluster client are not closed
+		mc.Close()
+		gm.Expect(east.IsConnected()).To(gm.BeTrue())
+	})
+
+	gg.It("must read from the preferred cluster and skip the disconnected ones", func() {
+		mc := newMultiClusterClient(nil)
+
+		rec, err := mc.Get(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(rec.Bins["from"]).To(gm.Equal("east"))
+
+		east.Close()
+		gm.Expect(mc.HealthyClusters()).To(gm.Equal([]string{"west"}))
+
+		rec, err = mc.Get(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(rec.Bins["from"]).To(gm.Equal("west"))
+	})
+
+	gg.It("must spread the reads in round robin", func() {
+		policy := as.NewMultiClusterPolicy()
+		policy.ReadPreference = as.ReadRoundRobin
+		mc := newMultiClusterClient(policy)
+
+		seen := map[interface{}]int{}
+		for i := 0; i < 4; i++ {
+			rec, err := mc.Get(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			seen[rec.Bins["from"]]++
+		}
+		gm.Expect(seen).To(gm.Equal(map[interface{}]int{"east": 2, "west": 2}))
+	})
+
+	gg.It("must fail over according to the policy", func() {
+		east.err = as.ErrTimeout
+
+		mc := newMultiClusterClient(nil)
+		rec, err := mc.Get(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(rec.Bins["from"]).To(gm.Equal("west"))
+
+		policy := as.NewMultiClusterPolicy()
+		policy.Failover = as.FailoverNever
+		mc = newMultiClusterClient(policy)
+		_, err = mc.Get(nil, key)
+		gm.Expect(err.Matches(types.TIMEOUT)).To(gm.BeTrue())
+
+		// results of the command itself do not fail over
+		east.err = nil
+		missing, _ := as.NewKey(ns, "users", "bob")
+		policy.Failover = as.FailoverOnAnyError
+		mc = newMultiClusterClient(policy)
+		west.calls = 0
+		_, err = mc.Get(nil, missing)
+		gm.Expect(err.Matches(types.KEY_NOT_FOUND_ERROR)).To(gm.BeTrue())
+		gm.Expect(west.calls).To(gm.Equal(0))
+	})
+
+	gg.It("must mark the clusters unhealthy after consecutive failures", func() {
+		east.err = as.ErrNetwork
+
+		policy := as.NewMultiClusterPolicy()
+		policy.MaxFailures = 2
+		policy.UnhealthyDuration = time.Hour
+		mc := newMultiClusterClient(policy)
+
+		for i := 0; i < 2; i++ {
+			_, err := mc.Get(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+		}
+		gm.Expect(east.calls).To(gm.Equal(2))
+		gm.Expect(mc.HealthyClusters()).To(gm.Equal([]string{"west"}))
+
+		_, err := mc.Get(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(east.calls).To(gm.Equal(2))
+
+		// unhealthy clusters are still used if none of the clusters is healthy
+		west.Close()
+		east.err = nil
+		rec, err := mc.Get(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(rec.Bins["from"]).To(gm.Equal("east"))
+	})
+
+	gg.It("must write to the first healthy cluster", func() {
+		mc := newMultiClusterClient(nil)
+		gm.Expect(mc.Put(nil, key, as.BinMap{"from": "multi"})).ToNot(gm.HaveOccurred())
+
+		rec, err := east.Get(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(rec.Bins["from"]).To(gm.Equal("multi"))
+
+		rec, err = west.Get(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(rec.Bins["from"]).To(gm.Equal("west"))
+	})
+
+	gg.It("must duplicate the writes", func() {
+		policy := as.NewMultiClusterPolicy()
+		policy.DuplicateWrites = true
+		mc := newMultiClusterClient(policy)
+
+		gm.Expect(mc.Put(nil, key, as.BinMap{"from": "multi"})).ToNot(gm.HaveOccurred())
+		for _, clnt := range []*flakyClient{east, west} {
+			rec, err := clnt.Get(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Bins["from"]).To(gm.Equal("multi"))
+		}
+
+		existed, err := mc.Delete(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(existed).To(gm.BeTrue())
+		exists, err := west.Exists(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(exists).To(gm.BeFalse())
+
+		// the errors of all the clusters are reported
+		west.err = as.ErrTimeout
+		err = mc.Put(nil, key, as.BinMap{"from": "again"})
+		gm.Expect(err.Matches(types.TIMEOUT)).To(gm.BeTrue())
+		rec, err := east.Get(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(rec.Bins["from"]).To(gm.Equal("again"))
+	})
+
+	gg.It("must run custom commands", func() {
+		mc := newMultiClusterClient(nil)
+
+		var clusters []string
+		err := mc.ExecuteWrite(func(cluster string, clnt as.ClientIfc) as.Error {
+			clusters = append(clusters, cluster)
+			return clnt.Touch(nil, key)
+		})
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(clusters).To(gm.Equal([]string{"east"}))
+
+		err = mc.ExecuteRead(func(cluster string, clnt as.ClientIfc) as.Error {
+			clusters = append(clusters, cluster)
+			return as.ErrTimeout
+		})
+		gm.Expect(err.Matches(types.TIMEOUT)).To(gm.BeTrue())
+		gm.Expect(clusters).To(gm.Equal([]string{"east", "east", "west"}))
+	})
+})
+
+var _ = gg.Describe("Migration client", func() {
+	var source *mock.Client
+	var target *flakyClient
+	var key *as.Key
+
+	gg.BeforeEach(func() {
+		source = mock.NewClient()
+		target = &flakyClient{Client: mock.NewClient()}
+
+		var err error
+		key, err = as.NewKey(ns, "users", "alice")
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+	})
+
+	gg.It("must implement ClientIfc", func() {
+		var mc as.ClientIfc = as.NewMigrationClient(nil, source, target)
+		gm.Expect(mc.IsConnected()).To(gm.BeTrue())
+
+		mc.Close()
+		gm.Expect(source.IsConnected()).To(gm.BeFalse())
+		gm.Expect(target.IsConnected()).To(gm.BeFalse())
+	})
+
+	gg.It("must write to both clusters", func() {
+		mc := as.NewMigrationClient(nil, source, target)
+		defer mc.Close()
+
+		gm.Expect(target.Client.Put(nil, key, as.BinMap{"a": 0})).ToNot(gm.HaveOccurred())
+		gm.Expect(mc.Put(nil, key, as.BinMap{"a": 1})).ToNot(gm.HaveOccurred())
+		gm.Expect(mc.AddBins(nil, key, as.NewBin("a", 1))).ToNot(gm.HaveOccurred())
+
+		// the generation checks are only applied on the source cluster
+		rec, err := source.Get(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		_, err = mc.Operate(as.NewWritePolicy(0, 0).IfGenerationEquals(rec.Generation), key, as.AddOp(as.NewBin("a", 1)))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		for _, clnt := range []as.ClientIfc{source, target} {
+			rec, err := clnt.Get(nil, key)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"a": 3}))
+		}
+		gm.Expect(mc.MigrationStats().TargetWrites).To(gm.Equal(3))
+
+		// reads are not sent to the target cluster
+		_, err = mc.Operate(nil, key, as.GetBinOp("a"))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(mc.MigrationStats().TargetWrites).To(gm.Equal(3))
+
+		existed, err := mc.Delete(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(existed).To(gm.BeTrue())
+		exists, err := target.Exists(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(exists).To(gm.BeFalse())
+	})
+
+	gg.It("must not write to the target cluster if the source cluster fails", func() {
+		mc := as.NewMigrationClient(nil, source, target)
+		defer mc.Close()
+
+		gm.Expect(source.Put(nil, key, as.BinMap{"a": 1})).ToNot(gm.HaveOccurred())
+
+		policy := as.NewWritePolicy(0, 0)
+		policy.RecordExistsAction = as.CREATE_ONLY
+		err := mc.Put(policy, key, as.BinMap{"a": 2})
+		gm.Expect(err.Matches(types.KEY_EXISTS_ERROR)).To(gm.BeTrue())
+
+		exists, err := target.Exists(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(exists).To(gm.BeFalse())
+		gm.Expect(mc.MigrationStats().TargetWrites).To(gm.Equal(0))
+	})
+
+	gg.It("must report the errors of the target cluster", func() {
+		var failed []*as.Key
+		policy := as.NewMigrationPolicy()
+		policy.OnTargetWriteError = func(key *as.Key, err as.Error) {
+			failed = append(failed, key)
+		}
+		mc := as.NewMigrationClient(policy, source, target)
+		defer mc.Close()
+
+		target.err = as.ErrTimeout
+		gm.Expect(mc.Put(nil, key, as.BinMap{"a": 1})).ToNot(gm.HaveOccurred())
+		gm.Expect(mc.MigrationStats().TargetWriteErrors).To(gm.Equal(1))
+		gm.Expect(failed).To(gm.Equal([]*as.Key{key}))
+
+		policy.FailOnTargetWriteError = true
+		mc = as.NewMigrationClient(policy, source, target)
+		err := mc.Put(nil, key, as.BinMap{"a": 1})
+		gm.Expect(err.Matches(types.TIMEOUT)).To(gm.BeTrue())
+	})
+
+	gg.It("must keep the results of the source cluster in batch writes", func() {
+		mc := as.NewMigrationClient(nil, source, target)
+		defer mc.Close()
+
+		key2, _ := as.NewKey(ns, "users", "bob")
+		gm.Expect(source.Put(nil, key2, as.BinMap{"a": 10})).ToNot(gm.HaveOccurred())
+
+		gm.Expect(target.Client.Put(nil, key, as.BinMap{"a": 5})).ToNot(gm.HaveOccurred())
+		write := as.NewBatchWrite(nil, key, as.AddOp(as.NewBin("a", 1)), as.GetBinOp("a"))
+		read := as.NewBatchRead(nil, key2, []string{"a"})
+		gm.Expect(mc.BatchOperate(nil, []as.BatchRecordIfc{write, read})).ToNot(gm.HaveOccurred())
+
+		gm.Expect(write.ResultCode).To(gm.Equal(types.OK))
+		gm.Expect(write.Record.Bins).To(gm.Equal(as.BinMap{"a": as.OpResults{nil, 1}}))
+		gm.Expect(read.Record.Bins).To(gm.Equal(as.BinMap{"a": 10}))
+
+		rec, err := target.Get(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"a": 6}))
+		exists, err := target.Exists(nil, key2)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(exists).To(gm.BeFalse())
+		gm.Expect(mc.MigrationStats().TargetWrites).To(gm.Equal(1))
+	})
+
+	gg.It("must compare the reads with the target cluster", func() {
+		var mismatches []*as.Key
+		policy := as.NewMigrationPolicy()
+		policy.ShadowReadWorkers = 1
+		policy.OnMismatch = func(key *as.Key, source, target *as.Record) {
+			mismatches = append(mismatches, key)
+		}
+		mc := as.NewMigrationClient(policy, source, target)
+
+		key2, _ := as.NewKey(ns, "users", "bob")
+		gm.Expect(mc.Put(nil, key, as.BinMap{"a": 1})).ToNot(gm.HaveOccurred())
+		gm.Expect(source.Put(nil, key2, as.BinMap{"a": 2})).ToNot(gm.HaveOccurred())
+
+		_, err := mc.Get(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		exists, err := mc.Exists(nil, key2)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(exists).To(gm.BeTrue())
+		_, err = mc.BatchGet(nil, []*as.Key{key, key2})
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		// Close waits for the queued shadow reads
+		mc.Close()
+		stats := mc.MigrationStats()
+		gm.Expect(stats.ShadowReads).To(gm.Equal(4))
+		gm.Expect(stats.Mismatches).To(gm.Equal(2))
+		gm.Expect(mismatches).To(gm.Equal([]*as.Key{key2, key2}))
+	})
+
+	gg.It("must not compare the reads if shadow reads are disabled", func() {
+		policy := as.NewMigrationPolicy()
+		policy.ShadowReadPercent = 0
+		mc := as.NewMigrationClient(policy, source, target)
+
+		_, err := mc.Get(nil, key)
+		gm.Expect(err.Matches(types.KEY_NOT_FOUND_ERROR)).To(gm.BeTrue())
+		mc.Close()
+		gm.Expect(mc.MigrationStats().ShadowReads).To(gm.Equal(0))
+	})
+})
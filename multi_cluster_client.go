@@ -0,0 +1,489 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	iatomic "github.com/aerospike/aerospike-client-go/v7/internal/atomic"
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// MultiClusterReadPreference determines the cluster that serves the reads of a MultiClusterClient.
+type MultiClusterReadPreference int
+
+const (
+	// ReadPreferFirst reads from the first healthy cluster, in the order of the members.
+	ReadPreferFirst MultiClusterReadPreference = iota
+
+	// ReadRoundRobin spreads the reads over the healthy clusters.
+	ReadRoundRobin
+)
+
+// MultiClusterFailover determines when a failed command of a MultiClusterClient is retried on the next cluster.
+type MultiClusterFailover int
+
+const (
+	// FailoverNever returns the error of the first cluster tried.
+	FailoverNever MultiClusterFailover = iota
+
+	// FailoverOnUnavailable retries the command on the next cluster if the cluster could not be reached,
+	// like on network errors, timeouts and missing nodes.
+	FailoverOnUnavailable
+
+	// FailoverOnAnyError retries the command on the next cluster on all errors, except the results
+	// that would be the same on all clusters, like key not found, key exists, generation mismatch,
+	// filtered out records and parameter errors.
+	FailoverOnAnyError
+)
+
+// MultiClusterPolicy encapsulates the parameters of a MultiClusterClient.
+type MultiClusterPolicy struct {
+	// ReadPreference determines the cluster that serves the reads.
+	// Default: ReadPreferFirst
+	ReadPreference MultiClusterReadPreference
+
+	// Failover determines when a failed command is retried on the next healthy cluster.
+	// Writes that time out may have been applied on the cluster they were sent to;
+	// in active-active setups, the record will converge through XDR.
+	// Failover is not used for writes if DuplicateWrites is set.
+	// Default: FailoverOnUnavailable
+	Failover MultiClusterFailover
+
+	// DuplicateWrites sends the writes to all the healthy clusters concurrently, instead of only to
+	// the first healthy cluster. The result of a duplicated write is the result of the first cluster,
+	// and the errors of all the clusters are chained.
+	// Default: false
+	DuplicateWrites bool
+
+	// MaxFailures is the number of consecutive failures of a cluster, because it could not be reached,
+	// after which the cluster is considered unhealthy for UnhealthyDuration.
+	// Unhealthy clusters are only used if none of the clusters is healthy.
+	// A value of 0 or less disables the tracking of the failures; clusters are then unhealthy
+	// only when their client is not connected.
+	// Default: 3
+	MaxFailures int
+
+	// UnhealthyDuration is the time a cluster is considered unhealthy after MaxFailures consecutive failures.
+	// Default: 5 seconds
+	UnhealthyDuration time.Duration
+}
+
+// NewMultiClusterPolicy returns a MultiClusterPolicy with the default values.
+func NewMultiClusterPolicy() *MultiClusterPolicy {
+	return &MultiClusterPolicy{
+		ReadPreference:    ReadPreferFirst,
+		Failover:          FailoverOnUnavailable,
+		MaxFailures:       3,
+		UnhealthyDuration: 5 * time.Second,
+	}
+}
+
+// MultiClusterMember describes a cluster of a MultiClusterClient.
+type MultiClusterMember struct {
+	// Name is the name of the cluster. It is used as the ClusterName of the client policy,
+	// so that each seed and peer node is validated to belong to the cluster.
+	Name string
+
+	// Policy is the policy of the client of the cluster. If nil, the default client policy is used.
+	// If its ClusterName is set, it must be equal to Name.
+	Policy *ClientPolicy
+
+	// Hosts are the seeds of the cluster. A seed cannot belong to more than one member.
+	Hosts []*Host
+
+	// Client is an already connected client of the cluster. If set, Policy and Hosts are ignored,
+	// and the client is not closed by the MultiClusterClient.
+	// If the client is a *Client, its ClientPolicy.ClusterName must be equal to Name.
+	Client ClientIfc
+}
+
+type multiClusterMember struct {
+	name   string
+	client ClientIfc
+	owned  bool
+
+	failures       iatomic.Int
+	unhealthyUntil iatomic.TypedVal[time.Time]
+}
+
+// healthy returns true if the client of the cluster is connected and
+// the cluster has not been marked as unhealthy.
+func (m *multiClusterMember) healthy() bool {
+	return m.client.IsConnected() && !time.Now().Before(m.unhealthyUntil.Get())
+}
+
+// MultiClusterClient manages the clients of several clusters, like the clusters of an active-active
+// XDR setup. Reads are routed to the healthy clusters according to the read preference,
+// writes are sent to the first healthy cluster or duplicated to all of them,
+// and failed commands fail over to the next cluster according to the failover policy.
+type MultiClusterClient struct {
+	policy  MultiClusterPolicy
+	members []*multiClusterMember
+
+	nextRead iatomic.Int
+}
+
+// NewMultiClusterClient validates the members and connects to their clusters.
+// The names of the members must be unique, and a seed cannot belong to more than one member.
+// Each seed must report the name of its member as cluster name, otherwise a
+// CLUSTER_NAME_MISMATCH_ERROR is returned; this prevents clients from being wired to the wrong cluster.
+// If the policy is nil, the default policy will be used.
+func NewMultiClusterClient(policy *MultiClusterPolicy, members ...*MultiClusterMember) (*MultiClusterClient, Error) {
+	if policy == nil {
+		policy = NewMultiClusterPolicy()
+	}
+
+	if len(members) == 0 {
+		return nil, newError(types.PARAMETER_ERROR, "No clusters were specified for the multi-cluster client")
+	}
+
+	if err := validateMultiClusterMembers(members); err != nil {
+		return nil, err
+	}
+
+	mc := &MultiClusterClient{
+		policy:  *policy,
+		members: make([]*multiClusterMember, 0, len(members)),
+	}
+
+	for _, m := range members {
+		member := &multiClusterMember{name: m.Name, client: m.Client}
+		if member.client == nil {
+			clientPolicy := NewClientPolicy()
+			if m.Policy != nil {
+				*clientPolicy = *m.Policy
+			}
+			clientPolicy.ClusterName = m.Name
+
+			clnt, err := NewClientWithPolicyAndHost(clientPolicy, m.Hosts...)
+			if err != nil {
+				mc.Close()
+				return nil, chainErrors(newError(err.resultCode(), "Failed to connect to cluster `"+m.Name+"`"), err)
+			}
+			member.client = clnt
+			member.owned = true
+		}
+		mc.members = append(mc.members, member)
+	}
+
+	return mc, nil
+}
+
+func validateMultiClusterMembers(members []*MultiClusterMember) Error {
+	names := make(map[string]struct{}, len(members))
+	seeds := make(map[string]string)
+	for _, m := range members {
+		if len(m.Name) == 0 {
+			return newError(types.PARAMETER_ERROR, "The clusters of a multi-cluster client must have a name")
+		}
+
+		if _, exists := names[m.Name]; exists {
+			return newError(types.PARAMETER_ERROR, "Duplicate cluster name `"+m.Name+"` in multi-cluster client")
+		}
+		names[m.Name] = struct{}{}
+
+		if m.Client != nil {
+			if clnt, ok := m.Client.(*Client); ok && clnt.cluster.clientPolicy.ClusterName != m.Name {
+				return newError(types.CLUSTER_NAME_MISMATCH_ERROR, fmt.Sprintf("Client of cluster `%s` has ClientPolicy.ClusterName `%s`", m.Name, clnt.cluster.clientPolicy.ClusterName))
+			}
+			continue
+		}
+
+		if m.Policy != nil && len(m.Policy.ClusterName) > 0 && m.Policy.ClusterName != m.Name {
+			return newError(types.CLUSTER_NAME_MISMATCH_ERROR, fmt.Sprintf("Cluster `%s` has ClientPolicy.ClusterName `%s`", m.Name, m.Policy.ClusterName))
+		}
+
+		if len(m.Hosts) == 0 {
+			return newError(types.PARAMETER_ERROR, "No seeds were specified for cluster `"+m.Name+"`")
+		}
+
+		for _, host := range m.Hosts {
+			if other, exists := seeds[host.String()]; exists && other != m.Name {
+				return newError(types.PARAMETER_ERROR, fmt.Sprintf("Seed %s is specified for clusters `%s` and `%s`", host.String(), other, m.Name))
+			}
+			seeds[host.String()] = m.Name
+		}
+	}
+	return nil
+}
+
+// Close closes the clients created by the multi-cluster client.
+// The clients passed in MultiClusterMember.Client are not closed.
+func (mc *MultiClusterClient) Close() {
+	for _, m := range mc.members {
+		if m.owned {
+			m.client.Close()
+		}
+	}
+}
+
+// IsConnected returns true if the client of at least one cluster is connected.
+func (mc *MultiClusterClient) IsConnected() bool {
+	for _, m := range mc.members {
+		if m.client.IsConnected() {
+			return true
+		}
+	}
+	return false
+}
+
+// Clusters returns the names of the clusters, in the order of the members.
+func (mc *MultiClusterClient) Clusters() []string {
+	res := make([]string, len(mc.members))
+	for i, m := range mc.members {
+		res[i] = m.name
+	}
+	return res
+}
+
+// HealthyClusters returns the names of the healthy clusters, in the order of the members.
+func (mc *MultiClusterClient) HealthyClusters() []string {
+	res := make([]string, 0, len(mc.members))
+	for _, m := range mc.members {
+		if m.healthy() {
+			res = append(res, m.name)
+		}
+	}
+	return res
+}
+
+// Client returns the client of the cluster, or nil if the cluster does not exist.
+func (mc *MultiClusterClient) Client(cluster string) ClientIfc {
+	for _, m := range mc.members {
+		if m.name == cluster {
+			return m.client
+		}
+	}
+	return nil
+}
+
+// healthyMembers returns the healthy members starting from the member at index start, wrapping around.
+// If none of the members is healthy, all the members are returned, so that the commands still
+// get a chance to run and report their errors.
+func (mc *MultiClusterClient) healthyMembers(start int) []*multiClusterMember {
+	n := len(mc.members)
+	res := make([]*multiClusterMember, 0, n)
+	for i := 0; i < n; i++ {
+		if m := mc.members[(start+i)%n]; m.healthy() {
+			res = append(res, m)
+		}
+	}
+
+	if len(res) == 0 {
+		for i := 0; i < n; i++ {
+			res = append(res, mc.members[(start+i)%n])
+		}
+	}
+	return res
+}
+
+func (mc *MultiClusterClient) readMembers() []*multiClusterMember {
+	start := 0
+	if mc.policy.ReadPreference == ReadRoundRobin {
+		start = mc.nextRead.GetAndIncrement() % len(mc.members)
+		if start < 0 {
+			start += len(mc.members)
+		}
+	}
+	return mc.healthyMembers(start)
+}
+
+// record tracks the consecutive failures of the member and marks it as unhealthy
+// once they reach MaxFailures.
+func (mc *MultiClusterClient) record(m *multiClusterMember, err Error) {
+	if err == nil || !multiClusterUnavailable(err) {
+		m.failures.Set(0)
+		return
+	}
+
+	if mc.policy.MaxFailures > 0 && m.failures.IncrementAndGet() >= mc.policy.MaxFailures {
+		m.failures.Set(0)
+		m.unhealthyUntil.Set(time.Now().Add(mc.policy.UnhealthyDuration))
+	}
+}
+
+// multiClusterUnavailable returns true if the error means the cluster could not be reached.
+func multiClusterUnavailable(err Error) bool {
+	return err.Matches(
+		types.NETWORK_ERROR,
+		types.TIMEOUT,
+		types.MAX_RETRIES_EXCEEDED,
+		types.SERVER_NOT_AVAILABLE,
+		types.INVALID_NODE_ERROR,
+		types.NO_AVAILABLE_CONNECTIONS_TO_NODE,
+		types.INVALID_CLUSTER_PARTITION_MAP,
+	)
+}
+
+func (mc *MultiClusterClient) failover(err Error) bool {
+	switch mc.policy.Failover {
+	case FailoverOnUnavailable:
+		return multiClusterUnavailable(err)
+	case FailoverOnAnyError:
+		return !err.Matches(
+			types.KEY_NOT_FOUND_ERROR,
+			types.KEY_EXISTS_ERROR,
+			types.GENERATION_ERROR,
+			types.FILTERED_OUT,
+			types.PARAMETER_ERROR,
+		)
+	default:
+		return false
+	}
+}
+
+// multiClusterRun runs the command on the members in order, until it succeeds
+// or fails with an error that does not fail over.
+func multiClusterRun[T any](mc *MultiClusterClient, members []*multiClusterMember, fn func(cluster string, clnt ClientIfc) (T, Error)) (T, Error) {
+	var res T
+	var errs Error
+	for _, m := range members {
+		r, err := fn(m.name, m.client)
+		mc.record(m, err)
+		if err == nil {
+			return r, nil
+		}
+
+		res, errs = r, chainErrors(err, errs)
+		if !mc.failover(err) {
+			break
+		}
+	}
+	return res, errs
+}
+
+// multiClusterRead runs the read on the clusters chosen by the read preference.
+func multiClusterRead[T any](mc *MultiClusterClient, fn func(cluster string, clnt ClientIfc) (T, Error)) (T, Error) {
+	return multiClusterRun(mc, mc.readMembers(), fn)
+}
+
+// multiClusterWrite runs the write on the first healthy cluster, or on all of them if the writes are duplicated.
+func multiClusterWrite[T any](mc *MultiClusterClient, fn func(cluster string, clnt ClientIfc) (T, Error)) (T, Error) {
+	members := mc.healthyMembers(0)
+	if !mc.policy.DuplicateWrites || len(members) == 1 {
+		return multiClusterRun(mc, members, fn)
+	}
+
+	results := make([]T, len(members))
+	errs := make([]Error, len(members))
+
+	var wg sync.WaitGroup
+	wg.Add(len(members))
+	for i := range members {
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = fn(members[i].name, members[i].client)
+			mc.record(members[i], errs[i])
+		}(i)
+	}
+	wg.Wait()
+
+	var err Error
+	for i := len(errs) - 1; i >= 0; i-- {
+		if errs[i] != nil {
+			err = chainErrors(errs[i], err)
+		}
+	}
+	return results[0], err
+}
+
+// ExecuteRead runs the read command on the clusters chosen by the read preference,
+// failing over to the next cluster according to the failover policy.
+func (mc *MultiClusterClient) ExecuteRead(fn func(cluster string, clnt ClientIfc) Error) Error {
+	_, err := multiClusterRead(mc, func(cluster string, clnt ClientIfc) (struct{}, Error) {
+		return struct{}{}, fn(cluster, clnt)
+	})
+	return err
+}
+
+// ExecuteWrite runs the write command on the first healthy cluster, failing over to the next
+// cluster according to the failover policy, or on all the healthy clusters if the writes are duplicated.
+// When the writes are duplicated, fn is called concurrently.
+func (mc *MultiClusterClient) ExecuteWrite(fn func(cluster string, clnt ClientIfc) Error) Error {
+	_, err := multiClusterWrite(mc, func(cluster string, clnt ClientIfc) (struct{}, Error) {
+		return struct{}{}, fn(cluster, clnt)
+	})
+	return err
+}
+
+// Get reads a record from the clusters chosen by the read preference.
+// See Client.Get.
+func (mc *MultiClusterClient) Get(policy *BasePolicy, key *Key, binNames ...string) (*Record, Error) {
+	return multiClusterRead(mc, func(_ string, clnt ClientIfc) (*Record, Error) {
+		return clnt.Get(policy, key, binNames...)
+	})
+}
+
+// GetHeader reads a record header from the clusters chosen by the read preference.
+// See Client.GetHeader.
+func (mc *MultiClusterClient) GetHeader(policy *BasePolicy, key *Key) (*Record, Error) {
+	return multiClusterRead(mc, func(_ string, clnt ClientIfc) (*Record, Error) {
+		return clnt.GetHeader(policy, key)
+	})
+}
+
+// Exists determines if a record exists on the clusters chosen by the read preference.
+// See Client.Exists.
+func (mc *MultiClusterClient) Exists(policy *BasePolicy, key *Key) (bool, Error) {
+	return multiClusterRead(mc, func(_ string, clnt ClientIfc) (bool, Error) {
+		return clnt.Exists(policy, key)
+	})
+}
+
+// BatchGet reads multiple records from the clusters chosen by the read preference.
+// See Client.BatchGet.
+func (mc *MultiClusterClient) BatchGet(policy *BatchPolicy, keys []*Key, binNames ...string) ([]*Record, Error) {
+	return multiClusterRead(mc, func(_ string, clnt ClientIfc) ([]*Record, Error) {
+		return clnt.BatchGet(policy, keys, binNames...)
+	})
+}
+
+// Put writes the bins of a record to the cluster(s) chosen by the write policy of the multi-cluster client.
+// See Client.Put.
+func (mc *MultiClusterClient) Put(policy *WritePolicy, key *Key, binMap BinMap) Error {
+	_, err := multiClusterWrite(mc, func(_ string, clnt ClientIfc) (struct{}, Error) {
+		return struct{}{}, clnt.Put(policy, key, binMap)
+	})
+	return err
+}
+
+// PutBins writes the bins of a record to the cluster(s) chosen by the write policy of the multi-cluster client.
+// See Client.PutBins.
+func (mc *MultiClusterClient) PutBins(policy *WritePolicy, key *Key, bins ...*Bin) Error {
+	_, err := multiClusterWrite(mc, func(_ string, clnt ClientIfc) (struct{}, Error) {
+		return struct{}{}, clnt.PutBins(policy, key, bins...)
+	})
+	return err
+}
+
+// Delete deletes a record from the cluster(s) chosen by the write policy of the multi-cluster client.
+// See Client.Delete.
+func (mc *MultiClusterClient) Delete(policy *WritePolicy, key *Key) (bool, Error) {
+	return multiClusterWrite(mc, func(_ string, clnt ClientIfc) (bool, Error) {
+		return clnt.Delete(policy, key)
+	})
+}
+
+// Operate runs the operations on a record of the cluster(s) chosen by the write policy of the multi-cluster
+// client. Operate is always routed as a write, even if all the operations are reads.
+// See Client.Operate.
+func (mc *MultiClusterClient) Operate(policy *WritePolicy, key *Key, operations ...*Operation) (*Record, Error) {
+	return multiClusterWrite(mc, func(_ string, clnt ClientIfc) (*Record, Error) {
+		return clnt.Operate(policy, key, operations...)
+	})
+}
@@ -16,8 +16,8 @@ package aerospike
 
 import (
 	"fmt"
-	"math/rand"
 	"reflect"
+	"time"
 
 	"github.com/aerospike/aerospike-client-go/v7/types"
 	Buffer "github.com/aerospike/aerospike-client-go/v7/utils/buffer"
@@ -33,6 +33,10 @@ type baseMultiCommand struct {
 
 	isOperation bool
 
+	// projection filters the bins of the records
+	// returned by the server. Nil keeps all the bins.
+	projection *binProjection
+
 	// Used in correct Scans/Queries
 	tracker        *partitionTracker
 	nodePartitions *nodePartitions
@@ -113,12 +117,12 @@ func (cmd *baseMultiCommand) prepareRetry(ifc command, isTimeout bool) bool {
 	return false
 }
 
-func (cmd *baseMultiCommand) getConnection(policy Policy) (*Connection, Error) {
-	return cmd.node.getConnectionWithHint(policy.GetBasePolicy().deadline(), policy.GetBasePolicy().socketTimeout(), byte(rand.Int63()&0xff))
+func (cmd *baseMultiCommand) getConnection(policy Policy, deadline time.Time) (*Connection, Error) {
+	return cmd.node.getConnectionWithPolicy(policy.GetBasePolicy(), deadline, policy.GetBasePolicy().socketTimeout(), cmd.node.connections.shard())
 }
 
 func (cmd *baseMultiCommand) putConnection(conn *Connection) {
-	cmd.node.putConnectionWithHint(conn, byte(rand.Int63()&0xff))
+	cmd.node.putConnectionWithHint(conn, cmd.node.connections.shard())
 }
 
 func (cmd *baseMultiCommand) parseResult(ifc command, conn *Connection) Error {
@@ -336,6 +340,9 @@ func (cmd *baseMultiCommand) parseRecordResults(ifc command, receiveSize int) (b
 				if err = cmd.readBytes(particleBytesSize); err != nil {
 					return false, newNodeError(cmd.node, err)
 				}
+				if !cmd.projection.allows(name) {
+					continue
+				}
 				value, err := bytesToParticleRaw(particleType, cmd.dataBuffer, 0, particleBytesSize, cmd.rawCDT)
 				if err != nil {
 					return false, newNodeError(cmd.node, err)
@@ -364,12 +371,11 @@ func (cmd *baseMultiCommand) parseRecordResults(ifc command, receiveSize int) (b
 				continue
 			}
 
+			cmd.recordset.throttle()
+
 			// If the channel is full and it blocks, we don't want this command to
 			// block forever, or panic in case the channel is closed in the meantime.
-			select {
-			// send back the result on the async channel
-			case cmd.recordset.records <- &Result{Record: newRecord(cmd.node, key, bins, generation, expiration), Err: nil, BVal: &bval}:
-			case <-cmd.recordset.cancelled:
+			if !cmd.recordset.send(&Result{Record: newRecord(cmd.node, key, bins, generation, expiration), Err: nil, BVal: &bval}) {
 				switch cmd.terminationErrorType {
 				case types.SCAN_TERMINATED:
 					return false, ErrScanTerminated.err().setNode(cmd.node)
@@ -390,14 +396,20 @@ func (cmd *baseMultiCommand) parseRecordResults(ifc command, receiveSize int) (b
 				continue
 			}
 
-			// set the object to send
-			cmd.selectCases[0].Send = obj
+			cmd.recordset.throttle()
 
-			chosen, _, _ := reflect.Select(cmd.selectCases)
-			switch chosen {
-			case 0: // object sent
-			case 1: // cancel channel is closed
-				return false, newError(cmd.terminationErrorType).setNode(cmd.node)
+			if !cmd.recordset.objChan.TrySend(obj) {
+				// set the object to send
+				cmd.selectCases[0].Send = obj
+
+				start := time.Now()
+				chosen, _, _ := reflect.Select(cmd.selectCases)
+				cmd.recordset.blocked(start)
+				switch chosen {
+				case 0: // object sent
+				case 1: // cancel channel is closed
+					return false, newError(cmd.terminationErrorType).setNode(cmd.node)
+				}
 			}
 		}
 
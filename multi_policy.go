@@ -44,6 +44,16 @@ type MultiPolicy struct {
 	// Currently only applicable to a query without a defined filter.
 	RecordsPerSecond int
 
+	// TotalRecordsPerSecond limits the total rate of the records returned by all the nodes
+	// of a scan or query, unlike RecordsPerSecond which applies to each node.
+	// The rate is enforced by the client: the records of all the node streams share a single
+	// token bucket, and the streams that run ahead of the rate stop reading from the server
+	// until tokens are available.
+	// This option is ignored by the proxy client.
+	//
+	// Default: 0 (do not limit the total rate)
+	TotalRecordsPerSecond int
+
 	// Number of records to place in queue before blocking.
 	// Records received from multiple server nodes will be placed in a queue.
 	// A separate goroutine consumes these records in parallel.
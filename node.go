@@ -20,6 +20,7 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/sync/errgroup"
@@ -38,6 +39,11 @@ const (
 	_SUPPORTS_QUERY_SHOW
 	_SUPPORTS_BATCH_ANY
 	_SUPPORTS_PARTITION_QUERY
+	_SUPPORTS_USER_AGENT
+	// enterprise edition servers keep tombstones for durable deletes
+	_SUPPORTS_DURABLE_DELETE
+	// server versions 7.0+ support secondary indexes on blob bins
+	_SUPPORTS_BLOB_INDEX
 )
 
 // Node represents an Aerospike Database Server Node
@@ -61,6 +67,8 @@ type Node struct {
 
 	connections     connectionHeap
 	connectionCount iatomic.Int
+	// number of commands currently sent to the node, used by the LEAST_LOADED replica policy
+	inFlight iatomic.Int
 
 	partitionGeneration iatomic.Int
 	referenceCount      iatomic.Int
@@ -68,10 +76,30 @@ type Node struct {
 	partitionChanged    iatomic.Bool
 	errorCount          iatomic.Int
 	rebalanceGeneration iatomic.Int
+	connectionsDesync   iatomic.Int
 
-	features int
+	// commands waiting for a connection to be returned to the pool
+	connWaiters *connectionWaitQueue
+
+	// shared sockets of the single record commands, if ClientPolicy.MultiplexedConnectionsPerNode is set
+	mux *connMultiplexer
+
+	// recent latencies of the node for the adaptive timeouts
+	latencies latencyWindow
+
+	// values reported by the server process of the node; replaced as a whole
+	serverInfo iatomic.TypedVal[serverInfo]
+	// serializes the updates of serverInfo; reads are lock-free
+	serverInfoLock sync.Mutex
+	// set when a tend fails, since the server may be restarted with a different
+	// build before the next tend succeeds
+	serverInfoStale iatomic.Bool
 
 	active iatomic.Bool
+
+	// quiesced is true if new commands should not be routed to the node.
+	// See Cluster.QuiesceNode.
+	quiesced iatomic.Bool
 }
 
 // NewNode initializes a server node with connection parameters.
@@ -81,13 +109,11 @@ func newNode(cluster *Cluster, nv *nodeValidator) *Node {
 		name:    nv.name,
 		host:    nv.primaryHost,
 
-		features: nv.features,
-
 		stats: *newNodeStats(cluster.MetricsPolicy()),
 
 		// Assign host to first IP alias because the server identifies nodes
 		// by IP address (not hostname).
-		connections:         *newConnectionHeap(cluster.clientPolicy.MinConnectionsPerNode, cluster.clientPolicy.ConnectionQueueSize),
+		connections:         *newConnectionHeap(cluster.clientPolicy.MinConnectionsPerNode, cluster.clientPolicy.ConnectionQueueSize, cluster.clientPolicy.ConnectionPoolShards, cluster.clientPolicy.LockFreeConnectionPool),
 		connectionCount:     *iatomic.NewInt(0),
 		peersGeneration:     *iatomic.NewInt(-1),
 		partitionGeneration: *iatomic.NewInt(-2),
@@ -99,9 +125,21 @@ func newNode(cluster *Cluster, nv *nodeValidator) *Node {
 		rebalanceGeneration: *iatomic.NewInt(-1),
 	}
 
+	maxWaiters := cluster.clientPolicy.MaxConnectionWaiters
+	if maxWaiters <= 0 {
+		maxWaiters = cluster.clientPolicy.ConnectionQueueSize
+	}
+	newNode.connWaiters = newConnectionWaitQueue(maxWaiters)
+
+	if n := cluster.clientPolicy.MultiplexedConnectionsPerNode; n > 0 {
+		newNode.mux = newConnMultiplexer(newNode, n)
+	}
+
 	newNode.aliases.Set(nv.aliases)
 	newNode.sessionInfo.Set(nv.sessionInfo)
 	newNode.racks.Set(make(map[string]int))
+	newNode.setServerInfo(nv)
+	newNode.quiesced.Set(cluster.isNodeQuiesced(nv.name))
 
 	// this will reset to zero on first aggregation on the cluster,
 	// therefore will only be counted once.
@@ -112,17 +150,34 @@ func newNode(cluster *Cluster, nv *nodeValidator) *Node {
 
 // SupportsBatchAny returns true if the node supports the feature.
 func (nd *Node) SupportsBatchAny() bool {
-	return (nd.features & _SUPPORTS_BATCH_ANY) != 0
+	return (nd.serverInfo.Get().features & _SUPPORTS_BATCH_ANY) != 0
 }
 
 // SupportsQueryShow returns true if the node supports the feature.
 func (nd *Node) SupportsQueryShow() bool {
-	return (nd.features & _SUPPORTS_QUERY_SHOW) != 0
+	return (nd.serverInfo.Get().features & _SUPPORTS_QUERY_SHOW) != 0
 }
 
 // SupportsPartitionQuery returns true if the node supports the feature.
 func (nd *Node) SupportsPartitionQuery() bool {
-	return (nd.features & _SUPPORTS_PARTITION_QUERY) != 0
+	return (nd.serverInfo.Get().features & _SUPPORTS_PARTITION_QUERY) != 0
+}
+
+// SupportsZstdCompression returns true if the commands to the node can be compressed with ZSTD.
+// The servers do not advertise it; see ClientPolicy.EnableZstdCompression.
+func (nd *Node) SupportsZstdCompression() bool {
+	return nd.cluster != nil && nd.cluster.clientPolicy.EnableZstdCompression
+}
+
+// SupportsDurableDelete returns true if the node is an Enterprise Edition server that
+// leaves tombstones for durable deletes.
+func (nd *Node) SupportsDurableDelete() bool {
+	return (nd.serverInfo.Get().features & _SUPPORTS_DURABLE_DELETE) != 0
+}
+
+// SupportsBlobIndex returns true if the node supports secondary indexes on BLOB bins.
+func (nd *Node) SupportsBlobIndex() bool {
+	return (nd.serverInfo.Get().features & _SUPPORTS_BLOB_INDEX) != 0
 }
 
 // Refresh requests current status from server node, and updates node with the result.
@@ -139,14 +194,25 @@ func (nd *Node) Refresh(peers *peers) Error {
 	// Clear node reference counts.
 	nd.referenceCount.Set(0)
 	nd.partitionChanged.Set(false)
+	nd.quiesced.Set(nd.cluster.isNodeQuiesced(nd.name))
 
 	var infoMap map[string]string
 	commands := []string{"node", "peers-generation", "partition-generation"}
 	if nd.cluster.clientPolicy.RackAware {
 		commands = append(commands, "racks:")
 	}
+	if nd.reconcileConnectionsDue() {
+		commands = append(commands, _USER_AGENTS)
+	}
+
+	// reload the server info in the same request after a failed tend
+	staleInfo := nd.serverInfoStale.Get()
+	if staleInfo {
+		commands = append(commands, serverInfoKeys...)
+	}
 
-	infoMap, err := nd.RequestInfo(&nd.cluster.infoPolicy, commands...)
+	// always go to the server; the cached node name must not hide a node change
+	infoMap, err := nd.requestInfo(nd.cluster.infoPolicy.Timeout, commands...)
 	if err != nil {
 		nd.refreshFailed(err)
 		return err
@@ -177,6 +243,12 @@ func (nd *Node) Refresh(peers *peers) Error {
 		logger.Logger.Warn("Updating node rack info failed with error: %s (racks: `%s`)", err, infoMap["racks:"])
 	}
 
+	nd.reconcileConnections(infoMap)
+
+	if staleInfo {
+		nd.refreshServerInfo(infoMap)
+	}
+
 	nd.failures.Set(0)
 	peers.refreshCount.IncrementAndGet()
 	nd.referenceCount.IncrementAndGet()
@@ -210,7 +282,7 @@ func (nd *Node) refreshSessionToken() (err Error) {
 
 	nd.usingTendConn(nd.cluster.clientPolicy.LoginTimeout, func(conn *Connection) {
 		command := newLoginCommand(conn.dataBuffer)
-		if err = command.login(&nd.cluster.clientPolicy, conn, nd.cluster.Password()); err != nil {
+		if err = nd.cluster.login(&nd.cluster.clientPolicy, conn, nd.host, command); err != nil {
 			// force new connections to use default creds until a new valid session token is acquired
 			nd.resetSessionInfo()
 			// Socket not authenticated. Do not put back into pool.
@@ -389,6 +461,8 @@ func (nd *Node) refreshFailed(e Error) {
 	nd.failures.IncrementAndGet()
 	nd.stats.TendsFailed.IncrementAndGet()
 
+	nd.serverInfoStale.Set(true)
+
 	// Only log message if cluster is still active.
 	if nd.cluster.IsConnected() {
 		logger.Logger.Warn("Node `%s` refresh failed: `%s`", nd, e)
@@ -437,7 +511,7 @@ func (nd *Node) GetConnection(timeout time.Duration) (conn *Connection, err Erro
 // getConnection gets a connection to the node.
 // If no pooled connection is available, a new connection will be created.
 func (nd *Node) getConnection(deadline time.Time, timeout time.Duration) (conn *Connection, err Error) {
-	return nd.getConnectionWithHint(deadline, timeout, 0)
+	return nd.getConnectionWithHint(deadline, timeout, nd.connections.shard())
 }
 
 // newConnectionAllowed will tentatively check if the client is allowed to make a new connection
@@ -495,6 +569,13 @@ func (nd *Node) newConnection(overrideThreshold bool) (*Connection, Error) {
 		defer nd.cluster.connectionThreshold.DecrementAndGet()
 	}
 
+	// wait for a slot if the connections opened at the same time are limited cluster-wide
+	if err := nd.cluster.reconnect.acquireConnect(nd.cluster.clientPolicy.Timeout); err != nil {
+		nd.connectionCount.DecrementAndGet()
+		return nil, err
+	}
+	defer nd.cluster.reconnect.releaseConnect()
+
 	nd.stats.ConnectionsAttempts.IncrementAndGet()
 	conn, err := NewConnection(&nd.cluster.clientPolicy, nd.host)
 	if err != nil {
@@ -507,7 +588,10 @@ func (nd *Node) newConnection(overrideThreshold bool) (*Connection, Error) {
 
 	sessionInfo := nd.sessionInfo.Get()
 	// need to authenticate
-	if err = conn.login(&nd.cluster.clientPolicy, nd.cluster.Password(), sessionInfo); err != nil {
+	login := func(command *loginCommand) Error {
+		return nd.cluster.login(&nd.cluster.clientPolicy, conn, nd.host, command)
+	}
+	if err = conn.login(&nd.cluster.clientPolicy, login, sessionInfo); err != nil {
 		// increment node errors if authentication hit a network error
 		if networkError(err) {
 			nd.incrErrorCount()
@@ -519,6 +603,11 @@ func (nd *Node) newConnection(overrideThreshold bool) (*Connection, Error) {
 		return nil, err
 	}
 
+	if err = nd.identifyConnection(conn); err != nil {
+		// not fatal; the connection will only be ignored in reconciliation
+		logger.Logger.Debug("Failed to identify the connection to node %s: %s", nd.String(), err.Error())
+	}
+
 	nd.stats.ConnectionsSuccessful.IncrementAndGet()
 	conn.setIdleTimeout(nd.cluster.clientPolicy.IdleTimeout)
 
@@ -577,11 +666,80 @@ func (nd *Node) getConnectionWithHint(deadline time.Time, timeout time.Duration,
 	return conn, nil
 }
 
+// getConnectionWithPolicy gets a connection to the node for a command, with the given socket timeout.
+// If the pool is exhausted and the policy allows it, it will wait for a connection
+// to be returned to the pool until the deadline of the command. The deadline is computed
+// once per command, so that the waits of all the attempts are bounded by it.
+func (nd *Node) getConnectionWithPolicy(policy *BasePolicy, deadline time.Time, timeout time.Duration, hint byte) (conn *Connection, err Error) {
+	conn, err = nd.getConnectionWithHint(deadline, timeout, hint)
+	if err == nil || !policy.WaitForConnection {
+		return conn, err
+	}
+
+	waited := false
+	for err != nil && (errors.Is(err, ErrConnectionPoolExhausted) || errors.Is(err, ErrConnectionPoolEmpty)) {
+		if !waited {
+			nd.stats.ConnectionsWaited.IncrementAndGet()
+			waited = true
+		}
+
+		var ok bool
+		conn, ok = nd.connWaiters.wait(nd.clock(), deadline)
+		if !ok {
+			nd.stats.ConnectionsWaitTimeouts.IncrementAndGet()
+			return nil, err
+		}
+
+		if conn == nil {
+			// a slot was freed; try again
+			conn, err = nd.getConnectionWithHint(deadline, timeout, hint)
+			continue
+		}
+
+		if !conn.IsConnected() {
+			conn.Close()
+			conn, err = nd.getConnectionWithHint(deadline, timeout, hint)
+			continue
+		}
+
+		if err = conn.SetTimeout(deadline, timeout); err != nil {
+			nd.stats.ConnectionsFailed.IncrementAndGet()
+			conn.Close()
+			return nil, err
+		}
+
+		conn.refresh()
+		return conn, nil
+	}
+
+	return conn, err
+}
+
+// ConnectionWaitQueueLen returns the number of commands currently waiting for a connection to this node.
+func (nd *Node) ConnectionWaitQueueLen() int {
+	return nd.connWaiters.Len()
+}
+
+// InFlightCommands returns the number of commands this client is currently executing on the node.
+func (nd *Node) InFlightCommands() int {
+	return nd.inFlight.Get()
+}
+
 // PutConnection puts back a connection to the pool.
 // If connection pool is full, the connection will be
 // closed and discarded.
 func (nd *Node) putConnectionWithHint(conn *Connection, hint byte) bool {
+	if conn.multiplexed {
+		// the shared socket stays open; only release the virtual connection
+		nd.mux.put(conn)
+		return true
+	}
+
 	conn.refresh()
+	if nd.active.Get() && nd.connWaiters.handOff(conn) {
+		return true
+	}
+
 	if !nd.active.Get() || !nd.connections.Offer(conn, hint) {
 		nd.stats.ConnectionsPoolOverflow.IncrementAndGet()
 		conn.Close()
@@ -594,7 +752,7 @@ func (nd *Node) putConnectionWithHint(conn *Connection, hint byte) bool {
 // If connection pool is full, the connection will be
 // closed and discarded.
 func (nd *Node) PutConnection(conn *Connection) {
-	nd.putConnectionWithHint(conn, 0)
+	nd.putConnectionWithHint(conn, nd.connections.shard())
 }
 
 // InvalidateConnection closes and discards a connection from the pool.
@@ -607,11 +765,25 @@ func (nd *Node) GetHost() *Host {
 	return nd.host
 }
 
+// clock returns the source of time of the cluster of the node.
+func (nd *Node) clock() Clock {
+	if nd.cluster == nil {
+		return SystemClock
+	}
+	return nd.cluster.clock()
+}
+
 // IsActive Checks if the node is active.
 func (nd *Node) IsActive() bool {
 	return nd != nil && nd.active.Get() && nd.partitionGeneration.Get() >= -1
 }
 
+// IsQuiesced returns true if the node is quiesced on the client with Cluster.QuiesceNode.
+// Quiesced nodes only receive new commands when no other replica of the partition is active.
+func (nd *Node) IsQuiesced() bool {
+	return nd.quiesced.Get()
+}
+
 // GetName returns node name.
 func (nd *Node) GetName() string {
 	return nd.name
@@ -659,6 +831,10 @@ func (nd *Node) String() string {
 }
 
 func (nd *Node) closeConnections() {
+	if nd.mux != nil {
+		nd.mux.close()
+	}
+
 	for conn := nd.connections.Poll(0); conn != nil; conn = nd.connections.Poll(0) {
 		conn.Close()
 	}
@@ -772,8 +948,29 @@ func (nd *Node) requestInfoWithRetry(policy *InfoPolicy, n int, name ...string)
 }
 
 // RequestInfo gets info values by name from the specified database server node.
+// Responses of the commands that do not change for the lifetime of the server
+// process (node, build, edition and features) are cached on the node, and
+// only the remaining commands are sent to the server in a single request.
 func (nd *Node) RequestInfo(policy *InfoPolicy, name ...string) (map[string]string, Error) {
-	return nd.requestInfo(policy.Timeout, name...)
+	if len(name) == 0 {
+		return nd.requestInfo(policy.Timeout)
+	}
+
+	res, missing := nd.cachedInfo(name)
+	if len(missing) == 0 {
+		return res, nil
+	}
+
+	response, err := nd.requestInfo(policy.Timeout, missing...)
+	if err != nil {
+		return nil, err
+	}
+	nd.cacheInfo(response)
+
+	for k, v := range response {
+		res[k] = v
+	}
+	return res, nil
 }
 
 // RequestInfo gets info values by name from the specified database server node.
@@ -882,6 +1079,8 @@ func (nd *Node) WarmUp(count int) (int, Error) {
 	}
 
 	for i := 0; i < toAlloc; i++ {
+		// spread the connections over the shards of the pool
+		hint := byte(i % len(nd.connections.heaps))
 		g.Go(func() error {
 			conn, err := nd.newConnection(true)
 			if err != nil {
@@ -891,7 +1090,7 @@ func (nd *Node) WarmUp(count int) (int, Error) {
 				return err
 			}
 
-			if nd.putConnectionWithHint(conn, 0) {
+			if nd.putConnectionWithHint(conn, hint) {
 				cnt.IncrementAndGet()
 			} else {
 				conn.Close()
@@ -0,0 +1,189 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// immutableInfoKeys are the info commands whose responses do not change for
+// the lifetime of a server process. Their values are cached on the node and
+// served by Node.RequestInfo without a round trip.
+var immutableInfoKeys = map[string]struct{}{
+	"node":     {},
+	"build":    {},
+	"edition":  {},
+	"features": {},
+}
+
+// serverInfoKeys are the info commands the server info of a node is derived from.
+var serverInfoKeys = []string{"build", "edition", "features"}
+
+// serverInfo is what the server process of a node reported about itself.
+// The values are replaced together, so they always describe the same process.
+type serverInfo struct {
+	features    int
+	build       string
+	featureList []string
+
+	// cached responses of the info commands that do not change for the
+	// lifetime of the server process. See immutableInfoKeys.
+	cache map[string]string
+}
+
+// cacheableInfo returns the subset of infoMap that can be cached on the node.
+// Error responses are never cached.
+func cacheableInfo(infoMap map[string]string) map[string]string {
+	res := make(map[string]string, len(immutableInfoKeys))
+	for k := range immutableInfoKeys {
+		if v, exists := infoMap[k]; exists && !strings.HasPrefix(strings.ToUpper(v), "ERROR") {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+// parseFeatureList splits the response of the `features` info command into
+// a sorted list of feature names.
+func parseFeatureList(features string) []string {
+	res := []string{}
+	for _, f := range strings.Split(features, ";") {
+		if f = strings.TrimSpace(f); len(f) > 0 {
+			res = append(res, f)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// parseServerVersion returns the major and minor versions of a server build
+// string like `7.0.0.1`.
+func parseServerVersion(build string) (major, minor int, ok bool) {
+	parts := strings.SplitN(build, ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+
+	minor, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return major, minor, true
+}
+
+// setServerInfo replaces the server info of the node with the values found by the validator.
+func (nd *Node) setServerInfo(nv *nodeValidator) {
+	nd.serverInfoLock.Lock()
+	defer nd.serverInfoLock.Unlock()
+
+	nd.serverInfo.Set(serverInfo{
+		features:    nv.features,
+		build:       nv.build,
+		featureList: nv.featureList,
+		cache:       nv.infoCache,
+	})
+}
+
+// refreshServerInfo reloads the server info of the node from the responses of a tend
+// that followed a failed one. See refreshFailed.
+func (nd *Node) refreshServerInfo(infoMap map[string]string) {
+	nv := &nodeValidator{}
+	nv.setServerInfo(infoMap)
+
+	nd.setServerInfo(nv)
+	nd.serverInfoStale.Set(false)
+}
+
+// Features returns the sorted list of features reported by the server.
+func (nd *Node) Features() []string {
+	featureList := nd.serverInfo.Get().featureList
+	if len(featureList) == 0 {
+		return nil
+	}
+
+	res := make([]string, len(featureList))
+	copy(res, featureList)
+	return res
+}
+
+// HasFeature returns true if the server reported the feature.
+func (nd *Node) HasFeature(feature string) bool {
+	featureList := nd.serverInfo.Get().featureList
+	i := sort.SearchStrings(featureList, feature)
+	return i < len(featureList) && featureList[i] == feature
+}
+
+// Build returns the server version reported by the server.
+func (nd *Node) Build() string {
+	return nd.serverInfo.Get().build
+}
+
+// cachedInfo splits the requested info commands into the values already found
+// in the node's cache and the commands that need to be sent to the server.
+// Nothing is served from the cache after a failed tend, until the server info is reloaded.
+func (nd *Node) cachedInfo(names []string) (map[string]string, []string) {
+	var cache map[string]string
+	if !nd.serverInfoStale.Get() {
+		cache = nd.serverInfo.Get().cache
+	}
+
+	res := make(map[string]string, len(names))
+	var missing []string
+	for _, name := range names {
+		if v, exists := cache[name]; exists {
+			res[name] = v
+			continue
+		}
+		missing = append(missing, name)
+	}
+	return res, missing
+}
+
+// cacheInfo stores the immutable values of infoMap in the node's cache.
+// The values are not cached after a failed tend, since they may belong to another
+// server process than the rest of the server info.
+func (nd *Node) cacheInfo(infoMap map[string]string) {
+	values := cacheableInfo(infoMap)
+	if len(values) == 0 {
+		return
+	}
+
+	nd.serverInfoLock.Lock()
+	defer nd.serverInfoLock.Unlock()
+
+	if nd.serverInfoStale.Get() {
+		return
+	}
+
+	// copy on write; the cache is read without locks
+	info := nd.serverInfo.Get()
+	merged := make(map[string]string, len(info.cache)+len(values))
+	for k, v := range info.cache {
+		merged[k] = v
+	}
+	for k, v := range values {
+		merged[k] = v
+	}
+	info.cache = merged
+	nd.serverInfo.Set(info)
+}
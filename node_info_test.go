@@ -0,0 +1,174 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"errors"
+
+	ParticleType "github.com/aerospike/aerospike-client-go/v7/types/particle_type"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Node info cache", func() {
+
+	var nd *Node
+
+	gg.BeforeEach(func() {
+		clstr := &Cluster{quiescedNodes: map[string]struct{}{}, clientPolicy: *NewClientPolicy()}
+		nv := &nodeValidator{
+			name:        "A",
+			build:       "7.0.0.1",
+			featureList: parseFeatureList("pscans;query-show;;batch-any;"),
+			infoCache:   cacheableInfo(map[string]string{"node": "A", "build": "7.0.0.1", "edition": "ERROR::not found", "partition-generation": "5"}),
+		}
+		nd = newNode(clstr, nv)
+	})
+
+	gg.It("must parse and sort the feature list", func() {
+		gm.Expect(nd.Features()).To(gm.Equal([]string{"batch-any", "pscans", "query-show"}))
+		gm.Expect(nd.HasFeature("pscans")).To(gm.BeTrue())
+		gm.Expect(nd.HasFeature("pquery")).To(gm.BeFalse())
+		gm.Expect(nd.Build()).To(gm.Equal("7.0.0.1"))
+
+		// callers cannot modify the node's copy
+		nd.Features()[0] = "x"
+		gm.Expect(nd.HasFeature("batch-any")).To(gm.BeTrue())
+	})
+
+	gg.It("must only cache the immutable values without errors", func() {
+		gm.Expect(nd.serverInfo.Get().cache).To(gm.Equal(map[string]string{"node": "A", "build": "7.0.0.1"}))
+	})
+
+	gg.It("must serve cached values without a round trip", func() {
+		res, err := nd.RequestInfo(NewInfoPolicy(), "node", "build")
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(res).To(gm.Equal(map[string]string{"node": "A", "build": "7.0.0.1"}))
+	})
+
+	gg.It("must split the requests between the cache and the server", func() {
+		res, missing := nd.cachedInfo([]string{"build", "statistics", "edition"})
+		gm.Expect(res).To(gm.Equal(map[string]string{"build": "7.0.0.1"}))
+		gm.Expect(missing).To(gm.Equal([]string{"statistics", "edition"}))
+
+		nd.cacheInfo(map[string]string{"statistics": "a=1", "edition": "Aerospike Enterprise Edition"})
+		_, missing = nd.cachedInfo([]string{"build", "statistics", "edition"})
+		gm.Expect(missing).To(gm.Equal([]string{"statistics"}))
+	})
+
+	gg.It("must bypass the cache after a failed tend until the server info is reloaded", func() {
+		nd.refreshFailed(ErrNetTimeout.err())
+		_, missing := nd.cachedInfo([]string{"node", "build"})
+		gm.Expect(missing).To(gm.Equal([]string{"node", "build"}))
+
+		// responses of a server that may have been restarted are not cached
+		nd.cacheInfo(map[string]string{"edition": "Aerospike Enterprise Edition"})
+		_, missing = nd.cachedInfo([]string{"edition"})
+		gm.Expect(missing).To(gm.Equal([]string{"edition"}))
+
+		// the values reported before the failure are kept
+		gm.Expect(nd.Build()).To(gm.Equal("7.0.0.1"))
+		gm.Expect(nd.SupportsPartitionQuery()).To(gm.BeFalse())
+
+		// the next tend reloads the server info as a whole
+		nd.refreshServerInfo(map[string]string{"node": "A", "build": "7.1.0.0", "edition": "Aerospike Enterprise Edition", "features": "pscans;pquery"})
+		gm.Expect(nd.Build()).To(gm.Equal("7.1.0.0"))
+		gm.Expect(nd.Features()).To(gm.Equal([]string{"pquery", "pscans"}))
+		gm.Expect(nd.SupportsPartitionQuery()).To(gm.BeTrue())
+		gm.Expect(nd.SupportsDurableDelete()).To(gm.BeTrue())
+
+		res, missing := nd.cachedInfo([]string{"node", "build", "features"})
+		gm.Expect(missing).To(gm.BeEmpty())
+		gm.Expect(res).To(gm.Equal(map[string]string{"node": "A", "build": "7.1.0.0", "features": "pscans;pquery"}))
+	})
+})
+
+var _ = gg.Describe("Cluster feature detection", func() {
+
+	var clstr *Cluster
+
+	newFeatureNode := func(name, build, features string) *Node {
+		nv := &nodeValidator{name: name, build: build, featureList: parseFeatureList(features)}
+		nv.setFeatures(features)
+		if major, _, ok := parseServerVersion(build); ok && major >= 7 {
+			nv.features |= _SUPPORTS_BLOB_INDEX
+		}
+		return newNode(clstr, nv)
+	}
+
+	gg.BeforeEach(func() {
+		clstr = &Cluster{quiescedNodes: map[string]struct{}{}, clientPolicy: *NewClientPolicy()}
+	})
+
+	gg.It("must parse the server versions", func() {
+		major, minor, ok := parseServerVersion("7.1.0.2")
+		gm.Expect(ok).To(gm.BeTrue())
+		gm.Expect(major).To(gm.Equal(7))
+		gm.Expect(minor).To(gm.Equal(1))
+
+		_, _, ok = parseServerVersion("seven")
+		gm.Expect(ok).To(gm.BeFalse())
+		_, _, ok = parseServerVersion("")
+		gm.Expect(ok).To(gm.BeFalse())
+	})
+
+	gg.It("must not report any features for an empty cluster", func() {
+		gm.Expect(clstr.SupportsFeature("pscans")).To(gm.BeFalse())
+		gm.Expect(clstr.SupportsQueryShow()).To(gm.BeFalse())
+	})
+
+	gg.It("must only report the features supported by all nodes", func() {
+		clstr.nodes.Set([]*Node{
+			newFeatureNode("A", "7.0.0.1", "pscans;pquery;query-show"),
+			newFeatureNode("B", "6.4.0.7", "pscans;pquery"),
+		})
+
+		gm.Expect(clstr.SupportsFeature("pscans")).To(gm.BeTrue())
+		gm.Expect(clstr.SupportsFeature("query-show")).To(gm.BeFalse())
+		gm.Expect(clstr.SupportsPartitionQuery()).To(gm.BeTrue())
+		gm.Expect(clstr.SupportsQueryShow()).To(gm.BeFalse())
+		gm.Expect(clstr.SupportsBlobIndex()).To(gm.BeFalse())
+
+		clstr.nodes.Set([]*Node{
+			newFeatureNode("A", "7.0.0.1", "pscans;pquery;query-show"),
+			newFeatureNode("B", "7.1.0.0", "pscans;pquery;query-show"),
+		})
+		gm.Expect(clstr.SupportsQueryShow()).To(gm.BeTrue())
+		gm.Expect(clstr.SupportsBlobIndex()).To(gm.BeTrue())
+	})
+
+	gg.It("must return a typed error when a node is too old for the feature", func() {
+		clstr.nodes.Set([]*Node{
+			newFeatureNode("A", "7.0.0.1", "pscans"),
+			newFeatureNode("B", "6.4.0.7", "pscans"),
+			newFeatureNode("C", "", "pscans"),
+		})
+
+		gm.Expect(clstr.requireServerVersion("partition query", 6, 4)).ToNot(gm.HaveOccurred())
+		gm.Expect(clstr.requireFilterSupport(NewEqualFilter("bin", 1))).ToNot(gm.HaveOccurred())
+
+		err := clstr.requireFilterSupport(NewFilter("bin", ICT_DEFAULT, ParticleType.BLOB, NewValue([]byte{1}), NewValue([]byte{1}), nil))
+		gm.Expect(err).To(gm.HaveOccurred())
+		gm.Expect(errors.Is(err, ErrUnsupportedServerFeature)).To(gm.BeTrue())
+
+		featureErr := &ServerFeatureError{}
+		gm.Expect(errors.As(err, &featureErr)).To(gm.BeTrue())
+		gm.Expect(featureErr.Feature).To(gm.Equal("blob index query"))
+		gm.Expect(featureErr.MinVersion).To(gm.Equal("7.0"))
+		gm.Expect(featureErr.NodeVersions).To(gm.Equal(map[string]string{"B": "6.4.0.7"}))
+		gm.Expect(err.Error()).To(gm.ContainSubstring("blob index query requires server version 7.0+, but found nodes: B=6.4.0.7"))
+	})
+})
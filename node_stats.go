@@ -61,6 +61,21 @@ type nodeStats struct {
 	NodeAdded iatomic.Int `json:"node-added-count"`
 	// Total number of times nodes were removed from the client (not the same as actual nodes removed. Network disruptions between client and server may cause a node being dropped client-side)
 	NodeRemoved iatomic.Int `json:"node-removed-count"`
+	// Number of times the number of connections reported by the node for this client differed from the
+	// number of connections the client tracks. See ClientPolicy.ConnectionReconcileWindow
+	ConnectionsDesync iatomic.Int `json:"connections-desync"`
+	// Number of times a command waited for a connection to be returned to the exhausted pool.
+	// See BasePolicy.WaitForConnection
+	ConnectionsWaited iatomic.Int `json:"connections-waited"`
+	// Number of times a command waiting for a connection timed out, or found the wait queue full
+	ConnectionsWaitTimeouts iatomic.Int `json:"connections-wait-timeouts"`
+	// number of idle connections probed by the health check
+	ConnectionsProbed iatomic.Int `json:"connections-probed"`
+	// number of idle connections that failed the health check and were closed
+	ConnectionsProbeFailed iatomic.Int `json:"connections-probe-failed"`
+	// Number of compressed commands sent with zlib instead of the algorithm of their policy.
+	// See BasePolicy.CompressionAlgorithm
+	CompressionFallbacks iatomic.Int `json:"compression-fallbacks"`
 
 	// Total number of transaction retries
 	TransactionRetryCount iatomic.Int `json:"transaction-retry-count"`
@@ -133,6 +148,12 @@ func (ns *nodeStats) getAndReset() *nodeStats {
 		PartitionMapUpdates:      ns.PartitionMapUpdates.CloneAndSet(0),
 		NodeAdded:                ns.NodeAdded.CloneAndSet(0),
 		NodeRemoved:              ns.NodeRemoved.CloneAndSet(0),
+		ConnectionsDesync:        ns.ConnectionsDesync.CloneAndSet(0),
+		ConnectionsWaited:        ns.ConnectionsWaited.CloneAndSet(0),
+		ConnectionsWaitTimeouts:  ns.ConnectionsWaitTimeouts.CloneAndSet(0),
+		ConnectionsProbed:        ns.ConnectionsProbed.CloneAndSet(0),
+		ConnectionsProbeFailed:   ns.ConnectionsProbeFailed.CloneAndSet(0),
+		CompressionFallbacks:     ns.CompressionFallbacks.CloneAndSet(0),
 
 		TransactionRetryCount: ns.TransactionRetryCount.CloneAndSet(0),
 		TransactionErrorCount: ns.TransactionErrorCount.CloneAndSet(0),
@@ -175,6 +196,12 @@ func (ns *nodeStats) clone() nodeStats {
 		PartitionMapUpdates:      ns.PartitionMapUpdates.Clone(),
 		NodeAdded:                ns.NodeAdded.Clone(),
 		NodeRemoved:              ns.NodeRemoved.Clone(),
+		ConnectionsDesync:        ns.ConnectionsDesync.Clone(),
+		ConnectionsWaited:        ns.ConnectionsWaited.Clone(),
+		ConnectionsWaitTimeouts:  ns.ConnectionsWaitTimeouts.Clone(),
+		ConnectionsProbed:        ns.ConnectionsProbed.Clone(),
+		ConnectionsProbeFailed:   ns.ConnectionsProbeFailed.Clone(),
+		CompressionFallbacks:     ns.CompressionFallbacks.Clone(),
 
 		TransactionRetryCount: ns.TransactionRetryCount.Clone(),
 		TransactionErrorCount: ns.TransactionErrorCount.Clone(),
@@ -233,6 +260,12 @@ func (ns *nodeStats) aggregate(newStats *nodeStats) {
 	ns.PartitionMapUpdates.AddAndGet(newStats.PartitionMapUpdates.Get())
 	ns.NodeAdded.AddAndGet(newStats.NodeAdded.Get())
 	ns.NodeRemoved.AddAndGet(newStats.NodeRemoved.Get())
+	ns.ConnectionsDesync.AddAndGet(newStats.ConnectionsDesync.Get())
+	ns.ConnectionsWaited.AddAndGet(newStats.ConnectionsWaited.Get())
+	ns.ConnectionsWaitTimeouts.AddAndGet(newStats.ConnectionsWaitTimeouts.Get())
+	ns.ConnectionsProbed.AddAndGet(newStats.ConnectionsProbed.Get())
+	ns.ConnectionsProbeFailed.AddAndGet(newStats.ConnectionsProbeFailed.Get())
+	ns.CompressionFallbacks.AddAndGet(newStats.CompressionFallbacks.Get())
 
 	ns.TransactionRetryCount.AddAndGet(newStats.TransactionRetryCount.Get())
 	ns.TransactionErrorCount.AddAndGet(newStats.TransactionErrorCount.Get())
@@ -272,6 +305,12 @@ func (ns nodeStats) MarshalJSON() ([]byte, error) {
 		PartitionMapUpdates      int `json:"partition-map-updates"`
 		NodeAdded                int `json:"node-added-count"`
 		NodeRemoved              int `json:"node-removed-count"`
+		ConnectionsDesync        int `json:"connections-desync"`
+		ConnectionsWaited        int `json:"connections-waited"`
+		ConnectionsWaitTimeouts  int `json:"connections-wait-timeouts"`
+		ConnectionsProbed        int `json:"connections-probed"`
+		ConnectionsProbeFailed   int `json:"connections-probe-failed"`
+		CompressionFallbacks     int `json:"compression-fallbacks"`
 
 		RetryCount int `json:"transaction-retry-count"`
 		ErrorCount int `json:"transaction-error-count"`
@@ -305,6 +344,12 @@ func (ns nodeStats) MarshalJSON() ([]byte, error) {
 		ns.PartitionMapUpdates.Get(),
 		ns.NodeAdded.Get(),
 		ns.NodeRemoved.Get(),
+		ns.ConnectionsDesync.Get(),
+		ns.ConnectionsWaited.Get(),
+		ns.ConnectionsWaitTimeouts.Get(),
+		ns.ConnectionsProbed.Get(),
+		ns.ConnectionsProbeFailed.Get(),
+		ns.CompressionFallbacks.Get(),
 
 		ns.TransactionRetryCount.Get(),
 		ns.TransactionErrorCount.Get(),
@@ -342,6 +387,12 @@ func (ns *nodeStats) UnmarshalJSON(data []byte) error {
 		PartitionMapUpdates      int `json:"partition-map-updates"`
 		NodeAdded                int `json:"node-added-count"`
 		NodeRemoved              int `json:"node-removed-count"`
+		ConnectionsDesync        int `json:"connections-desync"`
+		ConnectionsWaited        int `json:"connections-waited"`
+		ConnectionsWaitTimeouts  int `json:"connections-wait-timeouts"`
+		ConnectionsProbed        int `json:"connections-probed"`
+		ConnectionsProbeFailed   int `json:"connections-probe-failed"`
+		CompressionFallbacks     int `json:"compression-fallbacks"`
 
 		RetryCount int `json:"transaction-retry-count"`
 		ErrorCount int `json:"transaction-error-count"`
@@ -380,6 +431,12 @@ func (ns *nodeStats) UnmarshalJSON(data []byte) error {
 	ns.PartitionMapUpdates.Set(aux.PartitionMapUpdates)
 	ns.NodeAdded.Set(aux.NodeAdded)
 	ns.NodeRemoved.Set(aux.NodeRemoved)
+	ns.ConnectionsDesync.Set(aux.ConnectionsDesync)
+	ns.ConnectionsWaited.Set(aux.ConnectionsWaited)
+	ns.ConnectionsWaitTimeouts.Set(aux.ConnectionsWaitTimeouts)
+	ns.ConnectionsProbed.Set(aux.ConnectionsProbed)
+	ns.ConnectionsProbeFailed.Set(aux.ConnectionsProbeFailed)
+	ns.CompressionFallbacks.Set(aux.CompressionFallbacks)
 
 	ns.TransactionRetryCount.Set(aux.RetryCount)
 	ns.TransactionErrorCount.Set(aux.ErrorCount)
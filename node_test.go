@@ -361,4 +361,30 @@ var _ = gg.Describe("Aerospike Node Tests", func() {
 
 		})
 	})
+
+	gg.Describe("Cluster tend", func() {
+
+		gg.It("must tend the cluster on demand with bounded concurrency", func() {
+			clientPolicy := as.NewClientPolicy()
+			clientPolicy.TlsConfig = tlsConfig
+			clientPolicy.User = *user
+			clientPolicy.Password = *password
+			clientPolicy.TendInterval = time.Hour
+			clientPolicy.TendConcurrency = 1
+
+			dbHost := as.NewHost(*host, *port)
+			dbHost.TLSName = *nodeTLSName
+
+			client, err := as.NewClientWithPolicyAndHost(clientPolicy, dbHost)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+
+			count := client.Cluster().TendMetrics().Count
+			gm.Expect(client.TendNow()).ToNot(gm.HaveOccurred())
+			gm.Expect(client.Cluster().TendMetrics().Count).To(gm.Equal(count + 1))
+			gm.Expect(client.GetNodes()).ToNot(gm.BeEmpty())
+
+			client.Close()
+			gm.Expect(client.TendNow()).To(gm.HaveOccurred())
+		})
+	})
 })
@@ -48,7 +48,10 @@ type nodeValidator struct {
 
 	sessionInfo *sessionInfo
 
-	features int
+	features    int
+	build       string
+	featureList []string
+	infoCache   map[string]string
 }
 
 func (ndv *nodeValidator) seedNodes(cluster *Cluster, host *Host, nodesToAdd nodesToAddT) Error {
@@ -155,7 +158,7 @@ func (ndv *nodeValidator) validateAlias(cluster *Cluster, alias *Host) Error {
 	if clientPolicy.RequiresAuthentication() {
 		// need to authenticate
 		acmd := newLoginCommand(conn.dataBuffer)
-		err = acmd.login(&clientPolicy, conn, cluster.Password())
+		err = cluster.login(&clientPolicy, conn, alias, acmd)
 		if err != nil {
 			return err
 		}
@@ -163,19 +166,11 @@ func (ndv *nodeValidator) validateAlias(cluster *Cluster, alias *Host) Error {
 		ndv.sessionInfo = acmd.sessionInfo()
 	}
 
-	// check to make sure we have actually connected
-	info, err := conn.RequestInfo("build")
-	if err != nil {
-		return err
-	}
-
-	if _, exists := info["ERROR:80:not authenticated"]; exists {
-		return ErrNotAuthenticated.err()
-	}
-
 	hasClusterName := len(clientPolicy.ClusterName) > 0
 
-	infoKeys := []string{"node", "partition-generation", "features"}
+	// all the values are requested in one round trip; `build` also makes
+	// sure we have actually connected and authenticated
+	infoKeys := []string{"node", "partition-generation", "build", "features", "edition"}
 	if hasClusterName {
 		infoKeys = append(infoKeys, "cluster-name")
 	}
@@ -190,6 +185,10 @@ func (ndv *nodeValidator) validateAlias(cluster *Cluster, alias *Host) Error {
 		return err
 	}
 
+	if _, exists := infoMap["ERROR:80:not authenticated"]; exists {
+		return ErrNotAuthenticated.err()
+	}
+
 	nodeName, exists := infoMap["node"]
 	if !exists {
 		return newError(types.INVALID_NODE_ERROR, "Invalid node alias:"+alias.String())
@@ -217,10 +216,7 @@ func (ndv *nodeValidator) validateAlias(cluster *Cluster, alias *Host) Error {
 		}
 	}
 
-	// set features
-	if features, exists := infoMap["features"]; exists {
-		ndv.setFeatures(features)
-	}
+	ndv.setServerInfo(infoMap)
 
 	// This client requires partition scan support. Partition scans were first
 	// supported in server version 4.9. Do not allow any server node into the
@@ -267,7 +263,7 @@ func (ndv *nodeValidator) validateAlias(cluster *Cluster, alias *Host) Error {
 					if clientPolicy.RequiresAuthentication() {
 						// need to authenticate
 						acmd := newLoginCommand(hconn.dataBuffer)
-						err = acmd.login(&clientPolicy, hconn, cluster.Password())
+						err = cluster.login(&clientPolicy, hconn, h, acmd)
 						if err != nil {
 							continue
 						}
@@ -299,6 +295,24 @@ func (ndv *nodeValidator) validateAlias(cluster *Cluster, alias *Host) Error {
 	return nil
 }
 
+// setServerInfo sets the features and the build of the server from the responses
+// of the `features`, `build` and `edition` info commands.
+func (ndv *nodeValidator) setServerInfo(infoMap map[string]string) {
+	if features, exists := infoMap["features"]; exists {
+		ndv.setFeatures(features)
+		ndv.featureList = parseFeatureList(features)
+	}
+	ndv.build = infoMap["build"]
+	if major, _, ok := parseServerVersion(ndv.build); ok && major >= 7 {
+		ndv.features |= _SUPPORTS_BLOB_INDEX
+	}
+	ndv.infoCache = cacheableInfo(infoMap)
+
+	if strings.Contains(infoMap["edition"], "Enterprise") {
+		ndv.features |= _SUPPORTS_DURABLE_DELETE
+	}
+}
+
 func (ndv *nodeValidator) setFeatures(features string) {
 	featureList := strings.Split(features, ";")
 	for i := range featureList {
@@ -311,6 +325,8 @@ func (ndv *nodeValidator) setFeatures(features string) {
 			ndv.features |= _SUPPORTS_BATCH_ANY
 		case "pquery":
 			ndv.features |= _SUPPORTS_PARTITION_QUERY
+		case "user-agent":
+			ndv.features |= _SUPPORTS_USER_AGENT
 		}
 	}
 }
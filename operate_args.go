@@ -85,6 +85,10 @@ func newOperateArgs(
 
 	if cluster != nil {
 		if write {
+			if err = cluster.clientPolicy.Schemas.ValidateOperations(key, operations...); err != nil {
+				return operateArgs{}, err
+			}
+
 			res.partition, err = PartitionForWrite(cluster, &res.writePolicy.BasePolicy, key)
 			if err != nil {
 				return operateArgs{}, err
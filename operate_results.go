@@ -0,0 +1,98 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import "github.com/aerospike/aerospike-client-go/v7/types"
+
+// OpResult is the result of a single operation of OperateWithResults.
+type OpResult struct {
+	// Op is the operation the result belongs to.
+	Op *Operation
+
+	// BinName is the name of the bin the operation was applied to.
+	// It is empty for operations that do not apply to a bin, like TouchOp.
+	BinName string
+
+	// Value is the value returned by the operation.
+	// It is nil for operations that do not return a value, like most write operations.
+	Value interface{}
+}
+
+// validateOpResultsOps makes sure the results of the operations can be aligned with the operations.
+func validateOpResultsOps(operations []*Operation) Error {
+	for _, op := range operations {
+		if op.opType == _READ && op.binName == "" {
+			return newError(types.PARAMETER_ERROR, "OperateWithResults does not support operations reading all bins. Use GetBinOp for each bin instead")
+		}
+	}
+	return nil
+}
+
+// opResults splits the bins of a record returned for a command with RespondPerEachOp into
+// the results of the operations, in the order of the operations.
+// The bins of the record are converted to the form Operate returns.
+func opResults(operations []*Operation, rec *Record) []OpResult {
+	res := make([]OpResult, len(operations))
+
+	// the index of the next result of each bin
+	next := make(map[string]int, len(rec.Bins))
+	for i, op := range operations {
+		res[i] = OpResult{Op: op, BinName: op.binName}
+
+		v, exists := rec.Bins[op.binName]
+		if !exists {
+			continue
+		}
+
+		if results, ok := v.(OpResults); ok {
+			if n := next[op.binName]; n < len(results) {
+				res[i].Value = results[n]
+			}
+		} else if next[op.binName] == 0 {
+			res[i].Value = v
+		}
+		next[op.binName]++
+	}
+
+	for name, v := range rec.Bins {
+		if results, ok := v.(OpResults); ok {
+			rec.Bins[name] = []interface{}(results)
+		}
+	}
+
+	return res
+}
+
+// OperateWithResults works like Operate, but also returns the result of each operation,
+// in the same order as the operations. Unlike the bins of the returned record, the results
+// are not merged when several operations are applied to the same bin.
+// The policy is copied and RespondPerEachOp is set, so that every operation returns a result.
+// Operations reading all the bins of the record are not supported.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) OperateWithResults(policy *WritePolicy, key *Key, operations ...*Operation) (*Record, []OpResult, Error) {
+	if err := validateOpResultsOps(operations); err != nil {
+		return nil, nil, err
+	}
+
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
+	respondAll := *policy
+	respondAll.RespondPerEachOp = true
+
+	rec, err := clnt.operate(&respondAll, key, true, operations...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rec, opResults(operations, rec), nil
+}
@@ -58,6 +58,8 @@ type Operation struct {
 	ctx []*CDTContext
 
 	encoder func(*Operation, BufferEx) (int, Error)
+	// packed is the value encoded by OperationList.Compile
+	packed []byte
 
 	// binName (Optional) determines the name of bin used in operation.
 	binName string
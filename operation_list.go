@@ -0,0 +1,118 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+// OperationList is a list of operations that is built once and reused in many commands.
+// CDT, bit, HLL and expression operations are packed every time they are sent to the server,
+// twice per command. Compile packs them once, so that commands issuing the same operations
+// on different keys only copy the packed bytes.
+//
+// A compiled list is immutable and safe for concurrent use.
+//
+//	ops := NewOperationList(
+//		ListAppendOp("events", event),
+//		MapIncrementOp(mapPolicy, "counters", "events", 1),
+//	)
+//	if err := ops.Compile(); err != nil {
+//		return err
+//	}
+//
+//	for _, key := range keys {
+//		if _, err := client.Operate(nil, key, ops.Operations()...); err != nil {
+//			return err
+//		}
+//	}
+type OperationList struct {
+	ops      []*Operation
+	compiled []*Operation
+}
+
+// NewOperationList creates an OperationList with the operations.
+func NewOperationList(ops ...*Operation) *OperationList {
+	return &OperationList{ops: ops}
+}
+
+// Add appends the operations to the list. If the list was compiled, it has to be compiled again.
+func (ol *OperationList) Add(ops ...*Operation) *OperationList {
+	ol.ops = append(ol.ops, ops...)
+	ol.compiled = nil
+	return ol
+}
+
+// Len returns the number of operations in the list.
+func (ol *OperationList) Len() int {
+	return len(ol.ops)
+}
+
+// Compile packs the operations of the list. The values of the operations must not be
+// modified after the list is compiled, since the changes would not be sent to the server.
+func (ol *OperationList) Compile() Error {
+	compiled := make([]*Operation, len(ol.ops))
+	for i, op := range ol.ops {
+		cop, err := op.compile()
+		if err != nil {
+			return err
+		}
+		compiled[i] = cop
+	}
+
+	ol.compiled = compiled
+	return nil
+}
+
+// IsCompiled returns true if the list was compiled after the last change.
+func (ol *OperationList) IsCompiled() bool {
+	return ol.compiled != nil || len(ol.ops) == 0
+}
+
+// Operations returns the operations to pass to Operate and the batch commands.
+// The compiled operations are returned if the list was compiled.
+func (ol *OperationList) Operations() []*Operation {
+	if ol.compiled != nil {
+		return ol.compiled
+	}
+	return ol.ops
+}
+
+// compile returns a copy of the operation with its encoded value cached.
+// Simple operations are not encoded and are returned as is.
+func (op *Operation) compile() (*Operation, Error) {
+	if op.encoder == nil || op.packed != nil {
+		return op, nil
+	}
+
+	size, err := op.encoder(op, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := newBuffer(size)
+	if _, err := op.encoder(op, buf); err != nil {
+		return nil, err
+	}
+
+	res := *op
+	res.packed = buf.dataBuffer[:buf.dataOffset]
+	res.encoder = packedOperationEncoder
+	return &res, nil
+}
+
+// packedOperationEncoder writes the value cached by compile.
+func packedOperationEncoder(op *Operation, packer BufferEx) (int, Error) {
+	if packer != nil {
+		return packer.Write(op.packed)
+	}
+	return len(op.packed), nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	as "github.com/aerospike/aerospike-client-go/v7"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+// ALL tests are isolated by SetName and Key, which are 50 random characters
+var _ = gg.Describe("OperationList", func() {
+
+	var ns = *namespace
+	var set = randString(50)
+
+	gg.It("must track the compilation of the list", func() {
+		ops := as.NewOperationList(as.ListAppendOp("list", 1))
+		gm.Expect(ops.IsCompiled()).To(gm.BeFalse())
+
+		gm.Expect(ops.Compile()).ToNot(gm.HaveOccurred())
+		gm.Expect(ops.IsCompiled()).To(gm.BeTrue())
+		gm.Expect(ops.Operations()).To(gm.HaveLen(1))
+
+		ops.Add(as.GetBinOp("list"))
+		gm.Expect(ops.IsCompiled()).To(gm.BeFalse())
+		gm.Expect(ops.Len()).To(gm.Equal(2))
+	})
+
+	gg.It("must apply the compiled operations to many keys", func() {
+		ops := as.NewOperationList(
+			as.ListAppendOp("list", 1, "a"),
+			as.MapPutOp(as.DefaultMapPolicy(), "map", "k", 2),
+			as.PutOp(as.NewBin("bin", 3)),
+			as.GetOp(),
+		)
+		gm.Expect(ops.Compile()).ToNot(gm.HaveOccurred())
+
+		for i := 0; i < 10; i++ {
+			key, err := as.NewKey(ns, set, randString(50))
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+
+			rec, err := client.Operate(nil, key, ops.Operations()...)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{
+				"list": []interface{}{1, "a"},
+				"map":  map[interface{}]interface{}{"k": 2},
+				"bin":  3,
+			}))
+		}
+	})
+})
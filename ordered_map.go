@@ -0,0 +1,102 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// OrderedMap is a map that preserves the order of its entries as stored in the database.
+// The key ordered maps are decoded into OrderedMap when ValuePolicy.DecodeOrderedMaps is set.
+// An OrderedMap can be written back as a value, and is encoded to JSON as an object
+// with its keys in order.
+type OrderedMap []MapPair
+
+// Len returns the number of entries in the map.
+func (om OrderedMap) Len() int {
+	return len(om)
+}
+
+// PackMap packs the entries of the map in order.
+func (om OrderedMap) PackMap(buf BufferEx) (int, error) {
+	size := 0
+	for i := range om {
+		n, err := packObject(buf, om[i].Key, true)
+		size += n
+		if err != nil {
+			return size, err
+		}
+
+		n, err = packObject(buf, om[i].Value, false)
+		size += n
+		if err != nil {
+			return size, err
+		}
+	}
+	return size, nil
+}
+
+// Get returns the value of the key, and if the key exists in the map.
+func (om OrderedMap) Get(key interface{}) (interface{}, bool) {
+	for i := range om {
+		if om[i].Key == key {
+			return om[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// Keys returns the keys of the map in order.
+func (om OrderedMap) Keys() []interface{} {
+	res := make([]interface{}, len(om))
+	for i := range om {
+		res[i] = om[i].Key
+	}
+	return res
+}
+
+// MarshalJSON encodes the map to a JSON object with its keys in order.
+// Keys other than strings are formatted with fmt.Sprint.
+func (om OrderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i := range om {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, ok := om[i].Key.(string)
+		if !ok {
+			key = fmt.Sprint(om[i].Key)
+		}
+
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+
+		v, err := json.Marshal(om[i].Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"encoding/json"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Map decoding", func() {
+
+	gg.AfterEach(func() {
+		SetValuePolicy(nil)
+	})
+
+	// packs a key ordered map, in the order of the pairs
+	packOrdered := func(pairs ...MapPair) []byte {
+		p := newPacker()
+		packMapBegin(p, len(pairs)+1)
+		p.Write([]byte{0xc7, 0x00, 0x01})
+		packNil(p)
+		for i := range pairs {
+			packObject(p, pairs[i].Key, true)
+			packObject(p, pairs[i].Value, false)
+		}
+		return p.Bytes()
+	}
+
+	unpack := func(buf []byte) interface{} {
+		res, err := newUnpacker(buf, 0, len(buf)).UnpackMap()
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		return res
+	}
+
+	packMap := func(m interface{}) []byte {
+		p := newPacker()
+		_, err := packObject(p, m, false)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		return p.Bytes()
+	}
+
+	gg.It("must decode maps into map[interface{}]interface{} and []MapPair by default", func() {
+		gm.Expect(unpack(packMap(map[string]interface{}{"a": 1}))).To(gm.Equal(map[interface{}]interface{}{"a": 1}))
+		gm.Expect(unpack(packOrdered(MapPair{"b", 1}, MapPair{"a", 2}))).To(gm.Equal([]MapPair{{"b", 1}, {"a", 2}}))
+	})
+
+	gg.It("must decode string-keyed maps into map[string]interface{}", func() {
+		SetValuePolicy(&ValuePolicy{DecodeStringKeyedMaps: true})
+
+		m := map[interface{}]interface{}{"a": 1, "nested": map[interface{}]interface{}{"b": []interface{}{1}}}
+		gm.Expect(unpack(packMap(m))).To(gm.Equal(map[string]interface{}{"a": 1, "nested": map[string]interface{}{"b": []interface{}{1}}}))
+		gm.Expect(unpack(packMap(map[interface{}]interface{}{"a": 1, 2: 3}))).To(gm.Equal(map[interface{}]interface{}{"a": 1, 2: 3}))
+		gm.Expect(unpack(packMap(map[interface{}]interface{}{}))).To(gm.Equal(map[string]interface{}{}))
+	})
+
+	gg.It("must decode key ordered maps into OrderedMap", func() {
+		SetValuePolicy(&ValuePolicy{DecodeOrderedMaps: true})
+
+		res := unpack(packOrdered(MapPair{"b", 1}, MapPair{"a", 2}, MapPair{3, "c"}))
+		gm.Expect(res).To(gm.Equal(OrderedMap{{"b", 1}, {"a", 2}, {3, "c"}}))
+
+		om := res.(OrderedMap)
+		gm.Expect(om.Keys()).To(gm.Equal([]interface{}{"b", "a", 3}))
+		v, exists := om.Get("a")
+		gm.Expect(exists).To(gm.BeTrue())
+		gm.Expect(v).To(gm.Equal(2))
+		_, exists = om.Get("z")
+		gm.Expect(exists).To(gm.BeFalse())
+
+		js, err := json.Marshal(om)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(string(js)).To(gm.Equal(`{"b":1,"a":2,"3":"c"}`))
+
+		// written back as a regular map
+		gm.Expect(unpack(packMap(om))).To(gm.Equal(map[interface{}]interface{}{"b": 1, "a": 2, 3: "c"}))
+	})
+})
@@ -251,7 +251,7 @@ func packObject(cmd BufferEx, obj interface{}, mapKey bool) (int, Error) {
 	case uint64:
 		return packAUInt64(cmd, v)
 	case time.Time:
-		return packAInt64(cmd, v.UnixNano())
+		return NewTimeValue(v).pack(cmd)
 	case nil:
 		return packNil(cmd)
 	case bool:
@@ -300,7 +300,12 @@ func packObject(cmd BufferEx, obj interface{}, mapKey bool) (int, Error) {
 	return 0, newError(types.SERIALIZE_ERROR, fmt.Sprintf("Type `%v (%s)` not supported to pack. ", obj, reflect.TypeOf(obj).String()))
 }
 
+// packAUInt64 packs the values beyond math.MaxInt64 according to the
+// IntegerOverflow mode of the ValuePolicy.
 func packAUInt64(cmd BufferEx, val uint64) (int, Error) {
+	if val > math.MaxInt64 {
+		return NewUint64Value(val).pack(cmd)
+	}
 	return packUInt64(cmd, val)
 }
 
@@ -501,6 +506,35 @@ func packInfinity(cmd BufferEx) (int, Error) {
 	return 3, nil
 }
 
+// packExt packs a MessagePack extension, using the fixext formats when possible.
+func packExt(cmd BufferEx, typ byte, data []byte) (int, Error) {
+	var size int
+	switch l := len(data); {
+	case l == 1:
+		size, _ = packAByte(cmd, 0xd4)
+	case l == 2:
+		size, _ = packAByte(cmd, 0xd5)
+	case l == 4:
+		size, _ = packAByte(cmd, 0xd6)
+	case l == 8:
+		size, _ = packAByte(cmd, 0xd7)
+	case l == 16:
+		size, _ = packAByte(cmd, 0xd8)
+	case l <= math.MaxUint8:
+		size, _ = packByte(cmd, 0xc7, byte(l))
+	case l <= math.MaxUint16:
+		size, _ = packShort(cmd, 0xc8, int16(uint16(l)))
+	default:
+		size, _ = packInt(cmd, 0xc9, int32(uint32(l)))
+	}
+
+	if cmd != nil {
+		cmd.WriteByte(typ)
+		cmd.Write(data)
+	}
+	return size + 1 + len(data), nil
+}
+
 func packWildCard(cmd BufferEx) (int, Error) {
 	if cmd != nil {
 		cmd.WriteByte(byte(0xd4))
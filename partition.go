@@ -54,10 +54,16 @@ func NewPartitionForReplicaPolicy(namespace string, replica ReplicaPolicy) *Part
 	}
 }
 
+// PartitionForKey returns the id of the partition that the key belongs to.
+// The id is in the range [0, 4096) and does not depend on the state of the cluster.
+func PartitionForKey(key *Key) int {
+	return key.PartitionId()
+}
+
 // PartitionForWrite returns a partition for write purposes
 func PartitionForWrite(cluster *Cluster, policy *BasePolicy, key *Key) (*Partition, Error) {
 	// Must copy hashmap reference for copy on write semantics to work.
-	pmap := cluster.getPartitions()
+	pmap := cluster.commandPartitions()
 	partitions := pmap[key.namespace]
 
 	if partitions == nil {
@@ -69,12 +75,21 @@ func PartitionForWrite(cluster *Cluster, policy *BasePolicy, key *Key) (*Partiti
 
 // PartitionForRead returns a partition for read purposes
 func PartitionForRead(cluster *Cluster, policy *BasePolicy, key *Key) (*Partition, Error) {
+	ptn := new(Partition)
+	if err := ptn.setForRead(cluster, policy, key); err != nil {
+		return nil, err
+	}
+	return ptn, nil
+}
+
+// setForRead initializes the partition in place for read purposes.
+func (ptn *Partition) setForRead(cluster *Cluster, policy *BasePolicy, key *Key) Error {
 	// Must copy hashmap reference for copy on write semantics to work.
-	pmap := cluster.getPartitions()
+	pmap := cluster.commandPartitions()
 	partitions := pmap[key.namespace]
 
 	if partitions == nil {
-		return nil, newInvalidNamespaceError(key.namespace, len(pmap))
+		return newInvalidNamespaceError(key.namespace, len(pmap))
 	}
 
 	var replica ReplicaPolicy
@@ -101,7 +116,15 @@ func PartitionForRead(cluster *Cluster, policy *BasePolicy, key *Key) (*Partitio
 		replica = policy.ReplicaPolicy
 		linearize = false
 	}
-	return NewPartition(partitions, key, replica, nil, linearize), nil
+
+	*ptn = Partition{
+		partitions:  partitions,
+		Namespace:   key.Namespace(),
+		replica:     replica,
+		linearize:   linearize,
+		PartitionId: key.PartitionId(),
+	}
+	return nil
 }
 
 // GetReplicaPolicySC returns a ReplicaPolicy based on different variables in SC mode
@@ -124,7 +147,7 @@ func GetReplicaPolicySC(policy *BasePolicy) ReplicaPolicy {
 // GetNodeBatchRead returns a node for batch reads
 func GetNodeBatchRead(cluster *Cluster, key *Key, replica ReplicaPolicy, replicaSC ReplicaPolicy, prevNode *Node, sequence int, sequenceSC int) (*Node, Error) {
 	// Must copy hashmap reference for copy on write semantics to work.
-	pmap := cluster.getPartitions()
+	pmap := cluster.commandPartitions()
 	partitions := pmap[key.namespace]
 
 	if partitions == nil {
@@ -144,7 +167,7 @@ func GetNodeBatchRead(cluster *Cluster, key *Key, replica ReplicaPolicy, replica
 // GetNodeBatchWrite returns a node for batch Writes
 func GetNodeBatchWrite(cluster *Cluster, key *Key, replica ReplicaPolicy, prevNode *Node, sequence int) (*Node, Error) {
 	// Must copy hashmap reference for copy on write semantics to work.
-	pmap := cluster.getPartitions()
+	pmap := cluster.commandPartitions()
 	partitions := pmap[key.namespace]
 
 	if partitions == nil {
@@ -176,6 +199,9 @@ func (ptn *Partition) GetNodeRead(cluster *Cluster) (*Node, Error) {
 
 	case RANDOM:
 		return cluster.GetRandomNode()
+
+	case LEAST_LOADED:
+		return ptn.getLeastLoadedNode(cluster)
 	}
 }
 
@@ -187,6 +213,8 @@ func (ptn *Partition) GetNodeWrite(cluster *Cluster) (*Node, Error) {
 	case SEQUENCE:
 		fallthrough
 	case PREFER_RACK:
+		fallthrough
+	case LEAST_LOADED:
 		return ptn.getSequenceNode(cluster)
 
 	case MASTER:
@@ -230,18 +258,34 @@ func (ptn *Partition) PrepareRetryWrite(isClientTimeout bool) {
 	}
 }
 
+// Quiesced nodes are skipped by the node selection, and are only returned
+// when no other replica of the partition is active.
+
 func (ptn *Partition) getSequenceNode(cluster *Cluster) (*Node, Error) {
 	replicas := ptn.partitions.Replicas
 
+	var quiesced *Node
+	quiescedSeq := 0
 	for range replicas {
 		index := ptn.sequence % len(replicas)
 		node := replicas[index][ptn.PartitionId]
 
 		if node != nil && node.IsActive() {
-			return node, nil
+			if !node.IsQuiesced() {
+				return node, nil
+			}
+			if quiesced == nil {
+				quiesced, quiescedSeq = node, ptn.sequence
+			}
 		}
 		ptn.sequence++
 	}
+
+	if quiesced != nil {
+		ptn.sequence = quiescedSeq
+		return quiesced, nil
+	}
+
 	nodeArray := cluster.GetNodes()
 	return nil, newInvalidNodeError(len(nodeArray), ptn)
 }
@@ -255,7 +299,7 @@ func (ptn *Partition) getRackNode(cluster *Cluster) (*Node, Error) {
 			index := ptn.sequence % len(replicas)
 			node := replicas[index][ptn.PartitionId]
 
-			if node != nil && node != ptn.prevNode && node.hasRack(ptn.Namespace, rackId) && node.IsActive() {
+			if node != nil && node != ptn.prevNode && node.hasRack(ptn.Namespace, rackId) && node.IsActive() && !node.IsQuiesced() {
 				ptn.prevNode = node
 				ptn.sequence = seq
 				return node, nil
@@ -264,25 +308,26 @@ func (ptn *Partition) getRackNode(cluster *Cluster) (*Node, Error) {
 		}
 	}
 
-	for range replicas {
-		index := ptn.sequence % len(replicas)
-		node := replicas[index][ptn.PartitionId]
-
-		if node != nil && node.IsActive() {
-			ptn.prevNode = node
-			return node, nil
-		}
-		ptn.sequence++
+	node, err := ptn.getSequenceNode(cluster)
+	if err != nil {
+		return nil, err
 	}
-
-	nodeArray := cluster.GetNodes()
-	return nil, newInvalidNodeError(len(nodeArray), ptn)
+	ptn.prevNode = node
+	return node, nil
 }
 
 func (ptn *Partition) getMasterNode(cluster *Cluster) (*Node, Error) {
 	node := ptn.partitions.Replicas[0][ptn.PartitionId]
 
 	if node != nil && node.IsActive() {
+		if node.IsQuiesced() {
+			// the server proxies the commands to the master
+			for _, replica := range ptn.partitions.Replicas[1:] {
+				if n := replica[ptn.PartitionId]; n != nil && n.IsActive() && !n.IsQuiesced() {
+					return n, nil
+				}
+			}
+		}
 		return node, nil
 	}
 	nodeArray := cluster.GetNodes()
@@ -292,14 +337,64 @@ func (ptn *Partition) getMasterNode(cluster *Cluster) (*Node, Error) {
 func (ptn *Partition) getMasterProlesNode(cluster *Cluster) (*Node, Error) {
 	replicas := ptn.partitions.Replicas
 
+	var quiesced *Node
 	for range replicas {
 		index := cluster.replicaIndex.IncrementAndGet() % len(replicas)
 		node := replicas[index][ptn.PartitionId]
 
 		if node != nil && node.IsActive() {
-			return node, nil
+			if !node.IsQuiesced() {
+				return node, nil
+			}
+			quiesced = node
 		}
 	}
+
+	if quiesced != nil {
+		return quiesced, nil
+	}
+
+	nodeArray := cluster.GetNodes()
+	return nil, newInvalidNodeError(len(nodeArray), ptn)
+}
+
+func (ptn *Partition) getLeastLoadedNode(cluster *Cluster) (*Node, Error) {
+	replicas := ptn.partitions.Replicas
+
+	var best, quiesced *Node
+	bestInFlight := 0
+	for i := range replicas {
+		node := replicas[i][ptn.PartitionId]
+		if node == nil || !node.IsActive() {
+			continue
+		}
+
+		if node.IsQuiesced() {
+			if quiesced == nil {
+				quiesced = node
+			}
+			continue
+		}
+
+		// avoid the node of the previous attempt, unless it is the only one left
+		if node == ptn.prevNode && best != nil {
+			continue
+		}
+
+		if inFlight := node.InFlightCommands(); best == nil || best == ptn.prevNode || inFlight < bestInFlight {
+			best, bestInFlight = node, inFlight
+		}
+	}
+
+	if best == nil {
+		best = quiesced
+	}
+
+	if best != nil {
+		ptn.prevNode = best
+		return best, nil
+	}
+
 	nodeArray := cluster.GetNodes()
 	return nil, newInvalidNodeError(len(nodeArray), ptn)
 }
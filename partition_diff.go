@@ -0,0 +1,129 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// partitionOwnershipChange describes a number of partitions of a namespace replica
+// that moved from one node to another between two partition maps.
+// Empty node names denote partitions without an owner.
+type partitionOwnershipChange struct {
+	namespace string
+	replica   int
+	from, to  string
+	count     int
+}
+
+// String implements the Stringer interface.
+func (c *partitionOwnershipChange) String() string {
+	from, to := c.from, c.to
+	if from == "" {
+		from = "<none>"
+	}
+	if to == "" {
+		to = "<none>"
+	}
+	return fmt.Sprintf("ns=%s replica=%d from=%s to=%s partitions=%d", c.namespace, c.replica, from, to, c.count)
+}
+
+// diffPartitionMaps compares the ownership of the partitions in two partition maps,
+// and returns the changes aggregated by namespace, replica and nodes, sorted in that order.
+// Namespaces and replicas that only exist in one of the maps are reported as moving
+// from or to no owner.
+func diffPartitionMaps(oldMap, newMap partitionMap) []partitionOwnershipChange {
+	type changeKey struct {
+		namespace string
+		replica   int
+		from, to  string
+	}
+
+	counts := map[changeKey]int{}
+	diff := func(ns string, oldParts, newParts *Partitions) {
+		replicaCount := 0
+		if oldParts != nil {
+			replicaCount = len(oldParts.Replicas)
+		}
+		if newParts != nil && len(newParts.Replicas) > replicaCount {
+			replicaCount = len(newParts.Replicas)
+		}
+
+		for replica := 0; replica < replicaCount; replica++ {
+			for partition := 0; partition < _PARTITIONS; partition++ {
+				from := partitionOwner(oldParts, replica, partition)
+				to := partitionOwner(newParts, replica, partition)
+				if from != to {
+					counts[changeKey{namespace: ns, replica: replica, from: from, to: to}]++
+				}
+			}
+		}
+	}
+
+	for ns, oldParts := range oldMap {
+		diff(ns, oldParts, newMap[ns])
+	}
+
+	for ns, newParts := range newMap {
+		if _, exists := oldMap[ns]; !exists {
+			diff(ns, nil, newParts)
+		}
+	}
+
+	res := make([]partitionOwnershipChange, 0, len(counts))
+	for k, count := range counts {
+		res = append(res, partitionOwnershipChange{namespace: k.namespace, replica: k.replica, from: k.from, to: k.to, count: count})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		a, b := &res[i], &res[j]
+		if a.namespace != b.namespace {
+			return a.namespace < b.namespace
+		}
+		if a.replica != b.replica {
+			return a.replica < b.replica
+		}
+		if a.from != b.from {
+			return a.from < b.from
+		}
+		return a.to < b.to
+	})
+
+	return res
+}
+
+// partitionOwner returns the name of the node owning the partition replica, or an empty string.
+func partitionOwner(p *Partitions, replica, partition int) string {
+	if p == nil || replica >= len(p.Replicas) || partition >= len(p.Replicas[replica]) {
+		return ""
+	}
+
+	if node := p.Replicas[replica][partition]; node != nil {
+		return node.GetName()
+	}
+	return ""
+}
+
+// formatPartitionDiff formats the ownership changes for logging, one change per line.
+func formatPartitionDiff(changes []partitionOwnershipChange) string {
+	var sb strings.Builder
+	for i := range changes {
+		sb.WriteString("\n\t")
+		sb.WriteString(changes[i].String())
+	}
+	return sb.String()
+}
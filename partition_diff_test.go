@@ -0,0 +1,72 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Partition map diff tests", func() {
+
+	nodeA, nodeB := &Node{name: "A"}, &Node{name: "B"}
+
+	fill := func(p *Partitions, replica int, node *Node) {
+		for i := range p.Replicas[replica] {
+			p.Replicas[replica][i] = node
+		}
+	}
+
+	gg.It("must not report changes for identical maps", func() {
+		p := newPartitions(_PARTITIONS, 2, false)
+		fill(p, 0, nodeA)
+		fill(p, 1, nodeB)
+
+		oldMap := partitionMap{"test": p}
+		gm.Expect(diffPartitionMaps(oldMap, oldMap.clone())).To(gm.BeEmpty())
+	})
+
+	gg.It("must aggregate the moved partitions by namespace, replica and nodes", func() {
+		p := newPartitions(_PARTITIONS, 2, false)
+		fill(p, 0, nodeA)
+		fill(p, 1, nodeB)
+
+		oldMap := partitionMap{"test": p}
+		newMap := oldMap.clone()
+		for i := 0; i < 10; i++ {
+			newMap["test"].Replicas[0][i] = nodeB
+			newMap["test"].Replicas[1][i] = nodeA
+		}
+		newMap["test"].Replicas[1][10] = nil
+
+		changes := diffPartitionMaps(oldMap, newMap)
+		gm.Expect(changes).To(gm.Equal([]partitionOwnershipChange{
+			{namespace: "test", replica: 0, from: "A", to: "B", count: 10},
+			{namespace: "test", replica: 1, from: "B", to: "", count: 1},
+			{namespace: "test", replica: 1, from: "B", to: "A", count: 10},
+		}))
+	})
+
+	gg.It("must report added namespaces as moving from no owner", func() {
+		p := newPartitions(_PARTITIONS, 1, false)
+		fill(p, 0, nodeA)
+
+		changes := diffPartitionMaps(partitionMap{}, partitionMap{"test": p})
+		gm.Expect(changes).To(gm.Equal([]partitionOwnershipChange{
+			{namespace: "test", replica: 0, from: "", to: "A", count: _PARTITIONS},
+		}))
+		gm.Expect(formatPartitionDiff(changes)).To(gm.Equal("\n\tns=test replica=0 from=<none> to=A partitions=4096"))
+	})
+})
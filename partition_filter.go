@@ -18,6 +18,7 @@ package aerospike
 import (
 	"bytes"
 	"encoding/gob"
+	"sort"
 
 	"github.com/aerospike/aerospike-client-go/v7/types"
 )
@@ -56,6 +57,31 @@ func NewPartitionFilterByRange(begin, count int) *PartitionFilter {
 	return newPartitionFilter(begin, count)
 }
 
+// NewPartitionFilterByIds creates a partition filter for an explicit list of partition ids.
+// Partition ids are between 0 - 4095. Duplicate ids are ignored.
+// Combined with Client.ScanNodePartitions and Client.QueryNodePartitions, it allows
+// distributing the partitions across workers deterministically, and retrying only
+// the partitions that failed.
+func NewPartitionFilterByIds(partitionIds []int) *PartitionFilter {
+	ids := make([]int, len(partitionIds))
+	copy(ids, partitionIds)
+	sort.Ints(ids)
+
+	parts := make([]*PartitionStatus, 0, len(ids))
+	for i, id := range ids {
+		if i > 0 && id == ids[i-1] {
+			continue
+		}
+		parts = append(parts, newPartitionStatus(id))
+	}
+
+	pf := &PartitionFilter{Count: len(parts), Partitions: parts, Retry: true}
+	if len(parts) > 0 {
+		pf.Begin = parts[0].Id
+	}
+	return pf
+}
+
 // NewPartitionFilterByKey returns records after the key's digest in the partition containing the digest.
 // Records in all other partitions are not included. The digest is used to determine
 // order and this is not the same as userKey order.
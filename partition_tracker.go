@@ -26,6 +26,7 @@ import (
 
 type partitionTracker struct {
 	partitions          []*PartitionStatus
+	partitionIndex      map[int]*PartitionStatus // only set if the partitions are not a contiguous range
 	partitionsCapacity  int
 	partitionBegin      int
 	nodeCapacity        int
@@ -129,10 +130,55 @@ func newPartitionTracker(policy *MultiPolicy, filter *PartitionFilter, nodes []*
 
 	pt.partitions = filter.Partitions
 	pt.partitionFilter = filter
+	pt.indexPartitions()
 	pt.init(policy)
 	return pt
 }
 
+// indexPartitions builds an index of the partitions by id if they
+// are not a contiguous range starting at partitionBegin.
+func (pt *partitionTracker) indexPartitions() {
+	contiguous := true
+	for i, ps := range pt.partitions {
+		if ps.Id != pt.partitionBegin+i {
+			contiguous = false
+			break
+		}
+	}
+
+	if contiguous {
+		return
+	}
+
+	pt.partitionIndex = make(map[int]*PartitionStatus, len(pt.partitions))
+	for _, ps := range pt.partitions {
+		if ps.Id < 0 || ps.Id >= _PARTITIONS {
+			panic(newError(types.PARAMETER_ERROR, fmt.Sprintf("Invalid partition id %d . Valid range: 0-%d", ps.Id, (_PARTITIONS-1))))
+		}
+		pt.partitionIndex[ps.Id] = ps
+	}
+}
+
+// partitionStatus returns the status of the partition, or nil if the partition is not tracked.
+func (pt *partitionTracker) partitionStatus(partitionId int) *PartitionStatus {
+	if pt.partitionIndex != nil {
+		return pt.partitionIndex[partitionId]
+	}
+
+	if i := partitionId - pt.partitionBegin; i >= 0 && i < len(pt.partitions) {
+		return pt.partitions[i]
+	}
+	return nil
+}
+
+// newPartitionTrackerForNodeFilter creates a tracker for the partitions in the filter
+// that are owned by the node.
+func newPartitionTrackerForNodeFilter(policy *MultiPolicy, filter *PartitionFilter, nodeFilter *Node) *partitionTracker {
+	pt := newPartitionTracker(policy, filter, []*Node{nodeFilter})
+	pt.nodeFilter = nodeFilter
+	return pt
+}
+
 func (pt *partitionTracker) init(policy *MultiPolicy) {
 	pt.sleepBetweenRetries = policy.SleepBetweenRetries
 	pt.socketTimeout = policy.SocketTimeout
@@ -171,7 +217,7 @@ func (pt *partitionTracker) SetSleepBetweenRetries(sleepBetweenRetries time.Dura
 func (pt *partitionTracker) assignPartitionsToNodes(cluster *Cluster, namespace string) ([]*nodePartitions, Error) {
 	list := make([]*nodePartitions, 0, pt.nodeCapacity)
 
-	pMap := cluster.getPartitions()
+	pMap := cluster.commandPartitions()
 	parts := pMap[namespace]
 
 	if parts == nil {
@@ -221,9 +267,50 @@ func (pt *partitionTracker) assignPartitionsToNodes(cluster *Cluster, namespace
 		pt.partitionFilter.Retry = true
 	}
 
+	pt.distributeMaxRecords(list)
+
+	pt.nodePartitionsList = list
+	return list, nil
+}
+
+// assignPartitionsToRanges assigns the partitions to query to contiguous partition ranges.
+// It is used by the proxy client, which cannot send commands to the nodes directly.
+func (pt *partitionTracker) assignPartitionsToRanges() []*nodePartitions {
+	var list []*nodePartitions
+	var np *nodePartitions
+
+	retry := (pt.partitionFilter == nil || pt.partitionFilter.Retry) && (pt.iteration == 1)
+	lastId := -1
+
+	for _, part := range pt.partitions {
+		if retry || part.Retry {
+			if np == nil || part.Id != lastId+1 {
+				np = newNodePartitions(nil, 0)
+				list = append(list, np)
+			}
+			np.addPartition(part)
+			part.Retry = false
+			lastId = part.Id
+		}
+	}
+
+	// See assignPartitionsToNodes.
+	if pt.partitionFilter != nil {
+		pt.partitionFilter.Retry = true
+	}
+
+	pt.distributeMaxRecords(list)
+
+	pt.nodePartitionsList = list
+	return list
+}
+
+// distributeMaxRecords distributes maxRecords across the node partitions.
+func (pt *partitionTracker) distributeMaxRecords(list []*nodePartitions) {
+	nodeSize := len(list)
 	pt.recordCount = nil
 
-	if pt.maxRecords > 0 {
+	if pt.maxRecords > 0 && nodeSize > 0 {
 		if pt.maxRecords >= int64(nodeSize) {
 			// Distribute maxRecords across nodes.
 			max := pt.maxRecords / int64(nodeSize)
@@ -247,9 +334,6 @@ func (pt *partitionTracker) assignPartitionsToNodes(cluster *Cluster, namespace
 			pt.recordCount = atmc.NewInt(0)
 		}
 	}
-
-	pt.nodePartitionsList = list
-	return list, nil
 }
 
 func (pt *partitionTracker) findNode(list []*nodePartitions, node *Node) *nodePartitions {
@@ -263,7 +347,7 @@ func (pt *partitionTracker) findNode(list []*nodePartitions, node *Node) *nodePa
 }
 
 func (pt *partitionTracker) partitionUnavailable(nodePartitions *nodePartitions, partitionId int) {
-	ps := pt.partitions[partitionId-pt.partitionBegin]
+	ps := pt.partitionStatus(partitionId)
 	ps.Retry = true
 	ps.sequence++
 	nodePartitions.partsUnavailable++
@@ -271,7 +355,7 @@ func (pt *partitionTracker) partitionUnavailable(nodePartitions *nodePartitions,
 
 func (pt *partitionTracker) setDigest(nodePartitions *nodePartitions, key *Key) {
 	partitionId := key.PartitionId()
-	pt.partitions[partitionId-pt.partitionBegin].Digest = key.Digest()
+	pt.partitionStatus(partitionId).Digest = key.Digest()
 
 	// nodePartitions is nil in Proxy client
 	if nodePartitions != nil {
@@ -281,10 +365,10 @@ func (pt *partitionTracker) setDigest(nodePartitions *nodePartitions, key *Key)
 
 func (pt *partitionTracker) setLast(nodePartitions *nodePartitions, key *Key, bval *int64) {
 	partitionId := key.PartitionId()
-	if partitionId-pt.partitionBegin < 0 {
+	ps := pt.partitionStatus(partitionId)
+	if ps == nil {
 		panic(fmt.Sprintf("Partition mismatch: key.partitionId: %d, partitionBegin: %d", partitionId, pt.partitionBegin))
 	}
-	ps := pt.partitions[partitionId-pt.partitionBegin]
 	ps.Digest = key.digest[:]
 	if bval != nil {
 		ps.BVal = *bval
@@ -17,6 +17,7 @@ package aerospike
 import (
 	"bytes"
 	"fmt"
+	"sort"
 	"strconv"
 
 	"github.com/aerospike/aerospike-client-go/v7/types"
@@ -186,3 +187,71 @@ func (pm partitionMap) validate() Error {
 
 	return nil
 }
+
+// masterPartitions returns the ids of the partitions owned by each node as master, in ascending order.
+// Partitions without an active master node are not listed.
+func (p *Partitions) masterPartitions() map[*Node][]int {
+	res := make(map[*Node][]int)
+	if len(p.Replicas) == 0 {
+		return res
+	}
+
+	for partitionID, node := range p.Replicas[0] {
+		if node.IsActive() {
+			res[node] = append(res[node], partitionID)
+		}
+	}
+	return res
+}
+
+// NamespacePartitionHealth reports the partitions of a namespace that have no node assigned
+// in the client partition map. Commands on these partitions will fail until the map is refreshed.
+type NamespacePartitionHealth struct {
+	// Namespace is the name of the namespace.
+	Namespace string
+
+	// ReplicaCount is the number of replicas, including the master, of the namespace.
+	ReplicaCount int
+
+	// MissingMasters is the number of partitions without a master node.
+	MissingMasters int
+
+	// MissingReplicas is the number of partition replicas, excluding the masters, without a node.
+	MissingReplicas int
+}
+
+// Healthy returns true if all the partitions of the namespace have all their replicas assigned.
+func (h *NamespacePartitionHealth) Healthy() bool {
+	return h.MissingMasters == 0 && h.MissingReplicas == 0
+}
+
+// health returns the health of each namespace in the partition map, sorted by namespace.
+func (pm partitionMap) health() []NamespacePartitionHealth {
+	res := make([]NamespacePartitionHealth, 0, len(pm))
+	for nsName, partition := range pm {
+		h := NamespacePartitionHealth{
+			Namespace:    nsName,
+			ReplicaCount: len(partition.Replicas),
+		}
+
+		for replica, partitionNodes := range partition.Replicas {
+			missing := _PARTITIONS - len(partitionNodes)
+			for _, node := range partitionNodes {
+				if node == nil {
+					missing++
+				}
+			}
+
+			if replica == 0 {
+				h.MissingMasters += missing
+			} else {
+				h.MissingReplicas += missing
+			}
+		}
+
+		res = append(res, h)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Namespace < res[j].Namespace })
+	return res
+}
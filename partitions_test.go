@@ -0,0 +1,275 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Partition map health tests", func() {
+
+	node := &Node{name: "A"}
+
+	gg.It("must count the partitions without nodes per namespace", func() {
+		full := newPartitions(_PARTITIONS, 2, false)
+		partial := newPartitions(_PARTITIONS, 2, false)
+		for r := range full.Replicas {
+			for i := range full.Replicas[r] {
+				full.Replicas[r][i] = node
+				partial.Replicas[r][i] = node
+			}
+		}
+		partial.Replicas[0][1] = nil
+		partial.Replicas[1][1] = nil
+		partial.Replicas[1][2] = nil
+
+		pm := partitionMap{"b": partial, "a": full}
+		gm.Expect(pm.validate()).To(gm.HaveOccurred())
+
+		health := pm.health()
+		gm.Expect(health).To(gm.Equal([]NamespacePartitionHealth{
+			{Namespace: "a", ReplicaCount: 2},
+			{Namespace: "b", ReplicaCount: 2, MissingMasters: 1, MissingReplicas: 2},
+		}))
+		gm.Expect(health[0].Healthy()).To(gm.BeTrue())
+		gm.Expect(health[1].Healthy()).To(gm.BeFalse())
+	})
+
+	gg.Context("Sharding helpers", func() {
+
+		nodeA := &Node{name: "A"}
+		nodeB := &Node{name: "B"}
+		nodeA.active.Set(true)
+		nodeB.active.Set(true)
+
+		newShardedCluster := func() *Cluster {
+			partitions := newPartitions(_PARTITIONS, 1, false)
+			for i := range partitions.Replicas[0] {
+				if i%2 == 0 {
+					partitions.Replicas[0][i] = nodeA
+				} else {
+					partitions.Replicas[0][i] = nodeB
+				}
+			}
+			// a partition without a master
+			partitions.Replicas[0][1] = nil
+
+			clstr := &Cluster{}
+			clstr.partitionWriteMap.Set(partitionMap{"test": partitions})
+			return clstr
+		}
+
+		gg.It("must compute the partition id of a key", func() {
+			key, err := NewKey("test", "set", 1)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(PartitionForKey(key)).To(gm.Equal(key.PartitionId()))
+			gm.Expect(PartitionForKey(key)).To(gm.BeNumerically("<", _PARTITIONS))
+		})
+
+		gg.It("must list the partitions owned by each node", func() {
+			clstr := newShardedCluster()
+
+			res, err := clstr.NodePartitions("test")
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(res).To(gm.HaveLen(2))
+			gm.Expect(res[nodeA]).To(gm.HaveLen(_PARTITIONS / 2))
+			gm.Expect(res[nodeB]).To(gm.HaveLen(_PARTITIONS/2 - 1))
+			gm.Expect(res[nodeA][:2]).To(gm.Equal([]int{0, 2}))
+			gm.Expect(res[nodeB][:2]).To(gm.Equal([]int{3, 5}))
+
+			_, err = clstr.NodePartitions("missing")
+			gm.Expect(err).To(gm.HaveOccurred())
+			gm.Expect(err.Matches(types.INVALID_NAMESPACE)).To(gm.BeTrue())
+		})
+
+		gg.It("must group the keys by their master node", func() {
+			clstr := newShardedCluster()
+
+			var keys []*Key
+			for i := 0; len(keys) < 100; i++ {
+				key, err := NewKey("test", "set", i)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				if key.PartitionId() != 1 {
+					keys = append(keys, key)
+				}
+			}
+
+			res, err := clstr.GroupKeysByNode(keys)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(len(res[nodeA]) + len(res[nodeB])).To(gm.Equal(len(keys)))
+			for node, nodeKeys := range res {
+				prev := -1
+				for _, key := range nodeKeys {
+					gm.Expect(node.name == "A").To(gm.Equal(key.PartitionId()%2 == 0))
+
+					idx := -1
+					for i := range keys {
+						if keys[i] == key {
+							idx = i
+						}
+					}
+					gm.Expect(idx).To(gm.BeNumerically(">", prev))
+					prev = idx
+				}
+			}
+
+			key, _ := NewKey("missing", "set", 1)
+			_, err = clstr.GroupKeysByNode([]*Key{key})
+			gm.Expect(err.Matches(types.INVALID_NAMESPACE)).To(gm.BeTrue())
+		})
+
+		gg.It("must fail to group a key without a master node", func() {
+			clstr := newShardedCluster()
+
+			for i := 0; ; i++ {
+				key, _ := NewKey("test", "set", i)
+				if key.PartitionId() == 1 {
+					_, err := clstr.GroupKeysByNode([]*Key{key})
+					gm.Expect(err).To(gm.HaveOccurred())
+					break
+				}
+			}
+		})
+	})
+
+	gg.Context("Quiesced nodes", func() {
+
+		var clstr *Cluster
+		var nodeA, nodeB *Node
+		var key *Key
+
+		gg.BeforeEach(func() {
+			clstr = &Cluster{quiescedNodes: map[string]struct{}{}, clientPolicy: *NewClientPolicy()}
+			nodeA = &Node{cluster: clstr, name: "A"}
+			nodeB = &Node{cluster: clstr, name: "B"}
+			nodeA.active.Set(true)
+			nodeB.active.Set(true)
+			clstr.nodes.Set([]*Node{nodeA, nodeB})
+
+			partitions := newPartitions(_PARTITIONS, 2, false)
+			for i := range partitions.Replicas[0] {
+				partitions.Replicas[0][i] = nodeA
+				partitions.Replicas[1][i] = nodeB
+			}
+			clstr.partitionWriteMap.Set(partitionMap{"test": partitions})
+
+			key, _ = NewKey("test", "set", 1)
+		})
+
+		nodeFor := func(replica ReplicaPolicy, write bool) *Node {
+			ptn := NewPartition(clstr.getPartitions()["test"], key, replica, nil, false)
+			var node *Node
+			var err Error
+			if write {
+				node, err = ptn.GetNodeWrite(clstr)
+			} else {
+				node, err = ptn.GetNodeRead(clstr)
+			}
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			return node
+		}
+
+		gg.It("must route the commands to the other replicas", func() {
+			gm.Expect(clstr.QuiesceNode("A")).ToNot(gm.HaveOccurred())
+			gm.Expect(nodeA.IsQuiesced()).To(gm.BeTrue())
+
+			for _, replica := range []ReplicaPolicy{SEQUENCE, MASTER, MASTER_PROLES, PREFER_RACK, LEAST_LOADED} {
+				gm.Expect(nodeFor(replica, false)).To(gm.BeIdenticalTo(nodeB))
+				gm.Expect(nodeFor(replica, true)).To(gm.BeIdenticalTo(nodeB))
+			}
+			for i := 0; i < 4; i++ {
+				gm.Expect(clstr.GetRandomNode()).To(gm.BeIdenticalTo(nodeB))
+			}
+
+			clstr.UnquiesceNode("A")
+			gm.Expect(nodeFor(SEQUENCE, true)).To(gm.BeIdenticalTo(nodeA))
+		})
+
+		gg.It("must use the quiesced nodes when no other replica is active", func() {
+			gm.Expect(clstr.QuiesceNode("A")).ToNot(gm.HaveOccurred())
+			gm.Expect(clstr.QuiesceNode("B")).ToNot(gm.HaveOccurred())
+			gm.Expect(nodeFor(SEQUENCE, false)).To(gm.BeIdenticalTo(nodeA))
+			gm.Expect(nodeFor(MASTER, true)).To(gm.BeIdenticalTo(nodeA))
+
+			clstr.UnquiesceNode("B")
+			nodeB.active.Set(false)
+			gm.Expect(nodeFor(SEQUENCE, false)).To(gm.BeIdenticalTo(nodeA))
+
+			gm.Expect(clstr.QuiesceNode("C").Matches(types.INVALID_NODE_ERROR)).To(gm.BeTrue())
+		})
+
+		gg.It("must keep the nodes quiesced when they rejoin the cluster", func() {
+			gm.Expect(clstr.QuiesceNode("A")).ToNot(gm.HaveOccurred())
+			gm.Expect(newNode(clstr, &nodeValidator{name: "A"}).IsQuiesced()).To(gm.BeTrue())
+			gm.Expect(newNode(clstr, &nodeValidator{name: "B"}).IsQuiesced()).To(gm.BeFalse())
+		})
+	})
+
+	gg.Context("Least loaded replica", func() {
+
+		var clstr *Cluster
+		var nodeA, nodeB *Node
+		var ptn *Partition
+
+		gg.BeforeEach(func() {
+			clstr = &Cluster{quiescedNodes: map[string]struct{}{}}
+			nodeA = &Node{cluster: clstr, name: "A"}
+			nodeB = &Node{cluster: clstr, name: "B"}
+			nodeA.active.Set(true)
+			nodeB.active.Set(true)
+
+			partitions := newPartitions(_PARTITIONS, 2, false)
+			for i := range partitions.Replicas[0] {
+				partitions.Replicas[0][i] = nodeA
+				partitions.Replicas[1][i] = nodeB
+			}
+
+			key, _ := NewKey("test", "set", 1)
+			ptn = NewPartition(partitions, key, LEAST_LOADED, nil, false)
+		})
+
+		gg.It("must read from the replica with the fewest commands in flight", func() {
+			// ties go to the master
+			node, err := ptn.GetNodeRead(clstr)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(node).To(gm.BeIdenticalTo(nodeA))
+
+			nodeA.inFlight.Set(3)
+			nodeB.inFlight.Set(1)
+			ptn.prevNode = nil
+			gm.Expect(ptn.GetNodeRead(clstr)).To(gm.BeIdenticalTo(nodeB))
+
+			nodeB.active.Set(false)
+			ptn.prevNode = nil
+			gm.Expect(ptn.GetNodeRead(clstr)).To(gm.BeIdenticalTo(nodeA))
+
+			// writes go to the master
+			nodeB.active.Set(true)
+			gm.Expect(ptn.GetNodeWrite(clstr)).To(gm.BeIdenticalTo(nodeA))
+		})
+
+		gg.It("must avoid the node of the previous attempt on retries", func() {
+			gm.Expect(ptn.GetNodeRead(clstr)).To(gm.BeIdenticalTo(nodeA))
+			gm.Expect(ptn.GetNodeRead(clstr)).To(gm.BeIdenticalTo(nodeB))
+			gm.Expect(ptn.GetNodeRead(clstr)).To(gm.BeIdenticalTo(nodeA))
+
+			nodeB.active.Set(false)
+			gm.Expect(ptn.GetNodeRead(clstr)).To(gm.BeIdenticalTo(nodeA))
+		})
+	})
+})
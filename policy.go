@@ -139,6 +139,16 @@ type BasePolicy struct {
 	// The default is false
 	ExitFastOnExhaustedConnectionPool bool // false
 
+	// WaitForConnection determines if a command that finds the connection pool of a node exhausted
+	// or empty will wait in the node's bounded wait queue for a connection to be returned to the pool,
+	// instead of failing immediately and retrying.
+	// The wait is bounded by the deadline of the command, and the queue size by ClientPolicy.MaxConnectionWaiters.
+	// Commands without a TotalTimeout or SocketTimeout never wait.
+	// The number of waits and wait timeouts are reported in the node statistics.
+	//
+	// Default: false
+	WaitForConnection bool // false
+
 	// SendKey determines to whether send user defined key in addition to hash digest on both reads and writes.
 	// If the key is sent on a write, the key will be stored with the record on
 	// the server.
@@ -149,8 +159,8 @@ type BasePolicy struct {
 	// The default is to not send the user defined key.
 	SendKey bool // = false
 
-	// UseCompression uses zlib compression on command buffers sent to the server and responses received
-	// from the server when the buffer size is greater than 128 bytes.
+	// UseCompression uses compression on command buffers sent to the server and responses received
+	// from the server when the buffer size is greater than MinCompressSize (128 bytes by default).
 	//
 	// This option will increase cpu and memory usage (for extra compressed buffers),but
 	// decrease the size of data sent over the network.
@@ -158,12 +168,87 @@ type BasePolicy struct {
 	// Default: false
 	UseCompression bool // = false
 
+	// CompressionAlgorithm determines the algorithm used to compress the commands when
+	// UseCompression is set. ZSTD requires a compressor registered via RegisterZstdCompressor,
+	// and ClientPolicy.EnableZstdCompression. Otherwise zlib is used, and the fallback is counted
+	// in the compression-fallbacks node statistic.
+	//
+	// Default: CompressionZlib
+	CompressionAlgorithm CompressionAlgorithm // = CompressionZlib
+
+	// CompressionLevel is the level passed to the compressor. 0 uses the default level
+	// of the algorithm. Higher levels trade cpu time for better compression ratios.
+	//
+	// Default: 0
+	CompressionLevel int // = 0
+
+	// MinCompressSize is the minimum size of a command in bytes for it to be compressed.
+	// Smaller commands are sent uncompressed even if UseCompression is set, since the
+	// compression overhead outweighs the bandwidth savings for small payloads.
+	// 0 uses the default of 128 bytes, which is where zlib starts to reduce the message size
+	// for typical records (see BenchmarkCompression).
+	//
+	// Default: 0
+	MinCompressSize int // = 0
+
 	// ReplicaPolicy specifies the algorithm used to determine the target node for a partition derived from a key
 	// or requested in a scan/query.
 	// Write commands are not affected by this setting, because all writes are directed
 	// to the node containing the key's master partition.
 	// Default to sending read commands to the node containing the key's master partition.
 	ReplicaPolicy ReplicaPolicy
+
+	// AdaptiveTimeout, when set, replaces the socket timeout of each attempt of a single record
+	// command with one derived from the recent latencies of the target node, e.g. twice its p99,
+	// bounded by the floor and the ceiling of the AdaptiveTimeoutPolicy.
+	// Attempts on a degraded node then time out and are retried sooner, instead of waiting for
+	// an overly long static SocketTimeout. TotalTimeout and MaxRetries still apply as usual.
+	// The latencies are sampled from the successful attempts, and refreshed on every cluster tend.
+	// This option is ignored by the proxy client.
+	//
+	// Default: nil (static SocketTimeout)
+	AdaptiveTimeout *AdaptiveTimeoutPolicy
+
+	// HedgeDelay, when positive, makes Get, GetHeader and Exists send a duplicate read to another
+	// replica of the partition if the first one has not responded within the delay.
+	// The first successful response is returned, and the other read is cancelled: it is not
+	// retried any more, and its response is discarded.
+	// Reads are not hedged if the partition has a single replica, or if the strong consistency
+	// mode of the read requires the master.
+	// The number of hedges that won or were wasted are reported in Client.Stats.
+	// This option is ignored by the proxy client and for writes.
+	//
+	// Default: 0 (no hedging)
+	HedgeDelay time.Duration
+
+	// CollapseReads makes concurrent Get commands for the same record and bin names, which also
+	// set this option, share a single request to the server. The request is sent with the policy
+	// of the first caller, and the other callers receive copies of its record or error; the bin
+	// values themselves are shared, and must not be modified.
+	// This helps against read stampedes on hot keys. Reads with a FilterExpression are not collapsed.
+	// The number of reads served this way are reported as collapsed-reads in Client.Stats.
+	// This option is ignored by the proxy client.
+	//
+	// Default: false
+	CollapseReads bool
+
+	// OmitKeyInErrors prevents the namespace, set name and digest of the record from being
+	// attached to the errors of single record commands. They are reported by the accessors of
+	// the error and in its message, so that the affected record can be identified in logs.
+	// The user key is never attached.
+	// This option is ignored by the proxy client, which does not attach them.
+	//
+	// Default: false
+	OmitKeyInErrors bool // = false
+
+	// AuditContext identifies the effective user or tenant on whose behalf the command is sent,
+	// for the attribution of the commands of multi-tenant services. The string is opaque to the client.
+	// The native protocol has no field for it, so the native client does not send it to the server;
+	// it is reported to the interceptors in CommandInfo.AuditContext.
+	// The proxy client also sends it to the proxy server in the aerospike-audit-context gRPC header.
+	//
+	// Default: ""
+	AuditContext string // = ""
 }
 
 // NewPolicy generates a new BasePolicy instance with default values.
@@ -220,15 +305,20 @@ func (p *BasePolicy) timeout() time.Duration {
 }
 
 func (p *BasePolicy) deadline() time.Time {
+	return p.deadlineFrom(time.Now())
+}
+
+// deadlineFrom returns the deadline of the command started at the time.
+func (p *BasePolicy) deadlineFrom(now time.Time) time.Time {
 	var deadline time.Time
 	if p != nil {
 		if p.TotalTimeout > 0 {
-			deadline = time.Now().Add(p.TotalTimeout)
+			deadline = now.Add(p.TotalTimeout)
 		} else if p.SocketTimeout > 0 {
 			if p.MaxRetries > 0 {
-				deadline = time.Now().Add(time.Duration(p.MaxRetries) * p.SocketTimeout)
+				deadline = now.Add(time.Duration(p.MaxRetries) * p.SocketTimeout)
 			} else {
-				deadline = time.Now().Add(p.SocketTimeout)
+				deadline = now.Add(p.SocketTimeout)
 			}
 		}
 	}
@@ -0,0 +1,250 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"sync"
+
+	iatomic "github.com/aerospike/aerospike-client-go/v7/internal/atomic"
+)
+
+// PolicyRegistry holds the default policies of a client per namespace, and optionally per set.
+// When a command is issued with a nil policy, the policy registered for the namespace and set
+// of the command is used, then the policy registered for the namespace with an empty set name,
+// and finally the default policy of the client.
+//
+// The policies are copied when they are registered, and can be replaced at any time while
+// commands are running; each command uses the policies registered when it was issued.
+// Batch commands use the policies of the namespace and set of their keys if all the keys
+// share them. Only the native client supports the registry.
+type PolicyRegistry struct {
+	// serializes the updates; readers only load the snapshot
+	m      sync.Mutex
+	scopes iatomic.TypedVal[map[policyScope]*scopePolicies]
+}
+
+type policyScope struct {
+	namespace string
+	set       string
+}
+
+type scopePolicies struct {
+	read  *BasePolicy
+	write *WritePolicy
+	batch *BatchPolicy
+	scan  *ScanPolicy
+	query *QueryPolicy
+}
+
+func (sp *scopePolicies) isEmpty() bool {
+	return sp.read == nil && sp.write == nil && sp.batch == nil && sp.scan == nil && sp.query == nil
+}
+
+// SetReadPolicy registers the default read policy for the namespace and set.
+// A nil policy removes the registered policy.
+func (pr *PolicyRegistry) SetReadPolicy(namespace, set string, policy *BasePolicy) {
+	pr.update(namespace, set, func(sp *scopePolicies) { sp.read = copyPolicy(policy) })
+}
+
+// SetWritePolicy registers the default write policy for the namespace and set.
+// A nil policy removes the registered policy.
+func (pr *PolicyRegistry) SetWritePolicy(namespace, set string, policy *WritePolicy) {
+	pr.update(namespace, set, func(sp *scopePolicies) { sp.write = copyPolicy(policy) })
+}
+
+// SetBatchPolicy registers the default batch policy for the namespace and set.
+// A nil policy removes the registered policy.
+func (pr *PolicyRegistry) SetBatchPolicy(namespace, set string, policy *BatchPolicy) {
+	pr.update(namespace, set, func(sp *scopePolicies) { sp.batch = copyPolicy(policy) })
+}
+
+// SetScanPolicy registers the default scan policy for the namespace and set.
+// A nil policy removes the registered policy.
+func (pr *PolicyRegistry) SetScanPolicy(namespace, set string, policy *ScanPolicy) {
+	pr.update(namespace, set, func(sp *scopePolicies) { sp.scan = copyPolicy(policy) })
+}
+
+// SetQueryPolicy registers the default query policy for the namespace and set.
+// A nil policy removes the registered policy.
+func (pr *PolicyRegistry) SetQueryPolicy(namespace, set string, policy *QueryPolicy) {
+	pr.update(namespace, set, func(sp *scopePolicies) { sp.query = copyPolicy(policy) })
+}
+
+// ReadPolicy returns the read policy used for the namespace and set, or nil if none is registered.
+func (pr *PolicyRegistry) ReadPolicy(namespace, set string) *BasePolicy {
+	return lookupPolicy(pr, namespace, set, func(sp *scopePolicies) *BasePolicy { return sp.read })
+}
+
+// WritePolicy returns the write policy used for the namespace and set, or nil if none is registered.
+func (pr *PolicyRegistry) WritePolicy(namespace, set string) *WritePolicy {
+	return lookupPolicy(pr, namespace, set, func(sp *scopePolicies) *WritePolicy { return sp.write })
+}
+
+// BatchPolicy returns the batch policy used for the namespace and set, or nil if none is registered.
+func (pr *PolicyRegistry) BatchPolicy(namespace, set string) *BatchPolicy {
+	return lookupPolicy(pr, namespace, set, func(sp *scopePolicies) *BatchPolicy { return sp.batch })
+}
+
+// ScanPolicy returns the scan policy used for the namespace and set, or nil if none is registered.
+func (pr *PolicyRegistry) ScanPolicy(namespace, set string) *ScanPolicy {
+	return lookupPolicy(pr, namespace, set, func(sp *scopePolicies) *ScanPolicy { return sp.scan })
+}
+
+// QueryPolicy returns the query policy used for the namespace and set, or nil if none is registered.
+func (pr *PolicyRegistry) QueryPolicy(namespace, set string) *QueryPolicy {
+	return lookupPolicy(pr, namespace, set, func(sp *scopePolicies) *QueryPolicy { return sp.query })
+}
+
+// Clear removes all the registered policies.
+func (pr *PolicyRegistry) Clear() {
+	pr.m.Lock()
+	defer pr.m.Unlock()
+
+	pr.scopes.Set(nil)
+}
+
+// update replaces the snapshot of the policies with a copy changed by f.
+func (pr *PolicyRegistry) update(namespace, set string, f func(sp *scopePolicies)) {
+	pr.m.Lock()
+	defer pr.m.Unlock()
+
+	old := pr.scopes.Get()
+	scopes := make(map[policyScope]*scopePolicies, len(old)+1)
+	for k, v := range old {
+		scopes[k] = v
+	}
+
+	scope := policyScope{namespace: namespace, set: set}
+	sp := &scopePolicies{}
+	if prev := scopes[scope]; prev != nil {
+		*sp = *prev
+	}
+	f(sp)
+
+	if sp.isEmpty() {
+		delete(scopes, scope)
+	} else {
+		scopes[scope] = sp
+	}
+
+	if len(scopes) == 0 {
+		scopes = nil
+	}
+	pr.scopes.Set(scopes)
+}
+
+func lookupPolicy[T any](pr *PolicyRegistry, namespace, set string, get func(sp *scopePolicies) *T) *T {
+	scopes := pr.scopes.Get()
+	if scopes == nil {
+		return nil
+	}
+
+	if sp := scopes[policyScope{namespace: namespace, set: set}]; sp != nil {
+		if p := get(sp); p != nil {
+			return p
+		}
+	}
+
+	if set != "" {
+		if sp := scopes[policyScope{namespace: namespace}]; sp != nil {
+			return get(sp)
+		}
+	}
+	return nil
+}
+
+func copyPolicy[T any](policy *T) *T {
+	if policy == nil {
+		return nil
+	}
+	res := *policy
+	return &res
+}
+
+// keysScope returns the namespace and set shared by the keys. The set is empty if the keys
+// belong to different sets, and the namespace is empty if they belong to different namespaces.
+func keysScope(n int, key func(i int) *Key) (namespace, set string) {
+	if n == 0 {
+		return "", ""
+	}
+
+	first := key(0)
+	namespace, set = first.namespace, first.setName
+	for i := 1; i < n; i++ {
+		k := key(i)
+		if k.namespace != namespace {
+			return "", ""
+		}
+		if k.setName != set {
+			set = ""
+		}
+	}
+	return namespace, set
+}
+
+// DefaultPolicies returns the registry of the default policies per namespace and set.
+func (clnt *Client) DefaultPolicies() *PolicyRegistry {
+	return &clnt.policies
+}
+
+func (clnt *Client) getUsablePolicyFor(policy *BasePolicy, namespace, set string) *BasePolicy {
+	if policy == nil {
+		if p := clnt.policies.ReadPolicy(namespace, set); p != nil {
+			return p
+		}
+	}
+	return clnt.getUsablePolicy(policy)
+}
+
+func (clnt *Client) getUsableWritePolicyFor(policy *WritePolicy, namespace, set string) *WritePolicy {
+	if policy == nil {
+		if p := clnt.policies.WritePolicy(namespace, set); p != nil {
+			return p
+		}
+	}
+	return clnt.getUsableWritePolicy(policy)
+}
+
+func (clnt *Client) getUsableBatchPolicyFor(policy *BatchPolicy, keys []*Key) *BatchPolicy {
+	return clnt.getUsableBatchPolicyForRecords(policy, len(keys), func(i int) *Key { return keys[i] })
+}
+
+func (clnt *Client) getUsableBatchPolicyForRecords(policy *BatchPolicy, n int, key func(i int) *Key) *BatchPolicy {
+	if policy == nil && clnt.policies.scopes.Get() != nil {
+		namespace, set := keysScope(n, key)
+		if p := clnt.policies.BatchPolicy(namespace, set); p != nil {
+			return p
+		}
+	}
+	return clnt.getUsableBatchPolicy(policy)
+}
+
+func (clnt *Client) getUsableScanPolicyFor(policy *ScanPolicy, namespace, set string) *ScanPolicy {
+	if policy == nil {
+		if p := clnt.policies.ScanPolicy(namespace, set); p != nil {
+			return p
+		}
+	}
+	return clnt.getUsableScanPolicy(policy)
+}
+
+func (clnt *Client) getUsableQueryPolicyFor(policy *QueryPolicy, statement *Statement) *QueryPolicy {
+	if policy == nil {
+		if p := clnt.policies.QueryPolicy(statement.Namespace, statement.SetName); p != nil {
+			return p
+		}
+	}
+	return clnt.getUsableQueryPolicy(policy)
+}
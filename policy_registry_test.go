@@ -0,0 +1,97 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	as "github.com/aerospike/aerospike-client-go/v7"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+// ALL tests are isolated by SetName and Key, which are 50 random characters
+var _ = gg.Describe("PolicyRegistry", func() {
+
+	var ns = *namespace
+	var set = randString(50)
+
+	gg.Context("Registry", func() {
+
+		gg.It("must resolve the set, then the namespace policies", func() {
+			var pr as.PolicyRegistry
+			gm.Expect(pr.WritePolicy("test", "set")).To(gm.BeNil())
+
+			nsPolicy := as.NewWritePolicy(0, 10)
+			pr.SetWritePolicy("test", "", nsPolicy)
+			gm.Expect(pr.WritePolicy("test", "set").Expiration).To(gm.Equal(uint32(10)))
+			gm.Expect(pr.WritePolicy("other", "set")).To(gm.BeNil())
+
+			setPolicy := as.NewWritePolicy(0, 20)
+			pr.SetWritePolicy("test", "set", setPolicy)
+			gm.Expect(pr.WritePolicy("test", "set").Expiration).To(gm.Equal(uint32(20)))
+			gm.Expect(pr.WritePolicy("test", "other").Expiration).To(gm.Equal(uint32(10)))
+
+			// the registered policies are copies
+			setPolicy.Expiration = 30
+			gm.Expect(pr.WritePolicy("test", "set").Expiration).To(gm.Equal(uint32(20)))
+
+			pr.SetWritePolicy("test", "set", nil)
+			gm.Expect(pr.WritePolicy("test", "set").Expiration).To(gm.Equal(uint32(10)))
+
+			pr.SetReadPolicy("test", "", as.NewPolicy())
+			pr.Clear()
+			gm.Expect(pr.WritePolicy("test", "set")).To(gm.BeNil())
+			gm.Expect(pr.ReadPolicy("test", "")).To(gm.BeNil())
+		})
+	})
+
+	gg.Context("Client", func() {
+
+		gg.BeforeEach(func() {
+			if *proxy {
+				gg.Skip("Not supported in Proxy Client")
+			}
+		})
+
+		gg.AfterEach(func() {
+			if !*proxy {
+				nativeClient.DefaultPolicies().Clear()
+			}
+		})
+
+		gg.It("must use the registered policy when the policy is nil", func() {
+			key, err := as.NewKey(ns, set, randString(50))
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+
+			policy := as.NewWritePolicy(0, 0)
+			policy.RecordExistsAction = as.UPDATE_ONLY
+			nativeClient.DefaultPolicies().SetWritePolicy(ns, set, policy)
+
+			err = nativeClient.PutBins(nil, key, as.NewBin("a", 1))
+			gm.Expect(err).To(gm.HaveOccurred())
+			gm.Expect(err.Matches(as.ErrKeyNotFound.ResultCode)).To(gm.BeTrue())
+
+			// an explicit policy is not overridden
+			err = nativeClient.PutBins(as.NewWritePolicy(0, 0), key, as.NewBin("a", 1))
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+
+			// other sets are not affected
+			okey, err := as.NewKey(ns, randString(50), randString(50))
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			err = nativeClient.PutBins(nil, okey, as.NewBin("a", 1))
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+		})
+	})
+})
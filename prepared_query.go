@@ -0,0 +1,222 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+	ParticleType "github.com/aerospike/aerospike-client-go/v7/types/particle_type"
+)
+
+// PreparedQuery is a secondary index query that is executed repeatedly with different filter values,
+// like a prepared statement. The parts of the statement that do not depend on the filter values,
+// such as the packed CDT context of the filter and the bin projection, are computed once.
+//
+// Each execution still gets its own random task id, since the server rejects the queries
+// that reuse the task id of a running query.
+type PreparedQuery struct {
+	stmt       Statement
+	filter     Filter
+	projection *binProjection
+}
+
+// NewPreparedQuery prepares the query of the statement. The statement filter is a template:
+// its bin, index collection type, value type and context are used by the executions,
+// while its values are replaced by the values of each execution.
+// The statement is copied; changing it later does not change the prepared query.
+func NewPreparedQuery(statement *Statement) (*PreparedQuery, Error) {
+	if statement.Filter == nil {
+		return nil, newError(types.PARAMETER_ERROR, "prepared queries require a statement filter")
+	}
+
+	if statement.functionName != "" {
+		return nil, newError(types.PARAMETER_ERROR, "prepared queries do not support aggregate functions")
+	}
+
+	pq := &PreparedQuery{
+		stmt:   *statement,
+		filter: *statement.Filter,
+	}
+	pq.stmt.BinNames = append([]string(nil), statement.BinNames...)
+	pq.stmt.ExcludeBins = append([]string(nil), statement.ExcludeBins...)
+	pq.stmt.Filter = nil
+	pq.projection = pq.stmt.projection()
+
+	if len(pq.filter.ctx) > 0 {
+		sz, err := pq.filter.estimatePackedCtxSize()
+		if err != nil {
+			return nil, err
+		}
+
+		buf := newBuffer(sz)
+		if _, err := pq.filter.packCtx(buf); err != nil {
+			return nil, err
+		}
+		pq.filter.packedCtx = buf.Bytes()
+	}
+
+	return pq, nil
+}
+
+// Statement returns the statement of an execution of the query. A single value filters
+// the records equal to the value, and two values filter the records in the range.
+// The values must be of the type of the template filter values.
+func (pq *PreparedQuery) Statement(values ...interface{}) (*Statement, Error) {
+	var begin, end Value
+	switch len(values) {
+	case 1:
+		begin = pq.filterValue(values[0])
+		end = begin
+	case 2:
+		begin, end = pq.filterValue(values[0]), pq.filterValue(values[1])
+	default:
+		return nil, newError(types.PARAMETER_ERROR, fmt.Sprintf("prepared queries require one or two filter values, got %d", len(values)))
+	}
+
+	if begin.GetType() != pq.filter.valueParticleType || end.GetType() != pq.filter.valueParticleType {
+		return nil, newError(types.PARAMETER_ERROR, fmt.Sprintf("filter values of bin `%s` must be of particle type %d", pq.filter.name, pq.filter.valueParticleType))
+	}
+
+	filter := pq.filter
+	filter.begin, filter.end = begin, end
+
+	stmt := pq.stmt
+	stmt.Filter = &filter
+	stmt.TaskId = rand.Uint64()
+	stmt.preparedProjection = pq.projection
+	return &stmt, nil
+}
+
+// filterValue converts a value to the filter value type. Strings are
+// accepted for the GeoJSON filters.
+func (pq *PreparedQuery) filterValue(v interface{}) Value {
+	if s, ok := v.(string); ok && pq.filter.valueParticleType == ParticleType.GEOJSON {
+		return NewGeoJSONValue(s)
+	}
+	return NewValue(v)
+}
+
+// Query executes the query with the filter values.
+// If the policy is nil, the default relevant policy will be used.
+func (pq *PreparedQuery) Query(clnt ClientIfc, policy *QueryPolicy, values ...interface{}) (*Recordset, Error) {
+	return pq.QueryPartitions(clnt, policy, nil, values...)
+}
+
+// QueryPartitions executes the query with the filter values, for the partitions in the partition filter.
+// If the policy is nil, the default relevant policy will be used.
+func (pq *PreparedQuery) QueryPartitions(clnt ClientIfc, policy *QueryPolicy, partitionFilter *PartitionFilter, values ...interface{}) (*Recordset, Error) {
+	stmt, err := pq.Statement(values...)
+	if err != nil {
+		return nil, err
+	}
+
+	if partitionFilter == nil {
+		return clnt.Query(policy, stmt)
+	}
+	return clnt.QueryPartitions(policy, stmt, partitionFilter)
+}
+
+// PreparedQueryCache caches the prepared queries by namespace, set, bins and filter shape,
+// so that the code building the statements of the same query does not prepare it again.
+// It is safe for concurrent use.
+type PreparedQueryCache struct {
+	mutex   sync.Mutex
+	queries map[string]*PreparedQuery
+}
+
+// NewPreparedQueryCache creates an empty PreparedQueryCache.
+func NewPreparedQueryCache() *PreparedQueryCache {
+	return &PreparedQueryCache{
+		queries: map[string]*PreparedQuery{},
+	}
+}
+
+// Prepare returns the cached prepared query of the statement, and prepares it if it is not cached.
+// The values of the statement filter are ignored; only its shape is part of the cache key.
+func (pqc *PreparedQueryCache) Prepare(statement *Statement) (*PreparedQuery, Error) {
+	if statement.Filter == nil {
+		return nil, newError(types.PARAMETER_ERROR, "prepared queries require a statement filter")
+	}
+
+	key, err := preparedQueryKey(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	pqc.mutex.Lock()
+	defer pqc.mutex.Unlock()
+
+	if pq, exists := pqc.queries[key]; exists {
+		return pq, nil
+	}
+
+	pq, err := NewPreparedQuery(statement)
+	if err != nil {
+		return nil, err
+	}
+	pqc.queries[key] = pq
+	return pq, nil
+}
+
+// Len returns the number of cached prepared queries.
+func (pqc *PreparedQueryCache) Len() int {
+	pqc.mutex.Lock()
+	defer pqc.mutex.Unlock()
+	return len(pqc.queries)
+}
+
+// Clear removes all the cached prepared queries.
+func (pqc *PreparedQueryCache) Clear() {
+	pqc.mutex.Lock()
+	defer pqc.mutex.Unlock()
+	pqc.queries = map[string]*PreparedQuery{}
+}
+
+func preparedQueryKey(stmt *Statement) (string, Error) {
+	fltr := stmt.Filter
+
+	var ctx string
+	if len(fltr.ctx) > 0 {
+		sz, err := fltr.estimatePackedCtxSize()
+		if err != nil {
+			return "", err
+		}
+
+		buf := newBuffer(sz)
+		if _, err := fltr.packCtx(buf); err != nil {
+			return "", err
+		}
+		ctx = hex.EncodeToString(buf.Bytes())
+	}
+
+	// the names are quoted, so that the separators in names do not make the keys ambiguous
+	return fmt.Sprintf("%q|%q|%q|%q|%q|%q|%s|%d|%s|%v",
+		stmt.Namespace,
+		stmt.SetName,
+		stmt.IndexName,
+		strings.Join(stmt.BinNames, "\x00"),
+		strings.Join(stmt.ExcludeBins, "\x00"),
+		fltr.name,
+		fltr.idxType,
+		fltr.valueParticleType,
+		ctx,
+		stmt.ReturnData,
+	), nil
+}
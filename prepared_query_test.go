@@ -0,0 +1,135 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"github.com/aerospike/aerospike-client-go/v7/types"
+	ParticleType "github.com/aerospike/aerospike-client-go/v7/types/particle_type"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Prepared queries", func() {
+
+	gg.It("must build the statements of the executions", func() {
+		stmt := NewStatement("test", "users", "name", "age")
+		stmt.ExcludeBins = []string{"secret"}
+		stmt.Filter = NewContainsFilter("scores", ICT_MAPVALUES, 1, CtxMapKey(NewValue("games")))
+
+		pq, err := NewPreparedQuery(stmt)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		// the template is copied
+		stmt.BinNames[0] = "changed"
+		stmt.Filter = nil
+
+		s1, err := pq.Statement(5)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		s2, err := pq.Statement(10, 20)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		gm.Expect(s1.BinNames).To(gm.Equal([]string{"name", "age"}))
+		gm.Expect(s1.Filter.begin).To(gm.Equal(IntegerValue(5)))
+		gm.Expect(s1.Filter.end).To(gm.Equal(IntegerValue(5)))
+		gm.Expect(s2.Filter.begin).To(gm.Equal(IntegerValue(10)))
+		gm.Expect(s2.Filter.end).To(gm.Equal(IntegerValue(20)))
+		gm.Expect(s1.Filter).ToNot(gm.BeIdenticalTo(s2.Filter))
+		gm.Expect(s1.TaskId).ToNot(gm.Equal(s2.TaskId))
+
+		// the projection and the packed ctx are shared
+		gm.Expect(s1.projection()).To(gm.BeIdenticalTo(s2.projection()))
+		gm.Expect(s1.projection().allows("name")).To(gm.BeTrue())
+		gm.Expect(s1.projection().allows("secret")).To(gm.BeFalse())
+
+		fresh := NewContainsFilter("scores", ICT_MAPVALUES, 1, CtxMapKey(NewValue("games")))
+		expected := newBuffer(256)
+		n, err := fresh.packCtx(expected)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(s1.Filter.packedCtx).To(gm.Equal(expected.Bytes()[:n]))
+
+		sz, err := s2.Filter.estimatePackedCtxSize()
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(sz).To(gm.Equal(n))
+	})
+
+	gg.It("must validate the filter values", func() {
+		stmt := NewStatement("test", "users")
+		stmt.Filter = NewEqualFilter("name", "a")
+		pq, err := NewPreparedQuery(stmt)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		_, err = pq.Statement()
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+		_, err = pq.Statement("a", "b", "c")
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+		_, err = pq.Statement(1)
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+
+		geo := NewStatement("test", "places")
+		geo.Filter = NewGeoWithinRegionFilter("loc", `{"type":"Polygon"}`)
+		pq, err = NewPreparedQuery(geo)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		s, err := pq.Statement(`{"type":"AeroCircle"}`)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(s.Filter.begin.GetType()).To(gm.Equal(ParticleType.GEOJSON))
+
+		_, err = NewPreparedQuery(NewStatement("test", "users"))
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+
+		agg := NewStatement("test", "users")
+		agg.Filter = NewEqualFilter("name", "a")
+		agg.SetAggregateFunction("pkg", "fn", nil, true)
+		_, err = NewPreparedQuery(agg)
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+	})
+
+	gg.It("must cache the prepared queries by shape", func() {
+		cache := NewPreparedQueryCache()
+
+		newStmt := func(set string, filter *Filter, bins ...string) *Statement {
+			stmt := NewStatement("test", set, bins...)
+			stmt.Filter = filter
+			return stmt
+		}
+
+		pq1, err := cache.Prepare(newStmt("users", NewRangeFilter("age", 1, 2)))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		pq2, err := cache.Prepare(newStmt("users", NewRangeFilter("age", 30, 40)))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(pq2).To(gm.BeIdenticalTo(pq1))
+		gm.Expect(cache.Len()).To(gm.Equal(1))
+
+		for _, stmt := range []*Statement{
+			newStmt("other", NewRangeFilter("age", 1, 2)),
+			newStmt("users", NewRangeFilter("score", 1, 2)),
+			newStmt("users", NewRangeFilter("age", 1, 2), "name"),
+			newStmt("users", NewEqualFilter("age", "1")),
+			newStmt("users", NewRangeFilter("age", 1, 2, CtxListIndex(0))),
+			newStmt("users", NewContainsRangeFilter("age", ICT_LIST, 1, 2)),
+		} {
+			pq, err := cache.Prepare(stmt)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(pq).ToNot(gm.BeIdenticalTo(pq1))
+		}
+		gm.Expect(cache.Len()).To(gm.Equal(7))
+
+		_, err = cache.Prepare(NewStatement("test", "users"))
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+
+		cache.Clear()
+		gm.Expect(cache.Len()).To(gm.Equal(0))
+	})
+})
@@ -22,27 +22,37 @@ import (
 	"encoding/json"
 	"runtime/debug"
 	"strings"
+	"sync"
 	"time"
 
 	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/aerospike/aerospike-client-go/v7/logger"
 	auth "github.com/aerospike/aerospike-client-go/v7/proto/auth"
 	"github.com/aerospike/aerospike-client-go/v7/types"
 )
 
+// defaultProxyTokenRefreshBefore provides a buffer before the token expiry due to network latency.
+const defaultProxyTokenRefreshBefore = 5 * time.Second
+
 type authInterceptor struct {
 	clnt   *ProxyClient
+	source ProxyTokenSource
 	closer chan struct{}
 
-	expiry    time.Time
-	fullToken string // "Bearer <token>"
+	// serializes the token refreshes
+	mutex    sync.Mutex
+	expiry   time.Time
+	lifetime time.Duration
 }
 
 func newAuthInterceptor(clnt *ProxyClient) (*authInterceptor, Error) {
 	interceptor := &authInterceptor{
 		clnt:   clnt,
+		source: clnt.clientPolicy.ProxyTokenSource,
 		closer: make(chan struct{}),
 	}
 
@@ -70,7 +80,7 @@ func (interceptor *authInterceptor) active() bool {
 }
 
 func (interceptor *authInterceptor) scheduleRefreshToken() Error {
-	err := interceptor.refreshToken()
+	err := interceptor.refreshToken("")
 	if err != nil {
 		return err
 	}
@@ -90,20 +100,31 @@ func (interceptor *authInterceptor) tokenRefresher() {
 		}
 	}()
 
-	// provide 5 secs of buffer before expiry due to network latency
-	wait := interceptor.expiry.Sub(time.Now()) - 5*time.Second
-	ticker := time.NewTicker(wait)
-	defer ticker.Stop()
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
 
+	wait, ok := interceptor.refreshWait()
 	for {
-		ticker.Reset(wait)
+		// tokens without expiry are only refreshed when rejected by the server
+		var expired <-chan time.Time
+		if ok {
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(wait)
+			expired = timer.C
+		}
+
 		select {
-		case <-ticker.C:
-			err := interceptor.refreshToken()
-			if err != nil {
-				wait = time.Second
+		case <-expired:
+			if err := interceptor.refreshToken(""); err != nil {
+				logger.Logger.Warn("Failed to refresh the proxy access token: %s", err.Error())
+				wait, ok = time.Second, true
 			} else {
-				wait = interceptor.expiry.Sub(time.Now()) - 5*time.Second
+				wait, ok = interceptor.refreshWait()
 			}
 
 		case <-interceptor.closer:
@@ -113,17 +134,88 @@ func (interceptor *authInterceptor) tokenRefresher() {
 	}
 }
 
-func (interceptor *authInterceptor) refreshToken() Error {
-	err := interceptor.login()
+// refreshWait returns how long to wait before refreshing the current token,
+// and false if the token does not expire.
+func (interceptor *authInterceptor) refreshWait() (time.Duration, bool) {
+	interceptor.mutex.Lock()
+	defer interceptor.mutex.Unlock()
+
+	if interceptor.expiry.IsZero() {
+		return 0, false
+	}
+
+	before := interceptor.clnt.clientPolicy.ProxyTokenRefreshBefore
+	if before <= 0 {
+		before = defaultProxyTokenRefreshBefore
+	}
+
+	if before > interceptor.lifetime/2 {
+		before = interceptor.lifetime / 2
+	}
+
+	wait := time.Until(interceptor.expiry) - before
+	if wait < time.Millisecond {
+		wait = time.Millisecond
+	}
+	return wait, true
+}
+
+// refreshToken fetches a new token. If stale is set, the token is only fetched if stale is
+// still the current token, so that the commands rejected at the same time refresh it only once.
+func (interceptor *authInterceptor) refreshToken(stale string) Error {
+	interceptor.mutex.Lock()
+	defer interceptor.mutex.Unlock()
+
+	if stale != "" && interceptor.clnt.token() != stale {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), interceptor.clnt.clientPolicy.Timeout)
+	defer cancel()
+
+	token, err := interceptor.fetchToken(ctx)
 	if err != nil {
 		return err
 	}
 
-	interceptor.clnt.setAuthToken(interceptor.fullToken)
+	interceptor.expiry = token.Expiry
+	interceptor.lifetime = time.Until(token.Expiry)
+	interceptor.clnt.setAuthToken("Bearer " + token.Value)
 
 	return nil
 }
 
+func (interceptor *authInterceptor) fetchToken(ctx context.Context) (*ProxyToken, Error) {
+	if interceptor.source == nil {
+		return interceptor.login(ctx)
+	}
+
+	token, err := interceptor.source.Token(ctx)
+	if err != nil {
+		return nil, newErrorAndWrap(err, types.NOT_AUTHENTICATED, "Failed to fetch the proxy access token: "+err.Error())
+	}
+
+	if token == nil || token.Value == "" {
+		return nil, newError(types.NOT_AUTHENTICATED, "Proxy token source returned an empty token")
+	}
+
+	return token, nil
+}
+
+// retryUnauthenticated refreshes the token if err is an UNAUTHENTICATED status returned for the
+// token, and returns true if the call should be retried with the new token.
+func (interceptor *authInterceptor) retryUnauthenticated(err error, token string) bool {
+	if status.Code(err) != codes.Unauthenticated || !interceptor.active() {
+		return false
+	}
+
+	if rerr := interceptor.refreshToken(token); rerr != nil {
+		logger.Logger.Warn("Failed to refresh the rejected proxy access token: %s", rerr.Error())
+		return false
+	}
+	return true
+}
+
 func (interceptor *authInterceptor) RequireTransportSecurity() bool {
 	return true
 }
@@ -137,7 +229,12 @@ func (interceptor *authInterceptor) Unary() grpc.UnaryClientInterceptor {
 		invoker grpc.UnaryInvoker,
 		opts ...grpc.CallOption,
 	) error {
-		return invoker(interceptor.attachToken(ctx), method, req, reply, cc, opts...)
+		token := interceptor.clnt.token()
+		err := invoker(attachToken(ctx, token), method, req, reply, cc, opts...)
+		if interceptor.retryUnauthenticated(err, token) {
+			err = invoker(attachToken(ctx, interceptor.clnt.token()), method, req, reply, cc, opts...)
+		}
+		return err
 	}
 }
 
@@ -150,69 +247,149 @@ func (interceptor *authInterceptor) Stream() grpc.StreamClientInterceptor {
 		streamer grpc.Streamer,
 		opts ...grpc.CallOption,
 	) (grpc.ClientStream, error) {
-		return streamer(interceptor.attachToken(ctx), desc, cc, method, opts...)
+		token := interceptor.clnt.token()
+		cs, err := streamer(attachToken(ctx, token), desc, cc, method, opts...)
+		if interceptor.retryUnauthenticated(err, token) {
+			token = interceptor.clnt.token()
+			cs, err = streamer(attachToken(ctx, token), desc, cc, method, opts...)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &authClientStream{
+			ClientStream: cs,
+			interceptor:  interceptor,
+			token:        token,
+			newStream: func(token string) (grpc.ClientStream, error) {
+				return streamer(attachToken(ctx, token), desc, cc, method, opts...)
+			},
+		}, nil
 	}
 }
 
-func (interceptor *authInterceptor) attachToken(ctx context.Context) context.Context {
-	token := interceptor.clnt.token()
+func attachToken(ctx context.Context, token string) context.Context {
 	return metadata.AppendToOutgoingContext(ctx, "Authorization", token)
 }
 
-func (interceptor *authInterceptor) login() Error {
+// authClientStream replays the request on a new stream when the server rejects the token
+// before sending any response, which is how streams report UNAUTHENTICATED.
+type authClientStream struct {
+	grpc.ClientStream
+
+	interceptor *authInterceptor
+	token       string
+	newStream   func(token string) (grpc.ClientStream, error)
+
+	sent       []interface{}
+	sendClosed bool
+	received   bool
+	retried    bool
+}
+
+func (s *authClientStream) SendMsg(m interface{}) error {
+	if !s.received {
+		s.sent = append(s.sent, m)
+	}
+	return s.ClientStream.SendMsg(m)
+}
+
+func (s *authClientStream) CloseSend() error {
+	s.sendClosed = true
+	return s.ClientStream.CloseSend()
+}
+
+func (s *authClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		s.received = true
+		s.sent = nil
+		return nil
+	}
+
+	if s.received || s.retried || !s.interceptor.retryUnauthenticated(err, s.token) {
+		return err
+	}
+	s.retried = true
+
+	cs, serr := s.newStream(s.interceptor.clnt.token())
+	if serr != nil {
+		return serr
+	}
+
+	for _, msg := range s.sent {
+		if serr := cs.SendMsg(msg); serr != nil {
+			return serr
+		}
+	}
+
+	if s.sendClosed {
+		if serr := cs.CloseSend(); serr != nil {
+			return serr
+		}
+	}
+
+	s.ClientStream = cs
+	return s.RecvMsg(m)
+}
+
+// login fetches a token from the proxy server with the user and password of the client policy,
+// or of its CredentialsProvider if set.
+func (interceptor *authInterceptor) login(ctx context.Context) (*ProxyToken, Error) {
+	user, password := interceptor.clnt.clientPolicy.User, interceptor.clnt.clientPolicy.Password
+	if provider := interceptor.clnt.clientPolicy.CredentialsProvider; provider != nil {
+		var perr error
+		if user, password, perr = provider.GetCredentials(ctx); perr != nil {
+			return nil, newErrorAndWrap(perr, types.NOT_AUTHENTICATED, "failed to get the credentials from ClientPolicy.CredentialsProvider")
+		}
+	}
+
 	conn, err := interceptor.clnt.createGrpcConn(true)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer conn.Close()
 
 	req := auth.AerospikeAuthRequest{
-		Username: interceptor.clnt.clientPolicy.User,
-		Password: interceptor.clnt.clientPolicy.Password,
+		Username: user,
+		Password: password,
 	}
 
 	client := auth.NewAuthServiceClient(conn)
 
-	ctx, cancel := context.WithTimeout(context.Background(), interceptor.clnt.clientPolicy.Timeout)
-	defer cancel()
-
 	res, gerr := client.Get(ctx, &req)
 	if gerr != nil {
-		return newGrpcError(false, gerr, gerr.Error())
+		return nil, newGrpcError(false, gerr, gerr.Error())
 	}
 
 	claims := strings.Split(res.GetToken(), ".")
 	decClaims, gerr := base64.RawURLEncoding.DecodeString(claims[1])
 	if gerr != nil {
-		return newGrpcError(false, gerr, "Invalid token encoding. Expected base64.")
+		return nil, newGrpcError(false, gerr, "Invalid token encoding. Expected base64.")
 	}
 
 	tokenMap := make(map[string]interface{}, 8)
 	gerr = json.Unmarshal(decClaims, &tokenMap)
 	if gerr != nil {
-		return newError(types.PARSE_ERROR, "Invalid token encoding. Expected json.")
+		return nil, newError(types.PARSE_ERROR, "Invalid token encoding. Expected json.")
 	}
 
 	expiryToken, ok := tokenMap["exp"].(float64)
 	if !ok {
-		return newError(types.PARSE_ERROR, "Invalid expiry value. Expected float64.")
+		return nil, newError(types.PARSE_ERROR, "Invalid expiry value. Expected float64.")
 	}
 
 	iat, ok := tokenMap["iat"].(float64)
 	if !ok {
-		return newError(types.PARSE_ERROR, "Invalid iat value. Expected float64.")
+		return nil, newError(types.PARSE_ERROR, "Invalid iat value. Expected float64.")
 
 	}
 
 	ttl := time.Duration(expiryToken-iat) * time.Second
 	if ttl <= 0 {
-		return newError(types.PARSE_ERROR, "Invalid token values. token 'iat' > 'exp'")
+		return nil, newError(types.PARSE_ERROR, "Invalid token values. token 'iat' > 'exp'")
 	}
 
 	// Set expiry based on local clock.
-	expiry := time.Now().Add(ttl)
-	interceptor.fullToken = "Bearer " + res.GetToken()
-	interceptor.expiry = expiry
-
-	return nil
+	return &ProxyToken{Value: res.GetToken(), Expiry: time.Now().Add(ttl)}, nil
 }
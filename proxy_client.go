@@ -20,7 +20,6 @@ import (
 	"context"
 	"math/rand"
 	"runtime"
-	"sync"
 	"time"
 
 	"google.golang.org/grpc"
@@ -39,8 +38,7 @@ const notSupportedInProxyClient = "NOT SUPPORTED IN THE PROXY CLIENT"
 type ProxyClient struct {
 	// only for GRPC
 	clientPolicy ClientPolicy
-	grpcConnPool *grpcConnectionHeap
-	grpcHost     *Host
+	grpcConnPool *proxyConnPool
 	dialOptions  []grpc.DialOption
 
 	authToken       iatomic.TypedVal[string]
@@ -94,14 +92,27 @@ func grpcClientFinalizer(f *ProxyClient) {
 // It is recommended to call the client.WarmUp() method right after connecting to the database
 // to fill up the connection pool to the required service level.
 func NewProxyClientWithPolicyAndHost(policy *ClientPolicy, host *Host, dialOptions ...grpc.DialOption) (*ProxyClient, Error) {
+	return NewProxyClientWithPolicyAndHosts(policy, []*Host{host}, dialOptions...)
+}
+
+// NewProxyClientWithPolicyAndHosts generates a new ProxyClient with the specified ClientPolicy
+// that balances the commands between the provided proxy endpoints.
+// You must pass the tag 'as_proxy' to the compiler during build.
+// If the policy is nil, the default relevant policy will be used.
+// The commands are distributed between the endpoints according to ClientPolicy.ProxyLoadBalancing,
+// and each endpoint is connected to via ClientPolicy.ProxyChannelsPerEndpoint gRPC channels
+// that are shared by the concurrent commands.
+func NewProxyClientWithPolicyAndHosts(policy *ClientPolicy, hosts []*Host, dialOptions ...grpc.DialOption) (*ProxyClient, Error) {
 	if policy == nil {
 		policy = NewClientPolicy()
 	}
 
+	if len(hosts) == 0 {
+		return nil, newError(types.SERVER_NOT_AVAILABLE, "No hosts were provided")
+	}
+
 	grpcClient := &ProxyClient{
 		clientPolicy: *policy,
-		grpcConnPool: newGrpcConnectionHeap(policy.ConnectionQueueSize),
-		grpcHost:     host,
 		dialOptions:  dialOptions,
 
 		active: *iatomic.NewBool(true),
@@ -118,10 +129,20 @@ func NewProxyClientWithPolicyAndHost(policy *ClientPolicy, host *Host, dialOptio
 		DefaultAdminPolicy:       NewAdminPolicy(),
 		DefaultInfoPolicy:        NewInfoPolicy(),
 	}
+	grpcClient.grpcConnPool = newProxyConnPool(grpcClient, hosts)
 
-	if policy.RequiresAuthentication() {
+	if policy.DefaultSendKey {
+		grpcClient.DefaultWritePolicy.SendKey = true
+		grpcClient.DefaultBatchPolicy.SendKey = true
+		grpcClient.DefaultBatchWritePolicy.SendKey = true
+		grpcClient.DefaultBatchDeletePolicy.SendKey = true
+		grpcClient.DefaultBatchUDFPolicy.SendKey = true
+	}
+
+	if grpcClient.requiresAuthentication() {
 		authInterceptor, err := newAuthInterceptor(grpcClient)
 		if err != nil {
+			grpcClient.Close()
 			return nil, err
 		}
 
@@ -133,6 +154,7 @@ func NewProxyClientWithPolicyAndHost(policy *ClientPolicy, host *Host, dialOptio
 	infoPolicy.Timeout = policy.Timeout
 	_, err := grpcClient.ServerVersion(infoPolicy)
 	if err != nil {
+		grpcClient.Close()
 		return nil, err
 	}
 
@@ -258,6 +280,11 @@ func (clnt *ProxyClient) SetDefaultInfoPolicy(policy *InfoPolicy) {
 // Cluster Connection Management
 //-------------------------------------------------------
 
+// requiresAuthentication returns true if the commands are sent with an access token.
+func (clnt *ProxyClient) requiresAuthentication() bool {
+	return clnt.clientPolicy.RequiresAuthentication() || clnt.clientPolicy.ProxyTokenSource != nil
+}
+
 func (clnt *ProxyClient) token() string {
 	return clnt.authToken.Get()
 }
@@ -267,21 +294,19 @@ func (clnt *ProxyClient) setAuthToken(token string) {
 }
 
 func (clnt *ProxyClient) grpcConn() (*grpc.ClientConn, Error) {
-	pconn := clnt.grpcConnPool.Get()
-	if pconn != nil {
-		return pconn, nil
-	}
-
-	return clnt.createGrpcConn(!clnt.clientPolicy.RequiresAuthentication())
+	return clnt.grpcConnPool.get()
 }
 
 func (clnt *ProxyClient) returnGrpcConnToPool(conn *grpc.ClientConn) {
-	if conn != nil {
-		clnt.grpcConnPool.Put(conn)
-	}
+	clnt.grpcConnPool.put(conn)
 }
 
+// createGrpcConn opens a connection to one of the proxy endpoints that does not belong to the pool.
 func (clnt *ProxyClient) createGrpcConn(noInterceptor bool) (*grpc.ClientConn, Error) {
+	return clnt.grpcConnPool.dialAny(noInterceptor)
+}
+
+func (clnt *ProxyClient) dialGrpcConn(host *Host, noInterceptor bool) (*grpc.ClientConn, Error) {
 	// make a new connection
 	// Implement TLS and auth
 	dialOptions := []grpc.DialOption{grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(MaxBufferSize)), grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(MaxBufferSize))}
@@ -296,13 +321,14 @@ func (clnt *ProxyClient) createGrpcConn(noInterceptor bool) (*grpc.ClientConn, E
 
 	allOptions := append(dialOptions, clnt.dialOptions...)
 	if !noInterceptor {
-		allOptions = append(dialOptions,
-			grpc.WithUnaryInterceptor(clnt.authInterceptor.Unary()),
-			grpc.WithStreamInterceptor(clnt.authInterceptor.Stream()),
+		// chain the interceptors to keep the ones passed in the dial options
+		allOptions = append(allOptions,
+			grpc.WithChainUnaryInterceptor(clnt.authInterceptor.Unary()),
+			grpc.WithChainStreamInterceptor(clnt.authInterceptor.Stream()),
 		)
 	}
 
-	conn, err := grpc.DialContext(ctx, clnt.grpcHost.String(), allOptions...)
+	conn, err := grpc.DialContext(ctx, host.String(), allOptions...)
 	if err != nil {
 		return nil, newError(types.NO_AVAILABLE_CONNECTIONS_TO_NODE, err.Error())
 	}
@@ -313,12 +339,17 @@ func (clnt *ProxyClient) createGrpcConn(noInterceptor bool) (*grpc.ClientConn, E
 // Close closes all Grpcclient connections to database server nodes.
 func (clnt *ProxyClient) Close() {
 	clnt.active.Set(false)
-	clnt.grpcConnPool.cleanup()
+	clnt.grpcConnPool.close()
 	if clnt.authInterceptor != nil {
 		clnt.authInterceptor.close()
 	}
 }
 
+// CloseGracefully is not supported in the proxy client.
+func (clnt *ProxyClient) CloseGracefully(ctx context.Context) (int, Error) {
+	panic(notSupportedInProxyClient)
+}
+
 // IsConnected determines if the Grpcclient is ready to talk to the database server cluster.
 func (clnt *ProxyClient) IsConnected() bool {
 	return clnt.active.Get()
@@ -344,6 +375,7 @@ func (clnt *ProxyClient) ServerVersion(policy *InfoPolicy) (string, Error) {
 	if err != nil {
 		return "", err
 	}
+	defer clnt.returnGrpcConnToPool(conn)
 
 	client := kvs.NewAboutClient(conn)
 
@@ -355,8 +387,6 @@ func (clnt *ProxyClient) ServerVersion(policy *InfoPolicy) (string, Error) {
 		return "", newGrpcError(false, gerr, gerr.Error())
 	}
 
-	clnt.returnGrpcConnToPool(conn)
-
 	return res.GetVersion(), nil
 }
 
@@ -497,6 +527,19 @@ func (clnt *ProxyClient) Delete(policy *WritePolicy, key *Key) (bool, Error) {
 	return command.Existed(), err
 }
 
+// DeleteWithResult deletes a record for specified key, and reports if the record
+// existed and if the delete left a tombstone.
+// Proxy servers are Enterprise Edition servers, so durable deletes always leave tombstones.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *ProxyClient) DeleteWithResult(policy *WritePolicy, key *Key) (*DeleteResult, Error) {
+	policy = clnt.getUsableWritePolicy(policy)
+	existed, err := clnt.Delete(policy, key)
+	if err != nil {
+		return nil, err
+	}
+	return &DeleteResult{Existed: existed, Tombstone: existed && policy.DurableDelete}, nil
+}
+
 //-------------------------------------------------------
 // Touch Operations
 //-------------------------------------------------------
@@ -555,6 +598,20 @@ func (clnt *ProxyClient) BatchExists(policy *BatchPolicy, keys []*Key) ([]bool,
 	return records, err
 }
 
+// BatchExistsFunc determines if multiple record keys exist, and reports the result of each key
+// to fn in the positional order of the keys, without allocating the result for all keys at once.
+// The keys are sent in consecutive batches of chunkSize keys.
+// If chunkSize is zero or negative, a default of 5000 keys per batch is used.
+// Return false from fn to stop the iteration. The remaining keys will not be checked.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *ProxyClient) BatchExistsFunc(policy *BatchPolicy, keys []*Key, chunkSize int, fn func(index int, exists bool) bool) Error {
+	return batchExistsChunks(keys, chunkSize, func(keys []*Key, existsArray []bool) Error {
+		res, err := clnt.BatchExists(policy, keys)
+		copy(existsArray, res)
+		return err
+	}, fn)
+}
+
 //-------------------------------------------------------
 // Read Record Operations
 //-------------------------------------------------------
@@ -576,6 +633,18 @@ func (clnt *ProxyClient) Get(policy *BasePolicy, key *Key, binNames ...string) (
 	return command.GetRecord(), nil
 }
 
+// GetBin reads the value of a single bin for the specified key.
+// If the record exists but does not contain the bin, a nil value is returned.
+// The policy can be used to specify timeouts.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *ProxyClient) GetBin(policy *BasePolicy, key *Key, binName string) (interface{}, Error) {
+	rec, err := clnt.Get(policy, key, binName)
+	if err != nil {
+		return nil, err
+	}
+	return rec.Bins[binName], nil
+}
+
 // GetHeader reads a record generation and expiration only for specified key.
 // Bins are not read.
 // The policy can be used to specify timeouts.
@@ -703,6 +772,10 @@ func (clnt *ProxyClient) BatchGetHeader(policy *BatchPolicy, keys []*Key) ([]*Re
 	}
 
 	filteredOut, err := clnt.batchOperate(policy, batchRecordsIfc)
+	if err != nil && !policy.AllowPartialResults {
+		return nil, err
+	}
+
 	records := make([]*Record, 0, len(keys))
 	for i := range batchRecordsIfc {
 		records = append(records, batchRecordsIfc[i].BatchRec().Record)
@@ -715,6 +788,37 @@ func (clnt *ProxyClient) BatchGetHeader(policy *BatchPolicy, keys []*Key) ([]*Re
 	return records, err
 }
 
+// BatchGetDigest returns the digests of the records that exist and pass the filter expression
+// of the policy, in the positional order of the keys.
+// Only the record headers are read on the server, and no bin data is sent back.
+// Records rejected by the filter expression are left out of the result, and are not reported
+// with ErrFilteredOut.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *ProxyClient) BatchGetDigest(policy *BatchPolicy, keys []*Key) ([][]byte, Error) {
+	policy = clnt.getUsableBatchPolicy(policy)
+
+	if len(keys) == 0 {
+		return [][]byte{}, nil
+	}
+
+	batchRecordsIfc := make([]BatchRecordIfc, 0, len(keys))
+	for _, key := range keys {
+		batchRecordsIfc = append(batchRecordsIfc, NewBatchReadHeader(clnt.DefaultBatchReadPolicy, key))
+	}
+
+	_, err := clnt.batchOperate(policy, batchRecordsIfc)
+	if err != nil && !policy.AllowPartialResults {
+		return nil, err
+	}
+
+	existsArray := make([]bool, len(keys))
+	for i := range batchRecordsIfc {
+		existsArray[i] = batchRecordsIfc[i].BatchRec().Record != nil
+	}
+
+	return existingDigests(keys, existsArray), err
+}
+
 // BatchDelete deletes records for specified keys. If a key is not found, the corresponding result
 // BatchRecord.ResultCode will be types.KEY_NOT_FOUND_ERROR.
 //
@@ -808,6 +912,56 @@ func (clnt *ProxyClient) Operate(policy *WritePolicy, key *Key, operations ...*O
 	return clnt.operate(policy, key, false, operations...)
 }
 
+// OperateReplicas is not supported in the proxy client, since the proxy server selects the nodes.
+func (clnt *ProxyClient) OperateReplicas(policy *WritePolicy, key *Key, operations ...*Operation) ([]*ReplicaRecord, Error) {
+	panic(notSupportedInProxyClient)
+}
+
+// OperateWithResults works like Operate, but also returns the result of each operation,
+// in the same order as the operations. Unlike the bins of the returned record, the results
+// are not merged when several operations are applied to the same bin.
+// The policy is copied and RespondPerEachOp is set, so that every operation returns a result.
+// Operations reading all the bins of the record are not supported.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *ProxyClient) OperateWithResults(policy *WritePolicy, key *Key, operations ...*Operation) (*Record, []OpResult, Error) {
+	if err := validateOpResultsOps(operations); err != nil {
+		return nil, nil, err
+	}
+
+	respondAll := *clnt.getUsableWritePolicy(policy)
+	respondAll.RespondPerEachOp = true
+
+	rec, err := clnt.operate(&respondAll, key, true, operations...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rec, opResults(operations, rec), nil
+}
+
+// DocGet reads the value at the path of the JSON document stored in the map bin.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *ProxyClient) DocGet(policy *BasePolicy, key *Key, binName string, path string) (interface{}, Error) {
+	return docGet(clnt, policy, key, binName, path)
+}
+
+// DocSet sets the value at the path of the JSON document stored in the map bin.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *ProxyClient) DocSet(policy *WritePolicy, key *Key, binName string, path string, value interface{}) Error {
+	return docSet(clnt, policy, key, binName, path, value)
+}
+
+// DocAppend appends the value to the list at the path of the JSON document stored in the map bin.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *ProxyClient) DocAppend(policy *WritePolicy, key *Key, binName string, path string, value interface{}) Error {
+	return docAppend(clnt, policy, key, binName, path, value)
+}
+
+// DocDelete removes the element at the path of the JSON document stored in the map bin.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *ProxyClient) DocDelete(policy *WritePolicy, key *Key, binName string, path string) Error {
+	return docDelete(clnt, policy, key, binName, path)
+}
+
 func (clnt *ProxyClient) operate(policy *WritePolicy, key *Key, useOpResults bool, operations ...*Operation) (*Record, Error) {
 	policy = clnt.getUsableWritePolicy(policy)
 	args, err := newOperateArgs(nil, policy, key, operations)
@@ -868,6 +1022,12 @@ func (clnt *ProxyClient) ScanNode(apolicy *ScanPolicy, node *Node, namespace str
 	panic(notSupportedInProxyClient)
 }
 
+// ScanNodePartitions reads all records in specified namespace and set for one node and partition filter.
+// Not supported in the proxy client.
+func (clnt *ProxyClient) ScanNodePartitions(apolicy *ScanPolicy, node *Node, partitionFilter *PartitionFilter, namespace string, setName string, binNames ...string) (*Recordset, Error) {
+	panic(notSupportedInProxyClient)
+}
+
 //---------------------------------------------------------------
 // User defined functions (Supported by Aerospike 3+ servers only)
 //---------------------------------------------------------------
@@ -895,6 +1055,13 @@ func (clnt *ProxyClient) RegisterUDF(policy *WritePolicy, udfBody []byte, server
 	panic(notSupportedInProxyClient)
 }
 
+// RegisterUDFDir registers all the packages of the language in the client directory
+// with the server.
+// Not supported in the proxy client.
+func (clnt *ProxyClient) RegisterUDFDir(policy *WritePolicy, clientDir string, language Language) (*RegisterTask, Error) {
+	panic(notSupportedInProxyClient)
+}
+
 // RemoveUDF removes a package containing user defined functions in the server.
 // This asynchronous server call will return before command is complete.
 // The user can optionally wait for command completion by using the returned
@@ -1039,6 +1206,12 @@ func (clnt *ProxyClient) SetXDRFilter(policy *InfoPolicy, datacenter string, nam
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *ProxyClient) QueryPartitions(policy *QueryPolicy, statement *Statement, partitionFilter *PartitionFilter) (*Recordset, Error) {
 	policy = clnt.getUsableQueryPolicy(policy)
+	if len(policy.sortKeys) > 0 {
+		return querySorted(policy, func(policy *QueryPolicy) (*Recordset, Error) {
+			return clnt.QueryPartitions(policy, statement, partitionFilter)
+		})
+	}
+
 	// result recordset
 	tracker := newPartitionTracker(&policy.MultiPolicy, partitionFilter, nil)
 	res := newRecordset(policy.RecordQueueSize, 1)
@@ -1073,6 +1246,12 @@ func (clnt *ProxyClient) queryNodePartitions(policy *QueryPolicy, node *Node, st
 	panic(notSupportedInProxyClient)
 }
 
+// QueryNodePartitions executes a query on a specific node for the partition filter.
+// Not supported in the proxy client.
+func (clnt *ProxyClient) QueryNodePartitions(policy *QueryPolicy, node *Node, statement *Statement, partitionFilter *PartitionFilter) (*Recordset, Error) {
+	panic(notSupportedInProxyClient)
+}
+
 //--------------------------------------------------------
 // Index functions (Supported by Aerospike 3+ servers only)
 //--------------------------------------------------------
@@ -1136,6 +1315,30 @@ func (clnt *ProxyClient) Truncate(policy *InfoPolicy, namespace, set string, bef
 	panic(notSupportedInProxyClient)
 }
 
+// TruncateWithPolicy removes records in specified namespace/set, similar to Truncate.
+// Not supported in the proxy client.
+func (clnt *ProxyClient) TruncateWithPolicy(policy *TruncatePolicy, namespace, set string, beforeLastUpdate time.Time) (*TruncateResult, Error) {
+	panic(notSupportedInProxyClient)
+}
+
+// ListSets returns the metadata of the sets of the namespace.
+// Not supported in the proxy client.
+func (clnt *ProxyClient) ListSets(policy *InfoPolicy, namespace string) ([]*SetInfo, Error) {
+	panic(notSupportedInProxyClient)
+}
+
+// DeleteSet removes all the records of the set, and waits until they are removed.
+// Not supported in the proxy client.
+func (clnt *ProxyClient) DeleteSet(policy *DeleteSetPolicy, namespace, set string) Error {
+	panic(notSupportedInProxyClient)
+}
+
+// DropSetIndexes drops all the secondary indexes of the set.
+// Not supported in the proxy client.
+func (clnt *ProxyClient) DropSetIndexes(policy *WritePolicy, namespace, set string) ([]string, Error) {
+	panic(notSupportedInProxyClient)
+}
+
 //-------------------------------------------------------
 // User administration
 //-------------------------------------------------------
@@ -1238,6 +1441,7 @@ func (clnt *ProxyClient) RequestInfo(policy *InfoPolicy, commands ...string) (ma
 	if err != nil {
 		return nil, err
 	}
+	defer clnt.returnGrpcConnToPool(conn)
 
 	client := kvs.NewInfoClient(conn)
 
@@ -1249,8 +1453,6 @@ func (clnt *ProxyClient) RequestInfo(policy *InfoPolicy, commands ...string) (ma
 		return nil, newGrpcError(false, gerr, gerr.Error())
 	}
 
-	defer clnt.returnGrpcConnToPool(conn)
-
 	if res.GetStatus() != 0 {
 		return nil, newGrpcStatusError(res)
 	}
@@ -1283,25 +1485,60 @@ func (clnt *ProxyClient) Stats() (map[string]interface{}, Error) {
 	panic(notSupportedInProxyClient)
 }
 
-// WarmUp fills the connection pool with connections for all nodes.
+// MetricsEnabled returns true if metrics are enabled for the cluster.
+// Metrics are not supported in the proxy client, so it always returns false.
+func (clnt *ProxyClient) MetricsEnabled() bool {
+	return false
+}
+
+// EnableMetrics enables the cluster transaction metrics gathering.
+// Not supported in the proxy client.
+func (clnt *ProxyClient) EnableMetrics(policy *MetricsPolicy) {
+	panic(notSupportedInProxyClient)
+}
+
+// DisableMetrics disables the cluster transaction metrics gathering.
+// Not supported in the proxy client.
+func (clnt *ProxyClient) DisableMetrics() {
+	panic(notSupportedInProxyClient)
+}
+
+// QuiesceNode is not supported in the proxy client, since it does not route the commands to the nodes.
+func (clnt *ProxyClient) QuiesceNode(nodeName string) Error {
+	panic(notSupportedInProxyClient)
+}
+
+// HotKeys is not supported in the proxy client.
+func (clnt *ProxyClient) HotKeys() []HotKey {
+	panic(notSupportedInProxyClient)
+}
+
+// WireCaptures is not supported in the proxy client.
+func (clnt *ProxyClient) WireCaptures() []WireCapture {
+	panic(notSupportedInProxyClient)
+}
+
+// UnquiesceNode is not supported in the proxy client, since it does not route the commands to the nodes.
+func (clnt *ProxyClient) UnquiesceNode(nodeName string) {
+	panic(notSupportedInProxyClient)
+}
+
+// TendNow is not supported in the proxy client, since it does not tend the cluster.
+func (clnt *ProxyClient) TendNow() Error {
+	panic(notSupportedInProxyClient)
+}
+
+// WarmUp opens the gRPC channels to the proxy endpoints, and returns the number of open channels.
 // This is necessary on startup for high traffic programs.
-// If the count is <= 0, the connection queue will be filled.
-// If the count is more than the size of the pool, the pool will be filled.
-// Note: One connection per node is reserved for tend operations and is not used for transactions.
+// If the count is <= 0, all the channels will be opened.
+// If the count is more than the number of channels, all the channels will be opened.
+// The channels are spread over the endpoints.
 func (clnt *ProxyClient) WarmUp(count int) (int, Error) {
-	if count <= 0 || count > clnt.clientPolicy.ConnectionQueueSize {
-		count = clnt.clientPolicy.ConnectionQueueSize
+	if count <= 0 || count > clnt.grpcConnPool.capacity() {
+		count = clnt.grpcConnPool.capacity()
 	}
 
-	for i := 0; i < count; i++ {
-		conn, err := clnt.createGrpcConn(!clnt.clientPolicy.RequiresAuthentication())
-		if err != nil {
-			return i, err
-		}
-		clnt.returnGrpcConnToPool(conn)
-	}
-
-	return count, nil
+	return clnt.grpcConnPool.warmUp(count)
 }
 
 //-------------------------------------------------------
@@ -1435,97 +1672,3 @@ func (clnt *ProxyClient) getUsableInfoPolicy(policy *InfoPolicy) *InfoPolicy {
 	}
 	return policy
 }
-
-//-------------------------------------------------------
-// Utility Functions
-//-------------------------------------------------------
-
-// grpcConnectionHeap is a non-blocking LIFO heap.
-// If the heap is empty, nil is returned.
-// if the heap is full, offer will return false
-type grpcConnectionHeap struct {
-	head, tail uint32
-	data       []*grpc.ClientConn
-	size       uint32
-	full       bool
-	mutex      sync.Mutex
-}
-
-// newGrpcConnectionHeap creates a new heap with initial size.
-func newGrpcConnectionHeap(size int) *grpcConnectionHeap {
-	if size <= 0 {
-		panic("Heap size cannot be less than 1")
-	}
-
-	return &grpcConnectionHeap{
-		full: false,
-		data: make([]*grpc.ClientConn, uint32(size)),
-		size: uint32(size),
-	}
-}
-
-func (h *grpcConnectionHeap) cleanup() {
-	h.mutex.Lock()
-	defer h.mutex.Unlock()
-
-	for i := range h.data {
-		if h.data[i] != nil {
-			h.data[i].Close()
-		}
-
-		h.data[i] = nil
-	}
-
-	// make sure offer and poll both fail
-	h.data = nil
-	h.full = true
-	h.head = 0
-	h.tail = 0
-}
-
-// Put adds an item to the heap unless the heap is full.
-// In case the heap is full, the item will not be added to the heap
-// and false will be returned
-func (h *grpcConnectionHeap) Put(conn *grpc.ClientConn) bool {
-	h.mutex.Lock()
-
-	// make sure heap is not full or cleaned up
-	if h.full || len(h.data) == 0 {
-		h.mutex.Unlock()
-		return false
-	}
-
-	h.head = (h.head + 1) % h.size
-	h.full = (h.head == h.tail)
-	h.data[h.head] = conn
-	h.mutex.Unlock()
-	return true
-}
-
-// Poll removes and returns an item from the heap.
-// If the heap is empty, nil will be returned.
-func (h *grpcConnectionHeap) Get() (res *grpc.ClientConn) {
-	h.mutex.Lock()
-
-	// the heap has been cleaned up
-	if len(h.data) == 0 {
-		h.mutex.Unlock()
-		return nil
-	}
-
-	// if heap is not empty
-	if (h.tail != h.head) || h.full {
-		res = h.data[h.head]
-		h.data[h.head] = nil
-
-		h.full = false
-		if h.head == 0 {
-			h.head = h.size - 1
-		} else {
-			h.head--
-		}
-	}
-
-	h.mutex.Unlock()
-	return res
-}
@@ -34,15 +34,22 @@ import (
 //			Name string `as:"name"`
 //	 		Address string `as:"desc,omitempty"`
 //	 		Age uint8 `as:",omitempty"`
+//	 		Born time.Time `as:"born,omitzero"`
+//	 		Home Address `asm:"prefix=home_"`
 //	 		Password string `as:"-"`
 //	 }
 //
 // Tag `as:` denotes Aerospike fields. The first value will be the alias for the field.
 // `,omitempty` (without any spaces between the comma and the word) will act like the
 // json package, and will not send the value of the field to the database if the value is zero value.
+// `,omitzero` will not send the value of the field if it is the zero value of its type,
+// or if the value has an `IsZero() bool` method returning true, like time.Time.
 // Tag `asm:` denotes Aerospike Meta fields, and includes ttl and generation values.
+// Tag `asm:"prefix=..."` flattens the fields of a nested struct into the bins of the record,
+// prefixing their names, e.g. the `Street` field of `Home` above will be stored in bin `home_Street`.
 // If a tag is marked with `-`, it will not be sent to the database at all.
 // Note: Tag `as` can be replaced with any other user-defined tag via the function `SetAerospikeTag`.
+// The names of the fields without an alias can be mapped to bin names via the function `SetNameMapper`.
 func (clnt *ProxyClient) PutObject(policy *WritePolicy, key *Key, obj interface{}) (err Error) {
 	policy = clnt.getUsableWritePolicy(policy)
 
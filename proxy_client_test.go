@@ -17,7 +17,13 @@
 package aerospike_test
 
 import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
 	as "github.com/aerospike/aerospike-client-go/v7"
+	ast "github.com/aerospike/aerospike-client-go/v7/types"
 
 	gg "github.com/onsi/ginkgo/v2"
 	gm "github.com/onsi/gomega"
@@ -39,4 +45,78 @@ var _ = gg.Describe("Aerospike Proxy Client", func() {
 		})
 	})
 
+	gg.Describe("Load balancing on proxy client", func() {
+		gg.BeforeEach(func() {
+			if !*proxy {
+				gg.Skip("Only supported in grpc environment")
+			}
+		})
+
+		for _, lb := range []as.ProxyLoadBalancing{as.ProxyRoundRobin, as.ProxyLeastInFlight} {
+			lb := lb
+
+			gg.It("must balance the commands between the endpoints", func() {
+				cp := *clientPolicy
+				cp.ProxyLoadBalancing = lb
+				cp.ProxyChannelsPerEndpoint = 2
+				cp.ProxyHealthCheckInterval = 100 * time.Millisecond
+
+				dbHost := as.NewHost(*host, *port)
+				dbHost.TLSName = *nodeTLSName
+
+				pclient, err := as.NewProxyClientWithPolicyAndHosts(&cp, []*as.Host{dbHost, dbHost})
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				defer pclient.Close()
+
+				count, err := pclient.WarmUp(0)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(count).To(gm.Equal(4))
+
+				set := randString(50)
+				var wg sync.WaitGroup
+				for i := 0; i < 20; i++ {
+					wg.Add(1)
+					go func() {
+						defer gg.GinkgoRecover()
+						defer wg.Done()
+
+						key, err := as.NewKey(*namespace, set, randString(50))
+						gm.Expect(err).ToNot(gm.HaveOccurred())
+						gm.Expect(pclient.PutBins(nil, key, as.NewBin("a", 1))).ToNot(gm.HaveOccurred())
+
+						rec, err := pclient.Get(nil, key)
+						gm.Expect(err).ToNot(gm.HaveOccurred())
+						gm.Expect(rec.Bins["a"]).To(gm.Equal(1))
+					}()
+				}
+				wg.Wait()
+			})
+		}
+	})
+
+	gg.Describe("Token sources on proxy client", func() {
+		gg.BeforeEach(func() {
+			if !*proxy {
+				gg.Skip("Only supported in grpc environment")
+			}
+		})
+
+		gg.It("must fail to create the client when the token source fails", func() {
+			calls := 0
+			cp := *clientPolicy
+			cp.ProxyTokenSource = as.ProxyTokenSourceFunc(func(ctx context.Context) (*as.ProxyToken, error) {
+				calls++
+				return nil, errors.New("token provider is down")
+			})
+
+			dbHost := as.NewHost(*host, *port)
+			dbHost.TLSName = *nodeTLSName
+
+			_, err := as.NewProxyClientWithPolicyAndHost(&cp, dbHost)
+			gm.Expect(err).To(gm.HaveOccurred())
+			gm.Expect(err.Matches(ast.NOT_AUTHENTICATED)).To(gm.BeTrue())
+			gm.Expect(calls).To(gm.Equal(1))
+		})
+	})
+
 })
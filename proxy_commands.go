@@ -27,6 +27,7 @@ func (cmd *readCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 	if err != nil {
 		return err
 	}
+	defer clnt.returnGrpcConnToPool(conn)
 
 	client := kvs.NewKVSClient(conn)
 
@@ -40,8 +41,6 @@ func (cmd *readCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 
 	cmd.commandWasSent = true
 
-	defer clnt.returnGrpcConnToPool(conn)
-
 	if res.GetStatus() != 0 {
 		return newGrpcStatusError(res)
 	}
@@ -75,6 +74,7 @@ func (cmd *batchCommandOperate) ExecuteGRPC(clnt *ProxyClient) Error {
 	if err != nil {
 		return err
 	}
+	defer clnt.returnGrpcConnToPool(conn)
 
 	client := kvs.NewKVSClient(conn)
 
@@ -115,8 +115,6 @@ func (cmd *batchCommandOperate) ExecuteGRPC(clnt *ProxyClient) Error {
 		return err
 	}
 
-	clnt.returnGrpcConnToPool(conn)
-
 	return nil
 }
 
@@ -139,6 +137,7 @@ func (cmd *deleteCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 	if err != nil {
 		return err
 	}
+	defer clnt.returnGrpcConnToPool(conn)
 
 	client := kvs.NewKVSClient(conn)
 
@@ -152,8 +151,6 @@ func (cmd *deleteCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 
 	cmd.commandWasSent = true
 
-	defer clnt.returnGrpcConnToPool(conn)
-
 	if res.GetStatus() != 0 {
 		return newGrpcStatusError(res)
 	}
@@ -186,6 +183,7 @@ func (cmd *executeCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 	if err != nil {
 		return err
 	}
+	defer clnt.returnGrpcConnToPool(conn)
 
 	client := kvs.NewKVSClient(conn)
 
@@ -199,8 +197,6 @@ func (cmd *executeCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 
 	cmd.commandWasSent = true
 
-	defer clnt.returnGrpcConnToPool(conn)
-
 	if res.GetStatus() != 0 {
 		return newGrpcStatusError(res)
 	}
@@ -233,6 +229,7 @@ func (cmd *existsCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 	if err != nil {
 		return err
 	}
+	defer clnt.returnGrpcConnToPool(conn)
 
 	client := kvs.NewKVSClient(conn)
 
@@ -246,8 +243,6 @@ func (cmd *existsCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 
 	cmd.commandWasSent = true
 
-	defer clnt.returnGrpcConnToPool(conn)
-
 	if res.GetStatus() != 0 {
 		return newGrpcStatusError(res)
 	}
@@ -280,6 +275,7 @@ func (cmd *operateCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 	if err != nil {
 		return err
 	}
+	defer clnt.returnGrpcConnToPool(conn)
 
 	client := kvs.NewKVSClient(conn)
 
@@ -293,8 +289,6 @@ func (cmd *operateCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 
 	cmd.commandWasSent = true
 
-	defer clnt.returnGrpcConnToPool(conn)
-
 	if res.GetStatus() != 0 {
 		return newGrpcStatusError(res)
 	}
@@ -327,6 +321,7 @@ func (cmd *readHeaderCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 	if err != nil {
 		return err
 	}
+	defer clnt.returnGrpcConnToPool(conn)
 
 	client := kvs.NewKVSClient(conn)
 
@@ -340,8 +335,6 @@ func (cmd *readHeaderCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 
 	cmd.commandWasSent = true
 
-	defer clnt.returnGrpcConnToPool(conn)
-
 	if res.GetStatus() != 0 {
 		return newGrpcStatusError(res)
 	}
@@ -379,6 +372,7 @@ func (cmd *serverCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 	if err != nil {
 		return err
 	}
+	defer clnt.returnGrpcConnToPool(conn)
 
 	client := kvs.NewQueryClient(conn)
 
@@ -417,8 +411,6 @@ func (cmd *serverCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 		return err
 	}
 
-	clnt.returnGrpcConnToPool(conn)
-
 	return nil
 }
 
@@ -441,6 +433,7 @@ func (cmd *touchCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 	if err != nil {
 		return err
 	}
+	defer clnt.returnGrpcConnToPool(conn)
 
 	client := kvs.NewKVSClient(conn)
 
@@ -454,8 +447,6 @@ func (cmd *touchCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 
 	cmd.commandWasSent = true
 
-	defer clnt.returnGrpcConnToPool(conn)
-
 	if res.GetStatus() != 0 {
 		return newGrpcStatusError(res)
 	}
@@ -488,6 +479,7 @@ func (cmd *writeCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 	if err != nil {
 		return err
 	}
+	defer clnt.returnGrpcConnToPool(conn)
 
 	client := kvs.NewKVSClient(conn)
 
@@ -501,8 +493,6 @@ func (cmd *writeCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 
 	cmd.commandWasSent = true
 
-	defer clnt.returnGrpcConnToPool(conn)
-
 	if res.GetStatus() != 0 {
 		return newGrpcStatusError(res)
 	}
@@ -0,0 +1,354 @@
+//go:build as_proxy
+
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+
+	iatomic "github.com/aerospike/aerospike-client-go/v7/internal/atomic"
+	"github.com/aerospike/aerospike-client-go/v7/logger"
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+const defaultProxyChannelsPerEndpoint = 4
+
+// proxyChannel is a gRPC channel shared by the concurrent commands sent to an endpoint.
+type proxyChannel struct {
+	endpoint *proxyEndpoint
+	conn     *grpc.ClientConn
+	inFlight iatomic.Int
+}
+
+// proxyEndpoint holds the channels of a proxy server.
+type proxyEndpoint struct {
+	host *Host
+
+	mutex sync.Mutex
+	// channels are dialed on demand; the slice grows up to the channel count of the pool.
+	channels []*proxyChannel
+
+	inFlight  iatomic.Int
+	unhealthy iatomic.Bool
+}
+
+// proxyConnPool balances the commands of the proxy client between the channels
+// of the proxy endpoints.
+type proxyConnPool struct {
+	clnt      *ProxyClient
+	endpoints []*proxyEndpoint
+
+	balancing    ProxyLoadBalancing
+	channels     int
+	maxStreams   int
+	nextEndpoint iatomic.Int
+
+	// conns maps the dialed connections to their channels, to release them after use.
+	conns sync.Map
+
+	closed iatomic.Bool
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newProxyConnPool(clnt *ProxyClient, hosts []*Host) *proxyConnPool {
+	policy := &clnt.clientPolicy
+
+	p := &proxyConnPool{
+		clnt:       clnt,
+		endpoints:  make([]*proxyEndpoint, len(hosts)),
+		balancing:  policy.ProxyLoadBalancing,
+		channels:   policy.ProxyChannelsPerEndpoint,
+		maxStreams: policy.ProxyStreamsPerChannel,
+		done:       make(chan struct{}),
+	}
+
+	if p.channels <= 0 {
+		p.channels = defaultProxyChannelsPerEndpoint
+	}
+
+	for i := range hosts {
+		p.endpoints[i] = &proxyEndpoint{host: hosts[i]}
+	}
+
+	if policy.ProxyHealthCheckInterval > 0 {
+		p.wg.Add(1)
+		go p.healthCheck(policy.ProxyHealthCheckInterval)
+	}
+
+	return p
+}
+
+// get returns a connection of the least loaded channel of the selected endpoint.
+// The connection must be released with put after use.
+// If the endpoint cannot be dialed, the next endpoints are tried.
+func (p *proxyConnPool) get() (*grpc.ClientConn, Error) {
+	if p.closed.Get() {
+		return nil, newError(types.NO_AVAILABLE_CONNECTIONS_TO_NODE, "Proxy client is closed")
+	}
+
+	first := p.selectEndpoint()
+	var errs Error
+	for i := range p.endpoints {
+		ep := p.endpoints[(first+i)%len(p.endpoints)]
+		ch, err := p.acquire(ep)
+		if err != nil {
+			errs = chainErrors(err, errs)
+			continue
+		}
+		return ch.conn, nil
+	}
+
+	return nil, errs
+}
+
+// put releases a connection returned by get.
+func (p *proxyConnPool) put(conn *grpc.ClientConn) {
+	if conn == nil {
+		return
+	}
+
+	ch, ok := p.conns.Load(conn)
+	if !ok {
+		// the connection does not belong to the pool
+		conn.Close()
+		return
+	}
+
+	ch.(*proxyChannel).inFlight.DecrementAndGet()
+	ch.(*proxyChannel).endpoint.inFlight.DecrementAndGet()
+}
+
+// selectEndpoint returns the index of the endpoint chosen by the load balancing policy.
+// Unhealthy endpoints are skipped unless all the endpoints are unhealthy.
+func (p *proxyConnPool) selectEndpoint() int {
+	n := len(p.endpoints)
+	if n == 1 {
+		return 0
+	}
+
+	switch p.balancing {
+	case ProxyLeastInFlight:
+		best, bestInFlight, bestHealthy := 0, 0, false
+		for i, ep := range p.endpoints {
+			healthy := !ep.unhealthy.Get()
+			inFlight := ep.inFlight.Get()
+			if i == 0 || (healthy && !bestHealthy) || (healthy == bestHealthy && inFlight < bestInFlight) {
+				best, bestInFlight, bestHealthy = i, inFlight, healthy
+			}
+		}
+		return best
+	default:
+		start := p.nextEndpoint.GetAndIncrement() % n
+		if start < 0 {
+			start += n
+		}
+		for i := 0; i < n; i++ {
+			if idx := (start + i) % n; !p.endpoints[idx].unhealthy.Get() {
+				return idx
+			}
+		}
+		return start
+	}
+}
+
+// acquire reserves a stream on the least loaded channel of the endpoint.
+// A new channel is dialed if all the channels are in use and the endpoint has room for more.
+func (p *proxyConnPool) acquire(ep *proxyEndpoint) (*proxyChannel, Error) {
+	ep.mutex.Lock()
+	defer ep.mutex.Unlock()
+
+	var best *proxyChannel
+	bestInFlight := 0
+	for _, ch := range ep.channels {
+		if inFlight := ch.inFlight.Get(); best == nil || inFlight < bestInFlight {
+			best, bestInFlight = ch, inFlight
+		}
+	}
+
+	if (best == nil || bestInFlight > 0) && len(ep.channels) < p.channels {
+		ch, err := p.dialChannel(ep)
+		if err != nil {
+			if best == nil {
+				return nil, err
+			}
+			logger.Logger.Debug("Failed to open a new channel to proxy endpoint %s: %s", ep.host, err.Error())
+		} else {
+			best, bestInFlight = ch, 0
+		}
+	}
+
+	if p.maxStreams > 0 && bestInFlight >= p.maxStreams {
+		return nil, newError(types.NO_AVAILABLE_CONNECTIONS_TO_NODE, "All the channels of proxy endpoint "+ep.host.String()+" reached ClientPolicy.ProxyStreamsPerChannel")
+	}
+
+	best.inFlight.IncrementAndGet()
+	ep.inFlight.IncrementAndGet()
+	return best, nil
+}
+
+// dialChannel opens a new channel to the endpoint. Must be called while holding the endpoint mutex.
+func (p *proxyConnPool) dialChannel(ep *proxyEndpoint) (*proxyChannel, Error) {
+	conn, err := p.clnt.dialGrpcConn(ep.host, !p.clnt.requiresAuthentication())
+	if err != nil {
+		ep.unhealthy.Set(true)
+		return nil, err
+	}
+
+	ch := &proxyChannel{endpoint: ep, conn: conn}
+	ep.channels = append(ep.channels, ch)
+	p.conns.Store(conn, ch)
+	return ch, nil
+}
+
+// dialAny opens a connection that does not belong to the pool to one of the endpoints.
+// The caller is responsible for closing the connection.
+func (p *proxyConnPool) dialAny(noInterceptor bool) (*grpc.ClientConn, Error) {
+	first := p.selectEndpoint()
+	var errs Error
+	for i := range p.endpoints {
+		conn, err := p.clnt.dialGrpcConn(p.endpoints[(first+i)%len(p.endpoints)].host, noInterceptor)
+		if err != nil {
+			errs = chainErrors(err, errs)
+			continue
+		}
+		return conn, nil
+	}
+	return nil, errs
+}
+
+// warmUp opens up to count channels in total, spread over the endpoints,
+// and returns the number of open channels.
+func (p *proxyConnPool) warmUp(count int) (int, Error) {
+	total := 0
+	for _, ep := range p.endpoints {
+		ep.mutex.Lock()
+		total += len(ep.channels)
+		ep.mutex.Unlock()
+	}
+
+	for total < count {
+		opened := false
+		for _, ep := range p.endpoints {
+			if total >= count {
+				break
+			}
+
+			ep.mutex.Lock()
+			if len(ep.channels) < p.channels {
+				if _, err := p.dialChannel(ep); err != nil {
+					ep.mutex.Unlock()
+					return total, err
+				}
+				total++
+				opened = true
+			}
+			ep.mutex.Unlock()
+		}
+
+		if !opened {
+			break
+		}
+	}
+
+	return total, nil
+}
+
+// capacity returns the maximum number of channels of the pool.
+func (p *proxyConnPool) capacity() int {
+	return p.channels * len(p.endpoints)
+}
+
+// healthCheck marks the endpoints whose channels all failed as unhealthy, until they reconnect.
+func (p *proxyConnPool) healthCheck(interval time.Duration) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			for _, ep := range p.endpoints {
+				p.checkEndpoint(ep)
+			}
+		}
+	}
+}
+
+func (p *proxyConnPool) checkEndpoint(ep *proxyEndpoint) {
+	ep.mutex.Lock()
+	defer ep.mutex.Unlock()
+
+	if len(ep.channels) == 0 {
+		if !ep.unhealthy.Get() {
+			return
+		}
+
+		// the endpoint could not be dialed; retry
+		if _, err := p.dialChannel(ep); err != nil {
+			return
+		}
+	}
+
+	healthy := false
+	for _, ch := range ep.channels {
+		switch ch.conn.GetState() {
+		case connectivity.Idle:
+			// idle channels are fine, but reconnect them so that they are ready for the next command
+			ch.conn.Connect()
+			healthy = true
+		case connectivity.TransientFailure, connectivity.Shutdown:
+		default:
+			healthy = true
+		}
+	}
+
+	if ep.unhealthy.Get() == healthy {
+		if healthy {
+			logger.Logger.Info("Proxy endpoint %s is healthy again", ep.host)
+		} else {
+			logger.Logger.Warn("Proxy endpoint %s is unhealthy; its channels failed to connect", ep.host)
+		}
+	}
+	ep.unhealthy.Set(!healthy)
+}
+
+// close closes all the channels and stops the health checks.
+func (p *proxyConnPool) close() {
+	if !p.closed.CompareAndToggle(false) {
+		return
+	}
+
+	close(p.done)
+	p.wg.Wait()
+
+	for _, ep := range p.endpoints {
+		ep.mutex.Lock()
+		for _, ch := range ep.channels {
+			p.conns.Delete(ch.conn)
+			ch.conn.Close()
+		}
+		ep.channels = nil
+		ep.mutex.Unlock()
+	}
+}
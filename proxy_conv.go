@@ -7,10 +7,15 @@ import (
 	"math/rand"
 	"time"
 
+	"google.golang.org/grpc/metadata"
+
 	kvs "github.com/aerospike/aerospike-client-go/v7/proto/kvs"
 	"github.com/aerospike/aerospike-client-go/v7/types"
 )
 
+// auditContextHeader is the gRPC header for BasePolicy.AuditContext.
+const auditContextHeader = "aerospike-audit-context"
+
 func (fltr *Filter) grpc() *kvs.Filter {
 	if fltr == nil {
 		return nil
@@ -80,7 +85,7 @@ func (pf *PartitionFilter) grpc() *kvs.PartitionFilter {
 		Count:             uint32(pf.Count),
 		Digest:            pf.Digest,
 		PartitionStatuses: ps,
-		Retry:             true,
+		Retry:             pf.Retry,
 	}
 
 }
@@ -110,13 +115,17 @@ func (p *BasePolicy) grpc() *kvs.ReadPolicy {
 }
 
 func (p *BasePolicy) grpcDeadlineContext() (context.Context, context.CancelFunc) {
+	ctx := context.Background()
+	if p.AuditContext != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, auditContextHeader, p.AuditContext)
+	}
+
 	timeout := p.timeout()
 	if timeout <= 0 {
-		return context.Background(), simpleCancelFunc
+		return ctx, simpleCancelFunc
 
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	return ctx, cancel
+	return context.WithTimeout(ctx, timeout)
 }
 
 ///////////////////////////////////////////////////////////////////
@@ -474,6 +483,9 @@ func (rp ReplicaPolicy) grpc() kvs.Replica {
 		return kvs.Replica_SEQUENCE
 	case PREFER_RACK:
 		return kvs.Replica_PREFER_RACK
+	case LEAST_LOADED:
+		// the proxy server does not track the in-flight commands of the nodes
+		return kvs.Replica_SEQUENCE
 	}
 	panic(unreachable)
 }
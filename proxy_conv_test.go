@@ -0,0 +1,47 @@
+//go:build as_proxy
+
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"google.golang.org/grpc/metadata"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Proxy policy conversion", func() {
+
+	gg.It("must send the audit context in the gRPC header", func() {
+		policy := NewPolicy()
+		policy.AuditContext = "tenant=acme"
+		ctx, cancel := policy.grpcDeadlineContext()
+		defer cancel()
+
+		md, ok := metadata.FromOutgoingContext(ctx)
+		gm.Expect(ok).To(gm.BeTrue())
+		gm.Expect(md.Get(auditContextHeader)).To(gm.Equal([]string{"tenant=acme"}))
+		_, hasDeadline := ctx.Deadline()
+		gm.Expect(hasDeadline).To(gm.BeTrue())
+
+		policy = NewPolicy()
+		policy.TotalTimeout = 0
+		ctx, cancel = policy.grpcDeadlineContext()
+		defer cancel()
+		_, ok = metadata.FromOutgoingContext(ctx)
+		gm.Expect(ok).To(gm.BeFalse())
+	})
+})
@@ -51,6 +51,7 @@ func (etsk *ExecuteTask) grpcIsDone() (bool, Error) {
 	if err != nil {
 		return false, err
 	}
+	defer clnt.returnGrpcConnToPool(conn)
 
 	client := kvs.NewQueryClient(conn)
 
@@ -73,16 +74,13 @@ func (etsk *ExecuteTask) grpcIsDone() (bool, Error) {
 
 		if res.GetStatus() != 0 {
 			e := newGrpcStatusError(res)
-			clnt.returnGrpcConnToPool(conn)
 			return false, e
 		}
 
 		switch res.GetBackgroundTaskStatus() {
 		case kvs.BackgroundTaskStatus_COMPLETE:
-			clnt.returnGrpcConnToPool(conn)
 			return true, nil
 		default:
-			clnt.returnGrpcConnToPool(conn)
 			return false, nil
 		}
 	}
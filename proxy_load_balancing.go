@@ -0,0 +1,30 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+// ProxyLoadBalancing determines how the proxy client distributes the commands
+// between the proxy endpoints.
+type ProxyLoadBalancing int
+
+const (
+	// ProxyRoundRobin sends the commands to the healthy endpoints in turn.
+	// This is the default behavior.
+	ProxyRoundRobin ProxyLoadBalancing = iota
+
+	// ProxyLeastInFlight sends the commands to the healthy endpoint with the fewest
+	// commands in flight. This option is useful when the endpoints do not have the same capacity,
+	// or some of them are slower than the others.
+	ProxyLeastInFlight
+)
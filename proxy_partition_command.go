@@ -0,0 +1,118 @@
+//go:build as_proxy
+
+// Copyright 2014-2022 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"sort"
+	"time"
+
+	kvs "github.com/aerospike/aerospike-client-go/v7/proto/kvs"
+)
+
+// grpcPartitionStream sends a scan or query stream to the proxy server for the partitions of np,
+// using the policy limits of the current iteration of the tracker.
+type grpcPartitionStream func(np *nodePartitions, filter *kvs.PartitionFilter) Error
+
+// executeGRPCPartitions runs the partition scan/query through the proxy server like the native
+// client does on the cluster nodes: the partitions are split into contiguous ranges streamed one
+// after the other, and the ranges that fail with a retryable error or return unavailable
+// partitions are retried from their last digest until the scan/query completes.
+func (cmd *baseMultiCommand) executeGRPCPartitions(policy *MultiPolicy, stream grpcPartitionStream) Error {
+	tracker := cmd.tracker
+
+	// for exponential backoff
+	interval := policy.SleepBetweenRetries
+
+	var errs Error
+	for {
+		list := tracker.assignPartitionsToRanges()
+
+		for _, np := range list {
+			if !cmd.recordset.IsActive() {
+				break
+			}
+
+			cmd.nodePartitions = np
+			cmd.grpcEOS = false
+			if err := stream(np, np.grpcPartitionFilter()); err != nil && err != errGRPCStreamEnd {
+				if !tracker.shouldRetry(np, err) {
+					errs = chainErrors(err, errs)
+				}
+			}
+		}
+
+		if done, err := tracker.isComplete(false, &policy.BasePolicy, list); !cmd.recordset.IsActive() || done || err != nil {
+			errs = chainErrors(err, errs)
+			// Scan/Query is complete.
+			if errs != nil {
+				tracker.partitionError()
+				cmd.recordset.sendError(errs)
+			}
+			return errs
+		}
+
+		if policy.SleepBetweenRetries > 0 {
+			// Sleep before trying again.
+			time.Sleep(interval)
+
+			if policy.SleepMultiplier > 1 {
+				interval = time.Duration(float64(interval) * policy.SleepMultiplier)
+			}
+		}
+
+		cmd.recordset.resetTaskID()
+	}
+}
+
+// grpcStreamPolicy sets the record and time limits of the current iteration on the policy of a stream.
+func (pt *partitionTracker) grpcStreamPolicy(policy *MultiPolicy, np *nodePartitions) {
+	if pt.maxRecords > 0 {
+		policy.MaxRecords = np.recordMax
+	}
+
+	if pt.iteration > 1 {
+		policy.TotalTimeout = pt.totalTimeout
+		policy.SocketTimeout = pt.socketTimeout
+	}
+}
+
+// grpcPartitionFilter returns the filter of the contiguous partition range of np.
+// The partitions that were already partially read resume after their last digest.
+func (np *nodePartitions) grpcPartitionFilter() *kvs.PartitionFilter {
+	parts := make([]*PartitionStatus, 0, len(np.partsFull)+len(np.partsPartial))
+	parts = append(parts, np.partsFull...)
+	parts = append(parts, np.partsPartial...)
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Id < parts[j].Id })
+
+	ps := make([]*kvs.PartitionStatus, len(parts))
+	for i := range parts {
+		ps[i] = parts[i].grpc()
+		ps[i].Retry = true
+	}
+
+	var begin uint32
+	if len(parts) > 0 {
+		begin = uint32(parts[0].Id)
+	}
+
+	return &kvs.PartitionFilter{
+		Begin:             &begin,
+		Count:             uint32(len(parts)),
+		PartitionStatuses: ps,
+		Retry:             true,
+	}
+}
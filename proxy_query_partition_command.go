@@ -50,6 +50,7 @@ func newGrpcQueryPartitionCommand(
 		operations:       operations,
 	}
 	cmd.rawCDT = policy.RawCDT
+	cmd.projection = statement.projection()
 	cmd.tracker = partitionTracker
 	cmd.terminationErrorType = statement.terminationError()
 	cmd.nodePartitions = newNodePartitions(nil, _PARTITIONS)
@@ -80,6 +81,18 @@ func (cmd *grpcQueryPartitionCommand) Execute() Error {
 func (cmd *grpcQueryPartitionCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 	defer cmd.recordset.signalEnd()
 
+	policy := cmd.policy
+	return cmd.executeGRPCPartitions(&policy.MultiPolicy, func(np *nodePartitions, filter *kvs.PartitionFilter) Error {
+		streamPolicy := *policy
+		cmd.tracker.grpcStreamPolicy(&streamPolicy.MultiPolicy, np)
+		cmd.policy = &streamPolicy
+		defer func() { cmd.policy = policy }()
+
+		return cmd.executeStream(clnt, filter)
+	})
+}
+
+func (cmd *grpcQueryPartitionCommand) executeStream(clnt *ProxyClient, filter *kvs.PartitionFilter) Error {
 	defer cmd.grpcPutBufferBack()
 
 	err := cmd.prepareBuffer(cmd, cmd.policy.deadline())
@@ -89,7 +102,7 @@ func (cmd *grpcQueryPartitionCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 
 	queryReq := &kvs.QueryRequest{
 		Statement:       cmd.statement.grpc(cmd.policy, cmd.operations),
-		PartitionFilter: cmd.partitionFilter.grpc(),
+		PartitionFilter: filter,
 		QueryPolicy:     cmd.policy.grpc(),
 	}
 
@@ -104,6 +117,7 @@ func (cmd *grpcQueryPartitionCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 	if err != nil {
 		return err
 	}
+	defer clnt.returnGrpcConnToPool(conn)
 
 	client := kvs.NewQueryClient(conn)
 
@@ -124,15 +138,11 @@ func (cmd *grpcQueryPartitionCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 
 		res, gerr := streamRes.Recv()
 		if gerr != nil {
-			e := newGrpcError(!cmd.isRead(), gerr)
-			cmd.recordset.sendError(e)
-			return nil, e
+			return nil, newGrpcError(!cmd.isRead(), gerr)
 		}
 
 		if res.GetStatus() != 0 {
-			e := newGrpcStatusError(res)
-			cmd.recordset.sendError(e)
-			return res.GetPayload(), e
+			return res.GetPayload(), newGrpcStatusError(res)
 		}
 
 		cmd.grpcEOS = !res.GetHasNext()
@@ -141,22 +151,5 @@ func (cmd *grpcQueryPartitionCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 	}
 
 	cmd.conn = newGrpcFakeConnection(nil, readCallback)
-	err = cmd.parseResult(cmd, cmd.conn)
-	if err != nil && err != errGRPCStreamEnd {
-		cmd.recordset.sendError(err)
-		return err
-	}
-
-	done, err := cmd.tracker.isComplete(false, &cmd.policy.BasePolicy, []*nodePartitions{cmd.nodePartitions})
-	if !cmd.recordset.IsActive() || done || err != nil {
-		// Query is complete.
-		if err != nil {
-			cmd.tracker.partitionError()
-			cmd.recordset.sendError(err)
-		}
-	}
-
-	clnt.returnGrpcConnToPool(conn)
-
-	return nil
+	return cmd.parseResult(cmd, cmd.conn)
 }
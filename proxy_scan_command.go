@@ -81,6 +81,18 @@ func (cmd *grpcScanPartitionCommand) Execute() Error {
 func (cmd *grpcScanPartitionCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 	defer cmd.recordset.signalEnd()
 
+	policy := cmd.policy
+	return cmd.executeGRPCPartitions(&policy.MultiPolicy, func(np *nodePartitions, filter *kvs.PartitionFilter) Error {
+		streamPolicy := *policy
+		cmd.tracker.grpcStreamPolicy(&streamPolicy.MultiPolicy, np)
+		cmd.policy = &streamPolicy
+		defer func() { cmd.policy = policy }()
+
+		return cmd.executeStream(clnt, filter)
+	})
+}
+
+func (cmd *grpcScanPartitionCommand) executeStream(clnt *ProxyClient, filter *kvs.PartitionFilter) Error {
 	defer cmd.grpcPutBufferBack()
 
 	err := cmd.prepareBuffer(cmd, cmd.policy.deadline())
@@ -92,7 +104,7 @@ func (cmd *grpcScanPartitionCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 		Namespace:       cmd.namespace,
 		SetName:         &cmd.setName,
 		BinNames:        cmd.binNames,
-		PartitionFilter: cmd.partitionFilter.grpc(),
+		PartitionFilter: filter,
 		ScanPolicy:      cmd.policy.grpc(),
 	}
 
@@ -107,6 +119,7 @@ func (cmd *grpcScanPartitionCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 	if err != nil {
 		return err
 	}
+	defer clnt.returnGrpcConnToPool(conn)
 
 	client := kvs.NewScanClient(conn)
 
@@ -127,39 +140,18 @@ func (cmd *grpcScanPartitionCommand) ExecuteGRPC(clnt *ProxyClient) Error {
 
 		res, gerr := streamRes.Recv()
 		if gerr != nil {
-			e := newGrpcError(!cmd.isRead(), gerr)
-			cmd.recordset.sendError(e)
-			return nil, e
+			return nil, newGrpcError(!cmd.isRead(), gerr)
 		}
 
 		cmd.grpcEOS = !res.GetHasNext()
 
 		if res.GetStatus() != 0 {
-			e := newGrpcStatusError(res)
-			cmd.recordset.sendError(e)
-			return res.GetPayload(), e
+			return res.GetPayload(), newGrpcStatusError(res)
 		}
 
 		return res.GetPayload(), nil
 	}
 
 	cmd.conn = newGrpcFakeConnection(nil, readCallback)
-	err = cmd.parseResult(cmd, cmd.conn)
-	if err != nil && err != errGRPCStreamEnd {
-		cmd.recordset.sendError(err)
-		return err
-	}
-
-	done, err := cmd.tracker.isComplete(false, &cmd.policy.BasePolicy, []*nodePartitions{cmd.nodePartitions})
-	if !cmd.recordset.IsActive() || done || err != nil {
-		// Query is complete.
-		if err != nil {
-			cmd.tracker.partitionError()
-			cmd.recordset.sendError(err)
-		}
-	}
-
-	clnt.returnGrpcConnToPool(conn)
-
-	return nil
+	return cmd.parseResult(cmd, cmd.conn)
 }
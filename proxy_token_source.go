@@ -0,0 +1,48 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"context"
+	"time"
+)
+
+// ProxyToken is an access token sent to the proxy server to authenticate the commands.
+type ProxyToken struct {
+	// Value is the token, without the "Bearer " prefix.
+	Value string
+
+	// Expiry is the time when the token expires. The proxy client refreshes the token
+	// ClientPolicy.ProxyTokenRefreshBefore before it expires.
+	// A zero Expiry means the token does not expire, and is only refreshed when the
+	// proxy server rejects it.
+	Expiry time.Time
+}
+
+// ProxyTokenSource provides the access tokens of the proxy client, for example from an
+// OAuth2 or IAM provider. Token is called when the client is created, before the current token
+// expires, and when the proxy server rejects the current token as unauthenticated.
+// Calls to Token are not concurrent. The context carries the ClientPolicy.Timeout deadline.
+type ProxyTokenSource interface {
+	Token(ctx context.Context) (*ProxyToken, error)
+}
+
+// ProxyTokenSourceFunc is an adapter to use a function as a ProxyTokenSource.
+type ProxyTokenSourceFunc func(ctx context.Context) (*ProxyToken, error)
+
+// Token calls f(ctx).
+func (f ProxyTokenSourceFunc) Token(ctx context.Context) (*ProxyToken, error) {
+	return f(ctx)
+}
@@ -32,6 +32,7 @@ func newQueryCommand(node *Node, policy *QueryPolicy, writePolicy *WritePolicy,
 		operations:       operations,
 	}
 	res.rawCDT = policy.RawCDT
+	res.projection = statement.projection()
 
 	return res
 }
@@ -48,6 +49,10 @@ func (cmd *queryCommand) parseResult(ifc command, conn *Connection) Error {
 	return cmd.baseMultiCommand.parseResult(ifc, conn)
 }
 
+func (cmd *queryCommand) commandStatement() *Statement {
+	return cmd.statement
+}
+
 func (cmd *queryCommand) transactionType() transactionType {
 	return ttQuery
 }
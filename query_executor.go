@@ -20,6 +20,7 @@ import (
 
 func (clnt *Client) queryPartitions(policy *QueryPolicy, tracker *partitionTracker, statement *Statement, recordset *Recordset) {
 	defer recordset.signalEnd()
+	recordset.limitRate(policy.TotalRecordsPerSecond)
 
 	// for exponential backoff
 	interval := policy.SleepBetweenRetries
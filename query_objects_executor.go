@@ -25,6 +25,7 @@ import (
 
 func (clnt *Client) queryPartitionObjects(policy *QueryPolicy, tracker *partitionTracker, statement *Statement, rs *Recordset) Error {
 	defer rs.signalEnd()
+	rs.limitRate(policy.TotalRecordsPerSecond)
 
 	// for exponential backoff
 	interval := policy.SleepBetweenRetries
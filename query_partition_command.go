@@ -31,6 +31,7 @@ func newQueryPartitionCommand(
 		operations:       nil,
 	}
 	cmd.rawCDT = policy.RawCDT
+	cmd.projection = statement.projection()
 	cmd.terminationErrorType = statement.terminationError()
 	cmd.tracker = tracker
 	cmd.nodePartitions = nodePartitions
@@ -51,6 +52,10 @@ func (cmd *queryPartitionCommand) shouldRetry(e Error) bool {
 	return cmd.tracker != nil && cmd.tracker.shouldRetry(cmd.nodePartitions, e)
 }
 
+func (cmd *queryPartitionCommand) commandStatement() *Statement {
+	return cmd.statement
+}
+
 func (cmd *queryPartitionCommand) transactionType() transactionType {
 	return ttQuery
 }
@@ -31,6 +31,7 @@ func newQueryPartitionObjectsCommand(
 		operations:       nil,
 	}
 	cmd.terminationErrorType = statement.terminationError()
+	cmd.projection = statement.projection()
 	cmd.tracker = tracker
 	cmd.nodePartitions = nodePartitions
 	cmd.node = nodePartitions.node
@@ -50,6 +51,10 @@ func (cmd *queryPartitionObjectsCommand) shouldRetry(e Error) bool {
 	return cmd.tracker != nil && cmd.tracker.shouldRetry(cmd.nodePartitions, e)
 }
 
+func (cmd *queryPartitionObjectsCommand) commandStatement() *Statement {
+	return cmd.statement
+}
+
 func (cmd *queryPartitionObjectsCommand) transactionType() transactionType {
 	return ttQuery
 }
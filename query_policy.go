@@ -34,6 +34,9 @@ type QueryPolicy struct {
 	// For backwards compatibility: If ShortQuery is true, the query is treated as a short query and
 	// ExpectedDuration is ignored. If shortQuery is false, ExpectedDuration is used defaults to {@link QueryDuration#LONG}.
 	ShortQuery bool
+
+	// sortKeys are the bins the records are sorted on client-side. Set with SortBy.
+	sortKeys []querySortKey
 }
 
 // NewQueryPolicy generates a new QueryPolicy instance with default values.
@@ -54,3 +57,16 @@ func NewQueryPolicy() *QueryPolicy {
 		MultiPolicy: *NewMultiPolicy(),
 	}
 }
+
+// SortBy sorts the records of the query on the bin client-side, and returns the policy.
+// Calling SortBy again adds a secondary sort bin, used when the previous bins are equal.
+// Records without the bin are returned after the other records.
+//
+// The records of all the nodes are merged client-side, and returned once the query is
+// complete; if MaxRecords is set, only the top MaxRecords records are kept in memory
+// and returned. Sorting applies to Query and QueryPartitions. Since the whole partition
+// filter is read, the filter of a sorted query cannot be used to page through the records.
+func (p *QueryPolicy) SortBy(binName string, direction SortDirection) *QueryPolicy {
+	p.sortKeys = append(p.sortKeys[:len(p.sortKeys):len(p.sortKeys)], querySortKey{binName: binName, direction: direction})
+	return p
+}
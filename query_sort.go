@@ -0,0 +1,163 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"container/heap"
+)
+
+// SortDirection determines the order of the records sorted by QueryPolicy.SortBy.
+type SortDirection int
+
+const (
+	// SortAscending sorts the records from the lowest to the highest bin value.
+	SortAscending SortDirection = iota
+	// SortDescending sorts the records from the highest to the lowest bin value.
+	SortDescending
+)
+
+// querySortKey is a bin the query records are sorted on.
+type querySortKey struct {
+	binName   string
+	direction SortDirection
+}
+
+// sortedRecord is a record kept by the query sort, with its arrival order to break ties.
+type sortedRecord struct {
+	rec *Record
+	seq int
+}
+
+// querySortHeap keeps the records sorted in reverse, so that the root is the
+// record sorted last and can be dropped when the heap grows over its limit.
+type querySortHeap struct {
+	keys    []querySortKey
+	records []sortedRecord
+}
+
+// before returns true if the record a is sorted before the record b.
+// Records without the bin are sorted after the records with the bin, regardless of the direction.
+func (h *querySortHeap) before(a, b *sortedRecord) bool {
+	for _, k := range h.keys {
+		va, oka := a.rec.Bins[k.binName]
+		vb, okb := b.rec.Bins[k.binName]
+		if oka != okb {
+			return oka
+		} else if !oka {
+			continue
+		}
+
+		c := compareValues(va, vb)
+		if k.direction == SortDescending {
+			c = -c
+		}
+		if c != 0 {
+			return c < 0
+		}
+	}
+	return a.seq < b.seq
+}
+
+func (h *querySortHeap) Len() int           { return len(h.records) }
+func (h *querySortHeap) Less(i, j int) bool { return h.before(&h.records[j], &h.records[i]) }
+func (h *querySortHeap) Swap(i, j int)      { h.records[i], h.records[j] = h.records[j], h.records[i] }
+func (h *querySortHeap) Push(x interface{}) { h.records = append(h.records, x.(sortedRecord)) }
+func (h *querySortHeap) Pop() interface{} {
+	last := h.records[len(h.records)-1]
+	h.records[len(h.records)-1] = sortedRecord{}
+	h.records = h.records[:len(h.records)-1]
+	return last
+}
+
+// push adds the record to the heap, and drops the record sorted last if the heap has more than limit records.
+// A limit of zero keeps all the records.
+func (h *querySortHeap) push(rec *Record, seq int, limit int64) {
+	heap.Push(h, sortedRecord{rec: rec, seq: seq})
+	if limit > 0 && int64(len(h.records)) > limit {
+		heap.Pop(h)
+	}
+}
+
+// sorted empties the heap and returns its records in sort order.
+func (h *querySortHeap) sorted() []*Record {
+	res := make([]*Record, len(h.records))
+	for i := len(res) - 1; i >= 0; i-- {
+		res[i] = heap.Pop(h).(sortedRecord).rec
+	}
+	return res
+}
+
+// querySorted runs the query with the sort of the policy.
+// The query is run without MaxRecords, since the server would otherwise return
+// an arbitrary subset of the records; the records are merged client-side instead,
+// keeping at most MaxRecords of them in memory.
+func querySorted(policy *QueryPolicy, run func(policy *QueryPolicy) (*Recordset, Error)) (*Recordset, Error) {
+	if len(policy.sortKeys) == 0 {
+		return run(policy)
+	}
+
+	p := *policy
+	p.MaxRecords = 0
+	p.sortKeys = nil
+	src, err := run(&p)
+	if err != nil {
+		return nil, err
+	}
+	return newSortedRecordset(src, policy.sortKeys, policy.MaxRecords, policy.RecordQueueSize), nil
+}
+
+// newSortedRecordset consumes the records of src, and returns a recordset
+// that yields them sorted on the keys once src is exhausted.
+// Errors of src are passed through as they arrive.
+func newSortedRecordset(src *Recordset, keys []querySortKey, limit int64, queueSize int) *Recordset {
+	res := newRecordset(queueSize, 1)
+	res.taskID = src.TaskId()
+
+	go func() {
+		defer res.signalEnd()
+		defer src.Close()
+
+		h := &querySortHeap{keys: keys}
+		for seq := 0; ; seq++ {
+			var result *Result
+			var ok bool
+			select {
+			case result, ok = <-src.Results():
+			case <-res.cancelled:
+				return
+			}
+
+			if !ok {
+				break
+			}
+
+			if result.Err != nil {
+				if !res.send(result) {
+					return
+				}
+				continue
+			}
+			h.push(result.Record, seq, limit)
+		}
+
+		for _, rec := range h.sorted() {
+			if !res.send(&Result{Record: rec}) {
+				return
+			}
+		}
+	}()
+
+	return res
+}
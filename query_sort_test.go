@@ -0,0 +1,69 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Query sort", func() {
+
+	records := func(values ...interface{}) *Recordset {
+		rs := newRecordset(len(values), 1)
+		for i, v := range values {
+			bins := BinMap{"n": i}
+			if v != nil {
+				bins["v"] = v
+			}
+			rs.send(&Result{Record: &Record{Bins: bins}})
+		}
+		rs.signalEnd()
+		return rs
+	}
+
+	collect := func(rs *Recordset) []interface{} {
+		res := []interface{}{}
+		for r := range rs.Results() {
+			gm.Expect(r.Err).ToNot(gm.HaveOccurred())
+			res = append(res, r.Record.Bins["n"])
+		}
+		return res
+	}
+
+	gg.It("must sort the records with the ties in arrival order", func() {
+		keys := []querySortKey{{binName: "v", direction: SortAscending}}
+		rs := newSortedRecordset(records(3, nil, 1, "a", 3, 2), keys, 0, 1)
+		gm.Expect(collect(rs)).To(gm.Equal([]interface{}{2, 5, 0, 4, 3, 1}))
+	})
+
+	gg.It("must keep the top records only", func() {
+		keys := []querySortKey{{binName: "v", direction: SortDescending}}
+		rs := newSortedRecordset(records(3, nil, 1, 7, 3, 2), keys, 3, 1)
+		gm.Expect(collect(rs)).To(gm.Equal([]interface{}{3, 0, 4}))
+	})
+
+	gg.It("must close the source recordset when closed", func() {
+		src := newRecordset(0, 1)
+		go func() {
+			defer src.signalEnd()
+			<-src.cancelled
+		}()
+
+		rs := newSortedRecordset(src, []querySortKey{{binName: "v"}}, 0, 1)
+		gm.Expect(rs.Close()).ToNot(gm.HaveOccurred())
+		gm.Eventually(src.IsActive).Should(gm.BeFalse())
+	})
+})
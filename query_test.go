@@ -365,6 +365,44 @@ var _ = gg.Describe("Query operations", func() {
 		gm.Expect(len(keys)).To(gm.Equal(0))
 	})
 
+	gg.It("must Query a range and get all records back without the excluded bins", func() {
+		stm := as.NewStatement(ns, set)
+		stm.ExcludeBins = []string{bin2.Name, bin3.Name}
+		stm.Filter = as.NewRangeFilter(bin3.Name, 0, math.MaxInt16/2)
+		recordset, err := client.Query(queryPolicy, stm)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		counter := 0
+		for res := range recordset.Results() {
+			gm.Expect(res.Err).ToNot(gm.HaveOccurred())
+			rec := res.Record
+
+			gm.Expect(rec.Bins).To(gm.HaveKey(bin1.Name))
+			gm.Expect(rec.Bins).ToNot(gm.HaveKey(bin2.Name))
+			gm.Expect(rec.Bins).ToNot(gm.HaveKey(bin3.Name))
+			counter++
+		}
+
+		gm.Expect(counter).To(gm.BeNumerically(">", 0))
+	})
+
+	gg.It("must Query a range and only get the requested bins back", func() {
+		stm := as.NewStatement(ns, set, bin1.Name, bin2.Name)
+		stm.ExcludeBins = []string{bin2.Name}
+		recordset, err := client.Query(queryPolicy, stm)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		for res := range recordset.Results() {
+			gm.Expect(res.Err).ToNot(gm.HaveOccurred())
+			rec := res.Record
+
+			gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{bin1.Name: bin1.Value.GetObject()}))
+			delete(keys, string(rec.Key.Digest()))
+		}
+
+		gm.Expect(len(keys)).To(gm.Equal(0))
+	})
+
 	gg.It("must Cancel Query abruptly", func() {
 		stm := as.NewStatement(ns, set)
 		recordset, err := client.Query(queryPolicy, stm)
@@ -0,0 +1,110 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"sync"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	Buffer "github.com/aerospike/aerospike-client-go/v7/utils/buffer"
+)
+
+// readBinCommand reads the value of a single bin. The commands are pooled, and hold
+// their partition and bin name list, so that a read does not allocate anything
+// other than the value itself.
+type readBinCommand struct {
+	readCommand
+
+	partitionBuf Partition
+	binNameBuf   [1]string
+
+	value interface{}
+}
+
+var readBinCommandPool = sync.Pool{
+	New: func() interface{} {
+		return new(readBinCommand)
+	},
+}
+
+func newReadBinCommand(cluster *Cluster, policy *BasePolicy, key *Key, binName string) (*readBinCommand, Error) {
+	cmd := readBinCommandPool.Get().(*readBinCommand)
+	if err := cmd.partitionBuf.setForRead(cluster, policy, key); err != nil {
+		readBinCommandPool.Put(cmd)
+		return nil, err
+	}
+
+	cmd.binNameBuf[0] = binName
+	cmd.readCommand = readCommand{
+		singleCommand: newSingleCommand(cluster, key, &cmd.partitionBuf),
+		binNames:      cmd.binNameBuf[:],
+		policy:        policy,
+	}
+	return cmd, nil
+}
+
+// release clears the references held by the command and returns it to the pool.
+func (cmd *readBinCommand) release() {
+	*cmd = readBinCommand{}
+	readBinCommandPool.Put(cmd)
+}
+
+func (cmd *readBinCommand) parseResult(ifc command, conn *Connection) Error {
+	header, err := cmd.readResponse(conn)
+	if err != nil {
+		return err
+	}
+
+	switch header.resultCode {
+	case 0:
+	case types.KEY_NOT_FOUND_ERROR:
+		return ErrKeyNotFound.err()
+	case types.FILTERED_OUT:
+		return ErrFilteredOut.err()
+	default:
+		return newError(header.resultCode)
+	}
+
+	receiveOffset := 0
+	for i := 0; i < header.fieldCount; i++ {
+		fieldSize := int(Buffer.BytesToUint32(cmd.dataBuffer, receiveOffset))
+		receiveOffset += 4 + fieldSize
+	}
+
+	binName := cmd.binNameBuf[0]
+	for i := 0; i < header.opCount; i++ {
+		opSize := int(Buffer.BytesToUint32(cmd.dataBuffer, receiveOffset))
+		particleType := int(cmd.dataBuffer[receiveOffset+5])
+		nameSize := int(cmd.dataBuffer[receiveOffset+7])
+		nameOffset := receiveOffset + 8
+		receiveOffset += 4 + 4 + nameSize
+
+		particleBytesSize := opSize - (4 + nameSize)
+
+		// the comparison does not allocate the name
+		if string(cmd.dataBuffer[nameOffset:nameOffset+nameSize]) == binName {
+			cmd.value, err = bytesToParticle(particleType, cmd.dataBuffer, receiveOffset, particleBytesSize)
+			return err
+		}
+		receiveOffset += particleBytesSize
+	}
+
+	return nil
+}
+
+func (cmd *readBinCommand) Execute() Error {
+	return cmd.execute(cmd)
+}
@@ -82,34 +82,91 @@ func (cmd *readCommand) prepareRetry(ifc command, isTimeout bool) bool {
 	return true
 }
 
+// readResponseHeader holds the fields of the message header of a single record response.
+type readResponseHeader struct {
+	resultCode types.ResultCode
+	generation uint32
+	expiration uint32
+	fieldCount int
+	opCount    int
+}
+
 func (cmd *readCommand) parseResult(ifc command, conn *Connection) Error {
+	header, err := cmd.readResponse(conn)
+	if err != nil {
+		return err
+	}
+
+	resultCode := header.resultCode
+	generation := header.generation
+	expiration := header.expiration
+	fieldCount := header.fieldCount
+	opCount := header.opCount
+
+	if resultCode != 0 {
+		if resultCode == types.KEY_NOT_FOUND_ERROR {
+			return ErrKeyNotFound.err()
+		} else if resultCode == types.FILTERED_OUT {
+			return ErrFilteredOut.err()
+		} else if resultCode == types.UDF_BAD_RESPONSE {
+			cmd.record, _ = cmd.parseRecord(ifc, opCount, fieldCount, generation, expiration)
+			err := cmd.handleUdfError(ifc, resultCode)
+			logger.Logger.Debug("UDF execution error: " + err.Error())
+			return err
+		}
+
+		return newError(resultCode)
+	}
+
+	if cmd.object == nil {
+		if opCount == 0 {
+			// data Bin was not returned
+			cmd.record = newRecord(cmd.node, cmd.key, nil, generation, expiration)
+			return nil
+		}
+
+		cmd.record, err = cmd.parseRecord(ifc, opCount, fieldCount, generation, expiration)
+		if err != nil {
+			return err
+		}
+	} else if objectParser != nil {
+		if err := objectParser(cmd, opCount, fieldCount, generation, expiration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readResponse reads the whole response into the data buffer, and returns its header.
+func (cmd *readCommand) readResponse(conn *Connection) (readResponseHeader, Error) {
 	// Read proto and check if compressed
 	if _, err := conn.Read(cmd.dataBuffer, 8); err != nil {
 		logger.Logger.Debug("Connection error reading data for ReadCommand: %s", err.Error())
-		return err
+		return readResponseHeader{}, err
 	}
 
 	if compressedSize := cmd.compressedSize(); compressedSize > 0 {
 		// Read compressed size
 		if _, err := conn.Read(cmd.dataBuffer, 8); err != nil {
 			logger.Logger.Debug("Connection error reading data for ReadCommand: %s", err.Error())
-			return err
+			return readResponseHeader{}, err
 		}
 
 		if err := cmd.conn.initInflater(true, compressedSize); err != nil {
-			return newError(types.PARSE_ERROR, fmt.Sprintf("Error setting up zlib inflater for size `%d`: %s", compressedSize, err.Error()))
+			return readResponseHeader{}, newError(types.PARSE_ERROR, fmt.Sprintf("Error setting up zlib inflater for size `%d`: %s", compressedSize, err.Error()))
 		}
 
 		// Read header.
 		if _, err := conn.Read(cmd.dataBuffer, int(_MSG_TOTAL_HEADER_SIZE)); err != nil {
 			logger.Logger.Debug("Connection error reading data for ReadCommand: %s", err.Error())
-			return err
+			return readResponseHeader{}, err
 		}
 	} else {
 		// Read header.
 		if _, err := conn.Read(cmd.dataBuffer[8:], int(_MSG_TOTAL_HEADER_SIZE)-8); err != nil {
 			logger.Logger.Debug("Connection error reading data for ReadCommand: %s", err.Error())
-			return err
+			return readResponseHeader{}, err
 		}
 	}
 
@@ -119,7 +176,7 @@ func (cmd *readCommand) parseResult(ifc command, conn *Connection) Error {
 
 	// Validate header to make sure we are at the beginning of a message
 	if err := cmd.validateHeader(sz); err != nil {
-		return err
+		return readResponseHeader{}, err
 	}
 
 	headerLength := int(cmd.dataBuffer[8])
@@ -133,54 +190,35 @@ func (cmd *readCommand) parseResult(ifc command, conn *Connection) Error {
 	// Read remaining message bytes.
 	if receiveSize > 0 {
 		if err := cmd.sizeBufferSz(receiveSize, false); err != nil {
-			return err
+			return readResponseHeader{}, err
 		}
 		if _, err := conn.Read(cmd.dataBuffer, receiveSize); err != nil {
 			logger.Logger.Debug("Connection error reading data for ReadCommand: %s", err.Error())
-			return err
+			return readResponseHeader{}, err
 		}
-
 	}
 
-	if resultCode != 0 {
-		if resultCode == types.KEY_NOT_FOUND_ERROR {
-			return ErrKeyNotFound.err()
-		} else if resultCode == types.FILTERED_OUT {
-			return ErrFilteredOut.err()
-		} else if resultCode == types.UDF_BAD_RESPONSE {
-			cmd.record, _ = cmd.parseRecord(ifc, opCount, fieldCount, generation, expiration)
-			err := cmd.handleUdfError(resultCode)
-			logger.Logger.Debug("UDF execution error: " + err.Error())
-			return err
-		}
+	return readResponseHeader{
+		resultCode: resultCode,
+		generation: generation,
+		expiration: expiration,
+		fieldCount: fieldCount,
+		opCount:    opCount,
+	}, nil
+}
 
-		return newError(resultCode)
+func (cmd *readCommand) handleUdfError(ifc command, resultCode types.ResultCode) Error {
+	var msg string
+	if cmd.record != nil {
+		msg, _ = cmd.record.Bins["FAILURE"].(string)
 	}
 
-	if cmd.object == nil {
-		if opCount == 0 {
-			// data Bin was not returned
-			cmd.record = newRecord(cmd.node, cmd.key, nil, generation, expiration)
-			return nil
-		}
-
-		var err Error
-		cmd.record, err = cmd.parseRecord(ifc, opCount, fieldCount, generation, expiration)
-		if err != nil {
-			return err
-		}
-	} else if objectParser != nil {
-		if err := objectParser(cmd, opCount, fieldCount, generation, expiration); err != nil {
-			return err
-		}
+	if exec, ok := ifc.(*executeCommand); ok {
+		return newUDFError(resultCode, exec.packageName, exec.functionName, msg)
 	}
 
-	return nil
-}
-
-func (cmd *readCommand) handleUdfError(resultCode types.ResultCode) Error {
-	if ret, exists := cmd.record.Bins["FAILURE"]; exists {
-		return newError(resultCode, ret.(string))
+	if msg != "" {
+		return newError(resultCode, msg)
 	}
 	return newError(resultCode)
 }
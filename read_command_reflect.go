@@ -20,8 +20,7 @@ import (
 	"fmt"
 	"math"
 	"reflect"
-	"strings"
-	"time"
+	"strconv"
 
 	"github.com/aerospike/aerospike-client-go/v7/types"
 	Buffer "github.com/aerospike/aerospike-client-go/v7/utils/buffer"
@@ -196,9 +195,33 @@ func setValue(f reflect.Value, value interface{}) Error {
 			return nil
 		}
 
+		// normalize the maps decoded per the ValuePolicy, unless the field takes them as is
+		if f.Kind() != reflect.Interface {
+			switch v := value.(type) {
+			case map[string]interface{}:
+				m := make(map[interface{}]interface{}, len(v))
+				for k, e := range v {
+					m[k] = e
+				}
+				value = m
+			case OrderedMap:
+				value = []MapPair(v)
+			}
+		}
+
 		switch fieldKind := f.Kind(); fieldKind {
 		case reflect.Int, reflect.Int64, reflect.Int8, reflect.Int16, reflect.Int32,
 			reflect.Uint, reflect.Uint64, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+			// unsigned values beyond math.MaxInt64 may be stored as decimal strings
+			if str, ok := value.(string); ok && (fieldKind == reflect.Uint || fieldKind == reflect.Uint64) {
+				u, err := strconv.ParseUint(str, 10, 64)
+				if err != nil {
+					return newError(types.PARSE_ERROR, fmt.Sprintf("Invalid value `%#v` for %s field", value, fieldKind))
+				}
+				f.SetUint(u)
+				break
+			}
+
 			v := reflect.ValueOf(value)
 			t := f.Type()
 			if !v.CanConvert(t) {
@@ -331,14 +354,21 @@ func setValue(f reflect.Value, value interface{}) Error {
 			case reflect.Struct:
 				// support time.Time
 				if f.Type().Elem().PkgPath() == "time" && f.Type().Elem().Name() == "Time" {
-					v, ok := value.(int)
+					tm, ok := timeFromInterface(value)
 					if !ok {
 						return newError(types.PARSE_ERROR, fmt.Sprintf("Invalid value `%#v` for *%s field", value, fieldKind))
 					}
-					tm := time.Unix(0, int64(v))
 					f.Set(reflect.ValueOf(&tm))
 					break
 				}
+				if f.Type().Elem() == bigIntType {
+					v, ok := bigIntFromInterface(value)
+					if !ok {
+						return newError(types.PARSE_ERROR, fmt.Sprintf("Invalid value `%#v` for *big.Int field", value))
+					}
+					f.Set(reflect.ValueOf(v))
+					break
+				}
 				valMap, ok := value.(map[interface{}]interface{})
 				if !ok {
 					return newError(types.PARSE_ERROR, fmt.Sprintf("Invalid value `%#v` for %s field", value, fieldKind))
@@ -351,7 +381,7 @@ func setValue(f reflect.Value, value interface{}) Error {
 					}
 
 					theStruct := newObjPtr.Elem()
-					if err := setStructValue(theStruct, valMap, theStruct.Type(), nil); err != nil {
+					if err := setStructValue(theStruct, valMap, theStruct.Type(), nil, ""); err != nil {
 						return err
 					}
 
@@ -416,11 +446,21 @@ func setValue(f reflect.Value, value interface{}) Error {
 
 			// support time.Time
 			if f.Type().PkgPath() == "time" && f.Type().Name() == "Time" {
-				v, ok := value.(int)
+				tm, ok := timeFromInterface(value)
 				if !ok {
 					return newError(types.PARSE_ERROR, fmt.Sprintf("Invalid value `%#v` for time %s field", value, fieldKind))
 				}
-				f.Set(reflect.ValueOf(time.Unix(0, int64(v))))
+				f.Set(reflect.ValueOf(tm))
+				break
+			}
+
+			// support big.Int
+			if f.Type() == bigIntType {
+				v, ok := bigIntFromInterface(value)
+				if !ok {
+					return newError(types.PARSE_ERROR, fmt.Sprintf("Invalid value `%#v` for big.Int field", value))
+				}
+				f.Set(reflect.ValueOf(v).Elem())
 				break
 			}
 
@@ -429,7 +469,7 @@ func setValue(f reflect.Value, value interface{}) Error {
 				return newError(types.PARSE_ERROR, fmt.Sprintf("Invalid value `%#v` for %s field", value, fieldKind))
 			}
 			// iterate over struct fields and recursively fill them up
-			if err := setStructValue(f, valMap, f.Type(), nil); err != nil {
+			if err := setStructValue(f, valMap, f.Type(), nil, ""); err != nil {
 				return err
 			}
 
@@ -441,13 +481,20 @@ func setValue(f reflect.Value, value interface{}) Error {
 	return nil
 }
 
-func setStructValue(f reflect.Value, valMap map[interface{}]interface{}, typeOfT reflect.Type, index []int) (err Error) {
+func setStructValue(f reflect.Value, valMap map[interface{}]interface{}, typeOfT reflect.Type, index []int, prefix string) (err Error) {
 	numFields := typeOfT.NumField()
 	for i := 0; i < numFields; i++ {
 		fld := typeOfT.Field(i)
 		fldIndex := append(index, fld.Index...)
+		if fldPrefix, ok := fieldPrefix(fld); ok {
+			if err := setStructValue(f, valMap, fld.Type, fldIndex, prefix+fldPrefix); err != nil {
+				return err
+			}
+			continue
+		}
+
 		if fld.Anonymous && fld.Type.Kind() == reflect.Struct {
-			if err := setStructValue(f, valMap, fld.Type, fldIndex); err != nil {
+			if err := setStructValue(f, valMap, fld.Type, fldIndex, prefix); err != nil {
 				return err
 			}
 			continue
@@ -457,11 +504,11 @@ func setStructValue(f reflect.Value, valMap map[interface{}]interface{}, typeOfT
 			continue
 		}
 
-		alias := fld.Name
-		tag := strings.Trim(stripOptions(fld.Tag.Get(aerospikeTag)), " ")
-		if tag != "" {
-			alias = tag
+		alias := fieldAlias(fld)
+		if alias == "" {
+			continue
 		}
+		alias = prefix + alias
 
 		if valMap[alias] != nil {
 			if err := setValue(f.FieldByIndex(fldIndex), valMap[alias]); err != nil {
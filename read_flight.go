@@ -0,0 +1,85 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"strings"
+)
+
+// readFlightKey returns the key that identical reads share in the flight group.
+func readFlightKey(key *Key, binNames []string) string {
+	var sb strings.Builder
+	sb.Grow(len(key.namespace) + len(key.digest) + 1 + 16*len(binNames))
+	sb.WriteString(key.namespace)
+	sb.WriteByte(0)
+	sb.Write(key.digest[:])
+	for _, name := range binNames {
+		sb.WriteByte(0)
+		sb.WriteString(name)
+	}
+	return sb.String()
+}
+
+// collapsedGet reads the record, sharing the request with the identical reads in flight.
+// Each caller receives its own copy of the record and the error, since the callers may modify them.
+func (clnt *Client) collapsedGet(policy *BasePolicy, key *Key, binNames []string, useCache bool) (*Record, Error) {
+	leader := false
+	v, _, shared := clnt.readFlights.Do(readFlightKey(key, binNames), func() (interface{}, error) {
+		leader = true
+		rec, err := clnt.get(policy, key, binNames, useCache)
+		return &readFlightResult{rec: rec, err: err}, nil
+	})
+
+	if !leader {
+		clnt.cluster.collapsedReads.IncrementAndGet()
+	}
+
+	res := v.(*readFlightResult)
+	if !shared {
+		return res.rec, res.err
+	}
+	return res.copy(key)
+}
+
+// readFlightResult is the result of a read shared in the flight group.
+type readFlightResult struct {
+	rec *Record
+	err Error
+}
+
+func (res *readFlightResult) copy(key *Key) (*Record, Error) {
+	var err Error
+	if res.err != nil {
+		err = res.err
+		if ae, ok := res.err.(*AerospikeError); ok {
+			cp := *ae
+			err = &cp
+		}
+	}
+
+	if res.rec == nil {
+		return nil, err
+	}
+
+	rec := *res.rec
+	rec.Key = key
+	if res.rec.Bins != nil {
+		rec.Bins = make(BinMap, len(res.rec.Bins))
+		for name, v := range res.rec.Bins {
+			rec.Bins[name] = v
+		}
+	}
+	return &rec, err
+}
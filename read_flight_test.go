@@ -0,0 +1,58 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Collapsed reads", func() {
+
+	gg.It("must only share the flight of identical reads", func() {
+		key1, _ := NewKey("test", "set", 1)
+		key2, _ := NewKey("test", "set", 2)
+		key3, _ := NewKey("test", "set", 1)
+
+		gm.Expect(readFlightKey(key1, nil)).To(gm.Equal(readFlightKey(key3, nil)))
+		gm.Expect(readFlightKey(key1, []string{"a"})).To(gm.Equal(readFlightKey(key3, []string{"a"})))
+		gm.Expect(readFlightKey(key1, nil)).ToNot(gm.Equal(readFlightKey(key2, nil)))
+		gm.Expect(readFlightKey(key1, []string{"a"})).ToNot(gm.Equal(readFlightKey(key1, []string{"b"})))
+		gm.Expect(readFlightKey(key1, []string{"ab"})).ToNot(gm.Equal(readFlightKey(key1, []string{"a", "b"})))
+	})
+
+	gg.It("must give every caller its own copy of the result", func() {
+		key, _ := NewKey("test", "set", 1)
+		other, _ := NewKey("test", "set", 1)
+		res := &readFlightResult{rec: newRecord(nil, key, BinMap{"a": 1}, 2, 3)}
+
+		rec, err := res.copy(other)
+		gm.Expect(err).To(gm.BeNil())
+		gm.Expect(rec).ToNot(gm.BeIdenticalTo(res.rec))
+		gm.Expect(rec.Key).To(gm.BeIdenticalTo(other))
+		gm.Expect(rec.Generation).To(gm.Equal(uint32(2)))
+		rec.Bins["a"] = 2
+		gm.Expect(res.rec.Bins["a"]).To(gm.Equal(1))
+
+		res = &readFlightResult{err: newError(types.TIMEOUT)}
+		rec, err = res.copy(other)
+		gm.Expect(rec).To(gm.BeNil())
+		gm.Expect(err).ToNot(gm.BeIdenticalTo(res.err))
+		gm.Expect(err.Matches(types.TIMEOUT)).To(gm.BeTrue())
+	})
+
+})
@@ -0,0 +1,165 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	iatomic "github.com/aerospike/aerospike-client-go/v7/internal/atomic"
+)
+
+// reconnector spreads the seedings of a cluster which lost all its nodes with a jittered
+// exponential backoff, and limits the connections opened at the same time.
+type reconnector struct {
+	policy ReconnectPolicy
+
+	// slots for the connections being opened; nil if not limited
+	connects chan struct{}
+
+	mutex     sync.Mutex
+	connected bool // the cluster had nodes
+	lost      bool // the cluster lost all its nodes, and was not seeded again
+	backoff   time.Duration
+	nextSeed  time.Time
+
+	waves          iatomic.Int // number of times the cluster was seeded again after losing all its nodes
+	seedsDelayed   iatomic.Int // number of tends which did not seed the cluster due to the backoff
+	connectsWaited iatomic.Int // number of connections which waited for a slot to be opened
+}
+
+func newReconnector(policy ReconnectPolicy) *reconnector {
+	def := NewReconnectPolicy()
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = def.InitialBackoff
+	}
+	if policy.MaxBackoff < policy.InitialBackoff {
+		policy.MaxBackoff = policy.InitialBackoff
+	}
+	if policy.Multiplier < 1 {
+		policy.Multiplier = def.Multiplier
+	}
+
+	r := &reconnector{policy: policy}
+	if policy.MaxConcurrentConnects > 0 {
+		r.connects = make(chan struct{}, policy.MaxConcurrentConnects)
+	}
+	return r
+}
+
+// jitter returns a random duration up to d.
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d)) + 1)
+}
+
+// allowSeed returns true if the cluster may be seeded at the time.
+// The first seeding after the cluster lost all its nodes is delayed by a random part of the initial backoff.
+func (r *reconnector) allowSeed(now time.Time) bool {
+	if r == nil {
+		return true
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.connected && !r.lost {
+		r.lost = true
+		r.backoff = r.policy.InitialBackoff
+		r.nextSeed = now.Add(jitter(r.backoff))
+	}
+
+	if now.Before(r.nextSeed) {
+		r.seedsDelayed.IncrementAndGet()
+		return false
+	}
+	return true
+}
+
+// seeded records the result of a seeding.
+func (r *reconnector) seeded(now time.Time, success bool) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if success {
+		if r.lost {
+			r.waves.IncrementAndGet()
+		}
+		r.connected = true
+		r.lost = false
+		r.backoff = 0
+		r.nextSeed = time.Time{}
+		return
+	}
+
+	if r.lost {
+		r.backoff = time.Duration(float64(r.backoff) * r.policy.Multiplier)
+		if r.backoff > r.policy.MaxBackoff {
+			r.backoff = r.policy.MaxBackoff
+		}
+		r.nextSeed = now.Add(jitter(r.backoff))
+	}
+}
+
+// connectedTo records that the cluster has nodes.
+func (r *reconnector) connectedTo() {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	r.connected = true
+	r.mutex.Unlock()
+}
+
+// acquireConnect waits for a slot to open a connection, up to the timeout.
+func (r *reconnector) acquireConnect(timeout time.Duration) Error {
+	if r == nil || r.connects == nil {
+		return nil
+	}
+
+	select {
+	case r.connects <- struct{}{}:
+		return nil
+	default:
+	}
+
+	r.connectsWaited.IncrementAndGet()
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	select {
+	case r.connects <- struct{}{}:
+		return nil
+	case <-t.C:
+		return ErrTooManyOpeningConnections.err()
+	}
+}
+
+// releaseConnect frees the slot of an opened connection.
+func (r *reconnector) releaseConnect() {
+	if r == nil || r.connects == nil {
+		return
+	}
+	<-r.connects
+}
@@ -0,0 +1,57 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+)
+
+// ReconnectPolicy protects a cluster from the reconnection storms of its clients after it restarts,
+// when all clients lose their nodes and reconnect at the same time. See ClientPolicy.ReconnectPolicy.
+type ReconnectPolicy struct {
+	// InitialBackoff is the maximum delay of the first seeding of the cluster after the client has lost
+	// all its nodes. The actual delay is random up to the backoff, so that the clients spread their
+	// reconnections. The backoff grows by Multiplier after each failed seeding.
+	//
+	// Default: 1 second
+	InitialBackoff time.Duration //= 1 second
+
+	// MaxBackoff is the maximum backoff between the seedings of the cluster.
+	//
+	// Default: 30 seconds
+	MaxBackoff time.Duration //= 30 seconds
+
+	// Multiplier is the factor the backoff grows by after each failed seeding.
+	//
+	// Default: 2
+	Multiplier float64 //= 2
+
+	// MaxConcurrentConnects limits the number of connections opened at the same time to all the nodes
+	// of the cluster. Unlike ClientPolicy.OpeningConnectionThreshold, the commands and tends which need a
+	// new connection wait up to ClientPolicy.Timeout for their turn instead of failing immediately.
+	// If zero, the number is not limited.
+	//
+	// Default: 0
+	MaxConcurrentConnects int //= 0
+}
+
+// NewReconnectPolicy returns a policy with the default values.
+func NewReconnectPolicy() *ReconnectPolicy {
+	return &ReconnectPolicy{
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+	}
+}
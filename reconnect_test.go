@@ -0,0 +1,90 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"errors"
+	"time"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Reconnect policy", func() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	gg.It("must spread the seedings after the cluster is lost", func() {
+		policy := NewReconnectPolicy()
+		policy.InitialBackoff = time.Second
+		policy.MaxBackoff = 3 * time.Second
+		r := newReconnector(*policy)
+
+		// the initial seeding is never delayed
+		gm.Expect(r.allowSeed(now)).To(gm.BeTrue())
+		r.seeded(now, true)
+		gm.Expect(r.waves.Get()).To(gm.BeZero())
+
+		// the first seeding after the nodes were lost is delayed up to the initial backoff
+		gm.Expect(r.allowSeed(now)).To(gm.BeFalse())
+		gm.Expect(r.nextSeed).To(gm.BeTemporally(">", now))
+		gm.Expect(r.nextSeed).To(gm.BeTemporally("<=", now.Add(time.Second)))
+		gm.Expect(r.seedsDelayed.Get()).To(gm.Equal(1))
+
+		now = r.nextSeed
+		gm.Expect(r.allowSeed(now)).To(gm.BeTrue())
+
+		// the backoff grows after each failed seeding, up to the maximum
+		r.seeded(now, false)
+		gm.Expect(r.backoff).To(gm.Equal(2 * time.Second))
+		gm.Expect(r.nextSeed).To(gm.BeTemporally("<=", now.Add(2*time.Second)))
+		r.seeded(now, false)
+		gm.Expect(r.backoff).To(gm.Equal(3 * time.Second))
+		r.seeded(now, false)
+		gm.Expect(r.backoff).To(gm.Equal(3 * time.Second))
+
+		now = r.nextSeed
+		gm.Expect(r.allowSeed(now)).To(gm.BeTrue())
+		r.seeded(now, true)
+		gm.Expect(r.waves.Get()).To(gm.Equal(1))
+		gm.Expect(r.backoff).To(gm.BeZero())
+
+		var nilReconnector *reconnector
+		gm.Expect(nilReconnector.allowSeed(now)).To(gm.BeTrue())
+		nilReconnector.seeded(now, false)
+		gm.Expect(nilReconnector.acquireConnect(0)).ToNot(gm.HaveOccurred())
+		nilReconnector.releaseConnect()
+	})
+
+	gg.It("must limit the connections opened at the same time", func() {
+		policy := NewReconnectPolicy()
+		policy.MaxConcurrentConnects = 2
+		r := newReconnector(*policy)
+
+		gm.Expect(r.acquireConnect(time.Second)).ToNot(gm.HaveOccurred())
+		gm.Expect(r.acquireConnect(time.Second)).ToNot(gm.HaveOccurred())
+
+		err := r.acquireConnect(10 * time.Millisecond)
+		gm.Expect(errors.Is(err, ErrTooManyOpeningConnections)).To(gm.BeTrue())
+		gm.Expect(r.connectsWaited.Get()).To(gm.Equal(1))
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			r.releaseConnect()
+		}()
+		gm.Expect(r.acquireConnect(time.Second)).ToNot(gm.HaveOccurred())
+		gm.Expect(r.connectsWaited.Get()).To(gm.Equal(2))
+	})
+})
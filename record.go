@@ -14,7 +14,11 @@
 
 package aerospike
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"time"
+)
 
 // Record is the container struct for database records.
 // Records are equivalent to rows.
@@ -35,6 +39,15 @@ type Record struct {
 	// Expiration is TTL (Time-To-Live).
 	// Number of seconds until record expires.
 	Expiration uint32
+
+	// LastUpdateTime is the last update time of the record.
+	// The server only returns it when requested with LastUpdateTimeOp;
+	// it is the zero time otherwise.
+	LastUpdateTime time.Time
+
+	// expiresAt is the local time the record expires, computed when the record was received.
+	// It is zero for records that never expire.
+	expiresAt time.Time
 }
 
 func newRecord(node *Node, key *Key, bins BinMap, generation, expiration uint32) *Record {
@@ -46,6 +59,18 @@ func newRecord(node *Node, key *Key, bins BinMap, generation, expiration uint32)
 		Expiration: expiration,
 	}
 
+	if expiration != math.MaxUint32 && expiration != 0 {
+		r.expiresAt = time.Now().Add(time.Duration(expiration) * time.Second)
+	}
+
+	// the last update time is returned as the result of an expression read
+	if lut, exists := bins[lastUpdateTimeBin]; exists {
+		if nanos, ok := lut.(int); ok {
+			r.LastUpdateTime = time.Unix(0, int64(nanos))
+		}
+		delete(bins, lastUpdateTimeBin)
+	}
+
 	// always assign a map of length zero if Bins is nil
 	if r.Bins == nil {
 		r.Bins = make(BinMap)
@@ -54,6 +79,30 @@ func newRecord(node *Node, key *Key, bins BinMap, generation, expiration uint32)
 	return r
 }
 
+// TTL returns the Time-To-Live of the record as of when it was received.
+// Returns zero if the record never expires.
+func (rc *Record) TTL() time.Duration {
+	if rc.Expiration == math.MaxUint32 {
+		return 0
+	}
+	return time.Duration(rc.Expiration) * time.Second
+}
+
+// ExpiresAt returns the time the record expires, in the local clock.
+// The time is computed from the TTL and the moment the record was received, so it
+// does not depend on the clock of the server being in sync with the client.
+// Returns the zero time if the record never expires.
+func (rc *Record) ExpiresAt() time.Time {
+	if rc.Expiration == math.MaxUint32 || rc.Expiration == 0 {
+		return time.Time{}
+	}
+	if rc.expiresAt.IsZero() {
+		// the record was not created by the client
+		return time.Now().Add(rc.TTL())
+	}
+	return rc.expiresAt
+}
+
 // String implements the Stringer interface.
 // Returns string representation of record.
 func (rc *Record) String() string {
@@ -0,0 +1,279 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// RecordCache is a user-pluggable client side cache of records, keyed by the record digest.
+// When set on ClientPolicy, Get, BatchGet and Exists commands are served from the cache
+// if possible, and the results of the reads of whole records are stored in it.
+// Records reported as not found are stored as negative entries with a nil record.
+//
+// The writes issued through the same client invalidate the entries of their keys,
+// and Truncate clears the cache. Changes made by other clients, background queries
+// and record expiration are not seen by the cache, and the implementations must
+// expire the entries to bound their staleness.
+//
+// Reads with a filter expression or with linearizable SC read mode are never served
+// from the cache. The records returned from the cache are shallow copies with the
+// header of the original read; their bin values must not be modified.
+//
+// Implementations must be safe for concurrent use.
+type RecordCache interface {
+	// Get returns the cached record for the digest. found is false if the cache has no entry
+	// for the digest. A nil record with found set to true is a negative entry, and means
+	// the record did not exist.
+	Get(digest []byte) (record *Record, found bool)
+
+	// Put stores the record for the digest. A nil record stores a negative entry.
+	Put(digest []byte, record *Record)
+
+	// Invalidate removes the entry of the digest, if any.
+	Invalidate(digest []byte)
+
+	// Clear removes all the entries.
+	Clear()
+}
+
+type recordCacheEntry struct {
+	digest  [20]byte
+	record  *Record
+	expires time.Time
+}
+
+// MemoryRecordCache is an in-memory RecordCache with a maximum number of entries and
+// a time-to-live for the entries. The least recently used entries are evicted first.
+type MemoryRecordCache struct {
+	m sync.Mutex
+
+	maxEntries  int
+	ttl         time.Duration
+	negativeTTL time.Duration
+
+	lru     *list.List
+	entries map[[20]byte]*list.Element
+}
+
+// NewMemoryRecordCache creates an in-memory RecordCache holding at most maxEntries records.
+// A maxEntries of 0 or less does not limit the number of entries.
+// Records are cached for ttl, or until they would expire on the server if that is sooner.
+// Negative entries are cached for negativeTTL; a negativeTTL of 0 or less disables negative caching.
+func NewMemoryRecordCache(maxEntries int, ttl, negativeTTL time.Duration) *MemoryRecordCache {
+	return &MemoryRecordCache{
+		maxEntries:  maxEntries,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		lru:         list.New(),
+		entries:     map[[20]byte]*list.Element{},
+	}
+}
+
+// Get implements RecordCache.
+func (c *MemoryRecordCache) Get(digest []byte) (*Record, bool) {
+	d := cacheDigest(digest)
+
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	elem := c.entries[d]
+	if elem == nil {
+		return nil, false
+	}
+
+	e := elem.Value.(*recordCacheEntry)
+	if !time.Now().Before(e.expires) {
+		c.remove(elem)
+		return nil, false
+	}
+
+	c.lru.MoveToFront(elem)
+	return e.record, true
+}
+
+// Put implements RecordCache.
+func (c *MemoryRecordCache) Put(digest []byte, record *Record) {
+	d := cacheDigest(digest)
+
+	ttl := c.ttl
+	if record == nil {
+		ttl = c.negativeTTL
+	} else if exp := time.Duration(record.Expiration) * time.Second; record.Expiration > 0 && exp < ttl {
+		ttl = exp
+	}
+
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	// the previous entry is stale even if the new one is not cached
+	if elem := c.entries[d]; elem != nil {
+		c.remove(elem)
+	}
+
+	if ttl <= 0 {
+		return
+	}
+
+	c.entries[d] = c.lru.PushFront(&recordCacheEntry{digest: d, record: record, expires: time.Now().Add(ttl)})
+	if c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+// Invalidate implements RecordCache.
+func (c *MemoryRecordCache) Invalidate(digest []byte) {
+	d := cacheDigest(digest)
+
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if elem := c.entries[d]; elem != nil {
+		c.remove(elem)
+	}
+}
+
+// Clear implements RecordCache.
+func (c *MemoryRecordCache) Clear() {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.lru.Init()
+	c.entries = map[[20]byte]*list.Element{}
+}
+
+// Len returns the number of entries in the cache, including the expired entries
+// which have not been evicted yet.
+func (c *MemoryRecordCache) Len() int {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	return c.lru.Len()
+}
+
+func (c *MemoryRecordCache) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*recordCacheEntry).digest)
+}
+
+func cacheDigest(digest []byte) (d [20]byte) {
+	copy(d[:], digest)
+	return d
+}
+
+// cacheable returns true if the reads with the policy can be served from the cache.
+func cacheable(policy *BasePolicy) bool {
+	return policy.FilterExpression == nil && policy.ReadModeSC != ReadModeSCLinearize
+}
+
+// cachedRecord returns a copy of the cached record for the key, with only the named bins
+// if binNames is not empty.
+func cachedRecord(key *Key, rec *Record, binNames []string) *Record {
+	res := *rec
+	res.Key = key
+	if len(binNames) == 0 {
+		res.Bins = make(BinMap, len(rec.Bins))
+		for name, v := range rec.Bins {
+			res.Bins[name] = v
+		}
+		return &res
+	}
+
+	res.Bins = make(BinMap, len(binNames))
+	for _, name := range binNames {
+		if v, exists := rec.Bins[name]; exists {
+			res.Bins[name] = v
+		}
+	}
+	return &res
+}
+
+// cacheReadResult stores the result of the read of a whole record in the cache.
+func (clnt *Client) cacheReadResult(key *Key, rec *Record, err Error) {
+	switch {
+	case err == nil && rec != nil:
+		clnt.cache.Put(key.digest[:], cachedRecord(key, rec, nil))
+	case err != nil && err.Matches(ErrKeyNotFound.ResultCode):
+		clnt.cache.Put(key.digest[:], nil)
+	case err != nil:
+		// the record may have changed
+		clnt.cache.Invalidate(key.digest[:])
+	}
+}
+
+// cachedBatchGet serves the records found in the cache, and reads the rest from the database.
+func (clnt *Client) cachedBatchGet(policy *BatchPolicy, keys []*Key, binNames []string) ([]*Record, Error) {
+	records := make([]*Record, len(keys))
+
+	var misses []int
+	for i, key := range keys {
+		rec, found := clnt.cache.Get(key.digest[:])
+		if !found {
+			misses = append(misses, i)
+		} else if rec != nil {
+			records[i] = cachedRecord(key, rec, binNames)
+		}
+	}
+
+	if len(misses) == 0 {
+		return records, nil
+	}
+
+	missedKeys := make([]*Key, len(misses))
+	for j, i := range misses {
+		missedKeys[j] = keys[i]
+	}
+
+	res, err := clnt.batchGet(policy, missedKeys, binNames)
+	if res == nil {
+		return nil, err
+	}
+
+	for j, i := range misses {
+		records[i] = res[j]
+
+		// only whole records are cached; absent records are only known to not exist
+		// if the whole batch succeeded
+		if len(binNames) == 0 {
+			if res[j] != nil {
+				clnt.cache.Put(keys[i].digest[:], cachedRecord(keys[i], res[j], nil))
+			} else if err == nil {
+				clnt.cache.Put(keys[i].digest[:], nil)
+			}
+		}
+	}
+
+	return records, err
+}
+
+// invalidateCached removes the key from the record cache after a write.
+func (clnt *Client) invalidateCached(key *Key) {
+	if clnt.cache != nil {
+		clnt.cache.Invalidate(key.digest[:])
+	}
+}
+
+// invalidateCachedBatch removes the keys of the write records from the record cache.
+func (clnt *Client) invalidateCachedBatch(records []BatchRecordIfc) {
+	if clnt.cache != nil {
+		for _, r := range records {
+			if r.isWrite() {
+				clnt.cache.Invalidate(r.key().digest[:])
+			}
+		}
+	}
+}
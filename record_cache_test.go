@@ -0,0 +1,209 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	"time"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+	ast "github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("MemoryRecordCache", func() {
+
+	digest := func(i byte) []byte {
+		d := make([]byte, 20)
+		d[0] = i
+		return d
+	}
+
+	gg.It("must store positive and negative entries", func() {
+		c := as.NewMemoryRecordCache(10, time.Minute, time.Minute)
+
+		_, found := c.Get(digest(1))
+		gm.Expect(found).To(gm.BeFalse())
+
+		c.Put(digest(1), &as.Record{Bins: as.BinMap{"a": 1}})
+		c.Put(digest(2), nil)
+
+		rec, found := c.Get(digest(1))
+		gm.Expect(found).To(gm.BeTrue())
+		gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"a": 1}))
+
+		rec, found = c.Get(digest(2))
+		gm.Expect(found).To(gm.BeTrue())
+		gm.Expect(rec).To(gm.BeNil())
+
+		c.Invalidate(digest(1))
+		_, found = c.Get(digest(1))
+		gm.Expect(found).To(gm.BeFalse())
+
+		c.Clear()
+		gm.Expect(c.Len()).To(gm.Equal(0))
+	})
+
+	gg.It("must not store negative entries if negative caching is disabled", func() {
+		c := as.NewMemoryRecordCache(10, time.Minute, 0)
+
+		c.Put(digest(1), &as.Record{})
+		c.Put(digest(1), nil)
+
+		_, found := c.Get(digest(1))
+		gm.Expect(found).To(gm.BeFalse())
+	})
+
+	gg.It("must expire the entries", func() {
+		c := as.NewMemoryRecordCache(10, 50*time.Millisecond, time.Minute)
+
+		c.Put(digest(1), &as.Record{})
+		time.Sleep(100 * time.Millisecond)
+
+		_, found := c.Get(digest(1))
+		gm.Expect(found).To(gm.BeFalse())
+		gm.Expect(c.Len()).To(gm.Equal(0))
+	})
+
+	gg.It("must evict the least recently used entries", func() {
+		c := as.NewMemoryRecordCache(2, time.Minute, time.Minute)
+
+		c.Put(digest(1), &as.Record{})
+		c.Put(digest(2), &as.Record{})
+		c.Get(digest(1))
+		c.Put(digest(3), &as.Record{})
+
+		gm.Expect(c.Len()).To(gm.Equal(2))
+		_, found := c.Get(digest(1))
+		gm.Expect(found).To(gm.BeTrue())
+		_, found = c.Get(digest(2))
+		gm.Expect(found).To(gm.BeFalse())
+	})
+})
+
+// ALL tests are isolated by SetName and Key, which are 50 random characters
+var _ = gg.Describe("Record Cache", func() {
+
+	var ns = *namespace
+	var set = randString(50)
+
+	var cache *as.MemoryRecordCache
+	var cclient *as.Client
+
+	gg.BeforeEach(func() {
+		if *proxy {
+			gg.Skip("Not supported in Proxy Client")
+		}
+
+		cache = as.NewMemoryRecordCache(100, time.Minute, time.Minute)
+		cp := *clientPolicy
+		cp.RecordCache = cache
+
+		dbHost := as.NewHost(*host, *port)
+		dbHost.TLSName = *nodeTLSName
+
+		var err error
+		cclient, err = as.NewClientWithPolicyAndHost(&cp, dbHost)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+	})
+
+	gg.AfterEach(func() {
+		if cclient != nil {
+			cclient.Close()
+		}
+	})
+
+	gg.It("must serve the reads from the cache until the record is written", func() {
+		key, err := as.NewKey(ns, set, randString(50))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		err = cclient.PutBins(nil, key, as.NewBin("a", 1), as.NewBin("b", 2))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		rec, err := cclient.Get(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"a": 1, "b": 2}))
+		gm.Expect(cache.Len()).To(gm.Equal(1))
+
+		// written by another client; the cache is stale
+		err = nativeClient.PutBins(nil, key, as.NewBin("a", 10))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		rec, err = cclient.Get(nil, key, "a")
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"a": 1}))
+
+		// written by the same client
+		err = cclient.PutBins(nil, key, as.NewBin("a", 20))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(cache.Len()).To(gm.Equal(0))
+
+		rec, err = cclient.Get(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"a": 20, "b": 2}))
+	})
+
+	gg.It("must cache the records not found", func() {
+		key, err := as.NewKey(ns, set, randString(50))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		_, err = cclient.Get(nil, key)
+		gm.Expect(err).To(gm.HaveOccurred())
+		gm.Expect(err.Matches(ast.KEY_NOT_FOUND_ERROR)).To(gm.BeTrue())
+
+		err = nativeClient.PutBins(nil, key, as.NewBin("a", 1))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		exists, err := cclient.Exists(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(exists).To(gm.BeFalse())
+
+		_, err = cclient.Delete(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(cache.Len()).To(gm.Equal(0))
+	})
+
+	gg.It("must combine the cached records with the batch reads", func() {
+		var keys []*as.Key
+		for i := 0; i < 4; i++ {
+			key, err := as.NewKey(ns, set, randString(50))
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			keys = append(keys, key)
+		}
+
+		for _, key := range keys[:3] {
+			err := cclient.PutBins(nil, key, as.NewBin("a", 1))
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+		}
+
+		_, err := cclient.Get(nil, keys[0])
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		recs, err := cclient.BatchGet(nil, keys)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(recs).To(gm.HaveLen(4))
+		for i, rec := range recs[:3] {
+			gm.Expect(rec.Key.Digest()).To(gm.Equal(keys[i].Digest()))
+			gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"a": 1}))
+		}
+		gm.Expect(recs[3]).To(gm.BeNil())
+		gm.Expect(cache.Len()).To(gm.Equal(4))
+
+		err = cclient.BatchOperate(nil, []as.BatchRecordIfc{as.NewBatchDelete(nil, keys[1])})
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(cache.Len()).To(gm.Equal(3))
+	})
+})
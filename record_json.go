@@ -0,0 +1,647 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// The tags of the JSON objects encoding the values that have no native JSON representation.
+// A tagged value is an object with a single member, named after the tag.
+const (
+	jsonTagBlob    = "$blob"
+	jsonTagGeoJSON = "$geojson"
+	jsonTagHLL     = "$hll"
+	jsonTagFloat   = "$float"
+	jsonTagMap     = "$map"
+	jsonTagOMap    = "$omap"
+	jsonTagExt     = "$ext"
+)
+
+// MarshalValueJSON encodes a bin value to JSON, preserving its Aerospike type, so that
+// UnmarshalValueJSON decodes it back to the value the client would read from the server.
+//
+// Integers, strings, booleans, nil and lists are encoded natively. Floats are always encoded
+// with a decimal point or an exponent to set them apart from integers.
+// Maps with string keys are encoded as objects, unless one of the keys starts with '$'.
+// The other values are encoded as objects with a single tag member:
+//
+//	{"$blob": "<base64>"}              byte arrays
+//	{"$geojson": "<geojson>"}          GeoJSON values
+//	{"$hll": "<base64>"}               HyperLogLog values
+//	{"$float": "NaN"|"+Inf"|"-Inf"}    non-finite floats
+//	{"$map": [[key, value], ...]}      maps with non-string keys, sorted by key
+//	{"$omap": [[key, value], ...]}     ordered maps, in order
+//	{"$ext": {"type": n, "data": "<base64>"}}  MessagePack extensions
+func MarshalValueJSON(v interface{}) ([]byte, Error) {
+	tree, err := toJSONTree(v, false)
+	if err != nil {
+		return nil, err
+	}
+	return marshalJSONTree(tree)
+}
+
+// UnmarshalValueJSON decodes a bin value encoded by MarshalValueJSON.
+// The value is decoded to the types the client returns for the values read from the server:
+// int, float64, string, bool, []byte, GeoJSONValue, HLLValue, []interface{},
+// map[interface{}]interface{}, OrderedMap and ExtValue.
+func UnmarshalValueJSON(data []byte) (interface{}, Error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var tree interface{}
+	if err := dec.Decode(&tree); err != nil {
+		return nil, newErrorAndWrap(err, types.PARSE_ERROR, "invalid JSON value")
+	}
+	return fromJSONTree(tree, false)
+}
+
+// MarshalJSON encodes the key to JSON. The user key is encoded as by MarshalValueJSON,
+// and omitted if the key only consists of the digest. The digest is encoded in hex.
+func (ky *Key) MarshalJSON() ([]byte, error) {
+	aux := struct {
+		Namespace string          `json:"namespace"`
+		SetName   string          `json:"set"`
+		UserKey   json.RawMessage `json:"userKey,omitempty"`
+		Digest    string          `json:"digest"`
+	}{
+		Namespace: ky.namespace,
+		SetName:   ky.setName,
+		Digest:    hex.EncodeToString(ky.digest[:]),
+	}
+
+	if ky.userKey != nil {
+		if _, isNull := ky.userKey.(NullValue); !isNull {
+			uk, err := MarshalValueJSON(ky.userKey)
+			if err != nil {
+				return nil, err
+			}
+			aux.UserKey = uk
+		}
+	}
+
+	return json.Marshal(&aux)
+}
+
+// UnmarshalJSON decodes a key encoded by MarshalJSON.
+func (ky *Key) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Namespace string          `json:"namespace"`
+		SetName   string          `json:"set"`
+		UserKey   json.RawMessage `json:"userKey"`
+		Digest    string          `json:"digest"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return newErrorAndWrap(err, types.PARSE_ERROR, "invalid JSON key")
+	}
+
+	digest, err := hex.DecodeString(aux.Digest)
+	if err != nil {
+		return newErrorAndWrap(err, types.PARSE_ERROR, "invalid JSON key digest")
+	}
+
+	var userKey interface{}
+	if len(aux.UserKey) > 0 {
+		uk, err := UnmarshalValueJSON(aux.UserKey)
+		if err != nil {
+			return err
+		}
+		userKey = uk
+	}
+
+	key, kerr := NewKeyWithDigest(aux.Namespace, aux.SetName, userKey, digest)
+	if kerr != nil {
+		return kerr
+	}
+	*ky = *key
+	return nil
+}
+
+// MarshalJSON encodes the record to JSON, with its bins encoded as by MarshalValueJSON.
+// The node the record was read from is not encoded.
+func (rc *Record) MarshalJSON() ([]byte, error) {
+	aux := struct {
+		Key            *Key                       `json:"key,omitempty"`
+		Generation     uint32                     `json:"generation"`
+		Expiration     uint32                     `json:"expiration"`
+		LastUpdateTime string                     `json:"lastUpdateTime,omitempty"`
+		Bins           map[string]json.RawMessage `json:"bins"`
+	}{
+		Key:        rc.Key,
+		Generation: rc.Generation,
+		Expiration: rc.Expiration,
+		Bins:       make(map[string]json.RawMessage, len(rc.Bins)),
+	}
+
+	if !rc.LastUpdateTime.IsZero() {
+		aux.LastUpdateTime = rc.LastUpdateTime.UTC().Format(time.RFC3339Nano)
+	}
+
+	for name, value := range rc.Bins {
+		v, err := MarshalValueJSON(value)
+		if err != nil {
+			return nil, newErrorAndWrap(err, types.TYPE_NOT_SUPPORTED, fmt.Sprintf("bin `%s` cannot be encoded to JSON", name))
+		}
+		aux.Bins[name] = v
+	}
+
+	return json.Marshal(&aux)
+}
+
+// UnmarshalJSON decodes a record encoded by MarshalJSON.
+func (rc *Record) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Key            *Key                       `json:"key"`
+		Generation     uint32                     `json:"generation"`
+		Expiration     uint32                     `json:"expiration"`
+		LastUpdateTime string                     `json:"lastUpdateTime"`
+		Bins           map[string]json.RawMessage `json:"bins"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		if aerr, ok := err.(Error); ok {
+			return aerr
+		}
+		return newErrorAndWrap(err, types.PARSE_ERROR, "invalid JSON record")
+	}
+
+	bins := make(BinMap, len(aux.Bins))
+	for name, raw := range aux.Bins {
+		v, err := UnmarshalValueJSON(raw)
+		if err != nil {
+			return err
+		}
+		bins[name] = v
+	}
+
+	res := newRecord(nil, aux.Key, bins, aux.Generation, aux.Expiration)
+	if aux.LastUpdateTime != "" {
+		lut, err := time.Parse(time.RFC3339Nano, aux.LastUpdateTime)
+		if err != nil {
+			return newErrorAndWrap(err, types.PARSE_ERROR, "invalid JSON record last update time")
+		}
+		res.LastUpdateTime = lut
+	}
+
+	*rc = *res
+	return nil
+}
+
+// marshalJSONTree encodes a tree built by toJSONTree, without escaping the HTML characters.
+func marshalJSONTree(tree interface{}) ([]byte, Error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(tree); err != nil {
+		return nil, newErrorAndWrap(err, types.SERIALIZE_ERROR, "value cannot be encoded to JSON")
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+func jsonTagged(tag string, v interface{}) map[string]interface{} {
+	return map[string]interface{}{tag: v}
+}
+
+func jsonInt(v int64) json.Number {
+	return json.Number(strconv.FormatInt(v, 10))
+}
+
+func jsonFloat(f float64) interface{} {
+	switch {
+	case math.IsNaN(f):
+		return jsonTagged(jsonTagFloat, "NaN")
+	case math.IsInf(f, 1):
+		return jsonTagged(jsonTagFloat, "+Inf")
+	case math.IsInf(f, -1):
+		return jsonTagged(jsonTagFloat, "-Inf")
+	}
+
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return json.Number(s)
+}
+
+// toJSONTree converts a value to a tree of JSON values. The values the converter
+// does not know are normalized by packing and unpacking them first.
+func toJSONTree(v interface{}, normalized bool) (interface{}, Error) {
+	switch v := v.(type) {
+	case nil, NullValue:
+		return nil, nil
+	case bool:
+		return v, nil
+	case BoolValue:
+		return bool(v), nil
+	case int:
+		return jsonInt(int64(v)), nil
+	case int8:
+		return jsonInt(int64(v)), nil
+	case int16:
+		return jsonInt(int64(v)), nil
+	case int32:
+		return jsonInt(int64(v)), nil
+	case int64:
+		return jsonInt(v), nil
+	case uint8:
+		return jsonInt(int64(v)), nil
+	case uint16:
+		return jsonInt(int64(v)), nil
+	case uint32:
+		return jsonInt(int64(v)), nil
+	case uint:
+		return json.Number(strconv.FormatUint(uint64(v), 10)), nil
+	case uint64:
+		return json.Number(strconv.FormatUint(v, 10)), nil
+	case IntegerValue:
+		return jsonInt(int64(v)), nil
+	case LongValue:
+		return jsonInt(int64(v)), nil
+	case float32:
+		return jsonFloat(float64(v)), nil
+	case float64:
+		return jsonFloat(v), nil
+	case FloatValue:
+		return jsonFloat(float64(v)), nil
+	case string:
+		return v, nil
+	case StringValue:
+		return string(v), nil
+	case []byte:
+		return jsonTagged(jsonTagBlob, base64.StdEncoding.EncodeToString(v)), nil
+	case BytesValue:
+		return jsonTagged(jsonTagBlob, base64.StdEncoding.EncodeToString(v)), nil
+	case GeoJSONValue:
+		return jsonTagged(jsonTagGeoJSON, string(v)), nil
+	case HLLValue:
+		return jsonTagged(jsonTagHLL, base64.StdEncoding.EncodeToString(v)), nil
+	case ExtValue:
+		return jsonTagged(jsonTagExt, map[string]interface{}{
+			"type": jsonInt(int64(v.Type)),
+			"data": base64.StdEncoding.EncodeToString(v.Data),
+		}), nil
+	case []interface{}:
+		return listToJSONTree(v)
+	case ListValue:
+		return listToJSONTree(v)
+	case map[interface{}]interface{}:
+		return mapToJSONTree(v)
+	case MapValue:
+		return mapToJSONTree(v)
+	case map[string]interface{}:
+		return jsonMapToJSONTree(v)
+	case JsonValue:
+		return jsonMapToJSONTree(v)
+	case OrderedMap:
+		pairs := make([]interface{}, 0, len(v))
+		for i := range v {
+			pair, err := pairToJSONTree(v[i].Key, v[i].Value)
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, pair)
+		}
+		return jsonTagged(jsonTagOMap, pairs), nil
+	}
+
+	// byte arrays are the map keys for blobs
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Array && rv.Type().Elem().Kind() == reflect.Uint8 {
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		return jsonTagged(jsonTagBlob, base64.StdEncoding.EncodeToString(b)), nil
+	}
+
+	if normalized {
+		return nil, newError(types.TYPE_NOT_SUPPORTED, fmt.Sprintf("value of type `%T` cannot be encoded to JSON", v))
+	}
+
+	nv, err := normalizeValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return toJSONTree(nv, true)
+}
+
+// normalizeValue converts a value to the types the client reads from the server
+// by packing and unpacking it.
+func normalizeValue(v interface{}) (res interface{}, err Error) {
+	defer func() {
+		if r := recover(); r != nil {
+			res, err = nil, newError(types.TYPE_NOT_SUPPORTED, fmt.Sprintf("value of type `%T` cannot be encoded to JSON", v))
+		}
+	}()
+
+	pckr := newPacker()
+	if _, err := packObject(pckr, v, false); err != nil {
+		return nil, newErrorAndWrap(err, types.TYPE_NOT_SUPPORTED, fmt.Sprintf("value of type `%T` cannot be encoded to JSON", v))
+	}
+
+	buf := pckr.Bytes()
+	if len(buf) == 0 {
+		return nil, newError(types.TYPE_NOT_SUPPORTED, fmt.Sprintf("value of type `%T` cannot be encoded to JSON", v))
+	}
+	return newUnpacker(buf, 0, len(buf)).unpackObject(false)
+}
+
+func listToJSONTree(l []interface{}) (interface{}, Error) {
+	res := make([]interface{}, len(l))
+	for i := range l {
+		v, err := toJSONTree(l[i], false)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = v
+	}
+	return res, nil
+}
+
+func pairToJSONTree(k, v interface{}) ([]interface{}, Error) {
+	jk, err := toJSONTree(k, false)
+	if err != nil {
+		return nil, err
+	}
+
+	jv, err := toJSONTree(v, false)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{jk, jv}, nil
+}
+
+func jsonMapToJSONTree(m map[string]interface{}) (interface{}, Error) {
+	res := make(map[interface{}]interface{}, len(m))
+	for k, v := range m {
+		res[k] = v
+	}
+	return mapToJSONTree(res)
+}
+
+// mapToJSONTree encodes a map as a JSON object if its keys are strings that cannot be
+// mistaken for tags, and as a list of pairs sorted by their encoded key otherwise.
+func mapToJSONTree(m map[interface{}]interface{}) (interface{}, Error) {
+	isObject := true
+	for k := range m {
+		if s, ok := k.(string); !ok || strings.HasPrefix(s, "$") {
+			isObject = false
+			break
+		}
+	}
+
+	if isObject {
+		res := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			jv, err := toJSONTree(v, false)
+			if err != nil {
+				return nil, err
+			}
+			res[k.(string)] = jv
+		}
+		return res, nil
+	}
+
+	type sortablePair struct {
+		key  string
+		pair []interface{}
+	}
+
+	pairs := make([]sortablePair, 0, len(m))
+	for k, v := range m {
+		pair, err := pairToJSONTree(k, v)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := marshalJSONTree(pair[0])
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, sortablePair{key: string(key), pair: pair})
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].key < pairs[j].key })
+
+	res := make([]interface{}, len(pairs))
+	for i := range pairs {
+		res[i] = pairs[i].pair
+	}
+	return jsonTagged(jsonTagMap, res), nil
+}
+
+// fromJSONTree converts a tree of JSON values decoded with json.Decoder.UseNumber
+// to the value it encodes. Blobs are decoded to byte arrays when used as map keys.
+func fromJSONTree(tree interface{}, isMapKey bool) (interface{}, Error) {
+	switch v := tree.(type) {
+	case nil, bool, string:
+		return v, nil
+	case json.Number:
+		return numberFromJSON(v)
+	case []interface{}:
+		if isMapKey {
+			return nil, newError(types.PARSE_ERROR, "lists cannot be used as map keys")
+		}
+		res := make([]interface{}, len(v))
+		for i := range v {
+			e, err := fromJSONTree(v[i], false)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = e
+		}
+		return res, nil
+	case map[string]interface{}:
+		if len(v) == 1 {
+			for tag, tv := range v {
+				if strings.HasPrefix(tag, "$") {
+					return taggedFromJSON(tag, tv, isMapKey)
+				}
+			}
+		}
+
+		if isMapKey {
+			return nil, newError(types.PARSE_ERROR, "maps cannot be used as map keys")
+		}
+		res := make(map[interface{}]interface{}, len(v))
+		for k, e := range v {
+			ev, err := fromJSONTree(e, false)
+			if err != nil {
+				return nil, err
+			}
+			res[k] = ev
+		}
+		return res, nil
+	}
+
+	return nil, newError(types.PARSE_ERROR, fmt.Sprintf("unexpected JSON value of type `%T`", tree))
+}
+
+func numberFromJSON(n json.Number) (interface{}, Error) {
+	s := string(n)
+	if strings.ContainsAny(s, ".eE") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, newErrorAndWrap(err, types.PARSE_ERROR, fmt.Sprintf("invalid JSON float `%s`", s))
+		}
+		return f, nil
+	}
+
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if i >= math.MinInt && i <= math.MaxInt {
+			return int(i), nil
+		}
+		return i, nil
+	}
+
+	u, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return nil, newErrorAndWrap(err, types.PARSE_ERROR, fmt.Sprintf("invalid JSON integer `%s`", s))
+	}
+	return u, nil
+}
+
+func base64FromJSON(tag string, v interface{}) ([]byte, Error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, newError(types.PARSE_ERROR, fmt.Sprintf("invalid JSON `%s` value", tag))
+	}
+
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, newErrorAndWrap(err, types.PARSE_ERROR, fmt.Sprintf("invalid JSON `%s` value", tag))
+	}
+	return b, nil
+}
+
+func pairsFromJSON(tag string, v interface{}) ([]MapPair, Error) {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, newError(types.PARSE_ERROR, fmt.Sprintf("invalid JSON `%s` value", tag))
+	}
+
+	res := make([]MapPair, 0, len(list))
+	for i := range list {
+		pair, ok := list[i].([]interface{})
+		if !ok || len(pair) != 2 {
+			return nil, newError(types.PARSE_ERROR, fmt.Sprintf("invalid JSON `%s` entry", tag))
+		}
+
+		k, err := fromJSONTree(pair[0], true)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := fromJSONTree(pair[1], false)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, MapPair{Key: k, Value: e})
+	}
+	return res, nil
+}
+
+func taggedFromJSON(tag string, v interface{}, isMapKey bool) (interface{}, Error) {
+	switch tag {
+	case jsonTagBlob:
+		b, err := base64FromJSON(tag, v)
+		if err != nil {
+			return nil, err
+		}
+		if isMapKey {
+			arr := reflect.New(reflect.ArrayOf(len(b), reflect.TypeOf(byte(0)))).Elem()
+			reflect.Copy(arr, reflect.ValueOf(b))
+			return arr.Interface(), nil
+		}
+		return b, nil
+
+	case jsonTagGeoJSON:
+		s, ok := v.(string)
+		if !ok {
+			return nil, newError(types.PARSE_ERROR, fmt.Sprintf("invalid JSON `%s` value", tag))
+		}
+		return NewGeoJSONValue(s), nil
+
+	case jsonTagHLL:
+		b, err := base64FromJSON(tag, v)
+		if err != nil {
+			return nil, err
+		}
+		return NewHLLValue(b), nil
+
+	case jsonTagFloat:
+		switch v {
+		case "NaN":
+			return math.NaN(), nil
+		case "+Inf":
+			return math.Inf(1), nil
+		case "-Inf":
+			return math.Inf(-1), nil
+		}
+		return nil, newError(types.PARSE_ERROR, fmt.Sprintf("invalid JSON `%s` value", tag))
+
+	case jsonTagExt:
+		ext, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, newError(types.PARSE_ERROR, fmt.Sprintf("invalid JSON `%s` value", tag))
+		}
+		typ, ok := ext["type"].(json.Number)
+		if !ok {
+			return nil, newError(types.PARSE_ERROR, fmt.Sprintf("invalid JSON `%s` type", tag))
+		}
+		t, err := strconv.ParseUint(string(typ), 10, 8)
+		if err != nil {
+			return nil, newErrorAndWrap(err, types.PARSE_ERROR, fmt.Sprintf("invalid JSON `%s` type", tag))
+		}
+		data, derr := base64FromJSON(tag, ext["data"])
+		if derr != nil {
+			return nil, derr
+		}
+		return NewExtValue(byte(t), data), nil
+	}
+
+	if isMapKey {
+		return nil, newError(types.PARSE_ERROR, "maps cannot be used as map keys")
+	}
+
+	switch tag {
+	case jsonTagMap:
+		pairs, err := pairsFromJSON(tag, v)
+		if err != nil {
+			return nil, err
+		}
+		res := make(map[interface{}]interface{}, len(pairs))
+		for i := range pairs {
+			res[pairs[i].Key] = pairs[i].Value
+		}
+		return res, nil
+
+	case jsonTagOMap:
+		pairs, err := pairsFromJSON(tag, v)
+		if err != nil {
+			return nil, err
+		}
+		return OrderedMap(pairs), nil
+	}
+
+	return nil, newError(types.PARSE_ERROR, fmt.Sprintf("unknown JSON value tag `%s`", tag))
+}
@@ -0,0 +1,158 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Record JSON encoding", func() {
+
+	roundTrip := func(v interface{}) interface{} {
+		b, err := MarshalValueJSON(v)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		res, err := UnmarshalValueJSON(b)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		return res
+	}
+
+	gg.It("must encode the values natively when possible", func() {
+		for v, expected := range map[interface{}]string{
+			nil:                          `null`,
+			true:                         `true`,
+			7:                            `7`,
+			"a<b":                        `"a<b"`,
+			1.0:                          `1.0`,
+			1.5e100:                      `1.5e+100`,
+			StringValue("s"):             `"s"`,
+			LongValue(-3):                `-3`,
+			GeoJSONValue(`{"type":"P"}`): `{"$geojson":"{\"type\":\"P\"}"}`,
+		} {
+			b, err := MarshalValueJSON(v)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(string(b)).To(gm.Equal(expected))
+		}
+
+		b, err := MarshalValueJSON([]byte{1, 2, 3})
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(string(b)).To(gm.Equal(`{"$blob":"AQID"}`))
+
+		b, err = MarshalValueJSON(map[interface{}]interface{}{"b": 2, "a": []interface{}{1, "x"}})
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(string(b)).To(gm.Equal(`{"a":[1,"x"],"b":2}`))
+	})
+
+	gg.It("must round trip the Aerospike types", func() {
+		gm.Expect(roundTrip(nil)).To(gm.BeNil())
+		gm.Expect(roundTrip(NewNullValue())).To(gm.BeNil())
+		gm.Expect(roundTrip(1)).To(gm.Equal(1))
+		gm.Expect(roundTrip(int64(math.MaxInt64))).To(gm.Equal(math.MaxInt64))
+		gm.Expect(roundTrip(uint64(math.MaxUint64))).To(gm.Equal(uint64(math.MaxUint64)))
+		gm.Expect(roundTrip(2.0)).To(gm.Equal(2.0))
+		gm.Expect(roundTrip(FloatValue(-0.25))).To(gm.Equal(-0.25))
+		gm.Expect(roundTrip(math.Inf(1))).To(gm.Equal(math.Inf(1)))
+		gm.Expect(roundTrip(math.Inf(-1))).To(gm.Equal(math.Inf(-1)))
+		gm.Expect(math.IsNaN(roundTrip(math.NaN()).(float64))).To(gm.BeTrue())
+		gm.Expect(roundTrip(false)).To(gm.Equal(false))
+		gm.Expect(roundTrip("str")).To(gm.Equal("str"))
+		gm.Expect(roundTrip(BytesValue{0, 255})).To(gm.Equal([]byte{0, 255}))
+		gm.Expect(roundTrip(NewGeoJSONValue(`{"type":"Point","coordinates":[1,2]}`))).To(gm.Equal(GeoJSONValue(`{"type":"Point","coordinates":[1,2]}`)))
+		gm.Expect(roundTrip(NewHLLValue([]byte{9, 8, 7}))).To(gm.Equal(HLLValue([]byte{9, 8, 7})))
+		gm.Expect(roundTrip(NewExtValue(3, []byte{1}))).To(gm.Equal(NewExtValue(3, []byte{1})))
+		gm.Expect(roundTrip([]string{"a", "b"})).To(gm.Equal([]interface{}{"a", "b"}))
+		gm.Expect(roundTrip(JsonValue{"k": 1.5})).To(gm.Equal(map[interface{}]interface{}{"k": 1.5}))
+	})
+
+	gg.It("must round trip the maps with keys that are not strings", func() {
+		m := map[interface{}]interface{}{
+			1:              "int",
+			"$tag":         "escaped",
+			[3]byte{1, 2}:  []byte{3},
+			"str":          map[interface{}]interface{}{2.5: nil},
+			"nested $keys": NewGeoJSONValue(`{}`),
+		}
+		gm.Expect(roundTrip(m)).To(gm.Equal(m))
+
+		b1, err := MarshalValueJSON(m)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		b2, err := MarshalValueJSON(m)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(b1).To(gm.Equal(b2))
+
+		om := OrderedMap{{Key: "z", Value: 1}, {Key: "a", Value: []interface{}{2}}}
+		gm.Expect(roundTrip(om)).To(gm.Equal(om))
+	})
+
+	gg.It("must reject the invalid values", func() {
+		_, err := MarshalValueJSON(make(chan int))
+		gm.Expect(err.Matches(types.TYPE_NOT_SUPPORTED)).To(gm.BeTrue())
+
+		_, err = UnmarshalValueJSON([]byte(`{"$unknown":1}`))
+		gm.Expect(err.Matches(types.PARSE_ERROR)).To(gm.BeTrue())
+
+		_, err = UnmarshalValueJSON([]byte(`{"$blob":"***"}`))
+		gm.Expect(err.Matches(types.PARSE_ERROR)).To(gm.BeTrue())
+
+		_, err = UnmarshalValueJSON([]byte(`{"$map":[[[1],2]]}`))
+		gm.Expect(err.Matches(types.PARSE_ERROR)).To(gm.BeTrue())
+	})
+
+	gg.It("must round trip keys and records", func() {
+		key, err := NewKey("test", "dump", "user-1")
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		rec := newRecord(nil, key, BinMap{
+			"i":   1,
+			"f":   3.0,
+			"b":   []byte("bytes"),
+			"geo": NewGeoJSONValue(`{"type":"Point","coordinates":[0,0]}`),
+			"hll": NewHLLValue([]byte{0, 1, 2}),
+			"l":   []interface{}{1, "a", []byte{1}},
+			"m":   map[interface{}]interface{}{"x": 1, 2: "y"},
+		}, 4, 100)
+		rec.LastUpdateTime = time.Unix(0, 1700000000123456789).UTC()
+
+		b, jerr := json.Marshal(rec)
+		gm.Expect(jerr).ToNot(gm.HaveOccurred())
+
+		var res Record
+		gm.Expect(json.Unmarshal(b, &res)).To(gm.Succeed())
+		gm.Expect(res.Key.Equals(key)).To(gm.BeTrue())
+		gm.Expect(res.Key.Value()).To(gm.Equal(key.Value()))
+		gm.Expect(res.Key.SetName()).To(gm.Equal("dump"))
+		gm.Expect(res.Bins).To(gm.Equal(rec.Bins))
+		gm.Expect(res.Generation).To(gm.Equal(uint32(4)))
+		gm.Expect(res.Expiration).To(gm.Equal(uint32(100)))
+		gm.Expect(res.LastUpdateTime.Equal(rec.LastUpdateTime)).To(gm.BeTrue())
+
+		digestOnly, err := NewKeyFromDigest("test", "dump", key.Digest())
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		b, jerr = json.Marshal(digestOnly)
+		gm.Expect(jerr).ToNot(gm.HaveOccurred())
+		gm.Expect(string(b)).ToNot(gm.ContainSubstring("userKey"))
+
+		var k Key
+		gm.Expect(json.Unmarshal(b, &k)).To(gm.Succeed())
+		gm.Expect(k.Equals(key)).To(gm.BeTrue())
+	})
+})
@@ -0,0 +1,117 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// BinSize describes the serialized size of a bin. See RecordSizeReport.
+type BinSize struct {
+	// Name of the bin.
+	Name string
+
+	// Size is the serialized size of the bin in bytes, as it is sent to the server by a write,
+	// including the header of its operation.
+	Size int
+
+	// Cardinality is the number of elements of list and map values, and 0 for the other values.
+	Cardinality int
+}
+
+// RecordSizeReport describes the serialized sizes of the bins of a record, to find the bins
+// responsible for large records. The sizes are computed with the same estimation the client
+// uses to serialize the commands. See also WritePolicy.MaxRecordSize.
+type RecordSizeReport struct {
+	// Bins contains the size of each bin, the largest first.
+	Bins []BinSize
+
+	// BinsSize is the total serialized size of the bins in bytes.
+	BinsSize int
+
+	// WireSize is the size in bytes of the Put command for the record with the default write policy,
+	// including the message headers and the key.
+	WireSize int
+}
+
+// NewRecordSizeReport computes the serialized sizes of the bins of a record with the key.
+func NewRecordSizeReport(key *Key, bins BinMap) (*RecordSizeReport, Error) {
+	if key == nil {
+		return nil, newError(types.PARAMETER_ERROR, "The key of the record is required to compute its size")
+	}
+
+	cmd, err := newWriteCommand(nil, NewWritePolicy(0, 0), key, nil, bins, _WRITE)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &RecordSizeReport{Bins: make([]BinSize, 0, len(bins))}
+	for name, value := range bins {
+		cmd.dataOffset = 0
+		if err := cmd.estimateOperationSizeForBinNameAndValue(name, value); err != nil {
+			return nil, err
+		}
+
+		res.Bins = append(res.Bins, BinSize{Name: name, Size: cmd.dataOffset, Cardinality: cardinality(value)})
+		res.BinsSize += cmd.dataOffset
+	}
+
+	sort.Slice(res.Bins, func(i, j int) bool {
+		if res.Bins[i].Size != res.Bins[j].Size {
+			return res.Bins[i].Size > res.Bins[j].Size
+		}
+		return res.Bins[i].Name < res.Bins[j].Name
+	})
+
+	if err := cmd.setWrite(cmd.policy, _WRITE, key, nil, bins); err != nil {
+		return nil, err
+	}
+	res.WireSize = cmd.dataOffset
+
+	return res, nil
+}
+
+// SizeReport computes the serialized sizes of the bins of the record. See RecordSizeReport.
+func (rc *Record) SizeReport() (*RecordSizeReport, Error) {
+	return NewRecordSizeReport(rc.Key, rc.Bins)
+}
+
+// cardinality returns the number of elements of list and map values.
+func cardinality(value interface{}) int {
+	switch v := value.(type) {
+	case nil, []byte, BytesValue:
+		return 0
+	case ListValue:
+		return len(v)
+	case MapValue:
+		return len(v)
+	case []MapPair:
+		return len(v)
+	case ListIter:
+		return v.Len()
+	case MapIter:
+		return v.Len()
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len()
+	}
+	return 0
+}
@@ -0,0 +1,100 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// RecordSizeError describes a write rejected by the client before it was sent to the server,
+// because the size of its bins exceeds WritePolicy.MaxRecordSize.
+// The Error returned for the write wraps it, and it can be extracted with errors.As:
+//
+//	sizeErr := &as.RecordSizeError{}
+//	if errors.As(err, &sizeErr) {
+//	    println(sizeErr.Size, sizeErr.BinSizes["payload"])
+//	}
+type RecordSizeError struct {
+	// Size is the serialized size of the bins of the write in bytes.
+	Size int
+
+	// MaxSize is the MaxRecordSize of the policy.
+	MaxSize int
+
+	// BinSizes contains the serialized size of each bin of the write in bytes.
+	BinSizes map[string]int
+}
+
+// Error implements the error interface
+func (rse *RecordSizeError) Error() string {
+	names := make([]string, 0, len(rse.BinSizes))
+	for name := range rse.BinSizes {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if rse.BinSizes[names[i]] != rse.BinSizes[names[j]] {
+			return rse.BinSizes[names[i]] > rse.BinSizes[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "record size of %d bytes exceeds the maximum of %d bytes; bin sizes:", rse.Size, rse.MaxSize)
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		fmt.Fprintf(&sb, " %s=%d", name, rse.BinSizes[name])
+	}
+	return sb.String()
+}
+
+// recordSizeGuard accumulates the serialized sizes of the bins of a write to enforce
+// WritePolicy.MaxRecordSize. A nil guard does not enforce anything.
+type recordSizeGuard struct {
+	maxSize  int
+	size     int
+	binSizes map[string]int
+}
+
+// newRecordSizeGuard returns the guard for the policy, or nil if the policy sets no maximum.
+func newRecordSizeGuard(policy *WritePolicy) *recordSizeGuard {
+	if policy.MaxRecordSize <= 0 {
+		return nil
+	}
+	return &recordSizeGuard{maxSize: policy.MaxRecordSize, binSizes: map[string]int{}}
+}
+
+func (g *recordSizeGuard) add(binName string, size int) {
+	if g == nil {
+		return
+	}
+	g.size += size
+	g.binSizes[binName] += size
+}
+
+// check returns a RECORD_TOO_BIG error wrapping a RecordSizeError if the bins exceed the maximum size.
+func (g *recordSizeGuard) check() Error {
+	if g == nil || g.size <= g.maxSize {
+		return nil
+	}
+
+	rse := &RecordSizeError{Size: g.size, MaxSize: g.maxSize, BinSizes: g.binSizes}
+	return newErrorAndWrap(rse, types.RECORD_TOO_BIG, fmt.Sprintf("Record size of %d bytes exceeds WritePolicy.MaxRecordSize of %d bytes", g.size, g.maxSize))
+}
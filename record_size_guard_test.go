@@ -0,0 +1,82 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Record size guard", func() {
+
+	key, _ := NewKey("test", "set", 1)
+	big := strings.Repeat("a", 2048)
+
+	gg.It("must reject writes larger than the maximum before they are sent", func() {
+		policy := NewWritePolicy(0, 0)
+		policy.MaxRecordSize = 1024
+
+		cmd, err := newWriteCommand(nil, policy, key, []*Bin{NewBin("small", 1), NewBin("big", big)}, nil, _WRITE)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		err = cmd.writeBuffer(&cmd)
+		gm.Expect(err).To(gm.HaveOccurred())
+		gm.Expect(err.Matches(types.RECORD_TOO_BIG)).To(gm.BeTrue())
+
+		sizeErr := &RecordSizeError{}
+		gm.Expect(errors.As(err, &sizeErr)).To(gm.BeTrue())
+		gm.Expect(sizeErr.MaxSize).To(gm.Equal(1024))
+		gm.Expect(sizeErr.BinSizes).To(gm.HaveLen(2))
+		gm.Expect(sizeErr.BinSizes["big"]).To(gm.BeNumerically(">", 2048))
+		gm.Expect(sizeErr.Size).To(gm.Equal(sizeErr.BinSizes["big"] + sizeErr.BinSizes["small"]))
+		gm.Expect(sizeErr.Error()).To(gm.ContainSubstring("bin sizes: big="))
+	})
+
+	gg.It("must accept writes within the maximum", func() {
+		policy := NewWritePolicy(0, 0)
+		policy.MaxRecordSize = 4096
+
+		cmd, err := newWriteCommand(nil, policy, key, nil, BinMap{"big": big}, _WRITE)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(cmd.writeBuffer(&cmd)).ToNot(gm.HaveOccurred())
+	})
+
+	gg.It("must only count the write operations of Operate", func() {
+		policy := NewWritePolicy(0, 0)
+		policy.MaxRecordSize = 1024
+
+		ops := []*Operation{GetBinOp("big"), PutOp(NewBin("small", 1))}
+		args, err := newOperateArgs(nil, policy, key, ops)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		cmd, err := newOperateCommand(nil, policy, key, args, false)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(cmd.writeBuffer(&cmd)).ToNot(gm.HaveOccurred())
+
+		ops = append(ops, AppendOp(NewBin("big", big)))
+		args, err = newOperateArgs(nil, policy, key, ops)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		cmd, err = newOperateCommand(nil, policy, key, args, false)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		err = cmd.writeBuffer(&cmd)
+		gm.Expect(err).To(gm.HaveOccurred())
+		gm.Expect(err.Matches(types.RECORD_TOO_BIG)).To(gm.BeTrue())
+	})
+
+})
@@ -0,0 +1,69 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"strings"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Record size report", func() {
+
+	gg.It("must report the size and cardinality of each bin, the largest first", func() {
+		key, _ := NewKey("test", "set", 1)
+		bins := BinMap{
+			"int":  1,
+			"str":  strings.Repeat("a", 1000),
+			"list": []interface{}{1, 2, 3},
+			"map":  map[string]int{"a": 1, "b": 2},
+		}
+
+		report, err := NewRecordSizeReport(key, bins)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(report.Bins).To(gm.HaveLen(4))
+		gm.Expect(report.Bins[0].Name).To(gm.Equal("str"))
+		gm.Expect(report.Bins[0].Size).To(gm.BeNumerically(">", 1000))
+		gm.Expect(report.Bins[0].Cardinality).To(gm.Equal(0))
+
+		total := 0
+		for _, bs := range report.Bins {
+			total += bs.Size
+			switch bs.Name {
+			case "list":
+				gm.Expect(bs.Cardinality).To(gm.Equal(3))
+			case "map":
+				gm.Expect(bs.Cardinality).To(gm.Equal(2))
+			}
+		}
+		gm.Expect(report.BinsSize).To(gm.Equal(total))
+		gm.Expect(report.WireSize).To(gm.BeNumerically(">", report.BinsSize))
+
+		// the record reports the same sizes
+		rec := newRecord(nil, key, bins, 1, 0)
+		recReport, err := rec.SizeReport()
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(recReport).To(gm.Equal(report))
+	})
+
+	gg.It("must require the key", func() {
+		_, err := NewRecordSizeReport(nil, BinMap{"a": 1})
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+	})
+
+})
@@ -0,0 +1,91 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"sync"
+	"time"
+)
+
+// recordsGovernor is a token bucket shared by the node streams of a recordset,
+// limiting the total rate of the records they deliver. See MultiPolicy.TotalRecordsPerSecond.
+type recordsGovernor struct {
+	mutex  sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newRecordsGovernor returns a governor for the rate, or nil if the rate is not limited.
+// The bucket allows bursts of up to a tenth of a second worth of records.
+func newRecordsGovernor(recordsPerSecond int) *recordsGovernor {
+	if recordsPerSecond <= 0 {
+		return nil
+	}
+
+	burst := float64(recordsPerSecond) / 10
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &recordsGovernor{
+		rate:   float64(recordsPerSecond),
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token from the bucket, and returns how long the caller must wait
+// before using it.
+func (g *recordsGovernor) reserve(now time.Time) time.Duration {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if elapsed := now.Sub(g.last); elapsed > 0 {
+		g.tokens += elapsed.Seconds() * g.rate
+		if g.tokens > g.burst {
+			g.tokens = g.burst
+		}
+		g.last = now
+	}
+
+	g.tokens--
+	if g.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-g.tokens / g.rate * float64(time.Second))
+}
+
+// wait blocks until the caller may deliver a record, or the cancel channel is closed.
+// A nil governor does not block.
+func (g *recordsGovernor) wait(cancel <-chan struct{}) {
+	if g == nil {
+		return
+	}
+
+	d := g.reserve(time.Now())
+	if d <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-cancel:
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"sync"
+	"time"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Records governor", func() {
+
+	gg.It("must not limit the rate if not set", func() {
+		gm.Expect(newRecordsGovernor(0)).To(gm.BeNil())
+
+		var g *recordsGovernor
+		g.wait(nil)
+	})
+
+	gg.It("must allow short bursts and then deliver records at the rate", func() {
+		g := newRecordsGovernor(100)
+		now := g.last
+
+		for i := 0; i < 10; i++ {
+			gm.Expect(g.reserve(now)).To(gm.BeZero())
+		}
+		gm.Expect(g.reserve(now)).To(gm.BeNumerically("~", 10*time.Millisecond, time.Millisecond))
+		gm.Expect(g.reserve(now)).To(gm.BeNumerically("~", 20*time.Millisecond, time.Millisecond))
+
+		// the bucket refills at the rate
+		now = now.Add(time.Second)
+		for i := 0; i < 10; i++ {
+			gm.Expect(g.reserve(now)).To(gm.BeZero())
+		}
+		gm.Expect(g.reserve(now)).To(gm.BeNumerically(">", 0))
+	})
+
+	gg.It("must limit the total rate of concurrent streams", func() {
+		g := newRecordsGovernor(1000)
+
+		start := time.Now()
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 40; j++ {
+					g.wait(nil)
+				}
+			}()
+		}
+		wg.Wait()
+
+		// 320 records at 1000 rps, with a burst of 100
+		gm.Expect(time.Since(start)).To(gm.BeNumerically(">=", 200*time.Millisecond))
+	})
+
+	gg.It("must stop waiting when cancelled", func() {
+		g := newRecordsGovernor(1)
+		g.wait(nil)
+
+		cancel := make(chan struct{})
+		close(cancel)
+		start := time.Now()
+		g.wait(cancel)
+		gm.Expect(time.Since(start)).To(gm.BeNumerically("<", 100*time.Millisecond))
+	})
+
+})
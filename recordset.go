@@ -20,6 +20,7 @@ import (
 	"reflect"
 	"runtime"
 	"sync"
+	"time"
 
 	"github.com/aerospike/aerospike-client-go/v7/internal/atomic"
 )
@@ -56,6 +57,12 @@ type objectset struct {
 	chanLock sync.Mutex
 
 	taskID uint64
+
+	// limits the total rate of the records of all node streams, if set
+	governor *recordsGovernor
+
+	// the number of times and the total nanoseconds the node streams blocked on a full queue
+	blockedSends, blockedNanos atomic.Int
 }
 
 // TaskId returns the transactionId/jobId sent to the server for this recordset.
@@ -65,6 +72,23 @@ func (os *objectset) TaskId() uint64 {
 	return os.taskID
 }
 
+// limitRate limits the total rate of the records delivered by all the node streams of the set.
+// It must be called before the streams are started.
+func (os *objectset) limitRate(recordsPerSecond int) {
+	os.governor = newRecordsGovernor(recordsPerSecond)
+}
+
+// throttle blocks the node stream until it may deliver the next record.
+func (os *objectset) throttle() {
+	os.governor.wait(os.cancelled)
+}
+
+// blocked records a block of a node stream on the full queue, which started at start.
+func (os *objectset) blocked(start time.Time) {
+	os.blockedSends.IncrementAndGet()
+	os.blockedNanos.AddAndGet(int(time.Since(start)))
+}
+
 // Always set the taskID client-side to a non-zero random value
 func (os *objectset) resetTaskID() {
 	os.chanLock.Lock()
@@ -80,6 +104,9 @@ type Recordset struct {
 	// NOTE: Do not use Records directly. Range on channel returned by Results() instead.
 	// Will be unexported in the future
 	records chan *Result
+
+	// the fan-in of the results of the node streams, which feeds records
+	queue *resultQueue
 }
 
 // makes sure the recordset is closed eventually, even if it is not consumed
@@ -114,9 +141,10 @@ func newRecordset(recSize, goroutines int) *Recordset {
 	var nilChan chan *struct{}
 
 	rs := &Recordset{
-		records:   make(chan *Result, recSize),
 		objectset: *newObjectset(reflect.ValueOf(nilChan), goroutines),
 	}
+	rs.queue = newResultQueue(recSize, rs.cancelled)
+	rs.records = rs.queue.out
 
 	runtime.SetFinalizer(rs, recordsetFinalizer)
 	return rs
@@ -136,6 +164,52 @@ func (rcs *Recordset) Errors() <-chan Error {
 	panic("Errors chan not valid for non-reflection API")
 }
 
+// RecordsetStats describes the state of the queue between the node streams of a Recordset
+// and its consumer. The node streams never drop records: when the queue is full, they block
+// and stop reading from the server until the consumer catches up. Frequent or long blocks
+// indicate a slow consumer. The size of the queue is set by MultiPolicy.RecordQueueSize.
+// Half of it is buffered in the channel returned by Results, and the other half holds
+// the results of the node streams until they are forwarded to the channel.
+type RecordsetStats struct {
+	// QueueDepth is the number of records waiting in the queue to be consumed.
+	QueueDepth int
+
+	// QueueCapacity is the maximum number of records in the queue.
+	QueueCapacity int
+
+	// BlockedSends is the number of times a node stream blocked because the queue was full.
+	BlockedSends int
+
+	// BlockedTime is the total time the node streams spent blocked on the full queue.
+	BlockedTime time.Duration
+}
+
+// Stats returns the state of the queue of the recordset. For the reflection APIs,
+// the queue is the channel of objects passed by the user.
+func (rcs *Recordset) Stats() RecordsetStats {
+	res := RecordsetStats{
+		BlockedSends: rcs.blockedSends.Get(),
+		BlockedTime:  time.Duration(rcs.blockedNanos.Get()),
+	}
+
+	if rcs.records != nil {
+		res.QueueDepth, res.QueueCapacity = rcs.queue.len(), rcs.queue.cap()
+	} else if rcs.objChan.IsValid() && !rcs.objChan.IsNil() {
+		res.QueueDepth, res.QueueCapacity = rcs.objChan.Len(), rcs.objChan.Cap()
+	}
+	return res
+}
+
+// send queues the result for the consumer, blocking while the queue is full.
+// It returns false if the recordset was cancelled before the result could be queued.
+func (rcs *Recordset) send(res *Result) bool {
+	if !rcs.queue.waitForSpace(rcs.blocked) {
+		return false
+	}
+	rcs.queue.push(res)
+	return true
+}
+
 // Results returns a new receive-only channel with the results of the Scan/Query.
 // This is a more idiomatic approach to the iterator pattern in getting the
 // results back from the recordset, and doesn't require the user to write the
@@ -187,7 +261,7 @@ func (rcs *Recordset) signalEnd() {
 		defer rcs.chanLock.Unlock()
 
 		if rcs.records != nil {
-			close(rcs.records)
+			rcs.queue.end()
 		} else if rcs.objChan.IsValid() {
 			rcs.objChan.Close()
 		}
@@ -201,7 +275,7 @@ func (rcs *Recordset) sendError(err Error) {
 	defer rcs.chanLock.Unlock()
 	if rcs.IsActive() {
 		if rcs.records != nil {
-			rcs.records <- &Result{Err: err}
+			rcs.queue.push(&Result{Err: err})
 		} else {
 			rcs.errors <- err
 		}
@@ -0,0 +1,125 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// consume calls fn for each record of the recordset, as the records arrive.
+// The recordset is closed on the first error returned by the recordset or fn.
+func (rcs *Recordset) consume(fn func(rec *Record) Error) Error {
+	if rcs.records == nil {
+		return newError(types.PARAMETER_ERROR, "Aggregations are not supported on object recordsets")
+	}
+
+	for res := range rcs.Results() {
+		err := res.Err
+		if err == nil {
+			err = fn(res.Record)
+		}
+
+		if err != nil {
+			rcs.Close()
+			return err
+		}
+	}
+	return nil
+}
+
+// Reduce folds the records of the recordset into a single value, starting with seed.
+// fn is called for each record as the records arrive, with the value returned by
+// the previous call. If fn or the recordset return an error, the recordset is closed
+// and the error is returned.
+func (rcs *Recordset) Reduce(seed interface{}, fn func(acc interface{}, rec *Record) (interface{}, Error)) (interface{}, Error) {
+	acc := seed
+	err := rcs.consume(func(rec *Record) (err Error) {
+		acc, err = fn(acc, rec)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return acc, nil
+}
+
+// GroupBy groups the records of the recordset by the value of the bin.
+// Records without the bin are grouped under a nil key, and blob values are keyed
+// by a byte array of the same length. List and map values cannot be grouped.
+// If maxRecords is positive and the recordset has more records, the recordset is
+// closed and an error is returned to cap the memory used by the groups.
+func (rcs *Recordset) GroupBy(binName string, maxRecords int) (map[interface{}][]*Record, Error) {
+	res := map[interface{}][]*Record{}
+	count := 0
+	err := rcs.consume(func(rec *Record) Error {
+		count++
+		if maxRecords > 0 && count > maxRecords {
+			return newError(types.COMMON_ERROR, "GroupBy exceeded the limit of "+strconv.Itoa(maxRecords)+" records")
+		}
+
+		k, err := aggregationKey(rec.Bins[binName])
+		if err != nil {
+			return err
+		}
+		res[k] = append(res[k], rec)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CountDistinct returns the number of distinct values of the bin in the records of the recordset.
+// Records without the bin are not counted. List and map values cannot be counted.
+// If maxValues is positive and the bin has more distinct values, the recordset is
+// closed and an error is returned to cap the memory used to track the values.
+func (rcs *Recordset) CountDistinct(binName string, maxValues int) (int, Error) {
+	seen := map[interface{}]struct{}{}
+	err := rcs.consume(func(rec *Record) Error {
+		v, exists := rec.Bins[binName]
+		if !exists {
+			return nil
+		}
+
+		k, err := aggregationKey(v)
+		if err != nil {
+			return err
+		}
+		seen[k] = struct{}{}
+
+		if maxValues > 0 && len(seen) > maxValues {
+			return newError(types.COMMON_ERROR, "CountDistinct exceeded the limit of "+strconv.Itoa(maxValues)+" distinct values")
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(seen), nil
+}
+
+// aggregationKey converts the bin value to a key of a Go map.
+func aggregationKey(v interface{}) (interface{}, Error) {
+	k := valueMapKey(v)
+	if k != nil && !reflect.TypeOf(k).Comparable() {
+		return nil, newError(types.TYPE_NOT_SUPPORTED, fmt.Sprintf("Values of type %T cannot be aggregated", v))
+	}
+	return k, nil
+}
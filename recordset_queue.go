@@ -0,0 +1,198 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	iatomic "github.com/aerospike/aerospike-client-go/v7/internal/atomic"
+)
+
+// resultQueue is the fan-in of the results of the node streams of a Recordset.
+// The streams push their results to a lock-free queue, and a single goroutine
+// forwards them to the channel returned by Recordset.Results, so the streams
+// do not contend with each other and the consumer on the lock of the channel.
+//
+// The queue is bounded to keep the back pressure of the record queue: the streams
+// block while about capacity results wait to be forwarded. A few streams may pass
+// the bound at the same time, so it can be exceeded by the number of streams.
+type resultQueue struct {
+	out       chan *Result
+	cancelled <-chan struct{}
+
+	pending *iatomic.MPSCQueue[*Result]
+
+	// the number of results pushed and not forwarded yet.
+	// It is incremented after the push, so it can briefly be negative.
+	depth    atomic.Int64
+	capacity int64
+
+	// wakes the forwarder up when it waits for results
+	wake  chan struct{}
+	ended atomic.Bool
+
+	// the streams blocked on a full queue wait for space to be closed
+	waiting   atomic.Bool
+	spaceLock sync.Mutex
+	space     chan struct{}
+}
+
+// newResultQueue creates the queue and starts the forwarder.
+// Half of the size is buffered in the out channel and the other half in the queue.
+func newResultQueue(size int, cancelled <-chan struct{}) *resultQueue {
+	capacity := size - size/2
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	q := &resultQueue{
+		out:       make(chan *Result, size/2),
+		cancelled: cancelled,
+		pending:   iatomic.NewMPSCQueue[*Result](),
+		capacity:  int64(capacity),
+		wake:      make(chan struct{}, 1),
+	}
+
+	go q.forward()
+	return q
+}
+
+// len returns the number of results waiting to be consumed.
+func (q *resultQueue) len() int {
+	depth := int(q.depth.Load())
+	if depth < 0 {
+		depth = 0
+	}
+	return len(q.out) + depth
+}
+
+// cap returns the maximum number of results waiting to be consumed.
+func (q *resultQueue) cap() int {
+	return cap(q.out) + int(q.capacity)
+}
+
+// push queues the result. It never blocks; the streams call waitForSpace first.
+func (q *resultQueue) push(res *Result) {
+	q.pending.Push(res)
+	q.depth.Add(1)
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// waitForSpace blocks while the queue is full. It returns false if the
+// recordset was cancelled, and reports the time it blocked to blocked.
+func (q *resultQueue) waitForSpace(blocked func(start time.Time)) bool {
+	if q.depth.Load() < q.capacity {
+		return true
+	}
+
+	defer blocked(time.Now())
+
+	for {
+		q.spaceLock.Lock()
+		// the flag is set before the depth is checked, so the forwarder
+		// either sees the flag, or the space it made is seen here.
+		q.waiting.Store(true)
+		if q.depth.Load() < q.capacity {
+			q.spaceLock.Unlock()
+			return true
+		}
+		if q.space == nil {
+			q.space = make(chan struct{})
+		}
+		space := q.space
+		q.spaceLock.Unlock()
+
+		select {
+		case <-space:
+		case <-q.cancelled:
+			return false
+		}
+	}
+}
+
+// end marks the end of the results. The forwarder closes the out channel
+// once the queued results are forwarded. No result may be pushed after end.
+func (q *resultQueue) end() {
+	q.ended.Store(true)
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *resultQueue) forward() {
+	defer close(q.out)
+
+	for {
+		// read before the pop: all the pushes are visible once ended is set
+		ended := q.ended.Load()
+
+		res, ok := q.pending.Pop()
+		if !ok {
+			switch {
+			case ended:
+				return
+			case q.depth.Load() > 0:
+				// a push is in progress
+				runtime.Gosched()
+			default:
+				<-q.wake
+			}
+			continue
+		}
+
+		q.deliver(res)
+		q.depth.Add(-1)
+
+		if q.waiting.Load() {
+			q.signalSpace()
+		}
+	}
+}
+
+// deliver sends the result to the consumer. The results that do not fit in
+// the out channel once the recordset is cancelled are dropped.
+func (q *resultQueue) deliver(res *Result) {
+	select {
+	case q.out <- res:
+		return
+	default:
+	}
+
+	select {
+	case q.out <- res:
+	case <-q.cancelled:
+	}
+}
+
+// signalSpace wakes up the streams blocked on the full queue.
+func (q *resultQueue) signalSpace() {
+	q.spaceLock.Lock()
+	defer q.spaceLock.Unlock()
+
+	q.waiting.Store(false)
+	if q.space != nil {
+		close(q.space)
+		q.space = nil
+	}
+}
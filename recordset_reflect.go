@@ -0,0 +1,62 @@
+//go:build !as_performance
+
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"reflect"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// ObjectsInto decodes the results of the recordset, and appends them to the slice pointed to by objects.
+// It is meant for the results of QueryAggregate: the values returned by the stream UDF are decoded
+// into the slice elements, mapping Lua maps to structs using the `as` field tags, and nested lists
+// and maps into slices, maps and structs. Results of other queries are decoded from their bins.
+//
+// The results are decoded as they arrive. On the first error, the recordset is closed
+// and the error is returned; the elements decoded before the error remain in the slice.
+func (rcs *Recordset) ObjectsInto(objects interface{}) Error {
+	rval := reflect.ValueOf(objects)
+	if rval.Kind() != reflect.Ptr || rval.IsNil() || rval.Elem().Kind() != reflect.Slice {
+		return newError(types.PARAMETER_ERROR, "ObjectsInto expects a pointer to a slice")
+	}
+
+	slice := rval.Elem()
+	elemType := slice.Type().Elem()
+	return rcs.consume(func(rec *Record) Error {
+		elem := reflect.New(elemType).Elem()
+		if err := setValue(elem, aggregationResult(rec)); err != nil {
+			return err
+		}
+		slice.Set(reflect.Append(slice, elem))
+		return nil
+	})
+}
+
+// aggregationResult returns the value returned by the stream UDF for aggregation results,
+// or the bins of the record otherwise.
+func aggregationResult(rec *Record) interface{} {
+	if v, exists := rec.Bins["SUCCESS"]; exists && rec.Key == nil && len(rec.Bins) == 1 {
+		return v
+	}
+
+	res := make(map[interface{}]interface{}, len(rec.Bins))
+	for k, v := range rec.Bins {
+		res[k] = v
+	}
+	return res
+}
@@ -0,0 +1,99 @@
+//go:build !as_performance
+
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+type aggregationItem struct {
+	Name  string  `as:"name"`
+	Price float64 `as:"price"`
+}
+
+type aggregationResultObject struct {
+	Count int                `as:"count"`
+	Sum   float64            `as:"sum"`
+	Tags  []string           `as:"tags"`
+	Items []*aggregationItem `as:"items"`
+	Stats map[string]int     `as:"stats"`
+}
+
+var _ = gg.Describe("Recordset ObjectsInto", func() {
+
+	results := func(res ...*Result) *Recordset {
+		rs := newRecordset(len(res), 1)
+		for _, r := range res {
+			rs.send(r)
+		}
+		rs.signalEnd()
+		return rs
+	}
+
+	aggregation := func(v interface{}) *Result {
+		return &Result{Record: &Record{Bins: BinMap{"SUCCESS": v}}}
+	}
+
+	gg.It("must decode the aggregation results into structs", func() {
+		rs := results(aggregation(map[interface{}]interface{}{
+			"count": float64(2),
+			"sum":   float64(30.5),
+			"tags":  []interface{}{"a", "b"},
+			"items": []interface{}{
+				map[interface{}]interface{}{"name": "x", "price": float64(10)},
+				map[interface{}]interface{}{"name": "y", "price": 20.5},
+			},
+			"stats": map[interface{}]interface{}{"min": float64(10), "max": float64(20)},
+		}))
+
+		var objs []aggregationResultObject
+		gm.Expect(rs.ObjectsInto(&objs)).ToNot(gm.HaveOccurred())
+		gm.Expect(objs).To(gm.Equal([]aggregationResultObject{{
+			Count: 2,
+			Sum:   30.5,
+			Tags:  []string{"a", "b"},
+			Items: []*aggregationItem{{Name: "x", Price: 10}, {Name: "y", Price: 20.5}},
+			Stats: map[string]int{"min": 10, "max": 20},
+		}}))
+	})
+
+	gg.It("must decode the aggregation values and the record bins", func() {
+		var sums []float64
+		gm.Expect(results(aggregation(float64(1)), aggregation(2)).ObjectsInto(&sums)).ToNot(gm.HaveOccurred())
+		gm.Expect(sums).To(gm.Equal([]float64{1, 2}))
+
+		key, _ := NewKey("test", "test", 1)
+		var items []aggregationItem
+		gm.Expect(results(&Result{Record: &Record{Key: key, Bins: BinMap{"name": "x", "price": 1.5}}}).ObjectsInto(&items)).ToNot(gm.HaveOccurred())
+		gm.Expect(items).To(gm.Equal([]aggregationItem{{Name: "x", Price: 1.5}}))
+	})
+
+	gg.It("must return the errors", func() {
+		var objs []aggregationResultObject
+		err := results(aggregation("not a map")).ObjectsInto(&objs)
+		gm.Expect(err.Matches(types.PARSE_ERROR)).To(gm.BeTrue())
+
+		err = results(aggregation(1)).ObjectsInto(objs)
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+
+		err = results(&Result{Err: ErrTimeout.err()}).ObjectsInto(&objs)
+		gm.Expect(err.Matches(types.TIMEOUT)).To(gm.BeTrue())
+	})
+})
@@ -43,4 +43,88 @@ var _ = gg.Describe("Recordset test", func() {
 		}
 	})
 
+	gg.It("must block the producers on a full queue and report it", func() {
+		rs := newRecordset(2, 1)
+		gm.Expect(rs.send(&Result{})).To(gm.BeTrue())
+		// let the first result reach the channel, so the second one fits in the queue
+		gm.Eventually(func() int { return len(rs.records) }).Should(gm.Equal(1))
+		gm.Expect(rs.send(&Result{})).To(gm.BeTrue())
+
+		stats := rs.Stats()
+		gm.Expect(stats.QueueDepth).To(gm.Equal(2))
+		gm.Expect(stats.QueueCapacity).To(gm.Equal(2))
+		gm.Expect(stats.BlockedSends).To(gm.Equal(0))
+
+		done := make(chan bool)
+		go func() {
+			done <- rs.send(&Result{})
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		<-rs.Results()
+		gm.Expect(<-done).To(gm.BeTrue())
+
+		gm.Eventually(func() int { return rs.Stats().QueueDepth }).Should(gm.Equal(2))
+		stats = rs.Stats()
+		gm.Expect(stats.BlockedSends).To(gm.Equal(1))
+		gm.Expect(stats.BlockedTime).To(gm.BeNumerically(">=", 10*time.Millisecond))
+
+		// a cancelled recordset does not block the producers
+		rs.wgGoroutines.Done()
+		rs.Close()
+		gm.Expect(rs.send(&Result{})).To(gm.BeFalse())
+	})
+
+	gg.It("must deliver the results of all the streams in order and close the channel", func() {
+		const streams, count = 8, 1000
+		rs := newRecordset(16, streams)
+
+		for i := 0; i < streams; i++ {
+			go func(stream int) {
+				defer rs.signalEnd()
+				for j := 0; j < count; j++ {
+					rs.send(&Result{Record: &Record{Bins: BinMap{"stream": stream, "seq": j}}})
+				}
+			}(i)
+		}
+
+		next := make([]int, streams)
+		for res := range rs.Results() {
+			stream, seq := res.Record.Bins["stream"].(int), res.Record.Bins["seq"].(int)
+			gm.Expect(seq).To(gm.Equal(next[stream]))
+			next[stream]++
+		}
+
+		for i := range next {
+			gm.Expect(next[i]).To(gm.Equal(count))
+		}
+	})
+
+	gg.It("must release the blocked streams and close the channel when closed", func() {
+		rs := newRecordset(2, 2)
+
+		for i := 0; i < 2; i++ {
+			go func() {
+				defer rs.signalEnd()
+				for rs.send(&Result{}) {
+				}
+			}()
+		}
+
+		gm.Eventually(func() int { return rs.Stats().QueueDepth }).Should(gm.BeNumerically(">=", 2))
+		time.Sleep(20 * time.Millisecond)
+
+		// the blocks are counted when the streams are released
+		gm.Expect(rs.Close()).ToNot(gm.HaveOccurred())
+		gm.Expect(rs.Stats().BlockedSends).To(gm.BeNumerically(">", 0))
+
+		closed := make(chan struct{})
+		go func() {
+			for range rs.Results() {
+			}
+			close(closed)
+		}()
+		gm.Eventually(closed).Should(gm.BeClosed())
+	})
+
 })
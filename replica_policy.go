@@ -45,4 +45,12 @@ const (
 	// This option requires ClientPolicy.Rackaware to be enabled
 	// in order to function properly.
 	PREFER_RACK
+
+	// LEAST_LOADED distributes reads across nodes containing key's master and replicated partitions,
+	// picking the node with the fewest commands in flight from this client.
+	// Ties are resolved in favor of the master. On retries, the previously tried node is avoided
+	// if another replica is available.
+	// This improves tail latencies when a replica is slow, since the commands pile up on it.
+	// Writes behave as with SEQUENCE.
+	LEAST_LOADED
 )
@@ -0,0 +1,127 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// ReplicaRecord is the result of a read on one of the replicas of a record.
+// See Client.OperateReplicas.
+type ReplicaRecord struct {
+	// Node is the node holding the replica.
+	Node *Node
+
+	// Replica is the index of the replica: 0 for the master, 1 for the first prole, and so on.
+	Replica int
+
+	// Record is the record read from the replica. It is nil if the read failed.
+	Record *Record
+
+	// LastUpdateTime is the last update time of the record on the replica.
+	LastUpdateTime time.Time
+
+	// Err is the error returned by the read on the replica, if any.
+	// Replicas that do not hold the record return a KEY_NOT_FOUND_ERROR.
+	Err Error
+}
+
+// replicaReadCommand is a read-only operate command sent to a given replica of the record.
+type replicaReadCommand struct {
+	operateCommand
+
+	replica *Node
+}
+
+func (cmd *replicaReadCommand) getNode(ifc command) (*Node, Error) {
+	return cmd.replica, nil
+}
+
+func (cmd *replicaReadCommand) prepareRetry(ifc command, isTimeout bool) bool {
+	// retry on the same replica
+	return true
+}
+
+func (cmd *replicaReadCommand) Execute() Error {
+	return cmd.execute(cmd)
+}
+
+// OperateReplicas performs read-only operations on every replica of the record, in parallel,
+// and returns the result of each replica along with the generation and last update time of
+// the record on that replica.
+// This is a diagnostic API meant for consistency audits, e.g. to detect replication divergence
+// in AP namespaces; use Operate for regular reads.
+// If no operation is passed, all the bins of the record are read.
+// Operations that write are not allowed. Reading the last update time requires server v5.2+.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) OperateReplicas(policy *WritePolicy, key *Key, operations ...*Operation) ([]*ReplicaRecord, Error) {
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
+
+	if len(operations) == 0 {
+		operations = []*Operation{GetOp()}
+	}
+
+	ops := make([]*Operation, 0, len(operations)+1)
+	ops = append(ops, operations...)
+	ops = append(ops, LastUpdateTimeOp())
+
+	args, err := newOperateArgs(clnt.cluster, policy, key, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	if args.hasWrite {
+		return nil, newError(types.PARAMETER_ERROR, "OperateReplicas does not allow write operations")
+	}
+
+	partitions := clnt.cluster.commandPartitions()[key.namespace]
+	if partitions == nil {
+		return nil, newInvalidNamespaceError(key.namespace, len(clnt.cluster.commandPartitions()))
+	}
+
+	res := make([]*ReplicaRecord, len(partitions.Replicas))
+	var wg sync.WaitGroup
+	for i := range partitions.Replicas {
+		node := partitions.Replicas[i][key.PartitionId()]
+		res[i] = &ReplicaRecord{Node: node, Replica: i}
+		if node == nil || !node.IsActive() {
+			res[i].Err = newError(types.INVALID_NODE_ERROR, "No active node holds replica "+strconv.Itoa(i)+" of the record")
+			continue
+		}
+
+		cmd, err := newOperateCommand(clnt.cluster, policy, key, args, false)
+		if err != nil {
+			return nil, err
+		}
+
+		wg.Add(1)
+		go func(rr *ReplicaRecord, cmd *replicaReadCommand) {
+			defer wg.Done()
+			if rr.Err = cmd.Execute(); rr.Err != nil {
+				return
+			}
+
+			rr.Record = cmd.GetRecord()
+			rr.LastUpdateTime = rr.Record.LastUpdateTime
+		}(res[i], &replicaReadCommand{operateCommand: cmd, replica: node})
+	}
+	wg.Wait()
+
+	return res, nil
+}
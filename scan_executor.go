@@ -20,6 +20,7 @@ import (
 
 func (clnt *Client) scanPartitions(policy *ScanPolicy, tracker *partitionTracker, namespace string, setName string, recordset *Recordset, binNames ...string) {
 	defer recordset.signalEnd()
+	recordset.limitRate(policy.TotalRecordsPerSecond)
 
 	// for exponential backoff
 	interval := policy.SleepBetweenRetries
@@ -25,6 +25,7 @@ import (
 
 func (clnt *Client) scanPartitionObjects(policy *ScanPolicy, tracker *partitionTracker, namespace string, setName string, rs *Recordset, binNames ...string) Error {
 	defer rs.signalEnd()
+	rs.limitRate(policy.TotalRecordsPerSecond)
 
 	// for exponential backoff
 	interval := policy.SleepBetweenRetries
@@ -180,6 +180,38 @@ var _ = gg.Describe("Scan operations", func() {
 		gm.Expect(len(keys)).To(gm.Equal(0))
 	})
 
+	gg.It("must Scan and paginate by partition ids until the partition filters are done", func() {
+		gm.Expect(len(keys)).To(gm.Equal(keyCount))
+
+		var even, odd []int
+		for i := 0; i < 4096; i++ {
+			if i%2 == 0 {
+				even = append(even, i)
+			} else {
+				odd = append(odd, i)
+			}
+		}
+
+		spolicy := as.NewScanPolicy()
+		spolicy.MaxRecords = 30
+
+		received := 0
+		for _, ids := range [][]int{odd, even} {
+			pf := as.NewPartitionFilterByIds(ids)
+			for !pf.IsDone() {
+				recordset, err := client.ScanPartitions(spolicy, pf, ns, set)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				recs := checkResults(recordset, 0, false)
+				gm.Expect(recs).To(gm.BeNumerically("<=", int(spolicy.MaxRecords)))
+				received += recs
+			}
+		}
+
+		gm.Expect(received).To(gm.Equal(keyCount))
+		gm.Expect(len(keys)).To(gm.Equal(0))
+	})
+
 	gg.It("must Scan and get all records back from all partitions concurrently", func() {
 		gm.Expect(len(keys)).To(gm.Equal(keyCount))
 
@@ -299,6 +331,36 @@ var _ = gg.Describe("Scan operations", func() {
 		gm.Expect(len(keys)).To(gm.Equal(0))
 	})
 
+	gg.It("must Scan and get all records back for a specified node and partition ids", func() {
+		if *proxy {
+			gg.Skip("Not supported in Proxy Client")
+		}
+
+		gm.Expect(len(keys)).To(gm.Equal(keyCount))
+
+		var even, odd []int
+		for i := 0; i < 4096; i++ {
+			if i%2 == 0 {
+				even = append(even, i)
+			} else {
+				odd = append(odd, i)
+			}
+		}
+
+		for _, node := range nativeClient.GetNodes() {
+			for _, ids := range [][]int{odd, even} {
+				pf := as.NewPartitionFilterByIds(ids)
+				recordset, err := nativeClient.ScanNodePartitions(scanPolicy, node, pf, ns, set)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				checkResults(recordset, 0, false)
+				gm.Expect(pf.IsDone()).To(gm.BeTrue())
+			}
+		}
+
+		gm.Expect(len(keys)).To(gm.Equal(0))
+	})
+
 	gg.It("must Scan and get all records back from all nodes concurrently", func() {
 		gm.Expect(len(keys)).To(gm.Equal(keyCount))
 
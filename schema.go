@@ -0,0 +1,566 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+	ParticleType "github.com/aerospike/aerospike-client-go/v7/types/particle_type"
+)
+
+// BinSchema declares a bin of a set and the type of its values.
+type BinSchema struct {
+	// Name is the name of the bin.
+	Name string
+
+	// Type is the expected type of the values of the bin.
+	// ExpTypeNIL accepts values of any type.
+	Type ExpType
+}
+
+// IndexSchema declares a secondary index on a bin of a set.
+type IndexSchema struct {
+	// Name is the name of the index.
+	Name string
+
+	// Bin is the name of the indexed bin. It must be declared in the bins of the schema.
+	Bin string
+
+	// Type is the type of the indexed values.
+	Type IndexType
+
+	// CollectionType determines if the value of the bin, or the elements of its list
+	// or map are indexed.
+	CollectionType IndexCollectionType
+}
+
+// SetSchema declares the bins and the secondary indexes of a set.
+type SetSchema struct {
+	// Namespace is the namespace of the set.
+	Namespace string
+
+	// Set is the name of the set. Empty for the records without a set.
+	Set string
+
+	// Bins are the bins of the set, in the order they are formatted.
+	Bins []BinSchema
+
+	// Indexes are the secondary indexes of the set, created by SchemaRegistry.CreateIndexes.
+	Indexes []IndexSchema
+
+	// Strict rejects the writes to bins that are not declared in Bins.
+	Strict bool
+
+	bins map[string]ExpType
+}
+
+// SchemaError describes a write rejected by the client, or a bin read with a typed accessor,
+// that does not match the schema of the set.
+// The Error returned wraps it, and it can be extracted with errors.As.
+type SchemaError struct {
+	// Namespace is the namespace of the record.
+	Namespace string
+
+	// Set is the set of the record.
+	Set string
+
+	// Bin is the name of the bin.
+	Bin string
+
+	// Declared is the type of the bin in the schema.
+	Declared ExpType
+
+	// Actual is the type of the value, or the type required by the operation.
+	Actual ExpType
+
+	// Undeclared is set if the bin is not declared in a strict schema.
+	Undeclared bool
+}
+
+// Error implements the error interface
+func (se *SchemaError) Error() string {
+	if se.Undeclared {
+		return fmt.Sprintf("bin `%s` is not declared in the schema of set `%s.%s`", se.Bin, se.Namespace, se.Set)
+	}
+	return fmt.Sprintf("bin `%s` of set `%s.%s` is declared as %s, but got %s", se.Bin, se.Namespace, se.Set, se.Declared, se.Actual)
+}
+
+func (ss *SetSchema) newError(se *SchemaError) Error {
+	se.Namespace, se.Set = ss.Namespace, ss.Set
+	return newErrorAndWrap(se, types.BIN_TYPE_ERROR, se.Error())
+}
+
+// prepare indexes the bins of the schema, and checks that the schema is consistent.
+func (ss *SetSchema) prepare() Error {
+	if ss.Namespace == "" {
+		return newError(types.PARAMETER_ERROR, "Schema namespace is not set")
+	}
+
+	ss.bins = make(map[string]ExpType, len(ss.Bins))
+	for _, bin := range ss.Bins {
+		if bin.Name == "" {
+			return newError(types.PARAMETER_ERROR, fmt.Sprintf("Schema of set `%s.%s` declares a bin without a name", ss.Namespace, ss.Set))
+		}
+		if len(bin.Name) > 15 {
+			return newError(types.BIN_NAME_TOO_LONG, fmt.Sprintf("Schema of set `%s.%s` declares bin `%s` with a name longer than 15 characters", ss.Namespace, ss.Set, bin.Name))
+		}
+		if _, exists := ss.bins[bin.Name]; exists {
+			return newError(types.PARAMETER_ERROR, fmt.Sprintf("Schema of set `%s.%s` declares bin `%s` more than once", ss.Namespace, ss.Set, bin.Name))
+		}
+		ss.bins[bin.Name] = bin.Type
+	}
+
+	indexes := make(map[string]struct{}, len(ss.Indexes))
+	for _, idx := range ss.Indexes {
+		if _, exists := indexes[idx.Name]; exists || idx.Name == "" {
+			return newError(types.PARAMETER_ERROR, fmt.Sprintf("Schema of set `%s.%s` declares index `%s` more than once, or without a name", ss.Namespace, ss.Set, idx.Name))
+		}
+		indexes[idx.Name] = struct{}{}
+
+		declared, exists := ss.bins[idx.Bin]
+		if !exists {
+			return newError(types.PARAMETER_ERROR, fmt.Sprintf("Index `%s` of set `%s.%s` is on undeclared bin `%s`", idx.Name, ss.Namespace, ss.Set, idx.Bin))
+		}
+		if required := indexBinType(idx); declared != ExpTypeNIL && declared != required {
+			return newError(types.PARAMETER_ERROR, fmt.Sprintf("Index `%s` of set `%s.%s` requires bin `%s` to be %s, but it is declared as %s", idx.Name, ss.Namespace, ss.Set, idx.Bin, required, declared))
+		}
+	}
+
+	return nil
+}
+
+// indexBinType returns the type of the bins the index can be built on.
+func indexBinType(idx IndexSchema) ExpType {
+	switch idx.CollectionType {
+	case ICT_LIST:
+		return ExpTypeLIST
+	case ICT_MAPKEYS, ICT_MAPVALUES:
+		return ExpTypeMAP
+	}
+
+	switch idx.Type {
+	case NUMERIC:
+		return ExpTypeINT
+	case STRING:
+		return ExpTypeSTRING
+	case BLOB:
+		return ExpTypeBLOB
+	case GEO2DSPHERE:
+		return ExpTypeGEO
+	}
+	return ExpTypeNIL
+}
+
+// expTypeOf returns the type of the value as stored on the server.
+// Returns false if the type of the value is not supported.
+func expTypeOf(value interface{}) (ExpType, bool) {
+	if value == nil {
+		return ExpTypeNIL, true
+	}
+
+	v, ok := value.(Value)
+	if !ok {
+		if v = tryConcreteValue(value); v == nil && newValueReflect != nil {
+			v = newValueReflect(value)
+		}
+		if v == nil {
+			return ExpTypeNIL, false
+		}
+	}
+
+	switch v.GetType() {
+	case ParticleType.NULL:
+		return ExpTypeNIL, true
+	case ParticleType.INTEGER:
+		return ExpTypeINT, true
+	case ParticleType.FLOAT:
+		return ExpTypeFLOAT, true
+	case ParticleType.STRING:
+		return ExpTypeSTRING, true
+	case ParticleType.BLOB:
+		return ExpTypeBLOB, true
+	case ParticleType.BOOL:
+		return ExpTypeBOOL, true
+	case ParticleType.HLL:
+		return ExpTypeHLL, true
+	case ParticleType.MAP:
+		return ExpTypeMAP, true
+	case ParticleType.LIST:
+		return ExpTypeLIST, true
+	case ParticleType.GEOJSON:
+		return ExpTypeGEO, true
+	}
+	return ExpTypeNIL, false
+}
+
+// check returns an error if the bin is not declared in a strict schema,
+// or if it is declared with a type other than actual.
+func (ss *SetSchema) check(name string, actual ExpType) Error {
+	declared, exists := ss.bins[name]
+	if !exists {
+		if ss.Strict {
+			return ss.newError(&SchemaError{Bin: name, Undeclared: true})
+		}
+		return nil
+	}
+
+	if declared == ExpTypeNIL || actual == ExpTypeNIL || declared == actual {
+		return nil
+	}
+	return ss.newError(&SchemaError{Bin: name, Declared: declared, Actual: actual})
+}
+
+// checkValue checks a value written to the bin. Nil values delete the bin and are always allowed.
+func (ss *SetSchema) checkValue(name string, value interface{}) Error {
+	actual, _ := expTypeOf(value)
+	return ss.check(name, actual)
+}
+
+// checkOperation checks the bin written by the operation.
+func (ss *SetSchema) checkOperation(op *Operation) Error {
+	if !op.opType.isWrite || op.binName == "" {
+		return nil
+	}
+
+	switch op.opType {
+	case _WRITE, _ADD, _APPEND, _PREPEND:
+		return ss.checkValue(op.binName, op.binValue)
+	case _BIT_MODIFY:
+		return ss.check(op.binName, ExpTypeBLOB)
+	case _HLL_MODIFY:
+		return ss.check(op.binName, ExpTypeHLL)
+	case _CDT_MODIFY, _MAP_MODIFY:
+		// operations on nested collections do not determine the type of the bin
+		if len(op.ctx) > 0 {
+			return ss.check(op.binName, ExpTypeNIL)
+		}
+		if op.opType == _CDT_MODIFY {
+			return ss.check(op.binName, ExpTypeLIST)
+		}
+		return ss.check(op.binName, ExpTypeMAP)
+	}
+	return ss.check(op.binName, ExpTypeNIL)
+}
+
+type schemaKey struct {
+	namespace string
+	set       string
+}
+
+// SchemaRegistry holds the schemas of the sets the application uses.
+// When assigned to ClientPolicy.Schemas, the writes of the client are validated against the
+// schema of the set of their key before they are sent to the server, so that a value of the
+// wrong type fails with a BIN_TYPE_ERROR wrapping a SchemaError instead of reaching the database.
+// Sets without a schema are not validated.
+// The registry is safe for concurrent use.
+type SchemaRegistry struct {
+	mutex sync.RWMutex
+	sets  map[schemaKey]*SetSchema
+}
+
+// NewSchemaRegistry returns an empty schema registry.
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{
+		sets: map[schemaKey]*SetSchema{},
+	}
+}
+
+// Register adds the schema of a set to the registry, replacing the previous schema of the set.
+// The schema is copied, so later changes to it are not taken into account.
+func (sr *SchemaRegistry) Register(schema *SetSchema) Error {
+	ss := &SetSchema{
+		Namespace: schema.Namespace,
+		Set:       schema.Set,
+		Bins:      append([]BinSchema{}, schema.Bins...),
+		Indexes:   append([]IndexSchema{}, schema.Indexes...),
+		Strict:    schema.Strict,
+	}
+	if err := ss.prepare(); err != nil {
+		return err
+	}
+
+	sr.mutex.Lock()
+	sr.sets[schemaKey{ss.Namespace, ss.Set}] = ss
+	sr.mutex.Unlock()
+	return nil
+}
+
+// Schema returns the schema of a set, or nil if the set has no schema.
+func (sr *SchemaRegistry) Schema(namespace, set string) *SetSchema {
+	if sr == nil {
+		return nil
+	}
+
+	sr.mutex.RLock()
+	defer sr.mutex.RUnlock()
+	return sr.sets[schemaKey{namespace, set}]
+}
+
+// Schemas returns the schemas of the registry, sorted by namespace and set.
+func (sr *SchemaRegistry) Schemas() []*SetSchema {
+	sr.mutex.RLock()
+	res := make([]*SetSchema, 0, len(sr.sets))
+	for _, ss := range sr.sets {
+		res = append(res, ss)
+	}
+	sr.mutex.RUnlock()
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Namespace != res[j].Namespace {
+			return res[i].Namespace < res[j].Namespace
+		}
+		return res[i].Set < res[j].Set
+	})
+	return res
+}
+
+func (sr *SchemaRegistry) schemaOf(key *Key) *SetSchema {
+	if sr == nil || key == nil {
+		return nil
+	}
+	return sr.Schema(key.namespace, key.setName)
+}
+
+// ValidateBins checks the bins written to the record of the key against the schema of its set.
+func (sr *SchemaRegistry) ValidateBins(key *Key, bins BinMap) Error {
+	ss := sr.schemaOf(key)
+	if ss == nil {
+		return nil
+	}
+
+	for name, value := range bins {
+		if err := ss.checkValue(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateOperations checks the bins written by the operations on the record of the key
+// against the schema of its set. Read operations are not checked.
+func (sr *SchemaRegistry) ValidateOperations(key *Key, operations ...*Operation) Error {
+	ss := sr.schemaOf(key)
+	if ss == nil {
+		return nil
+	}
+
+	for _, op := range operations {
+		if err := ss.checkOperation(op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateWrite checks the bins of a write command.
+func (sr *SchemaRegistry) validateWrite(key *Key, bins []*Bin, binMap BinMap) Error {
+	ss := sr.schemaOf(key)
+	if ss == nil {
+		return nil
+	}
+
+	for name, value := range binMap {
+		if err := ss.checkValue(name, value); err != nil {
+			return err
+		}
+	}
+	for _, bin := range bins {
+		if err := ss.checkValue(bin.Name, bin.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateBatch checks the bins written by the batch writes.
+func (sr *SchemaRegistry) validateBatch(records []BatchRecordIfc) Error {
+	if sr == nil {
+		return nil
+	}
+
+	for _, record := range records {
+		if bw, ok := record.(*BatchWrite); ok {
+			if err := sr.ValidateOperations(bw.Key, bw.Ops...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CreateIndexes creates the secondary indexes declared in the schemas of the registry,
+// and waits until they are built. The indexes that already exist are skipped.
+func (sr *SchemaRegistry) CreateIndexes(clnt ClientIfc, policy *WritePolicy) Error {
+	for _, ss := range sr.Schemas() {
+		for _, idx := range ss.Indexes {
+			task, err := clnt.CreateComplexIndex(policy, ss.Namespace, ss.Set, idx.Name, idx.Bin, idx.Type, idx.CollectionType)
+			if err != nil {
+				if err.Matches(types.INDEX_FOUND) {
+					continue
+				}
+				return err
+			}
+
+			if err := <-task.OnComplete(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Format returns a multi-line representation of the record, with the bins in the order of the
+// schema of its set and their declared types. Values that do not match the declared type are
+// flagged, and the undeclared bins are listed last, sorted by name.
+func (sr *SchemaRegistry) Format(rec *Record) string {
+	var sb strings.Builder
+	if rec.Key != nil {
+		sb.WriteString(rec.Key.String())
+		sb.WriteByte(' ')
+	}
+	fmt.Fprintf(&sb, "generation: %d, expiration: %d\n", rec.Generation, rec.Expiration)
+
+	formatBin := func(name string, value interface{}, declared ExpType, isDeclared bool) {
+		fmt.Fprintf(&sb, "  %s: ", name)
+		if s, ok := value.(string); ok {
+			fmt.Fprintf(&sb, "%q", s)
+		} else {
+			fmt.Fprintf(&sb, "%v", value)
+		}
+
+		actual, _ := expTypeOf(value)
+		switch {
+		case !isDeclared:
+			sb.WriteString(" (undeclared)\n")
+		case declared != ExpTypeNIL && actual != ExpTypeNIL && declared != actual:
+			fmt.Fprintf(&sb, " (%s, got %s)\n", declared, actual)
+		default:
+			fmt.Fprintf(&sb, " (%s)\n", declared)
+		}
+	}
+
+	var ss *SetSchema
+	if rec.Key != nil {
+		ss = sr.Schema(rec.Key.namespace, rec.Key.setName)
+	}
+
+	var undeclared []string
+	if ss != nil {
+		for _, bin := range ss.Bins {
+			if value, exists := rec.Bins[bin.Name]; exists {
+				formatBin(bin.Name, value, bin.Type, true)
+			}
+		}
+	}
+	for name := range rec.Bins {
+		if ss == nil {
+			undeclared = append(undeclared, name)
+		} else if _, exists := ss.bins[name]; !exists {
+			undeclared = append(undeclared, name)
+		}
+	}
+	sort.Strings(undeclared)
+	for _, name := range undeclared {
+		formatBin(name, rec.Bins[name], ExpTypeNIL, false)
+	}
+
+	return sb.String()
+}
+
+// Typed returns the typed accessors for the bins of the record.
+// The accessors check the values against the schema of the set of the record.
+func (sr *SchemaRegistry) Typed(rec *Record) *TypedRecord {
+	var ss *SetSchema
+	if rec.Key != nil {
+		ss = sr.Schema(rec.Key.namespace, rec.Key.setName)
+	}
+	return &TypedRecord{Record: rec, schema: ss}
+}
+
+// TypedRecord gives access to the bins of a record with the types declared in the schema of its set.
+// The accessors fail with a BIN_TYPE_ERROR wrapping a SchemaError if the bin is declared with
+// another type in the schema, or if its value has another type. Missing bins return the zero value.
+type TypedRecord struct {
+	*Record
+
+	schema *SetSchema
+}
+
+func typedBin[T any](tr *TypedRecord, name string, expected ExpType) (T, Error) {
+	var zero T
+
+	ss := tr.schema
+	if ss == nil {
+		ss = &SetSchema{}
+		if tr.Key != nil {
+			ss.Namespace, ss.Set = tr.Key.namespace, tr.Key.setName
+		}
+	}
+
+	if declared, exists := ss.bins[name]; exists && declared != ExpTypeNIL && declared != expected {
+		return zero, ss.newError(&SchemaError{Bin: name, Declared: declared, Actual: expected})
+	}
+
+	value, exists := tr.Bins[name]
+	if !exists || value == nil {
+		return zero, nil
+	}
+
+	res, ok := value.(T)
+	if !ok {
+		actual, _ := expTypeOf(value)
+		return zero, ss.newError(&SchemaError{Bin: name, Declared: expected, Actual: actual})
+	}
+	return res, nil
+}
+
+// GetInt returns the value of an INT bin.
+func (tr *TypedRecord) GetInt(name string) (int, Error) {
+	return typedBin[int](tr, name, ExpTypeINT)
+}
+
+// GetFloat returns the value of a FLOAT bin.
+func (tr *TypedRecord) GetFloat(name string) (float64, Error) {
+	return typedBin[float64](tr, name, ExpTypeFLOAT)
+}
+
+// GetString returns the value of a STRING bin.
+func (tr *TypedRecord) GetString(name string) (string, Error) {
+	return typedBin[string](tr, name, ExpTypeSTRING)
+}
+
+// GetBool returns the value of a BOOL bin.
+func (tr *TypedRecord) GetBool(name string) (bool, Error) {
+	return typedBin[bool](tr, name, ExpTypeBOOL)
+}
+
+// GetBytes returns the value of a BLOB bin.
+func (tr *TypedRecord) GetBytes(name string) ([]byte, Error) {
+	return typedBin[[]byte](tr, name, ExpTypeBLOB)
+}
+
+// GetList returns the value of a LIST bin.
+func (tr *TypedRecord) GetList(name string) ([]interface{}, Error) {
+	return typedBin[[]interface{}](tr, name, ExpTypeLIST)
+}
+
+// GetMap returns the value of a MAP bin.
+func (tr *TypedRecord) GetMap(name string) (map[interface{}]interface{}, Error) {
+	return typedBin[map[interface{}]interface{}](tr, name, ExpTypeMAP)
+}
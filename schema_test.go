@@ -0,0 +1,196 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Schema registry", func() {
+
+	key, _ := NewKey("test", "users", 1)
+	other, _ := NewKey("test", "other", 1)
+
+	var registry *SchemaRegistry
+
+	gg.BeforeEach(func() {
+		registry = NewSchemaRegistry()
+		err := registry.Register(&SetSchema{
+			Namespace: "test",
+			Set:       "users",
+			Bins: []BinSchema{
+				{Name: "name", Type: ExpTypeSTRING},
+				{Name: "age", Type: ExpTypeINT},
+				{Name: "tags", Type: ExpTypeLIST},
+				{Name: "attrs", Type: ExpTypeMAP},
+				{Name: "any"},
+			},
+			Indexes: []IndexSchema{
+				{Name: "idx_age", Bin: "age", Type: NUMERIC},
+				{Name: "idx_tags", Bin: "tags", Type: STRING, CollectionType: ICT_LIST},
+			},
+		})
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+	})
+
+	gg.It("must reject inconsistent schemas", func() {
+		err := registry.Register(&SetSchema{Set: "users"})
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+
+		err = registry.Register(&SetSchema{Namespace: "test", Bins: []BinSchema{{Name: "a"}, {Name: "a"}}})
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+
+		err = registry.Register(&SetSchema{Namespace: "test", Bins: []BinSchema{{Name: strings.Repeat("a", 16)}}})
+		gm.Expect(err.Matches(types.BIN_NAME_TOO_LONG)).To(gm.BeTrue())
+
+		err = registry.Register(&SetSchema{Namespace: "test", Indexes: []IndexSchema{{Name: "idx", Bin: "a", Type: NUMERIC}}})
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+
+		err = registry.Register(&SetSchema{
+			Namespace: "test",
+			Bins:      []BinSchema{{Name: "a", Type: ExpTypeSTRING}},
+			Indexes:   []IndexSchema{{Name: "idx", Bin: "a", Type: NUMERIC}},
+		})
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+		gm.Expect(err.Error()).To(gm.ContainSubstring("requires bin `a` to be INT, but it is declared as STRING"))
+
+		gm.Expect(registry.Schemas()).To(gm.HaveLen(1))
+	})
+
+	gg.It("must validate the types of the written bins", func() {
+		gm.Expect(registry.ValidateBins(key, BinMap{"name": "Alice", "age": 30, "tags": []string{"a"}, "attrs": map[string]int{"a": 1}})).ToNot(gm.HaveOccurred())
+		gm.Expect(registry.ValidateBins(key, BinMap{"name": nil, "any": 1.5, "extra": true})).ToNot(gm.HaveOccurred())
+		gm.Expect(registry.ValidateBins(other, BinMap{"age": "30"})).ToNot(gm.HaveOccurred())
+
+		err := registry.ValidateBins(key, BinMap{"age": "30"})
+		gm.Expect(err.Matches(types.BIN_TYPE_ERROR)).To(gm.BeTrue())
+
+		schemaErr := &SchemaError{}
+		gm.Expect(errors.As(err, &schemaErr)).To(gm.BeTrue())
+		gm.Expect(*schemaErr).To(gm.Equal(SchemaError{Namespace: "test", Set: "users", Bin: "age", Declared: ExpTypeINT, Actual: ExpTypeSTRING}))
+		gm.Expect(schemaErr.Error()).To(gm.Equal("bin `age` of set `test.users` is declared as INT, but got STRING"))
+
+		var nilRegistry *SchemaRegistry
+		gm.Expect(nilRegistry.ValidateBins(key, BinMap{"age": "30"})).ToNot(gm.HaveOccurred())
+	})
+
+	gg.It("must reject the undeclared bins of strict schemas", func() {
+		gm.Expect(registry.Register(&SetSchema{Namespace: "test", Set: "other", Bins: []BinSchema{{Name: "a"}}, Strict: true})).ToNot(gm.HaveOccurred())
+
+		gm.Expect(registry.ValidateBins(other, BinMap{"a": 1})).ToNot(gm.HaveOccurred())
+		err := registry.ValidateBins(other, BinMap{"b": 1})
+		gm.Expect(err.Matches(types.BIN_TYPE_ERROR)).To(gm.BeTrue())
+		gm.Expect(err.Error()).To(gm.ContainSubstring("bin `b` is not declared in the schema of set `test.other`"))
+	})
+
+	gg.It("must validate the bins written by operations", func() {
+		gm.Expect(registry.ValidateOperations(key,
+			PutOp(NewBin("name", "Bob")),
+			AddOp(NewBin("age", 1)),
+			AppendOp(NewBin("name", "!")),
+			ListAppendOp("tags", "b"),
+			MapPutOp(DefaultMapPolicy(), "attrs", "b", 2),
+			ListAppendWithPolicyContextOp(DefaultListPolicy(), "attrs", []*CDTContext{CtxMapKey(NewValue("list"))}, 1),
+			GetBinOp("age"),
+		)).ToNot(gm.HaveOccurred())
+
+		err := registry.ValidateOperations(key, AddOp(NewBin("name", 1)))
+		gm.Expect(err.Matches(types.BIN_TYPE_ERROR)).To(gm.BeTrue())
+
+		err = registry.ValidateOperations(key, ListAppendOp("attrs", 1))
+		schemaErr := &SchemaError{}
+		gm.Expect(errors.As(err, &schemaErr)).To(gm.BeTrue())
+		gm.Expect(schemaErr.Declared).To(gm.Equal(ExpTypeMAP))
+		gm.Expect(schemaErr.Actual).To(gm.Equal(ExpTypeLIST))
+	})
+
+	gg.It("must validate the writes of the client before they are sent", func() {
+		cluster := &Cluster{}
+		cluster.clientPolicy.Schemas = registry
+		policy := NewWritePolicy(0, 0)
+
+		_, err := newWriteCommand(cluster, policy, key, []*Bin{NewBin("age", 1.5)}, nil, _WRITE)
+		gm.Expect(err.Matches(types.BIN_TYPE_ERROR)).To(gm.BeTrue())
+
+		_, err = newWriteCommand(cluster, policy, key, nil, BinMap{"name": 1}, _WRITE)
+		gm.Expect(err.Matches(types.BIN_TYPE_ERROR)).To(gm.BeTrue())
+
+		_, err = newOperateArgs(cluster, policy, key, []*Operation{GetBinOp("name"), PutOp(NewBin("tags", "a"))})
+		gm.Expect(err.Matches(types.BIN_TYPE_ERROR)).To(gm.BeTrue())
+
+		err = registry.validateBatch([]BatchRecordIfc{
+			NewBatchRead(nil, key, nil),
+			NewBatchWrite(nil, key, PutOp(NewBin("age", "1"))),
+		})
+		gm.Expect(err.Matches(types.BIN_TYPE_ERROR)).To(gm.BeTrue())
+	})
+
+	gg.It("must format the records with the declared types", func() {
+		rec := newRecord(nil, key, BinMap{"zeta": 1, "age": "30", "name": "Alice", "extra": true}, 2, 100)
+
+		lines := strings.Split(strings.TrimSpace(registry.Format(rec)), "\n")
+		gm.Expect(lines).To(gm.Equal([]string{
+			key.String() + " generation: 2, expiration: 100",
+			`  name: "Alice" (STRING)`,
+			`  age: "30" (INT, got STRING)`,
+			"  extra: true (undeclared)",
+			"  zeta: 1 (undeclared)",
+		}))
+	})
+
+	gg.It("must give typed access to the bins", func() {
+		rec := newRecord(nil, key, BinMap{"name": "Alice", "age": "30", "tags": []interface{}{"a"}, "any": 1.5}, 1, 0)
+		tr := registry.Typed(rec)
+
+		name, err := tr.GetString("name")
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(name).To(gm.Equal("Alice"))
+
+		tags, err := tr.GetList("tags")
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(tags).To(gm.Equal([]interface{}{"a"}))
+
+		f, err := tr.GetFloat("any")
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(f).To(gm.Equal(1.5))
+
+		attrs, err := tr.GetMap("attrs")
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(attrs).To(gm.BeNil())
+
+		// the value drifted from the declared type
+		_, err = tr.GetInt("age")
+		gm.Expect(err.Matches(types.BIN_TYPE_ERROR)).To(gm.BeTrue())
+		gm.Expect(err.Error()).To(gm.ContainSubstring("declared as INT, but got STRING"))
+
+		// the accessor does not match the declared type
+		_, err = tr.GetInt("name")
+		gm.Expect(err.Matches(types.BIN_TYPE_ERROR)).To(gm.BeTrue())
+
+		// records of sets without a schema only check the values
+		untyped := registry.Typed(newRecord(nil, other, BinMap{"age": 30}, 1, 0))
+		age, err := untyped.GetInt("age")
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(age).To(gm.Equal(30))
+		_, err = untyped.GetString("age")
+		gm.Expect(err.Matches(types.BIN_TYPE_ERROR)).To(gm.BeTrue())
+	})
+})
@@ -0,0 +1,98 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	as "github.com/aerospike/aerospike-client-go/v7"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+// ALL tests are isolated by SetName and Key, which are 50 random characters
+var _ = gg.Describe("DefaultSendKey", func() {
+
+	var ns = *namespace
+	var set = randString(50)
+
+	var skclient *as.Client
+
+	gg.BeforeEach(func() {
+		if *proxy {
+			gg.Skip("Not supported in Proxy Client")
+		}
+
+		cp := *clientPolicy
+		cp.DefaultSendKey = true
+
+		dbHost := as.NewHost(*host, *port)
+		dbHost.TLSName = *nodeTLSName
+
+		var err error
+		skclient, err = as.NewClientWithPolicyAndHost(&cp, dbHost)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+	})
+
+	gg.AfterEach(func() {
+		if skclient != nil {
+			skclient.Close()
+		}
+	})
+
+	keyStored := func(key *as.Key) bool {
+		policy := as.NewPolicy()
+		policy.FilterExpression = as.ExpKeyExists()
+		_, err := nativeClient.Get(policy, key)
+		if err != nil && err.Matches(as.ErrFilteredOut.ResultCode) {
+			return false
+		}
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		return true
+	}
+
+	gg.It("must set SendKey on the default write policies", func() {
+		gm.Expect(skclient.DefaultWritePolicy.SendKey).To(gm.BeTrue())
+		gm.Expect(skclient.DefaultBatchPolicy.SendKey).To(gm.BeTrue())
+		gm.Expect(skclient.DefaultBatchWritePolicy.SendKey).To(gm.BeTrue())
+		gm.Expect(skclient.DefaultBatchDeletePolicy.SendKey).To(gm.BeTrue())
+		gm.Expect(skclient.DefaultBatchUDFPolicy.SendKey).To(gm.BeTrue())
+	})
+
+	gg.It("must store the user key on single record writes", func() {
+		key, err := as.NewKey(ns, set, randString(50))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		err = skclient.PutBins(nil, key, as.NewBin("a", 1))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(keyStored(key)).To(gm.BeTrue())
+	})
+
+	gg.It("must store the user key on batch writes", func() {
+		key1, err := as.NewKey(ns, set, randString(50))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		key2, err := as.NewKey(ns, set, randString(50))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		// a record policy without SendKey does not override the batch policy
+		err = skclient.BatchOperate(nil, []as.BatchRecordIfc{
+			as.NewBatchWrite(nil, key1, as.PutOp(as.NewBin("a", 1))),
+			as.NewBatchWrite(as.NewBatchWritePolicy(), key2, as.PutOp(as.NewBin("a", 1))),
+		})
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		gm.Expect(keyStored(key1)).To(gm.BeTrue())
+		gm.Expect(keyStored(key2)).To(gm.BeTrue())
+	})
+})
@@ -0,0 +1,278 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// SetInfo contains the metadata of a set, aggregated over the nodes of the cluster.
+type SetInfo struct {
+	// Namespace is the namespace of the set.
+	Namespace string
+
+	// Name is the name of the set.
+	Name string
+
+	// Objects is the number of master records of the set; the replicas are not counted.
+	Objects int64
+
+	// Tombstones is the number of master tombstones of the set.
+	Tombstones int64
+
+	// MemoryBytes is the memory used by the records of the set on all the nodes, replicas included.
+	// Only reported by servers prior to v7.
+	MemoryBytes int64
+
+	// DeviceBytes is the storage used by the records of the set on all the nodes, replicas included.
+	// Only reported by servers prior to v7.
+	DeviceBytes int64
+
+	// DataBytes is the storage used by the records of the set on all the nodes, replicas included.
+	// Only reported by servers v7+.
+	DataBytes int64
+
+	// Indexes is the number of secondary indexes on the set.
+	Indexes int
+
+	// StopWritesCount is the number of records of the set at which writes are stopped. 0 means no limit.
+	StopWritesCount int64
+
+	// StopWritesSize is the size in bytes of the set at which writes are stopped. 0 means no limit.
+	StopWritesSize int64
+
+	// TruncateLastUpdate is the cutoff of the last truncation of the set:
+	// the records last updated before it were removed. It is the zero time if the set was never truncated.
+	TruncateLastUpdate time.Time
+}
+
+// ListSets returns the metadata of the sets of the namespace, sorted by name.
+// The statistics of all the nodes are requested and aggregated.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) ListSets(policy *InfoPolicy, namespace string) ([]*SetInfo, Error) {
+	policy = clnt.getUsableInfoPolicy(policy)
+
+	nodes := clnt.cluster.GetNodes()
+	if len(nodes) == 0 {
+		return nil, ErrClusterIsEmpty.err()
+	}
+
+	nsCmd := "namespace/" + namespace
+	setsCmd := "sets/" + namespace
+
+	sets := map[string]*SetInfo{}
+	replicationFactor := int64(1)
+	for _, node := range nodes {
+		info, err := node.RequestInfo(policy, nsCmd, setsCmd)
+		if err != nil {
+			return nil, err
+		}
+
+		nsStats := parseInfoStats(info[nsCmd], ";")
+		if len(nsStats) == 0 {
+			return nil, newError(types.PARAMETER_ERROR, fmt.Sprintf("namespace `%s` not found on node %s", namespace, node.String()))
+		}
+		if rf := infoReplicationFactor(nsStats); rf > replicationFactor {
+			replicationFactor = rf
+		}
+
+		mergeSetInfos(sets, namespace, info[setsCmd])
+	}
+
+	return sortedSetInfos(sets, replicationFactor), nil
+}
+
+// DeleteSet removes all the records of the set, and waits until they are removed on all the nodes.
+// The set name is required; use Truncate to remove all the records of a namespace.
+// The records written during the truncation delay its completion, until policy.WaitTimeout.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) DeleteSet(policy *DeleteSetPolicy, namespace, set string) Error {
+	if policy == nil {
+		policy = NewDeleteSetPolicy()
+	}
+
+	if len(set) == 0 {
+		return newError(types.PARAMETER_ERROR, "set name is required to delete a set")
+	}
+
+	if err := clnt.Truncate(&policy.InfoPolicy, namespace, set, nil); err != nil {
+		return err
+	}
+
+	if err := clnt.waitForEmptySet(policy, namespace, set); err != nil {
+		return err
+	}
+
+	if policy.DropIndexes {
+		wpolicy := NewWritePolicy(0, 0)
+		wpolicy.TotalTimeout = policy.Timeout
+		if _, err := clnt.DropSetIndexes(wpolicy, namespace, set); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitForEmptySet polls the statistics of the set until it has no records on any node.
+func (clnt *Client) waitForEmptySet(policy *DeleteSetPolicy, namespace, set string) Error {
+	if policy.WaitTimeout <= 0 {
+		return nil
+	}
+
+	interval := policy.PollInterval
+	if interval <= 0 {
+		interval = 100 * time.Millisecond
+	}
+
+	setCmd := "sets/" + namespace + "/" + set
+	deadline := time.Now().Add(policy.WaitTimeout)
+	for {
+		empty := true
+		for _, node := range clnt.cluster.GetNodes() {
+			info, err := node.RequestInfo(&policy.InfoPolicy, setCmd)
+			if err != nil {
+				return err
+			}
+
+			if objects, _ := strconv.ParseInt(parseInfoStats(info[setCmd], ":")["objects"], 10, 64); objects > 0 {
+				empty = false
+				break
+			}
+		}
+
+		if empty {
+			return nil
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			return newError(types.TIMEOUT, fmt.Sprintf("set `%s.%s` still has records after %s", namespace, set, policy.WaitTimeout))
+		}
+		time.Sleep(interval)
+	}
+}
+
+// DropSetIndexes drops all the secondary indexes of the set, and returns their names.
+// It blocks until the indexes are dropped on all the nodes.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) DropSetIndexes(policy *WritePolicy, namespace, set string) ([]string, Error) {
+	policy = clnt.getUsableWritePolicy(policy)
+
+	strCmd := "sindex-list:ns=" + namespace
+	responseMap, err := clnt.sendInfoCommand(policy.TotalTimeout, strCmd)
+	if err != nil {
+		return nil, err
+	}
+
+	names := setIndexNames(responseMap[strCmd], set)
+	for i, name := range names {
+		if err := clnt.DropIndex(policy, namespace, set, name); err != nil {
+			return names[:i], err
+		}
+	}
+	return names, nil
+}
+
+// infoReplicationFactor returns the replication factor of the namespace statistics.
+func infoReplicationFactor(nsStats map[string]string) int64 {
+	rf, _ := strconv.ParseInt(nsStats["effective_replication_factor"], 10, 64)
+	if rf <= 0 {
+		rf, _ = strconv.ParseInt(nsStats["replication-factor"], 10, 64)
+	}
+	return rf
+}
+
+// mergeSetInfos adds the set statistics of a node, in the sets/<namespace> info format,
+// to the statistics of the sets.
+func mergeSetInfos(sets map[string]*SetInfo, namespace, response string) {
+	for _, entry := range strings.Split(strings.TrimSpace(response), ";") {
+		stats := parseInfoStats(entry, ":")
+		name, exists := stats["set"]
+		if !exists {
+			continue
+		}
+
+		si := sets[name]
+		if si == nil {
+			si = &SetInfo{Namespace: namespace, Name: name}
+			sets[name] = si
+		}
+
+		statInt := func(name string) int64 {
+			n, _ := strconv.ParseInt(stats[name], 10, 64)
+			return n
+		}
+
+		si.Objects += statInt("objects")
+		si.Tombstones += statInt("tombstones")
+		si.MemoryBytes += statInt("memory_data_bytes")
+		si.DeviceBytes += statInt("device_data_bytes")
+		si.DataBytes += statInt("data_used_bytes")
+
+		// the limits and the configuration are the same on all the nodes
+		si.Indexes = int(statInt("sindexes"))
+		si.StopWritesCount = statInt("stop-writes-count")
+		si.StopWritesSize = statInt("stop-writes-size")
+
+		// truncate_lut is in milliseconds since the Citrusleaf epoch
+		if lut := statInt("truncate_lut"); lut > 0 {
+			t := time.Unix(types.CITRUSLEAF_EPOCH, 0).Add(time.Duration(lut) * time.Millisecond)
+			if t.After(si.TruncateLastUpdate) {
+				si.TruncateLastUpdate = t
+			}
+		}
+	}
+}
+
+// sortedSetInfos returns the sets sorted by name, with the number of records
+// counted by the nodes divided by the replication factor.
+func sortedSetInfos(sets map[string]*SetInfo, replicationFactor int64) []*SetInfo {
+	res := make([]*SetInfo, 0, len(sets))
+	for _, si := range sets {
+		si.Objects /= replicationFactor
+		si.Tombstones /= replicationFactor
+		res = append(res, si)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// setIndexNames returns the names of the indexes of the set, in the sindex-list info format.
+func setIndexNames(response, set string) []string {
+	var res []string
+	for _, entry := range strings.Split(strings.TrimSpace(response), ";") {
+		stats := parseInfoStats(entry, ":")
+		name, exists := stats["indexname"]
+		if !exists {
+			continue
+		}
+
+		indexSet := stats["set"]
+		if indexSet == "NULL" {
+			indexSet = ""
+		}
+
+		if indexSet == set {
+			res = append(res, name)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
@@ -0,0 +1,79 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Set administration", func() {
+
+	gg.It("must aggregate the set statistics of the nodes", func() {
+		node1 := "ns=test:set=users:objects=10:tombstones=2:memory_data_bytes=100:device_data_bytes=1000:sindexes=2:stop-writes-count=0:stop-writes-size=0:truncate_lut=0;" +
+			"ns=test:set=events:objects=4:data_used_bytes=64:sindexes=0:stop-writes-count=500:truncate_lut=1000;"
+		node2 := "ns=test:set=users:objects=12:tombstones=0:memory_data_bytes=120:device_data_bytes=1200:sindexes=2:stop-writes-count=0:stop-writes-size=0:truncate_lut=0;" +
+			"ns=test:set=events:objects=4:data_used_bytes=60:sindexes=0:stop-writes-count=500:truncate_lut=2000"
+
+		sets := map[string]*SetInfo{}
+		mergeSetInfos(sets, "test", node1)
+		mergeSetInfos(sets, "test", node2)
+		mergeSetInfos(sets, "test", "")
+
+		res := sortedSetInfos(sets, 2)
+		gm.Expect(res).To(gm.Equal([]*SetInfo{
+			{
+				Namespace:          "test",
+				Name:               "events",
+				Objects:            4,
+				DataBytes:          124,
+				StopWritesCount:    500,
+				TruncateLastUpdate: time.Unix(types.CITRUSLEAF_EPOCH, 0).Add(2 * time.Second),
+			},
+			{
+				Namespace:   "test",
+				Name:        "users",
+				Objects:     11,
+				Tombstones:  1,
+				MemoryBytes: 220,
+				DeviceBytes: 2200,
+				Indexes:     2,
+			},
+		}))
+	})
+
+	gg.It("must find the indexes of a set", func() {
+		response := "ns=test:indexname=idx_b:set=users:bin=b:type=numeric:indextype=default:context=NULL:state=RW;" +
+			"ns=test:indexname=idx_a:set=users:bin=a:type=string:indextype=list:context=NULL:state=RW;" +
+			"ns=test:indexname=idx_c:set=events:bin=c:type=numeric:indextype=default:context=NULL:state=RW;" +
+			"ns=test:indexname=idx_ns:set=NULL:bin=d:type=numeric:indextype=default:context=NULL:state=RW"
+
+		gm.Expect(setIndexNames(response, "users")).To(gm.Equal([]string{"idx_a", "idx_b"}))
+		gm.Expect(setIndexNames(response, "events")).To(gm.Equal([]string{"idx_c"}))
+		gm.Expect(setIndexNames(response, "")).To(gm.Equal([]string{"idx_ns"}))
+		gm.Expect(setIndexNames(response, "missing")).To(gm.BeEmpty())
+		gm.Expect(setIndexNames("", "users")).To(gm.BeEmpty())
+	})
+
+	gg.It("must report the replication factor of the namespace", func() {
+		gm.Expect(infoReplicationFactor(map[string]string{"effective_replication_factor": "2", "replication-factor": "3"})).To(gm.Equal(int64(2)))
+		gm.Expect(infoReplicationFactor(map[string]string{"replication-factor": "3"})).To(gm.Equal(int64(3)))
+		gm.Expect(infoReplicationFactor(map[string]string{})).To(gm.Equal(int64(0)))
+	})
+})
@@ -15,6 +15,8 @@
 package aerospike
 
 import (
+	"time"
+
 	Buffer "github.com/aerospike/aerospike-client-go/v7/utils/buffer"
 )
 
@@ -35,12 +37,34 @@ func newSingleCommand(cluster *Cluster, key *Key, partition *Partition) singleCo
 	}
 }
 
-func (cmd *singleCommand) getConnection(policy Policy) (*Connection, Error) {
-	return cmd.node.getConnectionWithHint(policy.GetBasePolicy().deadline(), policy.GetBasePolicy().socketTimeout(), cmd.key.digest[0])
+func (cmd *singleCommand) commandKey() *Key {
+	return cmd.key
+}
+
+func (cmd *singleCommand) clock() Clock {
+	if cmd.cluster == nil {
+		return SystemClock
+	}
+	return cmd.cluster.clock()
+}
+
+func (cmd *singleCommand) getConnection(policy Policy, deadline time.Time) (*Connection, Error) {
+	bp := policy.GetBasePolicy()
+	timeout := bp.socketTimeout()
+	if bp.AdaptiveTimeout != nil {
+		timeout = bp.AdaptiveTimeout.socketTimeout(&cmd.node.latencies, timeout)
+	}
+	if cmd.node.mux != nil {
+		if !cmd.node.active.Get() {
+			return nil, ErrServerNotAvailable.err()
+		}
+		return cmd.node.mux.connection(deadline, timeout), nil
+	}
+	return cmd.node.getConnectionWithPolicy(bp, deadline, timeout, cmd.node.connections.shard())
 }
 
 func (cmd *singleCommand) putConnection(conn *Connection) {
-	cmd.node.putConnectionWithHint(conn, cmd.key.digest[0])
+	cmd.node.putConnectionWithHint(conn, cmd.node.connections.shard())
 }
 
 func (cmd *singleCommand) emptySocket(conn *Connection) Error {
@@ -34,8 +34,15 @@ type Statement struct {
 	IndexName string
 
 	// BinNames determines bin names (optional)
+	// Bins the server returns that are not in the list are dropped by the client.
 	BinNames []string
 
+	// ExcludeBins determines the bins to leave out of the returned records (optional).
+	// The server does not support negative projections, so all the other bins are still
+	// read and sent over the wire; the excluded bins are dropped by the client while
+	// parsing, before their values are decoded.
+	ExcludeBins []string
+
 	// Filter determines query index filter (Optional).
 	// This filter is applied to the secondary index on query.
 	// Query index filters must reference a bin which has a secondary index defined.
@@ -51,6 +58,9 @@ type Statement struct {
 
 	// determines if the query should return data
 	ReturnData bool
+
+	// binProjection shared by the statements of a PreparedQuery
+	preparedProjection *binProjection
 }
 
 // NewStatement initializes a new Statement instance.
@@ -65,11 +75,12 @@ func NewStatement(ns string, set string, binNames ...string) *Statement {
 }
 
 func (stmt *Statement) String() string {
-	return fmt.Sprintf("Statement: {Namespace: %s, set: %s, IndexName: %s, BinNames: %v, Filter: %s, UDF: %s.%s(%v), TaskId: %d, return data: %v}",
+	return fmt.Sprintf("Statement: {Namespace: %s, set: %s, IndexName: %s, BinNames: %v, ExcludeBins: %v, Filter: %s, UDF: %s.%s(%v), TaskId: %d, return data: %v}",
 		stmt.Namespace,
 		stmt.SetName,
 		stmt.IndexName,
 		stmt.BinNames,
+		stmt.ExcludeBins,
 		stmt.Filter,
 		stmt.packageName,
 		stmt.functionName,
@@ -109,6 +120,56 @@ func (stmt *Statement) terminationError() types.ResultCode {
 	return types.QUERY_TERMINATED
 }
 
+// projection returns the client-side bin filter of the statement,
+// or nil if all the bins the server returns are to be kept.
+func (stmt *Statement) projection() *binProjection {
+	if stmt.preparedProjection != nil {
+		return stmt.preparedProjection
+	}
+	if len(stmt.BinNames) == 0 && len(stmt.ExcludeBins) == 0 {
+		return nil
+	}
+	return newBinProjection(stmt.BinNames, stmt.ExcludeBins)
+}
+
+// binProjection keeps the requested bins of a record and drops the excluded ones.
+type binProjection struct {
+	include map[string]struct{}
+	exclude map[string]struct{}
+}
+
+func newBinProjection(include, exclude []string) *binProjection {
+	toSet := func(names []string) map[string]struct{} {
+		if len(names) == 0 {
+			return nil
+		}
+		res := make(map[string]struct{}, len(names))
+		for _, name := range names {
+			res[name] = struct{}{}
+		}
+		return res
+	}
+
+	return &binProjection{
+		include: toSet(include),
+		exclude: toSet(exclude),
+	}
+}
+
+// allows returns true if the bin is to be kept in the record.
+func (bp *binProjection) allows(name string) bool {
+	if bp == nil {
+		return true
+	}
+	if bp.include != nil {
+		if _, exists := bp.include[name]; !exists {
+			return false
+		}
+	}
+	_, excluded := bp.exclude[name]
+	return !excluded
+}
+
 // Always set the taskID client-side to a non-zero random value
 func (stmt *Statement) prepare(returnData bool) {
 	stmt.ReturnData = returnData
@@ -33,6 +33,9 @@ type Task interface {
 type baseTask struct {
 	retries atomic.Int
 	cluster *Cluster
+
+	// done is set for tasks of commands that completed synchronously
+	done bool
 }
 
 // newTask initializes task with fields needed to query server nodes.
@@ -42,10 +45,21 @@ func newTask(cluster *Cluster) *baseTask {
 	}
 }
 
+// newCompletedTask initializes a task for a command that has already completed.
+func newCompletedTask() *baseTask {
+	return &baseTask{done: true}
+}
+
 // Wait for asynchronous task to complete using default sleep interval.
 func (btsk *baseTask) onComplete(ifc Task) chan Error {
 	ch := make(chan Error, 1)
 
+	if btsk.done {
+		ch <- nil
+		close(ch)
+		return ch
+	}
+
 	// goroutine will loop every <interval> until IsDone() returns true or error
 	go func() {
 		// always close the channel on return
@@ -33,6 +33,10 @@ func NewDropIndexTask(cluster *Cluster, namespace string, indexName string) *Dro
 
 // IsDone queries all nodes for task completion status.
 func (tski *DropIndexTask) IsDone() (bool, Error) {
+	if tski.done {
+		return true, nil
+	}
+
 	command := "sindex-exists:ns=" + tski.namespace + ";indexname=" + tski.indexName
 	nodes := tski.cluster.GetNodes()
 	complete := false
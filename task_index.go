@@ -39,6 +39,10 @@ func NewIndexTask(cluster *Cluster, namespace string, indexName string) *IndexTa
 
 // IsDone queries all nodes for task completion status.
 func (tski *IndexTask) IsDone() (bool, Error) {
+	if tski.done {
+		return true, nil
+	}
+
 	command := "sindex/" + tski.namespace + "/" + tski.indexName
 	nodes := tski.cluster.GetNodes()
 	complete := false
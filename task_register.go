@@ -22,19 +22,31 @@ import (
 type RegisterTask struct {
 	*baseTask
 
-	packageName string
+	// packages maps the registered package names to their expected hash.
+	// An empty hash is not verified.
+	packages map[string]string
 }
 
 // NewRegisterTask initializes a RegisterTask with fields needed to query server nodes.
 func NewRegisterTask(cluster *Cluster, packageName string) *RegisterTask {
+	return newRegisterTask(cluster, map[string]string{packageName: ""})
+}
+
+func newRegisterTask(cluster *Cluster, packages map[string]string) *RegisterTask {
 	return &RegisterTask{
-		baseTask:    newTask(cluster),
-		packageName: packageName,
+		baseTask: newTask(cluster),
+		packages: packages,
 	}
 }
 
 // IsDone will query all nodes for task completion status.
+// The task is done when all the packages are registered on all the nodes,
+// with the hash of the registered content if known.
 func (tskr *RegisterTask) IsDone() (bool, Error) {
+	if tskr.done {
+		return true, nil
+	}
+
 	command := "udf-list"
 	nodes := tskr.cluster.GetNodes()
 	done := false
@@ -46,11 +58,16 @@ func (tskr *RegisterTask) IsDone() (bool, Error) {
 		}
 
 		for _, response := range responseMap {
-			find := "filename=" + tskr.packageName
-			index := strings.Index(response, find)
+			registered := make(map[string]string, len(tskr.packages))
+			for _, udf := range parseUDFList(response) {
+				registered[udf.Filename] = udf.Hash
+			}
 
-			if index < 0 {
-				return false, nil
+			for packageName, hash := range tskr.packages {
+				h, exists := registered[packageName]
+				if !exists || (hash != "" && !strings.EqualFold(h, hash)) {
+					return false, nil
+				}
 			}
 			done = true
 		}
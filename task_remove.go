@@ -35,6 +35,10 @@ func NewRemoveTask(cluster *Cluster, packageName string) *RemoveTask {
 
 // IsDone will query all nodes for task completion status.
 func (tskr *RemoveTask) IsDone() (bool, Error) {
+	if tskr.done {
+		return true, nil
+	}
+
 	command := "udf-list"
 	nodes := tskr.cluster.GetNodes()
 	done := false
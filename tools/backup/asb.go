@@ -0,0 +1,578 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+	"github.com/aerospike/aerospike-client-go/v7/types"
+	ParticleType "github.com/aerospike/aerospike-client-go/v7/types/particle_type"
+)
+
+// asbVersion is the version of the backup file format written by the Encoder.
+const asbVersion = "3.1"
+
+// Record is a record read from a backup file.
+type Record struct {
+	// Key is the key of the record. The user key value is only set if it was stored on the server.
+	Key *as.Key
+
+	// Generation is the generation of the record at the time of the backup.
+	Generation uint32
+
+	// VoidTime is the expiration time of the record in seconds since the Citrusleaf epoch (2010-01-01).
+	// 0 means the record never expires.
+	VoidTime uint32
+
+	// Bins are the bins of the record. Lists and maps are kept as *as.RawBlobValue.
+	Bins as.BinMap
+}
+
+// TTL returns the remaining time to live of the record, relative to now.
+// Returns false if the record has already expired.
+func (r *Record) TTL() (uint32, bool) {
+	if r.VoidTime == 0 {
+		return as.TTLDontExpire, true
+	}
+
+	ttl := int64(types.CITRUSLEAF_EPOCH) + int64(r.VoidTime) - time.Now().Unix()
+	if ttl <= 0 {
+		return 0, false
+	}
+	return uint32(ttl), true
+}
+
+// Encoder writes records to an io.Writer in the Aerospike backup (.asb) text format.
+// Encoder is not safe for concurrent use.
+type Encoder struct {
+	w         *bufio.Writer
+	namespace string
+}
+
+// NewEncoder creates an Encoder and writes the file header for the namespace.
+func NewEncoder(w io.Writer, namespace string) (*Encoder, error) {
+	e := newEncoder(w, namespace)
+	e.w.WriteString("Version " + asbVersion + "\n")
+	e.w.WriteString("# namespace " + escape(namespace) + "\n")
+	e.w.WriteString("# first-file\n")
+	return e, e.w.Flush()
+}
+
+func newEncoder(w io.Writer, namespace string) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w), namespace: namespace}
+}
+
+// Flush writes the buffered data to the underlying writer.
+func (e *Encoder) Flush() error {
+	return e.w.Flush()
+}
+
+// Encode writes the record. Records must be read with ScanPolicy.RawCDT set,
+// so that lists and maps are written without being decoded.
+func (e *Encoder) Encode(rec *as.Record) error {
+	if rec.Key == nil {
+		return errors.New("record without key")
+	}
+
+	if hasUserKey(rec.Key) {
+		if err := e.encodeUserKey(rec.Key.Value()); err != nil {
+			return err
+		}
+	}
+
+	e.w.WriteString("+ n " + escape(rec.Key.Namespace()) + "\n")
+	e.w.WriteString("+ d " + base64.StdEncoding.EncodeToString(rec.Key.Digest()) + "\n")
+	if set := rec.Key.SetName(); set != "" {
+		e.w.WriteString("+ s " + escape(set) + "\n")
+	}
+	e.w.WriteString("+ g " + strconv.FormatUint(uint64(rec.Generation), 10) + "\n")
+	e.w.WriteString("+ t " + strconv.FormatUint(uint64(voidTime(rec.Expiration)), 10) + "\n")
+	e.w.WriteString("+ b " + strconv.Itoa(len(rec.Bins)) + "\n")
+
+	// sort the bins for a deterministic output
+	names := make([]string, 0, len(rec.Bins))
+	for name := range rec.Bins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := e.encodeBin(name, rec.Bins[name]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *Encoder) encodeUserKey(v as.Value) error {
+	switch v.GetType() {
+	case ParticleType.INTEGER:
+		e.w.WriteString(fmt.Sprintf("+ k I %d\n", v.GetObject()))
+	case ParticleType.FLOAT:
+		e.w.WriteString("+ k D " + strconv.FormatFloat(v.GetObject().(float64), 'g', -1, 64) + "\n")
+	case ParticleType.STRING:
+		s := v.GetObject().(string)
+		e.w.WriteString("+ k S " + strconv.Itoa(len(s)) + " " + s + "\n")
+	case ParticleType.BLOB:
+		b := base64.StdEncoding.EncodeToString(v.GetObject().([]byte))
+		e.w.WriteString("+ k B " + strconv.Itoa(len(b)) + " " + b + "\n")
+	default:
+		return fmt.Errorf("unsupported key type %d", v.GetType())
+	}
+	return nil
+}
+
+func (e *Encoder) encodeBin(name string, value interface{}) error {
+	name = escape(name)
+	switch v := value.(type) {
+	case nil:
+		e.w.WriteString("- N " + name + "\n")
+	case bool:
+		b := "F"
+		if v {
+			b = "T"
+		}
+		e.w.WriteString("- Z " + name + " " + b + "\n")
+	case int:
+		e.w.WriteString("- I " + name + " " + strconv.Itoa(v) + "\n")
+	case int64:
+		e.w.WriteString("- I " + name + " " + strconv.FormatInt(v, 10) + "\n")
+	case float64:
+		e.w.WriteString("- D " + name + " " + strconv.FormatFloat(v, 'g', -1, 64) + "\n")
+	case string:
+		e.w.WriteString("- S " + name + " " + strconv.Itoa(len(v)) + " " + v + "\n")
+	case as.GeoJSONValue:
+		e.w.WriteString("- G " + name + " " + strconv.Itoa(len(v)) + " " + string(v) + "\n")
+	case []byte:
+		e.writeBase64("B", name, v)
+	case as.HLLValue:
+		e.writeBase64("Y", name, v)
+	case *as.RawBlobValue:
+		switch v.ParticleType {
+		case ParticleType.LIST:
+			e.writeBase64("L", name, v.Data)
+		case ParticleType.MAP:
+			e.writeBase64("M", name, v.Data)
+		default:
+			return fmt.Errorf("unsupported raw particle type %d for bin `%s`", v.ParticleType, name)
+		}
+	default:
+		return fmt.Errorf("unsupported value type %T for bin `%s`. Lists and maps require ScanPolicy.RawCDT", value, name)
+	}
+	return nil
+}
+
+func (e *Encoder) writeBase64(typ, name string, b []byte) {
+	s := base64.StdEncoding.EncodeToString(b)
+	e.w.WriteString("- " + typ + " " + name + " " + strconv.Itoa(len(s)) + " " + s + "\n")
+}
+
+// hasUserKey returns true if the key has a user key value, and not only the digest.
+func hasUserKey(key *as.Key) bool {
+	v := key.Value()
+	return v != nil && v.GetType() != ParticleType.NULL
+}
+
+// voidTime converts a TTL to the expiration time since the Citrusleaf epoch.
+func voidTime(ttl uint32) uint32 {
+	if ttl == math.MaxUint32 || ttl == 0 {
+		return 0
+	}
+	return uint32(time.Now().Unix() - types.CITRUSLEAF_EPOCH + int64(ttl))
+}
+
+// Decoder reads records from an io.Reader in the Aerospike backup (.asb) text format.
+// Global metadata (UDFs and secondary indexes) are skipped.
+// Decoder is not safe for concurrent use.
+type Decoder struct {
+	r         *bufio.Reader
+	line      int
+	namespace string
+}
+
+// NewDecoder creates a Decoder and reads the file header.
+func NewDecoder(r io.Reader) (*Decoder, error) {
+	d := &Decoder{r: bufio.NewReader(r), line: 1}
+
+	header, err := d.readLine()
+	if err != nil {
+		return nil, d.errorf("failed to read the header: %v", err)
+	}
+
+	if !bytes.HasPrefix(header, []byte("Version ")) {
+		return nil, d.errorf("invalid header `%s`", header)
+	}
+
+	if v := string(header[len("Version "):]); v != "3.0" && v != "3.1" {
+		return nil, d.errorf("unsupported version %s", v)
+	}
+
+	return d, nil
+}
+
+// Namespace returns the namespace declared in the metadata of the file, if already read.
+func (d *Decoder) Namespace() string {
+	return d.namespace
+}
+
+// Decode reads the next record. Returns io.EOF when there are no more records.
+func (d *Decoder) Decode() (*Record, error) {
+	for {
+		b, err := d.r.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+
+		switch b[0] {
+		case '#':
+			line, err := d.readLine()
+			if err != nil {
+				return nil, err
+			}
+			if ns, ok := bytes.CutPrefix(line, []byte("# namespace ")); ok {
+				d.namespace = unescape(string(ns))
+			}
+		case '*':
+			// global section: udfs and secondary indexes
+			if _, err := d.readLine(); err != nil {
+				return nil, err
+			}
+		case '+':
+			return d.decodeRecord()
+		default:
+			return nil, d.errorf("unexpected character `%c`", b[0])
+		}
+	}
+}
+
+func (d *Decoder) decodeRecord() (*Record, error) {
+	var (
+		userKey   interface{}
+		namespace string
+		digest    []byte
+		set       string
+		rec       Record
+		binCount  = -1
+	)
+
+	for binCount < 0 {
+		if err := d.expect("+ "); err != nil {
+			return nil, err
+		}
+
+		field, err := d.readToken()
+		if err != nil {
+			return nil, err
+		}
+
+		switch field {
+		case "k":
+			if userKey, err = d.decodeValue(); err != nil {
+				return nil, err
+			}
+			continue
+		case "n":
+			namespace, err = d.readEscapedLine()
+		case "d":
+			var line []byte
+			if line, err = d.readLine(); err == nil {
+				digest, err = base64.StdEncoding.DecodeString(string(line))
+			}
+		case "s":
+			set, err = d.readEscapedLine()
+		case "g":
+			var v uint64
+			v, err = d.readUint()
+			rec.Generation = uint32(v)
+		case "t":
+			var v uint64
+			v, err = d.readUint()
+			rec.VoidTime = uint32(v)
+		case "b":
+			var v uint64
+			v, err = d.readUint()
+			binCount = int(v)
+		default:
+			return nil, d.errorf("unknown record field `%s`", field)
+		}
+
+		if err != nil {
+			return nil, d.errorf("invalid record field `%s`: %v", field, err)
+		}
+	}
+
+	key, err := as.NewKeyWithDigest(namespace, set, userKey, digest)
+	if err != nil {
+		return nil, d.errorf("invalid key: %v", err)
+	}
+	rec.Key = key
+
+	rec.Bins = make(as.BinMap, binCount)
+	for i := 0; i < binCount; i++ {
+		if err := d.expect("- "); err != nil {
+			return nil, err
+		}
+
+		name, value, err := d.decodeBin()
+		if err != nil {
+			return nil, err
+		}
+		rec.Bins[name] = value
+	}
+
+	return &rec, nil
+}
+
+func (d *Decoder) decodeBin() (string, interface{}, error) {
+	typ, err := d.readToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	name, err := d.readToken()
+	if err != nil {
+		return "", nil, err
+	}
+	name = unescape(name)
+
+	var value interface{}
+	switch typ {
+	case "N":
+		err = d.expect("\n")
+	case "Z":
+		var line []byte
+		if line, err = d.readLine(); err == nil {
+			value = string(line) == "T"
+		}
+	case "G":
+		var b []byte
+		if b, err = d.readSized(); err == nil {
+			value = as.GeoJSONValue(b)
+		}
+	case "Y":
+		var b []byte
+		if b, err = d.readBase64(); err == nil {
+			value = as.HLLValue(b)
+		}
+	case "L", "M":
+		var b []byte
+		if b, err = d.readBase64(); err == nil {
+			pt := ParticleType.LIST
+			if typ == "M" {
+				pt = ParticleType.MAP
+			}
+			value = as.NewRawBlobValue(pt, b)
+		}
+	default:
+		value, err = d.decodeTypedValue(typ)
+	}
+
+	if err != nil {
+		return "", nil, d.errorf("invalid bin `%s`: %v", name, err)
+	}
+	return name, value, nil
+}
+
+// decodeValue decodes a `<type> <value>` pair, used for keys and for the scalar bins.
+func (d *Decoder) decodeValue() (interface{}, error) {
+	typ, err := d.readToken()
+	if err != nil {
+		return nil, err
+	}
+	return d.decodeTypedValue(typ)
+}
+
+func (d *Decoder) decodeTypedValue(typ string) (interface{}, error) {
+	switch typ {
+	case "I":
+		line, err := d.readLine()
+		if err != nil {
+			return nil, err
+		}
+		v, err := strconv.ParseInt(string(line), 10, 64)
+		return int(v), err
+	case "D":
+		line, err := d.readLine()
+		if err != nil {
+			return nil, err
+		}
+		return strconv.ParseFloat(string(line), 64)
+	case "S":
+		b, err := d.readSized()
+		return string(b), err
+	case "B":
+		return d.readBase64()
+	}
+	return nil, d.errorf("unsupported value type `%s`", typ)
+}
+
+// readSized reads a `<length> <data>\n` value.
+func (d *Decoder) readSized() ([]byte, error) {
+	token, err := d.readToken()
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.Atoi(token)
+	if err != nil || n < 0 {
+		return nil, d.errorf("invalid length `%s`", token)
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		return nil, err
+	}
+	d.line += bytes.Count(b, []byte{'\n'})
+
+	return b, d.expect("\n")
+}
+
+func (d *Decoder) readBase64() ([]byte, error) {
+	b, err := d.readSized()
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(string(b))
+}
+
+func (d *Decoder) readUint() (uint64, error) {
+	line, err := d.readLine()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(string(line), 10, 64)
+}
+
+// readToken reads up to the next space, and consumes the space.
+func (d *Decoder) readToken() (string, error) {
+	var token []byte
+	for {
+		c, err := d.r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+
+		switch c {
+		case ' ':
+			return string(token), nil
+		case '\n':
+			d.r.UnreadByte()
+			return string(token), nil
+		case '\\':
+			// keep the escape sequence for unescape
+			next, err := d.r.ReadByte()
+			if err != nil {
+				return "", err
+			}
+			token = append(token, c, next)
+		default:
+			token = append(token, c)
+		}
+	}
+}
+
+func (d *Decoder) readEscapedLine() (string, error) {
+	line, err := d.readLine()
+	return unescape(string(line)), err
+}
+
+func (d *Decoder) readLine() ([]byte, error) {
+	line, err := d.r.ReadBytes('\n')
+	if err != nil {
+		if err == io.EOF && len(line) > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	d.line++
+	return line[:len(line)-1], nil
+}
+
+func (d *Decoder) expect(s string) error {
+	for i := 0; i < len(s); i++ {
+		c, err := d.r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		if c != s[i] {
+			return d.errorf("expected `%q`, found `%q`", s[i], c)
+		}
+		if c == '\n' {
+			d.line++
+		}
+	}
+	return nil
+}
+
+func (d *Decoder) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", d.line, fmt.Sprintf(format, args...))
+}
+
+// escape escapes the spaces, newlines and backslashes in names.
+func escape(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case ' ', '\n', '\\':
+			if b == nil {
+				b = append(make([]byte, 0, len(s)+4), s[:i]...)
+			}
+			b = append(b, '\\', s[i])
+		default:
+			if b != nil {
+				b = append(b, s[i])
+			}
+		}
+	}
+
+	if b == nil {
+		return s
+	}
+	return string(b)
+}
+
+func unescape(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			if b == nil {
+				b = append(make([]byte, 0, len(s)), s[:i]...)
+			}
+			i++
+			b = append(b, s[i])
+		} else if b != nil {
+			b = append(b, s[i])
+		}
+	}
+
+	if b == nil {
+		return s
+	}
+	return string(b)
+}
@@ -0,0 +1,101 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+	"github.com/aerospike/aerospike-client-go/v7/tools/backup"
+	ParticleType "github.com/aerospike/aerospike-client-go/v7/types/particle_type"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("ASB format", func() {
+
+	gg.It("must encode and decode records", func() {
+		key1, _ := as.NewKey("test", "my set", "key 1\nwith newline")
+		key2, _ := as.NewKey("test", "", 42)
+		key3, _ := as.NewKeyWithDigest("test", "set", nil, key2.Digest())
+
+		bins := as.BinMap{
+			"int":      7,
+			"float":    1.5,
+			"str":      "hello world\n",
+			"bool":     true,
+			"blob":     []byte{0, 1, 2, 255},
+			"geo":      as.GeoJSONValue(`{"type":"Point","coordinates":[1,2]}`),
+			"hll":      as.HLLValue([]byte{1, 2, 3}),
+			"list":     as.NewRawBlobValue(ParticleType.LIST, []byte{0x92, 0x01, 0x02}),
+			"map":      as.NewRawBlobValue(ParticleType.MAP, []byte{0x81, 0x01, 0x02}),
+			"bin name": "with space",
+		}
+
+		var buf bytes.Buffer
+		enc, err := backup.NewEncoder(&buf, "test")
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		gm.Expect(enc.Encode(&as.Record{Key: key1, Bins: bins, Generation: 3, Expiration: as.TTLDontExpire})).To(gm.Succeed())
+		gm.Expect(enc.Encode(&as.Record{Key: key2, Bins: as.BinMap{"i": -1}, Generation: 1, Expiration: 3600})).To(gm.Succeed())
+		gm.Expect(enc.Encode(&as.Record{Key: key3, Bins: as.BinMap{}, Generation: 1, Expiration: as.TTLDontExpire})).To(gm.Succeed())
+		gm.Expect(enc.Flush()).To(gm.Succeed())
+
+		gm.Expect(strings.HasPrefix(buf.String(), "Version 3.1\n# namespace test\n# first-file\n")).To(gm.BeTrue())
+
+		dec, err := backup.NewDecoder(&buf)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		rec, err := dec.Decode()
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(dec.Namespace()).To(gm.Equal("test"))
+		gm.Expect(rec.Key.Equals(key1)).To(gm.BeTrue())
+		gm.Expect(rec.Key.Value().GetObject()).To(gm.Equal("key 1\nwith newline"))
+		gm.Expect(rec.Key.SetName()).To(gm.Equal("my set"))
+		gm.Expect(rec.Generation).To(gm.Equal(uint32(3)))
+		gm.Expect(rec.VoidTime).To(gm.Equal(uint32(0)))
+		gm.Expect(rec.Bins).To(gm.Equal(bins))
+
+		rec, err = dec.Decode()
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(rec.Key.Value().GetObject()).To(gm.Equal(42))
+		gm.Expect(rec.Bins).To(gm.Equal(as.BinMap{"i": -1}))
+		ttl, alive := rec.TTL()
+		gm.Expect(alive).To(gm.BeTrue())
+		gm.Expect(ttl).To(gm.BeNumerically("~", 3600, 2))
+
+		rec, err = dec.Decode()
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(rec.Key.Value().GetType()).To(gm.Equal(ParticleType.NULL))
+		gm.Expect(rec.Key.Digest()).To(gm.Equal(key2.Digest()))
+		gm.Expect(rec.Bins).To(gm.BeEmpty())
+
+		_, err = dec.Decode()
+		gm.Expect(err).To(gm.Equal(io.EOF))
+	})
+
+	gg.It("must reject invalid files", func() {
+		_, err := backup.NewDecoder(strings.NewReader("Version 2.0\n"))
+		gm.Expect(err).To(gm.HaveOccurred())
+
+		dec, err := backup.NewDecoder(strings.NewReader("Version 3.1\n+ n test\n+ x 1\n"))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		_, err = dec.Decode()
+		gm.Expect(err).To(gm.HaveOccurred())
+	})
+})
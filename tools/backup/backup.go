@@ -0,0 +1,266 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package backup implements backup and restore of Aerospike namespaces and sets
+// in the text format (.asb) used by the asbackup and asrestore tools.
+//
+// Backups are performed with paginated partition scans, and can be resumed
+// from a cursor reported after each page.
+package backup
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+)
+
+const partitionCount = 4096
+
+// BackupPolicy contains the attributes used for Backup.
+type BackupPolicy struct {
+	// ScanPolicy is the policy used for the partition scans.
+	// RawCDT is always set, so that lists and maps are backed up without being decoded.
+	// ScanPolicy.RecordsPerSecond can be used to throttle each scan on the server.
+	// If nil, a default ScanPolicy is used.
+	ScanPolicy *as.ScanPolicy
+
+	// Parallel is the number of partition ranges scanned concurrently.
+	//
+	// Default: 1
+	Parallel int // = 1
+
+	// PageSize is the maximum number of records each scan returns before the
+	// Checkpoint callback is invoked. 0 scans all the partitions of a range at once.
+	//
+	// Default: 10000
+	PageSize int64 // = 10000
+
+	// Checkpoint is called after each page has been written and flushed, with a cursor
+	// that can be passed to Backup to resume after the records already written.
+	// Checkpoint is never called concurrently.
+	Checkpoint func(cursor []byte)
+}
+
+// NewBackupPolicy creates a new BackupPolicy with default values.
+func NewBackupPolicy() *BackupPolicy {
+	return &BackupPolicy{
+		Parallel: 1,
+		PageSize: 10000,
+	}
+}
+
+// Stats contains the counters of a Backup or Restore.
+type Stats struct {
+	// Records is the number of records backed up or restored.
+	Records int64
+
+	// Skipped is the number of records that were read from the backup but not restored,
+	// because they had expired, or a newer version existed in the database.
+	Skipped int64
+}
+
+// Backup writes the records of the namespace and set to w in the .asb format.
+// An empty set backs up the whole namespace. To resume an interrupted backup, pass
+// the last cursor reported to BackupPolicy.Checkpoint; only the records after the
+// cursor are then written, without the file header.
+func Backup(client as.ClientIfc, policy *BackupPolicy, namespace, set string, w io.Writer, cursor []byte) (*Stats, error) {
+	if policy == nil {
+		policy = NewBackupPolicy()
+	}
+
+	scanPolicy := as.NewScanPolicy()
+	if policy.ScanPolicy != nil {
+		*scanPolicy = *policy.ScanPolicy
+	}
+	scanPolicy.RawCDT = true
+	scanPolicy.MaxRecords = policy.PageSize
+
+	statuses := make([]*as.PartitionStatus, partitionCount)
+	if cursor != nil {
+		pf := as.NewPartitionFilterAll()
+		if err := pf.DecodeCursor(cursor); err != nil {
+			return nil, err
+		}
+		for _, ps := range pf.Partitions {
+			if ps.Id >= 0 && ps.Id < partitionCount {
+				statuses[ps.Id] = ps
+			}
+		}
+	}
+
+	for i := range statuses {
+		if statuses[i] == nil {
+			statuses[i] = &as.PartitionStatus{Id: i, Retry: true}
+		}
+	}
+
+	// resumed backups are appended to the existing file, without a header
+	enc := newEncoder(w, namespace)
+	if cursor == nil {
+		var err error
+		if enc, err = NewEncoder(w, namespace); err != nil {
+			return nil, err
+		}
+	}
+
+	b := &backup{
+		client:     client,
+		policy:     policy,
+		scanPolicy: scanPolicy,
+		namespace:  namespace,
+		set:        set,
+		enc:        enc,
+		snapshot:   statuses,
+	}
+
+	parallel := policy.Parallel
+	if parallel <= 0 {
+		parallel = 1
+	} else if parallel > partitionCount {
+		parallel = partitionCount
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, parallel)
+	for i := 0; i < parallel; i++ {
+		begin := i * partitionCount / parallel
+		end := (i + 1) * partitionCount / parallel
+
+		// each worker owns its copy of the statuses, which are mutated by the scans
+		parts := make([]*as.PartitionStatus, 0, end-begin)
+		for _, ps := range statuses[begin:end] {
+			parts = append(parts, copyStatus(ps))
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = b.run(&as.PartitionFilter{Begin: begin, Count: end - begin, Partitions: parts})
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return b.stats(), err
+		}
+	}
+
+	return b.stats(), b.enc.Flush()
+}
+
+type backup struct {
+	client     as.ClientIfc
+	policy     *BackupPolicy
+	scanPolicy *as.ScanPolicy
+	namespace  string
+	set        string
+	records    int64
+
+	// guards enc and snapshot
+	m        sync.Mutex
+	enc      *Encoder
+	snapshot []*as.PartitionStatus
+}
+
+func (b *backup) stats() *Stats {
+	return &Stats{Records: atomic.LoadInt64(&b.records)}
+}
+
+// run scans the partitions in the filter page by page until they are all done.
+func (b *backup) run(pf *as.PartitionFilter) error {
+	for !pf.IsDone() {
+		if b.scanPolicy.MaxRecords > 0 && !pendingPartitions(pf.Partitions) {
+			// all the partitions were already done in the resumed cursor
+			return nil
+		}
+
+		rs, err := b.client.ScanPartitions(b.scanPolicy, pf, b.namespace, b.set)
+		if err != nil {
+			return err
+		}
+
+		for res := range rs.Results() {
+			if res.Err != nil {
+				rs.Close()
+				return res.Err
+			}
+
+			b.m.Lock()
+			err := b.enc.Encode(res.Record)
+			b.m.Unlock()
+			if err != nil {
+				rs.Close()
+				return err
+			}
+			atomic.AddInt64(&b.records, 1)
+		}
+
+		if err := b.checkpoint(pf.Partitions); err != nil {
+			return err
+		}
+
+		if b.scanPolicy.MaxRecords <= 0 {
+			return nil
+		}
+	}
+	return nil
+}
+
+// checkpoint flushes the written records, updates the snapshot of the partitions,
+// and reports the cursor of the whole backup.
+func (b *backup) checkpoint(parts []*as.PartitionStatus) error {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	if err := b.enc.Flush(); err != nil {
+		return err
+	}
+
+	for _, ps := range parts {
+		b.snapshot[ps.Id] = copyStatus(ps)
+	}
+
+	if b.policy.Checkpoint == nil {
+		return nil
+	}
+
+	pf := &as.PartitionFilter{Partitions: b.snapshot}
+	cursor, err := pf.EncodeCursor()
+	if err != nil {
+		return err
+	}
+
+	b.policy.Checkpoint(cursor)
+	return nil
+}
+
+func pendingPartitions(parts []*as.PartitionStatus) bool {
+	for _, ps := range parts {
+		if ps.Retry {
+			return true
+		}
+	}
+	return false
+}
+
+func copyStatus(ps *as.PartitionStatus) *as.PartitionStatus {
+	res := *ps
+	if ps.Digest != nil {
+		res.Digest = append([]byte(nil), ps.Digest...)
+	}
+	return &res
+}
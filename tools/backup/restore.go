@@ -0,0 +1,221 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// RestorePolicy contains the attributes used for Restore.
+type RestorePolicy struct {
+	// WritePolicy is the base policy used to write the records. The generation and
+	// expiration are set for each record. If nil, a default WritePolicy is used.
+	// Set RecordExistsAction to as.CREATE_ONLY to only restore the records missing in
+	// the database, or as.REPLACE to remove the bins not in the backup.
+	WritePolicy *as.WritePolicy
+
+	// IgnoreGeneration determines if the records are written regardless of their generation.
+	// Otherwise, records are only written if they do not exist in the database, or if their
+	// generation in the backup is greater than the one in the database, so that newer
+	// versions of the records are not overwritten.
+	//
+	// Default: false
+	IgnoreGeneration bool // = false
+
+	// Namespace overrides the namespace of the records in the backup if set.
+	Namespace string
+
+	// Parallel is the number of records written concurrently.
+	//
+	// Default: 8
+	Parallel int // = 8
+
+	// RecordsPerSecond limits the number of records written per second. 0 means no limit.
+	//
+	// Default: 0
+	RecordsPerSecond int // = 0
+}
+
+// NewRestorePolicy creates a new RestorePolicy with default values.
+func NewRestorePolicy() *RestorePolicy {
+	return &RestorePolicy{
+		Parallel: 8,
+	}
+}
+
+// Restore reads the records in the .asb format from r and writes them to the database.
+// Expired records are skipped. Restore stops at the first error.
+func Restore(client as.ClientIfc, policy *RestorePolicy, r io.Reader) (*Stats, error) {
+	if policy == nil {
+		policy = NewRestorePolicy()
+	}
+
+	dec, err := NewDecoder(r)
+	if err != nil {
+		return nil, err
+	}
+
+	parallel := policy.Parallel
+	if parallel <= 0 {
+		parallel = 1
+	}
+
+	var limiter *throttle
+	if policy.RecordsPerSecond > 0 {
+		limiter = newThrottle(policy.RecordsPerSecond)
+	}
+
+	var (
+		stats    Stats
+		wg       sync.WaitGroup
+		errLock  sync.Mutex
+		firstErr error
+		failed   atomic.Bool
+	)
+
+	records := make(chan *Record, parallel)
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rec := range records {
+				if failed.Load() {
+					continue
+				}
+
+				limiter.wait()
+				written, err := restoreRecord(client, policy, rec)
+				if err != nil {
+					errLock.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					errLock.Unlock()
+					failed.Store(true)
+					continue
+				}
+
+				if written {
+					atomic.AddInt64(&stats.Records, 1)
+				} else {
+					atomic.AddInt64(&stats.Skipped, 1)
+				}
+			}
+		}()
+	}
+
+	for !failed.Load() {
+		rec, err := dec.Decode()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			errLock.Lock()
+			if firstErr == nil {
+				firstErr = err
+			}
+			errLock.Unlock()
+			break
+		}
+		records <- rec
+	}
+	close(records)
+	wg.Wait()
+
+	return &stats, firstErr
+}
+
+// restoreRecord writes the record. Returns false if the record was skipped.
+func restoreRecord(client as.ClientIfc, policy *RestorePolicy, rec *Record) (bool, error) {
+	ttl, alive := rec.TTL()
+	if !alive {
+		return false, nil
+	}
+
+	wp := as.NewWritePolicy(0, ttl)
+	if policy.WritePolicy != nil {
+		*wp = *policy.WritePolicy
+		wp.Expiration = ttl
+	}
+
+	if !policy.IgnoreGeneration {
+		wp.GenerationPolicy = as.EXPECT_GEN_GT
+		wp.Generation = rec.Generation
+	}
+
+	key := rec.Key
+	if policy.Namespace != "" && policy.Namespace != key.Namespace() {
+		var userKey interface{}
+		if hasUserKey(key) {
+			userKey = key.Value().GetObject()
+		}
+
+		var err as.Error
+		if key, err = as.NewKeyWithDigest(policy.Namespace, key.SetName(), userKey, key.Digest()); err != nil {
+			return false, err
+		}
+	}
+
+	// keep the user key in the database if it was stored
+	wp.SendKey = wp.SendKey || hasUserKey(key)
+
+	if err := client.Put(wp, key, rec.Bins); err != nil {
+		switch {
+		case !policy.IgnoreGeneration && err.Matches(types.GENERATION_ERROR):
+			// a newer version of the record exists
+			return false, nil
+		case wp.RecordExistsAction == as.CREATE_ONLY && err.Matches(types.KEY_EXISTS_ERROR):
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// throttle limits the rate of the operations by spacing them evenly.
+type throttle struct {
+	m        sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newThrottle(perSecond int) *throttle {
+	return &throttle{interval: time.Second / time.Duration(perSecond)}
+}
+
+// wait blocks until the next operation is allowed. A nil throttle never blocks.
+func (t *throttle) wait() {
+	if t == nil {
+		return
+	}
+
+	t.m.Lock()
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	d := t.next.Sub(now)
+	t.next = t.next.Add(t.interval)
+	t.m.Unlock()
+
+	if d > 0 {
+		time.Sleep(d)
+	}
+}
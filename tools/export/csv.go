@@ -0,0 +1,110 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// CSVWriter writes rows as CSV, with a header line of the column names.
+// Floats are written in their shortest representation, bytes are base64 encoded,
+// and the missing values are empty.
+type CSVWriter struct {
+	w      *csv.Writer
+	closer io.Closer
+	record []string
+}
+
+var _ RowWriter = (*CSVWriter)(nil)
+
+// NewCSVWriter creates a CSVWriter for the schema, and writes the header line to w.
+func NewCSVWriter(w io.Writer, schema *Schema) (*CSVWriter, error) {
+	cw := &CSVWriter{
+		w:      csv.NewWriter(w),
+		record: make([]string, len(schema.Columns)),
+	}
+
+	for i := range schema.Columns {
+		cw.record[i] = schema.Columns[i].Name
+	}
+	if err := cw.w.Write(cw.record); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+// WriteRow implements the RowWriter interface.
+func (cw *CSVWriter) WriteRow(row []interface{}) error {
+	if len(row) != len(cw.record) {
+		return fmt.Errorf("row has %d values, expected %d", len(row), len(cw.record))
+	}
+
+	for i, v := range row {
+		switch v := v.(type) {
+		case nil:
+			cw.record[i] = ""
+		case int64:
+			cw.record[i] = strconv.FormatInt(v, 10)
+		case float64:
+			cw.record[i] = strconv.FormatFloat(v, 'g', -1, 64)
+		case string:
+			cw.record[i] = v
+		case bool:
+			cw.record[i] = strconv.FormatBool(v)
+		case []byte:
+			cw.record[i] = base64.StdEncoding.EncodeToString(v)
+		default:
+			return fmt.Errorf("unsupported value type %T", v)
+		}
+	}
+	return cw.w.Write(cw.record)
+}
+
+// Close flushes the buffered rows. The underlying writer is only closed
+// if the CSVWriter was created by CSVFiles.
+func (cw *CSVWriter) Close() error {
+	cw.w.Flush()
+	err := cw.w.Error()
+	if cw.closer != nil {
+		if cerr := cw.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// CSVFiles returns a WriterFactory creating the CSV files <prefix>-<part>.csv in dir.
+func CSVFiles(dir, prefix string) WriterFactory {
+	return func(part int, schema *Schema) (RowWriter, error) {
+		f, err := os.Create(filepath.Join(dir, fmt.Sprintf("%s-%05d.csv", prefix, part)))
+		if err != nil {
+			return nil, err
+		}
+
+		cw, err := NewCSVWriter(f, schema)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		cw.closer = f
+		return cw, nil
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package export streams the records of a Recordset to CSV files, or to any other
+// row based or columnar format, such as Parquet, through the RowWriter interface.
+//
+// The rows follow a Schema, either provided or inferred from the first records.
+// The records are written by parallel workers, each rotating its own files, and only
+// the records of the schema inference sample are held in memory.
+package export
+
+import (
+	"sync"
+	"sync/atomic"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+)
+
+// RowWriter writes the exported rows to a file.
+// A Parquet adapter maps the Schema columns to the Parquet schema, and writes
+// the row values, which are int64, float64, string, bool, []byte or nil.
+type RowWriter interface {
+	// WriteRow writes a row, with a value for each column of the schema.
+	// The row slice is reused after the call returns.
+	WriteRow(row []interface{}) error
+
+	// Close flushes and closes the file.
+	Close() error
+}
+
+// WriterFactory creates the RowWriter of a new file. The parts are numbered from 1,
+// and are unique for an Export. WriterFactory is called concurrently by the workers.
+type WriterFactory func(part int, schema *Schema) (RowWriter, error)
+
+// ExportPolicy contains the attributes used for Export.
+type ExportPolicy struct {
+	// Schema is the schema of the exported rows.
+	// If nil, the schema is inferred from the first SampleSize records.
+	Schema *Schema
+
+	// SampleSize is the number of records the schema is inferred from. The bins that are
+	// not in the sample records are not exported. The sample records are held in memory.
+	//
+	// Default: 1000
+	SampleSize int // = 1000
+
+	// IncludeMetadata adds the digest, user key, generation and expiration columns to the
+	// inferred schema.
+	//
+	// Default: false
+	IncludeMetadata bool
+
+	// Parallel is the number of workers writing the files concurrently.
+	//
+	// Default: 1
+	Parallel int // = 1
+
+	// MaxRowsPerFile is the maximum number of rows written to a file before a worker
+	// rotates to a new file. 0 writes a single file per worker.
+	//
+	// Default: 0
+	MaxRowsPerFile int64
+}
+
+// NewExportPolicy creates a new ExportPolicy with default values.
+func NewExportPolicy() *ExportPolicy {
+	return &ExportPolicy{
+		SampleSize: 1000,
+		Parallel:   1,
+	}
+}
+
+// Stats contains the counters of an Export.
+type Stats struct {
+	// Records is the number of records exported.
+	Records int64
+
+	// Files is the number of files created.
+	Files int64
+}
+
+// Export writes the records of the recordset to the files created by newWriter,
+// and returns the schema of the rows. No file is created if the recordset is empty.
+// The recordset is closed on return.
+func Export(rs *as.Recordset, policy *ExportPolicy, newWriter WriterFactory) (*Schema, *Stats, error) {
+	if policy == nil {
+		policy = NewExportPolicy()
+	}
+	defer rs.Close()
+
+	e := &exporter{
+		policy:    policy,
+		newWriter: newWriter,
+		schema:    policy.Schema,
+		records:   make(chan *as.Record, policy.Parallel),
+		stop:      make(chan struct{}),
+	}
+
+	var sample []*as.Record
+	if e.schema == nil {
+		sampleSize := policy.SampleSize
+		if sampleSize <= 0 {
+			sampleSize = 1000
+		}
+
+		for res := range rs.Results() {
+			if res.Err != nil {
+				return nil, e.stats(), res.Err
+			}
+
+			sample = append(sample, res.Record)
+			if len(sample) >= sampleSize {
+				break
+			}
+		}
+		e.schema = InferSchema(sample, policy.IncludeMetadata)
+	}
+
+	parallel := policy.Parallel
+	if parallel <= 0 {
+		parallel = 1
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := e.work(); err != nil {
+				e.fail(err)
+			}
+		}()
+	}
+
+	if e.dispatch(sample) {
+		for res := range rs.Results() {
+			if res.Err != nil {
+				e.fail(res.Err)
+				break
+			}
+
+			if !e.dispatch([]*as.Record{res.Record}) {
+				break
+			}
+		}
+	}
+	close(e.records)
+	wg.Wait()
+
+	return e.schema, e.stats(), e.err
+}
+
+type exporter struct {
+	policy    *ExportPolicy
+	newWriter WriterFactory
+	schema    *Schema
+	records   chan *as.Record
+
+	exported int64
+	files    int64
+	parts    int64
+
+	// the first error stops the export
+	once sync.Once
+	stop chan struct{}
+	err  error
+}
+
+func (e *exporter) stats() *Stats {
+	return &Stats{
+		Records: atomic.LoadInt64(&e.exported),
+		Files:   atomic.LoadInt64(&e.files),
+	}
+}
+
+func (e *exporter) fail(err error) {
+	e.once.Do(func() {
+		e.err = err
+		close(e.stop)
+	})
+}
+
+// dispatch sends the records to the workers. It returns false if the export was stopped.
+func (e *exporter) dispatch(records []*as.Record) bool {
+	for _, rec := range records {
+		select {
+		case e.records <- rec:
+		case <-e.stop:
+			return false
+		}
+	}
+	return true
+}
+
+// work writes the records to the files of the worker, until the records channel is closed.
+func (e *exporter) work() (err error) {
+	var w RowWriter
+	var rows int64
+	var row []interface{}
+
+	defer func() {
+		if w != nil {
+			if cerr := w.Close(); err == nil {
+				err = cerr
+			}
+		}
+	}()
+
+	for rec := range e.records {
+		if w != nil && e.policy.MaxRowsPerFile > 0 && rows >= e.policy.MaxRowsPerFile {
+			cerr := w.Close()
+			w = nil
+			if cerr != nil {
+				return cerr
+			}
+		}
+
+		if w == nil {
+			part := int(atomic.AddInt64(&e.parts, 1))
+			if w, err = e.newWriter(part, e.schema); err != nil {
+				w = nil
+				return err
+			}
+			atomic.AddInt64(&e.files, 1)
+			rows = 0
+		}
+
+		if row, err = e.schema.row(rec, row); err != nil {
+			return err
+		}
+
+		if err = w.WriteRow(row); err != nil {
+			return err
+		}
+		rows++
+		atomic.AddInt64(&e.exported, 1)
+	}
+	return nil
+}
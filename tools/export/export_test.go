@@ -0,0 +1,187 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+	"github.com/aerospike/aerospike-client-go/v7/mock"
+	"github.com/aerospike/aerospike-client-go/v7/tools/export"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+// rowCollector is a RowWriter keeping the typed rows, as a Parquet adapter receives them.
+type rowCollector struct {
+	m    *sync.Mutex
+	rows *[][]interface{}
+}
+
+func (rc *rowCollector) WriteRow(row []interface{}) error {
+	rc.m.Lock()
+	defer rc.m.Unlock()
+	*rc.rows = append(*rc.rows, append([]interface{}(nil), row...))
+	return nil
+}
+
+func (rc *rowCollector) Close() error {
+	return nil
+}
+
+var _ = gg.Describe("Export", func() {
+	const ns = "test"
+
+	var clnt *mock.Client
+
+	gg.BeforeEach(func() {
+		clnt = mock.NewClient()
+	})
+
+	put := func(set string, userKey interface{}, bins as.BinMap) {
+		key, err := as.NewKey(ns, set, userKey)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		policy := as.NewWritePolicy(0, 0)
+		policy.SendKey = true
+		gm.Expect(clnt.Put(policy, key, bins)).To(gm.Succeed())
+	}
+
+	scan := func(set string) *as.Recordset {
+		rs, err := clnt.ScanAll(nil, ns, set)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		return rs
+	}
+
+	gg.It("must infer the schema and export CSV", func() {
+		put("csv", 1, as.BinMap{"name": "a,b", "n": 1, "f": 1.5, "blob": []byte{1, 2}, "list": []interface{}{1, "x"}})
+		put("csv", 2, as.BinMap{"name": "c", "n": 2.5, "ok": true})
+
+		var buf bytes.Buffer
+		schema, stats, err := export.Export(scan("csv"), nil, func(part int, schema *export.Schema) (export.RowWriter, error) {
+			gm.Expect(part).To(gm.Equal(1))
+			return export.NewCSVWriter(&buf, schema)
+		})
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(stats).To(gm.Equal(&export.Stats{Records: 2, Files: 1}))
+		gm.Expect(schema.Columns).To(gm.Equal([]export.Column{
+			{Name: "blob", Type: export.ColumnBytes},
+			{Name: "f", Type: export.ColumnFloat},
+			{Name: "list", Type: export.ColumnJSON},
+			{Name: "n", Type: export.ColumnFloat},
+			{Name: "name", Type: export.ColumnString},
+			{Name: "ok", Type: export.ColumnBool},
+		}))
+
+		lines, err := csv.NewReader(&buf).ReadAll()
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(lines).To(gm.HaveLen(3))
+		gm.Expect(lines[0]).To(gm.Equal([]string{"blob", "f", "list", "n", "name", "ok"}))
+		gm.Expect(lines[1:]).To(gm.ConsistOf(
+			[]string{"AQI=", "1.5", `[1,"x"]`, "1", "a,b", ""},
+			[]string{"", "", "", "2.5", "c", "true"},
+		))
+	})
+
+	gg.It("must rotate the files of the parallel workers", func() {
+		for i := 0; i < 20; i++ {
+			put("rotate", i, as.BinMap{"i": i})
+		}
+
+		dir := gg.GinkgoT().TempDir()
+		policy := export.NewExportPolicy()
+		policy.Parallel = 3
+		policy.MaxRowsPerFile = 4
+		policy.SampleSize = 5
+
+		_, stats, err := export.Export(scan("rotate"), policy, export.CSVFiles(dir, "rotate"))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(stats.Records).To(gm.Equal(int64(20)))
+
+		files, err := filepath.Glob(filepath.Join(dir, "rotate-*.csv"))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(files).To(gm.HaveLen(int(stats.Files)))
+		gm.Expect(len(files)).To(gm.BeNumerically(">=", 5))
+
+		var values []string
+		for _, file := range files {
+			f, err := os.Open(file)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			lines, err := csv.NewReader(f).ReadAll()
+			f.Close()
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+
+			gm.Expect(lines[0]).To(gm.Equal([]string{"i"}))
+			gm.Expect(len(lines) - 1).To(gm.BeNumerically("<=", 4))
+			for _, line := range lines[1:] {
+				values = append(values, line[0])
+			}
+		}
+		gm.Expect(values).To(gm.HaveLen(20))
+		sort.Strings(values)
+		gm.Expect(values[0]).To(gm.Equal("0"))
+	})
+
+	gg.It("must export typed rows with a user schema and metadata", func() {
+		put("typed", "k1", as.BinMap{"a": 7, "b": "x", "ignored": 1})
+
+		var m sync.Mutex
+		var rows [][]interface{}
+		policy := export.NewExportPolicy()
+		policy.Schema = &export.Schema{Columns: []export.Column{
+			{Name: export.UserKeyColumn, Type: export.ColumnJSON},
+			{Name: export.GenerationColumn, Type: export.ColumnInt},
+			{Name: "a", Type: export.ColumnFloat},
+			{Name: "b", Type: export.ColumnJSON},
+			{Name: "missing", Type: export.ColumnInt},
+		}}
+
+		_, stats, err := export.Export(scan("typed"), policy, func(part int, schema *export.Schema) (export.RowWriter, error) {
+			return &rowCollector{m: &m, rows: &rows}, nil
+		})
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(stats.Records).To(gm.Equal(int64(1)))
+		gm.Expect(rows).To(gm.Equal([][]interface{}{{`"k1"`, int64(1), 7.0, `"x"`, nil}}))
+	})
+
+	gg.It("must fail on values that do not match the schema", func() {
+		put("mismatch", 1, as.BinMap{"a": "not a number"})
+
+		policy := export.NewExportPolicy()
+		policy.Schema = &export.Schema{Columns: []export.Column{{Name: "a", Type: export.ColumnInt}}}
+
+		var buf bytes.Buffer
+		_, stats, err := export.Export(scan("mismatch"), policy, func(part int, schema *export.Schema) (export.RowWriter, error) {
+			return export.NewCSVWriter(&buf, schema)
+		})
+		gm.Expect(err).To(gm.HaveOccurred())
+		gm.Expect(err.Error()).To(gm.ContainSubstring("column `a`"))
+		gm.Expect(stats.Records).To(gm.Equal(int64(0)))
+	})
+
+	gg.It("must not create files for empty recordsets", func() {
+		_, stats, err := export.Export(scan("empty"), nil, func(part int, schema *export.Schema) (export.RowWriter, error) {
+			gg.Fail("no file expected")
+			return nil, nil
+		})
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(stats).To(gm.Equal(&export.Stats{}))
+	})
+})
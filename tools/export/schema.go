@@ -0,0 +1,279 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+)
+
+// The names of the columns filled from the metadata of the records, instead of their bins.
+const (
+	// DigestColumn is the hex encoded digest of the record key.
+	DigestColumn = "@digest"
+
+	// UserKeyColumn is the user key of the record, if it was stored on the server.
+	UserKeyColumn = "@key"
+
+	// GenerationColumn is the generation of the record.
+	GenerationColumn = "@generation"
+
+	// ExpirationColumn is the TTL of the record, in seconds.
+	ExpirationColumn = "@expiration"
+)
+
+// ColumnType is the type of the values of a column.
+type ColumnType int
+
+const (
+	// ColumnJSON columns contain the values encoded as by as.MarshalValueJSON, as strings.
+	// They can hold any value, and are used for lists, maps, GeoJSON and HLL bins.
+	ColumnJSON ColumnType = iota
+
+	// ColumnInt columns contain int64 values.
+	ColumnInt
+
+	// ColumnFloat columns contain float64 values. Integers are converted to floats.
+	ColumnFloat
+
+	// ColumnString columns contain string values.
+	ColumnString
+
+	// ColumnBool columns contain bool values.
+	ColumnBool
+
+	// ColumnBytes columns contain []byte values.
+	ColumnBytes
+)
+
+// String implements the Stringer interface.
+func (ct ColumnType) String() string {
+	switch ct {
+	case ColumnJSON:
+		return "json"
+	case ColumnInt:
+		return "int"
+	case ColumnFloat:
+		return "float"
+	case ColumnString:
+		return "string"
+	case ColumnBool:
+		return "bool"
+	case ColumnBytes:
+		return "bytes"
+	}
+	return fmt.Sprintf("ColumnType(%d)", int(ct))
+}
+
+// Column describes a column of the exported rows.
+type Column struct {
+	// Name is the name of the bin the column is filled from, or one of the metadata columns.
+	Name string
+
+	// Type is the type of the values of the column.
+	Type ColumnType
+}
+
+// Schema describes the columns of the exported rows.
+// The bins of the records that are not in the schema are not exported.
+type Schema struct {
+	Columns []Column
+}
+
+// InferSchema returns the schema of the bins of the records. The columns are sorted by name,
+// after the metadata columns if includeMetadata is set. A bin with values of different types
+// is exported as a float column if the values are all numbers, and as a JSON column otherwise.
+func InferSchema(records []*as.Record, includeMetadata bool) *Schema {
+	types := map[string]ColumnType{}
+	for _, rec := range records {
+		for name, value := range rec.Bins {
+			ct, ok := columnTypeOf(value)
+			if !ok {
+				continue
+			}
+
+			if prev, exists := types[name]; exists {
+				ct = mergeColumnTypes(prev, ct)
+			}
+			types[name] = ct
+		}
+	}
+
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := &Schema{}
+	if includeMetadata {
+		res.Columns = append(res.Columns,
+			Column{Name: DigestColumn, Type: ColumnString},
+			Column{Name: UserKeyColumn, Type: ColumnJSON},
+			Column{Name: GenerationColumn, Type: ColumnInt},
+			Column{Name: ExpirationColumn, Type: ColumnInt},
+		)
+	}
+
+	for _, name := range names {
+		res.Columns = append(res.Columns, Column{Name: name, Type: types[name]})
+	}
+	return res
+}
+
+// columnTypeOf returns the column type of a bin value, and false for nil values.
+func columnTypeOf(v interface{}) (ColumnType, bool) {
+	switch v.(type) {
+	case nil, as.NullValue:
+		return ColumnJSON, false
+	case int, int8, int16, int32, int64, uint8, uint16, uint32:
+		return ColumnInt, true
+	case float32, float64:
+		return ColumnFloat, true
+	case string:
+		return ColumnString, true
+	case bool:
+		return ColumnBool, true
+	case []byte:
+		return ColumnBytes, true
+	}
+	return ColumnJSON, true
+}
+
+func mergeColumnTypes(a, b ColumnType) ColumnType {
+	switch {
+	case a == b:
+		return a
+	case (a == ColumnInt && b == ColumnFloat) || (a == ColumnFloat && b == ColumnInt):
+		return ColumnFloat
+	}
+	return ColumnJSON
+}
+
+// row converts a record to the values of the columns of the schema.
+// The missing bins are nil.
+func (s *Schema) row(rec *as.Record, dst []interface{}) ([]interface{}, error) {
+	dst = dst[:0]
+	for i := range s.Columns {
+		col := &s.Columns[i]
+
+		var v interface{}
+		switch col.Name {
+		case DigestColumn:
+			if rec.Key != nil {
+				v = hex.EncodeToString(rec.Key.Digest())
+			}
+		case UserKeyColumn:
+			if rec.Key != nil && rec.Key.Value() != nil {
+				v = rec.Key.Value().GetObject()
+			}
+		case GenerationColumn:
+			v = int64(rec.Generation)
+		case ExpirationColumn:
+			v = int64(rec.Expiration)
+		default:
+			v = rec.Bins[col.Name]
+		}
+
+		cv, err := convert(v, col.Type)
+		if err != nil {
+			return nil, fmt.Errorf("column `%s`: %w", col.Name, err)
+		}
+		dst = append(dst, cv)
+	}
+	return dst, nil
+}
+
+// convert converts a value to the type of a column.
+func convert(v interface{}, ct ColumnType) (interface{}, error) {
+	if _, ok := columnTypeOf(v); !ok {
+		return nil, nil
+	}
+
+	switch ct {
+	case ColumnJSON:
+		b, err := as.MarshalValueJSON(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+
+	case ColumnInt:
+		if i, ok := toInt64(v); ok {
+			return i, nil
+		}
+
+	case ColumnFloat:
+		switch f := v.(type) {
+		case float64:
+			return f, nil
+		case float32:
+			return float64(f), nil
+		}
+		if i, ok := toInt64(v); ok {
+			return float64(i), nil
+		}
+
+	case ColumnString:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+
+	case ColumnBool:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+
+	case ColumnBytes:
+		if b, ok := v.([]byte); ok {
+			return b, nil
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown column type %d", int(ct))
+	}
+
+	return nil, fmt.Errorf("value of type %T cannot be exported as %s", v, ct)
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch i := v.(type) {
+	case int:
+		return int64(i), true
+	case int8:
+		return int64(i), true
+	case int16:
+		return int64(i), true
+	case int32:
+		return int64(i), true
+	case int64:
+		return i, true
+	case uint8:
+		return int64(i), true
+	case uint16:
+		return int64(i), true
+	case uint32:
+		return int64(i), true
+	case uint64:
+		if i <= math.MaxInt64 {
+			return int64(i), true
+		}
+	}
+	return 0, false
+}
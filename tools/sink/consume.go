@@ -0,0 +1,72 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+)
+
+// ConsumeRecordset sends the records of the recordset to the sink, and flushes it.
+// It returns the number of records sent. The recordset is closed on return.
+func ConsumeRecordset(ctx context.Context, s Sink, rs *as.Recordset) (int64, error) {
+	defer rs.Close()
+
+	var sent int64
+	for res := range rs.Results() {
+		if res.Err != nil {
+			return sent, res.Err
+		}
+
+		msg := &Message{Key: res.Record.Key, Generation: res.Record.Generation, Record: res.Record}
+		if err := s.Send(ctx, msg); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, s.Flush(ctx)
+}
+
+// ConsumeChanges sends the changes of a ChangeStream, as returned by ChangeStream.Events,
+// to the sink until the stream is closed or ctx is done. The sink is flushed on return.
+// The events with an error are passed to onError if it is not nil, and skipped.
+func ConsumeChanges(ctx context.Context, s Sink, events <-chan *as.ChangeEvent, onError func(as.Error)) error {
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return s.Flush(ctx)
+			}
+
+			if e.Err != nil {
+				if onError != nil {
+					onError(e.Err)
+				}
+				continue
+			}
+
+			if err := s.Send(ctx, &Message{Key: e.Key, Generation: e.Generation}); err != nil {
+				return err
+			}
+
+		case <-ctx.Done():
+			if err := s.Flush(context.Background()); err != nil {
+				return err
+			}
+			return ctx.Err()
+		}
+	}
+}
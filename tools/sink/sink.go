@@ -0,0 +1,411 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sink publishes the records of a Recordset, or the changes of a ChangeStream,
+// to a message queue such as Kafka, through a user supplied publisher.
+//
+// The messages of a record are always published in order: the messages are distributed
+// to ordered lanes by the partition of their digest, and each lane publishes its batches
+// sequentially, retrying the failed publications.
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+)
+
+// ErrClosed is returned by the operations of a closed sink.
+var ErrClosed = errors.New("sink is closed")
+
+// Message is a record, or a record change, to be published.
+type Message struct {
+	// Key is the key of the record. Its digest is the message key for partitioned queues.
+	Key *as.Key
+
+	// Generation is the generation of the record.
+	Generation uint32
+
+	// Record is the record with its bins. It is nil for the messages of the change events,
+	// which only hold the key and generation of the changed records.
+	Record *as.Record
+}
+
+// Value returns the JSON encoding of the message record, as encoded by Record.MarshalJSON.
+func (m *Message) Value() ([]byte, error) {
+	if m.Record != nil {
+		return json.Marshal(m.Record)
+	}
+	return json.Marshal(&as.Record{Key: m.Key, Generation: m.Generation})
+}
+
+// Publisher publishes a message to the queue.
+type Publisher func(ctx context.Context, msg *Message) error
+
+// BatchPublisher publishes a batch of messages to the queue, in order.
+// The batch is retried as a whole if an error is returned.
+type BatchPublisher func(ctx context.Context, msgs []*Message) error
+
+// Sink receives the messages to publish.
+type Sink interface {
+	// Send queues a message to be published. It blocks while the queue of the message lane is full.
+	Send(ctx context.Context, msg *Message) error
+
+	// Flush publishes the queued messages, and waits until they are published.
+	Flush(ctx context.Context) error
+
+	// Close flushes the queued messages and releases the resources of the sink.
+	Close() error
+}
+
+// SinkPolicy contains the attributes used by the QueueSink.
+type SinkPolicy struct {
+	// Lanes is the number of batches published concurrently. The messages of a record
+	// are always sent to the same lane.
+	//
+	// Default: 8
+	Lanes int // = 8
+
+	// BatchSize is the maximum number of messages of a batch. It is also the size of the
+	// queue of each lane.
+	//
+	// Default: 100
+	BatchSize int // = 100
+
+	// FlushInterval is the maximum time a message is queued before its batch is published.
+	//
+	// Default: 100 milliseconds
+	FlushInterval time.Duration // = 100 * time.Millisecond
+
+	// MaxRetries is the number of times a failed publication is retried.
+	// The sink fails if a publication still fails after the retries.
+	//
+	// Default: 3
+	MaxRetries int // = 3
+
+	// RetryBackoff is the delay before the first retry. It doubles after each retry.
+	//
+	// Default: 100 milliseconds
+	RetryBackoff time.Duration // = 100 * time.Millisecond
+
+	// PublishTimeout is the timeout of each publication attempt. 0 means no timeout.
+	//
+	// Default: 0
+	PublishTimeout time.Duration
+}
+
+// NewSinkPolicy creates a new SinkPolicy with default values.
+func NewSinkPolicy() *SinkPolicy {
+	return &SinkPolicy{
+		Lanes:         8,
+		BatchSize:     100,
+		FlushInterval: 100 * time.Millisecond,
+		MaxRetries:    3,
+		RetryBackoff:  100 * time.Millisecond,
+	}
+}
+
+// Stats contains the counters of a QueueSink.
+type Stats struct {
+	// Published is the number of messages published.
+	Published int64
+
+	// Retries is the number of publications retried.
+	Retries int64
+}
+
+// QueueSink is the Sink publishing the messages in batches with a Publisher or a BatchPublisher.
+type QueueSink struct {
+	policy  SinkPolicy
+	publish func(ctx context.Context, msgs []*Message) (int, error)
+	lanes   []*lane
+	wg      sync.WaitGroup
+
+	// guards the fields below
+	m      sync.Mutex
+	stats  Stats
+	err    error
+	closed bool
+
+	// cancels the publications when the sink fails
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+var _ Sink = (*QueueSink)(nil)
+
+type lane struct {
+	messages chan *Message
+	flushes  chan chan struct{}
+}
+
+// NewQueueSink creates a sink publishing the messages one by one with publish.
+// If the policy is nil, the default SinkPolicy will be used.
+func NewQueueSink(policy *SinkPolicy, publish Publisher) *QueueSink {
+	return newQueueSink(policy, func(ctx context.Context, msgs []*Message) (int, error) {
+		for i, msg := range msgs {
+			if err := publish(ctx, msg); err != nil {
+				return i, err
+			}
+		}
+		return len(msgs), nil
+	})
+}
+
+// NewBatchQueueSink creates a sink publishing the messages in batches with publish.
+// If the policy is nil, the default SinkPolicy will be used.
+func NewBatchQueueSink(policy *SinkPolicy, publish BatchPublisher) *QueueSink {
+	return newQueueSink(policy, func(ctx context.Context, msgs []*Message) (int, error) {
+		if err := publish(ctx, msgs); err != nil {
+			return 0, err
+		}
+		return len(msgs), nil
+	})
+}
+
+func newQueueSink(policy *SinkPolicy, publish func(ctx context.Context, msgs []*Message) (int, error)) *QueueSink {
+	if policy == nil {
+		policy = NewSinkPolicy()
+	}
+
+	s := &QueueSink{
+		policy:  *policy,
+		publish: publish,
+	}
+
+	if s.policy.Lanes <= 0 {
+		s.policy.Lanes = 1
+	}
+	if s.policy.BatchSize <= 0 {
+		s.policy.BatchSize = 1
+	}
+	if s.policy.FlushInterval <= 0 {
+		s.policy.FlushInterval = 100 * time.Millisecond
+	}
+
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	s.lanes = make([]*lane, s.policy.Lanes)
+	for i := range s.lanes {
+		l := &lane{
+			messages: make(chan *Message, s.policy.BatchSize),
+			flushes:  make(chan chan struct{}),
+		}
+		s.lanes[i] = l
+
+		s.wg.Add(1)
+		go s.run(l)
+	}
+
+	return s
+}
+
+// Stats returns the counters of the sink.
+func (s *QueueSink) Stats() Stats {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.stats
+}
+
+// Err returns the error the sink failed with, if any.
+func (s *QueueSink) Err() error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.err
+}
+
+// Send implements the Sink interface. The messages without a key cannot be ordered,
+// and are rejected.
+func (s *QueueSink) Send(ctx context.Context, msg *Message) error {
+	if msg == nil || msg.Key == nil {
+		return errors.New("message without key")
+	}
+
+	s.m.Lock()
+	err, closed := s.err, s.closed
+	s.m.Unlock()
+	if err != nil {
+		return err
+	} else if closed {
+		return ErrClosed
+	}
+
+	l := s.lanes[msg.Key.PartitionId()%len(s.lanes)]
+	select {
+	case l.messages <- msg:
+		return nil
+	case <-s.ctx.Done():
+		return s.Err()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Flush implements the Sink interface.
+func (s *QueueSink) Flush(ctx context.Context) error {
+	s.m.Lock()
+	closed := s.closed
+	s.m.Unlock()
+	if closed {
+		return ErrClosed
+	}
+	return s.flush(ctx)
+}
+
+func (s *QueueSink) flush(ctx context.Context) error {
+	done := make([]chan struct{}, len(s.lanes))
+	for i, l := range s.lanes {
+		done[i] = make(chan struct{})
+		select {
+		case l.flushes <- done[i]:
+		case <-s.ctx.Done():
+			return s.Err()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	for i := range done {
+		select {
+		case <-done[i]:
+		case <-s.ctx.Done():
+			return s.Err()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return s.Err()
+}
+
+// Close implements the Sink interface. Send must not be called concurrently with Close.
+func (s *QueueSink) Close() error {
+	s.m.Lock()
+	if s.closed {
+		s.m.Unlock()
+		return ErrClosed
+	}
+	s.closed = true
+	s.m.Unlock()
+
+	err := s.flush(context.Background())
+	s.cancel()
+	s.wg.Wait()
+	return err
+}
+
+// fail stops the sink with the first error.
+func (s *QueueSink) fail(err error) {
+	s.m.Lock()
+	if s.err == nil {
+		s.err = err
+	}
+	s.m.Unlock()
+	s.cancel()
+}
+
+// run publishes the batches of a lane, until the sink is closed or fails.
+func (s *QueueSink) run(l *lane) {
+	defer s.wg.Done()
+
+	batch := make([]*Message, 0, s.policy.BatchSize)
+	timer := time.NewTimer(s.policy.FlushInterval)
+	defer timer.Stop()
+
+	publish := func() bool {
+		if len(batch) > 0 {
+			if err := s.publishBatch(batch); err != nil {
+				s.fail(err)
+				return false
+			}
+		}
+		batch = batch[:0]
+		return true
+	}
+
+	for {
+		select {
+		case msg := <-l.messages:
+			batch = append(batch, msg)
+			if len(batch) >= s.policy.BatchSize && !publish() {
+				return
+			}
+
+		case done := <-l.flushes:
+			// the messages sent before the flush are already in the queue
+			for len(l.messages) > 0 {
+				batch = append(batch, <-l.messages)
+				if len(batch) >= s.policy.BatchSize && !publish() {
+					return
+				}
+			}
+			if !publish() {
+				return
+			}
+			close(done)
+
+		case <-timer.C:
+			if !publish() {
+				return
+			}
+			timer.Reset(s.policy.FlushInterval)
+
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// publishBatch publishes the messages of a batch, retrying the messages
+// that were not published.
+func (s *QueueSink) publishBatch(batch []*Message) error {
+	backoff := s.policy.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		n, err := s.publishOnce(batch)
+
+		s.m.Lock()
+		s.stats.Published += int64(n)
+		if err != nil && attempt < s.policy.MaxRetries {
+			s.stats.Retries++
+		}
+		s.m.Unlock()
+
+		if err == nil {
+			return nil
+		} else if attempt >= s.policy.MaxRetries {
+			return err
+		}
+		batch = batch[n:]
+
+		select {
+		case <-time.After(backoff):
+		case <-s.ctx.Done():
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+func (s *QueueSink) publishOnce(batch []*Message) (int, error) {
+	ctx := s.ctx
+	if s.policy.PublishTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.policy.PublishTimeout)
+		defer cancel()
+	}
+	return s.publish(ctx, batch)
+}
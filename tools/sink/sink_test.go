@@ -0,0 +1,201 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+	"github.com/aerospike/aerospike-client-go/v7/mock"
+	"github.com/aerospike/aerospike-client-go/v7/tools/sink"
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Sink", func() {
+	const ns = "test"
+
+	ctx := context.Background()
+
+	var m sync.Mutex
+	var published []*sink.Message
+
+	collect := func(ctx context.Context, msg *sink.Message) error {
+		m.Lock()
+		defer m.Unlock()
+		published = append(published, msg)
+		return nil
+	}
+
+	newPolicy := func() *sink.SinkPolicy {
+		policy := sink.NewSinkPolicy()
+		policy.RetryBackoff = time.Millisecond
+		return policy
+	}
+
+	gg.BeforeEach(func() {
+		published = nil
+	})
+
+	gg.It("must publish the records of a recordset", func() {
+		clnt := mock.NewClient()
+		for i := 0; i < 50; i++ {
+			key, _ := as.NewKey(ns, "sink", i)
+			gm.Expect(clnt.Put(nil, key, as.BinMap{"i": i, "b": []byte{byte(i)}})).To(gm.Succeed())
+		}
+
+		rs, err := clnt.ScanAll(nil, ns, "sink")
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		s := sink.NewQueueSink(newPolicy(), collect)
+		sent, serr := sink.ConsumeRecordset(ctx, s, rs)
+		gm.Expect(serr).ToNot(gm.HaveOccurred())
+		gm.Expect(sent).To(gm.Equal(int64(50)))
+		gm.Expect(published).To(gm.HaveLen(50))
+		gm.Expect(s.Stats()).To(gm.Equal(sink.Stats{Published: 50}))
+		gm.Expect(s.Close()).To(gm.Succeed())
+		gm.Expect(s.Close()).To(gm.MatchError(sink.ErrClosed))
+
+		value, verr := published[0].Value()
+		gm.Expect(verr).ToNot(gm.HaveOccurred())
+		var rec as.Record
+		gm.Expect(json.Unmarshal(value, &rec)).To(gm.Succeed())
+		gm.Expect(rec.Key.Equals(published[0].Key)).To(gm.BeTrue())
+		gm.Expect(rec.Bins).To(gm.Equal(published[0].Record.Bins))
+	})
+
+	gg.It("must publish the messages of a record in order, in bounded batches", func() {
+		var batches [][]*sink.Message
+		policy := newPolicy()
+		policy.Lanes = 4
+		policy.BatchSize = 7
+		s := sink.NewBatchQueueSink(policy, func(ctx context.Context, msgs []*sink.Message) error {
+			m.Lock()
+			defer m.Unlock()
+			batches = append(batches, append([]*sink.Message(nil), msgs...))
+			return nil
+		})
+
+		keys := make([]*as.Key, 10)
+		for i := range keys {
+			keys[i], _ = as.NewKey(ns, "sink", i)
+		}
+		for gen := 1; gen <= 20; gen++ {
+			for _, key := range keys {
+				gm.Expect(s.Send(ctx, &sink.Message{Key: key, Generation: uint32(gen)})).To(gm.Succeed())
+			}
+		}
+		gm.Expect(s.Close()).To(gm.Succeed())
+
+		last := map[string]uint32{}
+		total := 0
+		for _, batch := range batches {
+			gm.Expect(len(batch)).To(gm.BeNumerically("<=", 7))
+			for _, msg := range batch {
+				d := string(msg.Key.Digest())
+				gm.Expect(msg.Generation).To(gm.Equal(last[d] + 1))
+				last[d] = msg.Generation
+				total++
+			}
+		}
+		gm.Expect(total).To(gm.Equal(200))
+	})
+
+	gg.It("must retry the failed publications from the failed message", func() {
+		failures := 2
+		s := sink.NewQueueSink(newPolicy(), func(ctx context.Context, msg *sink.Message) error {
+			if msg.Generation == 2 && failures > 0 {
+				failures--
+				return errors.New("broker unavailable")
+			}
+			return collect(ctx, msg)
+		})
+
+		key, _ := as.NewKey(ns, "sink", 1)
+		for gen := 1; gen <= 3; gen++ {
+			gm.Expect(s.Send(ctx, &sink.Message{Key: key, Generation: uint32(gen)})).To(gm.Succeed())
+		}
+		gm.Expect(s.Flush(ctx)).To(gm.Succeed())
+		gm.Expect(s.Stats()).To(gm.Equal(sink.Stats{Published: 3, Retries: 2}))
+
+		gens := []uint32{}
+		for _, msg := range published {
+			gens = append(gens, msg.Generation)
+		}
+		gm.Expect(gens).To(gm.Equal([]uint32{1, 2, 3}))
+		gm.Expect(s.Close()).To(gm.Succeed())
+	})
+
+	gg.It("must fail after the retries", func() {
+		policy := newPolicy()
+		policy.MaxRetries = 1
+		brokerErr := errors.New("broker unavailable")
+		s := sink.NewQueueSink(policy, func(ctx context.Context, msg *sink.Message) error {
+			return brokerErr
+		})
+
+		key, _ := as.NewKey(ns, "sink", 1)
+		gm.Expect(s.Send(ctx, &sink.Message{Key: key})).To(gm.Succeed())
+		gm.Expect(s.Flush(ctx)).To(gm.MatchError(brokerErr))
+		gm.Expect(s.Send(ctx, &sink.Message{Key: key})).To(gm.MatchError(brokerErr))
+		gm.Expect(s.Err()).To(gm.MatchError(brokerErr))
+		gm.Expect(s.Stats()).To(gm.Equal(sink.Stats{Retries: 1}))
+		gm.Expect(s.Close()).To(gm.MatchError(brokerErr))
+
+		gm.Expect(s.Send(ctx, &sink.Message{})).To(gm.HaveOccurred())
+	})
+
+	gg.It("must publish the changes of a change stream", func() {
+		events := make(chan *as.ChangeEvent, 4)
+		key1, _ := as.NewKey(ns, "sink", 1)
+		key2, _ := as.NewKey(ns, "sink", 2)
+		events <- &as.ChangeEvent{Key: key1, Generation: 3}
+		events <- &as.ChangeEvent{Err: as.ErrNetTimeout}
+		events <- &as.ChangeEvent{Key: key2, Generation: 1}
+		close(events)
+
+		var errs []as.Error
+		s := sink.NewQueueSink(newPolicy(), collect)
+		err := sink.ConsumeChanges(ctx, s, events, func(err as.Error) { errs = append(errs, err) })
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(errs).To(gm.HaveLen(1))
+		gm.Expect(errs[0].Matches(types.TIMEOUT)).To(gm.BeTrue())
+		gm.Expect(published).To(gm.HaveLen(2))
+		gm.Expect(s.Close()).To(gm.Succeed())
+
+		for _, msg := range published {
+			gm.Expect(msg.Record).To(gm.BeNil())
+			value, err := msg.Value()
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(string(value)).To(gm.ContainSubstring(`"digest"`))
+		}
+	})
+
+	gg.It("must stop consuming the changes when the context is done", func() {
+		events := make(chan *as.ChangeEvent)
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		s := sink.NewQueueSink(newPolicy(), collect)
+		gm.Expect(sink.ConsumeChanges(cctx, s, events, nil)).To(gm.MatchError(context.Canceled))
+		gm.Expect(s.Close()).To(gm.Succeed())
+	})
+})
@@ -0,0 +1,66 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+)
+
+// TruncatePolicy contains attributes used for the TruncateWithPolicy command.
+type TruncatePolicy struct {
+	InfoPolicy
+
+	// MaxClockSkew is the maximum tolerated difference between the local clock and the clock
+	// of any of the cluster nodes when a beforeLastUpdate time is passed to TruncateWithPolicy.
+	// The last update times are set by the server clocks, so a cutoff computed on a skewed
+	// client will remove a different range of records than intended.
+	// The server clocks are estimated with a resolution of one second.
+	// A value of 0 disables the check, but cutoffs in the future of the server clocks are always rejected.
+	//
+	// Default: 5 seconds
+	MaxClockSkew time.Duration // = 5 * time.Second
+
+	// DryRun determines if the truncation command is sent to the server.
+	// If true, only the validations are performed and the number of records that would be affected is
+	// estimated from the set or namespace statistics.
+	//
+	// Default: false
+	DryRun bool // = false
+}
+
+// NewTruncatePolicy generates a new TruncatePolicy with default values.
+func NewTruncatePolicy() *TruncatePolicy {
+	return &TruncatePolicy{
+		InfoPolicy:   *NewInfoPolicy(),
+		MaxClockSkew: 5 * time.Second,
+	}
+}
+
+// TruncateResult contains the outcome of the TruncateWithPolicy command.
+type TruncateResult struct {
+	// EstimatedRecords is the number of master records in the set or namespace,
+	// summed over the cluster nodes. Since the server does not keep statistics on
+	// the last update times, this is an upper bound for truncations with a cutoff time.
+	EstimatedRecords int64
+
+	// ClockSkew is the largest estimated difference between the clock of a node and the local one.
+	// Positive values denote a server clock ahead of the local clock.
+	// Only set if a cutoff time was passed.
+	ClockSkew time.Duration
+
+	// Truncated reports if the truncate command was sent to and accepted by the server.
+	// It is false in dry-run mode.
+	Truncated bool
+}
@@ -117,5 +117,71 @@ var _ = gg.Describe("Truncate operations test", func() {
 			gm.Expect(countRecords(ns, set)).To(gm.Equal(keyCount))
 		})
 
+		gg.Context("TruncateWithPolicy", func() {
+
+			gg.It("must only estimate the affected records in dry-run mode", func() {
+				tp := as.NewTruncatePolicy()
+				tp.DryRun = true
+
+				res, err := nativeClient.TruncateWithPolicy(tp, ns, set, time.Time{})
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(res.Truncated).To(gm.BeFalse())
+				gm.Expect(res.EstimatedRecords).To(gm.Equal(int64(keyCount)))
+
+				gm.Expect(countRecords(ns, set)).To(gm.Equal(keyCount))
+			})
+
+			gg.It("must reject cutoffs in the future", func() {
+				res, err := nativeClient.TruncateWithPolicy(nil, ns, set, time.Now().Add(time.Hour))
+				gm.Expect(err).To(gm.HaveOccurred())
+				gm.Expect(res).ToNot(gm.BeNil())
+				gm.Expect(res.Truncated).To(gm.BeFalse())
+
+				gm.Expect(countRecords(ns, set)).To(gm.Equal(keyCount))
+			})
+
+			gg.It("must reject cutoffs before the Citrusleaf epoch", func() {
+				_, err := nativeClient.TruncateWithPolicy(nil, ns, set, time.Unix(0, 0))
+				gm.Expect(err).To(gm.HaveOccurred())
+			})
+
+			gg.It("must truncate the older records", func() {
+				time.Sleep(1 * time.Second)
+
+				res, err := nativeClient.TruncateWithPolicy(nil, ns, set, time.Now())
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+				gm.Expect(res.Truncated).To(gm.BeTrue())
+
+				time.Sleep(time.Second)
+				gm.Expect(countRecords(ns, set)).To(gm.Equal(0))
+			})
+		})
+
+		gg.Context("Sets", func() {
+
+			gg.It("must list the sets of the namespace", func() {
+				sets, err := nativeClient.ListSets(nil, ns)
+				gm.Expect(err).ToNot(gm.HaveOccurred())
+
+				var found *as.SetInfo
+				for _, si := range sets {
+					gm.Expect(si.Namespace).To(gm.Equal(ns))
+					if si.Name == set {
+						found = si
+					}
+				}
+				gm.Expect(found).ToNot(gm.BeNil())
+				gm.Expect(found.Objects).To(gm.Equal(int64(keyCount)))
+			})
+
+			gg.It("must delete the set and wait for its records to be removed", func() {
+				gm.Expect(nativeClient.DeleteSet(nil, ns, set)).ToNot(gm.HaveOccurred())
+				gm.Expect(countRecords(ns, set)).To(gm.Equal(0))
+
+				err := nativeClient.DeleteSet(nil, ns, "")
+				gm.Expect(err).To(gm.HaveOccurred())
+			})
+		})
+
 	})
 })
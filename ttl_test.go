@@ -0,0 +1,50 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"math"
+	"time"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("TTL helpers", func() {
+
+	gg.It("must compute the TTL and expiration time of records", func() {
+		rec := newRecord(nil, nil, nil, 1, 100)
+		gm.Expect(rec.TTL()).To(gm.Equal(100 * time.Second))
+		gm.Expect(rec.ExpiresAt()).To(gm.BeTemporally("~", time.Now().Add(100*time.Second), time.Second))
+
+		rec = newRecord(nil, nil, nil, 1, TTLDontExpire)
+		gm.Expect(rec.TTL()).To(gm.BeZero())
+		gm.Expect(rec.ExpiresAt().IsZero()).To(gm.BeTrue())
+	})
+
+	gg.It("must set the expiration of write policies", func() {
+		policy := NewWritePolicy(0, 0)
+
+		gm.Expect(policy.ExpireIn(90 * time.Second).Expiration).To(gm.Equal(uint32(90)))
+		gm.Expect(policy.ExpireIn(1500 * time.Millisecond).Expiration).To(gm.Equal(uint32(2)))
+		gm.Expect(policy.ExpireIn(0).Expiration).To(gm.Equal(uint32(1)))
+		gm.Expect(policy.ExpireIn(200 * 365 * 24 * time.Hour).Expiration).To(gm.Equal(uint32(TTLDontUpdate - 1)))
+		gm.Expect(policy.ExpireIn(math.MaxInt64).Expiration).To(gm.Equal(uint32(TTLDontUpdate - 1)))
+		gm.Expect(policy.ExpireAt(time.Now().Add(time.Hour)).Expiration).To(gm.BeNumerically("~", 3600, 1))
+		gm.Expect(policy.ExpireAt(time.Now().Add(-time.Hour)).Expiration).To(gm.Equal(uint32(1)))
+		gm.Expect(policy.NeverExpire().Expiration).To(gm.Equal(uint32(TTLDontExpire)))
+		gm.Expect(policy.DontUpdateTTL().Expiration).To(gm.Equal(uint32(TTLDontUpdate)))
+	})
+})
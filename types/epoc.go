@@ -20,9 +20,11 @@ func TTL(secsFromCitrusLeafEpoc uint32) uint32 {
 		// Record may not have expired on server, but delay or clock differences may
 		// cause it to look expired on client. Floor at 1, not 0, to avoid old
 		// "never expires" interpretation.
+		//
+		// The sum is computed in 64 bits; void times after 2106 would overflow 32 bits.
 		now := time.Now().Unix()
-		expiration := int64(CITRUSLEAF_EPOCH + secsFromCitrusLeafEpoc)
-		if (expiration < 0 && now >= 0) || expiration > now {
+		expiration := int64(CITRUSLEAF_EPOCH) + int64(secsFromCitrusLeafEpoc)
+		if expiration > now {
 			return uint32(expiration - now)
 		}
 		return 1
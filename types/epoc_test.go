@@ -0,0 +1,46 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types_test
+
+import (
+	"math"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("TTL", func() {
+
+	gg.It("must not convert records that never expire", func() {
+		gm.Expect(types.TTL(0)).To(gm.Equal(uint32(math.MaxUint32)))
+	})
+
+	gg.It("must convert void times to TTLs", func() {
+		voidTime := uint32(time.Now().Unix()-types.CITRUSLEAF_EPOCH) + 100
+		gm.Expect(types.TTL(voidTime)).To(gm.BeNumerically("~", 100, 1))
+	})
+
+	gg.It("must floor void times in the past at 1", func() {
+		voidTime := uint32(time.Now().Unix()-types.CITRUSLEAF_EPOCH) - 100
+		gm.Expect(types.TTL(voidTime)).To(gm.Equal(uint32(1)))
+	})
+
+	gg.It("must not overflow for void times far in the future", func() {
+		gm.Expect(types.TTL(math.MaxUint32 - 10)).To(gm.BeNumerically(">", uint32(math.MaxUint32-types.CITRUSLEAF_EPOCH)))
+	})
+})
@@ -0,0 +1,136 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package histogram
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// AtomicHistogram is a histogram that can be added to concurrently without locking.
+// Use it on hot paths where SyncHistogram's mutex becomes a point of contention.
+//
+// Values are accumulated as float64, so Min and Max of integer values above 2^53 are approximate.
+// Snapshots taken while values are being added may not include all of the fields of
+// the values being added at the time.
+type AtomicHistogram[T hvals] struct {
+	htype Type
+	base  float64
+
+	buckets []atomic.Uint64
+	count   atomic.Uint64
+	sum     atomic.Uint64 // float64 bits
+	min     atomic.Uint64 // float64 bits
+	max     atomic.Uint64 // float64 bits
+}
+
+func NewAtomic[T hvals](htype Type, base T, buckets int) *AtomicHistogram[T] {
+	return NewAtomicFloat[T](htype, float64(base), buckets)
+}
+
+// NewAtomicFloat creates a lock-free histogram with a fractional bucket width or logarithm base.
+func NewAtomicFloat[T hvals](htype Type, base float64, buckets int) *AtomicHistogram[T] {
+	h := &AtomicHistogram[T]{
+		htype:   htype,
+		base:    base,
+		buckets: make([]atomic.Uint64, buckets),
+	}
+	h.resetMinMax()
+	return h
+}
+
+func (h *AtomicHistogram[T]) resetMinMax() {
+	h.min.Store(math.Float64bits(math.Inf(1)))
+	h.max.Store(math.Float64bits(math.Inf(-1)))
+}
+
+func (h *AtomicHistogram[T]) Add(v T) {
+	fv := float64(v)
+	h.buckets[bucketIndex(h.htype, h.base, fv, len(h.buckets))].Add(1)
+
+	for {
+		old := h.sum.Load()
+		if h.sum.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+fv)) {
+			break
+		}
+	}
+
+	for {
+		old := h.min.Load()
+		if fv >= math.Float64frombits(old) || h.min.CompareAndSwap(old, math.Float64bits(fv)) {
+			break
+		}
+	}
+
+	for {
+		old := h.max.Load()
+		if fv <= math.Float64frombits(old) || h.max.CompareAndSwap(old, math.Float64bits(fv)) {
+			break
+		}
+	}
+
+	h.count.Add(1)
+}
+
+func (h *AtomicHistogram[T]) Reset() {
+	h.CloneAndReset()
+}
+
+// Snapshot returns a copy of the histogram's current state.
+func (h *AtomicHistogram[T]) Snapshot() *Histogram[T] {
+	res := NewFloat[T](h.htype, h.base, len(h.buckets))
+	for i := range h.buckets {
+		res.Buckets[i] = h.buckets[i].Load()
+	}
+
+	res.Count = h.count.Load()
+	res.Sum = math.Float64frombits(h.sum.Load())
+	if res.Count > 0 {
+		res.Min = T(math.Float64frombits(h.min.Load()))
+		res.Max = T(math.Float64frombits(h.max.Load()))
+	}
+	return res
+}
+
+// CloneAndReset returns a copy of the histogram's current state and resets it.
+// No value added concurrently is lost; it is counted either in the copy or after the reset.
+func (h *AtomicHistogram[T]) CloneAndReset() *Histogram[T] {
+	res := NewFloat[T](h.htype, h.base, len(h.buckets))
+	for i := range h.buckets {
+		res.Buckets[i] = h.buckets[i].Swap(0)
+	}
+
+	res.Count = h.count.Swap(0)
+	res.Sum = math.Float64frombits(h.sum.Swap(0))
+	minv := math.Float64frombits(h.min.Swap(math.Float64bits(math.Inf(1))))
+	maxv := math.Float64frombits(h.max.Swap(math.Float64bits(math.Inf(-1))))
+	if res.Count > 0 {
+		res.Min = T(minv)
+		res.Max = T(maxv)
+	}
+	return res
+}
+
+func (h *AtomicHistogram[T]) Average() float64 {
+	return h.Snapshot().Average()
+}
+
+func (h *AtomicHistogram[T]) Median() T {
+	return h.Snapshot().Median()
+}
+
+func (h *AtomicHistogram[T]) String() string {
+	return h.Snapshot().String()
+}
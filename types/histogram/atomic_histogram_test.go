@@ -0,0 +1,100 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package histogram_test
+
+import (
+	"sync"
+
+	"github.com/aerospike/aerospike-client-go/v7/types/histogram"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("AtomicHistogram", func() {
+
+	gg.It("must make the same histogram as the locking implementation", func() {
+		l := []int{1, 1, 3, 4, 5, 5, 9, 11, 11, 11, 16, 16, 21}
+		h := histogram.NewAtomic[int](histogram.Linear, 5, 5)
+		sh := histogram.New[int](histogram.Linear, 5, 5)
+
+		for _, v := range l {
+			h.Add(v)
+			sh.Add(v)
+		}
+
+		gm.Expect(h.Snapshot()).To(gm.Equal(sh))
+		gm.Expect(h.Median()).To(gm.Equal(sh.Median()))
+		gm.Expect(h.Average()).To(gm.Equal(sh.Average()))
+		gm.Expect(h.String()).To(gm.Equal(sh.String()))
+	})
+
+	gg.It("must report zero min and max when empty", func() {
+		h := histogram.NewAtomic[uint64](histogram.Logarithmic, 2, 8)
+		s := h.Snapshot()
+		gm.Expect(s.Count).To(gm.Equal(uint64(0)))
+		gm.Expect(s.Min).To(gm.Equal(uint64(0)))
+		gm.Expect(s.Max).To(gm.Equal(uint64(0)))
+		gm.Expect(s.Buckets).To(gm.Equal(make([]uint64, 8)))
+	})
+
+	gg.It("must not lose values added concurrently", func() {
+		const workers, adds = 8, 10000
+		h := histogram.NewAtomic[uint64](histogram.Logarithmic, 2, 16)
+
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 1; i <= adds; i++ {
+					h.Add(uint64(i + w))
+				}
+			}(w)
+		}
+		wg.Wait()
+
+		s := h.CloneAndReset()
+		gm.Expect(s.Count).To(gm.Equal(uint64(workers * adds)))
+		var total uint64
+		for _, c := range s.Buckets {
+			total += c
+		}
+		gm.Expect(total).To(gm.Equal(s.Count))
+		gm.Expect(s.Min).To(gm.Equal(uint64(1)))
+		gm.Expect(s.Max).To(gm.Equal(uint64(adds + workers - 1)))
+		// sum of i + w over all workers and adds
+		gm.Expect(s.Sum).To(gm.Equal(float64(workers*adds*(adds+1)/2 + adds*workers*(workers-1)/2)))
+
+		r := h.Snapshot()
+		gm.Expect(r.Count).To(gm.Equal(uint64(0)))
+		gm.Expect(r.Sum).To(gm.Equal(0.0))
+		gm.Expect(r.Min).To(gm.Equal(uint64(0)))
+	})
+
+	gg.It("must track the minimum after a reset", func() {
+		h := histogram.NewAtomic[int](histogram.Linear, 10, 4)
+		h.Add(5)
+		h.Reset()
+		h.Add(20)
+		h.Add(30)
+
+		s := h.Snapshot()
+		gm.Expect(s.Min).To(gm.Equal(20))
+		gm.Expect(s.Max).To(gm.Equal(30))
+		gm.Expect(s.Buckets).To(gm.Equal([]uint64{0, 0, 1, 1}))
+	})
+
+})
@@ -81,3 +81,25 @@ func Benchmark_Histogram_Log2_Median(b *testing.B) {
 		_medianu64 = h.Median()
 	}
 }
+
+func Benchmark_Histogram_Sync_Add_Parallel(b *testing.B) {
+	h := histogram.NewSync[uint64](histogram.Logarithmic, 2, 24)
+	b.RunParallel(func(pb *testing.PB) {
+		var i uint64
+		for pb.Next() {
+			h.Add(i)
+			i++
+		}
+	})
+}
+
+func Benchmark_Histogram_Atomic_Add_Parallel(b *testing.B) {
+	h := histogram.NewAtomic[uint64](histogram.Logarithmic, 2, 24)
+	b.RunParallel(func(pb *testing.PB) {
+		var i uint64
+		for pb.Next() {
+			h.Add(i)
+			i++
+		}
+	})
+}
@@ -37,7 +37,7 @@ type hvals interface {
 
 type Histogram[T hvals] struct {
 	htype Type
-	base  T
+	base  float64
 
 	Buckets []uint64 `json:"buckets"` // slot -> count
 	Min     T        `json:"min"`
@@ -49,7 +49,7 @@ type Histogram[T hvals] struct {
 func New[T hvals](htype Type, base T, buckets int) *Histogram[T] {
 	return &Histogram[T]{
 		htype:   htype,
-		base:    base,
+		base:    float64(base),
 		Buckets: make([]uint64, buckets),
 	}
 }
@@ -57,7 +57,7 @@ func New[T hvals](htype Type, base T, buckets int) *Histogram[T] {
 func NewLinear[T hvals](base T, buckets int) *Histogram[T] {
 	return &Histogram[T]{
 		htype:   Linear,
-		base:    base,
+		base:    float64(base),
 		Buckets: make([]uint64, buckets),
 	}
 }
@@ -65,11 +65,26 @@ func NewLinear[T hvals](base T, buckets int) *Histogram[T] {
 func NewExponential[T hvals](base T, buckets int) *Histogram[T] {
 	return &Histogram[T]{
 		htype:   Logarithmic,
+		base:    float64(base),
+		Buckets: make([]uint64, buckets),
+	}
+}
+
+// NewFloat creates a histogram with a fractional bucket width or logarithm base,
+// e.g. a linear histogram of 0.1ms buckets over millisecond latencies.
+func NewFloat[T hvals](htype Type, base float64, buckets int) *Histogram[T] {
+	return &Histogram[T]{
+		htype:   htype,
 		base:    base,
 		Buckets: make([]uint64, buckets),
 	}
 }
 
+// NewLinearFloat creates a linear histogram with a fractional bucket width.
+func NewLinearFloat[T hvals](width float64, buckets int) *Histogram[T] {
+	return NewFloat[T](Linear, width, buckets)
+}
+
 func (h *Histogram[T]) Reset() {
 	for i := range h.Buckets {
 		h.Buckets[i] = 0
@@ -82,12 +97,12 @@ func (h *Histogram[T]) Reset() {
 }
 
 func (h *Histogram[T]) Reshape(htype Type, base T, buckets int) {
-	if h.htype == htype && h.base == base && len(h.Buckets) == buckets {
+	if h.htype == htype && h.base == float64(base) && len(h.Buckets) == buckets {
 		return
 	}
 
 	h.htype = htype
-	h.base = base
+	h.base = float64(base)
 	h.Buckets = make([]uint64, buckets)
 
 	h.Min = 0
@@ -101,17 +116,17 @@ func (h *Histogram[T]) String() string {
 	switch h.htype {
 	case Linear:
 		for i := 0; i < len(h.Buckets)-1; i++ {
-			v := float64(h.base) * float64(i)
-			fmt.Fprintf(res, "[%v, %v) => %d\n", v, v+float64(h.base), h.Buckets[i])
+			v := h.base * float64(i)
+			fmt.Fprintf(res, "[%v, %v) => %d\n", v, v+h.base, h.Buckets[i])
 		}
-		fmt.Fprintf(res, "[%v, inf) => %d\n", float64(h.base)*float64(len(h.Buckets)-1), h.Buckets[len(h.Buckets)-1])
+		fmt.Fprintf(res, "[%v, inf) => %d\n", h.base*float64(len(h.Buckets)-1), h.Buckets[len(h.Buckets)-1])
 	case Logarithmic:
-		fmt.Fprintf(res, "[0, %v) => %d\n", float64(h.base), h.Buckets[0])
+		fmt.Fprintf(res, "[0, %v) => %d\n", h.base, h.Buckets[0])
 		for i := 1; i < len(h.Buckets)-1; i++ {
-			v := math.Pow(float64(h.base), float64(i))
-			fmt.Fprintf(res, "[%v, %v) => %d\n", v, v*float64(h.base), h.Buckets[i])
+			v := math.Pow(h.base, float64(i))
+			fmt.Fprintf(res, "[%v, %v) => %d\n", v, v*h.base, h.Buckets[i])
 		}
-		fmt.Fprintf(res, "[%v, inf) => %d\n", math.Pow(float64(h.base), float64(len(h.Buckets))-1), h.Buckets[len(h.Buckets)-1])
+		fmt.Fprintf(res, "[%v, inf) => %d\n", math.Pow(h.base, float64(len(h.Buckets))-1), h.Buckets[len(h.Buckets)-1])
 	}
 	return res.String()
 }
@@ -175,9 +190,9 @@ func (h *Histogram[T]) Median() T {
 		if s >= c {
 			// found the bucket
 			if h.htype == Linear {
-				return T(i+1) * h.base
+				return T(float64(i+1) * h.base)
 			}
-			return T(math.Pow(float64(h.base), float64(i+1)))
+			return T(math.Pow(h.base, float64(i+1)))
 		}
 	}
 	return h.Max
@@ -198,21 +213,31 @@ func (h *Histogram[T]) Add(v T) {
 	h.Sum += float64(v)
 	h.Count++
 
+	h.Buckets[bucketIndex(h.htype, h.base, float64(v), len(h.Buckets))]++
+}
+
+// boundaryEpsilon absorbs the rounding error of fractional bucket widths, so that
+// e.g. 0.3 falls in the [0.3, 0.4) bucket of a histogram with a width of 0.1.
+const boundaryEpsilon = 1e-9
+
+// bucketIndex returns the bucket of a histogram with n buckets the value falls in.
+// Values below the first bucket are counted in the first, and values beyond the last
+// bucket in the last.
+func bucketIndex(htype Type, base float64, v float64, n int) int {
 	var slot int
 	if v > 0 {
-		switch h.htype {
+		switch htype {
 		case Linear:
-			slot = int(math.Floor(float64(v / T(h.base))))
+			slot = int(math.Floor(v/base + boundaryEpsilon))
 		case Logarithmic:
-			slot = int(math.Floor(math.Log(float64(v)) / math.Log(float64(h.base))))
+			slot = int(math.Floor(math.Log(v) / math.Log(base)))
 		}
 	}
 
-	if slot >= len(h.Buckets) {
-		h.Buckets[len(h.Buckets)-1]++
+	if slot >= n {
+		return n - 1
 	} else if slot < 0 {
-		h.Buckets[0]++
-	} else {
-		h.Buckets[slot]++
+		return 0
 	}
+	return slot
 }
@@ -164,4 +164,39 @@ var _ = gg.Describe("Histogram", func() {
 			})
 		})
 	})
+
+	gg.Context("Fractional Widths", func() {
+
+		gg.It("must make the correct histogram with sub-millisecond buckets", func() {
+			l := []float64{0.05, 0.1, 0.15, 0.3, 0.35, 0.42, 0.7, 1.5}
+			h := histogram.NewLinearFloat[float64](0.1, 5)
+
+			for _, v := range l {
+				h.Add(v)
+			}
+
+			gm.Expect(h.Min).To(gm.Equal(0.05))
+			gm.Expect(h.Max).To(gm.Equal(1.5))
+			gm.Expect(h.Count).To(gm.Equal(uint64(len(l))))
+			gm.Expect(h.Buckets).To(gm.Equal([]uint64{1, 2, 0, 2, 3}))
+			gm.Expect(h.Median()).To(gm.Equal(0.4))
+		})
+
+		gg.It("must bucket integer values by a fractional width", func() {
+			h := histogram.NewSyncFloat[uint64](histogram.Linear, 2.5, 4)
+			for _, v := range []uint64{0, 2, 3, 5, 7, 8, 100} {
+				h.Add(v)
+			}
+
+			gm.Expect(h.Buckets).To(gm.Equal([]uint64{2, 1, 2, 2}))
+			gm.Expect(h.Median()).To(gm.Equal(uint64(5)))
+			gm.Expect(h.String()).To(gm.Equal("[0, 2.5) => 2\n[2.5, 5) => 1\n[5, 7.5) => 2\n[7.5, inf) => 2\n"))
+		})
+
+		gg.It("must match histograms by their fractional width when merging", func() {
+			h := histogram.NewLinearFloat[float64](0.1, 5)
+			gm.Expect(h.Merge(histogram.NewLinearFloat[float64](0.1, 5))).ToNot(gm.HaveOccurred())
+			gm.Expect(h.Merge(histogram.NewLinearFloat[float64](0.2, 5))).To(gm.HaveOccurred())
+		})
+	})
 })
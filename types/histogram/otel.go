@@ -0,0 +1,144 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package histogram
+
+import (
+	"errors"
+	"math"
+)
+
+// ExponentialDataPoint holds the data of a histogram in the layout of the exponential
+// histogram data points of OpenTelemetry, so that it can be exported to OTLP collectors
+// without re-bucketing.
+//
+// The bucket at index i spans (base^i, base^(i+1)], where base = 2^(2^-Scale).
+// PositiveCounts[j] is the population of the bucket at index PositiveOffset+j.
+// The first bucket of the histogram, which spans [0, base), is reported as the zero bucket.
+// The last bucket of the histogram also counts the values above its upper bound; Max is exact.
+type ExponentialDataPoint struct {
+	Scale          int32
+	ZeroCount      uint64
+	ZeroThreshold  float64
+	PositiveOffset int32
+	PositiveCounts []uint64
+
+	Count uint64
+	Sum   float64
+	Min   float64
+	Max   float64
+}
+
+// maximum scale of the OpenTelemetry exponential histograms
+const maxExponentialScale = 20
+
+// exponentialScale returns the scale of an OpenTelemetry exponential histogram with the base,
+// or false if base is not 2^(2^-scale) for an integer scale.
+func exponentialScale(base float64) (int32, bool) {
+	if base <= 1 {
+		return 0, false
+	}
+
+	scale := -math.Log2(math.Log2(base))
+	rounded := math.Round(scale)
+	if math.Abs(scale-rounded) > 1e-9 || rounded > maxExponentialScale || rounded < -10 {
+		return 0, false
+	}
+	return int32(rounded), true
+}
+
+// newExponentialDataPoint converts the buckets of a logarithmic histogram to a data point.
+func newExponentialDataPoint(scale int32, base float64, buckets []uint64) *ExponentialDataPoint {
+	res := &ExponentialDataPoint{
+		Scale:         scale,
+		ZeroThreshold: base,
+	}
+	if len(buckets) == 0 {
+		return res
+	}
+	res.ZeroCount = buckets[0]
+
+	// trim the empty buckets at both ends
+	first, last := 1, len(buckets)-1
+	for first <= last && buckets[first] == 0 {
+		first++
+	}
+	for last >= first && buckets[last] == 0 {
+		last--
+	}
+
+	if first <= last {
+		res.PositiveOffset = int32(first)
+		res.PositiveCounts = make([]uint64, last-first+1)
+		copy(res.PositiveCounts, buckets[first:last+1])
+	}
+	return res
+}
+
+var errNotExponential = errors.New("histogram is not logarithmic with a base of 2^(2^-scale)")
+
+// Exponential converts the histogram to an OpenTelemetry exponential histogram data point.
+// Only the logarithmic histograms with a base of 2^(2^-scale), e.g. 2, 4, 16 or sqrt(2),
+// can be converted without loss; an error is returned for the other histograms.
+func (h *Histogram[T]) Exponential() (*ExponentialDataPoint, error) {
+	if h.htype != Logarithmic {
+		return nil, errNotExponential
+	}
+
+	scale, ok := exponentialScale(h.base)
+	if !ok {
+		return nil, errNotExponential
+	}
+
+	res := newExponentialDataPoint(scale, h.base, h.Buckets)
+	res.Count = h.Count
+	res.Sum = h.Sum
+	res.Min = float64(h.Min)
+	res.Max = float64(h.Max)
+	return res, nil
+}
+
+// Exponential converts the histogram to an OpenTelemetry exponential histogram data point.
+// See Histogram.Exponential.
+func (h *SyncHistogram[T]) Exponential() (*ExponentialDataPoint, error) {
+	h.l.RLock()
+	defer h.l.RUnlock()
+
+	if h.htype != Logarithmic {
+		return nil, errNotExponential
+	}
+
+	scale, ok := exponentialScale(h.base)
+	if !ok {
+		return nil, errNotExponential
+	}
+
+	res := newExponentialDataPoint(scale, h.base, h.Buckets)
+	res.Count = h.Count
+	res.Sum = h.Sum
+	res.Min = float64(h.Min)
+	res.Max = float64(h.Max)
+	return res, nil
+}
+
+// Exponential converts the histogram to an OpenTelemetry exponential histogram data point
+// with a scale of 0.
+func (h *Log2) Exponential() *ExponentialDataPoint {
+	res := newExponentialDataPoint(0, 2, h.Buckets)
+	res.Count = h.Count
+	res.Sum = float64(h.Sum)
+	res.Min = float64(h.Min)
+	res.Max = float64(h.Max)
+	return res
+}
@@ -0,0 +1,92 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package histogram_test
+
+import (
+	"math"
+
+	"github.com/aerospike/aerospike-client-go/v7/types/histogram"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Exponential data points", func() {
+
+	gg.It("must convert a base 2 histogram without re-bucketing", func() {
+		h := histogram.NewExponential[uint64](2, 8)
+		for _, v := range []uint64{0, 1, 5, 6, 40, 50, 60, 1000} {
+			h.Add(v)
+		}
+
+		dp, err := h.Exponential()
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(dp.Scale).To(gm.Equal(int32(0)))
+		gm.Expect(dp.ZeroThreshold).To(gm.Equal(2.0))
+		gm.Expect(dp.ZeroCount).To(gm.Equal(uint64(2)))
+		// [4, 8) => 2, [32, 64) => 3, overflow => 1
+		gm.Expect(dp.PositiveOffset).To(gm.Equal(int32(2)))
+		gm.Expect(dp.PositiveCounts).To(gm.Equal([]uint64{2, 0, 0, 3, 0, 1}))
+		gm.Expect(dp.Count).To(gm.Equal(uint64(8)))
+		gm.Expect(dp.Sum).To(gm.Equal(1162.0))
+		gm.Expect(dp.Min).To(gm.Equal(0.0))
+		gm.Expect(dp.Max).To(gm.Equal(1000.0))
+
+		var total uint64 = dp.ZeroCount
+		for _, c := range dp.PositiveCounts {
+			total += c
+		}
+		gm.Expect(total).To(gm.Equal(dp.Count))
+	})
+
+	gg.It("must find the scale of the base", func() {
+		for base, scale := range map[float64]int32{2: 0, 4: -1, 16: -2, 256: -3, math.Sqrt2: 1} {
+			h := histogram.NewSync[float64](histogram.Logarithmic, base, 4)
+			h.Add(3)
+			dp, err := h.Exponential()
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(dp.Scale).To(gm.Equal(scale))
+		}
+	})
+
+	gg.It("must reject the histograms which cannot be converted without loss", func() {
+		_, err := histogram.NewExponential[int](5, 4).Exponential()
+		gm.Expect(err).To(gm.HaveOccurred())
+
+		_, err = histogram.NewLinear[int](2, 4).Exponential()
+		gm.Expect(err).To(gm.HaveOccurred())
+
+		_, err = histogram.NewSync[int](histogram.Linear, 2, 4).Exponential()
+		gm.Expect(err).To(gm.HaveOccurred())
+	})
+
+	gg.It("must convert empty histograms and Log2 histograms", func() {
+		dp, err := histogram.NewExponential[int](4, 4).Exponential()
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(dp.ZeroCount).To(gm.BeZero())
+		gm.Expect(dp.PositiveCounts).To(gm.BeEmpty())
+
+		h := histogram.NewLog2(6)
+		for _, v := range []uint64{1, 2, 3, 17} {
+			h.Add(v)
+		}
+		ldp := h.Exponential()
+		gm.Expect(ldp.Scale).To(gm.Equal(int32(0)))
+		gm.Expect(ldp.ZeroCount).To(gm.Equal(uint64(1)))
+		gm.Expect(ldp.PositiveOffset).To(gm.Equal(int32(1)))
+		gm.Expect(ldp.PositiveCounts).To(gm.Equal([]uint64{2, 0, 0, 1}))
+		gm.Expect(ldp.Sum).To(gm.Equal(23.0))
+	})
+})
@@ -25,7 +25,7 @@ import (
 type SyncHistogram[T hvals] struct {
 	l     sync.RWMutex
 	htype Type
-	base  T
+	base  float64
 
 	Buckets []uint64 `json:"buckets"` // slot -> count
 	Min     T        `json:"min"`
@@ -35,6 +35,15 @@ type SyncHistogram[T hvals] struct {
 }
 
 func NewSync[T hvals](htype Type, base T, buckets int) *SyncHistogram[T] {
+	return &SyncHistogram[T]{
+		htype:   htype,
+		base:    float64(base),
+		Buckets: make([]uint64, buckets),
+	}
+}
+
+// NewSyncFloat creates a thread-safe histogram with a fractional bucket width or logarithm base.
+func NewSyncFloat[T hvals](htype Type, base float64, buckets int) *SyncHistogram[T] {
 	return &SyncHistogram[T]{
 		htype:   htype,
 		base:    base,
@@ -57,13 +66,13 @@ func (h *SyncHistogram[T]) Reset() {
 
 func (h *SyncHistogram[T]) Reshape(htype Type, base T, buckets int) {
 	h.l.Lock()
-	if h.htype == htype && h.base == base && len(h.Buckets) == buckets {
+	if h.htype == htype && h.base == float64(base) && len(h.Buckets) == buckets {
 		h.l.Unlock()
 		return
 	}
 
 	h.htype = htype
-	h.base = base
+	h.base = float64(base)
 	h.Buckets = make([]uint64, buckets)
 	h.l.Unlock()
 }
@@ -74,17 +83,17 @@ func (h *SyncHistogram[T]) String() string {
 	switch h.htype {
 	case Linear:
 		for i := 0; i < len(h.Buckets)-1; i++ {
-			v := float64(h.base) * float64(i)
-			fmt.Fprintf(res, "[%v, %v) => %d\n", v, v+float64(h.base), h.Buckets[i])
+			v := h.base * float64(i)
+			fmt.Fprintf(res, "[%v, %v) => %d\n", v, v+h.base, h.Buckets[i])
 		}
-		fmt.Fprintf(res, "[%v, inf) => %d\n", float64(h.base)*float64(len(h.Buckets)-1), h.Buckets[len(h.Buckets)-1])
+		fmt.Fprintf(res, "[%v, inf) => %d\n", h.base*float64(len(h.Buckets)-1), h.Buckets[len(h.Buckets)-1])
 	case Logarithmic:
-		fmt.Fprintf(res, "[0, %v) => %d\n", float64(h.base), h.Buckets[0])
+		fmt.Fprintf(res, "[0, %v) => %d\n", h.base, h.Buckets[0])
 		for i := 1; i < len(h.Buckets)-1; i++ {
-			v := math.Pow(float64(h.base), float64(i))
-			fmt.Fprintf(res, "[%v, %v) => %d\n", v, v*float64(h.base), h.Buckets[i])
+			v := math.Pow(h.base, float64(i))
+			fmt.Fprintf(res, "[%v, %v) => %d\n", v, v*h.base, h.Buckets[i])
 		}
-		fmt.Fprintf(res, "[%v, inf) => %d\n", math.Pow(float64(h.base), float64(len(h.Buckets))-1), h.Buckets[len(h.Buckets)-1])
+		fmt.Fprintf(res, "[%v, inf) => %d\n", math.Pow(h.base, float64(len(h.Buckets))-1), h.Buckets[len(h.Buckets)-1])
 	}
 	h.l.RUnlock()
 	return res.String()
@@ -185,11 +194,11 @@ func (h *SyncHistogram[T]) Median() T {
 		if s >= c {
 			// found the bucket
 			if h.htype == Linear {
-				res := T(i+1) * h.base
+				res := T(float64(i+1) * h.base)
 				h.l.RUnlock()
 				return res
 			}
-			res := T(math.Pow(float64(h.base), float64(i+1)))
+			res := T(math.Pow(h.base, float64(i+1)))
 			h.l.RUnlock()
 			return res
 		}
@@ -215,22 +224,6 @@ func (h *SyncHistogram[T]) Add(v T) {
 	h.Sum += float64(v)
 	h.Count++
 
-	var slot int
-	if v > 0 {
-		switch h.htype {
-		case Linear:
-			slot = int(math.Floor(float64(v / T(h.base))))
-		case Logarithmic:
-			slot = int(math.Floor(math.Log(float64(v)) / math.Log(float64(h.base))))
-		}
-	}
-
-	if slot >= len(h.Buckets) {
-		h.Buckets[len(h.Buckets)-1]++
-	} else if slot < 0 {
-		h.Buckets[0]++
-	} else {
-		h.Buckets[slot]++
-	}
+	h.Buckets[bucketIndex(h.htype, h.base, float64(v), len(h.Buckets))]++
 	h.l.Unlock()
 }
@@ -0,0 +1,55 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+// retryableResultCodes are the result codes of transient failures, for which
+// a command may succeed if it is retried later, or on another node.
+var retryableResultCodes = map[ResultCode]struct{}{
+	TIMEOUT:                          {},
+	NO_RESPONSE:                      {},
+	NETWORK_ERROR:                    {},
+	MAX_RETRIES_EXCEEDED:             {},
+	MAX_ERROR_RATE:                   {},
+	SERVER_NOT_AVAILABLE:             {},
+	NO_AVAILABLE_CONNECTIONS_TO_NODE: {},
+	INVALID_NODE_ERROR:               {},
+	CLUSTER_KEY_MISMATCH:             {},
+	PARTITION_UNAVAILABLE:            {},
+	KEY_BUSY:                         {},
+	DEVICE_OVERLOAD:                  {},
+	QUOTA_EXCEEDED:                   {},
+}
+
+// IsRetryable returns true if the result code denotes a transient failure,
+// and the command may succeed if it is retried, usually after a backoff.
+// Writes that failed with a retryable code may still have been applied,
+// so check if the error is in doubt before retrying writes that are not idempotent.
+func (rc ResultCode) IsRetryable() bool {
+	_, exists := retryableResultCodes[rc]
+	return exists
+}
+
+// IsClientError returns true if the result code is generated by the client,
+// as opposed to being returned by the server.
+func (rc ResultCode) IsClientError() bool {
+	return rc < 0
+}
+
+// IsServerError returns true if the result code is a failure returned by the server.
+// TIMEOUT is classified as a server error, although the client also reports
+// its own timeouts with it.
+func (rc ResultCode) IsServerError() bool {
+	return rc > 0
+}
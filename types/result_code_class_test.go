@@ -0,0 +1,46 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types_test
+
+import (
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Result code classification", func() {
+
+	gg.It("must classify the transient failures as retryable", func() {
+		for _, rc := range []types.ResultCode{types.TIMEOUT, types.KEY_BUSY, types.DEVICE_OVERLOAD, types.NETWORK_ERROR, types.SERVER_NOT_AVAILABLE} {
+			gm.Expect(rc.IsRetryable()).To(gm.BeTrue(), rc.String())
+		}
+
+		for _, rc := range []types.ResultCode{types.OK, types.KEY_NOT_FOUND_ERROR, types.PARAMETER_ERROR, types.GENERATION_ERROR, types.SERIALIZE_ERROR} {
+			gm.Expect(rc.IsRetryable()).To(gm.BeFalse(), rc.String())
+		}
+	})
+
+	gg.It("must classify the client and server errors", func() {
+		gm.Expect(types.NETWORK_ERROR.IsClientError()).To(gm.BeTrue())
+		gm.Expect(types.NETWORK_ERROR.IsServerError()).To(gm.BeFalse())
+
+		gm.Expect(types.KEY_NOT_FOUND_ERROR.IsClientError()).To(gm.BeFalse())
+		gm.Expect(types.KEY_NOT_FOUND_ERROR.IsServerError()).To(gm.BeTrue())
+
+		gm.Expect(types.OK.IsClientError()).To(gm.BeFalse())
+		gm.Expect(types.OK.IsServerError()).To(gm.BeFalse())
+	})
+})
@@ -1,5 +1,14 @@
 package aerospike
 
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
 // UDF carries information about UDFs on the server
 type UDF struct {
 	// Filename of the UDF
@@ -8,4 +17,71 @@ type UDF struct {
 	Hash string
 	// Language of UDF
 	Language Language
+	// Nodes are the names of the nodes the UDF is registered on.
+	Nodes []string
+}
+
+// udfHash returns the hash digest of the UDF body, as reported by the server.
+func udfHash(udfBody []byte) string {
+	hash := sha1.Sum(udfBody)
+	return hex.EncodeToString(hash[:])
+}
+
+// parseUDFList parses the response of the udf-list info command.
+func parseUDFList(response string) []*UDF {
+	vals := strings.Split(response, ";")
+	res := make([]*UDF, 0, len(vals))
+
+	for _, udfInfo := range vals {
+		if strings.Trim(udfInfo, " ") == "" {
+			continue
+		}
+		udfParts := strings.Split(udfInfo, ",")
+
+		udf := &UDF{}
+		for _, values := range udfParts {
+			valueParts := strings.Split(values, "=")
+			if len(valueParts) == 2 {
+				switch valueParts[0] {
+				case "filename":
+					udf.Filename = valueParts[1]
+				case "hash":
+					udf.Hash = valueParts[1]
+				case "type":
+					udf.Language = Language(valueParts[1])
+				}
+			}
+		}
+		res = append(res, udf)
+	}
+
+	return res
+}
+
+// readUDFDir reads the UDF packages of the language in the directory, keyed by their file name.
+// Subdirectories are not read.
+func readUDFDir(clientDir string, language Language) ([]string, map[string][]byte, Error) {
+	entries, err := os.ReadDir(clientDir)
+	if err != nil {
+		return nil, nil, newCommonError(err)
+	}
+
+	ext := "." + strings.ToLower(string(language))
+	names := make([]string, 0, len(entries))
+	bodies := make(map[string][]byte, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ext) {
+			continue
+		}
+
+		body, err := os.ReadFile(filepath.Join(clientDir, entry.Name()))
+		if err != nil {
+			return nil, nil, newCommonError(err)
+		}
+		names = append(names, entry.Name())
+		bodies[entry.Name()] = body
+	}
+	sort.Strings(names)
+
+	return names, bodies, nil
 }
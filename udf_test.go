@@ -19,6 +19,8 @@ package aerospike_test
 import (
 	"math"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -180,6 +182,30 @@ Message: syntax error near 'returned'`))
 		gm.Expect(len(udfList)).To(gm.BeNumerically(">", 0))
 	})
 
+	gg.It("must register a directory of udfs and list their nodes", func() {
+		dir := gg.GinkgoT().TempDir()
+		gm.Expect(os.WriteFile(filepath.Join(dir, "udfDir1.lua"), []byte(udfBody), 0644)).ToNot(gm.HaveOccurred())
+		gm.Expect(os.WriteFile(filepath.Join(dir, "udfDir2.lua"), []byte(udfEcho), 0644)).ToNot(gm.HaveOccurred())
+		gm.Expect(os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a udf"), 0644)).ToNot(gm.HaveOccurred())
+
+		regTask, err := client.RegisterUDFDir(wpolicy, dir, as.LUA)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(<-regTask.OnComplete()).NotTo(gm.HaveOccurred())
+
+		udfList, err := client.ListUDF(nil)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		found := 0
+		for _, udf := range udfList {
+			if udf.Filename == "udfDir1.lua" || udf.Filename == "udfDir2.lua" {
+				gm.Expect(udf.Nodes).To(gm.HaveLen(len(client.GetNodes())))
+				gm.Expect(udf.Language).To(gm.Equal(as.LUA))
+				found++
+			}
+		}
+		gm.Expect(found).To(gm.Equal(2))
+	})
+
 	gg.It("must drop a udf on the server", func() {
 		registerUDF(udfBody, "udfToBeDropped.lua")
 
@@ -326,6 +352,9 @@ Message: syntax error near 'returned'`))
 		}) // it
 
 		gg.It("must serialize list values to echo function and get the same value back", func() {
+			// the list stores a uint64 value beyond math.MaxInt64
+			as.SetValuePolicy(&as.ValuePolicy{IntegerOverflow: as.IntegerOverflowWrap})
+			defer as.SetValuePolicy(nil)
 
 			v := []interface{}{
 				nil,
@@ -125,14 +125,40 @@ func (upckr *unpacker) UnpackMap() (interface{}, Error) {
 }
 
 func (upckr *unpacker) unpackMap(count int) (interface{}, Error) {
+	policy := currentValuePolicy()
 	if count <= 0 {
+		if policy.DecodeStringKeyedMaps {
+			return map[string]interface{}{}, nil
+		}
 		return make(map[interface{}]interface{}), nil
 	}
 
 	if upckr.isMapCDT() {
-		return upckr.unpackCDTMap(count)
+		pairs, err := upckr.unpackCDTMap(count)
+		if err != nil || !policy.DecodeOrderedMaps {
+			return pairs, err
+		}
+		return OrderedMap(pairs), nil
+	}
+
+	res, err := upckr.unpackMapNormal(count)
+	if err != nil || !policy.DecodeStringKeyedMaps {
+		return res, err
+	}
+	return stringKeyedMap(res), nil
+}
+
+// stringKeyedMap converts the map to a map[string]interface{} if all its keys are strings.
+func stringKeyedMap(m map[interface{}]interface{}) interface{} {
+	res := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		sk, ok := k.(string)
+		if !ok {
+			return m
+		}
+		res[sk] = v
 	}
-	return upckr.unpackMapNormal(count)
+	return res
 }
 
 func (upckr *unpacker) unpackMapNormal(count int) (map[interface{}]interface{}, Error) {
@@ -243,6 +269,29 @@ func (upckr *unpacker) unpackBlob(count int, isMapKey bool) (interface{}, Error)
 // errSkipHeader is used internally as a signal; it is never sent back to the user
 var errSkipHeader = newError(types.OK, "Skip the unpacker error")
 
+// unpackExt unpacks a type extension of count bytes. Extensions are skipped, unless
+// ValuePolicy.DecodeExtensions is set. The collection flags headers, which are
+// extensions without payload, are always skipped.
+func (upckr *unpacker) unpackExt(count int) (interface{}, Error) {
+	typ := upckr.buffer[upckr.offset]
+	data := upckr.buffer[upckr.offset+1 : upckr.offset+1+count]
+	upckr.offset += 1 + count
+
+	if count == 0 || !currentValuePolicy().DecodeExtensions {
+		return nil, errSkipHeader
+	}
+
+	if typ == 0xff && count == 1 {
+		switch data[0] {
+		case 0x00:
+			return wildCardValue, nil
+		case 0x01:
+			return infinityValue, nil
+		}
+	}
+	return NewExtValue(typ, append([]byte(nil), data...)), nil
+}
+
 func (upckr *unpacker) unpackObject(isMapKey bool) (interface{}, Error) {
 	theType := upckr.buffer[upckr.offset] & 0xff
 	upckr.offset++
@@ -354,44 +403,39 @@ func (upckr *unpacker) unpackObject(isMapKey bool) (interface{}, Error) {
 		return upckr.unpackMap(count)
 
 	case 0xd4:
-		// Skip over type extension with 1 byte
-		upckr.offset += 1 + 1
-		return nil, errSkipHeader
+		// type extension with 1 byte
+		return upckr.unpackExt(1)
 
 	case 0xd5:
-		// Skip over type extension with 2 bytes
-		upckr.offset += 1 + 2
-		return nil, errSkipHeader
+		// type extension with 2 bytes
+		return upckr.unpackExt(2)
 
 	case 0xd6:
-		// Skip over type extension with 4 bytes
-		upckr.offset += 1 + 4
-		return nil, errSkipHeader
+		// type extension with 4 bytes
+		return upckr.unpackExt(4)
 
 	case 0xd7:
-		// Skip over type extension with 8 bytes
-		upckr.offset += 1 + 8
-		return nil, errSkipHeader
+		// type extension with 8 bytes
+		return upckr.unpackExt(8)
 
 	case 0xd8:
-		// Skip over type extension with 16 bytes
-		upckr.offset += 1 + 16
-		return nil, errSkipHeader
+		// type extension with 16 bytes
+		return upckr.unpackExt(16)
 
-	case 0xc7: // Skip over type extension with 8 bit header and bytes
+	case 0xc7: // type extension with 8 bit header and bytes
 		count := int(upckr.buffer[upckr.offset] & 0xff)
-		upckr.offset += count + 1 + 1
-		return nil, errSkipHeader
+		upckr.offset++
+		return upckr.unpackExt(count)
 
-	case 0xc8: // Skip over type extension with 16 bit header and bytes
-		count := int(Buffer.BytesToInt16(upckr.buffer, upckr.offset))
-		upckr.offset += count + 1 + 2
-		return nil, errSkipHeader
+	case 0xc8: // type extension with 16 bit header and bytes
+		count := int(Buffer.BytesToUint16(upckr.buffer, upckr.offset))
+		upckr.offset += 2
+		return upckr.unpackExt(count)
 
-	case 0xc9: // Skip over type extension with 32 bit header and bytes
-		count := int(Buffer.BytesToInt32(upckr.buffer, upckr.offset))
-		upckr.offset += count + 1 + 4
-		return nil, errSkipHeader
+	case 0xc9: // type extension with 32 bit header and bytes
+		count := int(Buffer.BytesToUint32(upckr.buffer, upckr.offset))
+		upckr.offset += 4
+		return upckr.unpackExt(count)
 
 	default:
 		if (theType & 0xe0) == 0xa0 {
@@ -16,8 +16,10 @@ package aerospike
 
 import (
 	"fmt"
+	"math/big"
 	"reflect"
 	"strconv"
+	"time"
 
 	"github.com/aerospike/aerospike-client-go/v7/types"
 	ParticleType "github.com/aerospike/aerospike-client-go/v7/types/particle_type"
@@ -108,10 +110,15 @@ func tryConcreteValue(v interface{}) Value {
 	case float64:
 		return FloatValue(val)
 	case uint:
-		// if it doesn't overflow int64, it is OK
-		if int64(val) >= 0 {
-			return LongValue(int64(val))
-		}
+		return NewUint64Value(uint64(val))
+	case uint64:
+		return NewUint64Value(val)
+	case time.Time:
+		return NewTimeValue(val)
+	case time.Duration:
+		return NewDurationValue(val)
+	case *big.Int:
+		return NewBigIntValue(val)
 	case bool:
 		return BoolValue(val)
 	case MapIter:
@@ -572,6 +579,53 @@ func (vl WildCardValue) String() string {
 
 ///////////////////////////////////////////////////////////////////////////////
 
+// ExtValue is a raw MessagePack extension value, for the server particle types that
+// the client does not support natively yet. It can only be used inside lists, maps
+// and CDT operations. The extensions without payload are reserved for the collection flags,
+// and the ext type 0xff for wildcard and infinity.
+// Reads return the extension values as ExtValue when ValuePolicy.DecodeExtensions is set.
+type ExtValue struct {
+	// Type is the MessagePack extension type.
+	Type byte
+
+	// Data is the payload of the extension.
+	Data []byte
+}
+
+// NewExtValue generates an ExtValue instance.
+func NewExtValue(typ byte, data []byte) ExtValue {
+	return ExtValue{Type: typ, Data: data}
+}
+
+// EstimateSize returns the size of the ExtValue in wire protocol.
+func (vl ExtValue) EstimateSize() (int, Error) {
+	return 0, nil
+}
+
+func (vl ExtValue) write(cmd BufferEx) (int, Error) {
+	return 0, nil
+}
+
+func (vl ExtValue) pack(cmd BufferEx) (int, Error) {
+	return packExt(cmd, vl.Type, vl.Data)
+}
+
+// GetType returns wire protocol value type.
+func (vl ExtValue) GetType() int {
+	panic("Invalid particle type: EXT")
+}
+
+// GetObject returns original value as an interface{}.
+func (vl ExtValue) GetObject() interface{} {
+	return vl
+}
+
+func (vl ExtValue) String() string {
+	return fmt.Sprintf("ext(%d, %v)", vl.Type, vl.Data)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
 // BytesValue encapsulates an array of bytes.
 type BytesValue []byte
 
@@ -1240,6 +1294,12 @@ func bytesToParticle(ptype int, buf []byte, offset int, length int) (interface{}
 		return HLLValue(newObj), nil
 
 	case ParticleType.BLOB:
+		if b := buf[offset : offset+length]; isCompressedValue(b) {
+			if res, ok, err := decompressValue(b); ok || err != nil {
+				return res, err
+			}
+		}
+
 		newObj := make([]byte, length)
 		copy(newObj, buf[offset:offset+length])
 		return newObj, nil
@@ -0,0 +1,149 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+	ParticleType "github.com/aerospike/aerospike-client-go/v7/types/particle_type"
+)
+
+// NewTimeValue generates a value for a time.Time, encoded according to the
+// TimeEncoding of the ValuePolicy.
+func NewTimeValue(t time.Time) Value {
+	if currentValuePolicy().TimeEncoding == TimeAsString {
+		return StringValue(t.Format(time.RFC3339Nano))
+	}
+	return LongValue(t.UnixNano())
+}
+
+// NewDurationValue generates a value for a time.Duration, stored as int64 nanoseconds.
+func NewDurationValue(d time.Duration) Value {
+	return LongValue(int64(d))
+}
+
+// NewUint64Value generates a value for an uint64. Values beyond math.MaxInt64 are
+// stored according to the IntegerOverflow mode of the ValuePolicy.
+func NewUint64Value(v uint64) Value {
+	if v <= math.MaxInt64 {
+		return LongValue(int64(v))
+	}
+
+	switch currentValuePolicy().IntegerOverflow {
+	case IntegerOverflowWrap:
+		return LongValue(int64(v))
+	case IntegerOverflowString:
+		return StringValue(strconv.FormatUint(v, 10))
+	default:
+		return newOverflowValue(strconv.FormatUint(v, 10))
+	}
+}
+
+// NewBigIntValue generates a value for a big.Int. Values that do not fit in an int64 are
+// stored according to the IntegerOverflow mode of the ValuePolicy. A nil value is stored as nil.
+func NewBigIntValue(v *big.Int) Value {
+	switch {
+	case v == nil:
+		return nullValue
+	case v.IsInt64():
+		return LongValue(v.Int64())
+	case v.IsUint64():
+		return NewUint64Value(v.Uint64())
+	case currentValuePolicy().IntegerOverflow == IntegerOverflowString:
+		return StringValue(v.String())
+	default:
+		return newOverflowValue(v.String())
+	}
+}
+
+// timeToInterface converts the time for the reflection API.
+func timeToInterface(t time.Time) interface{} {
+	if currentValuePolicy().TimeEncoding == TimeAsString {
+		return t.Format(time.RFC3339Nano)
+	}
+	return t.UnixNano()
+}
+
+// timeFromInterface converts a time read from the database, stored in either encoding.
+func timeFromInterface(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case int:
+		return time.Unix(0, int64(v)), true
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
+
+// bigIntFromInterface converts an integer read from the database, stored
+// as either an int64 or a decimal string.
+func bigIntFromInterface(value interface{}) (*big.Int, bool) {
+	switch v := value.(type) {
+	case int:
+		return big.NewInt(int64(v)), true
+	case string:
+		return new(big.Int).SetString(v, 10)
+	}
+	return nil, false
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// overflowValue is an integer value that does not fit in an int64, and fails the command
+// when it is serialized. It allows NewValue to report the overflow without panicking.
+type overflowValue struct {
+	value string
+}
+
+func newOverflowValue(value string) overflowValue {
+	return overflowValue{value: value}
+}
+
+func (vl overflowValue) err() Error {
+	return newError(types.PARAMETER_ERROR, fmt.Sprintf("Integer value %s overflows int64. Set ValuePolicy.IntegerOverflow to store it.", vl.value))
+}
+
+// EstimateSize returns the overflow error.
+func (vl overflowValue) EstimateSize() (int, Error) {
+	return 0, vl.err()
+}
+
+func (vl overflowValue) write(cmd BufferEx) (int, Error) {
+	return 0, vl.err()
+}
+
+func (vl overflowValue) pack(cmd BufferEx) (int, Error) {
+	return 0, vl.err()
+}
+
+// GetType returns wire protocol value type.
+func (vl overflowValue) GetType() int {
+	return ParticleType.NULL
+}
+
+// GetObject returns the decimal representation of the value.
+func (vl overflowValue) GetObject() interface{} {
+	return vl.value
+}
+
+func (vl overflowValue) String() string {
+	return vl.value
+}
@@ -0,0 +1,144 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Native value conversions", func() {
+
+	gg.AfterEach(func() {
+		SetValuePolicy(nil)
+	})
+
+	overflows := func(v Value) {
+		_, err := v.EstimateSize()
+		gm.Expect(err).To(gm.HaveOccurred())
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+	}
+
+	gg.It("must convert time.Time values according to the policy", func() {
+		tm := time.Date(2024, 2, 29, 12, 30, 0, 123456789, time.FixedZone("X", 3600))
+		gm.Expect(NewValue(tm)).To(gm.Equal(LongValue(tm.UnixNano())))
+
+		SetValuePolicy(&ValuePolicy{TimeEncoding: TimeAsString})
+		gm.Expect(NewValue(tm)).To(gm.Equal(StringValue("2024-02-29T12:30:00.123456789+01:00")))
+
+		res, ok := timeFromInterface("2024-02-29T12:30:00.123456789+01:00")
+		gm.Expect(ok).To(gm.BeTrue())
+		gm.Expect(res.Equal(tm)).To(gm.BeTrue())
+	})
+
+	gg.It("must convert time.Duration values to nanoseconds", func() {
+		gm.Expect(NewValue(1500 * time.Millisecond)).To(gm.Equal(LongValue(1500000000)))
+	})
+
+	gg.It("must convert unsigned values according to the overflow mode", func() {
+		gm.Expect(NewValue(uint64(42))).To(gm.Equal(LongValue(42)))
+		gm.Expect(NewValue(uint64(math.MaxInt64))).To(gm.Equal(LongValue(math.MaxInt64)))
+		overflows(NewValue(uint64(math.MaxUint64)))
+		overflows(NewValue(uint(math.MaxInt64 + 1)))
+
+		SetValuePolicy(&ValuePolicy{IntegerOverflow: IntegerOverflowWrap})
+		gm.Expect(NewValue(uint64(math.MaxUint64))).To(gm.Equal(LongValue(-1)))
+		gm.Expect(NewValue(uint(math.MaxInt64 + 1))).To(gm.Equal(LongValue(math.MinInt64)))
+
+		SetValuePolicy(&ValuePolicy{IntegerOverflow: IntegerOverflowString})
+		gm.Expect(NewValue(uint64(math.MaxUint64))).To(gm.Equal(StringValue("18446744073709551615")))
+	})
+
+	gg.It("must pack the unsigned values inside the lists and maps according to the overflow mode", func() {
+		pack := func(v interface{}) ([]byte, Error) {
+			size, err := packObject(nil, v, false)
+			if err != nil {
+				return nil, err
+			}
+			buf := newPacker()
+			_, err = packObject(buf, v, false)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(buf.Bytes()).To(gm.HaveLen(size))
+			return buf.Bytes(), nil
+		}
+		expect := func(v interface{}, want Value) {
+			res, err := pack(v)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			exp, err := pack([]interface{}{want})
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(res).To(gm.Equal(exp))
+		}
+
+		values := []interface{}{
+			[]interface{}{uint64(math.MaxUint64)},
+			NewValue([]uint64{math.MaxUint64}),
+			NewValue(map[string]uint64{"u": math.MaxUint64}),
+		}
+		for _, v := range values {
+			_, err := pack(v)
+			gm.Expect(err).To(gm.HaveOccurred())
+			gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+		}
+
+		_, err := pack([]interface{}{uint64(math.MaxInt64)})
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		SetValuePolicy(&ValuePolicy{IntegerOverflow: IntegerOverflowWrap})
+		expect(values[0], LongValue(-1))
+		expect(values[1], LongValue(-1))
+
+		SetValuePolicy(&ValuePolicy{IntegerOverflow: IntegerOverflowString})
+		expect(values[0], StringValue("18446744073709551615"))
+		expect(values[1], StringValue("18446744073709551615"))
+	})
+
+	gg.It("must convert big.Int values according to the overflow mode", func() {
+		huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+		gm.Expect(NewValue(big.NewInt(-7))).To(gm.Equal(LongValue(-7)))
+		gm.Expect(NewValue((*big.Int)(nil))).To(gm.Equal(nullValue))
+		overflows(NewValue(new(big.Int).SetUint64(math.MaxUint64)))
+		overflows(NewValue(huge))
+
+		SetValuePolicy(&ValuePolicy{IntegerOverflow: IntegerOverflowWrap})
+		gm.Expect(NewValue(new(big.Int).SetUint64(math.MaxUint64))).To(gm.Equal(LongValue(-1)))
+		overflows(NewValue(huge))
+
+		SetValuePolicy(&ValuePolicy{IntegerOverflow: IntegerOverflowString})
+		gm.Expect(NewValue(huge)).To(gm.Equal(StringValue("123456789012345678901234567890")))
+
+		res, ok := bigIntFromInterface("123456789012345678901234567890")
+		gm.Expect(ok).To(gm.BeTrue())
+		gm.Expect(res.Cmp(huge)).To(gm.Equal(0))
+	})
+
+	gg.It("must fail the commands with overflowing values by default", func() {
+		key, _ := NewKey("test", "set", 1)
+		cmd, err := newWriteCommand(nil, NewWritePolicy(0, 0), key, []*Bin{NewBin("u", uint64(math.MaxUint64))}, nil, _WRITE)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		err = cmd.writeBuffer(&cmd)
+		gm.Expect(err).To(gm.HaveOccurred())
+		gm.Expect(err.Matches(types.PARAMETER_ERROR)).To(gm.BeTrue())
+
+		_, err = packObject(newPacker(), []interface{}{uint64(math.MaxUint64), new(big.Int).Lsh(big.NewInt(1), 70)}, false)
+		gm.Expect(err).To(gm.HaveOccurred())
+	})
+})
@@ -0,0 +1,157 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+)
+
+// valueTypeOrder returns the rank of the value type in the server sort order of values.
+func valueTypeOrder(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 1
+	case bool:
+		return 2
+	case int, int64:
+		return 3
+	case string:
+		return 4
+	case []interface{}:
+		return 5
+	case map[interface{}]interface{}, []MapPair:
+		return 6
+	case []byte, HLLValue:
+		return 7
+	case float64:
+		return 8
+	case GeoJSONValue:
+		return 9
+	default:
+		if reflect.TypeOf(v).Kind() == reflect.Array {
+			return 7
+		}
+		return 50
+	}
+}
+
+// compareValues compares the values decoded from the server in the server sort order of values.
+func compareValues(a, b interface{}) int {
+	ta, tb := valueTypeOrder(a), valueTypeOrder(b)
+	if ta != tb {
+		return compareOrdered(ta, tb)
+	}
+
+	switch ta {
+	case 2:
+		x, y := a.(bool), b.(bool)
+		if x == y {
+			return 0
+		} else if !x {
+			return -1
+		}
+		return 1
+	case 3:
+		return compareOrdered(valueInt(a), valueInt(b))
+	case 4:
+		return compareOrdered(a.(string), b.(string))
+	case 5:
+		x, y := a.([]interface{}), b.([]interface{})
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if c := compareValues(x[i], y[i]); c != 0 {
+				return c
+			}
+		}
+		return compareOrdered(len(x), len(y))
+	case 6:
+		x, y := sortedMapPairs(a), sortedMapPairs(b)
+		if c := compareOrdered(len(x), len(y)); c != 0 {
+			return c
+		}
+		for i := range x {
+			if c := compareValues(x[i].Key, y[i].Key); c != 0 {
+				return c
+			}
+			if c := compareValues(x[i].Value, y[i].Value); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case 7:
+		return bytes.Compare(valueBytes(a), valueBytes(b))
+	case 8:
+		return compareOrdered(a.(float64), b.(float64))
+	case 9:
+		return compareOrdered(string(a.(GeoJSONValue)), string(b.(GeoJSONValue)))
+	}
+	return 0
+}
+
+func compareOrdered[T int | int64 | float64 | string](a, b T) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func valueInt(v interface{}) int64 {
+	if i, ok := v.(int); ok {
+		return int64(i)
+	}
+	return v.(int64)
+}
+
+func valueBytes(v interface{}) []byte {
+	switch v := v.(type) {
+	case []byte:
+		return v
+	case HLLValue:
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	b := make([]byte, rv.Len())
+	reflect.Copy(reflect.ValueOf(b), rv)
+	return b
+}
+
+// sortedMapPairs returns the entries of the map sorted by key.
+func sortedMapPairs(v interface{}) []MapPair {
+	var res []MapPair
+	switch v := v.(type) {
+	case []MapPair:
+		res = append(make([]MapPair, 0, len(v)), v...)
+	case map[interface{}]interface{}:
+		res = make([]MapPair, 0, len(v))
+		for k, e := range v {
+			res = append(res, MapPair{Key: k, Value: e})
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool { return compareValues(res[i].Key, res[j].Key) < 0 })
+	return res
+}
+
+// valueMapKey converts the value to a key of a Go map. Byte slices are converted to arrays.
+func valueMapKey(v interface{}) interface{} {
+	if b, ok := v.([]byte); ok {
+		arr := reflect.Indirect(reflect.New(reflect.ArrayOf(len(b), reflect.TypeOf(byte(0)))))
+		reflect.Copy(arr, reflect.ValueOf(b))
+		return arr.Interface()
+	}
+	return v
+}
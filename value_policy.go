@@ -0,0 +1,110 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	iatomic "github.com/aerospike/aerospike-client-go/v7/internal/atomic"
+)
+
+// TimeEncoding determines how time.Time values are stored in the database.
+type TimeEncoding int
+
+const (
+	// TimeAsUnixNano stores time.Time values as int64 nanoseconds since the Unix epoch.
+	TimeAsUnixNano TimeEncoding = iota
+
+	// TimeAsString stores time.Time values as RFC 3339 strings with nanosecond precision.
+	// The location of the value is preserved as its offset from UTC.
+	TimeAsString
+)
+
+// IntegerOverflowMode determines how integer values that do not fit in the
+// int64 integers of the database are stored.
+type IntegerOverflowMode int
+
+const (
+	// IntegerOverflowError fails the command with a PARAMETER_ERROR when a value does not fit in an int64.
+	IntegerOverflowError IntegerOverflowMode = iota
+
+	// IntegerOverflowWrap stores unsigned values beyond math.MaxInt64 as their two's complement
+	// int64 representation, which is how the client stored them before the ValuePolicy.
+	// The values are read back as negative integers, and can be converted back to uint64.
+	// big.Int values beyond the range of uint64 fail with a PARAMETER_ERROR.
+	IntegerOverflowWrap
+
+	// IntegerOverflowString stores the values that do not fit in an int64 as decimal strings.
+	IntegerOverflowString
+)
+
+// ValuePolicy determines how the Go types without a native counterpart in the database
+// are converted to values, and how the maps read from the database are decoded.
+// It applies to NewValue, the bins, the CDT operations and the reflection API alike.
+type ValuePolicy struct {
+	// TimeEncoding determines how time.Time values are stored.
+	// time.Duration values are always stored as int64 nanoseconds.
+	//
+	// Default: TimeAsUnixNano
+	TimeEncoding TimeEncoding
+
+	// IntegerOverflow determines how the uint, uint64 and big.Int values that overflow int64 are stored,
+	// both in the bins and inside the lists and maps.
+	//
+	// Default: IntegerOverflowError
+	IntegerOverflow IntegerOverflowMode
+
+	// DecodeStringKeyedMaps decodes the maps whose keys are all strings into map[string]interface{}
+	// instead of map[interface{}]interface{}, which can be directly encoded to JSON.
+	// Maps with other key types are still decoded into map[interface{}]interface{}.
+	//
+	// Default: false
+	DecodeStringKeyedMaps bool
+
+	// DecodeOrderedMaps decodes the key ordered maps, and the map results where the
+	// order needs to be preserved, into OrderedMap instead of []MapPair.
+	//
+	// Default: false
+	DecodeOrderedMaps bool
+
+	// DecodeExtensions decodes the MessagePack extensions inside the lists and maps into
+	// WildCardValue, InfinityValue or ExtValue for the other extension types, instead of skipping them.
+	// This allows reading the server particle types that the client does not support natively yet.
+	//
+	// Default: false
+	DecodeExtensions bool
+}
+
+// NewValuePolicy returns the default value policy.
+func NewValuePolicy() *ValuePolicy {
+	return &ValuePolicy{
+		TimeEncoding:    TimeAsUnixNano,
+		IntegerOverflow: IntegerOverflowError,
+	}
+}
+
+var valuePolicy iatomic.TypedVal[ValuePolicy]
+
+// SetValuePolicy sets the policy used to convert the Go values for all clients.
+// Passing nil restores the default policy.
+func SetValuePolicy(policy *ValuePolicy) {
+	if policy == nil {
+		policy = NewValuePolicy()
+	}
+	valuePolicy.Set(*policy)
+}
+
+// currentValuePolicy returns the value policy in effect. The zero value is the default policy.
+func currentValuePolicy() ValuePolicy {
+	return valuePolicy.Get()
+}
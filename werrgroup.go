@@ -16,20 +16,35 @@ package aerospike
 
 import (
 	"context"
+	"errors"
 	"sync"
 
 	"golang.org/x/sync/semaphore"
 
-	"github.com/aerospike/aerospike-client-go/v7/logger"
+	"github.com/aerospike/aerospike-client-go/v7/internal/atomic"
+	"github.com/aerospike/aerospike-client-go/v7/types"
 )
 
 type werrGroup struct {
 	sem  *semaphore.Weighted
 	ctx  context.Context
 	wg   sync.WaitGroup
-	el   sync.Mutex
 	errs Error
 
+	// the commands push their errors here without contending on a lock;
+	// they are collected in wait.
+	errQueue *atomic.MPSCQueue[Error]
+
+	// the errors of the commands, in the order they occurred
+	errList []Error
+
+	// shared worker pool; limits the concurrency across all groups using it
+	pool *semaphore.Weighted
+
+	// if set, the context will be cancelled on the first error,
+	// and the commands that have not started yet will not be executed.
+	cancel context.CancelFunc
+
 	// function to defer; used for recordset signals
 	f func()
 }
@@ -40,34 +55,93 @@ func newWeightedErrGroup(maxConcurrency int) *werrGroup {
 	}
 
 	return &werrGroup{
-		sem: semaphore.NewWeighted(int64(maxConcurrency)),
-		ctx: context.Background(),
+		sem:      semaphore.NewWeighted(int64(maxConcurrency)),
+		ctx:      context.Background(),
+		errQueue: atomic.NewMPSCQueue[Error](),
 	}
 }
 
-func (weg *werrGroup) execute(cmd command) {
-	weg.wg.Add(1)
+// withPool bounds the concurrency of the group by the shared worker pool as well.
+// The commands of the group must not run other groups on the same pool: a command waiting
+// for the slots held by its parents can starve the pool. This is why the batch commands
+// run their retries inline; see batchCommand.retryBatch.
+func (weg *werrGroup) withPool(pool *semaphore.Weighted) *werrGroup {
+	weg.pool = pool
+	return weg
+}
 
-	if err := weg.sem.Acquire(weg.ctx, 1); err != nil {
-		logger.Logger.Error("Constraint Semaphore failed: %s", err.Error())
+// withAbortOnError makes the group stop executing new commands after the first error.
+// The returned context is cancelled at that point, and can be used by the in-flight
+// commands to abort their execution.
+func (weg *werrGroup) withAbortOnError() context.Context {
+	weg.ctx, weg.cancel = context.WithCancel(weg.ctx)
+	return weg.ctx
+}
+
+func (weg *werrGroup) execute(cmd command) {
+	if err := weg.acquire(); err != nil {
+		// the error that aborted the group is already recorded
+		if weg.cancel == nil || weg.ctx.Err() == nil {
+			weg.errQueue.Push(newErrorAndWrap(err, types.COMMON_ERROR, "Failed to acquire a worker for the command"))
+		}
+		return
 	}
 
+	weg.wg.Add(1)
 	go func() {
 		defer weg.sem.Release(1)
+		if weg.pool != nil {
+			defer weg.pool.Release(1)
+		}
 		defer weg.wg.Done()
 		if weg.f != nil {
 			defer weg.f()
 		}
 
 		if err := cmd.Execute(); err != nil {
-			weg.el.Lock()
-			weg.errs = chainErrors(err, weg.errs)
-			weg.el.Unlock()
+			// errors caused by the abort itself are not relevant for the user
+			if weg.cancel == nil || weg.ctx.Err() == nil || !errors.Is(err, ErrBatchAborted) {
+				weg.errQueue.Push(err)
+			}
+
+			if weg.cancel != nil {
+				weg.cancel()
+			}
 		}
 	}()
 }
 
+// acquire takes a slot of the group, and of the shared worker pool if set.
+func (weg *werrGroup) acquire() error {
+	if err := weg.sem.Acquire(weg.ctx, 1); err != nil {
+		return err
+	}
+
+	if weg.pool != nil {
+		if err := weg.pool.Acquire(weg.ctx, 1); err != nil {
+			weg.sem.Release(1)
+			return err
+		}
+	}
+	return nil
+}
+
 func (weg *werrGroup) wait() Error {
 	weg.wg.Wait()
+	if weg.cancel != nil {
+		weg.cancel()
+	}
+
+	// all the commands are done, so the queue holds all of their errors
+	weg.errQueue.Drain(func(err Error) {
+		weg.errs = chainErrors(err, weg.errs)
+		weg.errList = append(weg.errList, err)
+	})
 	return weg.errs
 }
+
+// errors returns the errors of the commands, in the order they occurred.
+// It must only be called after wait.
+func (weg *werrGroup) errors() []Error {
+	return weg.errList
+}
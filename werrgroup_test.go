@@ -0,0 +1,51 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"context"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Weighted error group tests", func() {
+
+	gg.It("must report the commands that could not acquire a worker", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		weg := newWeightedErrGroup(1)
+		weg.ctx = ctx
+		weg.execute(&batchCommand{})
+
+		err := weg.wait()
+		gm.Expect(err).To(gm.HaveOccurred())
+		gm.Expect(err.Matches(types.COMMON_ERROR)).To(gm.BeTrue())
+		gm.Expect(weg.errors()).To(gm.HaveLen(1))
+	})
+
+	gg.It("must not report the commands skipped after an abort", func() {
+		weg := newWeightedErrGroup(1)
+		weg.withAbortOnError()
+		weg.cancel()
+		weg.execute(&batchCommand{})
+
+		gm.Expect(weg.wait()).ToNot(gm.HaveOccurred())
+		gm.Expect(weg.errors()).To(gm.BeEmpty())
+	})
+})
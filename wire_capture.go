@@ -0,0 +1,189 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	iatomic "github.com/aerospike/aerospike-client-go/v7/internal/atomic"
+)
+
+// WireCapture is the raw wire protocol exchange of a sampled command with a node.
+// Use DecodeWireMessages to decode the headers and the operations of the buffers.
+type WireCapture struct {
+	// Time is when the request was sent.
+	Time time.Time
+
+	// Command is the type of the command, e.g. "get" or "put".
+	Command string
+
+	// Node is the node the command was sent to.
+	Node string
+
+	// Request is the buffer sent to the node. The credentials in the admin messages are redacted.
+	Request []byte
+
+	// Response is the buffer read from the node; compressed responses are captured inflated.
+	Response []byte
+
+	// Truncated is set if the request or the response exceeded WireCapturePolicy.MaxBytes.
+	Truncated bool
+
+	// Err is the error of the command, if any.
+	Err Error
+}
+
+// String returns the decoded headers and operations of the buffers, followed by their hex dump.
+func (wc *WireCapture) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s on %s", wc.Time.Format(time.RFC3339Nano), wc.Command, wc.Node)
+	if wc.Truncated {
+		sb.WriteString(" (truncated)")
+	}
+	if wc.Err != nil {
+		fmt.Fprintf(&sb, ": %s", wc.Err.Error())
+	}
+	sb.WriteByte('\n')
+
+	dump := func(name string, buf []byte) {
+		fmt.Fprintf(&sb, "%s (%d bytes):\n", name, len(buf))
+		msgs, err := DecodeWireMessages(buf)
+		for i := range msgs {
+			sb.WriteString(msgs[i].String())
+		}
+		if err != nil {
+			fmt.Fprintf(&sb, "  undecodable: %s\n", err.Error())
+		}
+		sb.WriteString(hex.Dump(buf))
+	}
+	dump("request", wc.Request)
+	dump("response", wc.Response)
+	return sb.String()
+}
+
+// wireCapturer samples the commands and keeps their captures in a ring buffer.
+type wireCapturer struct {
+	policy  WireCapturePolicy
+	counter iatomic.Int
+
+	mutex    sync.Mutex
+	captures []WireCapture
+	next     int
+	full     bool
+}
+
+func newWireCapturer(policy WireCapturePolicy) *wireCapturer {
+	if policy.SampleInterval <= 0 {
+		policy.SampleInterval = 1
+	}
+	if policy.Capacity <= 0 {
+		policy.Capacity = 1
+	}
+	if policy.MaxBytes <= 0 {
+		policy.MaxBytes = NewWireCapturePolicy().MaxBytes
+	}
+
+	return &wireCapturer{
+		policy:   policy,
+		captures: make([]WireCapture, policy.Capacity),
+	}
+}
+
+// sample returns true if the next command should be captured.
+func (wc *wireCapturer) sample() bool {
+	return wc != nil && (wc.counter.IncrementAndGet()-1)%wc.policy.SampleInterval == 0
+}
+
+// start returns a new capture of a sampled command.
+func (wc *wireCapturer) start(command, node string, request []byte) *wireCaptureRecorder {
+	rec := &wireCaptureRecorder{
+		capturer: wc,
+		capture: WireCapture{
+			Time:    time.Now(),
+			Command: command,
+			Node:    node,
+		},
+	}
+	rec.capture.Request = rec.append(nil, request)
+	redactWire(rec.capture.Request)
+	return rec
+}
+
+// add stores the capture in the ring buffer, and writes it to the writer of the policy.
+func (wc *wireCapturer) add(capture WireCapture) {
+	wc.mutex.Lock()
+	defer wc.mutex.Unlock()
+
+	wc.captures[wc.next] = capture
+	wc.next = (wc.next + 1) % len(wc.captures)
+	if wc.next == 0 {
+		wc.full = true
+	}
+
+	if wc.policy.Writer != nil {
+		wc.policy.Writer.Write([]byte(capture.String()))
+	}
+}
+
+// report returns the captures in the ring buffer, the oldest first.
+func (wc *wireCapturer) report() []WireCapture {
+	wc.mutex.Lock()
+	defer wc.mutex.Unlock()
+
+	if !wc.full {
+		return append([]WireCapture(nil), wc.captures[:wc.next]...)
+	}
+
+	res := make([]WireCapture, 0, len(wc.captures))
+	res = append(res, wc.captures[wc.next:]...)
+	return append(res, wc.captures[:wc.next]...)
+}
+
+// wireCaptureRecorder collects the response of a sampled command as it is read from the connection.
+type wireCaptureRecorder struct {
+	capturer *wireCapturer
+	capture  WireCapture
+}
+
+// append appends the bytes to the buffer up to the MaxBytes of the policy.
+func (rec *wireCaptureRecorder) append(buf, b []byte) []byte {
+	if room := rec.capturer.policy.MaxBytes - len(buf); len(b) > room {
+		rec.capture.Truncated = true
+		if room <= 0 {
+			return buf
+		}
+		b = b[:room]
+	}
+	return append(buf, b...)
+}
+
+// read records the bytes read from the connection.
+func (rec *wireCaptureRecorder) read(b []byte) {
+	if rec != nil {
+		rec.capture.Response = rec.append(rec.capture.Response, b)
+	}
+}
+
+// finish stores the capture with the error of the command.
+func (rec *wireCaptureRecorder) finish(err Error) {
+	if rec != nil {
+		rec.capture.Err = err
+		rec.capturer.add(rec.capture)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"io"
+)
+
+// WireCapturePolicy determines how the client captures the raw wire protocol buffers of the
+// commands for debugging. See ClientPolicy.WireCapturePolicy and Client.WireCaptures.
+type WireCapturePolicy struct {
+	// SampleInterval is the number of commands per captured command.
+	// Set it to 1 to capture all commands.
+	//
+	// Default: 100
+	SampleInterval int //= 100
+
+	// Capacity is the number of captures kept in the ring buffer returned by Client.WireCaptures.
+	// The oldest captures are evicted first.
+	//
+	// Default: 100
+	Capacity int //= 100
+
+	// MaxBytes is the maximum number of bytes captured for each request and response.
+	// Longer buffers are truncated, which is common for the responses of scans and queries.
+	//
+	// Default: 64 KiB
+	MaxBytes int //= 64 KiB
+
+	// Writer is an optional destination, e.g. a file, for the captures.
+	// Each capture is written with its decoded headers and operations, followed by a hex dump of the buffers.
+	// Writes are serialized, and happen in the goroutine of the command.
+	//
+	// Default: nil
+	Writer io.Writer
+}
+
+// NewWireCapturePolicy returns a policy with the default values.
+func NewWireCapturePolicy() *WireCapturePolicy {
+	return &WireCapturePolicy{
+		SampleInterval: 100,
+		Capacity:       100,
+		MaxBytes:       64 * 1024,
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Wire capture", func() {
+
+	writeRequest := func() []byte {
+		key, err := NewKey("test", "set", 1)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		cmd, err := newWriteCommand(nil, NewWritePolicy(0, 0), key, []*Bin{NewBin("a", 1), NewBin("bb", "x")}, nil, _WRITE)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(cmd.writeBuffer(&cmd)).ToNot(gm.HaveOccurred())
+		return append([]byte(nil), cmd.dataBuffer[:cmd.dataOffset]...)
+	}
+
+	protoHeader := func(typ int, size int) []byte {
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint64(buf, uint64(2)<<56|uint64(typ)<<48|uint64(size))
+		return buf
+	}
+
+	gg.Context("DecodeWireMessages", func() {
+
+		gg.It("must decode the header, fields and operations of a request", func() {
+			msgs, err := DecodeWireMessages(writeRequest())
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(msgs).To(gm.HaveLen(1))
+			gm.Expect(msgs[0].Version).To(gm.Equal(2))
+			gm.Expect(msgs[0].Type).To(gm.Equal(WireMessageRecord))
+			gm.Expect(msgs[0].Records).To(gm.HaveLen(1))
+
+			rec := msgs[0].Records[0]
+			gm.Expect(rec.Info2 & byte(_INFO2_WRITE)).ToNot(gm.BeZero())
+			gm.Expect(rec.Fields[0]).To(gm.Equal(WireField{Type: NAMESPACE, Data: []byte("test")}))
+			gm.Expect(rec.Fields[1]).To(gm.Equal(WireField{Type: TABLE, Data: []byte("set")}))
+			gm.Expect(rec.Operations).To(gm.HaveLen(2))
+			gm.Expect(rec.Operations[0].BinName).To(gm.Equal("a"))
+			gm.Expect(rec.Operations[0].Value).To(gm.Equal([]byte{0, 0, 0, 0, 0, 0, 0, 1}))
+			gm.Expect(rec.Operations[1].BinName).To(gm.Equal("bb"))
+			gm.Expect(rec.Operations[1].Value).To(gm.Equal([]byte("x")))
+			gm.Expect(msgs[0].String()).To(gm.ContainSubstring(`bin "bb"`))
+		})
+
+		gg.It("must decode multiple records and compressed messages", func() {
+			req := writeRequest()
+			payload := append(append([]byte(nil), req[8:]...), req[8:]...)
+			buf := append(protoHeader(WireMessageRecord, len(payload)), payload...)
+
+			msgs, err := DecodeWireMessages(buf)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(msgs[0].Records).To(gm.HaveLen(2))
+
+			var zbuf bytes.Buffer
+			zbuf.Write(make([]byte, 8))
+			w := zlib.NewWriter(&zbuf)
+			w.Write(buf)
+			w.Close()
+			compressed := append(protoHeader(WireMessageCompressed, zbuf.Len()), zbuf.Bytes()...)
+
+			msgs, err = DecodeWireMessages(compressed)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			gm.Expect(msgs[0].Type).To(gm.Equal(WireMessageCompressed))
+			gm.Expect(msgs[0].Inflated).To(gm.HaveLen(1))
+			gm.Expect(msgs[0].Inflated[0].Records).To(gm.HaveLen(2))
+		})
+
+		gg.It("must return the messages decoded before a truncation", func() {
+			req := writeRequest()
+			buf := append(append([]byte(nil), req...), req[:20]...)
+
+			msgs, err := DecodeWireMessages(buf)
+			gm.Expect(err).To(gm.HaveOccurred())
+			gm.Expect(err.Matches(types.PARSE_ERROR)).To(gm.BeTrue())
+			gm.Expect(msgs).To(gm.HaveLen(2))
+			gm.Expect(msgs[0].Records).To(gm.HaveLen(1))
+		})
+	})
+
+	gg.It("must redact the credentials of the admin messages", func() {
+		field := func(id byte, data string) []byte {
+			f := make([]byte, 5, 5+len(data))
+			binary.BigEndian.PutUint32(f, uint32(len(data)+1))
+			f[4] = id
+			return append(f, data...)
+		}
+
+		payload := make([]byte, 16)
+		payload = append(payload, field(_USER, "admin")...)
+		payload = append(payload, field(_CREDENTIAL, "secret")...)
+		payload = append(payload, field(_SESSION_TOKEN, "token")...)
+		buf := append(protoHeader(WireMessageAdmin, len(payload)), payload...)
+
+		redactWire(buf)
+		gm.Expect(bytes.Contains(buf, []byte("admin"))).To(gm.BeTrue())
+		gm.Expect(bytes.Contains(buf, []byte("secret"))).To(gm.BeFalse())
+		gm.Expect(bytes.Contains(buf, []byte("token"))).To(gm.BeFalse())
+		gm.Expect(buf).To(gm.HaveLen(8 + len(payload)))
+
+		req := writeRequest()
+		redacted := append([]byte(nil), req...)
+		redactWire(redacted)
+		gm.Expect(redacted).To(gm.Equal(req))
+	})
+
+	gg.Context("Capturer", func() {
+
+		gg.It("must sample the commands into a ring buffer", func() {
+			policy := NewWireCapturePolicy()
+			policy.SampleInterval = 2
+			policy.Capacity = 3
+			wc := newWireCapturer(*policy)
+
+			captured := 0
+			for i := 0; i < 10; i++ {
+				if wc.sample() {
+					rec := wc.start("put", "BB9", []byte{byte(i)})
+					rec.read([]byte{byte(i), byte(i)})
+					rec.finish(nil)
+					captured++
+				}
+			}
+			gm.Expect(captured).To(gm.Equal(5))
+
+			res := wc.report()
+			gm.Expect(res).To(gm.HaveLen(3))
+			gm.Expect(res[0].Request).To(gm.Equal([]byte{4}))
+			gm.Expect(res[1].Request).To(gm.Equal([]byte{6}))
+			gm.Expect(res[2].Request).To(gm.Equal([]byte{8}))
+			gm.Expect(res[2].Response).To(gm.Equal([]byte{8, 8}))
+			gm.Expect(res[2].Command).To(gm.Equal("put"))
+			gm.Expect(res[2].Node).To(gm.Equal("BB9"))
+		})
+
+		gg.It("must truncate the buffers and write the captures", func() {
+			var out bytes.Buffer
+			policy := NewWireCapturePolicy()
+			policy.SampleInterval = 1
+			policy.MaxBytes = 4
+			policy.Writer = &out
+			wc := newWireCapturer(*policy)
+
+			gm.Expect(wc.sample()).To(gm.BeTrue())
+			rec := wc.start("get", "BB9", []byte{1, 2})
+			rec.read([]byte{1, 2, 3})
+			rec.read([]byte{4, 5, 6})
+			rec.finish(ErrTimeout.err())
+
+			res := wc.report()
+			gm.Expect(res).To(gm.HaveLen(1))
+			gm.Expect(res[0].Response).To(gm.Equal([]byte{1, 2, 3, 4}))
+			gm.Expect(res[0].Truncated).To(gm.BeTrue())
+			gm.Expect(res[0].Err).To(gm.HaveOccurred())
+			gm.Expect(out.String()).To(gm.ContainSubstring("get on BB9 (truncated)"))
+			gm.Expect(out.String()).To(gm.ContainSubstring("response (4 bytes)"))
+		})
+
+		gg.It("must ignore the recording of unsampled commands", func() {
+			var rec *wireCaptureRecorder
+			rec.read([]byte{1})
+			rec.finish(nil)
+
+			var wc *wireCapturer
+			gm.Expect(wc.sample()).To(gm.BeFalse())
+		})
+	})
+})
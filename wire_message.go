@@ -0,0 +1,270 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// Wire protocol message types.
+const (
+	WireMessageInfo       = 1
+	WireMessageAdmin      = 2
+	WireMessageRecord     = 3
+	WireMessageCompressed = 4
+)
+
+// WireMessage is a decoded message of the wire protocol. See DecodeWireMessages.
+type WireMessage struct {
+	// Version is the version of the protocol.
+	Version int
+
+	// Type is the type of the message, e.g. WireMessageRecord.
+	Type int
+
+	// Size is the size of the message, excluding its 8 byte protocol header.
+	Size int
+
+	// Info is the text of info messages.
+	Info string
+
+	// Records are the decoded records of record messages. Responses of the
+	// batch, scan and query commands contain multiple records per message.
+	Records []WireRecord
+
+	// Inflated are the decoded messages of compressed messages.
+	Inflated []WireMessage
+}
+
+// WireRecord is a record of a record message, with its header, fields and operations.
+type WireRecord struct {
+	Info1, Info2, Info3, Info4 byte
+
+	ResultCode types.ResultCode
+	Generation uint32
+	Expiration uint32
+	Timeout    uint32
+
+	Fields     []WireField
+	Operations []WireOperation
+}
+
+// WireField is a field of a record message, e.g. the namespace or the digest.
+type WireField struct {
+	Type FieldType
+	Data []byte
+}
+
+// WireOperation is an operation of a record message, or a bin of a response.
+type WireOperation struct {
+	Type         byte
+	ParticleType int
+	BinName      string
+	Value        []byte
+}
+
+func (msg *WireMessage) String() string {
+	var sb strings.Builder
+	msg.writeTo(&sb, "  ")
+	return sb.String()
+}
+
+func (msg *WireMessage) writeTo(sb *strings.Builder, indent string) {
+	fmt.Fprintf(sb, "%smessage: version %d, type %d, size %d\n", indent, msg.Version, msg.Type, msg.Size)
+	switch msg.Type {
+	case WireMessageInfo:
+		fmt.Fprintf(sb, "%s  info: %q\n", indent, msg.Info)
+	case WireMessageAdmin:
+		fmt.Fprintf(sb, "%s  admin message\n", indent)
+	}
+
+	for i := range msg.Records {
+		rec := &msg.Records[i]
+		fmt.Fprintf(sb, "%s  record: info1 %#02x, info2 %#02x, info3 %#02x, info4 %#02x, result code %d (%s), generation %d, expiration %d, timeout %d\n",
+			indent, rec.Info1, rec.Info2, rec.Info3, rec.Info4, rec.ResultCode, types.ResultCodeToString(rec.ResultCode), rec.Generation, rec.Expiration, rec.Timeout)
+		for _, f := range rec.Fields {
+			fmt.Fprintf(sb, "%s    field: type %d, %d bytes: %x\n", indent, f.Type, len(f.Data), f.Data)
+		}
+		for _, op := range rec.Operations {
+			fmt.Fprintf(sb, "%s    operation: type %d, bin %q, particle type %d, %d bytes: %x\n", indent, op.Type, op.BinName, op.ParticleType, len(op.Value), op.Value)
+		}
+	}
+
+	for i := range msg.Inflated {
+		msg.Inflated[i].writeTo(sb, indent+"  ")
+	}
+}
+
+// DecodeWireMessages decodes the headers, fields and operations of the messages in a raw
+// wire protocol buffer, e.g. the request or the response of a WireCapture.
+// For truncated or malformed buffers, the messages decoded so far are returned with the error.
+func DecodeWireMessages(buf []byte) ([]WireMessage, Error) {
+	var res []WireMessage
+	for offset := 0; offset < len(buf); {
+		if len(buf)-offset < 8 {
+			return res, newError(types.PARSE_ERROR, "Truncated protocol header")
+		}
+
+		proto := binary.BigEndian.Uint64(buf[offset:])
+		msg := WireMessage{
+			Version: int(proto >> 56),
+			Type:    int((proto >> 48) & 0xff),
+			Size:    int(proto & 0xffffffffffff),
+		}
+		offset += 8
+
+		end := offset + msg.Size
+		if end > len(buf) || end < offset {
+			return append(res, msg), newError(types.PARSE_ERROR, fmt.Sprintf("Truncated message of %d bytes", msg.Size))
+		}
+		payload := buf[offset:end]
+		offset = end
+
+		var err Error
+		switch msg.Type {
+		case WireMessageInfo:
+			msg.Info = string(payload)
+		case WireMessageRecord:
+			msg.Records, err = decodeWireRecords(payload)
+		case WireMessageCompressed:
+			msg.Inflated, err = decodeCompressedWireMessage(payload)
+		}
+
+		res = append(res, msg)
+		if err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+func decodeCompressedWireMessage(payload []byte) ([]WireMessage, Error) {
+	if len(payload) < 8 {
+		return nil, newError(types.PARSE_ERROR, "Truncated compressed message")
+	}
+
+	r, err := zlib.NewReader(bytes.NewReader(payload[8:]))
+	if err != nil {
+		return nil, newErrorAndWrap(err, types.PARSE_ERROR)
+	}
+	defer r.Close()
+
+	inflated, err := io.ReadAll(r)
+	if err != nil {
+		return nil, newErrorAndWrap(err, types.PARSE_ERROR)
+	}
+	return DecodeWireMessages(inflated)
+}
+
+func decodeWireRecords(payload []byte) ([]WireRecord, Error) {
+	var res []WireRecord
+	for offset := 0; offset < len(payload); {
+		if len(payload)-offset < int(_MSG_REMAINING_HEADER_SIZE) {
+			return res, newError(types.PARSE_ERROR, "Truncated record header")
+		}
+
+		h := payload[offset:]
+		if int(h[0]) < int(_MSG_REMAINING_HEADER_SIZE) {
+			return res, newError(types.PARSE_ERROR, fmt.Sprintf("Invalid record header size %d", h[0]))
+		}
+
+		rec := WireRecord{
+			Info1:      h[1],
+			Info2:      h[2],
+			Info3:      h[3],
+			Info4:      h[4],
+			ResultCode: types.ResultCode(h[5]),
+			Generation: binary.BigEndian.Uint32(h[6:]),
+			Expiration: binary.BigEndian.Uint32(h[10:]),
+			Timeout:    binary.BigEndian.Uint32(h[14:]),
+		}
+		fieldCount := int(binary.BigEndian.Uint16(h[18:]))
+		opCount := int(binary.BigEndian.Uint16(h[20:]))
+		offset += int(h[0])
+
+		for i := 0; i < fieldCount; i++ {
+			if len(payload)-offset < int(_FIELD_HEADER_SIZE) {
+				return append(res, rec), newError(types.PARSE_ERROR, "Truncated field header")
+			}
+			size := int(binary.BigEndian.Uint32(payload[offset:]))
+			if size < 1 || len(payload)-offset-4 < size {
+				return append(res, rec), newError(types.PARSE_ERROR, "Truncated field")
+			}
+			rec.Fields = append(rec.Fields, WireField{
+				Type: FieldType(payload[offset+4]),
+				Data: payload[offset+5 : offset+4+size],
+			})
+			offset += 4 + size
+		}
+
+		for i := 0; i < opCount; i++ {
+			if len(payload)-offset < int(_OPERATION_HEADER_SIZE) {
+				return append(res, rec), newError(types.PARSE_ERROR, "Truncated operation header")
+			}
+			size := int(binary.BigEndian.Uint32(payload[offset:]))
+			nameSize := int(payload[offset+7])
+			if size < 4+nameSize || len(payload)-offset-4 < size {
+				return append(res, rec), newError(types.PARSE_ERROR, "Truncated operation")
+			}
+			rec.Operations = append(rec.Operations, WireOperation{
+				Type:         payload[offset+4],
+				ParticleType: int(payload[offset+5]),
+				BinName:      string(payload[offset+8 : offset+8+nameSize]),
+				Value:        payload[offset+8+nameSize : offset+4+size],
+			})
+			offset += 4 + size
+		}
+
+		res = append(res, rec)
+	}
+	return res, nil
+}
+
+// redactWire zeroes the credentials of the admin messages in the buffer in place.
+func redactWire(buf []byte) {
+	for offset := 0; len(buf)-offset >= 8; {
+		proto := binary.BigEndian.Uint64(buf[offset:])
+		end := offset + 8 + int(proto&0xffffffffffff)
+		if end > len(buf) || end < offset {
+			end = len(buf)
+		}
+
+		if int((proto>>48)&0xff) == WireMessageAdmin {
+			for f := offset + _HEADER_SIZE; end-f >= int(_FIELD_HEADER_SIZE); {
+				size := int(binary.BigEndian.Uint32(buf[f:]))
+				dataEnd := f + 4 + size
+				if size < 1 || dataEnd > end {
+					dataEnd = end
+				}
+
+				switch buf[f+4] {
+				case _PASSWORD, _OLD_PASSWORD, _CREDENTIAL, _CLEAR_PASSWORD, _SESSION_TOKEN:
+					for i := f + 5; i < dataEnd; i++ {
+						buf[i] = 0
+					}
+				}
+				f = dataEnd
+			}
+		}
+		offset = end
+	}
+}
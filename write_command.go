@@ -42,6 +42,10 @@ func newWriteCommand(cluster *Cluster,
 	var partition *Partition
 	var err Error
 	if cluster != nil {
+		if err = cluster.clientPolicy.Schemas.validateWrite(key, bins, binMap); err != nil {
+			return writeCommand{}, err
+		}
+
 		partition, err = PartitionForWrite(cluster, &policy.BasePolicy, key)
 		if err != nil {
 			return writeCommand{}, err
@@ -0,0 +1,346 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// JournalOperation identifies the client write command recorded in a JournalEntry.
+type JournalOperation byte
+
+const (
+	// JournalPut is recorded for Put and PutBins commands.
+	JournalPut JournalOperation = iota
+	// JournalAppend is recorded for Append and AppendBins commands.
+	JournalAppend
+	// JournalPrepend is recorded for Prepend and PrependBins commands.
+	JournalPrepend
+	// JournalAdd is recorded for Add and AddBins commands.
+	JournalAdd
+	// JournalDelete is recorded for Delete commands.
+	JournalDelete
+	// JournalTouch is recorded for Touch commands.
+	JournalTouch
+	// JournalOperate is recorded for Operate commands containing write operations.
+	JournalOperate
+)
+
+// idempotent returns true if replaying a write of the operation that was already
+// applied on the server leaves the record in the same state.
+func (op JournalOperation) idempotent() bool {
+	switch op {
+	case JournalPut, JournalDelete, JournalTouch:
+		return true
+	default:
+		return false
+	}
+}
+
+// JournalEntryID identifies a JournalEntry. The first 6 bytes are the creation time of the entry
+// in milliseconds since the Unix epoch, big endian, and the other 10 bytes are random, so that
+// the IDs are unique across processes and restarts, and sort in the order of creation.
+type JournalEntryID [16]byte
+
+// newJournalEntryID returns a new ID for an entry created at the time.
+func newJournalEntryID(created time.Time) (JournalEntryID, Error) {
+	var id JournalEntryID
+	if _, err := rand.Read(id[6:]); err != nil {
+		return id, newCommonError(err, "Failed to generate a random journal entry ID")
+	}
+
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(created.UnixMilli()))
+	copy(id[:6], ms[2:])
+	return id, nil
+}
+
+// String returns the hex encoding of the ID.
+func (id JournalEntryID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// JournalEntry is the intent record written to the WriteJournal before a write
+// command is sent to the server.
+type JournalEntry struct {
+	// ID is assigned by the client when the intent is recorded.
+	ID JournalEntryID
+
+	// Key of the record being written.
+	Key *Key
+
+	// Operation is the client command that was issued.
+	Operation JournalOperation
+
+	// Bins contains the bins sent with Put/Append/Prepend/Add commands.
+	Bins []*Bin
+
+	// Operations contains the operations sent with the Operate command.
+	// If the command was sent with a write token, they include the operation that stores the token.
+	Operations []*Operation
+
+	// Policy is the write policy the command was sent with.
+	// If the command was sent with a write token, it includes the token and its filter expression.
+	Policy *WritePolicy
+
+	// Created is the time the intent was recorded.
+	Created time.Time
+}
+
+// WriteJournal is a user-pluggable write-ahead journal. When set on ClientPolicy,
+// the client records each write intent before sending it to the server, and marks
+// it complete after a definitive result is returned.
+//
+// The journal covers the single record writes: Put, Append, Prepend, Add, Delete, Touch and
+// Operate, including their variants. UDF executions (Execute) and batch commands (BatchOperate,
+// BatchDelete, BatchExecute) are not journaled, since their effects cannot be verified or replayed
+// record by record.
+//
+// Entries which are never completed, either because the process crashed mid-command
+// or because the command returned an in-doubt error, can be resolved after the fact
+// via Client.RecoverJournal.
+//
+// Implementations must be safe for concurrent use.
+type WriteJournal interface {
+	// Begin records the intent to write. If an error is returned, the write
+	// is not sent to the server.
+	Begin(entry *JournalEntry) error
+
+	// Complete marks the entry as resolved. It is called when the server returns
+	// a result that is not in doubt, successful or not.
+	Complete(entry *JournalEntry) error
+
+	// Unresolved returns all entries that have been begun but not completed.
+	Unresolved() ([]*JournalEntry, error)
+}
+
+// JournalVerifier decides if an unresolved journal entry was applied on the server.
+// The record is the current state of the record on the server, or nil if it does not exist.
+// If the verifier returns true, the entry will be completed without being replayed.
+type JournalVerifier func(entry *JournalEntry, record *Record) bool
+
+func newJournalEntry(policy *WritePolicy, key *Key, op JournalOperation, bins []*Bin, binMap BinMap, ops []*Operation) *JournalEntry {
+	if len(bins) == 0 && len(binMap) > 0 {
+		bins = make([]*Bin, 0, len(binMap))
+		for name, v := range binMap {
+			bins = append(bins, NewBin(name, v))
+		}
+	}
+
+	return &JournalEntry{
+		Key:        key,
+		Operation:  op,
+		Bins:       bins,
+		Operations: ops,
+		Policy:     policy,
+		Created:    time.Now(),
+	}
+}
+
+// journaled runs the write command f, recording its intent and completion in the
+// client's write journal. If no journal is set, f is called directly.
+func (clnt *Client) journaled(entry func() *JournalEntry, f func() Error) Error {
+	if clnt.journal == nil {
+		return f()
+	}
+
+	e := entry()
+	id, err := newJournalEntryID(e.Created)
+	if err != nil {
+		return err
+	}
+	e.ID = id
+
+	if err := clnt.journal.Begin(e); err != nil {
+		return newCommonError(err, "Failed to record the write intent in the journal")
+	}
+
+	err = f()
+	if err != nil && err.IsInDoubt() {
+		// leave the entry unresolved for recovery
+		return err
+	}
+
+	if jerr := clnt.journal.Complete(e); jerr != nil {
+		return chainErrors(newCommonError(jerr, "Failed to complete the write intent in the journal"), err)
+	}
+	return err
+}
+
+// RecoverJournal resolves all unresolved entries in the client's write journal.
+// For each entry, the record is read back from the server and passed to the verifier.
+// If the verifier reports the write as applied, the entry is completed. Otherwise the
+// write is replayed with the original policy and completed if the replay result is definitive.
+//
+// If the verifier is nil, only the idempotent entries (Put, Delete and Touch) are replayed.
+// Replaying an Append, Prepend, Add or Operate which was applied before would apply it twice,
+// so these entries are left unresolved and reported in the errors instead.
+//
+// Entries are processed in the order of their creation time. The entries which remained
+// unresolved after recovery are returned alongside the chained errors.
+func (clnt *Client) RecoverJournal(verifier JournalVerifier) ([]*JournalEntry, Error) {
+	if clnt.journal == nil {
+		return nil, newError(types.PARAMETER_ERROR, "No WriteJournal was set on the ClientPolicy")
+	}
+
+	entries, jerr := clnt.journal.Unresolved()
+	if jerr != nil {
+		return nil, newCommonError(jerr, "Failed to read the unresolved journal entries")
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Created.Before(entries[j].Created)
+	})
+
+	var errs Error
+	var unresolved []*JournalEntry
+	for _, e := range entries {
+		if verifier != nil {
+			rec, err := clnt.Get(nil, e.Key)
+			if err != nil && !err.Matches(types.KEY_NOT_FOUND_ERROR) {
+				errs = chainErrors(err, errs)
+				unresolved = append(unresolved, e)
+				continue
+			}
+
+			if verifier(e, rec) {
+				if err := clnt.journal.Complete(e); err != nil {
+					errs = chainErrors(newCommonError(err), errs)
+					unresolved = append(unresolved, e)
+				}
+				continue
+			}
+		} else if !e.Operation.idempotent() {
+			errs = chainErrors(newError(types.PARAMETER_ERROR, "Journal entry of a non-idempotent write cannot be replayed without a verifier"), errs)
+			unresolved = append(unresolved, e)
+			continue
+		}
+
+		if err := clnt.replayJournalEntry(e); err != nil {
+			errs = chainErrors(err, errs)
+			if err.IsInDoubt() {
+				unresolved = append(unresolved, e)
+				continue
+			}
+		}
+
+		if err := clnt.journal.Complete(e); err != nil {
+			errs = chainErrors(newCommonError(err), errs)
+			unresolved = append(unresolved, e)
+		}
+	}
+
+	return unresolved, errs
+}
+
+func (clnt *Client) replayJournalEntry(e *JournalEntry) Error {
+	policy := clnt.getUsableWritePolicy(e.Policy)
+	defer clnt.invalidateCached(e.Key)
+
+	var optype OperationType
+	switch e.Operation {
+	case JournalPut:
+		optype = _WRITE
+	case JournalAppend:
+		optype = _APPEND
+	case JournalPrepend:
+		optype = _PREPEND
+	case JournalAdd:
+		optype = _ADD
+	case JournalDelete:
+		command, err := newDeleteCommand(clnt.cluster, policy, e.Key)
+		if err != nil {
+			return err
+		}
+		return command.Execute()
+	case JournalTouch:
+		command, err := newTouchCommand(clnt.cluster, policy, e.Key)
+		if err != nil {
+			return err
+		}
+		return command.Execute()
+	case JournalOperate:
+		// run the command directly; going through Client.Operate would journal the replay again
+		args, err := newOperateArgs(clnt.cluster, policy, e.Key, e.Operations)
+		if err != nil {
+			return err
+		}
+		command, err := newOperateCommand(clnt.cluster, policy, e.Key, args, false)
+		if err != nil {
+			return err
+		}
+		return command.Execute()
+	default:
+		return newError(types.PARAMETER_ERROR, "Unknown journal operation")
+	}
+
+	command, err := newWriteCommand(clnt.cluster, policy, e.Key, e.Bins, nil, optype)
+	if err != nil {
+		return err
+	}
+	return command.Execute()
+}
+
+// MemoryWriteJournal is an in-memory implementation of WriteJournal.
+// It does not survive process restarts and is meant as a reference implementation
+// and for testing. Production deployments should persist the entries.
+type MemoryWriteJournal struct {
+	m       sync.Mutex
+	entries map[JournalEntryID]*JournalEntry
+}
+
+var _ WriteJournal = &MemoryWriteJournal{}
+
+// NewMemoryWriteJournal creates a new in-memory WriteJournal.
+func NewMemoryWriteJournal() *MemoryWriteJournal {
+	return &MemoryWriteJournal{
+		entries: make(map[JournalEntryID]*JournalEntry),
+	}
+}
+
+// Begin implements the WriteJournal interface.
+func (j *MemoryWriteJournal) Begin(entry *JournalEntry) error {
+	j.m.Lock()
+	j.entries[entry.ID] = entry
+	j.m.Unlock()
+	return nil
+}
+
+// Complete implements the WriteJournal interface.
+func (j *MemoryWriteJournal) Complete(entry *JournalEntry) error {
+	j.m.Lock()
+	delete(j.entries, entry.ID)
+	j.m.Unlock()
+	return nil
+}
+
+// Unresolved implements the WriteJournal interface.
+func (j *MemoryWriteJournal) Unresolved() ([]*JournalEntry, error) {
+	j.m.Lock()
+	defer j.m.Unlock()
+
+	res := make([]*JournalEntry, 0, len(j.entries))
+	for _, e := range j.entries {
+		res = append(res, e)
+	}
+	return res, nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+	"time"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Write Journal entry IDs", func() {
+
+	gg.It("must be unique for the entries created at the same time", func() {
+		now := time.Now()
+		ids := make(map[JournalEntryID]struct{})
+		for i := 0; i < 1000; i++ {
+			id, err := newJournalEntryID(now)
+			gm.Expect(err).ToNot(gm.HaveOccurred())
+			ids[id] = struct{}{}
+		}
+		gm.Expect(ids).To(gm.HaveLen(1000))
+	})
+
+	gg.It("must sort in the order of creation", func() {
+		now := time.Now()
+		first, err := newJournalEntryID(now)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		second, err := newJournalEntryID(now.Add(time.Millisecond))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		gm.Expect(bytes.Compare(first[:], second[:])).To(gm.Equal(-1))
+		gm.Expect(first.String()).To(gm.HaveLen(32))
+	})
+})
@@ -0,0 +1,156 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	as "github.com/aerospike/aerospike-client-go/v7"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+// countingJournal counts the intents recorded in the journal, and keeps the last one
+type countingJournal struct {
+	*as.MemoryWriteJournal
+	begins int
+	last   *as.JournalEntry
+}
+
+func (j *countingJournal) Begin(entry *as.JournalEntry) error {
+	j.begins++
+	j.last = entry
+	return j.MemoryWriteJournal.Begin(entry)
+}
+
+// ALL tests are isolated by SetName and Key, which are 50 random characters
+var _ = gg.Describe("Write Journal", func() {
+
+	var ns = *namespace
+	var set = randString(50)
+
+	var journal *countingJournal
+	var jclient *as.Client
+
+	gg.BeforeEach(func() {
+		if *proxy {
+			gg.Skip("Not supported in Proxy Client")
+		}
+
+		journal = &countingJournal{MemoryWriteJournal: as.NewMemoryWriteJournal()}
+		cp := *clientPolicy
+		cp.WriteJournal = journal
+
+		dbHost := as.NewHost(*host, *port)
+		dbHost.TLSName = *nodeTLSName
+
+		var err error
+		jclient, err = as.NewClientWithPolicyAndHost(&cp, dbHost)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+	})
+
+	gg.AfterEach(func() {
+		if jclient != nil {
+			jclient.Close()
+		}
+	})
+
+	gg.It("must complete the entries of successful writes", func() {
+		key, err := as.NewKey(ns, set, randString(50))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		err = jclient.PutBins(nil, key, as.NewBin("b", 1))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		_, err = jclient.Operate(nil, key, as.AddOp(as.NewBin("b", 1)), as.GetOp())
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		entries, jerr := journal.Unresolved()
+		gm.Expect(jerr).ToNot(gm.HaveOccurred())
+		gm.Expect(entries).To(gm.BeEmpty())
+	})
+
+	gg.It("must journal the operations sent with the write token", func() {
+		key, err := as.NewKey(ns, set, randString(50))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		wp := as.NewWritePolicy(0, 0)
+		wp.WriteTokenBin = "token"
+		_, err = jclient.Operate(wp, key, as.AddOp(as.NewBin("b", 1)))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		gm.Expect(journal.last.Policy.WriteToken).ToNot(gm.BeEmpty())
+		gm.Expect(journal.last.Policy.FilterExpression).ToNot(gm.BeNil())
+		gm.Expect(journal.last.Operations).To(gm.HaveLen(2))
+		gm.Expect(wp.WriteToken).To(gm.BeEmpty())
+	})
+
+	gg.It("must replay unresolved entries on recovery", func() {
+		key, err := as.NewKey(ns, set, randString(50))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		// simulate a crash after the intent was recorded
+		gm.Expect(journal.Begin(&as.JournalEntry{ID: as.JournalEntryID{1}, Key: key, Operation: as.JournalPut, Bins: []*as.Bin{as.NewBin("b", 7)}})).ToNot(gm.HaveOccurred())
+
+		unresolved, err := jclient.RecoverJournal(func(e *as.JournalEntry, rec *as.Record) bool {
+			return rec != nil && rec.Bins["b"] == 7
+		})
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(unresolved).To(gm.BeEmpty())
+
+		rec, err := jclient.Get(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(rec.Bins["b"]).To(gm.Equal(7))
+
+		entries, _ := journal.Unresolved()
+		gm.Expect(entries).To(gm.BeEmpty())
+	})
+	gg.It("must not replay non-idempotent entries without a verifier", func() {
+		key, err := as.NewKey(ns, set, randString(50))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		err = jclient.PutBins(nil, key, as.NewBin("b", 1))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		add := &as.JournalEntry{ID: as.JournalEntryID{2}, Key: key, Operation: as.JournalAdd, Bins: []*as.Bin{as.NewBin("b", 1)}}
+		gm.Expect(journal.Begin(add)).ToNot(gm.HaveOccurred())
+
+		unresolved, err := jclient.RecoverJournal(nil)
+		gm.Expect(err).To(gm.HaveOccurred())
+		gm.Expect(unresolved).To(gm.Equal([]*as.JournalEntry{add}))
+
+		rec, err := jclient.Get(nil, key)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(rec.Bins["b"]).To(gm.Equal(1))
+	})
+
+	gg.It("must not journal the replayed Operate commands", func() {
+		key, err := as.NewKey(ns, set, randString(50))
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		op := &as.JournalEntry{ID: as.JournalEntryID{3}, Key: key, Operation: as.JournalOperate, Operations: []*as.Operation{as.PutOp(as.NewBin("b", 3))}}
+		gm.Expect(journal.Begin(op)).ToNot(gm.HaveOccurred())
+		begins := journal.begins
+
+		unresolved, err := jclient.RecoverJournal(func(e *as.JournalEntry, rec *as.Record) bool {
+			return false
+		})
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(unresolved).To(gm.BeEmpty())
+		gm.Expect(journal.begins).To(gm.Equal(begins))
+
+		entries, _ := journal.Unresolved()
+		gm.Expect(entries).To(gm.BeEmpty())
+	})
+})
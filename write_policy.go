@@ -16,6 +16,7 @@ package aerospike
 
 import (
 	"math"
+	"time"
 )
 
 const (
@@ -74,6 +75,48 @@ type WritePolicy struct {
 	// This prevents deleted records from reappearing after node failures.
 	// Valid for Aerospike Server Enterprise Edition 3.10+ only.
 	DurableDelete bool
+
+	// RequireDurableDelete makes durable deletes fail with an ENTERPRISE_ONLY error when a node of
+	// the cluster cannot leave tombstones, instead of deleting the records without a tombstone.
+	// It only applies to Delete and DeleteWithResult when DurableDelete is set.
+	RequireDurableDelete bool
+
+	// MaxRecordSize is the maximum size in bytes of the bins of Put, Append, Prepend, Add, their
+	// Bins variants and the write operations of Operate. The size is computed while the command is
+	// serialized, and larger writes fail with a RECORD_TOO_BIG error before they are sent to the
+	// server. The error wraps a RecordSizeError with the size of each bin.
+	// The size is the serialized size of the bins, which approximates the size of the record on
+	// the server. Only the bins of the write are counted, not the existing bins of the record.
+	// The default (0) does not limit the size.
+	MaxRecordSize int // = 0
+
+	// VerifyInDoubt makes Put, PutBins, Append, Prepend, Add, their Bins variants, Touch and
+	// Operate read the record back when the command fails with an in-doubt error, to determine
+	// if the write was applied on the server.
+	// If WriteTokenBin is set and the record contains the write token, the write was applied and
+	// the command succeeds. Otherwise, the verification requires the GenerationPolicy to be
+	// EXPECT_GEN_EQUAL: If the generation of the record has been incremented by one, the write was
+	// applied and the command succeeds. If it is unchanged, the write was not applied and the error
+	// is returned with InDoubt set to false.
+	// In all other cases, or if the record cannot be read back, the original error is returned.
+	// If Operate succeeds this way, it returns a nil record, since the results of the operations are lost.
+	VerifyInDoubt bool // = false
+
+	// WriteTokenBin enables idempotent writes for Append, Prepend, Add, their Bins variants and
+	// Operate commands with write operations, so they can be safely retried after an in-doubt error.
+	// The write token is stored in the bin with this name alongside the write, and the write is
+	// filtered out if the bin already contains the token; the client reports such a write as
+	// successful, since it was applied by a previous attempt. Operate returns a nil record in that case.
+	// Only the token of the last write is stored, so the retry must happen before the record
+	// is written with another token. Append, Prepend and Add are sent as Operate commands.
+	// The default (empty) disables write tokens.
+	WriteTokenBin string
+
+	// WriteToken is the token used with WriteTokenBin. To retry a write in the application,
+	// set a token from NewWriteToken before the first attempt, and reuse it for the retries.
+	// If empty, a new token is generated for each command, which makes the retries of the client
+	// itself safe. See BasePolicy.MaxRetries.
+	WriteToken string
 }
 
 // NewWritePolicy initializes a new WritePolicy instance with default parameters.
@@ -93,3 +136,86 @@ func NewWritePolicy(generation, expiration uint32) *WritePolicy {
 
 	return res
 }
+
+// ExpireIn sets the expiration of the policy so that the written records expire after the duration.
+// The duration is rounded up to whole seconds, with a minimum of one second.
+// Returns the policy to allow chaining.
+func (p *WritePolicy) ExpireIn(d time.Duration) *WritePolicy {
+	p.Expiration = ttlFromDuration(d)
+	return p
+}
+
+// ExpireAt sets the expiration of the policy so that the written records expire at the given time.
+// The expiration is computed relative to the local clock when the method is called.
+// Times in the past expire the records after one second.
+// Returns the policy to allow chaining.
+func (p *WritePolicy) ExpireAt(t time.Time) *WritePolicy {
+	return p.ExpireIn(time.Until(t))
+}
+
+// NeverExpire sets the expiration of the policy so that the written records never expire.
+// Returns the policy to allow chaining.
+func (p *WritePolicy) NeverExpire() *WritePolicy {
+	p.Expiration = TTLDontExpire
+	return p
+}
+
+// DontUpdateTTL sets the expiration of the policy so that writes do not change the TTL of the records.
+// Returns the policy to allow chaining.
+func (p *WritePolicy) DontUpdateTTL() *WritePolicy {
+	p.Expiration = TTLDontUpdate
+	return p
+}
+
+// IfGenerationEquals makes the writes of the policy fail with a GENERATION_ERROR unless the
+// generation of the record equals the given generation, usually the one of a previous read.
+// Returns the policy to allow chaining.
+func (p *WritePolicy) IfGenerationEquals(generation uint32) *WritePolicy {
+	p.GenerationPolicy = EXPECT_GEN_EQUAL
+	p.Generation = generation
+	return p
+}
+
+// IfUnchangedSince makes the writes of the policy only apply if the record was not updated
+// after lastUpdate. Records that were updated fail with a FILTERED_OUT error.
+// The condition is combined with the filter expression of the policy, if any.
+// See ExpUnchangedSince.
+// Returns the policy to allow chaining.
+func (p *WritePolicy) IfUnchangedSince(lastUpdate time.Time) *WritePolicy {
+	p.andFilterExpression(ExpUnchangedSince(lastUpdate))
+	return p
+}
+
+// IfBinEquals makes the writes of the policy only apply if the bin holds the value,
+// usually the one of a previous read. Records that do not match fail with a FILTERED_OUT error.
+// The condition is combined with the filter expression of the policy, if any.
+// See ExpBinEquals.
+// Returns the policy to allow chaining.
+func (p *WritePolicy) IfBinEquals(binName string, value interface{}) *WritePolicy {
+	p.andFilterExpression(ExpBinEquals(binName, value))
+	return p
+}
+
+func (p *WritePolicy) andFilterExpression(exp *Expression) {
+	if p.FilterExpression == nil {
+		p.FilterExpression = exp
+		return
+	}
+	p.FilterExpression = ExpAnd(p.FilterExpression, exp)
+}
+
+// ttlFromDuration converts the duration to a TTL in seconds, rounded up.
+// The result is clamped so that it never collides with the special TTL values.
+func ttlFromDuration(d time.Duration) uint32 {
+	secs := int64(d / time.Second)
+	if d%time.Second > 0 {
+		secs++
+	}
+	switch {
+	case secs < 1:
+		return 1
+	case secs >= TTLDontUpdate:
+		return TTLDontUpdate - 1
+	}
+	return uint32(secs)
+}
@@ -0,0 +1,107 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/aerospike/aerospike-client-go/v7/types"
+)
+
+// NewWriteToken returns a new random token to be used in WritePolicy.WriteToken.
+// An error is returned if the system's random number generator fails, since a predictable
+// token could make a write be mistaken for a retry of another.
+func NewWriteToken() (string, Error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", newCommonError(err, "Failed to generate a random write token")
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// withWriteToken returns a copy of the policy and the operations that make the write conditional on
+// the absence of the write token in the record, and store the token alongside the write.
+// The token of the returned policy is set, so that retries and replays of the write reuse it.
+func withWriteToken(policy *WritePolicy, operations []*Operation) (*WritePolicy, []*Operation, Error) {
+	p := *policy
+	if p.WriteToken == "" {
+		token, err := NewWriteToken()
+		if err != nil {
+			return nil, nil, err
+		}
+		p.WriteToken = token
+	}
+
+	filter := ExpOr(
+		ExpNot(ExpBinExists(p.WriteTokenBin)),
+		ExpNotEq(ExpStringBin(p.WriteTokenBin), ExpStringVal(p.WriteToken)),
+	)
+	if p.FilterExpression != nil {
+		filter = ExpAnd(p.FilterExpression, filter)
+	}
+	p.FilterExpression = filter
+
+	ops := make([]*Operation, 0, len(operations)+1)
+	ops = append(ops, operations...)
+	ops = append(ops, PutOp(NewBin(p.WriteTokenBin, p.WriteToken)))
+	return &p, ops, nil
+}
+
+// writeTokenApplied reads the write token of the record back, and returns true if it is the token
+// of the policy, which means the write has already been applied.
+func (clnt *Client) writeTokenApplied(policy *WritePolicy, key *Key) bool {
+	rp := policy.BasePolicy
+	rp.FilterExpression = nil
+	rp.ReadModeAP = ReadModeAPAll
+	rp.ReadModeSC = ReadModeSCLinearize
+
+	rec, err := clnt.Get(&rp, key, policy.WriteTokenBin)
+	if err != nil || rec == nil {
+		return false
+	}
+	token, ok := rec.Bins[policy.WriteTokenBin].(string)
+	return ok && token == policy.WriteToken
+}
+
+// resolveWriteToken determines if the error of a write sent with a write token
+// means the write had already been applied.
+// Without a filter expression of the user, the write can only be filtered out
+// by its token. Otherwise, the token is read back to tell the filters apart.
+func (clnt *Client) resolveWriteToken(policy, tokenPolicy *WritePolicy, key *Key, err Error) Error {
+	if err == nil || !err.Matches(types.FILTERED_OUT) {
+		return err
+	}
+
+	if policy.FilterExpression == nil || clnt.writeTokenApplied(tokenPolicy, key) {
+		return nil
+	}
+	return err
+}
+
+// tokenWrite executes the write of the bins with the operation op as an Operate command,
+// so that the write token can be stored alongside it.
+func (clnt *Client) tokenWrite(policy *WritePolicy, key *Key, bins []*Bin, binMap BinMap, op func(*Bin) *Operation) Error {
+	ops := make([]*Operation, 0, len(bins)+len(binMap))
+	for _, bin := range bins {
+		ops = append(ops, op(bin))
+	}
+	for name, v := range binMap {
+		ops = append(ops, op(NewBin(name, v)))
+	}
+
+	_, err := clnt.operate(policy, key, false, ops...)
+	return err
+}
@@ -0,0 +1,71 @@
+// Copyright 2014-2024 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"github.com/aerospike/aerospike-client-go/v7/types"
+
+	gg "github.com/onsi/ginkgo/v2"
+	gm "github.com/onsi/gomega"
+)
+
+var _ = gg.Describe("Write tokens", func() {
+
+	gg.It("must generate unique tokens", func() {
+		token1, err := NewWriteToken()
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(token1).To(gm.HaveLen(32))
+
+		token2, err := NewWriteToken()
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(token2).ToNot(gm.Equal(token1))
+	})
+
+	gg.It("must store the token alongside the write without changing the policy", func() {
+		policy := NewWritePolicy(0, 0)
+		policy.WriteTokenBin = "token"
+		ops := []*Operation{AddOp(NewBin("a", 1))}
+
+		tpolicy, tops, err := withWriteToken(policy, ops)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(policy.WriteToken).To(gm.BeEmpty())
+		gm.Expect(policy.FilterExpression).To(gm.BeNil())
+		gm.Expect(tpolicy.WriteToken).ToNot(gm.BeEmpty())
+		gm.Expect(tpolicy.FilterExpression).ToNot(gm.BeNil())
+		gm.Expect(tops).To(gm.HaveLen(2))
+		gm.Expect(tops[0]).To(gm.BeIdenticalTo(ops[0]))
+		gm.Expect(tops[1].binName).To(gm.Equal("token"))
+		gm.Expect(tops[1].binValue).To(gm.Equal(NewValue(tpolicy.WriteToken)))
+
+		// retries reuse the token
+		rpolicy, _, err := withWriteToken(tpolicy, ops)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+		gm.Expect(rpolicy.WriteToken).To(gm.Equal(tpolicy.WriteToken))
+	})
+
+	gg.It("must report the writes filtered out by their token as applied", func() {
+		clnt := &Client{}
+		policy := NewWritePolicy(0, 0)
+		policy.WriteTokenBin = "token"
+		tpolicy, _, err := withWriteToken(policy, nil)
+		gm.Expect(err).ToNot(gm.HaveOccurred())
+
+		gm.Expect(clnt.resolveWriteToken(policy, tpolicy, nil, ErrFilteredOut.err())).To(gm.BeNil())
+
+		err = clnt.resolveWriteToken(policy, tpolicy, nil, newError(types.TIMEOUT))
+		gm.Expect(err.Matches(types.TIMEOUT)).To(gm.BeTrue())
+	})
+
+})